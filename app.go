@@ -4,10 +4,12 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/robfig/cron/v3"
 	"kitbook/internal/events"
+	"kitbook/internal/job"
 )
 
 type App struct {
 	server    *gin.Engine
 	consumers []events.Consumer
 	cron      *cron.Cron
+	scheduler *job.Scheduler
 }
@@ -1,13 +1,25 @@
 package domain
 
-import "time"
+import (
+	"kitbook/pkg/markdown"
+	"strings"
+	"time"
+)
 
 type Article struct {
 	Id      int64
 	Title   string
 	Content string
-	Author  Author
-	Status  ArticleStatus
+	// 正文渲染后的HTML, 只有线上库帖子才有
+	HTML   string
+	Author Author
+	// 分类, 每篇帖子只属于一个分类
+	Category string
+	// 标签, 按作者填写的顺序
+	Tags   []string
+	Status ArticleStatus
+	// 制作库版本号, 每次修改加1, 用于检测并发编辑
+	Version int64
 	Ctime   time.Time
 	Utime   time.Time
 }
@@ -17,23 +29,94 @@ type Author struct {
 	Name string
 }
 
+// Tag
+// @Description: 标签及带有该标签的已发表帖子数
+type Tag struct {
+	Name       string
+	ArticleCnt int64
+}
+
+// @func: NormalizeTag
+// @date: 2024-01-21 15:14:20
+// @brief: 标签名去除首尾空白并统一小写, 作为去重、查找的依据
+// @author: Kewin Li
+// @param name
+// @return string
+func NormalizeTag(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// @func: NormalizeTags
+// @date: 2024-01-21 15:14:52
+// @brief: 标签名统一处理后去空、去重, 保持原有顺序
+// @author: Kewin Li
+// @param tags
+// @return []string
+func NormalizeTags(tags []string) []string {
+	res := make([]string, 0, len(tags))
+	seen := make(map[string]struct{}, len(tags))
+	for _, tag := range tags {
+		tag = NormalizeTag(tag)
+		if _, ok := seen[tag]; ok || tag == "" {
+			continue
+		}
+		seen[tag] = struct{}{}
+		res = append(res, tag)
+	}
+
+	return res
+}
+
 // 截取摘要的最大长度
 const abstractMaxLen = 256
 
 // @func: Abstract
 // @date: 2023-12-04 22:26:53
-// @brief: 通过截取长度生成摘要
+// @brief: 通过截取长度生成摘要, 摘要为去掉Markdown语法后的纯文本, 只渲染正文开头部分
 // @author: Kewin Li
 // @receiver a
 func (a *Article) CreateAbstract() string {
-	// 考虑中文
-	str := []rune(a.Content)
+	// 按字截取, 考虑中文
+	return markdown.TextPrefix(a.Content, abstractMaxLen)
+}
 
-	if len(str) > abstractMaxLen {
-		str = str[:abstractMaxLen]
+// @func: RenderHTML
+// @date: 2024-01-24 11:20:16
+// @brief: 正文Markdown渲染为HTML, 已渲染过的直接返回
+// @author: Kewin Li
+// @receiver a
+// @return string
+func (a *Article) RenderHTML() string {
+	if a.HTML == "" && a.Content != "" {
+		a.HTML = markdown.Render(a.Content)
+	}
+
+	return a.HTML
+}
+
+// @func: PlainText
+// @date: 2024-01-24 11:21:40
+// @brief: 正文纯文本, 不含Markdown语法和HTML标签
+// @author: Kewin Li
+// @receiver a
+// @return string
+func (a *Article) PlainText() string {
+	html := a.HTML
+	if html == "" {
+		html = markdown.Render(a.Content)
 	}
 
-	return string(str)
+	return markdown.Text(html)
+}
+
+// @func: WordCount
+// @date: 2024-01-24 11:22:58
+// @brief: 正文字数, 汉字按字、英文按单词计
+// @author: Kewin Li
+// @receiver a
+// @return int
+func (a *Article) WordCount() int {
+	return markdown.WordCount(a.PlainText())
 }
 
 type ArticleStatus uint8
@@ -56,4 +139,10 @@ const (
 	ArticleStatusPublished
 	// 仅自己可见
 	ArticleStatusPrivate
+	// 定时发表, 等待发表时间到达
+	ArticleStatusScheduled
+	// 审核中, 通过后发表
+	ArticleStatusReviewing
+	// 审核未通过
+	ArticleStatusRejected
 )
@@ -0,0 +1,104 @@
+package domain
+
+import "time"
+
+// ArticleReview
+// @Description: 帖子的一次发表审核, 保存提交时的内容快照, 审核期间作者修改帖子会使本次审核失效
+type ArticleReview struct {
+	Id       int64
+	ArtId    int64
+	AuthorId int64
+	// 提交审核时制作库的版本号
+	Version int64
+	Title   string
+	Content string
+	Status  ReviewStatus
+	// 自动审核命中的规则、人工审核填写的原因, 展示给作者
+	Reasons []string
+	// 人工审核的审核员, 自动审核为0
+	ReviewerId int64
+	Ctime      time.Time
+	Utime      time.Time
+}
+
+type ReviewStatus uint8
+
+func (s ReviewStatus) ToUint8() uint8 {
+	return uint8(s)
+}
+
+func (s ReviewStatus) String() string {
+	switch s {
+	case ReviewStatusPending:
+		return "pending"
+	case ReviewStatusApproved:
+		return "approved"
+	case ReviewStatusRejected:
+		return "rejected"
+	case ReviewStatusCanceled:
+		return "canceled"
+	default:
+		return "unknown"
+	}
+}
+
+// 审核状态, 与dao层保持一致
+const (
+	ReviewStatusUnknown ReviewStatus = iota
+	// 等待人工审核
+	ReviewStatusPending
+	// 通过, 已发表
+	ReviewStatusApproved
+	// 未通过
+	ReviewStatusRejected
+	// 作者已修改或重新提交, 本次审核失效
+	ReviewStatusCanceled
+)
+
+// ModerationResult
+// @Description: 审核链的结论及原因
+type ModerationResult struct {
+	Verdict ModerationVerdict
+	Reasons []string
+}
+
+// @func: Merge
+// @date: 2024-01-25 11:02:30
+// @brief: 审核结论-合并两个结论, 取更严格的结论, 原因全部保留
+// @author: Kewin Li
+// @receiver r
+// @param other
+// @return ModerationResult
+func (r ModerationResult) Merge(other ModerationResult) ModerationResult {
+	return ModerationResult{
+		Verdict: max(r.Verdict, other.Verdict),
+		// 复制一份, 避免与原结果共用底层数组
+		Reasons: append(append([]string(nil), r.Reasons...), other.Reasons...),
+	}
+}
+
+type ModerationVerdict uint8
+
+func (v ModerationVerdict) String() string {
+	switch v {
+	case ModerationVerdictPass:
+		return "pass"
+	case ModerationVerdictReview:
+		return "review"
+	case ModerationVerdictReject:
+		return "reject"
+	default:
+		return "unknown"
+	}
+}
+
+// 审核结论, 越靠后越严格
+const (
+	ModerationVerdictUnknown ModerationVerdict = iota
+	// 通过
+	ModerationVerdictPass
+	// 无法判断, 转人工审核
+	ModerationVerdictReview
+	// 拒绝
+	ModerationVerdictReject
+)
@@ -0,0 +1,326 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	// 行级diff时LCS表的最大规模, 超过时改用Myers算法
+	diffMaxCells = 256 * 1024
+	// Myers算法的最大编辑距离, 超过时退化为整体删除+整体新增
+	diffMaxEdits = 512
+)
+
+// ArticleRevision
+// @Description: 帖子的一个历史版本, 每次保存、发表各生成一个, 生成后不可修改
+type ArticleRevision struct {
+	Id       int64
+	ArtId    int64
+	AuthorId int64
+	// 帖子内的版本号, 从1开始递增
+	Version  int
+	Kind     RevisionKind
+	Title    string
+	Content  string
+	Category string
+	Tags     []string
+	Ctime    time.Time
+}
+
+// ArticleDiff
+// @Description: 帖子两个版本之间的差异
+type ArticleDiff struct {
+	From ArticleRevision
+	To   ArticleRevision
+	// 正文逐行差异
+	Lines []DiffLine
+}
+
+// @func: Stat
+// @date: 2024-01-22 10:20:15
+// @brief: 统计新增、删除的行数
+// @author: Kewin Li
+// @receiver d
+// @return int 新增行数
+// @return int 删除行数
+func (d ArticleDiff) Stat() (int, int) {
+	var added, deleted int
+	for _, line := range d.Lines {
+		switch line.Op {
+		case DiffOpInsert:
+			added++
+		case DiffOpDelete:
+			deleted++
+		}
+	}
+
+	return added, deleted
+}
+
+// DiffLine
+// @Description: diff结果中的一行
+type DiffLine struct {
+	Op   DiffOp
+	Text string
+}
+
+// @func: DiffLines
+// @date: 2024-01-22 10:12:40
+// @brief: 按行比较两段文本, 基于最长公共子序列, 先去掉公共的首尾行缩小规模
+// @author: Kewin Li
+// @param from
+// @param to
+// @return []DiffLine
+func DiffLines(from string, to string) []DiffLine {
+	a, b := splitLines(from), splitLines(to)
+
+	// 公共前缀
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		pre++
+	}
+	// 公共后缀
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		suf++
+	}
+
+	res := make([]DiffLine, 0, len(a)+len(b))
+	for _, line := range a[:pre] {
+		res = append(res, DiffLine{Op: DiffOpEqual, Text: line})
+	}
+	res = append(res, diffMiddle(a[pre:len(a)-suf], b[pre:len(b)-suf])...)
+	for _, line := range a[len(a)-suf:] {
+		res = append(res, DiffLine{Op: DiffOpEqual, Text: line})
+	}
+
+	return res
+}
+
+// @func: diffMiddle
+// @date: 2024-01-22 10:15:22
+// @brief: 对去掉首尾公共行后的部分求LCS并回溯出差异
+// @author: Kewin Li
+// @param a
+// @param b
+// @return []DiffLine
+func diffMiddle(a []string, b []string) []DiffLine {
+	if len(a)*len(b) > diffMaxCells {
+		res, ok := diffMyers(a, b)
+		if ok {
+			return res
+		}
+
+		res = make([]DiffLine, 0, len(a)+len(b))
+		for _, line := range a {
+			res = append(res, DiffLine{Op: DiffOpDelete, Text: line})
+		}
+		for _, line := range b {
+			res = append(res, DiffLine{Op: DiffOpInsert, Text: line})
+		}
+		return res
+	}
+
+	res := make([]DiffLine, 0, len(a)+len(b))
+
+	// lcs[i][j]: a[i:]与b[j:]的最长公共子序列长度
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			res = append(res, DiffLine{Op: DiffOpEqual, Text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			res = append(res, DiffLine{Op: DiffOpDelete, Text: a[i]})
+			i++
+		default:
+			res = append(res, DiffLine{Op: DiffOpInsert, Text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		res = append(res, DiffLine{Op: DiffOpDelete, Text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		res = append(res, DiffLine{Op: DiffOpInsert, Text: b[j]})
+	}
+
+	return res
+}
+
+// @func: diffMyers
+// @date: 2024-01-22 10:16:05
+// @brief: Myers差分算法, 内存与编辑距离的平方成正比, 适合长文本的少量修改
+// @author: Kewin Li
+// @param a
+// @param b
+// @return []DiffLine
+// @return bool 编辑距离超过diffMaxEdits时返回false
+func diffMyers(a []string, b []string) ([]DiffLine, bool) {
+	n, m := len(a), len(b)
+	maxD := min(n+m, diffMaxEdits)
+	offset := maxD + 1
+
+	// v[offset+k]: 对角线k上走得最远的x
+	v := make([]int, 2*maxD+3)
+	// trace[d]: 第d步开始前对角线[-d-1, d+1]上的v, 用于回溯
+	trace := make([][]int, 0, maxD+1)
+	for d := 0; d <= maxD; d++ {
+		snap := make([]int, 2*d+3)
+		copy(snap, v[offset-d-1:offset+d+2])
+		trace = append(trace, snap)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				return myersBacktrack(a, b, trace), true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// @func: myersBacktrack
+// @date: 2024-01-22 10:16:30
+// @brief: Myers差分算法-从终点沿每一步的选择回溯出差异
+// @author: Kewin Li
+// @param a
+// @param b
+// @param trace
+// @return []DiffLine
+func myersBacktrack(a []string, b []string, trace [][]int) []DiffLine {
+	res := make([]DiffLine, 0, len(a)+len(b))
+	x, y := len(a), len(b)
+	for d := len(trace) - 1; d >= 0; d-- {
+		snap := trace[d]
+		at := func(k int) int {
+			return snap[k+d+1]
+		}
+
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			res = append(res, DiffLine{Op: DiffOpEqual, Text: a[x-1]})
+			x--
+			y--
+		}
+
+		if d > 0 {
+			if x == prevX {
+				res = append(res, DiffLine{Op: DiffOpInsert, Text: b[y-1]})
+			} else {
+				res = append(res, DiffLine{Op: DiffOpDelete, Text: a[x-1]})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	// 回溯得到的是倒序
+	for i, j := 0, len(res)-1; i < j; i, j = i+1, j-1 {
+		res[i], res[j] = res[j], res[i]
+	}
+
+	return res
+}
+
+// @func: splitLines
+// @date: 2024-01-22 10:16:48
+// @brief: 按行切分, 空文本没有任何行
+// @author: Kewin Li
+// @param s
+// @return []string
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	return strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+}
+
+type DiffOp uint8
+
+func (o DiffOp) String() string {
+	switch o {
+	case DiffOpInsert:
+		return "+"
+	case DiffOpDelete:
+		return "-"
+	default:
+		return "="
+	}
+}
+
+const (
+	// 两个版本相同的行
+	DiffOpEqual DiffOp = iota
+	// 新版本新增的行
+	DiffOpInsert
+	// 新版本删除的行
+	DiffOpDelete
+)
+
+type RevisionKind uint8
+
+func (k RevisionKind) ToUint8() uint8 {
+	return uint8(k)
+}
+
+func (k RevisionKind) String() string {
+	switch k {
+	case RevisionKindSave:
+		return "save"
+	case RevisionKindPublish:
+		return "publish"
+	case RevisionKindRollback:
+		return "rollback"
+	default:
+		return "unknown"
+	}
+}
+
+// 版本来源, 与dao层保持一致
+const (
+	// 保存草稿
+	RevisionKindSave RevisionKind = iota
+	// 发表
+	RevisionKindPublish
+	// 回滚到历史版本
+	RevisionKindRollback
+)
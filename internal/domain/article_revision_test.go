@@ -0,0 +1,84 @@
+// Package domain
+// @Description: 单元测试-帖子历史版本
+package domain
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+// @func: TestDiffLines
+// @date: 2024-01-22 10:30:15
+// @brief: 单元测试-按行比较, 长文本超过LCS规模后改用Myers算法, 仍只输出修改过的行
+// @author: Kewin Li
+// @param t
+func TestDiffLines(t *testing.T) {
+	// 中间部分彼此交错, 首尾公共行无法消去
+	long := func(n int, changed map[int]string) string {
+		lines := make([]string, 0, n)
+		for i := 0; i < n; i++ {
+			if line, ok := changed[i]; ok {
+				lines = append(lines, line)
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("第%d行", i))
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	testCases := []struct {
+		name string
+
+		from string
+		to   string
+
+		wantEdits int
+	}{
+		{
+			name:      "短文本修改一行",
+			from:      "a\nb\nc",
+			to:        "a\nx\nc",
+			wantEdits: 2,
+		},
+		{
+			name:      "长文本少量修改",
+			from:      long(1000, map[int]string{0: "开头", 999: "结尾"}),
+			to:        long(1000, map[int]string{0: "新开头", 500: "修改", 999: "新结尾"}),
+			wantEdits: 6,
+		},
+		{
+			name:      "长文本完全不同, 退化为整体替换",
+			from:      long(1000, map[int]string{0: "开头"}),
+			to:        strings.Repeat("其他\n", 999) + "其他",
+			wantEdits: 2000,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := DiffLines(tc.from, tc.to)
+
+			// 由差异还原出两个版本
+			var from, to []string
+			edits := 0
+			for _, line := range res {
+				switch line.Op {
+				case DiffOpEqual:
+					from = append(from, line.Text)
+					to = append(to, line.Text)
+				case DiffOpDelete:
+					from = append(from, line.Text)
+					edits++
+				case DiffOpInsert:
+					to = append(to, line.Text)
+					edits++
+				}
+			}
+			assert.Equal(t, tc.from, strings.Join(from, "\n"))
+			assert.Equal(t, tc.to, strings.Join(to, "\n"))
+			assert.Equal(t, tc.wantEdits, edits)
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package domain
+
+import "time"
+
+// ArticleSchedule
+// @Description: 帖子的定时发表计划, 每篇帖子最多一个
+type ArticleSchedule struct {
+	Id       int64
+	ArtId    int64
+	AuthorId int64
+	// 预定的发表时间
+	PublishTime time.Time
+	Status      ScheduleStatus
+	// 发表失败原因
+	Error string
+	Ctime time.Time
+	Utime time.Time
+}
+
+type ScheduleStatus uint8
+
+func (s ScheduleStatus) ToUint8() uint8 {
+	return uint8(s)
+}
+
+func (s ScheduleStatus) String() string {
+	switch s {
+	case ScheduleStatusWaiting:
+		return "waiting"
+	case ScheduleStatusPublishing:
+		return "publishing"
+	case ScheduleStatusDone:
+		return "done"
+	case ScheduleStatusFailed:
+		return "failed"
+	case ScheduleStatusCanceled:
+		return "canceled"
+	default:
+		return "unknown"
+	}
+}
+
+// 定时发表状态, 与dao层保持一致
+const (
+	// 等待发表时间到达
+	ScheduleStatusWaiting ScheduleStatus = iota
+	// 已被某个结点认领, 正在发表
+	ScheduleStatusPublishing
+	// 发表成功
+	ScheduleStatusDone
+	// 发表失败, 已通知作者
+	ScheduleStatusFailed
+	// 作者已取消
+	ScheduleStatusCanceled
+)
@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+// Collection
+// @Description: 收藏夹
+type Collection struct {
+	Id          int64
+	Uid         int64
+	Name        string
+	Description string
+	// 私密收藏夹仅自己可见
+	Private bool
+	Ctime   time.Time
+	Utime   time.Time
+}
+
+// CollectionItem
+// @Description: 收藏夹中的一条收藏
+type CollectionItem struct {
+	Id int64
+	// 所在收藏夹, 0表示默认收藏夹
+	CollectId int64
+	Biz       string
+	BizId     int64
+	// 被收藏的帖子, 目前只填充标题
+	Article Article
+	// 收藏时间
+	Utime time.Time
+}
@@ -0,0 +1,36 @@
+package domain
+
+import "time"
+
+// Comment
+// @Description: 评论, 根评论下的所有回复组成一棵评论树
+type Comment struct {
+	Id int64
+	// 评论者
+	Commenter User
+	// Biz + BizId 表示评论的是哪个业务的哪一条记录
+	Biz   string
+	BizId int64
+
+	Content string
+
+	// 根评论, 为空表示自身就是根评论
+	RootComment *Comment
+	// 直接回复的评论, 为空表示自身就是根评论
+	ParentComment *Comment
+	// 根评论预加载的回复
+	Children []Comment
+
+	Ctime time.Time
+	Utime time.Time
+}
+
+// @func: IsRoot
+// @date: 2024-01-08 20:41:17
+// @brief: 是否为根评论
+// @author: Kewin Li
+// @receiver c
+// @return bool
+func (c Comment) IsRoot() bool {
+	return c.ParentComment == nil
+}
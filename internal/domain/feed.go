@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// FeedEvent
+// @Description: feed流事件, 关注的作者发表了帖子
+type FeedEvent struct {
+	Id int64
+	// 收件人, 拉模式下为0
+	Uid int64
+	// 发表的帖子
+	ArtId int64
+	// 发表帖子的作者
+	AuthorId int64
+	Ctime    time.Time
+}
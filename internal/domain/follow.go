@@ -0,0 +1,41 @@
+package domain
+
+import "time"
+
+// FollowRelation
+// @Description: 关注关系, Follower 关注了 Followee
+type FollowRelation struct {
+	Id       int64
+	Follower int64
+	Followee int64
+	Ctime    time.Time
+}
+
+// FollowStatics
+// @Description: 用户的粉丝数、关注数
+type FollowStatics struct {
+	Uid int64
+	// 粉丝数
+	Followers int64
+	// 关注数
+	Followees int64
+}
+
+// FollowState
+// @Description: 两个用户之间的关注状态
+type FollowState struct {
+	// 是否关注了对方
+	Followed bool
+	// 对方是否关注了自己
+	FollowedBack bool
+}
+
+// @func: Mutual
+// @date: 2024-01-12 20:31:05
+// @brief: 是否互相关注
+// @author: Kewin Li
+// @receiver f
+// @return bool
+func (f FollowState) Mutual() bool {
+	return f.Followed && f.FollowedBack
+}
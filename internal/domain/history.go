@@ -1,9 +1,14 @@
 package domain
 
+import "time"
+
 // HistoryRecord
 // @Description: 浏览历史记录
 type HistoryRecord struct {
+	Id     int64
 	BizId  int64
 	Biz    string
 	UserId int64
+	// 最近一次浏览时间
+	Utime time.Time
 }
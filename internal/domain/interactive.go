@@ -5,6 +5,7 @@ type Interactive struct {
 	ReadCnt    int64
 	LikeCnt    int64
 	CollectCnt int64
+	CommentCnt int64
 	Liked      bool
 	Collected  bool
 }
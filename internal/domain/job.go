@@ -5,12 +5,66 @@ import (
 	"time"
 )
 
+// 任务调度统一使用的cron表达式解析器, 支持秒级
+var jobCronParser = cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// 统计错过的调度次数时最多遍历的次数, 避免高频任务长时间停机后遍历过久
+const jobMaxDueFires = 10000
+
 type Job struct {
-	Id           int64
-	Name         string
-	Expression   string
+	Id         int64
+	Name       string
+	Expression string
+	// cron表达式所在时区, 如Asia/Shanghai, 为空时使用服务器本地时区
+	TimeZone     string
 	ExecutorName string
-	CancelFunc   func()
+	// 执行器配置, 由执行器自行解析, 如HTTP执行器的URL、请求体
+	Cfg    string
+	Status JobStatus
+	// 抢占成功后的版本号, 续约、释放时校验, 防止任务被回收后旧结点继续修改
+	Version int
+	// 下一次调度时间点
+	NextExecTime time.Time
+
+	// 执行模式: 单结点、广播、分片
+	Mode JobMode
+	// 分片总数, 仅分片模式有效
+	ShardTotal int
+	// 本次执行的分片, 仅分片模式下由调度器填充
+	Shard JobShard
+
+	// 上游任务, 到期后还需所有上游在本周期内执行成功才会被调度
+	Upstreams []int64
+	// 亲和标签, 只有包含全部标签的结点才会执行, 为空时不限制
+	Affinity map[string]string
+
+	// 错过调度的处理策略, 仅单结点模式有效
+	MisfirePolicy JobMisfirePolicy
+	// 补跑次数上限, 仅MisfireFireAll有效
+	MisfireCap int
+	// 剩余待补跑的次数
+	CatchUp int
+	// 累计错过(未执行)的调度次数
+	MisfireCnt int64
+
+	// 重试策略: 执行失败后按退避间隔重试, 最多MaxRetries次, 且不晚于下一次cron调度
+	MaxRetries int
+	// 首次重试间隔, 之后每次翻倍
+	RetryInterval time.Duration
+	// 当前连续重试次数
+	RetryCnt int
+
+	// 最近一次执行情况
+	LastExecTime time.Time
+	LastResult   JobResult
+	LastError    string
+	// 最近一次执行成功的开始时间, 作为依赖关系的周期边界
+	LastSuccessTime time.Time
+
+	Ctime time.Time
+	Utime time.Time
+
+	CancelFunc func()
 }
 
 // @func: NextTime
@@ -20,7 +74,363 @@ type Job struct {
 // @receiver j
 // @return time.Time
 func (j Job) NextTime() time.Time {
-	c := cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
-	s, _ := c.Parse(j.Expression)
-	return s.Next(time.Now())
+	return j.NextTimeAfter(time.Now())
+}
+
+// @func: NextTimeAfter
+// @date: 2024-01-19 14:05:36
+// @brief: 按cron表达式及其时区计算t之后的调度时间点
+// @author: Kewin Li
+// @receiver j
+// @param t
+// @return time.Time
+func (j Job) NextTimeAfter(t time.Time) time.Time {
+	s, err := j.schedule()
+	if err != nil {
+		return time.Time{}
+	}
+	return s.Next(t)
+}
+
+// @func: DueFires
+// @date: 2024-01-19 14:08:12
+// @brief: 从NextExecTime到now之间应当调度的次数, 至少为1
+// @author: Kewin Li
+// @receiver j
+// @param now
+// @return int
+func (j Job) DueFires(now time.Time) int {
+	s, err := j.schedule()
+	if err != nil {
+		return 1
+	}
+
+	cnt := 1
+	for t := s.Next(j.NextExecTime); !t.IsZero() && !t.After(now) && cnt < jobMaxDueFires; t = s.Next(t) {
+		cnt++
+	}
+
+	return cnt
+}
+
+// @func: ValidExpression
+// @date: 2024-01-16 14:05:21
+// @brief: 校验cron表达式及时区, 与NextTime使用同一个解析器
+// @author: Kewin Li
+// @receiver j
+// @return error
+func (j Job) ValidExpression() error {
+	_, err := j.schedule()
+	return err
+}
+
+// @func: schedule
+// @date: 2024-01-19 14:02:48
+// @brief: 解析cron表达式, 指定时区时以CRON_TZ前缀交给解析器
+// @author: Kewin Li
+// @receiver j
+// @return cron.Schedule
+// @return error
+func (j Job) schedule() (cron.Schedule, error) {
+	expr := j.Expression
+	if j.TimeZone != "" {
+		expr = "CRON_TZ=" + j.TimeZone + " " + expr
+	}
+
+	return jobCronParser.Parse(expr)
+}
+
+// @func: RetryTime
+// @date: 2024-01-17 10:05:26
+// @brief: 计算失败后的重试时间点, 超过最大重试次数或晚于下一次cron调度时不再重试
+// @author: Kewin Li
+// @receiver j
+// @param now
+// @return time.Time
+// @return bool 是否重试
+func (j Job) RetryTime(now time.Time) (time.Time, bool) {
+	if j.RetryCnt >= j.MaxRetries || j.RetryInterval <= 0 {
+		return time.Time{}, false
+	}
+
+	// 指数退避
+	retryAt := now.Add(j.RetryInterval << j.RetryCnt)
+	if !retryAt.Before(j.NextTime()) {
+		return time.Time{}, false
+	}
+
+	return retryAt, true
+}
+
+// @func: MatchLabels
+// @date: 2024-01-20 10:05:18
+// @brief: 结点标签是否满足任务的亲和标签
+// @author: Kewin Li
+// @receiver j
+// @param labels 结点标签
+// @return bool
+func (j Job) MatchLabels(labels map[string]string) bool {
+	for key, val := range j.Affinity {
+		if labels[key] != val {
+			return false
+		}
+	}
+
+	return true
+}
+
+// @func: UpstreamSatisfied
+// @date: 2024-01-19 10:05:12
+// @brief: 上游任务在本周期内是否已执行成功, 即上游在当前任务最近一次成功之后又成功过
+// @author: Kewin Li
+// @receiver j
+// @param upstream
+// @return bool
+func (j Job) UpstreamSatisfied(upstream Job) bool {
+	return upstream.LastSuccessTime.After(j.LastSuccessTime)
+}
+
+// JobDAG
+// @Description: 任务依赖关系, key为下游任务, value为其上游任务
+type JobDAG map[int64][]int64
+
+// @func: DependsOn
+// @date: 2024-01-19 10:08:40
+// @brief: jobId是否直接或间接依赖target
+// @author: Kewin Li
+// @receiver g
+// @param jobId
+// @param target
+// @return bool
+func (g JobDAG) DependsOn(jobId int64, target int64) bool {
+	visited := map[int64]struct{}{}
+	stack := []int64{jobId}
+	for len(stack) > 0 {
+		cur := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		for _, up := range g[cur] {
+			if up == target {
+				return true
+			}
+			if _, ok := visited[up]; ok {
+				continue
+			}
+			visited[up] = struct{}{}
+			stack = append(stack, up)
+		}
+	}
+
+	return false
+}
+
+// @func: Component
+// @date: 2024-01-19 10:12:25
+// @brief: jobId所有直接或间接的上游、下游任务, 包含自身
+// @author: Kewin Li
+// @receiver g
+// @param jobId
+// @return []int64
+func (g JobDAG) Component(jobId int64) []int64 {
+	// 无向邻接表
+	adj := map[int64][]int64{}
+	for down, ups := range g {
+		for _, up := range ups {
+			adj[down] = append(adj[down], up)
+			adj[up] = append(adj[up], down)
+		}
+	}
+
+	res := []int64{jobId}
+	visited := map[int64]struct{}{jobId: {}}
+	for i := 0; i < len(res); i++ {
+		for _, next := range adj[res[i]] {
+			if _, ok := visited[next]; ok {
+				continue
+			}
+			visited[next] = struct{}{}
+			res = append(res, next)
+		}
+	}
+
+	return res
+}
+
+// JobNode
+// @Description: 调度结点的负载情况, 由各结点定时上报
+type JobNode struct {
+	Name   string
+	Labels map[string]string
+	// 正在运行的任务数及最多同时运行的任务数
+	Running  int64
+	Capacity int64
+	// 进程CPU使用率 0~1
+	CPU   float64
+	Utime time.Time
+}
+
+// @func: Load
+// @date: 2024-01-20 10:08:42
+// @brief: 结点负载, 取任务数占比与CPU使用率中较大者
+// @author: Kewin Li
+// @receiver n
+// @return float64 0~1
+func (n JobNode) Load() float64 {
+	load := n.CPU
+	if n.Capacity > 0 {
+		load = max(load, float64(n.Running)/float64(n.Capacity))
+	}
+
+	return load
+}
+
+// JobShard
+// @Description: 分片任务某一轮调度中的一个分片
+type JobShard struct {
+	Id int64
+	// 分片序号, 从0开始
+	Index int
+	// 抢占成功后的版本号, 续约、完成时校验
+	Version int
+	// 所属轮次, 即该轮的调度时间点
+	Round time.Time
+}
+
+// JobExecution
+// @Description: 任务的一次执行记录
+type JobExecution struct {
+	Id    int64
+	JobId int64
+	// 执行任务的结点
+	Node string
+	// 分片序号, 仅分片模式有效
+	ShardIndex int
+	// 第几次尝试, 1表示正常调度, 大于1表示重试
+	Attempt   int
+	Result    JobResult
+	Error     string
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+// JobCallback
+// @Description: 远程任务执行完毕后的回调结果
+type JobCallback struct {
+	Token   string
+	Success bool
+	Error   string
+}
+
+type JobMode uint8
+
+func (m JobMode) ToUint8() uint8 {
+	return uint8(m)
+}
+
+func (m JobMode) String() string {
+	switch m {
+	case JobModeSingle:
+		return "single"
+	case JobModeBroadcast:
+		return "broadcast"
+	case JobModeSharding:
+		return "sharding"
+	default:
+		return "unknown"
+	}
+}
+
+// 任务执行模式, 与dao层保持一致
+const (
+	// 单结点: 抢占成功的一个结点执行
+	JobModeSingle JobMode = iota
+	// 广播: 每个结点各自执行一次
+	JobModeBroadcast
+	// 分片: 拆分为ShardTotal个分片, 由各结点分别抢占执行
+	JobModeSharding
+)
+
+type JobMisfirePolicy uint8
+
+func (p JobMisfirePolicy) ToUint8() uint8 {
+	return uint8(p)
+}
+
+func (p JobMisfirePolicy) String() string {
+	switch p {
+	case MisfireFireOnce:
+		return "fire_once"
+	case MisfireSkip:
+		return "skip"
+	case MisfireFireAll:
+		return "fire_all"
+	default:
+		return "unknown"
+	}
+}
+
+// 错过调度(调度时间点已过去超过阈值才被抢占)的处理策略
+const (
+	// 只执行一次, 其余错过的调度丢弃
+	MisfireFireOnce JobMisfirePolicy = iota
+	// 全部丢弃, 等待下一个调度时间点
+	MisfireSkip
+	// 逐次补跑, 最多MisfireCap次
+	MisfireFireAll
+)
+
+type JobStatus uint8
+
+func (s JobStatus) ToUint8() uint8 {
+	return uint8(s)
+}
+
+func (s JobStatus) String() string {
+	switch s {
+	case JobStatusWaiting:
+		return "waiting"
+	case JobStatusRunning:
+		return "running"
+	case JobStatusPaused:
+		return "paused"
+	default:
+		return "unknown"
+	}
+}
+
+// 任务状态, 与dao层保持一致
+const (
+	// 等待调度
+	JobStatusWaiting JobStatus = iota
+	// 正在运行
+	JobStatusRunning
+	// 暂停调度
+	JobStatusPaused
+)
+
+type JobResult uint8
+
+func (r JobResult) ToUint8() uint8 {
+	return uint8(r)
+}
+
+func (r JobResult) String() string {
+	switch r {
+	case JobResultSuccess:
+		return "success"
+	case JobResultFailed:
+		return "failed"
+	default:
+		return "none"
+	}
 }
+
+// 任务执行结果
+const (
+	// 从未执行
+	JobResultNone JobResult = iota
+	// 执行成功
+	JobResultSuccess
+	// 执行失败
+	JobResultFailed
+)
@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// Notification
+// @Description: 站内通知
+type Notification struct {
+	Id int64
+	// 通知谁
+	Uid int64
+	// BizId + Biz 共同表示由哪个业务的哪一条记录触发
+	Biz   string
+	BizId int64
+	// 触发通知的事件发生时间, 同一事件重复投递只保留一条通知
+	Etime   time.Time
+	Content string
+	Ctime   time.Time
+}
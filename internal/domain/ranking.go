@@ -0,0 +1,8 @@
+package domain
+
+// RankingItem
+// @Description: 增量热榜中跟踪的帖子及其互动数, 帖子内容只保留摘要
+type RankingItem struct {
+	Article     Article
+	Interactive Interactive
+}
@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+// SearchArticle
+// @Description: 搜索索引中的已发表帖子
+type SearchArticle struct {
+	Id         int64
+	AuthorId   int64
+	AuthorName string
+	Title      string
+	Content    string
+	Utime      time.Time
+}
+
+// SearchArticleHit
+// @Description: 搜索命中的帖子, 高亮内容已做HTML转义
+type SearchArticleHit struct {
+	Article SearchArticle
+	Score   float64
+	// 高亮后的标题
+	Title string
+	// 高亮后的正文摘要
+	Snippet string
+}
+
+// SearchArticleResult
+// @Description: 帖子搜索结果
+type SearchArticleResult struct {
+	Total int
+	Hits  []SearchArticleHit
+}
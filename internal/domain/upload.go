@@ -0,0 +1,118 @@
+package domain
+
+import (
+	"regexp"
+	"time"
+)
+
+// 帖子正文中引用上传文件的地址
+var uploadURLRegexp = regexp.MustCompile(`/files/([0-9a-f]{64})`)
+
+// Upload
+// @Description: 用户上传的图片、附件; 相同内容只存储一份, 按内容哈希引用
+type Upload struct {
+	Id     int64
+	UserId int64
+	// 内容SHA256, 十六进制
+	Hash        string
+	Size        int64
+	ContentType string
+	Filename    string
+	Kind        UploadKind
+	Status      UploadStatus
+	Ctime       time.Time
+	Utime       time.Time
+}
+
+// @func: URL
+// @date: 2024-01-24 14:40:12
+// @brief: 帖子中引用该文件的地址, 访问时跳转到有时效的下载地址
+// @author: Kewin Li
+// @receiver u
+// @return string
+func (u *Upload) URL() string {
+	return "/files/" + u.Hash
+}
+
+// @func: ReferencedUploads
+// @date: 2024-01-28 15:30:12
+// @brief: 提取正文中引用的上传文件哈希, 去重
+// @author: Kewin Li
+// @param content
+// @return []string
+func ReferencedUploads(content string) []string {
+	var hashes []string
+	seen := make(map[string]struct{})
+
+	for _, m := range uploadURLRegexp.FindAllStringSubmatch(content, -1) {
+		if _, ok := seen[m[1]]; ok {
+			continue
+		}
+		seen[m[1]] = struct{}{}
+		hashes = append(hashes, m[1])
+	}
+
+	return hashes
+}
+
+// UploadPresign
+// @Description: 预签名上传结果; 文件已存在(秒传)时 Upload 已就绪, 不需要再上传
+type UploadPresign struct {
+	Upload   Upload
+	Method   string
+	URL      string
+	Headers  map[string]string
+	ExpireAt time.Time
+}
+
+type UploadKind uint8
+
+func (k UploadKind) ToUint8() uint8 {
+	return uint8(k)
+}
+
+func (k UploadKind) String() string {
+	switch k {
+	case UploadKindImage:
+		return "image"
+	case UploadKindAttachment:
+		return "attachment"
+	default:
+		return "unknown"
+	}
+}
+
+// 上传文件类型
+const (
+	UploadKindUnknown UploadKind = iota
+	// 图片
+	UploadKindImage
+	// 附件
+	UploadKindAttachment
+)
+
+type UploadStatus uint8
+
+func (s UploadStatus) ToUint8() uint8 {
+	return uint8(s)
+}
+
+func (s UploadStatus) String() string {
+	switch s {
+	case UploadStatusPending:
+		return "pending"
+	case UploadStatusReady:
+		return "ready"
+	default:
+		return "unknown"
+	}
+}
+
+// 上传状态
+const (
+	UploadStatusUnknown UploadStatus = iota
+	// 已签发上传地址, 等待客户端上传并确认
+	UploadStatusPending
+	// 已上传并校验通过
+	UploadStatusReady
+)
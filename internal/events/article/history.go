@@ -18,10 +18,10 @@ type HistoryRecordConsumer struct {
 	l      logger.Logger
 }
 
-func NewHistoryRecordConsumer(repo repository.InteractiveRepository,
+func NewHistoryRecordConsumer(repo repository.HistoryRepository,
 	client sarama.Client,
-	l logger.Logger) *InteractiveReadEventConsumer {
-	return &InteractiveReadEventConsumer{
+	l logger.Logger) *HistoryRecordConsumer {
+	return &HistoryRecordConsumer{
 		repo:   repo,
 		client: client,
 		l:      l,
@@ -29,23 +29,23 @@ func NewHistoryRecordConsumer(repo repository.InteractiveRepository,
 }
 
 // @func: Start
-// @date: 2023-12-17 20:25:40
-// @brief: 启动消费
+// @date: 2024-01-06 16:02:18
+// @brief: 启动消费-批量提交
 // @author: Kewin Li
-// @receiver i
+// @receiver h
 // @return error
 func (h *HistoryRecordConsumer) Start() error {
-	cg, err := sarama.NewConsumerGroupFromClient("interactive", h.client)
+	// 注意: 与阅读数消费者不能使用同一个消费者组, 否则同一条消息只会被其中一个消费
+	cg, err := sarama.NewConsumerGroupFromClient("history", h.client)
 	if err != nil {
 		return err
 	}
 
 	go func() {
 
-		err2 := cg.Consume(context.Background(), []string{TopicReadEvent}, saramax.NewHandler[ReadEvent](h.Consume, h.l))
+		err2 := cg.Consume(context.Background(), []string{TopicReadEvent}, saramax.NewBatchHandler[ReadEvent](h.BatchConsume, h.l))
 		if err2 != nil {
-			//TODO: 日志埋点
-
+			h.l.ERROR("浏览记录消费者退出", logger.Error(err2))
 		}
 
 	}()
@@ -53,37 +53,23 @@ func (h *HistoryRecordConsumer) Start() error {
 	return nil
 }
 
-// @func: Consume
-// @date: 2023-12-17 20:31:03
-// @brief: 帖子模块-实际消费业务处理-阅读数+1
+// @func: StartV1
+// @date: 2023-12-17 20:25:40
+// @brief: 启动消费-逐条提交
 // @author: Kewin Li
-// @receiver i
-// @param msg
-// @param events
+// @receiver h
 // @return error
-func (h *HistoryRecordConsumer) Consume(msg *sarama.ConsumerMessage, event ReadEvent) error {
-
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-	defer cancel()
-	return h.repo.AddRecord(ctx, domain.HistoryRecord{
-		BizId:  event.ArtId,
-		Biz:    "article", // 帖子业务标识
-		UserId: event.UserId,
-	})
-}
-
 func (h *HistoryRecordConsumer) StartV1() error {
-	cg, err := sarama.NewConsumerGroupFromClient("interactive", h.client)
+	cg, err := sarama.NewConsumerGroupFromClient("history", h.client)
 	if err != nil {
 		return err
 	}
 
 	go func() {
 
-		err2 := cg.Consume(context.Background(), []string{TopicReadEvent}, saramax.NewBatchHandler[ReadEvent](h.BatchConsume, h.l))
+		err2 := cg.Consume(context.Background(), []string{TopicReadEvent}, saramax.NewHandler[ReadEvent](h.Consume, h.l))
 		if err2 != nil {
-			//TODO: 日志埋点
-
+			h.l.ERROR("浏览记录消费者退出", logger.Error(err2))
 		}
 
 	}()
@@ -91,6 +77,21 @@ func (h *HistoryRecordConsumer) StartV1() error {
 	return nil
 }
 
+// @func: Consume
+// @date: 2023-12-17 20:31:03
+// @brief: 帖子模块-实际消费业务处理-新增浏览记录
+// @author: Kewin Li
+// @receiver h
+// @param msg
+// @param events
+// @return error
+func (h *HistoryRecordConsumer) Consume(msg *sarama.ConsumerMessage, event ReadEvent) error {
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	return h.repo.AddRecord(ctx, h.convertsRecord(msg, event))
+}
+
 // @func: BatchConsume
 // @date: 2023-12-19 12:46:41
 // @brief: 帖子模块-实际消费业务处理-批量提交
@@ -100,17 +101,44 @@ func (h *HistoryRecordConsumer) StartV1() error {
 // @param event
 // @return error
 func (h *HistoryRecordConsumer) BatchConsume(msgs []*sarama.ConsumerMessage, event []ReadEvent) error {
-	//bizs := make([]string, 0, len(event))
-	//bizIds := make([]int64, 0, len(event))
-	//
-	//for _, evt := range event {
-	//	bizs = append(bizs, "article")
-	//	bizIds = append(bizIds, evt.ArtId)
-	//}
-	//
-	//ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-	//defer cancel()
-	//
-	//return h.repo.BatchAddRecord(ctx, bizs, bizIds)
-	panic("接口位实现")
+	records := make([]domain.HistoryRecord, 0, len(event))
+
+	for i, evt := range event {
+		// 未登录用户不记录浏览历史
+		if evt.UserId <= 0 {
+			continue
+		}
+		records = append(records, h.convertsRecord(msgs[i], evt))
+	}
+
+	if len(records) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	return h.repo.BatchAddRecord(ctx, records)
+}
+
+// @func: convertsRecord
+// @date: 2024-01-06 16:10:25
+// @brief: 读事件转换为浏览记录, 以消息产生的时间作为浏览时间
+// @author: Kewin Li
+// @receiver h
+// @param msg
+// @param event
+// @return domain.HistoryRecord
+func (h *HistoryRecordConsumer) convertsRecord(msg *sarama.ConsumerMessage, event ReadEvent) domain.HistoryRecord {
+	utime := msg.Timestamp
+	if utime.IsZero() {
+		utime = time.Now()
+	}
+
+	return domain.HistoryRecord{
+		BizId:  event.ArtId,
+		Biz:    "article", // 帖子业务标识
+		UserId: event.UserId,
+		Utime:  utime,
+	}
 }
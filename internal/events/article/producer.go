@@ -8,11 +8,22 @@ import (
 )
 
 const (
-	TopicReadEvent = "article_read"
+	TopicReadEvent    = "article_read"
+	TopicPublishEvent = "article_publish"
+	// 撤回事件, 供搜索服务删除索引
+	TopicWithdrawEvent = "article_withdraw"
+	// 点赞、收藏事件, 供增量热榜实时更新分数
+	TopicInteractiveEvent = "article_interactive"
+	// 定时发表失败事件, 供通知服务提醒作者
+	TopicScheduleFailedEvent = "article_schedule_failed"
 )
 
 type Producer interface {
 	ProducerReadEvent(event ReadEvent) error
+	ProducerPublishEvent(event PublishEvent) error
+	ProducerWithdrawEvent(event WithdrawEvent) error
+	ProducerInteractiveEvent(event InteractiveEvent) error
+	ProducerScheduleFailedEvent(event ScheduleFailedEvent) error
 }
 
 // SaramaSyncProducer
@@ -48,6 +59,90 @@ func (s *SaramaSyncProducer) ProducerReadEvent(event ReadEvent) error {
 	return err
 }
 
+// @func: ProducerPublishEvent
+// @date: 2024-01-13 15:20:42
+// @brief: 帖子模块发表事件-通知feed流推送
+// @author: Kewin Li
+// @receiver s
+// @param event
+// @return error
+func (s *SaramaSyncProducer) ProducerPublishEvent(event PublishEvent) error {
+	val, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = s.producer.SendMessage(&sarama.ProducerMessage{
+		Topic: TopicPublishEvent,
+		Value: sarama.StringEncoder(val),
+	})
+
+	return err
+}
+
+// @func: ProducerWithdrawEvent
+// @date: 2024-01-23 16:02:30
+// @brief: 帖子模块撤回事件-通知搜索服务删除索引
+// @author: Kewin Li
+// @receiver s
+// @param event
+// @return error
+func (s *SaramaSyncProducer) ProducerWithdrawEvent(event WithdrawEvent) error {
+	val, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = s.producer.SendMessage(&sarama.ProducerMessage{
+		Topic: TopicWithdrawEvent,
+		Value: sarama.StringEncoder(val),
+	})
+
+	return err
+}
+
+// @func: ProducerInteractiveEvent
+// @date: 2024-01-21 10:12:36
+// @brief: 帖子模块点赞、收藏事件-通知增量热榜更新分数
+// @author: Kewin Li
+// @receiver s
+// @param event
+// @return error
+func (s *SaramaSyncProducer) ProducerInteractiveEvent(event InteractiveEvent) error {
+	val, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = s.producer.SendMessage(&sarama.ProducerMessage{
+		Topic: TopicInteractiveEvent,
+		Value: sarama.StringEncoder(val),
+	})
+
+	return err
+}
+
+// @func: ProducerScheduleFailedEvent
+// @date: 2024-01-22 15:10:26
+// @brief: 帖子模块定时发表失败事件-通知作者
+// @author: Kewin Li
+// @receiver s
+// @param event
+// @return error
+func (s *SaramaSyncProducer) ProducerScheduleFailedEvent(event ScheduleFailedEvent) error {
+	val, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = s.producer.SendMessage(&sarama.ProducerMessage{
+		Topic: TopicScheduleFailedEvent,
+		Value: sarama.StringEncoder(val),
+	})
+
+	return err
+}
+
 // ReadEvent
 // @Description: 帖子模块-读事件
 type ReadEvent struct {
@@ -56,3 +151,46 @@ type ReadEvent struct {
 	// 谁查询的
 	UserId int64
 }
+
+// PublishEvent
+// @Description: 帖子模块-发表事件
+type PublishEvent struct {
+	// 哪一篇文章
+	ArtId int64
+	// 谁发表的
+	AuthorId int64
+	// 发表时间(毫秒)
+	Ctime int64
+}
+
+// WithdrawEvent
+// @Description: 帖子模块-撤回事件
+type WithdrawEvent struct {
+	// 哪一篇文章
+	ArtId int64
+	// 谁撤回的
+	AuthorId int64
+}
+
+// InteractiveEvent
+// @Description: 帖子模块-点赞、收藏事件
+type InteractiveEvent struct {
+	// 哪一篇文章
+	ArtId int64
+	// 点赞数、收藏数的变化量, 取消时为负数
+	LikeDelta    int64
+	CollectDelta int64
+}
+
+// ScheduleFailedEvent
+// @Description: 帖子模块-定时发表失败事件
+type ScheduleFailedEvent struct {
+	// 哪一篇文章
+	ArtId int64
+	// 通知谁
+	AuthorId int64
+	// 预定的发表时间(毫秒)
+	PublishTime int64
+	// 失败原因
+	Reason string
+}
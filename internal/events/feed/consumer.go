@@ -0,0 +1,75 @@
+// Package feed
+// @Description: 领域事件-feed流消费帖子发表消息
+package feed
+
+import (
+	"context"
+	"github.com/IBM/sarama"
+	"kitbook/internal/domain"
+	"kitbook/internal/events/article"
+	"kitbook/internal/service"
+	"kitbook/pkg/logger"
+	"kitbook/pkg/saramax"
+	"time"
+)
+
+type ArticlePublishEventConsumer struct {
+	svc    service.FeedService
+	client sarama.Client
+
+	l logger.Logger
+}
+
+func NewArticlePublishEventConsumer(svc service.FeedService,
+	client sarama.Client,
+	l logger.Logger) *ArticlePublishEventConsumer {
+	return &ArticlePublishEventConsumer{
+		svc:    svc,
+		client: client,
+		l:      l,
+	}
+}
+
+// @func: Start
+// @date: 2024-01-13 16:50:11
+// @brief: 启动消费
+// @author: Kewin Li
+// @receiver a
+// @return error
+func (a *ArticlePublishEventConsumer) Start() error {
+	cg, err := sarama.NewConsumerGroupFromClient("feed", a.client)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		err2 := cg.Consume(context.Background(),
+			[]string{article.TopicPublishEvent},
+			saramax.NewHandler[article.PublishEvent](a.Consume, a.l))
+		if err2 != nil {
+			a.l.ERROR("退出feed流消费循环", logger.Error(err2))
+		}
+	}()
+
+	return nil
+}
+
+// @func: Consume
+// @date: 2024-01-13 16:53:27
+// @brief: feed流-实际消费业务处理-推送/写入发件箱
+// @author: Kewin Li
+// @receiver a
+// @param msg
+// @param event
+// @return error
+func (a *ArticlePublishEventConsumer) Consume(msg *sarama.ConsumerMessage, event article.PublishEvent) error {
+	// 写扩散可能涉及较多粉丝
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return a.svc.CreateFeedEvent(ctx, domain.FeedEvent{
+		ArtId:    event.ArtId,
+		AuthorId: event.AuthorId,
+		Ctime:    time.UnixMilli(event.Ctime),
+	})
+}
@@ -0,0 +1,79 @@
+// Package notification
+// @Description: 领域事件-站内通知消费帖子定时发表失败消息
+package notification
+
+import (
+	"context"
+	"fmt"
+	"github.com/IBM/sarama"
+	"kitbook/internal/domain"
+	"kitbook/internal/events/article"
+	"kitbook/internal/service"
+	"kitbook/pkg/logger"
+	"kitbook/pkg/saramax"
+	"time"
+)
+
+type ScheduleFailedEventConsumer struct {
+	svc    service.NotificationService
+	client sarama.Client
+	l      logger.Logger
+}
+
+func NewScheduleFailedEventConsumer(svc service.NotificationService,
+	client sarama.Client,
+	l logger.Logger) *ScheduleFailedEventConsumer {
+	return &ScheduleFailedEventConsumer{
+		svc:    svc,
+		client: client,
+		l:      l,
+	}
+}
+
+// @func: Start
+// @date: 2024-01-28 10:40:26
+// @brief: 启动消费
+// @author: Kewin Li
+// @receiver s
+// @return error
+func (s *ScheduleFailedEventConsumer) Start() error {
+	cg, err := sarama.NewConsumerGroupFromClient("notification", s.client)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		err2 := cg.Consume(context.Background(),
+			[]string{article.TopicScheduleFailedEvent},
+			saramax.NewHandler[article.ScheduleFailedEvent](s.Consume, s.l))
+		if err2 != nil {
+			s.l.ERROR("定时发表失败通知消费者退出", logger.Error(err2))
+		}
+	}()
+
+	return nil
+}
+
+// @func: Consume
+// @date: 2024-01-28 10:43:51
+// @brief: 帖子模块-实际消费业务处理-站内通知作者定时发表失败
+// @author: Kewin Li
+// @receiver s
+// @param msg
+// @param event
+// @return error
+func (s *ScheduleFailedEventConsumer) Consume(msg *sarama.ConsumerMessage, event article.ScheduleFailedEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	publishTime := time.UnixMilli(event.PublishTime)
+	return s.svc.Notify(ctx, domain.Notification{
+		Uid:   event.AuthorId,
+		Biz:   "article",
+		BizId: event.ArtId,
+		// 同一个计划只会失败一次, 以预定发表时间去重
+		Etime: publishTime,
+		Content: fmt.Sprintf("帖子定时发表失败, 预定发表时间: %s, 原因: %s",
+			publishTime.Format(time.DateTime), event.Reason),
+	})
+}
@@ -0,0 +1,143 @@
+// Package notification
+// @Description: 单元测试-定时发表失败通知作者
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"kitbook/internal/domain"
+	"kitbook/internal/events/article"
+	"kitbook/internal/repository"
+	"kitbook/internal/repository/dao"
+	repomocks "kitbook/internal/repository/mocks"
+	"kitbook/internal/service"
+	"kitbook/pkg/logger"
+	"testing"
+	"time"
+)
+
+// forwardProducer
+// @Description: 把定时发表失败消息按kafka的编码方式直接投递给消费者
+type forwardProducer struct {
+	article.Producer
+	consumer *ScheduleFailedEventConsumer
+
+	consumeErr error
+}
+
+func (f *forwardProducer) ProducerScheduleFailedEvent(event article.ScheduleFailedEvent) error {
+	val, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	msg := &sarama.ConsumerMessage{
+		Topic: article.TopicScheduleFailedEvent,
+		Value: val,
+	}
+	var evt article.ScheduleFailedEvent
+	err = json.Unmarshal(msg.Value, &evt)
+	if err != nil {
+		return err
+	}
+
+	f.consumeErr = f.consumer.Consume(msg, evt)
+	return nil
+}
+
+// @func: TestScheduleFailedEventConsumer_Consume
+// @date: 2024-01-28 12:05:33
+// @brief: 单元测试-定时发表失败后, 作者收到站内通知
+// @author: Kewin Li
+// @param t
+func TestScheduleFailedEventConsumer_Consume(t *testing.T) {
+	now := time.Now()
+	schedule := domain.ArticleSchedule{
+		Id:          10,
+		ArtId:       1,
+		AuthorId:    123,
+		PublishTime: time.UnixMilli(now.Add(-time.Second).UnixMilli()),
+		Status:      domain.ScheduleStatusWaiting,
+	}
+	content := "帖子定时发表失败, 预定发表时间: " + schedule.PublishTime.Format(time.DateTime) +
+		", 原因: 模拟数据库错误"
+
+	testCases := []struct {
+		name string
+
+		mock func(t *testing.T, mock sqlmock.Sqlmock)
+
+		wantErr error
+	}{
+		{
+			name: "通知写入成功",
+			mock: func(t *testing.T, mock sqlmock.Sqlmock) {
+				mock.ExpectExec("INSERT INTO `notifications`").
+					WithArgs(int64(123), "article", int64(1), schedule.PublishTime.UnixMilli(), content, sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			name: "通知写入失败, 消息重新消费",
+			mock: func(t *testing.T, mock sqlmock.Sqlmock) {
+				mock.ExpectExec("INSERT INTO `notifications`").
+					WithArgs(int64(123), "article", int64(1), schedule.PublishTime.UnixMilli(), content, sqlmock.AnyArg()).
+					WillReturnError(errors.New("模拟数据库错误"))
+			},
+			wantErr: errors.New("模拟数据库错误"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sqlDB, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer sqlDB.Close()
+			tc.mock(t, mock)
+
+			db, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlDB,
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{
+				SkipDefaultTransaction: true,
+				DisableAutomaticPing:   true,
+			})
+			require.NoError(t, err)
+
+			notificationSvc := service.NewNormalNotificationService(
+				repository.NewNormalNotificationRepository(dao.NewGormNotificationDao(db)))
+			producer := &forwardProducer{
+				consumer: NewScheduleFailedEventConsumer(notificationSvc, nil, logger.NewNopLogger()),
+			}
+
+			repo := repomocks.NewMockArticleRepository(ctrl)
+			scheduleRepo := repomocks.NewMockArticleScheduleRepository(ctrl)
+			scheduleRepo.EXPECT().FindDue(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				Return([]domain.ArticleSchedule{schedule}, nil)
+			scheduleRepo.EXPECT().Claim(gomock.Any(), schedule).Return(true, nil)
+			repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(domain.Article{}, errors.New("模拟数据库错误"))
+			scheduleRepo.EXPECT().Finish(gomock.Any(), int64(10), domain.ScheduleStatusFailed, "模拟数据库错误").
+				Return(nil)
+
+			artSvc := service.NewNormalArticleService(repo, nil, scheduleRepo, nil, nil, producer,
+				logger.NewNopLogger())
+			cnt, err := artSvc.PublishDue(context.Background(), now)
+			require.NoError(t, err)
+			assert.Equal(t, 0, cnt)
+
+			assert.Equal(t, tc.wantErr, producer.consumeErr)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,117 @@
+// Package ranking
+// @Description: 领域事件-增量热榜消费阅读、点赞、收藏消息
+package ranking
+
+import (
+	"context"
+	"errors"
+	"github.com/IBM/sarama"
+	"kitbook/internal/domain"
+	"kitbook/internal/events/article"
+	"kitbook/internal/service"
+	"kitbook/pkg/logger"
+	"kitbook/pkg/saramax"
+	"time"
+)
+
+type InteractiveEventConsumer struct {
+	svc    service.RankingService
+	client sarama.Client
+
+	l logger.Logger
+}
+
+func NewInteractiveEventConsumer(svc service.RankingService,
+	client sarama.Client,
+	l logger.Logger) *InteractiveEventConsumer {
+	return &InteractiveEventConsumer{
+		svc:    svc,
+		client: client,
+		l:      l,
+	}
+}
+
+// @func: Start
+// @date: 2024-01-21 12:05:36
+// @brief: 启动消费, 阅读消息批量消费, 点赞、收藏消息逐条消费
+// @author: Kewin Li
+// @receiver i
+// @return error
+func (i *InteractiveEventConsumer) Start() error {
+	// 注意: 与阅读数、浏览记录消费者不能使用同一个消费者组
+	readCg, err := sarama.NewConsumerGroupFromClient("ranking_read", i.client)
+	if err != nil {
+		return err
+	}
+
+	intrCg, err := sarama.NewConsumerGroupFromClient("ranking_interactive", i.client)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		err2 := readCg.Consume(context.Background(),
+			[]string{article.TopicReadEvent},
+			saramax.NewBatchHandler[article.ReadEvent](i.BatchConsumeRead, i.l))
+		if err2 != nil {
+			i.l.ERROR("退出热榜阅读消息消费循环", logger.Error(err2))
+		}
+	}()
+
+	go func() {
+		err2 := intrCg.Consume(context.Background(),
+			[]string{article.TopicInteractiveEvent},
+			saramax.NewHandler[article.InteractiveEvent](i.Consume, i.l))
+		if err2 != nil {
+			i.l.ERROR("退出热榜点赞、收藏消息消费循环", logger.Error(err2))
+		}
+	}()
+
+	return nil
+}
+
+// @func: BatchConsumeRead
+// @date: 2024-01-21 12:10:48
+// @brief: 增量热榜-一批阅读消息按帖子合并后更新分数
+// @author: Kewin Li
+// @receiver i
+// @param msgs
+// @param events
+// @return error
+func (i *InteractiveEventConsumer) BatchConsumeRead(msgs []*sarama.ConsumerMessage, events []article.ReadEvent) error {
+	cnts := make(map[int64]int64, len(events))
+	for _, evt := range events {
+		cnts[evt.ArtId]++
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+
+	var errs []error
+	for artId, cnt := range cnts {
+		err := i.svc.Incr(ctx, artId, domain.Interactive{ReadCnt: cnt})
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// @func: Consume
+// @date: 2024-01-21 12:14:20
+// @brief: 增量热榜-点赞、收藏消息更新分数
+// @author: Kewin Li
+// @receiver i
+// @param msg
+// @param event
+// @return error
+func (i *InteractiveEventConsumer) Consume(msg *sarama.ConsumerMessage, event article.InteractiveEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	return i.svc.Incr(ctx, event.ArtId, domain.Interactive{
+		LikeCnt:    event.LikeDelta,
+		CollectCnt: event.CollectDelta,
+	})
+}
@@ -0,0 +1,126 @@
+// Package search
+// @Description: 领域事件-搜索服务消费帖子发表、撤回消息
+package search
+
+import (
+	"context"
+	"fmt"
+	"github.com/IBM/sarama"
+	"kitbook/internal/events/article"
+	"kitbook/internal/service"
+	"kitbook/pkg/logger"
+	"kitbook/pkg/saramax"
+	"os"
+	"time"
+)
+
+// 启动时重建索引的超时时间
+const rebuildTimeout = 10 * time.Minute
+
+type ArticleEventConsumer struct {
+	svc    service.SearchService
+	client sarama.Client
+
+	l logger.Logger
+}
+
+func NewArticleEventConsumer(svc service.SearchService,
+	client sarama.Client,
+	l logger.Logger) *ArticleEventConsumer {
+	return &ArticleEventConsumer{
+		svc:    svc,
+		client: client,
+		l:      l,
+	}
+}
+
+// @func: Start
+// @date: 2024-01-23 16:35:18
+// @brief: 启动消费, 同时从线上库重建本结点的索引
+// @author: Kewin Li
+// @receiver a
+// @return error
+func (a *ArticleEventConsumer) Start() error {
+	node, err := os.Hostname()
+	if err != nil {
+		return err
+	}
+
+	// 注意: 索引在每个结点的进程内, 每个结点都要消费全部消息, 消费者组按结点区分
+	pubCg, err := sarama.NewConsumerGroupFromClient(fmt.Sprintf("search_publish_%s", node), a.client)
+	if err != nil {
+		return err
+	}
+
+	withdrawCg, err := sarama.NewConsumerGroupFromClient(fmt.Sprintf("search_withdraw_%s", node), a.client)
+	if err != nil {
+		return err
+	}
+
+	go a.rebuild()
+
+	go func() {
+		err2 := pubCg.Consume(context.Background(),
+			[]string{article.TopicPublishEvent},
+			saramax.NewHandler[article.PublishEvent](a.ConsumePublish, a.l))
+		if err2 != nil {
+			a.l.ERROR("退出搜索发表消息消费循环", logger.Error(err2))
+		}
+	}()
+
+	go func() {
+		err2 := withdrawCg.Consume(context.Background(),
+			[]string{article.TopicWithdrawEvent},
+			saramax.NewHandler[article.WithdrawEvent](a.ConsumeWithdraw, a.l))
+		if err2 != nil {
+			a.l.ERROR("退出搜索撤回消息消费循环", logger.Error(err2))
+		}
+	}()
+
+	return nil
+}
+
+// @func: rebuild
+// @date: 2024-01-23 16:38:40
+// @brief: 结点启动时索引为空, 从线上库重建
+// @author: Kewin Li
+// @receiver a
+func (a *ArticleEventConsumer) rebuild() {
+	ctx, cancel := context.WithTimeout(context.Background(), rebuildTimeout)
+	defer cancel()
+
+	_, err := a.svc.RebuildArticleIndex(ctx)
+	if err != nil {
+		a.l.ERROR("搜索索引重建失败", logger.Error(err))
+	}
+}
+
+// @func: ConsumePublish
+// @date: 2024-01-23 16:40:12
+// @brief: 搜索服务-帖子发表后更新索引
+// @author: Kewin Li
+// @receiver a
+// @param msg
+// @param event
+// @return error
+func (a *ArticleEventConsumer) ConsumePublish(msg *sarama.ConsumerMessage, event article.PublishEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	return a.svc.IndexArticle(ctx, event.ArtId)
+}
+
+// @func: ConsumeWithdraw
+// @date: 2024-01-23 16:41:36
+// @brief: 搜索服务-帖子撤回后删除索引
+// @author: Kewin Li
+// @receiver a
+// @param msg
+// @param event
+// @return error
+func (a *ArticleEventConsumer) ConsumeWithdraw(msg *sarama.ConsumerMessage, event article.WithdrawEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	return a.svc.RemoveArticle(ctx, event.ArtId)
+}
@@ -101,6 +101,7 @@ func (a *ArticleHandlerSuite) TestEdit() {
 					Content:  "第一个帖子的内容",
 					AuthorId: 123,
 					Status:   domain.ArticleStatusUnpublished,
+					Version:  1,
 				}, art)
 			},
 
@@ -124,6 +125,7 @@ func (a *ArticleHandlerSuite) TestEdit() {
 					Content:  "修改前的内容",
 					AuthorId: 123,
 					Status:   domain.ArticleStatusPublished,
+					Version:  1,
 					Ctime:    456,
 					Utime:    789,
 				}).Error
@@ -145,6 +147,7 @@ func (a *ArticleHandlerSuite) TestEdit() {
 					Content:  "修改后的内容",
 					AuthorId: 123,
 					Status:   domain.ArticleStatusUnpublished,
+					Version:  2,
 					Ctime:    456,
 				}, art)
 
@@ -154,6 +157,7 @@ func (a *ArticleHandlerSuite) TestEdit() {
 				Id:      2,
 				Title:   "修改后的标题",
 				Content: "修改后的内容",
+				Version: 1,
 			},
 
 			wantCode: http.StatusOK,
@@ -283,6 +287,7 @@ func (a *ArticleHandlerSuite) TestPublish() {
 					Content:  "新发表的内容",
 					Status:   domain.ArticleStatusPublished,
 					AuthorId: 123,
+					Version:  1,
 				}, artProduce)
 
 				var artLive dao.PublishedArticle
@@ -323,6 +328,7 @@ func (a *ArticleHandlerSuite) TestPublish() {
 					Content:  "修改前的内容",
 					Status:   domain.ArticleStatusPublished,
 					AuthorId: 123,
+					Version:  1,
 					Ctime:    666,
 					Utime:    666,
 				}
@@ -348,6 +354,7 @@ func (a *ArticleHandlerSuite) TestPublish() {
 					Content:  "修改后的内容",
 					Status:   domain.ArticleStatusPublished,
 					AuthorId: 123,
+					Version:  2,
 				}, artProduce)
 
 				var artLive dao.PublishedArticle
@@ -363,6 +370,7 @@ func (a *ArticleHandlerSuite) TestPublish() {
 					Content:  "修改后的内容",
 					Status:   domain.ArticleStatusPublished,
 					AuthorId: 123,
+					Version:  1,
 				}, artLive)
 
 			},
@@ -371,6 +379,7 @@ func (a *ArticleHandlerSuite) TestPublish() {
 				Id:      2,
 				Title:   "修改后的标题",
 				Content: "修改后的内容",
+				Version: 1,
 			},
 
 			wantCode: http.StatusOK,
@@ -678,6 +687,7 @@ type Article struct {
 	Id      int64
 	Title   string `json:"title"`
 	Content string `json:"content"`
+	Version int64  `json:"version"`
 }
 
 // Result[T any]
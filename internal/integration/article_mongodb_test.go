@@ -127,6 +127,7 @@ func (a *ArticleMongoDBHandlerSuite) TestEdit() {
 					Content:  "第一个帖子的内容",
 					AuthorId: 123,
 					Status:   domain.ArticleStatusUnpublished,
+					Version:  1,
 				}, art)
 			},
 
@@ -178,6 +179,7 @@ func (a *ArticleMongoDBHandlerSuite) TestEdit() {
 					AuthorId: 123,
 					Status:   domain.ArticleStatusUnpublished,
 					Ctime:    456,
+					Version:  1,
 				}, art)
 
 			},
@@ -337,6 +339,7 @@ func (a *ArticleMongoDBHandlerSuite) TestPublish() {
 					Content:  "新发表的内容",
 					Status:   domain.ArticleStatusPublished,
 					AuthorId: 123,
+					Version:  1,
 				}, artProduce)
 
 				var artLive dao.PublishedArticle
@@ -409,6 +412,7 @@ func (a *ArticleMongoDBHandlerSuite) TestPublish() {
 					Content:  "修改后的内容",
 					Status:   domain.ArticleStatusPublished,
 					AuthorId: 123,
+					Version:  1,
 				}, artProduce)
 
 				var artLive dao.PublishedArticle
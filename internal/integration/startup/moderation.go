@@ -0,0 +1,11 @@
+package startup
+
+import (
+	"kitbook/internal/service/moderation"
+	"kitbook/pkg/logger"
+)
+
+// InitModerator 测试使用空审核链, 帖子发表时直接通过审核
+func InitModerator(l logger.Logger) moderation.Moderator {
+	return moderation.NewChain(l)
+}
@@ -0,0 +1,13 @@
+package startup
+
+import (
+	"kitbook/pkg/storage"
+	"os"
+	"path/filepath"
+)
+
+// InitStorage 测试使用本地存储, 不依赖对象存储服务
+func InitStorage() storage.Storage {
+	return storage.NewLocalStorage(filepath.Join(os.TempDir(), "kitbook-storage"),
+		"http://localhost:8080/storage", []byte("kitbook"))
+}
@@ -45,8 +45,46 @@ func InitWebServer() *gin.Engine {
 		thirdPartySet,
 		interactiveSvcSet,
 
+		dao.NewGormHistoryDao,
+		repository.NewNormalHistoryRepository,
+		service.NewNormalHistoryService,
+
+		dao.NewGormCommentDao,
+		repository.NewCacheCommentRepository,
+		service.NewNormalCommentService,
+
+		dao.NewGormFollowDao,
+		cache.NewRedisFollowCache,
+		repository.NewCacheFollowRepository,
+		service.NewNormalFollowService,
+
+		dao.NewGormFeedDao,
+		repository.NewNormalFeedRepository,
+		service.NewPushPullFeedService,
+
+		dao.NewGormCollectionDao,
+		repository.NewNormalCollectionRepository,
+		service.NewNormalCollectionService,
+
+		cache.NewRedisRankingCache,
+		ioc.InitLocalRankingCache,
+		repository.NewCacheRankingRepository,
+		service.NewBatchRankingService,
+		ioc.InitLeaderboards,
+
+		dao.NewGormJobDao,
+		cache.NewRedisJobCallbackCache,
+		cache.NewRedisJobNodeCache,
+		repository.NewPreemptJobRepository,
+		service.NewCronJobService,
+
 		dao.NewGormUserDao,
 		dao.NewGormArticleDao,
+		dao.NewGormArticleTagDao,
+		dao.NewGormUploadRefDao,
+		dao.NewGormArticleRevisionDao,
+		dao.NewGormArticleScheduleDao,
+		dao.NewGormArticleReviewDao,
 		cache.NewRedisUserCache,
 		cache.NewRedisCodeCache,
 		cache.NewRedisArticleCache,
@@ -55,6 +93,24 @@ func InitWebServer() *gin.Engine {
 		repository.NewCacheUserRepository,
 		repository.NewcodeRepository,
 		repository.NewCacheArticleRepository,
+		repository.NewNormalArticleRevisionRepository,
+		repository.NewNormalArticleScheduleRepository,
+		repository.NewNormalArticleReviewRepository,
+		InitModerator, //不做内容审核拦截
+
+		dao.NewMemoryArticleSearchDao,
+		repository.NewNormalArticleSearchRepository,
+		service.NewNormalSearchService,
+
+		dao.NewGormUploadDao,
+		repository.NewNormalUploadRepository,
+		service.NewNormalUploadService,
+		InitStorage, //本地存储
+		ioc.InitUploadLimits,
+
+		dao.NewGormNotificationDao,
+		repository.NewNormalNotificationRepository,
+		service.NewNormalNotificationService,
 
 		article.NewSaramaSyncProducer,
 
@@ -71,13 +127,24 @@ func InitWebServer() *gin.Engine {
 		web.NewUserHandler,
 		web.NewArticleHandler,
 		web.NewOAuth2WechatHandler,
+		web.NewHistoryHandler,
+		web.NewCommentHandler,
+		web.NewFollowHandler,
+		web.NewFeedHandler,
+		web.NewCollectionHandler,
+		web.NewRankingHandler,
+		web.NewSearchHandler,
+		web.NewUploadHandler,
+		web.NewNotificationHandler,
+		ioc.InitJobHandler,
+		ioc.InitReviewHandler,
 		ioc.InitWebServer,
 	)
 
 	return gin.Default()
 }
 
-func NewArticleHandler(dao dao.ArticleDao) *web.ArticleHandler {
+func NewArticleHandler(artDao dao.ArticleDao) *web.ArticleHandler {
 	wire.Build(
 		thirdPartySet,
 		userSvcProvider,
@@ -86,7 +153,16 @@ func NewArticleHandler(dao dao.ArticleDao) *web.ArticleHandler {
 		article.NewSaramaSyncProducer,
 
 		cache.NewRedisArticleCache,
+		dao.NewGormArticleTagDao,
+		dao.NewGormUploadRefDao,
+		dao.NewGormArticleRevisionDao,
+		dao.NewGormArticleScheduleDao,
+		dao.NewGormArticleReviewDao,
 		repository.NewCacheArticleRepository,
+		repository.NewNormalArticleRevisionRepository,
+		repository.NewNormalArticleScheduleRepository,
+		repository.NewNormalArticleReviewRepository,
+		InitModerator, //不做内容审核拦截
 		service.NewNormalArticleService,
 		web.NewArticleHandler,
 	)
@@ -98,6 +174,7 @@ func NewInteractiveService() service.InteractiveService {
 	wire.Build(
 		thirdPartySet,
 		interactiveSvcSet,
+		article.NewSaramaSyncProducer,
 	)
-	return service.NewArticleInteractiveService(nil, nil)
+	return service.NewArticleInteractiveService(nil, nil, nil)
 }
@@ -36,42 +36,108 @@ func InitWebServer() *gin.Engine {
 	codeRepository := repository.NewcodeRepository(codeCache)
 	smsService := ioc.InitSmsService(limiter)
 	codeService := service.NewPhoneCodeService(codeRepository, smsService)
-	userHandler := web.NewUserHandler(userService, codeService, jwtHandler, logger)
+	followDao := dao.NewGormFollowDao(db)
+	followCache := cache.NewRedisFollowCache(cmdable)
+	followRepository := repository.NewCacheFollowRepository(followDao, followCache, logger)
+	followService := service.NewNormalFollowService(followRepository)
+	userHandler := web.NewUserHandler(userService, codeService, jwtHandler, followService, logger)
 	wechatService := InitWechatService()
 	oAuth2WechatHandler := web.NewOAuth2WechatHandler(wechatService, userService, jwtHandler, logger)
 	articleDao := dao.NewGormArticleDao(db)
 	articleCache := cache.NewRedisArticleCache(cmdable)
-	articleRepository := repository.NewCacheArticleRepository(articleDao, articleCache, userRepository)
+	articleTagDao := dao.NewGormArticleTagDao(db)
+	uploadRefDao := dao.NewGormUploadRefDao(db)
+	articleRevisionDao := dao.NewGormArticleRevisionDao(db)
+	articleRepository := repository.NewCacheArticleRepository(articleDao, articleTagDao, articleRevisionDao, uploadRefDao, articleCache, userRepository)
 	client := InitSaramaClient()
 	syncProducer := InitSyncProducer(client)
 	producer := article.NewSaramaSyncProducer(syncProducer)
-	articleService := service.NewNormalArticleService(articleRepository, producer, logger)
+	articleRevisionRepository := repository.NewNormalArticleRevisionRepository(articleRevisionDao)
+	articleScheduleDao := dao.NewGormArticleScheduleDao(db)
+	articleScheduleRepository := repository.NewNormalArticleScheduleRepository(articleScheduleDao)
+	articleReviewDao := dao.NewGormArticleReviewDao(db)
+	articleReviewRepository := repository.NewNormalArticleReviewRepository(articleReviewDao)
+	moderator := InitModerator(logger)
+	articleService := service.NewNormalArticleService(articleRepository, articleRevisionRepository, articleScheduleRepository, articleReviewRepository, moderator, producer, logger)
 	interactiveDao := dao.NewGORMInteractiveDao(db)
 	interactiveCache := cache.NewRedisInteractiveCache(cmdable)
 	interactiveRepository := repository.NewArticleInteractiveRepository(interactiveDao, interactiveCache, logger)
-	interactiveService := service.NewArticleInteractiveService(interactiveRepository, logger)
+	interactiveService := service.NewArticleInteractiveService(interactiveRepository, producer, logger)
 	articleHandler := web.NewArticleHandler(articleService, interactiveService, logger)
-	engine := ioc.InitWebServer(v, userHandler, oAuth2WechatHandler, articleHandler)
+	historyDao := dao.NewGormHistoryDao(db)
+	historyRepository := repository.NewNormalHistoryRepository(historyDao)
+	historyService := service.NewNormalHistoryService(historyRepository)
+	historyHandler := web.NewHistoryHandler(historyService, logger)
+	commentDao := dao.NewGormCommentDao(db)
+	commentRepository := repository.NewCacheCommentRepository(commentDao, interactiveCache, logger)
+	commentService := service.NewNormalCommentService(commentRepository, articleRepository)
+	commentHandler := web.NewCommentHandler(commentService, logger)
+	followHandler := web.NewFollowHandler(followService, logger)
+	feedDao := dao.NewGormFeedDao(db)
+	feedRepository := repository.NewNormalFeedRepository(feedDao)
+	feedService := service.NewPushPullFeedService(feedRepository, followService)
+	feedHandler := web.NewFeedHandler(feedService, logger)
+	collectionDao := dao.NewGormCollectionDao(db)
+	collectionRepository := repository.NewNormalCollectionRepository(collectionDao, interactiveCache, articleRepository, logger)
+	collectionService := service.NewNormalCollectionService(collectionRepository)
+	collectionHandler := web.NewCollectionHandler(collectionService, logger)
+	rankingCache := cache.NewRedisRankingCache(cmdable)
+	localRankingCache := ioc.InitLocalRankingCache()
+	rankingRepository := repository.NewCacheRankingRepository(rankingCache, localRankingCache, logger)
+	v2 := ioc.InitLeaderboards()
+	rankingService := service.NewBatchRankingService(interactiveService, articleService, rankingRepository, v2)
+	rankingHandler := web.NewRankingHandler(rankingService, interactiveService, logger)
+	jobDao := dao.NewGormJobDao(db)
+	jobCallbackCache := cache.NewRedisJobCallbackCache(cmdable)
+	jobNodeCache := cache.NewRedisJobNodeCache(cmdable)
+	jobRepository := repository.NewPreemptJobRepository(jobDao, jobCallbackCache, jobNodeCache)
+	jobService := service.NewCronJobService(jobRepository, logger)
+	jobHandler := ioc.InitJobHandler(jobService, logger)
+	articleSearchDao := dao.NewMemoryArticleSearchDao()
+	articleSearchRepository := repository.NewNormalArticleSearchRepository(articleSearchDao)
+	searchService := service.NewNormalSearchService(articleSearchRepository, articleRepository, userRepository, logger)
+	searchHandler := web.NewSearchHandler(searchService, logger)
+	uploadDao := dao.NewGormUploadDao(db)
+	uploadRepository := repository.NewNormalUploadRepository(uploadDao)
+	storageStorage := InitStorage()
+	uploadLimits := ioc.InitUploadLimits()
+	uploadService := service.NewNormalUploadService(uploadRepository, storageStorage, uploadLimits, logger)
+	uploadHandler := web.NewUploadHandler(uploadService, storageStorage, logger)
+	reviewHandler := ioc.InitReviewHandler(articleService, logger)
+	notificationDao := dao.NewGormNotificationDao(db)
+	notificationRepository := repository.NewNormalNotificationRepository(notificationDao)
+	notificationService := service.NewNormalNotificationService(notificationRepository)
+	notificationHandler := web.NewNotificationHandler(notificationService, logger)
+	engine := ioc.InitWebServer(v, userHandler, oAuth2WechatHandler, articleHandler, historyHandler, commentHandler, followHandler, feedHandler, collectionHandler, rankingHandler, jobHandler, searchHandler, uploadHandler, reviewHandler, notificationHandler)
 	return engine
 }
 
-func NewArticleHandler(dao2 dao.ArticleDao) *web.ArticleHandler {
+func NewArticleHandler(artDao dao.ArticleDao) *web.ArticleHandler {
 	cmdable := InitRedis()
 	articleCache := cache.NewRedisArticleCache(cmdable)
 	db := InitDB()
 	userDao := dao.NewGormUserDao(db)
 	userCache := cache.NewRedisUserCache(cmdable)
 	userRepository := repository.NewCacheUserRepository(userDao, userCache)
-	articleRepository := repository.NewCacheArticleRepository(dao2, articleCache, userRepository)
+	articleTagDao := dao.NewGormArticleTagDao(db)
+	uploadRefDao := dao.NewGormUploadRefDao(db)
+	articleRevisionDao := dao.NewGormArticleRevisionDao(db)
+	articleRepository := repository.NewCacheArticleRepository(artDao, articleTagDao, articleRevisionDao, uploadRefDao, articleCache, userRepository)
 	client := InitSaramaClient()
 	syncProducer := InitSyncProducer(client)
 	producer := article.NewSaramaSyncProducer(syncProducer)
 	logger := InitLogger()
-	articleService := service.NewNormalArticleService(articleRepository, producer, logger)
+	articleRevisionRepository := repository.NewNormalArticleRevisionRepository(articleRevisionDao)
+	articleScheduleDao := dao.NewGormArticleScheduleDao(db)
+	articleScheduleRepository := repository.NewNormalArticleScheduleRepository(articleScheduleDao)
+	articleReviewDao := dao.NewGormArticleReviewDao(db)
+	articleReviewRepository := repository.NewNormalArticleReviewRepository(articleReviewDao)
+	moderator := InitModerator(logger)
+	articleService := service.NewNormalArticleService(articleRepository, articleRevisionRepository, articleScheduleRepository, articleReviewRepository, moderator, producer, logger)
 	interactiveDao := dao.NewGORMInteractiveDao(db)
 	interactiveCache := cache.NewRedisInteractiveCache(cmdable)
 	interactiveRepository := repository.NewArticleInteractiveRepository(interactiveDao, interactiveCache, logger)
-	interactiveService := service.NewArticleInteractiveService(interactiveRepository, logger)
+	interactiveService := service.NewArticleInteractiveService(interactiveRepository, producer, logger)
 	articleHandler := web.NewArticleHandler(articleService, interactiveService, logger)
 	return articleHandler
 }
@@ -83,7 +149,10 @@ func NewInteractiveService() service.InteractiveService {
 	interactiveCache := cache.NewRedisInteractiveCache(cmdable)
 	logger := InitLogger()
 	interactiveRepository := repository.NewArticleInteractiveRepository(interactiveDao, interactiveCache, logger)
-	interactiveService := service.NewArticleInteractiveService(interactiveRepository, logger)
+	client := InitSaramaClient()
+	syncProducer := InitSyncProducer(client)
+	producer := article.NewSaramaSyncProducer(syncProducer)
+	interactiveService := service.NewArticleInteractiveService(interactiveRepository, producer, logger)
 	return interactiveService
 }
 
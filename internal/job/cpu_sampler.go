@@ -0,0 +1,43 @@
+package job
+
+import (
+	"runtime"
+	"time"
+)
+
+// cpuSampler
+// @Description: 按进程CPU时间与墙上时间估算CPU使用率, 进程CPU时间由平台相关的processCpuTime提供
+type cpuSampler struct {
+	lastCpu  time.Duration
+	lastWall time.Time
+}
+
+func newCpuSampler() *cpuSampler {
+	c := &cpuSampler{}
+	c.Sample()
+
+	return c
+}
+
+// @func: Sample
+// @date: 2024-01-20 10:50:32
+// @brief: CPU采样-距上一次采样期间的CPU使用率, 按核数归一化, 非并发安全
+// @author: Kewin Li
+// @receiver c
+// @return float64 0~1, 平台不支持时恒为0
+func (c *cpuSampler) Sample() float64 {
+	cpu, ok := processCpuTime()
+	if !ok {
+		return 0
+	}
+
+	now := time.Now()
+	deltaCpu, deltaWall := cpu-c.lastCpu, now.Sub(c.lastWall)
+	c.lastCpu, c.lastWall = cpu, now
+	if deltaWall <= 0 {
+		return 0
+	}
+
+	usage := float64(deltaCpu) / (float64(deltaWall) * float64(runtime.NumCPU()))
+	return min(max(usage, 0), 1)
+}
@@ -0,0 +1,15 @@
+//go:build !unix
+
+package job
+
+import "time"
+
+// @func: processCpuTime
+// @date: 2024-01-20 10:48:15
+// @brief: CPU采样-非unix平台暂不支持, 负载上报的CPU使用率恒为0
+// @author: Kewin Li
+// @return time.Duration
+// @return bool
+func processCpuTime() (time.Duration, bool) {
+	return 0, false
+}
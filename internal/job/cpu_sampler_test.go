@@ -0,0 +1,30 @@
+//go:build unix
+
+package job
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// @func: TestCpuSampler_Sample
+// @date: 2024-01-20 11:02:40
+// @brief: 单元测试-CPU采样, 不依赖GC即可反映忙碌期间的CPU使用率
+// @author: Kewin Li
+// @param t
+func TestCpuSampler_Sample(t *testing.T) {
+	c := newCpuSampler()
+
+	// 空转一段时间, 期间不分配内存, 不会触发GC
+	deadline := time.Now().Add(100 * time.Millisecond)
+	n := 0
+	for time.Now().Before(deadline) {
+		n++
+	}
+
+	usage := c.Sample()
+	assert.Greater(t, usage, 0.0)
+	assert.LessOrEqual(t, usage, 1.0)
+	assert.Greater(t, n, 0)
+}
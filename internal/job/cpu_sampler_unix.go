@@ -0,0 +1,24 @@
+//go:build unix
+
+package job
+
+import (
+	"syscall"
+	"time"
+)
+
+// @func: processCpuTime
+// @date: 2024-01-20 10:48:15
+// @brief: CPU采样-进程累计占用的CPU时间(用户态+内核态)
+// @author: Kewin Li
+// @return time.Duration
+// @return bool 是否读取成功
+func processCpuTime() (time.Duration, bool) {
+	var usage syscall.Rusage
+	err := syscall.Getrusage(syscall.RUSAGE_SELF, &usage)
+	if err != nil {
+		return 0, false
+	}
+
+	return time.Duration(usage.Utime.Nano() + usage.Stime.Nano()), true
+}
@@ -0,0 +1,159 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"io"
+	"kitbook/internal/domain"
+	"kitbook/internal/service"
+	"kitbook/pkg/logger"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// 远程任务默认超时时间
+	httpJobDefaultTimeout = 30 * time.Second
+	// 非2xx响应时, 错误信息中最多保留的响应体长度
+	httpJobRespMaxLen = 512
+)
+
+// HttpJobCfg
+// @Description: HTTP执行器配置, 对应Job.Cfg
+type HttpJobCfg struct {
+	Method  string            `json:"method"`
+	Url     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+	Body    string            `json:"body"`
+	// 超时时间 秒, 回调模式下包含等待回调的时间
+	Timeout int64 `json:"timeout"`
+	// 回调模式: 远程服务接收请求后立即返回, 执行完毕后再回调上报结果
+	Callback bool `json:"callback"`
+}
+
+// HttpExecutor
+// @Description: 远程任务调用
+type HttpExecutor struct {
+	client *http.Client
+	svc    service.JobService
+	// 远程服务上报结果的地址
+	callbackUrl string
+	l           logger.Logger
+}
+
+func NewHttpExecutor(client *http.Client, svc service.JobService, callbackUrl string, l logger.Logger) *HttpExecutor {
+	return &HttpExecutor{
+		client:      client,
+		svc:         svc,
+		callbackUrl: callbackUrl,
+		l:           l,
+	}
+}
+
+// @func: Name
+// @date: 2024-01-17 14:45:10
+// @brief: 执行器-远程任务
+// @author: Kewin Li
+// @receiver h
+// @return string
+func (h *HttpExecutor) Name() string {
+	return "http"
+}
+
+// @func: Exec
+// @date: 2024-01-17 14:46:32
+// @brief: 执行器-调用远程接口执行任务, 非2xx视为失败
+// @author: Kewin Li
+// @receiver h
+// @param ctx
+// @param job
+// @return error
+func (h *HttpExecutor) Exec(ctx context.Context, job domain.Job) error {
+	cfg, err := ParseHttpJobCfg(job.Cfg)
+	if err != nil {
+		return err
+	}
+
+	timeout := httpJobDefaultTimeout
+	if cfg.Timeout > 0 {
+		timeout = time.Duration(cfg.Timeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, cfg.Method, cfg.Url, strings.NewReader(cfg.Body))
+	if err != nil {
+		return err
+	}
+
+	for key, val := range cfg.Headers {
+		req.Header.Set(key, val)
+	}
+	req.Header.Set("X-Job-Id", strconv.FormatInt(job.Id, 10))
+	req.Header.Set("X-Job-Name", job.Name)
+	if job.Mode == domain.JobModeSharding {
+		// 远程服务按分片序号处理自己负责的数据
+		req.Header.Set("X-Job-Shard-Index", strconv.Itoa(job.Shard.Index))
+		req.Header.Set("X-Job-Shard-Total", strconv.Itoa(job.ShardTotal))
+	}
+
+	var token string
+	if cfg.Callback {
+		// 每次执行一个token, 远程服务回调时携带
+		token = uuid.New().String()
+		// 先登记token再下发, 回调接口只接受登记过的token
+		err = h.svc.RegisterCallback(ctx, token, timeout)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("X-Job-Callback-Token", token)
+		req.Header.Set("X-Job-Callback-Url", h.callbackUrl)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, httpJobRespMaxLen))
+		return fmt.Errorf("远程任务响应异常: %d %s", resp.StatusCode, string(body))
+	}
+
+	if !cfg.Callback {
+		return nil
+	}
+
+	// 回调模式: 等待远程服务上报执行结果
+	return h.svc.WaitCallback(ctx, token)
+}
+
+// @func: ParseHttpJobCfg
+// @date: 2024-01-17 14:55:48
+// @brief: 执行器-解析并校验HTTP执行器配置
+// @author: Kewin Li
+// @param cfg
+// @return HttpJobCfg
+// @return error
+func ParseHttpJobCfg(cfg string) (HttpJobCfg, error) {
+	var res HttpJobCfg
+	err := json.Unmarshal([]byte(cfg), &res)
+	if err != nil {
+		return res, fmt.Errorf("HTTP执行器配置解析失败: %w", err)
+	}
+
+	if !strings.HasPrefix(res.Url, "http://") && !strings.HasPrefix(res.Url, "https://") {
+		return res, fmt.Errorf("HTTP执行器配置URL不合法: %s", res.Url)
+	}
+
+	if res.Method == "" {
+		res.Method = http.MethodPost
+	}
+
+	return res, nil
+}
@@ -0,0 +1,135 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"kitbook/internal/domain"
+	"kitbook/internal/service"
+	svcmocks "kitbook/internal/service/mocks"
+	"kitbook/pkg/logger"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// @func: TestHttpExecutor_Exec
+// @date: 2024-01-17 15:40:18
+// @brief: 单元测试-HTTP执行器
+// @author: Kewin Li
+// @param t
+func TestHttpExecutor_Exec(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock    func(ctrl *gomock.Controller) service.JobService
+		handler http.HandlerFunc
+		// 执行器配置, %s替换为测试服务地址
+		cfg  string
+		mode domain.JobMode
+
+		wantErr bool
+	}{
+		{
+			name: "同步调用成功",
+			mock: func(ctrl *gomock.Controller) service.JobService {
+				return svcmocks.NewMockJobService(ctrl)
+			},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "1", r.Header.Get("X-Job-Id"))
+				assert.Empty(t, r.Header.Get("X-Job-Shard-Index"))
+				w.WriteHeader(http.StatusOK)
+			},
+			cfg: `{"url":"%s","method":"POST","body":"{}"}`,
+		},
+		{
+			name: "分片任务携带分片信息",
+			mock: func(ctrl *gomock.Controller) service.JobService {
+				return svcmocks.NewMockJobService(ctrl)
+			},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "2", r.Header.Get("X-Job-Shard-Index"))
+				assert.Equal(t, "4", r.Header.Get("X-Job-Shard-Total"))
+				w.WriteHeader(http.StatusOK)
+			},
+			cfg:  `{"url":"%s"}`,
+			mode: domain.JobModeSharding,
+		},
+		{
+			name: "非2xx视为失败",
+			mock: func(ctrl *gomock.Controller) service.JobService {
+				return svcmocks.NewMockJobService(ctrl)
+			},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			cfg:     `{"url":"%s"}`,
+			wantErr: true,
+		},
+		{
+			name: "回调模式, 远程任务执行失败",
+			mock: func(ctrl *gomock.Controller) service.JobService {
+				var registered string
+				svc := svcmocks.NewMockJobService(ctrl)
+				svc.EXPECT().RegisterCallback(gomock.Any(), gomock.Any(), httpJobDefaultTimeout).
+					DoAndReturn(func(ctx context.Context, token string, expiration time.Duration) error {
+						assert.NotEmpty(t, token)
+						registered = token
+						return nil
+					})
+				svc.EXPECT().WaitCallback(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, token string) error {
+						// 等待的必须是下发前登记的token
+						assert.Equal(t, registered, token)
+						return errors.New("远程任务执行失败")
+					})
+				return svc
+			},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				assert.NotEmpty(t, r.Header.Get("X-Job-Callback-Token"))
+				w.WriteHeader(http.StatusAccepted)
+			},
+			cfg:     `{"url":"%s","callback":true}`,
+			wantErr: true,
+		},
+		{
+			name: "回调模式, 登记token失败不下发任务",
+			mock: func(ctrl *gomock.Controller) service.JobService {
+				svc := svcmocks.NewMockJobService(ctrl)
+				svc.EXPECT().RegisterCallback(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(errors.New("redis错误"))
+				return svc
+			},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				t.Error("登记token失败时不应下发任务")
+			},
+			cfg:     `{"url":"%s","callback":true}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			server := httptest.NewServer(tc.handler)
+			defer server.Close()
+
+			exec := NewHttpExecutor(server.Client(), tc.mock(ctrl), "", logger.NewNopLogger())
+			err := exec.Exec(context.Background(), domain.Job{
+				Id:   1,
+				Name: "test",
+				Cfg:  fmt.Sprintf(tc.cfg, server.URL),
+				Mode: tc.mode,
+				// 非分片模式下忽略
+				ShardTotal: 4,
+				Shard:      domain.JobShard{Index: 2},
+			})
+			assert.Equal(t, tc.wantErr, err != nil)
+		})
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"kitbook/internal/domain"
 	"kitbook/internal/service"
 	"kitbook/pkg/logger"
+	"os"
+	"sync/atomic"
 	"time"
 )
 
@@ -72,21 +74,58 @@ type Scheduler struct {
 	svc service.JobService
 
 	dbTimeout time.Duration
-	executors map[string]Executor
+	// 当前结点标识, 记录在执行日志中
+	node string
+	// 没有可调度任务时的等待间隔
+	idleInterval time.Duration
+	// 广播任务的检查间隔
+	broadcastInterval time.Duration
+	executors         map[string]Executor
 
+	// 当前结点标签, 只执行亲和标签满足的任务
+	labels map[string]string
+	// 同一个web实例最多同时运行的任务数
+	capacity int64
 	// 令牌算法进行限流
 	limiter *semaphore.Weighted
+	// 正在运行的任务数
+	running atomic.Int64
+
+	// 负载上报间隔
+	reportInterval time.Duration
+	// 负载比最空闲的结点高出该值时暂缓抢占
+	loadMargin float64
+	// 连续暂缓抢占的次数上限, 避免只有本结点满足亲和标签的任务一直得不到调度
+	maxDefers int
+	// 当前结点是否为负载最高的结点
+	busiest atomic.Bool
+	cpu     *cpuSampler
 
 	l logger.Logger
 }
 
-func NewScheduler(svc service.JobService, l logger.Logger) *Scheduler {
+func NewScheduler(svc service.JobService, labels map[string]string, l logger.Logger) *Scheduler {
+	node, err := os.Hostname()
+	if err != nil {
+		node = "unknown"
+	}
+
+	const capacity = 100
 	return &Scheduler{
-		svc:       svc,
-		dbTimeout: time.Second,
-		executors: map[string]Executor{},
-		limiter:   semaphore.NewWeighted(100), //同一个web实例最多同时运行100个任务
-		l:         l}
+		svc:               svc,
+		dbTimeout:         time.Second,
+		node:              fmt.Sprintf("%s-%d", node, os.Getpid()),
+		idleInterval:      time.Second,
+		broadcastInterval: time.Second,
+		executors:         map[string]Executor{},
+		labels:            labels,
+		capacity:          capacity,
+		limiter:           semaphore.NewWeighted(capacity),
+		reportInterval:    5 * time.Second,
+		loadMargin:        0.2,
+		maxDefers:         10,
+		cpu:               newCpuSampler(),
+		l:                 l}
 }
 
 // @func: RegisterExecutor
@@ -105,6 +144,10 @@ func (s *Scheduler) RegisterExecutor(exec Executor) {
 // @author: Kewin Li
 // @receiver s
 func (s *Scheduler) Schedule(ctx context.Context) {
+	go s.scheduleBroadcast(ctx)
+	go s.reportLoad(ctx)
+
+	deferred := 0
 	for {
 
 		/*任务抢占保护 start*/
@@ -112,6 +155,15 @@ func (s *Scheduler) Schedule(ctx context.Context) {
 			s.l.INFO("context 出错/超时", logger.Error(ctx.Err()))
 			return
 		}
+
+		// 负载最高的结点暂缓抢占, 让给其他结点
+		if s.busiest.Load() && deferred < s.maxDefers {
+			deferred++
+			time.Sleep(s.idleInterval)
+			continue
+		}
+		deferred = 0
+
 		err := s.limiter.Acquire(ctx, 1)
 		if err != nil {
 			s.l.WARN("令牌获取失败", logger.Error(err))
@@ -122,44 +174,259 @@ func (s *Scheduler) Schedule(ctx context.Context) {
 		dbCtx, cancel := context.WithTimeout(context.Background(), s.dbTimeout)
 
 		// 1. 抢占任务
-		job, err := s.svc.Preempt(dbCtx)
+		job, err := s.preempt(dbCtx)
 		cancel()
 		if err != nil {
 			s.l.WARN("任务调度失败",
 				logger.Error(err),
 				logger.Int[int64]("job_id", job.Id))
+			s.limiter.Release(1)
+			// 没有可调度的任务, 避免空转
+			time.Sleep(s.idleInterval)
 			continue
 		}
 
 		// 2. 执行任务
-		exec, ok := s.executors[job.ExecutorName]
-		// 2.1 没有发现执行器
-		if !ok {
-			s.l.ERROR("执行器未发现",
-				logger.Field{"ok", ok},
+		s.execute(ctx, job)
+	}
+}
+
+// @func: preempt
+// @date: 2024-01-18 16:20:35
+// @brief: 调度器-优先抢占单结点任务, 没有时再抢占分片任务的一个分片
+// @author: Kewin Li
+// @receiver s
+// @param ctx
+// @return domain.Job
+// @return error
+func (s *Scheduler) preempt(ctx context.Context) (domain.Job, error) {
+	job, err := s.svc.Preempt(ctx, s.labels)
+	if err == nil {
+		return job, nil
+	}
+
+	return s.svc.PreemptShard(ctx, s.labels)
+}
+
+// @func: reportLoad
+// @date: 2024-01-20 11:02:15
+// @brief: 调度器-定时上报当前结点负载, 并判断是否为负载最高的结点
+// @author: Kewin Li
+// @receiver s
+// @param ctx
+func (s *Scheduler) reportLoad(ctx context.Context) {
+	ticker := time.NewTicker(s.reportInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		self := domain.JobNode{
+			Name:     s.node,
+			Labels:   s.labels,
+			Running:  s.running.Load(),
+			Capacity: s.capacity,
+			CPU:      s.cpu.Sample(),
+			Utime:    time.Now(),
+		}
+
+		dbCtx, cancel := context.WithTimeout(context.Background(), s.dbTimeout)
+		err := s.svc.ReportNode(dbCtx, self)
+		if err != nil {
+			cancel()
+			s.l.WARN("上报结点负载失败", logger.Error(err))
+			continue
+		}
+
+		nodes, err := s.svc.ListNodes(dbCtx)
+		cancel()
+		if err != nil {
+			// 无法判断时不暂缓抢占
+			s.busiest.Store(false)
+			s.l.WARN("查询结点负载失败", logger.Error(err))
+			continue
+		}
+
+		s.busiest.Store(s.isBusiest(self, nodes))
+	}
+}
+
+// @func: isBusiest
+// @date: 2024-01-20 11:06:40
+// @brief: 调度器-当前结点负载最高, 且明显高于最空闲的结点
+// @author: Kewin Li
+// @receiver s
+// @param self
+// @param nodes 所有在线结点, 可包含当前结点
+// @return bool
+func (s *Scheduler) isBusiest(self domain.JobNode, nodes []domain.JobNode) bool {
+	load := self.Load()
+	minLoad := load
+	others := 0
+	for _, node := range nodes {
+		if node.Name == self.Name {
+			continue
+		}
+
+		others++
+		if node.Load() > load {
+			return false
+		}
+		minLoad = min(minLoad, node.Load())
+	}
+
+	return others > 0 && load-minLoad > s.loadMargin
+}
+
+// broadcastPlan
+// @Description: 广播任务在当前结点的调度计划
+type broadcastPlan struct {
+	expression string
+	// 最近一次看到的数据库中的调度时间点, 变化说明被修改、恢复或立即触发
+	dbNextTime time.Time
+	nextTime   time.Time
+}
+
+// @func: scheduleBroadcast
+// @date: 2024-01-18 16:26:12
+// @brief: 调度器-广播任务调度, 各结点按本地时钟各自执行, 新发现的任务从下一个调度时间点开始
+// @author: Kewin Li
+// @receiver s
+// @param ctx
+func (s *Scheduler) scheduleBroadcast(ctx context.Context) {
+	ticker := time.NewTicker(s.broadcastInterval)
+	defer ticker.Stop()
+
+	plans := map[int64]*broadcastPlan{}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		dbCtx, cancel := context.WithTimeout(context.Background(), s.dbTimeout)
+		jobs, err := s.svc.ListBroadcast(dbCtx)
+		cancel()
+		if err != nil {
+			s.l.WARN("查询广播任务失败", logger.Error(err))
+			continue
+		}
+
+		now := time.Now()
+		// 已删除、暂停的任务不再保留计划
+		alive := make(map[int64]*broadcastPlan, len(jobs))
+		for _, job := range jobs {
+			if !job.MatchLabels(s.labels) {
+				continue
+			}
+
+			plan, ok := plans[job.Id]
+			switch {
+			case !ok || plan.expression != job.Expression:
+				plan = &broadcastPlan{
+					expression: job.Expression,
+					dbNextTime: job.NextExecTime,
+					nextTime:   job.NextTime(),
+				}
+			case !plan.dbNextTime.Equal(job.NextExecTime):
+				plan.dbNextTime = job.NextExecTime
+				plan.nextTime = job.NextExecTime
+			}
+			alive[job.Id] = plan
+
+			if now.Before(plan.nextTime) {
+				continue
+			}
+			plan.nextTime = job.NextTime()
+
+			if !s.limiter.TryAcquire(1) {
+				s.l.WARN("令牌不足, 跳过本次广播任务",
+					logger.Int[int64]("job_id", job.Id))
+				continue
+			}
+
+			job.CancelFunc = func() {}
+			s.execute(ctx, job)
+		}
+		plans = alive
+	}
+}
+
+// @func: execute
+// @date: 2024-01-18 16:32:50
+// @brief: 调度器-交给执行器异步执行, 调用前需已获取令牌
+// @author: Kewin Li
+// @receiver s
+// @param ctx
+// @param job
+func (s *Scheduler) execute(ctx context.Context, job domain.Job) {
+	s.running.Add(1)
+
+	exec, ok := s.executors[job.ExecutorName]
+	// 没有发现执行器
+	if !ok {
+		s.l.ERROR("执行器未发现",
+			logger.Field{"ok", ok},
+			logger.Int[int64]("job_id", job.Id),
+			logger.Field{"executor_name", job.ExecutorName})
+		s.finish(job, time.Now(), fmt.Errorf("执行器未发现: %s", job.ExecutorName))
+		return
+	}
+
+	// 任务开始执行
+	go func() {
+		start := time.Now()
+		err := exec.Exec(ctx, job)
+		if err != nil {
+			s.l.ERROR("任务执行发生错误",
+				logger.Error(err),
 				logger.Int[int64]("job_id", job.Id),
 				logger.Field{"executor_name", job.ExecutorName})
 		}
 
-		// 2.2 任务开始执行
-		go func() {
-			defer func() {
-				s.limiter.Release(1) //释放令牌
+		s.finish(job, start, err)
+	}()
+}
+
+// @func: finish
+// @date: 2024-01-16 15:35:08
+// @brief: 调度器-任务执行完毕, 记录执行日志、更新下一次调度时间点并释放任务
+// @author: Kewin Li
+// @receiver s
+// @param job
+// @param start
+// @param execErr
+func (s *Scheduler) finish(job domain.Job, start time.Time, execErr error) {
+	defer func() {
+		s.running.Add(-1)
+		s.limiter.Release(1) //释放令牌
 
-				job.CancelFunc() // 资源释放
-			}()
+		job.CancelFunc() // 资源释放
+	}()
 
-			err2 := exec.Exec(ctx, job)
-			if err2 != nil {
-				s.l.ERROR("任务执行发生错误",
-					logger.Error(err2),
-					logger.Int[int64]("job_id", job.Id),
-					logger.Field{"executor_name", job.ExecutorName})
-			}
+	exec := domain.JobExecution{
+		Node:      s.node,
+		Result:    domain.JobResultSuccess,
+		StartTime: start,
+		EndTime:   time.Now(),
+	}
+	if execErr != nil {
+		exec.Result = domain.JobResultFailed
+		exec.Error = execErr.Error()
+	}
 
-			// 2.3 执行完毕，更新下一次任务调度的时间点
-			err2 = s.svc.ResetNextTime(ctx, job)
+	dbCtx, cancel := context.WithTimeout(context.Background(), s.dbTimeout)
+	defer cancel()
 
-		}()
+	err := s.svc.Finish(dbCtx, job, exec)
+	if err != nil {
+		s.l.ERROR("记录任务执行结果失败",
+			logger.Error(err),
+			logger.Int[int64]("job_id", job.Id))
 	}
 }
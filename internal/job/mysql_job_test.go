@@ -0,0 +1,75 @@
+package job
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"kitbook/internal/domain"
+	svcmocks "kitbook/internal/service/mocks"
+	"kitbook/pkg/logger"
+	"testing"
+)
+
+// @func: TestScheduler_isBusiest
+// @date: 2024-01-20 11:40:26
+// @brief: 单元测试-判断当前结点是否为负载最高的结点
+// @author: Kewin Li
+// @param t
+func TestScheduler_isBusiest(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		self  domain.JobNode
+		nodes []domain.JobNode
+
+		want bool
+	}{
+		{
+			name:  "只有当前结点",
+			self:  domain.JobNode{Name: "a", Running: 90, Capacity: 100},
+			nodes: []domain.JobNode{{Name: "a", Running: 90, Capacity: 100}},
+		},
+		{
+			name: "负载最高且明显高于最空闲的结点",
+			self: domain.JobNode{Name: "a", Running: 60, Capacity: 100},
+			nodes: []domain.JobNode{
+				{Name: "a", Running: 60, Capacity: 100},
+				{Name: "b", Running: 10, Capacity: 100},
+				{Name: "c", Running: 50, Capacity: 100},
+			},
+			want: true,
+		},
+		{
+			name: "CPU使用率高",
+			self: domain.JobNode{Name: "a", Running: 1, Capacity: 100, CPU: 0.9},
+			nodes: []domain.JobNode{
+				{Name: "b", Running: 30, Capacity: 100, CPU: 0.2},
+			},
+			want: true,
+		},
+		{
+			name: "负载相近",
+			self: domain.JobNode{Name: "a", Running: 60, Capacity: 100},
+			nodes: []domain.JobNode{
+				{Name: "b", Running: 50, Capacity: 100},
+			},
+		},
+		{
+			name: "存在负载更高的结点",
+			self: domain.JobNode{Name: "a", Running: 60, Capacity: 100},
+			nodes: []domain.JobNode{
+				{Name: "b", Running: 10, Capacity: 100},
+				{Name: "c", Running: 70, Capacity: 100},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			s := NewScheduler(svcmocks.NewMockJobService(ctrl), nil, logger.NewNopLogger())
+			assert.Equal(t, tc.want, s.isBusiest(tc.self, tc.nodes))
+		})
+	}
+}
@@ -138,3 +138,34 @@ func (r *RankingJob) RunV1() error {
 
 	return r.svc.TopN(ctx)
 }
+
+// RankingLocalCacheJob
+// @Description: 定时从redis刷新本地热榜缓存, 每个web结点都要执行, 不需要分布式锁
+type RankingLocalCacheJob struct {
+	svc     service.RankingService
+	timeout time.Duration
+}
+
+func NewRankingLocalCacheJob(svc service.RankingService, timeout time.Duration) *RankingLocalCacheJob {
+	return &RankingLocalCacheJob{
+		svc:     svc,
+		timeout: timeout,
+	}
+}
+
+func (r *RankingLocalCacheJob) Name() string {
+	return "ranking_local_cache"
+}
+
+// @func: Run
+// @date: 2024-01-16 10:35:12
+// @brief: 本地热榜缓存刷新
+// @author: Kewin Li
+// @receiver r
+// @return error
+func (r *RankingLocalCacheJob) Run() error {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	return r.svc.RefreshLocalCache(ctx)
+}
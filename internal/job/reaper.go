@@ -0,0 +1,64 @@
+package job
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus"
+	"kitbook/internal/service"
+	"kitbook/pkg/logger"
+	"time"
+)
+
+// StuckJobReaper
+// @Description: 回收续约超时的任务, 抢占任务的结点宕机后任务重新回到等待调度状态
+type StuckJobReaper struct {
+	svc service.JobService
+	// 租约时长, 超过该时长没有续约视为结点已宕机
+	lease   time.Duration
+	timeout time.Duration
+	// 累计回收的任务数
+	counter prometheus.Counter
+
+	l logger.Logger
+}
+
+func NewStuckJobReaper(svc service.JobService, lease time.Duration, opts prometheus.CounterOpts, l logger.Logger) *StuckJobReaper {
+	counter := prometheus.NewCounter(opts)
+	prometheus.MustRegister(counter)
+
+	return &StuckJobReaper{
+		svc:     svc,
+		lease:   lease,
+		timeout: time.Second * 3,
+		counter: counter,
+		l:       l,
+	}
+}
+
+func (s *StuckJobReaper) Name() string {
+	return "stuck_job_reaper"
+}
+
+// @func: Run
+// @date: 2024-01-18 10:40:06
+// @brief: 回收续约超时的任务, 各结点同时执行也不会重复回收
+// @author: Kewin Li
+// @receiver s
+// @return error
+func (s *StuckJobReaper) Run() error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	cnt, err := s.svc.ReclaimStuck(ctx, s.lease)
+	if err != nil {
+		return err
+	}
+
+	if cnt > 0 {
+		s.counter.Add(float64(cnt))
+		s.l.WARN("回收续约超时的任务",
+			logger.Int[int64]("count", cnt),
+			logger.Field{"lease", s.lease.String()})
+	}
+
+	return nil
+}
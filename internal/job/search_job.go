@@ -0,0 +1,39 @@
+package job
+
+import (
+	"context"
+	"kitbook/internal/service"
+	"time"
+)
+
+// SearchIndexRebuildJob
+// @Description: 定时从线上库重建本结点的搜索索引, 修正丢失的发表、撤回消息, 每个web结点都要执行, 不需要分布式锁
+type SearchIndexRebuildJob struct {
+	svc     service.SearchService
+	timeout time.Duration
+}
+
+func NewSearchIndexRebuildJob(svc service.SearchService, timeout time.Duration) *SearchIndexRebuildJob {
+	return &SearchIndexRebuildJob{
+		svc:     svc,
+		timeout: timeout,
+	}
+}
+
+func (s *SearchIndexRebuildJob) Name() string {
+	return "search_index_rebuild"
+}
+
+// @func: Run
+// @date: 2024-01-23 16:50:26
+// @brief: 搜索索引重建
+// @author: Kewin Li
+// @receiver s
+// @return error
+func (s *SearchIndexRebuildJob) Run() error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	_, err := s.svc.RebuildArticleIndex(ctx)
+	return err
+}
@@ -10,7 +10,11 @@ import (
 	"time"
 )
 
-var ErrUserMismatch = dao.ErrUserMismatch
+var (
+	ErrUserMismatch    = dao.ErrUserMismatch
+	ErrVersionConflict = dao.ErrVersionConflict
+	ErrArticleNotFound = dao.ErrRecordNotFound
+)
 
 // 预加载缓存大小限制
 const contentLimitSize = 1 * 1024 * 1024
@@ -19,18 +23,27 @@ type ArticleRepository interface {
 	Create(ctx context.Context, art domain.Article) (int64, error)
 	Update(ctx context.Context, art domain.Article) error
 	Sync(ctx context.Context, art domain.Article) (int64, error)
+	// 保存、发表的同时在同一事务中生成历史版本
+	SaveWithRevision(ctx context.Context, art domain.Article, kind domain.RevisionKind) (int64, error)
+	SyncWithRevision(ctx context.Context, art domain.Article, kind domain.RevisionKind) (int64, error)
 	SyncStatus(ctx context.Context, artId int64, authorId int64, status domain.ArticleStatus) error
 	GetByAuthor(ctx context.Context, userId int64, offset int, limit int) ([]domain.Article, error)
 	GetById(ctx context.Context, artId int64) (domain.Article, error)
 	GetPubById(ctx context.Context, artId int64) (domain.Article, error)
 	ListPub(ctx context.Context, start time.Time, offset int, limit int) ([]domain.Article, error)
+	ListPubByTag(ctx context.Context, tag string, offset int, limit int) ([]domain.Article, error)
+	CountPubByTag(ctx context.Context, tag string) (int64, error)
+	SearchTags(ctx context.Context, prefix string, limit int) ([]domain.Tag, error)
 }
 
 type CacheArticleRepository struct {
 	// V0写法 不分库
-	dao      dao.ArticleDao
-	cache    cache.ArticleCache
-	userRepo UserRepository
+	dao         dao.ArticleDao
+	tagDao      dao.ArticleTagDao
+	revisionDao dao.ArticleRevisionDao
+	refDao      dao.UploadRefDao
+	cache       cache.ArticleCache
+	userRepo    UserRepository
 
 	// V2写法 在repository层做数据同步
 	authorDao dao.ArticleAuthorDao
@@ -40,12 +53,18 @@ type CacheArticleRepository struct {
 }
 
 func NewCacheArticleRepository(dao dao.ArticleDao,
+	tagDao dao.ArticleTagDao,
+	revisionDao dao.ArticleRevisionDao,
+	refDao dao.UploadRefDao,
 	cache cache.ArticleCache,
 	userRepo UserRepository) ArticleRepository {
 	return &CacheArticleRepository{
-		dao:      dao,
-		cache:    cache,
-		userRepo: userRepo,
+		dao:         dao,
+		tagDao:      tagDao,
+		revisionDao: revisionDao,
+		refDao:      refDao,
+		cache:       cache,
+		userRepo:    userRepo,
 	}
 }
 
@@ -81,7 +100,136 @@ func NewCacheArticleRepositoryV2(
 // @return int64
 // @return error
 func (c *CacheArticleRepository) Create(ctx context.Context, art domain.Article) (int64, error) {
-	return c.dao.Insert(ctx, ConvertsDaoArticle(&art))
+	return c.create(ctx, art, nil)
+}
+
+// @func: create
+// @date: 2024-01-22 11:20:16
+// @brief: 新建帖子, 帖子、标签、历史版本在同一事务中写入
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param art
+// @param kind 历史版本来源, 为nil时不生成历史版本
+// @return int64
+// @return error
+func (c *CacheArticleRepository) create(ctx context.Context, art domain.Article, kind *domain.RevisionKind) (int64, error) {
+	var id int64
+	err := c.withTx(ctx, func(d dao.ArticleDao, tagDao dao.ArticleTagDao, refDao dao.UploadRefDao, revRepo ArticleRevisionRepository) error {
+		var err error
+		id, err = d.Insert(ctx, ConvertsDaoArticle(&art))
+		if err != nil {
+			return err
+		}
+
+		// 标签统一存放在MySQL, 与帖子存储方案无关
+		if len(art.Tags) > 0 {
+			err = tagDao.SetTags(ctx, id, art.Tags)
+			if err != nil {
+				return err
+			}
+		}
+
+		// 上传文件引用同样存放在MySQL
+		hashes := domain.ReferencedUploads(art.Content)
+		if len(hashes) > 0 {
+			err = refDao.SetArticleRefs(ctx, id, art.Author.Id, hashes)
+			if err != nil {
+				return err
+			}
+		}
+
+		art.Id = id
+		return c.createRevision(ctx, revRepo, refDao, art, kind)
+	})
+
+	return id, err
+}
+
+// @func: SaveWithRevision
+// @date: 2024-01-22 11:22:40
+// @brief: 保存帖子到制作库, 同一事务中生成历史版本, 任一失败整体失败
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param art Id>0时修改, 否则新建
+// @param kind 历史版本来源
+// @return int64
+// @return error
+func (c *CacheArticleRepository) SaveWithRevision(ctx context.Context, art domain.Article, kind domain.RevisionKind) (int64, error) {
+	if art.Id > 0 {
+		return art.Id, c.update(ctx, art, &kind)
+	}
+
+	return c.create(ctx, art, &kind)
+}
+
+// @func: SyncWithRevision
+// @date: 2024-01-22 11:24:05
+// @brief: 发表帖子, 同一事务中生成历史版本, 任一失败整体失败
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param art
+// @param kind 历史版本来源
+// @return int64
+// @return error
+func (c *CacheArticleRepository) SyncWithRevision(ctx context.Context, art domain.Article, kind domain.RevisionKind) (int64, error) {
+	return c.sync(ctx, art, &kind)
+}
+
+// @func: createRevision
+// @date: 2024-01-22 11:25:30
+// @brief: 生成帖子的历史版本
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param revRepo 与帖子写入处于同一事务
+// @param refDao 与帖子写入处于同一事务, 记录历史版本引用的上传文件
+// @param art
+// @param kind 为nil时不生成
+// @return error
+func (c *CacheArticleRepository) createRevision(ctx context.Context, revRepo ArticleRevisionRepository, refDao dao.UploadRefDao,
+	art domain.Article, kind *domain.RevisionKind) error {
+	if kind == nil {
+		return nil
+	}
+
+	_, err := revRepo.Create(ctx, domain.ArticleRevision{
+		ArtId:    art.Id,
+		AuthorId: art.Author.Id,
+		Kind:     *kind,
+		Title:    art.Title,
+		Content:  art.Content,
+		Category: art.Category,
+		Tags:     art.Tags,
+	})
+	if err != nil {
+		return err
+	}
+
+	return refDao.AddRevisionRefs(ctx, art.Id, art.Author.Id, domain.ReferencedUploads(art.Content))
+}
+
+// @func: withTx
+// @date: 2024-01-21 15:45:30
+// @brief: 帖子存放在MySQL时, 帖子与标签、上传文件引用、历史版本在同一事务中写入; 其他存储方案无法共用事务, 依次写入
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param fn
+// @return error
+func (c *CacheArticleRepository) withTx(ctx context.Context,
+	fn func(d dao.ArticleDao, tagDao dao.ArticleTagDao, refDao dao.UploadRefDao, revRepo ArticleRevisionRepository) error) error {
+	txDao, ok := c.dao.(dao.ArticleTxDao)
+	if !ok {
+		return fn(c.dao, c.tagDao, c.refDao, NewNormalArticleRevisionRepository(c.revisionDao))
+	}
+
+	return txDao.Transaction(ctx, func(d dao.ArticleDao, tx *gorm.DB) error {
+		return fn(d, dao.NewGormArticleTagDao(tx), dao.NewGormUploadRefDao(tx),
+			NewNormalArticleRevisionRepository(dao.NewGormArticleRevisionDao(tx)))
+	})
 }
 
 // @func: Update
@@ -93,7 +241,41 @@ func (c *CacheArticleRepository) Create(ctx context.Context, art domain.Article)
 // @param art
 // @return error
 func (c *CacheArticleRepository) Update(ctx context.Context, art domain.Article) error {
-	err := c.dao.UpdateById(ctx, ConvertsDaoArticle(&art))
+	return c.update(ctx, art, nil)
+}
+
+// @func: update
+// @date: 2024-01-22 11:21:08
+// @brief: 修改帖子, 帖子、标签、历史版本在同一事务中写入
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param art
+// @param kind 历史版本来源, 为nil时不生成历史版本
+// @return error
+func (c *CacheArticleRepository) update(ctx context.Context, art domain.Article, kind *domain.RevisionKind) error {
+	err := c.withTx(ctx, func(d dao.ArticleDao, tagDao dao.ArticleTagDao, refDao dao.UploadRefDao, revRepo ArticleRevisionRepository) error {
+		err := d.UpdateById(ctx, ConvertsDaoArticle(&art))
+		if err != nil {
+			return err
+		}
+
+		err = tagDao.SetTags(ctx, art.Id, art.Tags)
+		if err != nil {
+			return err
+		}
+
+		err = refDao.SetArticleRefs(ctx, art.Id, art.Author.Id, domain.ReferencedUploads(art.Content))
+		if err != nil {
+			return err
+		}
+
+		return c.createRevision(ctx, revRepo, refDao, art, kind)
+	})
+	if err == nil {
+		// 详情缓存中的版本号已过期
+		err = c.cache.DelById(ctx, art.Id)
+	}
 	if err == nil {
 		err = c.cache.DelFirstPage(ctx, art.Author.Id)
 		if err != nil {
@@ -197,7 +379,47 @@ func (c *CacheArticleRepository) SyncV2(ctx context.Context, art domain.Article)
 // @return int64
 // @return error
 func (c *CacheArticleRepository) Sync(ctx context.Context, art domain.Article) (int64, error) {
-	id, err := c.dao.Sync(ctx, ConvertsDaoArticle(&art))
+	return c.sync(ctx, art, nil)
+}
+
+// @func: sync
+// @date: 2024-01-22 11:23:16
+// @brief: 帖子发表-数据同步, 帖子、标签、历史版本在同一事务中写入
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param art
+// @param kind 历史版本来源, 为nil时不生成历史版本
+// @return int64
+// @return error
+func (c *CacheArticleRepository) sync(ctx context.Context, art domain.Article, kind *domain.RevisionKind) (int64, error) {
+	var id int64
+	err := c.withTx(ctx, func(d dao.ArticleDao, tagDao dao.ArticleTagDao, refDao dao.UploadRefDao, revRepo ArticleRevisionRepository) error {
+		var err error
+		id, err = d.Sync(ctx, ConvertsDaoArticle(&art))
+		if err != nil {
+			return err
+		}
+
+		err = tagDao.SyncTags(ctx, id, art.Tags)
+		if err != nil {
+			return err
+		}
+
+		err = refDao.SyncArticleRefs(ctx, id, art.Author.Id, domain.ReferencedUploads(art.Content))
+		if err != nil {
+			return err
+		}
+
+		art.Id = id
+		return c.createRevision(ctx, revRepo, refDao, art, kind)
+	})
+	if err == nil {
+		art.Id = id
+	}
+	if err == nil {
+		err = c.cache.DelById(ctx, id)
+	}
 	if err == nil {
 		err = c.cache.DelFirstPage(ctx, art.Author.Id)
 		if err != nil {
@@ -225,6 +447,7 @@ func (c *CacheArticleRepository) Sync(ctx context.Context, art domain.Article) (
 			Id:   author.Id,
 			Name: author.Nickname,
 		}
+		art.RenderHTML()
 
 		err2 = c.cache.SetPub(ctx2, art)
 		if err2 != nil {
@@ -291,6 +514,9 @@ func (c *CacheArticleRepository) GetByAuthor(ctx context.Context, userId int64,
 	for i, art := range artsDao {
 		arts[i] = ConvertsDomainArticleFromProduce(&art)
 	}
+	if err == nil {
+		err = c.withTags(ctx, arts, c.tagDao.FindTags)
+	}
 
 	// 查完数据库需要把缓存放回去
 	// TODO:优化  1.异步缓存 2.达到查询阈值才缓存
@@ -338,18 +564,22 @@ func (c *CacheArticleRepository) GetById(ctx context.Context, artId int64) (doma
 	// TODO: 检查预加载出错 日志埋点
 
 	artDAO, err := c.dao.GetById(ctx, artId)
+	arts := []domain.Article{ConvertsDomainArticleFromProduce(&artDAO)}
+	if err == nil {
+		err = c.withTags(ctx, arts, c.tagDao.FindTags)
+	}
 
 	// 库查询结束后 预加载回写
 	// 可以同步 也可以异步
 	go func() {
-		err = c.cache.SetById(ctx, ConvertsDomainArticleFromProduce(&artDAO))
+		err := c.cache.SetById(ctx, arts[0])
 		if err != nil {
 			// TODO: 回写预加载出错 日志埋点
 		}
 
 	}()
 
-	return ConvertsDomainArticleFromProduce(&artDAO), err
+	return arts[0], err
 }
 
 // @func: GetPubById
@@ -365,6 +595,8 @@ func (c *CacheArticleRepository) GetPubById(ctx context.Context, artId int64) (d
 	// 取帖子缓存
 	art, err := c.cache.GetPubById(ctx, artId)
 	if err == nil && err != cache.ErrKeyNotExist {
+		// 与制作库共用缓存key, 制作库写入的没有渲染结果
+		art.RenderHTML()
 		return art, err
 	}
 
@@ -375,7 +607,14 @@ func (c *CacheArticleRepository) GetPubById(ctx context.Context, artId int64) (d
 		return domain.Article{}, err
 	}
 
-	art = ConvertsDomainArticleFromLive(&res)
+	arts := []domain.Article{ConvertsDomainArticleFromLive(&res)}
+	err = c.withTags(ctx, arts, c.tagDao.FindPubTags)
+	if err != nil {
+		return domain.Article{}, err
+	}
+	art = arts[0]
+	// 渲染结果随帖子一起缓存, 避免每次阅读都重新渲染
+	art.RenderHTML()
 
 	// 帖子缓存回写
 	go func() {
@@ -447,6 +686,103 @@ func (c *CacheArticleRepository) ListPub(ctx context.Context, start time.Time, o
 	return artsDomain, nil
 }
 
+// @func: ListPubByTag
+// @date: 2024-01-21 15:45:12
+// @brief: 帖子标签-分页查询带有该标签的已发表帖子
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param tag
+// @param offset
+// @param limit
+// @return []domain.Article
+// @return error
+func (c *CacheArticleRepository) ListPubByTag(ctx context.Context, tag string, offset int, limit int) ([]domain.Article, error) {
+	artsDao, err := c.tagDao.ListPubByTag(ctx, tag, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	arts := make([]domain.Article, len(artsDao))
+	for i, art := range artsDao {
+		arts[i] = ConvertsDomainArticleFromLive(&art)
+	}
+
+	return arts, c.withTags(ctx, arts, c.tagDao.FindPubTags)
+}
+
+// @func: CountPubByTag
+// @date: 2024-01-21 15:46:30
+// @brief: 帖子标签-统计带有该标签的已发表帖子数
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param tag
+// @return int64
+// @return error
+func (c *CacheArticleRepository) CountPubByTag(ctx context.Context, tag string) (int64, error) {
+	return c.tagDao.CountPubByTag(ctx, tag)
+}
+
+// @func: SearchTags
+// @date: 2024-01-21 15:47:18
+// @brief: 帖子标签-按前缀补全标签
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param prefix
+// @param limit
+// @return []domain.Tag
+// @return error
+func (c *CacheArticleRepository) SearchTags(ctx context.Context, prefix string, limit int) ([]domain.Tag, error) {
+	tags, err := c.tagDao.SearchTags(ctx, prefix, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]domain.Tag, 0, len(tags))
+	for _, tag := range tags {
+		res = append(res, domain.Tag{
+			Name:       tag.Name,
+			ArticleCnt: tag.Cnt,
+		})
+	}
+
+	return res, nil
+}
+
+// @func: withTags
+// @date: 2024-01-21 15:49:02
+// @brief: 帖子标签-批量填充帖子的标签
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param arts
+// @param find 查询制作库或线上库的标签
+// @return error
+func (c *CacheArticleRepository) withTags(ctx context.Context, arts []domain.Article,
+	find func(ctx context.Context, artIds []int64) (map[int64][]string, error)) error {
+	if len(arts) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(arts))
+	for _, art := range arts {
+		ids = append(ids, art.Id)
+	}
+
+	tags, err := find(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	for i := range arts {
+		arts[i].Tags = tags[arts[i].Id]
+	}
+
+	return nil
+}
+
 // @func: convertsDominUser
 // @date: 2023-10-09 02:08:11
 // @brief: 制作库转化为domin的Article结构体
@@ -455,15 +791,17 @@ func (c *CacheArticleRepository) ListPub(ctx context.Context, start time.Time, o
 // @return domain.User
 func ConvertsDomainArticleFromProduce(art *dao.Article) domain.Article {
 	return domain.Article{
-		Id:      art.Id,
-		Title:   art.Title,
-		Content: art.Content,
+		Id:       art.Id,
+		Title:    art.Title,
+		Content:  art.Content,
+		Category: art.Category,
 		Author: domain.Author{
 			Id: art.AuthorId,
 		},
-		Status: domain.ToArticleStatus(art.Status),
-		Ctime:  time.UnixMilli(art.Ctime),
-		Utime:  time.UnixMilli(art.Utime),
+		Status:  domain.ToArticleStatus(art.Status),
+		Version: art.Version,
+		Ctime:   time.UnixMilli(art.Ctime),
+		Utime:   time.UnixMilli(art.Utime),
 	}
 }
 
@@ -475,9 +813,10 @@ func ConvertsDomainArticleFromProduce(art *dao.Article) domain.Article {
 // @return domain.Article
 func ConvertsDomainArticleFromLive(art *dao.PublishedArticle) domain.Article {
 	return domain.Article{
-		Id:      art.Id,
-		Title:   art.Title,
-		Content: art.Content,
+		Id:       art.Id,
+		Title:    art.Title,
+		Content:  art.Content,
+		Category: art.Category,
 		Author: domain.Author{
 			Id: art.AuthorId,
 			// 深度理解这里为什么不默认进行创作者名称赋值
@@ -499,8 +838,10 @@ func ConvertsDaoArticle(art *domain.Article) dao.Article {
 		Id:       art.Id,
 		Title:    art.Title,
 		Content:  art.Content,
+		Category: art.Category,
 		AuthorId: art.Author.Id,
 		Status:   art.Status.ToUint8(),
+		Version:  art.Version,
 	}
 }
 
@@ -515,6 +856,7 @@ func ConvertsDaoPublishedArticle(art *domain.Article) dao.PublishedArticle {
 		Id:       art.Id,
 		Title:    art.Title,
 		Content:  art.Content,
+		Category: art.Category,
 		AuthorId: art.Author.Id,
 		Status:   art.Status.ToUint8(),
 	}
@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"kitbook/internal/domain"
+	"kitbook/internal/repository/dao"
+	"time"
+)
+
+var (
+	ErrReviewResolved = dao.ErrReviewResolved
+	ErrReviewNotFound = errors.New("审核记录不存在")
+)
+
+type ArticleReviewRepository interface {
+	Create(ctx context.Context, r domain.ArticleReview) (domain.ArticleReview, error)
+	FindById(ctx context.Context, id int64) (domain.ArticleReview, error)
+	FindLatest(ctx context.Context, artId int64, authorId int64) (domain.ArticleReview, error)
+	ListPending(ctx context.Context, offset int, limit int) ([]domain.ArticleReview, error)
+	Resolve(ctx context.Context, id int64, status domain.ReviewStatus, reviewerId int64, reasons []string) error
+	RevertApproval(ctx context.Context, id int64, status domain.ReviewStatus) error
+}
+
+type NormalArticleReviewRepository struct {
+	dao dao.ArticleReviewDao
+}
+
+func NewNormalArticleReviewRepository(dao dao.ArticleReviewDao) ArticleReviewRepository {
+	return &NormalArticleReviewRepository{
+		dao: dao,
+	}
+}
+
+// @func: Create
+// @date: 2024-01-25 12:25:10
+// @brief: 帖子审核-新增审核记录
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param r
+// @return domain.ArticleReview
+// @return error
+func (n *NormalArticleReviewRepository) Create(ctx context.Context, r domain.ArticleReview) (domain.ArticleReview, error) {
+	res, err := n.dao.Insert(ctx, n.ConvertsDaoReview(&r))
+	if err != nil {
+		return domain.ArticleReview{}, err
+	}
+
+	return n.ConvertsDomainReview(&res), nil
+}
+
+// @func: FindById
+// @date: 2024-01-25 12:26:24
+// @brief: 帖子审核-按ID查询
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param id
+// @return domain.ArticleReview
+// @return error 不存在时返回ErrReviewNotFound
+func (n *NormalArticleReviewRepository) FindById(ctx context.Context, id int64) (domain.ArticleReview, error) {
+	return n.find(n.dao.FindById(ctx, id))
+}
+
+// @func: FindLatest
+// @date: 2024-01-25 12:27:38
+// @brief: 帖子审核-查询作者某帖子最近一次审核
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param artId
+// @param authorId
+// @return domain.ArticleReview
+// @return error 不存在时返回ErrReviewNotFound
+func (n *NormalArticleReviewRepository) FindLatest(ctx context.Context, artId int64, authorId int64) (domain.ArticleReview, error) {
+	return n.find(n.dao.FindLatest(ctx, artId, authorId))
+}
+
+// @func: ListPending
+// @date: 2024-01-25 12:28:50
+// @brief: 帖子审核-分页查询人工审核队列
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param offset
+// @param limit
+// @return []domain.ArticleReview
+// @return error
+func (n *NormalArticleReviewRepository) ListPending(ctx context.Context, offset int, limit int) ([]domain.ArticleReview, error) {
+	rs, err := n.dao.ListPending(ctx, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]domain.ArticleReview, 0, len(rs))
+	for _, r := range rs {
+		res = append(res, n.ConvertsDomainReview(&r))
+	}
+
+	return res, nil
+}
+
+// @func: Resolve
+// @date: 2024-01-25 12:30:06
+// @brief: 帖子审核-处理人工审核
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param id
+// @param status
+// @param reviewerId
+// @param reasons
+// @return error 已被处理或已失效时返回ErrReviewResolved
+func (n *NormalArticleReviewRepository) Resolve(ctx context.Context, id int64, status domain.ReviewStatus, reviewerId int64, reasons []string) error {
+	return n.dao.Resolve(ctx, id, status.ToUint8(), reviewerId, marshalReasons(reasons))
+}
+
+// @func: RevertApproval
+// @date: 2024-01-28 19:00:40
+// @brief: 帖子审核-撤销已通过的审核
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param id
+// @param status 改回待处理或置为失效
+// @return error 审核不是已通过状态时返回ErrReviewResolved
+func (n *NormalArticleReviewRepository) RevertApproval(ctx context.Context, id int64, status domain.ReviewStatus) error {
+	return n.dao.RevertApproval(ctx, id, status.ToUint8())
+}
+
+func (n *NormalArticleReviewRepository) find(r dao.ArticleReview, err error) (domain.ArticleReview, error) {
+	if err == dao.ErrRecordNotFound {
+		return domain.ArticleReview{}, ErrReviewNotFound
+	}
+	if err != nil {
+		return domain.ArticleReview{}, err
+	}
+
+	return n.ConvertsDomainReview(&r), nil
+}
+
+func (n *NormalArticleReviewRepository) ConvertsDaoReview(r *domain.ArticleReview) dao.ArticleReview {
+	return dao.ArticleReview{
+		Id:         r.Id,
+		ArtId:      r.ArtId,
+		AuthorId:   r.AuthorId,
+		Version:    r.Version,
+		Title:      r.Title,
+		Content:    r.Content,
+		Status:     r.Status.ToUint8(),
+		Reasons:    marshalReasons(r.Reasons),
+		ReviewerId: r.ReviewerId,
+	}
+}
+
+func (n *NormalArticleReviewRepository) ConvertsDomainReview(r *dao.ArticleReview) domain.ArticleReview {
+	var reasons []string
+	if r.Reasons != "" {
+		_ = json.Unmarshal([]byte(r.Reasons), &reasons)
+	}
+
+	return domain.ArticleReview{
+		Id:         r.Id,
+		ArtId:      r.ArtId,
+		AuthorId:   r.AuthorId,
+		Version:    r.Version,
+		Title:      r.Title,
+		Content:    r.Content,
+		Status:     domain.ReviewStatus(r.Status),
+		Reasons:    reasons,
+		ReviewerId: r.ReviewerId,
+		Ctime:      time.UnixMilli(r.Ctime),
+		Utime:      time.UnixMilli(r.Utime),
+	}
+}
+
+func marshalReasons(reasons []string) string {
+	if len(reasons) == 0 {
+		return ""
+	}
+
+	// []string序列化不会失败
+	data, _ := json.Marshal(reasons)
+	return string(data)
+}
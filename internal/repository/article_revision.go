@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"kitbook/internal/domain"
+	"kitbook/internal/repository/dao"
+	"time"
+)
+
+var ErrRevisionNotFound = errors.New("帖子版本不存在")
+
+type ArticleRevisionRepository interface {
+	Create(ctx context.Context, rev domain.ArticleRevision) (domain.ArticleRevision, error)
+	List(ctx context.Context, artId int64, authorId int64, offset int, limit int) ([]domain.ArticleRevision, error)
+	Get(ctx context.Context, artId int64, authorId int64, version int) (domain.ArticleRevision, error)
+}
+
+type NormalArticleRevisionRepository struct {
+	dao dao.ArticleRevisionDao
+}
+
+func NewNormalArticleRevisionRepository(dao dao.ArticleRevisionDao) ArticleRevisionRepository {
+	return &NormalArticleRevisionRepository{
+		dao: dao,
+	}
+}
+
+// @func: Create
+// @date: 2024-01-22 10:50:18
+// @brief: 帖子历史版本-新增
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param rev
+// @return domain.ArticleRevision 带有版本号
+// @return error
+func (n *NormalArticleRevisionRepository) Create(ctx context.Context, rev domain.ArticleRevision) (domain.ArticleRevision, error) {
+	revDao, err := n.dao.Insert(ctx, n.ConvertsDaoRevision(&rev))
+	if err != nil {
+		return domain.ArticleRevision{}, err
+	}
+
+	return n.ConvertsDomainRevision(&revDao), nil
+}
+
+// @func: List
+// @date: 2024-01-22 10:52:40
+// @brief: 帖子历史版本-分页查询, 新版本在前
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param artId
+// @param authorId
+// @param offset
+// @param limit
+// @return []domain.ArticleRevision
+// @return error
+func (n *NormalArticleRevisionRepository) List(ctx context.Context, artId int64, authorId int64, offset int, limit int) ([]domain.ArticleRevision, error) {
+	revs, err := n.dao.FindByArtId(ctx, artId, authorId, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]domain.ArticleRevision, 0, len(revs))
+	for _, rev := range revs {
+		res = append(res, n.ConvertsDomainRevision(&rev))
+	}
+
+	return res, nil
+}
+
+// @func: Get
+// @date: 2024-01-22 10:54:12
+// @brief: 帖子历史版本-查询指定版本
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param artId
+// @param authorId
+// @param version
+// @return domain.ArticleRevision
+// @return error 不存在或不属于该作者时返回ErrRevisionNotFound
+func (n *NormalArticleRevisionRepository) Get(ctx context.Context, artId int64, authorId int64, version int) (domain.ArticleRevision, error) {
+	rev, err := n.dao.FindByVersion(ctx, artId, authorId, version)
+	if err == dao.ErrRecordNotFound {
+		return domain.ArticleRevision{}, ErrRevisionNotFound
+	}
+	if err != nil {
+		return domain.ArticleRevision{}, err
+	}
+
+	return n.ConvertsDomainRevision(&rev), nil
+}
+
+func (n *NormalArticleRevisionRepository) ConvertsDaoRevision(rev *domain.ArticleRevision) dao.ArticleRevision {
+	var tags []byte
+	if len(rev.Tags) > 0 {
+		// []string序列化不会失败
+		tags, _ = json.Marshal(rev.Tags)
+	}
+
+	return dao.ArticleRevision{
+		Id:       rev.Id,
+		ArtId:    rev.ArtId,
+		Version:  rev.Version,
+		AuthorId: rev.AuthorId,
+		Kind:     rev.Kind.ToUint8(),
+		Title:    rev.Title,
+		Content:  rev.Content,
+		Category: rev.Category,
+		Tags:     string(tags),
+	}
+}
+
+func (n *NormalArticleRevisionRepository) ConvertsDomainRevision(rev *dao.ArticleRevision) domain.ArticleRevision {
+	var tags []string
+	if rev.Tags != "" {
+		_ = json.Unmarshal([]byte(rev.Tags), &tags)
+	}
+
+	return domain.ArticleRevision{
+		Id:       rev.Id,
+		ArtId:    rev.ArtId,
+		AuthorId: rev.AuthorId,
+		Version:  rev.Version,
+		Kind:     domain.RevisionKind(rev.Kind),
+		Title:    rev.Title,
+		Content:  rev.Content,
+		Category: rev.Category,
+		Tags:     tags,
+		Ctime:    time.UnixMilli(rev.Ctime),
+	}
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"kitbook/internal/domain"
+	"kitbook/internal/repository/dao"
+	"time"
+)
+
+var (
+	ErrScheduleMismatch = dao.ErrScheduleMismatch
+	ErrScheduleNotFound = errors.New("定时发表计划不存在")
+)
+
+type ArticleScheduleRepository interface {
+	Save(ctx context.Context, s domain.ArticleSchedule) error
+	Cancel(ctx context.Context, artId int64, authorId int64) error
+	Get(ctx context.Context, artId int64, authorId int64) (domain.ArticleSchedule, error)
+	FindDue(ctx context.Context, now time.Time, stuckBefore time.Time, limit int) ([]domain.ArticleSchedule, error)
+	Claim(ctx context.Context, s domain.ArticleSchedule) (bool, error)
+	Finish(ctx context.Context, id int64, status domain.ScheduleStatus, errMsg string) error
+}
+
+type NormalArticleScheduleRepository struct {
+	dao dao.ArticleScheduleDao
+}
+
+func NewNormalArticleScheduleRepository(dao dao.ArticleScheduleDao) ArticleScheduleRepository {
+	return &NormalArticleScheduleRepository{
+		dao: dao,
+	}
+}
+
+// @func: Save
+// @date: 2024-01-22 15:45:12
+// @brief: 定时发表-新建或重新设置计划
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param s
+// @return error
+func (n *NormalArticleScheduleRepository) Save(ctx context.Context, s domain.ArticleSchedule) error {
+	return n.dao.Upsert(ctx, n.ConvertsDaoSchedule(&s))
+}
+
+// @func: Cancel
+// @date: 2024-01-22 15:46:20
+// @brief: 定时发表-取消等待中的计划
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param artId
+// @param authorId
+// @return error
+func (n *NormalArticleScheduleRepository) Cancel(ctx context.Context, artId int64, authorId int64) error {
+	return n.dao.Cancel(ctx, artId, authorId)
+}
+
+// @func: Get
+// @date: 2024-01-22 15:47:35
+// @brief: 定时发表-查询作者某帖子的计划
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param artId
+// @param authorId
+// @return domain.ArticleSchedule
+// @return error 不存在时返回ErrScheduleNotFound
+func (n *NormalArticleScheduleRepository) Get(ctx context.Context, artId int64, authorId int64) (domain.ArticleSchedule, error) {
+	s, err := n.dao.FindByArtId(ctx, artId, authorId)
+	if err == dao.ErrRecordNotFound {
+		return domain.ArticleSchedule{}, ErrScheduleNotFound
+	}
+	if err != nil {
+		return domain.ArticleSchedule{}, err
+	}
+
+	return n.ConvertsDomainSchedule(&s), nil
+}
+
+// @func: FindDue
+// @date: 2024-01-22 15:49:02
+// @brief: 定时发表-查询到期以及认领后卡住的计划
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param now
+// @param stuckBefore
+// @param limit
+// @return []domain.ArticleSchedule
+// @return error
+func (n *NormalArticleScheduleRepository) FindDue(ctx context.Context, now time.Time, stuckBefore time.Time, limit int) ([]domain.ArticleSchedule, error) {
+	ss, err := n.dao.FindDue(ctx, now.UnixMilli(), stuckBefore.UnixMilli(), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]domain.ArticleSchedule, 0, len(ss))
+	for _, s := range ss {
+		res = append(res, n.ConvertsDomainSchedule(&s))
+	}
+
+	return res, nil
+}
+
+// @func: Claim
+// @date: 2024-01-22 15:50:26
+// @brief: 定时发表-认领计划
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param s
+// @return bool 是否认领成功
+// @return error
+func (n *NormalArticleScheduleRepository) Claim(ctx context.Context, s domain.ArticleSchedule) (bool, error) {
+	return n.dao.Claim(ctx, n.ConvertsDaoSchedule(&s))
+}
+
+// @func: Finish
+// @date: 2024-01-22 15:51:40
+// @brief: 定时发表-记录发表结果
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param id
+// @param status
+// @param errMsg
+// @return error
+func (n *NormalArticleScheduleRepository) Finish(ctx context.Context, id int64, status domain.ScheduleStatus, errMsg string) error {
+	return n.dao.Finish(ctx, id, status.ToUint8(), errMsg)
+}
+
+func (n *NormalArticleScheduleRepository) ConvertsDaoSchedule(s *domain.ArticleSchedule) dao.ArticleSchedule {
+	return dao.ArticleSchedule{
+		Id:          s.Id,
+		ArtId:       s.ArtId,
+		AuthorId:    s.AuthorId,
+		PublishTime: s.PublishTime.UnixMilli(),
+		Status:      s.Status.ToUint8(),
+		Error:       s.Error,
+		Ctime:       s.Ctime.UnixMilli(),
+		Utime:       s.Utime.UnixMilli(),
+	}
+}
+
+func (n *NormalArticleScheduleRepository) ConvertsDomainSchedule(s *dao.ArticleSchedule) domain.ArticleSchedule {
+	return domain.ArticleSchedule{
+		Id:          s.Id,
+		ArtId:       s.ArtId,
+		AuthorId:    s.AuthorId,
+		PublishTime: time.UnixMilli(s.PublishTime),
+		Status:      domain.ScheduleStatus(s.Status),
+		Error:       s.Error,
+		Ctime:       time.UnixMilli(s.Ctime),
+		Utime:       time.UnixMilli(s.Utime),
+	}
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"kitbook/internal/domain"
+	"kitbook/internal/repository/dao"
+	"kitbook/pkg/fulltext"
+	"time"
+)
+
+// 搜索结果正文摘要的最大字数
+const searchSnippetLen = 120
+
+type ArticleSearchRepository interface {
+	Index(ctx context.Context, art domain.SearchArticle) error
+	Delete(ctx context.Context, artId int64) error
+	Search(ctx context.Context, query string, offset int, limit int) (domain.SearchArticleResult, error)
+	Prune(ctx context.Context, before time.Time, keep map[int64]struct{}) (int, error)
+}
+
+type NormalArticleSearchRepository struct {
+	dao dao.ArticleSearchDao
+}
+
+func NewNormalArticleSearchRepository(dao dao.ArticleSearchDao) ArticleSearchRepository {
+	return &NormalArticleSearchRepository{
+		dao: dao,
+	}
+}
+
+// @func: Index
+// @date: 2024-01-23 15:35:12
+// @brief: 帖子搜索-新增或覆盖帖子索引
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param art
+// @return error
+func (n *NormalArticleSearchRepository) Index(ctx context.Context, art domain.SearchArticle) error {
+	return n.dao.Upsert(ctx, n.ConvertsDaoSearchArticle(&art))
+}
+
+// @func: Delete
+// @date: 2024-01-23 15:36:26
+// @brief: 帖子搜索-删除帖子索引
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param artId
+// @return error
+func (n *NormalArticleSearchRepository) Delete(ctx context.Context, artId int64) error {
+	return n.dao.Delete(ctx, artId)
+}
+
+// @func: Search
+// @date: 2024-01-23 15:38:40
+// @brief: 帖子搜索-分页检索并高亮标题、截取正文摘要
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param query
+// @param offset
+// @param limit
+// @return domain.SearchArticleResult
+// @return error
+func (n *NormalArticleSearchRepository) Search(ctx context.Context, query string, offset int, limit int) (domain.SearchArticleResult, error) {
+	hits, total, err := n.dao.Search(ctx, query, offset, limit)
+	if err != nil {
+		return domain.SearchArticleResult{}, err
+	}
+
+	res := domain.SearchArticleResult{
+		Total: total,
+		Hits:  make([]domain.SearchArticleHit, 0, len(hits)),
+	}
+	for _, hit := range hits {
+		res.Hits = append(res.Hits, domain.SearchArticleHit{
+			Article: n.ConvertsDomainSearchArticle(&hit.Article),
+			Score:   hit.Score,
+			Title:   fulltext.Highlight(hit.Article.Title, query, 0),
+			Snippet: fulltext.Highlight(hit.Article.Content, query, searchSnippetLen),
+		})
+	}
+
+	return res, nil
+}
+
+// @func: Prune
+// @date: 2024-01-23 15:40:52
+// @brief: 帖子搜索-重建后清理已撤回、删除的帖子
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param before 重建开始时间
+// @param keep 本次重建写入的帖子
+// @return int 清理数
+// @return error
+func (n *NormalArticleSearchRepository) Prune(ctx context.Context, before time.Time, keep map[int64]struct{}) (int, error) {
+	return n.dao.Prune(ctx, before.UnixMilli(), keep)
+}
+
+func (n *NormalArticleSearchRepository) ConvertsDaoSearchArticle(art *domain.SearchArticle) dao.SearchArticle {
+	return dao.SearchArticle{
+		Id:         art.Id,
+		AuthorId:   art.AuthorId,
+		AuthorName: art.AuthorName,
+		Title:      art.Title,
+		Content:    art.Content,
+		Utime:      art.Utime.UnixMilli(),
+	}
+}
+
+func (n *NormalArticleSearchRepository) ConvertsDomainSearchArticle(art *dao.SearchArticle) domain.SearchArticle {
+	return domain.SearchArticle{
+		Id:         art.Id,
+		AuthorId:   art.AuthorId,
+		AuthorName: art.AuthorName,
+		Title:      art.Title,
+		Content:    art.Content,
+		Utime:      time.UnixMilli(art.Utime),
+	}
+}
@@ -0,0 +1,120 @@
+// Package repository
+// @Description: 数据转发层-帖子标签-单元测试
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"kitbook/internal/domain"
+	"kitbook/internal/repository/dao"
+	"strings"
+	"testing"
+)
+
+// @func: TestCacheArticleRepository_UpdateTags
+// @date: 2024-01-21 16:20:45
+// @brief: 单元测试-帖子存放在MySQL时, 标签写入失败则帖子修改一同回滚
+// @author: Kewin Li
+// @param t
+func TestCacheArticleRepository_UpdateTags(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer sqlDB.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `articles` SET").
+		WithArgs("", sqlmock.AnyArg(), "标题", sqlmock.AnyArg(), int64(2), int64(1), int64(123), int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("SAVEPOINT").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM `article_tags` WHERE art_id = \\?").
+		WithArgs(int64(1)).
+		WillReturnError(errors.New("数据库错误"))
+	mock.ExpectExec("ROLLBACK TO SAVEPOINT").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      sqlDB,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		SkipDefaultTransaction: true,
+		DisableAutomaticPing:   true,
+	})
+	assert.NoError(t, err)
+
+	// 写入失败时不会操作缓存
+	repo := NewCacheArticleRepository(dao.NewGormArticleDao(db), dao.NewGormArticleTagDao(db),
+		dao.NewGormArticleRevisionDao(db), dao.NewGormUploadRefDao(db), nil, nil)
+	err = repo.Update(context.Background(), domain.Article{
+		Id:      1,
+		Title:   "标题",
+		Content: "内容",
+		Tags:    []string{"go"},
+		Author:  domain.Author{Id: 123},
+		Version: 1,
+	})
+	assert.Equal(t, errors.New("数据库错误"), err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// @func: TestCacheArticleRepository_UpdateUploadRefs
+// @date: 2024-01-28 15:50:32
+// @brief: 单元测试-帖子存放在MySQL时, 上传文件引用写入失败则帖子修改一同回滚
+// @author: Kewin Li
+// @param t
+func TestCacheArticleRepository_UpdateUploadRefs(t *testing.T) {
+	hash := strings.Repeat("ab", 32)
+	content := "![图](/files/" + hash + ") [附件](/files/" + hash + ")"
+
+	sqlDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer sqlDB.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE `articles` SET").
+		WithArgs("", content, "标题", sqlmock.AnyArg(), int64(2), int64(1), int64(123), int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("SAVEPOINT").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM `article_tags` WHERE art_id = \\?").
+		WithArgs(int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("SAVEPOINT").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM `upload_refs` WHERE biz = \\? AND art_id = \\?").
+		WithArgs("article", int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// 正文中重复引用的文件只记录一次
+	mock.ExpectExec("INSERT INTO `upload_refs`").
+		WithArgs("article", int64(1), hash, int64(123), sqlmock.AnyArg()).
+		WillReturnError(errors.New("数据库错误"))
+	mock.ExpectExec("ROLLBACK TO SAVEPOINT").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      sqlDB,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		SkipDefaultTransaction: true,
+		DisableAutomaticPing:   true,
+	})
+	assert.NoError(t, err)
+
+	repo := NewCacheArticleRepository(dao.NewGormArticleDao(db), dao.NewGormArticleTagDao(db),
+		dao.NewGormArticleRevisionDao(db), dao.NewGormUploadRefDao(db), nil, nil)
+	err = repo.Update(context.Background(), domain.Article{
+		Id:      1,
+		Title:   "标题",
+		Content: content,
+		Author:  domain.Author{Id: 123},
+		Version: 1,
+	})
+	assert.Equal(t, errors.New("数据库错误"), err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
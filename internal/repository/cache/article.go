@@ -25,6 +25,7 @@ type ArticleCache interface {
 	DelFirstPage(ctx context.Context, userId int64) error
 	GetById(ctx context.Context, artId int64) (domain.Article, error)
 	SetById(ctx context.Context, art domain.Article) error
+	DelById(ctx context.Context, artId int64) error
 	GetPubById(ctx context.Context, artId int64) (domain.Article, error)
 	SetPubById(ctx context.Context, art domain.Article) error
 	SetPub(ctx context.Context, art domain.Article) error
@@ -199,6 +200,18 @@ func (r *RedisArticleCache) SetById(ctx context.Context, art domain.Article) err
 	return r.client.Set(ctx, r.createPreCacheKey(art.Id), val, time.Minute).Err()
 }
 
+// @func: DelById
+// @date: 2024-01-23 10:20:16
+// @brief: 清除列表详情缓存-按Id, 制作库修改后调用, 避免读到旧版本号
+// @author: Kewin Li
+// @receiver r
+// @param ctx
+// @param artId
+// @return error
+func (r *RedisArticleCache) DelById(ctx context.Context, artId int64) error {
+	return r.client.Del(ctx, r.createPreCacheKey(artId)).Err()
+}
+
 // @func: GetPubById
 // @date: 2023-12-06 22:59:52
 // @brief: 帖子查询-获取读者帖子缓存
@@ -0,0 +1,145 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"kitbook/internal/domain"
+	"strconv"
+	"time"
+)
+
+const (
+	fieldFollowers = "followers"
+	fieldFollowees = "followees"
+)
+
+type FollowCache interface {
+	Follow(ctx context.Context, follower int64, followee int64) error
+	Unfollow(ctx context.Context, follower int64, followee int64) error
+	GetStatics(ctx context.Context, uid int64) (domain.FollowStatics, error)
+	SetStatics(ctx context.Context, statics domain.FollowStatics) error
+}
+
+type RedisFollowCache struct {
+	client     redis.Cmdable
+	expiration time.Duration
+}
+
+func NewRedisFollowCache(client redis.Cmdable) FollowCache {
+	return &RedisFollowCache{
+		client:     client,
+		expiration: 15 * time.Minute,
+	}
+}
+
+// @func: Follow
+// @date: 2024-01-12 21:25:47
+// @brief: 关注-被关注者粉丝数+1, 关注者关注数+1
+// @author: Kewin Li
+// @receiver r
+// @param ctx
+// @param follower
+// @param followee
+// @return error
+func (r *RedisFollowCache) Follow(ctx context.Context, follower int64, followee int64) error {
+	return r.updateStaticsIfPresent(ctx, follower, followee, 1)
+}
+
+// @func: Unfollow
+// @date: 2024-01-12 21:26:33
+// @brief: 取消关注-被关注者粉丝数-1, 关注者关注数-1
+// @author: Kewin Li
+// @receiver r
+// @param ctx
+// @param follower
+// @param followee
+// @return error
+func (r *RedisFollowCache) Unfollow(ctx context.Context, follower int64, followee int64) error {
+	return r.updateStaticsIfPresent(ctx, follower, followee, -1)
+}
+
+// @func: updateStaticsIfPresent
+// @date: 2024-01-12 21:28:10
+// @brief: 缓存存在时才更新计数, 与互动模块共用同一个lua脚本
+// @author: Kewin Li
+// @receiver r
+// @param ctx
+// @param follower
+// @param followee
+// @param delta
+// @return error
+func (r *RedisFollowCache) updateStaticsIfPresent(ctx context.Context, follower int64, followee int64, delta int64) error {
+	err := r.client.Eval(ctx, luaIncrCnt, []string{r.createKey(followee)}, fieldFollowers, delta).Err()
+	if err != nil {
+		return err
+	}
+
+	return r.client.Eval(ctx, luaIncrCnt, []string{r.createKey(follower)}, fieldFollowees, delta).Err()
+}
+
+// @func: GetStatics
+// @date: 2024-01-12 21:31:52
+// @brief: 获取缓存的粉丝数、关注数
+// @author: Kewin Li
+// @receiver r
+// @param ctx
+// @param uid
+// @return domain.FollowStatics
+// @return error
+func (r *RedisFollowCache) GetStatics(ctx context.Context, uid int64) (domain.FollowStatics, error) {
+	res, err := r.client.HGetAll(ctx, r.createKey(uid)).Result()
+	if err != nil {
+		return domain.FollowStatics{}, err
+	}
+	// 数据不存在的情况
+	if len(res) == 0 {
+		return domain.FollowStatics{}, ErrKeyNotExist
+	}
+
+	followers, err := strconv.ParseInt(res[fieldFollowers], 10, 64)
+	if err != nil {
+		return domain.FollowStatics{}, err
+	}
+	followees, err := strconv.ParseInt(res[fieldFollowees], 10, 64)
+	if err != nil {
+		return domain.FollowStatics{}, err
+	}
+
+	return domain.FollowStatics{
+		Uid:       uid,
+		Followers: followers,
+		Followees: followees,
+	}, nil
+}
+
+// @func: SetStatics
+// @date: 2024-01-12 21:34:05
+// @brief: 写入缓存的粉丝数、关注数
+// @author: Kewin Li
+// @receiver r
+// @param ctx
+// @param statics
+// @return error
+func (r *RedisFollowCache) SetStatics(ctx context.Context, statics domain.FollowStatics) error {
+	key := r.createKey(statics.Uid)
+	err := r.client.HSet(ctx, key,
+		fieldFollowers, statics.Followers,
+		fieldFollowees, statics.Followees).Err()
+	if err != nil {
+		return err
+	}
+
+	return r.client.Expire(ctx, key, r.expiration).Err()
+}
+
+// @func: createKey
+// @date: 2024-01-12 21:35:20
+// @brief: 创建关注模块的key
+// @author: Kewin Li
+// @receiver r
+// @param uid
+// @return string
+func (r *RedisFollowCache) createKey(uid int64) string {
+	return fmt.Sprintf("follow_statics:%d", uid)
+}
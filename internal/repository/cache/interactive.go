@@ -13,12 +13,15 @@ import (
 var (
 	//go:embed lua/incr_cnt.lua
 	luaIncrCnt string
+	//go:embed lua/incr_field_cnt.lua
+	luaIncrFieldCnt string
 )
 
 const (
 	fieldReadCnt    = "read_cnt"
 	fieldLikeCnt    = "like_cnt"
 	fieldCollectCnt = "collect_cnt"
+	fieldCommentCnt = "comment_cnt"
 )
 
 type InteractiveCache interface {
@@ -27,6 +30,7 @@ type InteractiveCache interface {
 	DecreaseLikeCntIfPresent(ctx context.Context, biz string, bizId int64) error
 	IncrCollectionCntIfPresent(ctx context.Context, biz string, bizId int64) error
 	DecrCollectionCntIfPresent(ctx context.Context, biz string, bizId int64) error
+	IncrCommentCntIfPresent(ctx context.Context, biz string, bizId int64, delta int64) error
 	Get(ctx context.Context, biz string, bizId int64) (domain.Interactive, error)
 	Set(ctx context.Context, biz string, bizId int64, intr domain.Interactive) error
 }
@@ -113,6 +117,20 @@ func (r *RedisInteractiveCache) DecrCollectionCntIfPresent(ctx context.Context,
 
 }
 
+// @func: IncrCommentCntIfPresent
+// @date: 2024-01-08 21:12:40
+// @brief: 评论数增减, 删除评论时可能连带删除多条回复, 旧缓存中没有评论数字段时不修改, 等过期或回源后重建
+// @author: Kewin Li
+// @receiver r
+// @param ctx
+// @param biz
+// @param bizId
+// @param delta 负数表示减少
+// @return error
+func (r *RedisInteractiveCache) IncrCommentCntIfPresent(ctx context.Context, biz string, bizId int64, delta int64) error {
+	return r.client.Eval(ctx, luaIncrFieldCnt, []string{r.createKey(biz, bizId)}, fieldCommentCnt, delta).Err()
+}
+
 // @func: Get
 // @date: 2023-12-15 17:05:05
 // @brief: 获取缓存互动模块数据
@@ -130,8 +148,8 @@ func (r *RedisInteractiveCache) Get(ctx context.Context, biz string, bizId int64
 	if err != nil {
 		return domain.Interactive{}, err
 	}
-	// 数据不存在的情况
-	if len(res) == 0 {
+	// 数据不存在, 或是加入评论数之前写入的旧缓存, 都回源重建
+	if _, ok := res[fieldCommentCnt]; !ok {
 		return domain.Interactive{}, ErrKeyNotExist
 	}
 
@@ -152,7 +170,8 @@ func (r *RedisInteractiveCache) Set(ctx context.Context, biz string, bizId int64
 		bizId, intr.BizId,
 		fieldReadCnt, intr.ReadCnt,
 		fieldLikeCnt, intr.LikeCnt,
-		fieldCollectCnt, intr.CollectCnt).Err()
+		fieldCollectCnt, intr.CollectCnt,
+		fieldCommentCnt, intr.CommentCnt).Err()
 
 	if err != nil {
 		return err
@@ -186,11 +205,13 @@ func (r *RedisInteractiveCache) convertsDomainIntrFromCache(res map[string]strin
 	readCnt, err := strconv.ParseInt(res[fieldReadCnt], 10, 64)
 	likeCnt, err := strconv.ParseInt(res[fieldLikeCnt], 10, 64)
 	collectCnt, err := strconv.ParseInt(res[fieldCollectCnt], 10, 64)
+	commentCnt, err := strconv.ParseInt(res[fieldCommentCnt], 10, 64)
 
 	return domain.Interactive{
 		BizId:      bizId,
 		ReadCnt:    readCnt,
 		LikeCnt:    likeCnt,
 		CollectCnt: collectCnt,
+		CommentCnt: commentCnt,
 	}, err
 }
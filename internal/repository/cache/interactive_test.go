@@ -0,0 +1,128 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"kitbook/internal/domain"
+	"kitbook/internal/repository/cache/redismocks"
+	"testing"
+)
+
+// @func: TestRedisInteractiveCache_IncrCommentCntIfPresent
+// @date: 2024-01-28 18:40:12
+// @brief: 单元测试-评论数增减只修改已有的评论数字段
+// @author: Kewin Li
+// @param t
+func TestRedisInteractiveCache_IncrCommentCntIfPresent(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock func(ctrl *gomock.Controller) redis.Cmdable
+
+		wantErr error
+	}{
+		{
+			name: "修改成功",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				hdl := redis.NewCmd(context.Background())
+				hdl.SetVal(int64(1))
+				cmd.EXPECT().Eval(gomock.Any(), luaIncrFieldCnt,
+					[]string{"interactive:article:1"}, fieldCommentCnt, int64(-3)).Return(hdl)
+				return cmd
+			},
+		},
+		{
+			name: "redis错误",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				hdl := redis.NewCmd(context.Background())
+				hdl.SetErr(errors.New("redis错误"))
+				cmd.EXPECT().Eval(gomock.Any(), luaIncrFieldCnt,
+					[]string{"interactive:article:1"}, fieldCommentCnt, int64(-3)).Return(hdl)
+				return cmd
+			},
+			wantErr: errors.New("redis错误"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			c := NewRedisInteractiveCache(tc.mock(ctrl))
+			err := c.IncrCommentCntIfPresent(context.Background(), "article", 1, -3)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+// @func: TestRedisInteractiveCache_Get
+// @date: 2024-01-28 18:45:30
+// @brief: 单元测试-没有评论数字段的旧缓存视为不存在, 回源重建
+// @author: Kewin Li
+// @param t
+func TestRedisInteractiveCache_Get(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		res map[string]string
+
+		wantIntr domain.Interactive
+		wantErr  error
+	}{
+		{
+			name: "查询成功",
+			res: map[string]string{
+				"biz_id":        "1",
+				fieldReadCnt:    "10",
+				fieldLikeCnt:    "5",
+				fieldCollectCnt: "2",
+				fieldCommentCnt: "50",
+			},
+			wantIntr: domain.Interactive{
+				BizId:      1,
+				ReadCnt:    10,
+				LikeCnt:    5,
+				CollectCnt: 2,
+				CommentCnt: 50,
+			},
+		},
+		{
+			name:    "缓存不存在",
+			res:     map[string]string{},
+			wantErr: ErrKeyNotExist,
+		},
+		{
+			name: "旧缓存没有评论数字段",
+			res: map[string]string{
+				"biz_id":        "1",
+				fieldReadCnt:    "10",
+				fieldLikeCnt:    "5",
+				fieldCollectCnt: "2",
+			},
+			wantErr: ErrKeyNotExist,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			cmd := redismocks.NewMockCmdable(ctrl)
+			hdl := redis.NewMapStringStringCmd(context.Background())
+			hdl.SetVal(tc.res)
+			cmd.EXPECT().HGetAll(gomock.Any(), "interactive:article:1").Return(hdl)
+
+			c := NewRedisInteractiveCache(cmd)
+			intr, err := c.Get(context.Background(), "article", 1)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantIntr, intr)
+		})
+	}
+}
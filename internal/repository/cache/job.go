@@ -0,0 +1,217 @@
+package cache
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"kitbook/internal/domain"
+	"time"
+)
+
+var (
+	//go:embed lua/set_job_callback.lua
+	luaSetJobCallback string
+)
+
+var ErrInvalidCallbackToken = errors.New("回调token无效或已使用")
+
+// 执行器登记token时写入的占位值, 表示尚未回调
+const jobCallbackPending = "pending"
+
+type JobCallbackCache interface {
+	Register(ctx context.Context, token string, expiration time.Duration) error
+	Set(ctx context.Context, cb domain.JobCallback) error
+	Get(ctx context.Context, token string) (domain.JobCallback, error)
+}
+
+// RedisJobCallbackCache
+// @Description: 远程任务回调结果, 回调请求可能落到任意web结点, 通过redis转交给等待中的执行器
+type RedisJobCallbackCache struct {
+	client     redis.Cmdable
+	expiration time.Duration
+}
+
+func NewRedisJobCallbackCache(client redis.Cmdable) JobCallbackCache {
+	return &RedisJobCallbackCache{
+		client:     client,
+		expiration: time.Hour,
+	}
+}
+
+// @func: Register
+// @date: 2024-01-17 14:08:52
+// @brief: 任务回调缓存-执行器下发任务前登记token, 只有登记过的token才能回调
+// @author: Kewin Li
+// @receiver r
+// @param ctx
+// @param token
+// @param expiration 等待回调的最长时间, <=0时使用默认值
+// @return error
+func (r *RedisJobCallbackCache) Register(ctx context.Context, token string, expiration time.Duration) error {
+	if expiration <= 0 {
+		expiration = r.expiration
+	}
+
+	ok, err := r.client.SetNX(ctx, r.createKey(token), jobCallbackPending, expiration).Result()
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return errors.New("回调token重复登记")
+	}
+
+	return nil
+}
+
+// @func: Set
+// @date: 2024-01-17 14:10:35
+// @brief: 任务回调缓存-保存回调结果, token未登记、已过期或已回调时返回ErrInvalidCallbackToken
+// @author: Kewin Li
+// @receiver r
+// @param ctx
+// @param cb
+// @return error
+func (r *RedisJobCallbackCache) Set(ctx context.Context, cb domain.JobCallback) error {
+	val, err := json.Marshal(&cb)
+	if err != nil {
+		return err
+	}
+
+	res, err := r.client.Eval(ctx, luaSetJobCallback, []string{r.createKey(cb.Token)}, val, jobCallbackPending).Int()
+	if err != nil {
+		return err
+	}
+
+	switch res {
+	// token未登记或已过期
+	case -1:
+		return ErrInvalidCallbackToken
+	// 已回调过
+	case -2:
+		return ErrInvalidCallbackToken
+	default:
+		return nil
+	}
+}
+
+// @func: Get
+// @date: 2024-01-17 14:12:08
+// @brief: 任务回调缓存-查询回调结果, 尚未回调时返回ErrKeyNotExist
+// @author: Kewin Li
+// @receiver r
+// @param ctx
+// @param token
+// @return domain.JobCallback
+// @return error
+func (r *RedisJobCallbackCache) Get(ctx context.Context, token string) (domain.JobCallback, error) {
+	var cb domain.JobCallback
+
+	val, err := r.client.Get(ctx, r.createKey(token)).Bytes()
+	if err != nil {
+		return cb, err
+	}
+
+	// 已登记但尚未回调
+	if string(val) == jobCallbackPending {
+		return cb, ErrKeyNotExist
+	}
+
+	err = json.Unmarshal(val, &cb)
+	return cb, err
+}
+
+// @func: createKey
+// @date: 2024-01-17 14:13:20
+// @brief: 任务回调缓存-key
+// @author: Kewin Li
+// @receiver r
+// @param token
+// @return string
+func (r *RedisJobCallbackCache) createKey(token string) string {
+	return fmt.Sprintf("job:callback:%s", token)
+}
+
+type JobNodeCache interface {
+	Report(ctx context.Context, node domain.JobNode) error
+	List(ctx context.Context) ([]domain.JobNode, error)
+}
+
+// RedisJobNodeCache
+// @Description: 调度结点负载, 所有结点共用一个hash, 超时未上报的结点视为下线
+type RedisJobNodeCache struct {
+	client     redis.Cmdable
+	expiration time.Duration
+}
+
+func NewRedisJobNodeCache(client redis.Cmdable) JobNodeCache {
+	return &RedisJobNodeCache{
+		client:     client,
+		expiration: 30 * time.Second,
+	}
+}
+
+// @func: Report
+// @date: 2024-01-20 10:20:36
+// @brief: 调度结点缓存-上报当前结点负载
+// @author: Kewin Li
+// @receiver r
+// @param ctx
+// @param node
+// @return error
+func (r *RedisJobNodeCache) Report(ctx context.Context, node domain.JobNode) error {
+	val, err := json.Marshal(&node)
+	if err != nil {
+		return err
+	}
+
+	return r.client.HSet(ctx, r.key(), node.Name, val).Err()
+}
+
+// @func: List
+// @date: 2024-01-20 10:23:10
+// @brief: 调度结点缓存-查询在线结点的负载, 顺带清理超时未上报的结点
+// @author: Kewin Li
+// @receiver r
+// @param ctx
+// @return []domain.JobNode
+// @return error
+func (r *RedisJobNodeCache) List(ctx context.Context) ([]domain.JobNode, error) {
+	vals, err := r.client.HGetAll(ctx, r.key()).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(-r.expiration)
+	nodes := make([]domain.JobNode, 0, len(vals))
+	expired := make([]string, 0)
+	for name, val := range vals {
+		var node domain.JobNode
+		err = json.Unmarshal([]byte(val), &node)
+		if err != nil || node.Utime.Before(deadline) {
+			expired = append(expired, name)
+			continue
+		}
+		nodes = append(nodes, node)
+	}
+
+	if len(expired) > 0 {
+		// 清理失败不影响本次查询
+		_ = r.client.HDel(ctx, r.key(), expired...).Err()
+	}
+
+	return nodes, nil
+}
+
+// @func: key
+// @date: 2024-01-20 10:24:05
+// @brief: 调度结点缓存-key
+// @author: Kewin Li
+// @receiver r
+// @return string
+func (r *RedisJobNodeCache) key() string {
+	return "job:nodes"
+}
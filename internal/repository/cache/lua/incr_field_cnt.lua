@@ -0,0 +1,16 @@
+-- 具体业务Key
+local key = KEYS[1]
+-- 哪一个数据: 评论数等后加入的字段
+local cntKey = ARGV[1]
+
+local delta = tonumber(ARGV[2])
+
+-- 旧缓存没有该字段时不能直接HINCRBY, 否则计数会从0开始
+local exist=redis.call("HEXISTS", key, cntKey)
+if exist == 1 then
+
+    redis.call("HINCRBY", key, cntKey, delta)
+    return 1
+else
+    return 0
+end
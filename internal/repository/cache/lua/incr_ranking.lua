@@ -0,0 +1,12 @@
+-- 增量热榜: 跟踪中的帖子累加互动数, 未跟踪时返回空
+local key = KEYS[1]
+
+if redis.call("EXISTS", key) == 0 then
+    return false
+end
+
+redis.call("HINCRBY", key, ARGV[1], ARGV[2])
+redis.call("HINCRBY", key, ARGV[3], ARGV[4])
+redis.call("HINCRBY", key, ARGV[5], ARGV[6])
+
+return redis.call("HGETALL", key)
@@ -0,0 +1,19 @@
+-- 1. 回调结果key job:callback:[token]
+local key = KEYS[1]
+-- 2. 回调结果
+local val = ARGV[1]
+-- 3. 执行器登记时写入的占位值
+local pending = ARGV[2]
+
+local cur = redis.call("get", key)
+if cur == false then
+    -- token未登记或已过期
+    return -1
+elseif cur ~= pending then
+    -- 已回调过, 不允许覆盖
+    return -2
+end
+
+-- 仅覆盖已登记的token, 保留登记时的过期时间
+redis.call("set", key, val, "XX", "KEEPTTL")
+return 0
@@ -2,27 +2,38 @@ package cache
 
 import (
 	"context"
+	_ "embed"
 	"encoding/json"
+	"fmt"
 	"github.com/redis/go-redis/v9"
 	"kitbook/internal/domain"
+	"strconv"
 	"time"
 )
 
+var (
+	//go:embed lua/incr_ranking.lua
+	luaIncrRanking string
+)
+
+// 增量热榜中帖子摘要字段
+const fieldArticle = "article"
+
 type RankingCache interface {
-	Set(ctx context.Context, arts []domain.Article) error
-	Get(ctx context.Context) ([]domain.Article, error)
+	Set(ctx context.Context, name string, arts []domain.Article) error
+	Get(ctx context.Context, name string) ([]domain.Article, error)
 }
 
 type RedisRankingCache struct {
 	client     redis.Cmdable
-	key        string
+	keyPrefix  string
 	expiration time.Duration
 }
 
 func NewRedisRankingCache(client redis.Cmdable) RankingCache {
 	return &RedisRankingCache{
 		client:     client,
-		key:        "ranking:top_n",
+		keyPrefix:  "ranking:top_n",
 		expiration: 3 * time.Minute, // 3min 缓存过期时间
 	}
 }
@@ -33,9 +44,10 @@ func NewRedisRankingCache(client redis.Cmdable) RankingCache {
 // @author: Kewin Li
 // @receiver r
 // @param ctx
+// @param name 榜单名称
 // @param arts
 // @return error
-func (r *RedisRankingCache) Set(ctx context.Context, arts []domain.Article) error {
+func (r *RedisRankingCache) Set(ctx context.Context, name string, arts []domain.Article) error {
 	for i := range arts {
 
 		arts[i].Content = arts[i].CreateAbstract()
@@ -46,7 +58,7 @@ func (r *RedisRankingCache) Set(ctx context.Context, arts []domain.Article) erro
 		return err
 	}
 
-	return r.client.Set(ctx, r.key, val, r.expiration).Err()
+	return r.client.Set(ctx, r.createKey(name), val, r.expiration).Err()
 }
 
 // @func: Get
@@ -55,12 +67,13 @@ func (r *RedisRankingCache) Set(ctx context.Context, arts []domain.Article) erro
 // @author: Kewin Li
 // @receiver r
 // @param ctx
+// @param name 榜单名称
 // @return []domain.Article
 // @return error
-func (r *RedisRankingCache) Get(ctx context.Context) ([]domain.Article, error) {
+func (r *RedisRankingCache) Get(ctx context.Context, name string) ([]domain.Article, error) {
 	var arts []domain.Article
 
-	val, err := r.client.Get(ctx, r.key).Bytes()
+	val, err := r.client.Get(ctx, r.createKey(name)).Bytes()
 	if err != nil {
 		return nil, err
 	}
@@ -68,3 +81,315 @@ func (r *RedisRankingCache) Get(ctx context.Context) ([]domain.Article, error) {
 	err = json.Unmarshal(val, &arts)
 	return arts, err
 }
+
+// @func: createKey
+// @date: 2024-01-15 20:40:18
+// @brief: 热榜缓存-每个榜单一个key
+// @author: Kewin Li
+// @receiver r
+// @param name
+// @return string
+func (r *RedisRankingCache) createKey(name string) string {
+	return fmt.Sprintf("%s:%s", r.keyPrefix, name)
+}
+
+type RankingScoreCache interface {
+	Track(ctx context.Context, item domain.RankingItem, expiration time.Duration) error
+	Incr(ctx context.Context, artId int64, delta domain.Interactive) (domain.RankingItem, error)
+	List(ctx context.Context) ([]domain.RankingItem, error)
+	Untrack(ctx context.Context, artIds []int64) error
+	SetScores(ctx context.Context, name string, scores map[int64]float64, removed []int64) error
+	TopN(ctx context.Context, name string, n int) ([]domain.RankingItem, error)
+}
+
+// RedisRankingScoreCache
+// @Description: 增量热榜, 每个帖子一个hash保存摘要和互动数, 每个榜单一个有序集合保存分数
+type RedisRankingScoreCache struct {
+	client    redis.Cmdable
+	keyPrefix string
+}
+
+func NewRedisRankingScoreCache(client redis.Cmdable) RankingScoreCache {
+	return &RedisRankingScoreCache{
+		client:    client,
+		keyPrefix: "ranking:incr",
+	}
+}
+
+// @func: Track
+// @date: 2024-01-21 10:40:15
+// @brief: 增量热榜缓存-开始跟踪帖子, 写入摘要和当前互动数, 超出统计窗口后自动过期
+// @author: Kewin Li
+// @receiver r
+// @param ctx
+// @param item
+// @param expiration
+// @return error
+func (r *RedisRankingScoreCache) Track(ctx context.Context, item domain.RankingItem, expiration time.Duration) error {
+	art := item.Article
+	art.Content = art.CreateAbstract()
+	val, err := json.Marshal(&art)
+	if err != nil {
+		return err
+	}
+
+	key := r.itemKey(art.Id)
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key,
+			fieldArticle, val,
+			fieldReadCnt, item.Interactive.ReadCnt,
+			fieldLikeCnt, item.Interactive.LikeCnt,
+			fieldCollectCnt, item.Interactive.CollectCnt)
+		pipe.Expire(ctx, key, expiration)
+		// 跟踪中的帖子按更新时间排序, 便于定时重算
+		pipe.ZAdd(ctx, r.indexKey(), redis.Z{
+			Score:  float64(art.Utime.UnixMilli()),
+			Member: art.Id,
+		})
+		return nil
+	})
+
+	return err
+}
+
+// @func: Incr
+// @date: 2024-01-21 10:45:32
+// @brief: 增量热榜缓存-累加跟踪中帖子的互动数, 未跟踪时返回ErrKeyNotExist
+// @author: Kewin Li
+// @receiver r
+// @param ctx
+// @param artId
+// @param delta
+// @return domain.RankingItem 累加后的帖子及互动数
+// @return error
+func (r *RedisRankingScoreCache) Incr(ctx context.Context, artId int64, delta domain.Interactive) (domain.RankingItem, error) {
+	res, err := r.client.Eval(ctx, luaIncrRanking, []string{r.itemKey(artId)},
+		fieldReadCnt, delta.ReadCnt,
+		fieldLikeCnt, delta.LikeCnt,
+		fieldCollectCnt, delta.CollectCnt).StringSlice()
+	if err != nil {
+		return domain.RankingItem{}, err
+	}
+
+	vals := make(map[string]string, len(res)/2)
+	for i := 0; i+1 < len(res); i += 2 {
+		vals[res[i]] = res[i+1]
+	}
+
+	return r.convertsDomainItem(vals)
+}
+
+// @func: List
+// @date: 2024-01-21 10:50:26
+// @brief: 增量热榜缓存-查询全部跟踪中的帖子, 顺带清理已过期的帖子
+// @author: Kewin Li
+// @receiver r
+// @param ctx
+// @return []domain.RankingItem
+// @return error
+func (r *RedisRankingScoreCache) List(ctx context.Context) ([]domain.RankingItem, error) {
+	ids, err := r.client.ZRange(ctx, r.indexKey(), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	items, expired, err := r.getItems(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(expired) > 0 {
+		// 清理失败不影响本次查询
+		_ = r.client.ZRem(ctx, r.indexKey(), expired...).Err()
+	}
+
+	return items, nil
+}
+
+// @func: Untrack
+// @date: 2024-01-21 10:53:40
+// @brief: 增量热榜缓存-停止跟踪帖子
+// @author: Kewin Li
+// @receiver r
+// @param ctx
+// @param artIds
+// @return error
+func (r *RedisRankingScoreCache) Untrack(ctx context.Context, artIds []int64) error {
+	if len(artIds) == 0 {
+		return nil
+	}
+
+	members := make([]any, 0, len(artIds))
+	keys := make([]string, 0, len(artIds))
+	for _, id := range artIds {
+		members = append(members, id)
+		keys = append(keys, r.itemKey(id))
+	}
+
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRem(ctx, r.indexKey(), members...)
+		pipe.Del(ctx, keys...)
+		return nil
+	})
+
+	return err
+}
+
+// @func: SetScores
+// @date: 2024-01-21 10:56:18
+// @brief: 增量热榜缓存-更新榜单中帖子的分数, 并移出超出统计窗口的帖子
+// @author: Kewin Li
+// @receiver r
+// @param ctx
+// @param name 榜单名称
+// @param scores 帖子ID-->分数
+// @param removed 需要移出榜单的帖子
+// @return error
+func (r *RedisRankingScoreCache) SetScores(ctx context.Context, name string, scores map[int64]float64, removed []int64) error {
+	if len(scores) == 0 && len(removed) == 0 {
+		return nil
+	}
+
+	key := r.scoreKey(name)
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if len(scores) > 0 {
+			members := make([]redis.Z, 0, len(scores))
+			for id, score := range scores {
+				members = append(members, redis.Z{Score: score, Member: id})
+			}
+			pipe.ZAdd(ctx, key, members...)
+		}
+
+		if len(removed) > 0 {
+			members := make([]any, 0, len(removed))
+			for _, id := range removed {
+				members = append(members, id)
+			}
+			pipe.ZRem(ctx, key, members...)
+		}
+		return nil
+	})
+
+	return err
+}
+
+// @func: TopN
+// @date: 2024-01-21 11:02:45
+// @brief: 增量热榜缓存-按分数从高到低取出榜单前n个帖子
+// @author: Kewin Li
+// @receiver r
+// @param ctx
+// @param name 榜单名称
+// @param n
+// @return []domain.RankingItem
+// @return error
+func (r *RedisRankingScoreCache) TopN(ctx context.Context, name string, n int) ([]domain.RankingItem, error) {
+	ids, err := r.client.ZRevRange(ctx, r.scoreKey(name), 0, int64(n-1)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	// 已过期但尚未被定时重算移出的帖子直接跳过
+	items, _, err := r.getItems(ctx, ids)
+	return items, err
+}
+
+// @func: getItems
+// @date: 2024-01-21 11:05:12
+// @brief: 增量热榜缓存-批量查询帖子, 保持ids的顺序
+// @author: Kewin Li
+// @receiver r
+// @param ctx
+// @param ids
+// @return []domain.RankingItem
+// @return []any 已过期的帖子ID
+// @return error
+func (r *RedisRankingScoreCache) getItems(ctx context.Context, ids []string) ([]domain.RankingItem, []any, error) {
+	if len(ids) == 0 {
+		return []domain.RankingItem{}, nil, nil
+	}
+
+	cmds := make([]*redis.MapStringStringCmd, len(ids))
+	_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, id := range ids {
+			cmds[i] = pipe.HGetAll(ctx, r.itemKey(id))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	items := make([]domain.RankingItem, 0, len(ids))
+	expired := make([]any, 0)
+	for i, cmd := range cmds {
+		item, err := r.convertsDomainItem(cmd.Val())
+		if err != nil {
+			expired = append(expired, ids[i])
+			continue
+		}
+		items = append(items, item)
+	}
+
+	return items, expired, nil
+}
+
+// @func: convertsDomainItem
+// @date: 2024-01-21 11:08:30
+// @brief: 增量热榜缓存-hash转换为domain, 数据不存在时返回ErrKeyNotExist
+// @author: Kewin Li
+// @receiver r
+// @param vals
+// @return domain.RankingItem
+// @return error
+func (r *RedisRankingScoreCache) convertsDomainItem(vals map[string]string) (domain.RankingItem, error) {
+	var item domain.RankingItem
+	val, ok := vals[fieldArticle]
+	if !ok {
+		return item, ErrKeyNotExist
+	}
+
+	err := json.Unmarshal([]byte(val), &item.Article)
+	if err != nil {
+		return item, err
+	}
+
+	item.Interactive.BizId = item.Article.Id
+	item.Interactive.ReadCnt, _ = strconv.ParseInt(vals[fieldReadCnt], 10, 64)
+	item.Interactive.LikeCnt, _ = strconv.ParseInt(vals[fieldLikeCnt], 10, 64)
+	item.Interactive.CollectCnt, _ = strconv.ParseInt(vals[fieldCollectCnt], 10, 64)
+
+	return item, nil
+}
+
+// @func: itemKey
+// @date: 2024-01-21 11:10:02
+// @brief: 增量热榜缓存-帖子摘要及互动数key
+// @author: Kewin Li
+// @receiver r
+// @param artId 帖子ID, 整数或其字符串形式
+// @return string
+func (r *RedisRankingScoreCache) itemKey(artId any) string {
+	return fmt.Sprintf("%s:art:%v", r.keyPrefix, artId)
+}
+
+// @func: indexKey
+// @date: 2024-01-21 11:10:35
+// @brief: 增量热榜缓存-跟踪中帖子的索引key
+// @author: Kewin Li
+// @receiver r
+// @return string
+func (r *RedisRankingScoreCache) indexKey() string {
+	return fmt.Sprintf("%s:arts", r.keyPrefix)
+}
+
+// @func: scoreKey
+// @date: 2024-01-21 11:11:08
+// @brief: 增量热榜缓存-每个榜单一个分数key
+// @author: Kewin Li
+// @receiver r
+// @param name
+// @return string
+func (r *RedisRankingScoreCache) scoreKey(name string) string {
+	return fmt.Sprintf("%s:score:%s", r.keyPrefix, name)
+}
@@ -3,41 +3,65 @@ package cache
 import (
 	"context"
 	"errors"
-	"github.com/ecodeclub/ekit/syncx/atomicx"
 	"kitbook/internal/domain"
+	"sync"
 	"time"
 )
 
 var ErrLocalCacheInvalid = errors.New("本地缓存失效")
 
+// localRankingItem
+// @Description: 一个榜单的本地缓存
+type localRankingItem struct {
+	topN []domain.Article
+	ddl  time.Time
+}
+
 type LocalRankingCache struct {
-	topN       *atomicx.Value[[]domain.Article]
-	ddl        *atomicx.Value[time.Time]
+	// 榜单名称-->榜单数据
+	boards     map[string]localRankingItem
+	lock       sync.RWMutex
 	expiration time.Duration
 }
 
-func (l *LocalRankingCache) Set(ctx context.Context, arts []domain.Article) error {
+func NewLocalRankingCache(expiration time.Duration) *LocalRankingCache {
+	return &LocalRankingCache{
+		boards:     make(map[string]localRankingItem),
+		expiration: expiration,
+	}
+}
+
+func (l *LocalRankingCache) Set(ctx context.Context, name string, arts []domain.Article) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
 
-	l.topN.Store(arts)
-	l.ddl.Store(time.Now().Add(l.expiration))
+	l.boards[name] = localRankingItem{
+		topN: arts,
+		ddl:  time.Now().Add(l.expiration),
+	}
 	return nil
 }
 
-func (l *LocalRankingCache) Get(ctx context.Context) ([]domain.Article, error) {
-	ddl := l.ddl.Load()
-	arts := l.topN.Load()
-	if len(arts) <= 0 || ddl.Before(time.Now()) {
+func (l *LocalRankingCache) Get(ctx context.Context, name string) ([]domain.Article, error) {
+	l.lock.RLock()
+	item := l.boards[name]
+	l.lock.RUnlock()
+
+	if len(item.topN) <= 0 || item.ddl.Before(time.Now()) {
 		return nil, ErrLocalCacheInvalid
 	}
 
-	return arts, nil
+	return item.topN, nil
 }
 
-func (l *LocalRankingCache) ForceGet(ctx context.Context) ([]domain.Article, error) {
-	arts := l.topN.Load()
-	if len(arts) <= 0 {
+func (l *LocalRankingCache) ForceGet(ctx context.Context, name string) ([]domain.Article, error) {
+	l.lock.RLock()
+	item := l.boards[name]
+	l.lock.RUnlock()
+
+	if len(item.topN) <= 0 {
 		return nil, ErrLocalCacheInvalid
 	}
 
-	return arts, nil
+	return item.topN, nil
 }
@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"golang.org/x/sync/errgroup"
+	"kitbook/internal/domain"
+	"kitbook/internal/repository/cache"
+	"kitbook/internal/repository/dao"
+	"kitbook/pkg/logger"
+	"time"
+)
+
+var (
+	ErrCollectionMismatch = dao.ErrCollectionMismatch
+	ErrCollectionNotFound = errors.New("收藏夹不存在")
+)
+
+type CollectionRepository interface {
+	Create(ctx context.Context, c domain.Collection) (int64, error)
+	Update(ctx context.Context, c domain.Collection) error
+	Delete(ctx context.Context, id int64, uid int64) error
+	FindById(ctx context.Context, id int64) (domain.Collection, error)
+	ListByUser(ctx context.Context, uid int64, withPrivate bool) ([]domain.Collection, error)
+	ListItems(ctx context.Context, uid int64, collectId int64, offset int, limit int) ([]domain.CollectionItem, error)
+	MoveItems(ctx context.Context, uid int64, srcId int64, dstId int64, biz string, bizIds []int64) (int64, error)
+}
+
+type NormalCollectionRepository struct {
+	dao       dao.CollectionDao
+	intrCache cache.InteractiveCache
+	artRepo   ArticleRepository
+	l         logger.Logger
+}
+
+func NewNormalCollectionRepository(dao dao.CollectionDao, intrCache cache.InteractiveCache, artRepo ArticleRepository, l logger.Logger) CollectionRepository {
+	return &NormalCollectionRepository{
+		dao:       dao,
+		intrCache: intrCache,
+		artRepo:   artRepo,
+		l:         l,
+	}
+}
+
+// @func: Create
+// @date: 2024-01-14 14:50:03
+// @brief: 收藏夹-新建
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param c
+// @return int64
+// @return error
+func (n *NormalCollectionRepository) Create(ctx context.Context, c domain.Collection) (int64, error) {
+	return n.dao.Insert(ctx, n.ConvertsDaoCollection(&c))
+}
+
+// @func: Update
+// @date: 2024-01-14 14:50:41
+// @brief: 收藏夹-修改
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param c
+// @return error
+func (n *NormalCollectionRepository) Update(ctx context.Context, c domain.Collection) error {
+	return n.dao.Update(ctx, n.ConvertsDaoCollection(&c))
+}
+
+// @func: Delete
+// @date: 2024-01-14 14:51:15
+// @brief: 收藏夹-删除, 连带删除其中的收藏
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param id
+// @param uid
+// @return error
+func (n *NormalCollectionRepository) Delete(ctx context.Context, id int64, uid int64) error {
+	items, err := n.dao.Delete(ctx, id, uid)
+	if err != nil {
+		return err
+	}
+
+	// 与取消收藏一致, 逐条更新收藏数缓存, 部分失败问题不影响删除本身
+	for _, item := range items {
+		err = n.intrCache.DecrCollectionCntIfPresent(ctx, item.Biz, item.BizId)
+		if err != nil {
+			n.l.WARN("收藏数缓存更新失败",
+				logger.Error(err),
+				logger.Field{"biz", item.Biz},
+				logger.Int[int64]("biz_id", item.BizId))
+		}
+	}
+
+	return nil
+}
+
+// @func: FindById
+// @date: 2024-01-14 14:52:30
+// @brief: 收藏夹-查询
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param id
+// @return domain.Collection
+// @return error
+func (n *NormalCollectionRepository) FindById(ctx context.Context, id int64) (domain.Collection, error) {
+	c, err := n.dao.FindById(ctx, id)
+	switch err {
+	case nil:
+		return n.ConvertsDomainCollection(&c), nil
+	case dao.ErrRecordNotFound:
+		return domain.Collection{}, ErrCollectionNotFound
+	default:
+		return domain.Collection{}, err
+	}
+}
+
+// @func: ListByUser
+// @date: 2024-01-14 14:54:02
+// @brief: 收藏夹-查询用户的收藏夹列表
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param uid
+// @param withPrivate
+// @return []domain.Collection
+// @return error
+func (n *NormalCollectionRepository) ListByUser(ctx context.Context, uid int64, withPrivate bool) ([]domain.Collection, error) {
+	cs, err := n.dao.FindByUid(ctx, uid, withPrivate)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]domain.Collection, 0, len(cs))
+	for _, c := range cs {
+		res = append(res, n.ConvertsDomainCollection(&c))
+	}
+
+	return res, nil
+}
+
+// @func: ListItems
+// @date: 2024-01-14 14:58:46
+// @brief: 收藏夹-分页查询收藏夹中的收藏, 并填充帖子标题
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param uid
+// @param collectId
+// @param offset
+// @param limit
+// @return []domain.CollectionItem
+// @return error
+func (n *NormalCollectionRepository) ListItems(ctx context.Context, uid int64, collectId int64, offset int, limit int) ([]domain.CollectionItem, error) {
+	items, err := n.dao.FindItems(ctx, uid, collectId, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]domain.CollectionItem, len(items))
+	var eg errgroup.Group
+	for i, item := range items {
+		i, item := i, item
+		res[i] = domain.CollectionItem{
+			Id:        item.Id,
+			CollectId: item.CollectId,
+			Biz:       item.Biz,
+			BizId:     item.BizId,
+			Utime:     time.UnixMilli(item.Utime),
+		}
+
+		if item.Biz != "article" {
+			continue
+		}
+
+		eg.Go(func() error {
+			art, err2 := n.artRepo.GetPubById(ctx, item.BizId)
+			if err2 != nil {
+				// 帖子可能已被撤回, 不影响收藏列表展示
+				n.l.WARN("收藏夹查询帖子标题失败",
+					logger.Error(err2),
+					logger.Int[int64]("artId", item.BizId))
+				return nil
+			}
+
+			res[i].Article = domain.Article{
+				Id:     art.Id,
+				Title:  art.Title,
+				Author: art.Author,
+			}
+			return nil
+		})
+	}
+
+	return res, eg.Wait()
+}
+
+// @func: MoveItems
+// @date: 2024-01-14 15:06:19
+// @brief: 收藏夹-移动收藏
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param uid
+// @param srcId
+// @param dstId
+// @param biz
+// @param bizIds
+// @return int64
+// @return error
+func (n *NormalCollectionRepository) MoveItems(ctx context.Context, uid int64, srcId int64, dstId int64, biz string, bizIds []int64) (int64, error) {
+	return n.dao.MoveItems(ctx, uid, srcId, dstId, biz, bizIds)
+}
+
+// @func: ConvertsDaoCollection
+// @date: 2024-01-14 15:08:40
+// @brief: Collection Domain--->DAO
+// @author: Kewin Li
+// @receiver n
+// @param c
+// @return dao.Collection
+func (n *NormalCollectionRepository) ConvertsDaoCollection(c *domain.Collection) dao.Collection {
+	return dao.Collection{
+		Id:          c.Id,
+		Uid:         c.Uid,
+		Name:        c.Name,
+		Description: c.Description,
+		Private:     c.Private,
+	}
+}
+
+// @func: ConvertsDomainCollection
+// @date: 2024-01-14 15:09:12
+// @brief: Collection DAO--->Domain
+// @author: Kewin Li
+// @receiver n
+// @param c
+// @return domain.Collection
+func (n *NormalCollectionRepository) ConvertsDomainCollection(c *dao.Collection) domain.Collection {
+	return domain.Collection{
+		Id:          c.Id,
+		Uid:         c.Uid,
+		Name:        c.Name,
+		Description: c.Description,
+		Private:     c.Private,
+		Ctime:       time.UnixMilli(c.Ctime),
+		Utime:       time.UnixMilli(c.Utime),
+	}
+}
@@ -0,0 +1,250 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"golang.org/x/sync/errgroup"
+	"kitbook/internal/domain"
+	"kitbook/internal/repository/cache"
+	"kitbook/internal/repository/dao"
+	"kitbook/pkg/logger"
+	"time"
+)
+
+var (
+	ErrCommentMismatch = dao.ErrCommentMismatch
+	ErrCommentNotFound = dao.ErrRecordNotFound
+)
+
+type CommentRepository interface {
+	CreateComment(ctx context.Context, c domain.Comment) (int64, error)
+	DeleteComment(ctx context.Context, id int64, uid int64) error
+	FindById(ctx context.Context, id int64) (domain.Comment, error)
+	FindByBiz(ctx context.Context, biz string, bizId int64, minId int64, limit int, replyLimit int) ([]domain.Comment, error)
+	GetMoreReplies(ctx context.Context, rootId int64, maxId int64, limit int) ([]domain.Comment, error)
+}
+
+type CacheCommentRepository struct {
+	dao dao.CommentDao
+	// 评论数与阅读数、点赞数、收藏数缓存在一起
+	intrCache cache.InteractiveCache
+	l         logger.Logger
+}
+
+func NewCacheCommentRepository(dao dao.CommentDao, intrCache cache.InteractiveCache, l logger.Logger) CommentRepository {
+	return &CacheCommentRepository{
+		dao:       dao,
+		intrCache: intrCache,
+		l:         l,
+	}
+}
+
+// @func: CreateComment
+// @date: 2024-01-08 21:35:06
+// @brief: 评论-新增评论
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param cmt
+// @return int64
+// @return error
+func (c *CacheCommentRepository) CreateComment(ctx context.Context, cmt domain.Comment) (int64, error) {
+	id, err := c.dao.Insert(ctx, c.ConvertsDaoComment(&cmt))
+	if err != nil {
+		return 0, err
+	}
+
+	// 更新缓存, 部分失败问题不影响评论本身
+	err = c.intrCache.IncrCommentCntIfPresent(ctx, cmt.Biz, cmt.BizId, 1)
+	if err != nil {
+		c.l.WARN("评论数缓存更新失败",
+			logger.Error(err),
+			logger.Field{"biz", cmt.Biz},
+			logger.Int[int64]("biz_id", cmt.BizId))
+	}
+
+	return id, nil
+}
+
+// @func: DeleteComment
+// @date: 2024-01-08 21:38:44
+// @brief: 评论-删除评论
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param id
+// @param uid
+// @return error
+func (c *CacheCommentRepository) DeleteComment(ctx context.Context, id int64, uid int64) error {
+	cmt, cnt, err := c.dao.Delete(ctx, id, uid)
+	if err != nil {
+		return err
+	}
+
+	err = c.intrCache.IncrCommentCntIfPresent(ctx, cmt.Biz, cmt.BizId, -cnt)
+	if err != nil {
+		c.l.WARN("评论数缓存更新失败",
+			logger.Error(err),
+			logger.Field{"biz", cmt.Biz},
+			logger.Int[int64]("biz_id", cmt.BizId))
+	}
+
+	return nil
+}
+
+// @func: FindById
+// @date: 2024-01-08 21:40:29
+// @brief: 评论-按ID查询
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param id
+// @return domain.Comment
+// @return error
+func (c *CacheCommentRepository) FindById(ctx context.Context, id int64) (domain.Comment, error) {
+	cmt, err := c.dao.FindById(ctx, id)
+	if err != nil {
+		return domain.Comment{}, err
+	}
+
+	return c.ConvertsDomainComment(&cmt), nil
+}
+
+// @func: FindByBiz
+// @date: 2024-01-08 21:43:12
+// @brief: 评论-分页查询根评论, 并预加载每条根评论的前N条回复
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param biz
+// @param bizId
+// @param minId
+// @param limit
+// @param replyLimit 每条根评论预加载的回复条数
+// @return []domain.Comment
+// @return error
+func (c *CacheCommentRepository) FindByBiz(ctx context.Context, biz string, bizId int64, minId int64, limit int, replyLimit int) ([]domain.Comment, error) {
+	roots, err := c.dao.FindRootsByBiz(ctx, biz, bizId, minId, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	cmts := make([]domain.Comment, len(roots))
+	var eg errgroup.Group
+	for i := range roots {
+		cmts[i] = c.ConvertsDomainComment(&roots[i])
+		if replyLimit <= 0 {
+			continue
+		}
+
+		// 并发查询每条根评论的回复
+		i := i
+		eg.Go(func() error {
+			replies, err2 := c.dao.FindRepliesByRoot(ctx, roots[i].Id, 0, replyLimit)
+			if err2 != nil {
+				return err2
+			}
+
+			cmts[i].Children = make([]domain.Comment, 0, len(replies))
+			for _, reply := range replies {
+				cmts[i].Children = append(cmts[i].Children, c.ConvertsDomainComment(&reply))
+			}
+			return nil
+		})
+	}
+
+	return cmts, eg.Wait()
+}
+
+// @func: GetMoreReplies
+// @date: 2024-01-08 21:50:37
+// @brief: 评论-分页查询根评论下的更多回复
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param rootId
+// @param maxId
+// @param limit
+// @return []domain.Comment
+// @return error
+func (c *CacheCommentRepository) GetMoreReplies(ctx context.Context, rootId int64, maxId int64, limit int) ([]domain.Comment, error) {
+	replies, err := c.dao.FindRepliesByRoot(ctx, rootId, maxId, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	cmts := make([]domain.Comment, 0, len(replies))
+	for _, reply := range replies {
+		cmts = append(cmts, c.ConvertsDomainComment(&reply))
+	}
+
+	return cmts, nil
+}
+
+// @func: ConvertsDomainComment
+// @date: 2024-01-08 21:52:10
+// @brief: Comment DAO--->Domain
+// @author: Kewin Li
+// @receiver c
+// @param cmt
+// @return domain.Comment
+func (c *CacheCommentRepository) ConvertsDomainComment(cmt *dao.Comment) domain.Comment {
+	res := domain.Comment{
+		Id: cmt.Id,
+		Commenter: domain.User{
+			Id: cmt.Uid,
+		},
+		Biz:     cmt.Biz,
+		BizId:   cmt.BizId,
+		Content: cmt.Content,
+		Ctime:   time.UnixMilli(cmt.Ctime),
+		Utime:   time.UnixMilli(cmt.Utime),
+	}
+
+	if cmt.RootId.Valid {
+		res.RootComment = &domain.Comment{
+			Id: cmt.RootId.Int64,
+		}
+	}
+
+	if cmt.Pid.Valid {
+		res.ParentComment = &domain.Comment{
+			Id: cmt.Pid.Int64,
+		}
+	}
+
+	return res
+}
+
+// @func: ConvertsDaoComment
+// @date: 2024-01-08 21:53:31
+// @brief: Comment Domain--->DAO
+// @author: Kewin Li
+// @receiver c
+// @param cmt
+// @return dao.Comment
+func (c *CacheCommentRepository) ConvertsDaoComment(cmt *domain.Comment) dao.Comment {
+	res := dao.Comment{
+		Id:      cmt.Id,
+		Uid:     cmt.Commenter.Id,
+		Biz:     cmt.Biz,
+		BizId:   cmt.BizId,
+		Content: cmt.Content,
+	}
+
+	if cmt.RootComment != nil {
+		res.RootId = sql.NullInt64{
+			Int64: cmt.RootComment.Id,
+			Valid: true,
+		}
+	}
+
+	if cmt.ParentComment != nil {
+		res.Pid = sql.NullInt64{
+			Int64: cmt.ParentComment.Id,
+			Valid: true,
+		}
+	}
+
+	return res
+}
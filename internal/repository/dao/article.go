@@ -11,7 +11,10 @@ import (
 	"time"
 )
 
-var ErrUserMismatch = errors.New("帖子ID和用户ID不匹配")
+var (
+	ErrUserMismatch    = errors.New("帖子ID和用户ID不匹配")
+	ErrVersionConflict = errors.New("帖子已被修改, 版本号不一致")
+)
 
 type ArticleDao interface {
 	Insert(ctx context.Context, art Article) (int64, error)
@@ -24,6 +27,12 @@ type ArticleDao interface {
 	ListPub(ctx context.Context, start time.Time, offset int, limit int) ([]PublishedArticle, error)
 }
 
+// ArticleTxDao
+// @Description: 帖子存放在MySQL时实现, 标签等附属数据可与帖子在同一事务中写入
+type ArticleTxDao interface {
+	Transaction(ctx context.Context, fn func(d ArticleDao, tx *gorm.DB) error) error
+}
+
 type GormArticleDao struct {
 	db *gorm.DB
 }
@@ -34,6 +43,20 @@ func NewGormArticleDao(db *gorm.DB) ArticleDao {
 	}
 }
 
+// @func: Transaction
+// @date: 2024-01-21 15:40:12
+// @brief: 开启事务, fn中使用事务内的帖子dao及tx, fn返回错误时整体回滚
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param fn
+// @return error
+func (g *GormArticleDao) Transaction(ctx context.Context, fn func(d ArticleDao, tx *gorm.DB) error) error {
+	return g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(NewGormArticleDao(tx), tx)
+	})
+}
+
 // @func: Insert
 // @date: 2023-11-23 00:59:39
 // @brief: 新建帖子记录
@@ -48,6 +71,7 @@ func (g *GormArticleDao) Insert(ctx context.Context, art Article) (int64, error)
 	now := time.Now().UnixMilli()
 	art.Ctime = now
 	art.Utime = now
+	art.Version = 1
 	err := g.db.WithContext(ctx).Create(&art).Error
 
 	return art.Id, err
@@ -55,30 +79,56 @@ func (g *GormArticleDao) Insert(ctx context.Context, art Article) (int64, error)
 
 // @func: UpdateById
 // @date: 2023-11-24 21:02:25
-// @brief:  数据库-修改帖子记录按Id
+// @brief:  数据库-修改帖子记录按Id, 版本号一致才能修改, 修改后版本号加1
 // @author: Kewin Li
 // @receiver g
 // @param ctx
-// @param article
+// @param article 状态为未知时保持原状态
 // @return error
 func (g *GormArticleDao) UpdateById(ctx context.Context, art Article) error {
+	updates := map[string]any{
+		"title":    art.Title,
+		"content":  art.Content,
+		"category": art.Category,
+		"version":  art.Version + 1,
+		"utime":    time.Now().UnixMilli(),
+	}
+	if art.Status != domain.ArticleStatusUnknow {
+		updates["status"] = art.Status
+	}
 
 	result := g.db.WithContext(ctx).Model(&Article{}).
 		Where("id = ?", art.Id).
 		Where("author_id = ?", art.AuthorId).
-		Updates(map[string]any{
-			"title":   art.Title,
-			"content": art.Content,
-			"status":  art.Status,
-			"utime":   time.Now().UnixMilli(),
-		})
+		Where("version = ?", art.Version).
+		Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
 
 	if result.RowsAffected <= 0 {
-		return ErrUserMismatch
+		return g.updateErr(ctx, art)
 	}
 
-	return result.Error
+	return nil
+}
+
+// @func: updateErr
+// @date: 2024-01-23 10:12:40
+// @brief: 数据库-区分修改失败的原因: 帖子属于该作者说明版本号已过期, 否则为非法修改
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param art
+// @return error
+func (g *GormArticleDao) updateErr(ctx context.Context, art Article) error {
+	var cur Article
+	err := g.db.WithContext(ctx).Select("author_id").Where("id = ?", art.Id).First(&cur).Error
+	if err == nil && cur.AuthorId == art.AuthorId {
+		return ErrVersionConflict
+	}
 
+	return ErrUserMismatch
 }
 
 // @func: Sync
@@ -162,48 +212,23 @@ func (g *GormArticleDao) Sync(ctx context.Context, art Article) (int64, error) {
 	err := g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 
 		var err error
-		dao := NewGormArticleDao(tx)
+		authorDao := NewGormArticleAuthorDao(tx)
 
 		if art.Id > 0 {
 			// id存在视为更新
-			err = dao.UpdateById(ctx, art)
+			err = authorDao.Update(ctx, art)
 
 		} else {
 			// id不存在视为新建
-			art.Id, err = dao.Insert(ctx, art)
+			art.Id, err = authorDao.Create(ctx, art)
 
 		}
 		if err != nil {
 			return err
 		}
 
-		now := time.Now().UnixMilli()
-		art.Utime = now
-		art.Ctime = now
-		publishArt := PublishedArticle(art)
-
 		// 操作线上表
-		// id冲突时处理
-		// TODO: 该函数的作用是?
-		err = tx.Clauses(clause.OnConflict{
-			// MYSQL:
-			// INSERT xxx DUPLICATE KEY SET `title` = ?
-
-			//其他方言:
-			//sqlite: INSERT xxx ON CONFLICT DO UPDATES WHERE xxx
-
-			// Columns兼容其他方言
-			Columns: []clause.Column{{Name: "id"}},
-			// TODO: Mysql仅支持该字段, 其他不生效
-			DoUpdates: clause.Assignments(map[string]interface{}{
-				"title":   publishArt.Title,
-				"content": publishArt.Content,
-				"status":  publishArt.Status,
-				"utime":   publishArt.Utime,
-			}),
-		}).Create(&publishArt).Error
-
-		return err
+		return NewGormArticleReaderDao(tx).Upsert(ctx, art)
 	})
 
 	return art.Id, err
@@ -221,33 +246,15 @@ func (g *GormArticleDao) Sync(ctx context.Context, art Article) (int64, error) {
 // @return int64
 // @return error
 func (g *GormArticleDao) SyncStatus(ctx context.Context, artId int64, authorId int64, status uint8) error {
-	now := time.Now().UnixMilli()
 	return g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		// 1. 修改制作库
-		res := tx.Model(&Article{}).
-			Where("id = ?", artId).
-			Where("author_id = ?", authorId).
-			Updates(map[string]any{
-				"status": status,
-				"utime":  now,
-			})
-
-		if res.Error != nil {
-			return res.Error
-		}
-
-		// 更新无效，说明帖子ID和作者ID不匹配
-		if res.RowsAffected <= 0 {
-			return ErrUserMismatch
+		err := NewGormArticleAuthorDao(tx).UpdateStatus(ctx, artId, authorId, status)
+		if err != nil {
+			return err
 		}
 
 		// 2. 修改线上库
-		return tx.Model(&PublishedArticle{}).
-			Where("id = ?", artId).
-			Updates(map[string]any{
-				"status": status,
-				"utime":  now,
-			}).Error
+		return NewGormArticleReaderDao(tx).UpdateStatus(ctx, artId, status)
 	})
 
 }
@@ -321,9 +328,10 @@ func (g *GormArticleDao) GetPubById(ctx context.Context, artId int64) (Published
 func (g *GormArticleDao) ListPub(ctx context.Context, start time.Time, offset int, limit int) ([]PublishedArticle, error) {
 	var arts []PublishedArticle
 	err := g.db.WithContext(ctx).Where("utime < ? AND status = ?", start.UnixMilli(), domain.ArticleStatusPublished).
+		Order("id").
 		Offset(offset).
 		Limit(limit).
-		First(&arts).Error
+		Find(&arts).Error
 	return arts, err
 }
 
@@ -331,10 +339,13 @@ type Article struct {
 	Id       int64  `gorm:"primaryKey, autoIncrement" bson:"id,omitempty"`
 	Title    string `gorm:"type:varchar(256)" bson:"title,omitempty"`
 	Content  string `gorm:"type:BLOB" bson:"content,omitempty"`
+	Category string `gorm:"type:varchar(64);index" bson:"category,omitempty"`
 	AuthorId int64  `gorm:"index" bson:"author_id,omitempty"`
 	Status   uint8  `bson:"status,omitempty"`
-	Ctime    int64  `bson:"ctime,omitempty"`
-	Utime    int64  `bson:"utime,omitempty"`
+	// 制作库版本号, 乐观锁
+	Version int64 `gorm:"not null;default:0" bson:"version,omitempty"`
+	Ctime   int64 `bson:"ctime,omitempty"`
+	Utime   int64 `bson:"utime,omitempty"`
 }
 
 // 同步数据-同库不同表 使用衍生类型拓展一张一样的表结构
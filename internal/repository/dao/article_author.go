@@ -3,11 +3,17 @@ package dao
 import (
 	"context"
 	"gorm.io/gorm"
+	"time"
 )
 
+// ArticleAuthorDao
+// @Description: 制作库, 作者侧的读写
 type ArticleAuthorDao interface {
 	Create(ctx context.Context, art Article) (int64, error)
 	Update(ctx context.Context, art Article) error
+	UpdateStatus(ctx context.Context, artId int64, authorId int64, status uint8) error
+	GetByAuthor(ctx context.Context, userId int64, offset int, limit int) ([]Article, error)
+	GetById(ctx context.Context, artId int64) (Article, error)
 }
 
 type GormArticleAuthorDao struct {
@@ -20,12 +26,69 @@ func NewGormArticleAuthorDao(db *gorm.DB) *GormArticleAuthorDao {
 	}
 }
 
+// @func: Create
+// @date: 2024-01-26 10:05:12
+// @brief: 制作库-新建帖子
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param art
+// @return int64
+// @return error
 func (g *GormArticleAuthorDao) Create(ctx context.Context, art Article) (int64, error) {
-	//TODO implement me
-	panic("implement me")
+	return g.dao().Insert(ctx, art)
 }
 
+// @func: Update
+// @date: 2024-01-26 10:06:30
+// @brief: 制作库-修改帖子, 版本号一致才能修改
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param art
+// @return error
 func (g *GormArticleAuthorDao) Update(ctx context.Context, art Article) error {
-	//TODO implement me
-	panic("implement me")
+	return g.dao().UpdateById(ctx, art)
+}
+
+// @func: UpdateStatus
+// @date: 2024-01-26 10:08:16
+// @brief: 制作库-修改帖子状态
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param artId
+// @param authorId
+// @param status
+// @return error 帖子ID和作者ID不匹配时返回ErrUserMismatch
+func (g *GormArticleAuthorDao) UpdateStatus(ctx context.Context, artId int64, authorId int64, status uint8) error {
+	res := g.db.WithContext(ctx).Model(&Article{}).
+		Where("id = ?", artId).
+		Where("author_id = ?", authorId).
+		Updates(map[string]any{
+			"status": status,
+			"utime":  time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+
+	// 更新无效，说明帖子ID和作者ID不匹配
+	if res.RowsAffected <= 0 {
+		return ErrUserMismatch
+	}
+
+	return nil
+}
+
+func (g *GormArticleAuthorDao) GetByAuthor(ctx context.Context, userId int64, offset int, limit int) ([]Article, error) {
+	return g.dao().GetByAuthor(ctx, userId, offset, limit)
+}
+
+func (g *GormArticleAuthorDao) GetById(ctx context.Context, artId int64) (Article, error) {
+	return g.dao().GetById(ctx, artId)
+}
+
+func (g *GormArticleAuthorDao) dao() *GormArticleDao {
+	return &GormArticleDao{db: g.db}
 }
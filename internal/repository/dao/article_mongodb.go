@@ -6,6 +6,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"kitbook/internal/domain"
 	"time"
 )
 
@@ -40,6 +41,7 @@ func (m *MongoDBArticleDAO) Insert(ctx context.Context, art Article) (int64, err
 	art.Id = m.node.Generate().Int64()
 	art.Ctime = now
 	art.Utime = now
+	art.Version = 1
 	_, err := m.produceCol.InsertOne(ctx, &art)
 	return art.Id, err
 
@@ -47,29 +49,53 @@ func (m *MongoDBArticleDAO) Insert(ctx context.Context, art Article) (int64, err
 
 // @func: UpdateById
 // @date: 2023-12-01 02:26:16
-// @brief: mongodb-更新文档按ID
+// @brief: mongodb-更新文档按ID, 版本号一致才能修改, 修改后版本号加1
 // @author: Kewin Li
 // @receiver m
 // @param ctx
-// @param art
+// @param art 状态为未知时保持原状态
 // @return error
 func (m *MongoDBArticleDAO) UpdateById(ctx context.Context, art Article) error {
 	updateFilter := bson.M{
 		"id":        art.Id,
 		"author_id": art.AuthorId,
 	}
+	// 旧文档没有版本号字段
+	if art.Version > 0 {
+		updateFilter["version"] = art.Version
+	} else {
+		updateFilter["version"] = bson.M{"$exists": false}
+	}
 
-	updateRes, err := m.produceCol.UpdateOne(ctx, updateFilter, bson.D{{"$set", bson.M{
+	set := bson.M{
 		"title":     art.Title,
 		"content":   art.Content,
+		"category":  art.Category,
 		"author_id": art.AuthorId,
-		"status":    art.Status,
 		"utime":     time.Now().UnixMilli(),
-	}}})
+	}
+	if art.Status != domain.ArticleStatusUnknow {
+		set["status"] = art.Status
+	}
+
+	updateRes, err := m.produceCol.UpdateOne(ctx, updateFilter, bson.D{
+		{"$set", set},
+		{"$inc", bson.M{"version": 1}},
+	})
+	if err != nil {
+		return err
+	}
+
 	if updateRes.ModifiedCount <= 0 {
+		// 帖子属于该作者说明版本号已过期
+		cnt, err := m.produceCol.CountDocuments(ctx, bson.M{"id": art.Id, "author_id": art.AuthorId})
+		if err == nil && cnt > 0 {
+			return ErrVersionConflict
+		}
 		return ErrUserMismatch
 	}
-	return err
+
+	return nil
 }
 
 func (m *MongoDBArticleDAO) Sync(ctx context.Context, art Article) (int64, error) {
@@ -86,31 +112,122 @@ func (m *MongoDBArticleDAO) Sync(ctx context.Context, art Article) (int64, error
 		return art.Id, err
 	}
 
-	/*操作库*/
-	now := time.Now().UnixMilli()
-	art.Utime = now
-	filter := bson.D{
-		{"id", art.Id},
-		{"author_id", art.AuthorId},
+	// 线上库遵循UPSERT语义
+	return art.Id, m.upsertPub(ctx, PublishedArticle(art))
+}
+
+func (m *MongoDBArticleDAO) SyncStatus(ctx context.Context, artId int64, authorId int64, status uint8) error {
+	err := m.updateStatus(ctx, artId, authorId, status)
+	if err != nil {
+		return err
 	}
 
-	set := bson.D{
-		{"$set", PublishedArticle(art)},
-		{"$setOnInsert", bson.D{
-			{"ctime", now},
-		}},
+	return m.updatePubStatus(ctx, artId, status)
+}
+
+// @func: GetByAuthor
+// @date: 2024-01-26 10:30:20
+// @brief: mongodb-查询创作者创作列表, 最新修改的排在前面
+// @author: Kewin Li
+// @receiver m
+// @param ctx
+// @param userId
+// @param offset
+// @param limit
+// @return []Article
+// @return error
+func (m *MongoDBArticleDAO) GetByAuthor(ctx context.Context, userId int64, offset int, limit int) ([]Article, error) {
+	cursor, err := m.produceCol.Find(ctx, bson.M{"author_id": userId},
+		options.Find().
+			SetSort(bson.D{{"utime", -1}}).
+			SetSkip(int64(offset)).
+			SetLimit(int64(limit)))
+	if err != nil {
+		return nil, err
 	}
 
-	_, err = m.liveCol.UpdateOne(ctx, filter, set,
-		options.Update().SetUpsert(true))
+	var arts []Article
+	err = cursor.All(ctx, &arts)
+	return arts, err
+}
 
-	// 线上库遵循啊UPSERT语义
-	return art.Id, err
+// @func: GetById
+// @date: 2024-01-26 10:31:42
+// @brief: mongodb-查询制作库帖子
+// @author: Kewin Li
+// @receiver m
+// @param ctx
+// @param artId
+// @return Article
+// @return error 不存在时返回ErrRecordNotFound, 与gorm实现保持一致
+func (m *MongoDBArticleDAO) GetById(ctx context.Context, artId int64) (Article, error) {
+	var art Article
+	err := m.produceCol.FindOne(ctx, bson.M{"id": artId}).Decode(&art)
+	if err == mongo.ErrNoDocuments {
+		return Article{}, ErrRecordNotFound
+	}
+
+	return art, err
 }
 
-func (m *MongoDBArticleDAO) SyncStatus(ctx context.Context, artId int64, authorId int64, status uint8) error {
+// @func: GetPubById
+// @date: 2024-01-26 10:32:58
+// @brief: mongodb-查询线上库帖子
+// @author: Kewin Li
+// @receiver m
+// @param ctx
+// @param artId
+// @return PublishedArticle
+// @return error 不存在时返回ErrRecordNotFound, 与gorm实现保持一致
+func (m *MongoDBArticleDAO) GetPubById(ctx context.Context, artId int64) (PublishedArticle, error) {
+	var art PublishedArticle
+	err := m.liveCol.FindOne(ctx, bson.M{"id": artId}).Decode(&art)
+	if err == mongo.ErrNoDocuments {
+		return PublishedArticle{}, ErrRecordNotFound
+	}
 
-	now := time.Now().UnixMilli()
+	return art, err
+}
+
+// @func: ListPub
+// @date: 2024-01-26 10:34:16
+// @brief: mongodb-分批查询已发表的帖子
+// @author: Kewin Li
+// @receiver m
+// @param ctx
+// @param start
+// @param offset
+// @param limit
+// @return []PublishedArticle
+// @return error
+func (m *MongoDBArticleDAO) ListPub(ctx context.Context, start time.Time, offset int, limit int) ([]PublishedArticle, error) {
+	cursor, err := m.liveCol.Find(ctx, bson.M{
+		"utime":  bson.M{"$lt": start.UnixMilli()},
+		"status": domain.ArticleStatusPublished,
+	}, options.Find().
+		SetSort(bson.D{{"id", 1}}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit)))
+	if err != nil {
+		return nil, err
+	}
+
+	var arts []PublishedArticle
+	err = cursor.All(ctx, &arts)
+	return arts, err
+}
+
+// @func: updateStatus
+// @date: 2024-01-26 10:36:40
+// @brief: mongodb-修改制作库帖子状态
+// @author: Kewin Li
+// @receiver m
+// @param ctx
+// @param artId
+// @param authorId
+// @param status
+// @return error 帖子ID和作者ID不匹配时返回ErrUserMismatch
+func (m *MongoDBArticleDAO) updateStatus(ctx context.Context, artId int64, authorId int64, status uint8) error {
 	filter := bson.M{
 		"id":        artId,
 		"author_id": authorId,
@@ -118,42 +235,132 @@ func (m *MongoDBArticleDAO) SyncStatus(ctx context.Context, artId int64, authorI
 
 	updateRes, err := m.produceCol.UpdateOne(ctx, filter, bson.D{{"$set", bson.M{
 		"status": status,
-		"utime":  now,
+		"utime":  time.Now().UnixMilli(),
 	}}})
-
 	if err != nil {
 		return err
 	}
 
-	if updateRes.ModifiedCount <= 0 {
+	if updateRes.MatchedCount <= 0 {
 		return ErrUserMismatch
 	}
 
-	_, err = m.liveCol.UpdateOne(ctx, filter, bson.D{{"$set", bson.M{
+	return nil
+}
+
+// @func: upsertPub
+// @date: 2024-01-26 10:38:02
+// @brief: mongodb-线上库帖子不存在时新建, 存在时更新
+// @author: Kewin Li
+// @receiver m
+// @param ctx
+// @param art
+// @return error
+func (m *MongoDBArticleDAO) upsertPub(ctx context.Context, art PublishedArticle) error {
+	now := time.Now().UnixMilli()
+	art.Utime = now
+	// ctime只在新建时写入
+	art.Ctime = 0
+	filter := bson.D{
+		{"id", art.Id},
+		{"author_id", art.AuthorId},
+	}
+
+	set := bson.D{
+		{"$set", art},
+		{"$setOnInsert", bson.D{
+			{"ctime", now},
+		}},
+	}
+
+	_, err := m.liveCol.UpdateOne(ctx, filter, set,
+		options.Update().SetUpsert(true))
+	return err
+}
+
+// @func: updatePubStatus
+// @date: 2024-01-26 10:39:24
+// @brief: mongodb-修改线上库帖子状态, 帖子未发表过时不做处理
+// @author: Kewin Li
+// @receiver m
+// @param ctx
+// @param artId
+// @param status
+// @return error
+func (m *MongoDBArticleDAO) updatePubStatus(ctx context.Context, artId int64, status uint8) error {
+	_, err := m.liveCol.UpdateOne(ctx, bson.M{"id": artId}, bson.D{{"$set", bson.M{
 		"status": status,
-		"utime":  now,
+		"utime":  time.Now().UnixMilli(),
 	}}})
 
-	// 线上库遵循啊UPSERT语义
 	return err
 }
 
-func (m *MongoDBArticleDAO) GetByAuthor(ctx context.Context, userId int64, offset int, limit int) ([]Article, error) {
-	//TODO implement me
-	panic("implement me")
+// MongoDBArticleAuthorDao
+// @Description: mongodb制作库, 用于作者侧与线上库分别选择存储
+type MongoDBArticleAuthorDao struct {
+	dao *MongoDBArticleDAO
 }
 
-func (m *MongoDBArticleDAO) GetById(ctx context.Context, artId int64) (Article, error) {
-	//TODO implement me
-	panic("implement me")
+func NewMongoDBArticleAuthorDao(mdb *mongo.Database, node *snowflake.Node) ArticleAuthorDao {
+	return &MongoDBArticleAuthorDao{
+		dao: &MongoDBArticleDAO{
+			node:       node,
+			produceCol: mdb.Collection("articles"),
+		},
+	}
 }
 
-func (m *MongoDBArticleDAO) GetPubById(ctx context.Context, artId int64) (PublishedArticle, error) {
-	//TODO implement me
-	panic("implement me")
+func (m *MongoDBArticleAuthorDao) Create(ctx context.Context, art Article) (int64, error) {
+	return m.dao.Insert(ctx, art)
 }
 
-func (m *MongoDBArticleDAO) ListPub(ctx context.Context, start time.Time, offset int, limit int) ([]PublishedArticle, error) {
-	//TODO implement me
-	panic("implement me")
+func (m *MongoDBArticleAuthorDao) Update(ctx context.Context, art Article) error {
+	return m.dao.UpdateById(ctx, art)
+}
+
+func (m *MongoDBArticleAuthorDao) UpdateStatus(ctx context.Context, artId int64, authorId int64, status uint8) error {
+	return m.dao.updateStatus(ctx, artId, authorId, status)
+}
+
+func (m *MongoDBArticleAuthorDao) GetByAuthor(ctx context.Context, userId int64, offset int, limit int) ([]Article, error) {
+	return m.dao.GetByAuthor(ctx, userId, offset, limit)
+}
+
+func (m *MongoDBArticleAuthorDao) GetById(ctx context.Context, artId int64) (Article, error) {
+	return m.dao.GetById(ctx, artId)
+}
+
+// MongoDBArticleReaderDao
+// @Description: mongodb线上库, 用于作者侧与线上库分别选择存储
+type MongoDBArticleReaderDao struct {
+	dao *MongoDBArticleDAO
+}
+
+func NewMongoDBArticleReaderDao(mdb *mongo.Database) ArticleReaderDao {
+	return &MongoDBArticleReaderDao{
+		dao: &MongoDBArticleDAO{
+			liveCol: mdb.Collection("published_articles"),
+		},
+	}
+}
+
+func (m *MongoDBArticleReaderDao) Upsert(ctx context.Context, art Article) error {
+	return m.dao.upsertPub(ctx, PublishedArticle(art))
+}
+
+func (m *MongoDBArticleReaderDao) UpsertV2(ctx context.Context, art PublishedArticle) error {
+	return m.dao.upsertPub(ctx, art)
+}
+
+func (m *MongoDBArticleReaderDao) UpdateStatus(ctx context.Context, artId int64, status uint8) error {
+	return m.dao.updatePubStatus(ctx, artId, status)
+}
+
+func (m *MongoDBArticleReaderDao) GetPubById(ctx context.Context, artId int64) (PublishedArticle, error) {
+	return m.dao.GetPubById(ctx, artId)
+}
+
+func (m *MongoDBArticleReaderDao) ListPub(ctx context.Context, start time.Time, offset int, limit int) ([]PublishedArticle, error) {
+	return m.dao.ListPub(ctx, start, offset, limit)
 }
@@ -3,11 +3,18 @@ package dao
 import (
 	"context"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
 )
 
+// ArticleReaderDao
+// @Description: 线上库, 读者侧的读写
 type ArticleReaderDao interface {
 	Upsert(crx context.Context, art Article) error
 	UpsertV2(crx context.Context, art PublishedArticle) error
+	UpdateStatus(ctx context.Context, artId int64, status uint8) error
+	GetPubById(ctx context.Context, artId int64) (PublishedArticle, error)
+	ListPub(ctx context.Context, start time.Time, offset int, limit int) ([]PublishedArticle, error)
 }
 
 type GormArticleReaderDao struct {
@@ -21,11 +28,69 @@ func NewGormArticleReaderDao(db *gorm.DB) *GormArticleReaderDao {
 }
 
 func (g *GormArticleReaderDao) Upsert(crx context.Context, art Article) error {
-	//TODO implement me
-	panic("implement me")
+	return g.UpsertV2(crx, PublishedArticle(art))
 }
 
+// @func: UpsertV2
+// @date: 2024-01-26 10:15:40
+// @brief: 线上库-帖子不存在时新建, 存在时更新
+// @author: Kewin Li
+// @receiver g
+// @param crx
+// @param art
+// @return error
 func (g *GormArticleReaderDao) UpsertV2(crx context.Context, art PublishedArticle) error {
-	//TODO implement me
-	panic("implement me")
+	now := time.Now().UnixMilli()
+	art.Utime = now
+	art.Ctime = now
+
+	// id冲突时处理
+	return g.db.WithContext(crx).Clauses(clause.OnConflict{
+		// MYSQL:
+		// INSERT xxx DUPLICATE KEY SET `title` = ?
+
+		//其他方言:
+		//sqlite: INSERT xxx ON CONFLICT DO UPDATES WHERE xxx
+
+		// Columns兼容其他方言
+		Columns: []clause.Column{{Name: "id"}},
+		// TODO: Mysql仅支持该字段, 其他不生效
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"title":    art.Title,
+			"content":  art.Content,
+			"category": art.Category,
+			"status":   art.Status,
+			"utime":    art.Utime,
+		}),
+	}).Create(&art).Error
+}
+
+// @func: UpdateStatus
+// @date: 2024-01-26 10:17:52
+// @brief: 线上库-修改帖子状态, 帖子未发表过时不做处理
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param artId
+// @param status
+// @return error
+func (g *GormArticleReaderDao) UpdateStatus(ctx context.Context, artId int64, status uint8) error {
+	return g.db.WithContext(ctx).Model(&PublishedArticle{}).
+		Where("id = ?", artId).
+		Updates(map[string]any{
+			"status": status,
+			"utime":  time.Now().UnixMilli(),
+		}).Error
+}
+
+func (g *GormArticleReaderDao) GetPubById(ctx context.Context, artId int64) (PublishedArticle, error) {
+	return g.dao().GetPubById(ctx, artId)
+}
+
+func (g *GormArticleReaderDao) ListPub(ctx context.Context, start time.Time, offset int, limit int) ([]PublishedArticle, error) {
+	return g.dao().ListPub(ctx, start, offset, limit)
+}
+
+func (g *GormArticleReaderDao) dao() *GormArticleDao {
+	return &GormArticleDao{db: g.db}
 }
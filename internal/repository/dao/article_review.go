@@ -0,0 +1,209 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"time"
+)
+
+var ErrReviewResolved = errors.New("审核记录不存在或已处理")
+
+// 审核状态, 与domain层保持一致
+const (
+	reviewStatusPending uint8 = iota + 1
+	reviewStatusApproved
+	reviewStatusRejected
+	reviewStatusCanceled
+)
+
+type ArticleReviewDao interface {
+	Insert(ctx context.Context, r ArticleReview) (ArticleReview, error)
+	FindById(ctx context.Context, id int64) (ArticleReview, error)
+	FindLatest(ctx context.Context, artId int64, authorId int64) (ArticleReview, error)
+	ListPending(ctx context.Context, offset int, limit int) ([]ArticleReview, error)
+	Resolve(ctx context.Context, id int64, status uint8, reviewerId int64, reasons string) error
+	RevertApproval(ctx context.Context, id int64, status uint8) error
+}
+
+// GormArticleReviewDao
+// @Description: 帖子审核记录, 与帖子存储方案无关, 统一存放在MySQL
+type GormArticleReviewDao struct {
+	db *gorm.DB
+}
+
+func NewGormArticleReviewDao(db *gorm.DB) ArticleReviewDao {
+	return &GormArticleReviewDao{
+		db: db,
+	}
+}
+
+// @func: Insert
+// @date: 2024-01-25 12:10:16
+// @brief: 帖子审核-新增审核记录, 该帖子之前等待人工审核的记录一并失效
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param r
+// @return ArticleReview
+// @return error
+func (g *GormArticleReviewDao) Insert(ctx context.Context, r ArticleReview) (ArticleReview, error) {
+	now := time.Now().UnixMilli()
+	r.Ctime = now
+	r.Utime = now
+
+	err := g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&ArticleReview{}).
+			Where("art_id = ? AND status = ?", r.ArtId, reviewStatusPending).
+			Updates(map[string]any{
+				"status": reviewStatusCanceled,
+				"utime":  now,
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(&r).Error
+	})
+
+	return r, err
+}
+
+// @func: FindById
+// @date: 2024-01-25 12:12:40
+// @brief: 帖子审核-按ID查询
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param id
+// @return ArticleReview
+// @return error 不存在时返回ErrRecordNotFound
+func (g *GormArticleReviewDao) FindById(ctx context.Context, id int64) (ArticleReview, error) {
+	var r ArticleReview
+	err := g.db.WithContext(ctx).Where("id = ?", id).First(&r).Error
+
+	return r, err
+}
+
+// @func: FindLatest
+// @date: 2024-01-25 12:14:06
+// @brief: 帖子审核-查询作者某帖子最近一次审核
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param artId
+// @param authorId
+// @return ArticleReview
+// @return error 不存在时返回ErrRecordNotFound
+func (g *GormArticleReviewDao) FindLatest(ctx context.Context, artId int64, authorId int64) (ArticleReview, error) {
+	var r ArticleReview
+	err := g.db.WithContext(ctx).
+		Where("art_id = ? AND author_id = ?", artId, authorId).
+		Order("id DESC").
+		First(&r).Error
+
+	return r, err
+}
+
+// @func: ListPending
+// @date: 2024-01-25 12:15:32
+// @brief: 帖子审核-分页查询等待人工审核的记录, 先提交的在前
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param offset
+// @param limit
+// @return []ArticleReview
+// @return error
+func (g *GormArticleReviewDao) ListPending(ctx context.Context, offset int, limit int) ([]ArticleReview, error) {
+	var res []ArticleReview
+	err := g.db.WithContext(ctx).
+		Where("status = ?", reviewStatusPending).
+		Order("id").
+		Offset(offset).
+		Limit(limit).
+		Find(&res).Error
+
+	return res, err
+}
+
+// @func: Resolve
+// @date: 2024-01-25 12:17:48
+// @brief: 帖子审核-处理等待人工审核的记录, 已被处理或已失效的返回ErrReviewResolved, 防止多个审核员重复处理
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param id
+// @param status
+// @param reviewerId
+// @param reasons
+// @return error
+func (g *GormArticleReviewDao) Resolve(ctx context.Context, id int64, status uint8, reviewerId int64, reasons string) error {
+	res := g.db.WithContext(ctx).Model(&ArticleReview{}).
+		Where("id = ? AND status = ?", id, reviewStatusPending).
+		Updates(map[string]any{
+			"status":      status,
+			"reviewer_id": reviewerId,
+			"reasons":     reasons,
+			"utime":       time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected <= 0 {
+		return ErrReviewResolved
+	}
+
+	return nil
+}
+
+// @func: RevertApproval
+// @date: 2024-01-28 18:58:26
+// @brief: 帖子审核-撤销已通过的审核, 只修改仍为已通过的记录
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param id
+// @param status 改回待处理时清空审核员
+// @return error 审核不是已通过状态时返回ErrReviewResolved
+func (g *GormArticleReviewDao) RevertApproval(ctx context.Context, id int64, status uint8) error {
+	updates := map[string]any{
+		"status": status,
+		"utime":  time.Now().UnixMilli(),
+	}
+	if status == reviewStatusPending {
+		updates["reviewer_id"] = 0
+	}
+
+	res := g.db.WithContext(ctx).Model(&ArticleReview{}).
+		Where("id = ? AND status = ?", id, reviewStatusApproved).
+		Updates(updates)
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected <= 0 {
+		return ErrReviewResolved
+	}
+
+	return nil
+}
+
+// ArticleReview
+// @Description: 帖子审核记录表
+type ArticleReview struct {
+	Id       int64 `gorm:"primaryKey, autoIncrement"`
+	ArtId    int64 `gorm:"index:art_author,priority:1"`
+	AuthorId int64 `gorm:"index:art_author,priority:2"`
+	// 提交审核时制作库的版本号
+	Version int64
+	Title   string `gorm:"type:varchar(256)"`
+	Content string `gorm:"type:BLOB"`
+	Status  uint8  `gorm:"index"`
+	// 原因, 以JSON数组保存
+	Reasons    string `gorm:"type:TEXT"`
+	ReviewerId int64
+	Ctime      int64
+	Utime      int64
+}
@@ -0,0 +1,117 @@
+package dao
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type ArticleRevisionDao interface {
+	Insert(ctx context.Context, rev ArticleRevision) (ArticleRevision, error)
+	FindByArtId(ctx context.Context, artId int64, authorId int64, offset int, limit int) ([]ArticleRevision, error)
+	FindByVersion(ctx context.Context, artId int64, authorId int64, version int) (ArticleRevision, error)
+}
+
+// GormArticleRevisionDao
+// @Description: 帖子历史版本, 只插入不修改
+type GormArticleRevisionDao struct {
+	db *gorm.DB
+}
+
+func NewGormArticleRevisionDao(db *gorm.DB) ArticleRevisionDao {
+	return &GormArticleRevisionDao{
+		db: db,
+	}
+}
+
+// @func: Insert
+// @date: 2024-01-22 10:35:12
+// @brief: 帖子历史版本-新增, 版本号在帖子已有的最大版本号上加1
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param rev
+// @return ArticleRevision 带有ID、版本号
+// @return error
+func (g *GormArticleRevisionDao) Insert(ctx context.Context, rev ArticleRevision) (ArticleRevision, error) {
+	rev.Ctime = time.Now().UnixMilli()
+
+	err := g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 锁住该帖子的最新版本, 并发保存时串行分配版本号
+		var last ArticleRevision
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("art_id = ?", rev.ArtId).
+			Order("version DESC").
+			Limit(1).
+			Find(&last).Error
+		if err != nil {
+			return err
+		}
+
+		rev.Version = last.Version + 1
+		return tx.Create(&rev).Error
+	})
+
+	return rev, err
+}
+
+// @func: FindByArtId
+// @date: 2024-01-22 10:38:40
+// @brief: 帖子历史版本-分页查询作者某帖子的版本, 新版本在前
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param artId
+// @param authorId
+// @param offset
+// @param limit
+// @return []ArticleRevision
+// @return error
+func (g *GormArticleRevisionDao) FindByArtId(ctx context.Context, artId int64, authorId int64, offset int, limit int) ([]ArticleRevision, error) {
+	var revs []ArticleRevision
+	err := g.db.WithContext(ctx).
+		Where("art_id = ? AND author_id = ?", artId, authorId).
+		Order("version DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&revs).Error
+
+	return revs, err
+}
+
+// @func: FindByVersion
+// @date: 2024-01-22 10:40:05
+// @brief: 帖子历史版本-查询作者某帖子的指定版本
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param artId
+// @param authorId
+// @param version
+// @return ArticleRevision
+// @return error 不存在时返回ErrRecordNotFound
+func (g *GormArticleRevisionDao) FindByVersion(ctx context.Context, artId int64, authorId int64, version int) (ArticleRevision, error) {
+	var rev ArticleRevision
+	err := g.db.WithContext(ctx).
+		Where("art_id = ? AND author_id = ? AND version = ?", artId, authorId, version).
+		First(&rev).Error
+
+	return rev, err
+}
+
+// ArticleRevision
+// @Description: 帖子历史版本表
+type ArticleRevision struct {
+	Id       int64 `gorm:"primaryKey, autoIncrement"`
+	ArtId    int64 `gorm:"uniqueIndex:art_version"`
+	Version  int   `gorm:"uniqueIndex:art_version"`
+	AuthorId int64 `gorm:"index"`
+	Kind     uint8
+	Title    string `gorm:"type:varchar(256)"`
+	Content  string `gorm:"type:BLOB"`
+	Category string `gorm:"type:varchar(64)"`
+	// 标签, 以JSON数组保存
+	Tags  string `gorm:"type:varchar(512)"`
+	Ctime int64
+}
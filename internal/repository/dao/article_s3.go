@@ -5,169 +5,172 @@ package dao
 import (
 	"bytes"
 	"context"
+	"errors"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/s3"
-	"github.com/ecodeclub/ekit"
-	"github.com/gin-gonic/gin"
+	"golang.org/x/sync/errgroup"
 	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
+	"io"
+	"kitbook/internal/domain"
 	"strconv"
 	"time"
 )
 
-type S3ArticleDAO struct {
-	GormArticleDao
-	oss *s3.S3
+// 列表查询时并发读取内容的最大请求数
+const s3ListConcurrency = 8
+
+// S3ArticleReaderDao
+// @Description: 线上库-S3实现, 帖子内容存对象存储, 其余字段仍存MySQL线上表(内容列留空), 标签等联表查询不受影响
+type S3ArticleReaderDao struct {
+	GormArticleReaderDao
+	oss    *s3.S3
+	bucket string
 }
 
-func NewS3ArticleDAO(db *gorm.DB, oss *s3.S3) *S3ArticleDAO {
-	return &S3ArticleDAO{
-		GormArticleDao: GormArticleDao{db},
-		oss:            oss,
+func NewS3ArticleReaderDao(db *gorm.DB, oss *s3.S3, bucket string) ArticleReaderDao {
+	return &S3ArticleReaderDao{
+		GormArticleReaderDao: GormArticleReaderDao{db: db},
+		oss:                  oss,
+		bucket:               bucket,
 	}
 }
 
-// @func: Sync
-// @date: 2023-12-03 22:29:54
-// @brief: 帖子发表-数据同步-S3实现
+func (s *S3ArticleReaderDao) Upsert(crx context.Context, art Article) error {
+	return s.UpsertV2(crx, PublishedArticle(art))
+}
+
+// @func: UpsertV2
+// @date: 2024-01-26 10:50:16
+// @brief: 线上库-S3实现, 先写内容再写元数据, 读者不会查到没有内容的帖子
 // @author: Kewin Li
 // @receiver s
-// @param ctx
+// @param crx
 // @param art
-// @return int64
 // @return error
-func (s *S3ArticleDAO) Sync(ctx context.Context, art Article) (int64, error) {
-	// Gorm闭包开启事务
-	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-
-		var err error
-		dao := NewGormArticleDao(tx)
-
-		if art.Id > 0 {
-			// id存在视为更新
-			err = dao.UpdateById(ctx, art)
-
-		} else {
-			// id不存在视为新建
-			art.Id, err = dao.Insert(ctx, art)
-
-		}
-		if err != nil {
-			return err
-		}
-
-		now := time.Now().UnixMilli()
-		publishArt := PublishedArticleV2{
-			Id:       art.Id,
-			Title:    art.Title,
-			AuthorId: art.AuthorId,
-			Ctime:    now,
-			Utime:    now,
-			Status:   art.Status,
-		}
-
-		// 操作线上表
-		// id冲突时处理
-		// TODO: 该函数的作用是?
-		err = tx.Clauses(clause.OnConflict{
-			// MYSQL:
-			// INSERT xxx DUPLICATE KEY SET `title` = ?
-
-			//其他方言:
-			//sqlite: INSERT xxx ON CONFLICT DO UPDATES WHERE xxx
-
-			// Columns兼容其他方言
-			Columns: []clause.Column{{Name: "id"}},
-			// TODO: Mysql仅支持该字段, 其他不生效
-			DoUpdates: clause.Assignments(map[string]interface{}{
-				"title":  publishArt.Title,
-				"status": publishArt.Status,
-				"utime":  publishArt.Utime,
-			}),
-		}).Create(&publishArt).Error
-
-		return err
+func (s *S3ArticleReaderDao) UpsertV2(crx context.Context, art PublishedArticle) error {
+	_, err := s.oss.PutObjectWithContext(crx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(s.key(art.Id)),
+		Body:        bytes.NewReader([]byte(art.Content)),
+		ContentType: aws.String("text/plain;charset=utf-8"),
 	})
-
 	if err != nil {
-		return art.Id, err
+		return err
 	}
 
-	_, err = s.oss.PutObjectWithContext(ctx, &s3.PutObjectInput{
-		Bucket:      ekit.ToPtr[string]("kitbook-1314583317"),
-		Key:         ekit.ToPtr[string](strconv.FormatInt(art.Id, 10)),
-		Body:        bytes.NewReader([]byte(art.Content)),
-		ContentType: ekit.ToPtr[string]("text/plain;charset=utf-8"),
-	})
-
-	return art.Id, err
+	art.Content = ""
+	return s.GormArticleReaderDao.UpsertV2(crx, art)
 }
 
-// @func: SyncStatus
-// @date: 2023-12-03 22:42:30
-// @brief: 帖子状态同步-S3实现
+// @func: UpdateStatus
+// @date: 2024-01-26 10:52:40
+// @brief: 线上库-S3实现, 设为仅自己可见时删除对象存储中的内容, 再次发表时重新写入
 // @author: Kewin Li
 // @receiver s
 // @param ctx
 // @param artId
-// @param authorId
 // @param status
 // @return error
-// TODO: 部署网站进行验证
-func (s *S3ArticleDAO) SyncStatus(ctx *gin.Context, artId int64, authorId int64, status uint8) error {
-	now := time.Now().UnixMilli()
-	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		// 1. 修改制作库
-		res := tx.Model(&Article{}).
-			Where("id = ?", artId).
-			Where("author_id = ?", authorId).
-			Updates(map[string]any{
-				"status": status,
-				"utime":  now,
-			})
-
-		if res.Error != nil {
-			return res.Error
-		}
-
-		// 更新无效，说明帖子ID和作者ID不匹配
-		if res.RowsAffected <= 0 {
-			return ErrUserMismatch
-		}
-
-		// 2. 修改线上库
-		return tx.Model(&PublishedArticleV2{}).
-			Where("id = ?", artId).
-			Updates(map[string]any{
-				"status": status,
-				"utime":  now,
-			}).Error
+func (s *S3ArticleReaderDao) UpdateStatus(ctx context.Context, artId int64, status uint8) error {
+	err := s.GormArticleReaderDao.UpdateStatus(ctx, artId, status)
+	if err != nil {
+		return err
+	}
+
+	if status != domain.ArticleStatusPrivate {
+		return nil
+	}
+
+	_, err = s.oss.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key(artId)),
 	})
+	return err
+}
 
+// @func: GetPubById
+// @date: 2024-01-26 10:54:06
+// @brief: 线上库-S3实现, 元数据和内容分别读取
+// @author: Kewin Li
+// @receiver s
+// @param ctx
+// @param artId
+// @return PublishedArticle
+// @return error
+func (s *S3ArticleReaderDao) GetPubById(ctx context.Context, artId int64) (PublishedArticle, error) {
+	art, err := s.GormArticleReaderDao.GetPubById(ctx, artId)
 	if err != nil {
-		return err
+		return PublishedArticle{}, err
+	}
+
+	art.Content, err = s.content(ctx, artId)
+	return art, err
+}
+
+// @func: ListPub
+// @date: 2024-01-26 10:55:30
+// @brief: 线上库-S3实现, 元数据一次查出, 内容并发读取, 任一篇读取失败整体失败
+// @author: Kewin Li
+// @receiver s
+// @param ctx
+// @param start
+// @param offset
+// @param limit
+// @return []PublishedArticle
+// @return error
+func (s *S3ArticleReaderDao) ListPub(ctx context.Context, start time.Time, offset int, limit int) ([]PublishedArticle, error) {
+	arts, err := s.GormArticleReaderDao.ListPub(ctx, start, offset, limit)
+	if err != nil {
+		return nil, err
 	}
 
-	const ArticleStatusPrivate = 3
-	if status == ArticleStatusPrivate {
-		_, err = s.oss.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
-			Bucket: ekit.ToPtr[string]("kitbook-1314583317"),
-			Key:    ekit.ToPtr[string](strconv.FormatInt(artId, 10)),
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.SetLimit(s3ListConcurrency)
+	for i := range arts {
+		i := i
+		eg.Go(func() error {
+			content, err2 := s.content(egCtx, arts[i].Id)
+			arts[i].Content = content
+			return err2
 		})
+	}
+
+	err = eg.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	return arts, nil
+}
 
+// @func: content
+// @date: 2024-01-26 10:57:12
+// @brief: 线上库-S3实现, 读取帖子内容
+// @author: Kewin Li
+// @receiver s
+// @param ctx
+// @param artId
+// @return string
+// @return error 内容已删除(仅自己可见)时返回空内容
+func (s *S3ArticleReaderDao) content(ctx context.Context, artId int64) (string, error) {
+	out, err := s.oss.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key(artId)),
+	})
+	var aerr awserr.Error
+	if errors.As(err, &aerr) && aerr.Code() == s3.ErrCodeNoSuchKey {
+		return "", nil
 	}
+	if err != nil {
+		return "", err
+	}
+	defer out.Body.Close()
 
-	return err
+	data, err := io.ReadAll(out.Body)
+	return string(data), err
 }
 
-// PublishedArticleV2
-// @Description: OSS方案
-type PublishedArticleV2 struct {
-	Id    int64  `gorm:"primaryKey, autoIncrement" bson:"id,omitempty"`
-	Title string `gorm:"type=varchar(256)" bson:"title,omitempty"`
-	// 内容不存储
-	//Content  string `gorm:"type=BLOB" bson:"content,omitempty"`
-	AuthorId int64 `gorm:"index" bson:"author_id,omitempty"`
-	Status   uint8 `bson:"status,omitempty"`
-	Ctime    int64 `bson:"ctime,omitempty"`
-	Utime    int64 `bson:"utime,omitempty"`
+func (s *S3ArticleReaderDao) key(artId int64) string {
+	return "articles/" + strconv.FormatInt(artId, 10)
 }
@@ -0,0 +1,132 @@
+// Package dao
+// @Description: 单元测试-S3线上库
+package dao
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// @func: TestS3ArticleReaderDao_ListPub
+// @date: 2024-01-28 17:50:24
+// @brief: 单元测试-列表并发读取内容, 内容按帖子ID对应, 内容已删除时为空, 任一篇读取失败整体失败
+// @author: Kewin Li
+// @param t
+func TestS3ArticleReaderDao_ListPub(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		// 对象存储中的内容, 键为对象路径
+		objects map[string]string
+		// 对象存储返回服务端错误的对象路径
+		failKey string
+
+		wantArts []PublishedArticle
+		wantErr  bool
+	}{
+		{
+			name: "读取成功",
+			objects: map[string]string{
+				"/articles/articles/1": "内容1",
+				"/articles/articles/2": "内容2",
+				"/articles/articles/3": "内容3",
+			},
+			wantArts: []PublishedArticle{
+				{Id: 1, Title: "标题1", Content: "内容1"},
+				{Id: 2, Title: "标题2", Content: "内容2"},
+				{Id: 3, Title: "标题3", Content: "内容3"},
+			},
+		},
+		{
+			name: "部分内容已删除, 内容为空",
+			objects: map[string]string{
+				"/articles/articles/1": "内容1",
+				"/articles/articles/3": "内容3",
+			},
+			wantArts: []PublishedArticle{
+				{Id: 1, Title: "标题1", Content: "内容1"},
+				{Id: 2, Title: "标题2"},
+				{Id: 3, Title: "标题3", Content: "内容3"},
+			},
+		},
+		{
+			name: "读取失败",
+			objects: map[string]string{
+				"/articles/articles/1": "内容1",
+				"/articles/articles/3": "内容3",
+			},
+			failKey: "/articles/articles/2",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == tc.failKey {
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				content, ok := tc.objects[r.URL.Path]
+				if !ok {
+					w.Header().Set("Content-Type", "application/xml")
+					w.WriteHeader(http.StatusNotFound)
+					_, _ = w.Write([]byte(`<Error><Code>NoSuchKey</Code><Message>not found</Message></Error>`))
+					return
+				}
+				_, _ = w.Write([]byte(content))
+			}))
+			defer server.Close()
+
+			sess, err := session.NewSession(&aws.Config{
+				Credentials:      credentials.NewStaticCredentials("id", "secret", ""),
+				Region:           aws.String("us-east-1"),
+				Endpoint:         aws.String(server.URL),
+				S3ForcePathStyle: aws.Bool(true),
+				MaxRetries:       aws.Int(0),
+			})
+			require.NoError(t, err)
+
+			sqlDB, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer sqlDB.Close()
+			db, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlDB,
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{
+				SkipDefaultTransaction: true,
+				DisableAutomaticPing:   true,
+			})
+			require.NoError(t, err)
+
+			mock.ExpectQuery("SELECT \\* FROM `published_articles`").
+				WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg()).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).
+					AddRow(1, "标题1").
+					AddRow(2, "标题2").
+					AddRow(3, "标题3"))
+
+			readerDao := NewS3ArticleReaderDao(db, s3.New(sess), "articles")
+			arts, err := readerDao.ListPub(context.Background(), time.Now(), 0, 10)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantArts, arts)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+var ErrScheduleMismatch = errors.New("定时发表计划不存在或已执行")
+
+// 定时发表状态, 与domain层保持一致
+const (
+	scheduleStatusWaiting uint8 = iota
+	scheduleStatusPublishing
+	scheduleStatusDone
+	scheduleStatusFailed
+	scheduleStatusCanceled
+)
+
+type ArticleScheduleDao interface {
+	Upsert(ctx context.Context, s ArticleSchedule) error
+	Cancel(ctx context.Context, artId int64, authorId int64) error
+	FindByArtId(ctx context.Context, artId int64, authorId int64) (ArticleSchedule, error)
+	FindDue(ctx context.Context, now int64, stuckBefore int64, limit int) ([]ArticleSchedule, error)
+	Claim(ctx context.Context, s ArticleSchedule) (bool, error)
+	Finish(ctx context.Context, id int64, status uint8, errMsg string) error
+}
+
+// GormArticleScheduleDao
+// @Description: 帖子定时发表计划, 与帖子存储方案无关, 统一存放在MySQL
+type GormArticleScheduleDao struct {
+	db *gorm.DB
+}
+
+func NewGormArticleScheduleDao(db *gorm.DB) ArticleScheduleDao {
+	return &GormArticleScheduleDao{
+		db: db,
+	}
+}
+
+// @func: Upsert
+// @date: 2024-01-22 15:20:36
+// @brief: 定时发表-新建或重新设置帖子的发表时间, 之前的计划无论状态如何都重置为等待
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param s
+// @return error
+func (g *GormArticleScheduleDao) Upsert(ctx context.Context, s ArticleSchedule) error {
+	now := time.Now().UnixMilli()
+	s.Status = scheduleStatusWaiting
+	s.Ctime = now
+	s.Utime = now
+
+	return g.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "art_id"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"author_id":    s.AuthorId,
+			"publish_time": s.PublishTime,
+			"status":       scheduleStatusWaiting,
+			"error":        "",
+			"utime":        now,
+		}),
+	}).Create(&s).Error
+}
+
+// @func: Cancel
+// @date: 2024-01-22 15:23:10
+// @brief: 定时发表-取消, 只能取消尚在等待中的计划
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param artId
+// @param authorId
+// @return error
+func (g *GormArticleScheduleDao) Cancel(ctx context.Context, artId int64, authorId int64) error {
+	res := g.db.WithContext(ctx).Model(&ArticleSchedule{}).
+		Where("art_id = ? AND author_id = ? AND status = ?", artId, authorId, scheduleStatusWaiting).
+		Updates(map[string]any{
+			"status": scheduleStatusCanceled,
+			"utime":  time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected <= 0 {
+		return ErrScheduleMismatch
+	}
+
+	return nil
+}
+
+// @func: FindByArtId
+// @date: 2024-01-22 15:25:42
+// @brief: 定时发表-查询作者某帖子的计划
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param artId
+// @param authorId
+// @return ArticleSchedule
+// @return error 不存在时返回ErrRecordNotFound
+func (g *GormArticleScheduleDao) FindByArtId(ctx context.Context, artId int64, authorId int64) (ArticleSchedule, error) {
+	var s ArticleSchedule
+	err := g.db.WithContext(ctx).
+		Where("art_id = ? AND author_id = ?", artId, authorId).
+		First(&s).Error
+
+	return s, err
+}
+
+// @func: FindDue
+// @date: 2024-01-22 15:28:16
+// @brief: 定时发表-查询到期的计划, 以及认领后长时间未完成(结点宕机)的计划
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param now
+// @param stuckBefore 认领时间早于该时间点视为认领结点已宕机
+// @param limit
+// @return []ArticleSchedule
+// @return error
+func (g *GormArticleScheduleDao) FindDue(ctx context.Context, now int64, stuckBefore int64, limit int) ([]ArticleSchedule, error) {
+	var res []ArticleSchedule
+	err := g.db.WithContext(ctx).
+		Where("(status = ? AND publish_time <= ?) OR (status = ? AND utime < ?)",
+			scheduleStatusWaiting, now, scheduleStatusPublishing, stuckBefore).
+		Order("publish_time").
+		Limit(limit).
+		Find(&res).Error
+
+	return res, err
+}
+
+// @func: Claim
+// @date: 2024-01-22 15:31:48
+// @brief: 定时发表-认领计划, 以查询到的状态、更新时间为版本号, 保证同一计划只有一个结点发表
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param s
+// @return bool 是否认领成功
+// @return error
+func (g *GormArticleScheduleDao) Claim(ctx context.Context, s ArticleSchedule) (bool, error) {
+	res := g.db.WithContext(ctx).Model(&ArticleSchedule{}).
+		Where("id = ? AND status = ? AND utime = ?", s.Id, s.Status, s.Utime).
+		Updates(map[string]any{
+			"status": scheduleStatusPublishing,
+			"utime":  time.Now().UnixMilli(),
+		})
+
+	return res.RowsAffected > 0, res.Error
+}
+
+// @func: Finish
+// @date: 2024-01-22 15:34:20
+// @brief: 定时发表-记录发表结果, 认领后被作者重新设置的计划不受影响
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param id
+// @param status
+// @param errMsg
+// @return error
+func (g *GormArticleScheduleDao) Finish(ctx context.Context, id int64, status uint8, errMsg string) error {
+	return g.db.WithContext(ctx).Model(&ArticleSchedule{}).
+		Where("id = ? AND status = ?", id, scheduleStatusPublishing).
+		Updates(map[string]any{
+			"status": status,
+			"error":  errMsg,
+			"utime":  time.Now().UnixMilli(),
+		}).Error
+}
+
+// ArticleSchedule
+// @Description: 帖子定时发表计划表
+type ArticleSchedule struct {
+	Id       int64 `gorm:"primaryKey, autoIncrement"`
+	ArtId    int64 `gorm:"uniqueIndex"`
+	AuthorId int64 `gorm:"index"`
+	// 预定的发表时间 毫秒
+	PublishTime int64  `gorm:"index:status_publish_time,priority:2"`
+	Status      uint8  `gorm:"index:status_publish_time,priority:1"`
+	Error       string `gorm:"type:varchar(1024)"`
+	Ctime       int64
+	Utime       int64
+}
@@ -0,0 +1,154 @@
+package dao
+
+import (
+	"context"
+	"kitbook/pkg/fulltext"
+	"sync"
+)
+
+// 帖子索引字段及权重
+const (
+	searchFieldTitle   = "title"
+	searchFieldAuthor  = "author"
+	searchFieldContent = "content"
+)
+
+type ArticleSearchDao interface {
+	Upsert(ctx context.Context, art SearchArticle) error
+	Delete(ctx context.Context, id int64) error
+	Search(ctx context.Context, query string, offset int, limit int) ([]SearchArticleHit, int, error)
+	Prune(ctx context.Context, utimeBefore int64, keep map[int64]struct{}) (int, error)
+}
+
+// MemoryArticleSearchDao
+// @Description: 帖子搜索-进程内索引, 每个结点各自维护, 重启后需从线上库重建
+type MemoryArticleSearchDao struct {
+	// 保证索引与文档内容一致
+	mu    sync.RWMutex
+	index *fulltext.Index
+	docs  map[int64]SearchArticle
+}
+
+func NewMemoryArticleSearchDao() ArticleSearchDao {
+	return &MemoryArticleSearchDao{
+		index: fulltext.NewIndex(
+			fulltext.Field{Name: searchFieldTitle, Boost: 3},
+			fulltext.Field{Name: searchFieldAuthor, Boost: 2},
+			fulltext.Field{Name: searchFieldContent, Boost: 1},
+		),
+		docs: make(map[int64]SearchArticle),
+	}
+}
+
+// @func: Upsert
+// @date: 2024-01-23 15:20:36
+// @brief: 帖子搜索-新增或覆盖索引
+// @author: Kewin Li
+// @receiver m
+// @param ctx
+// @param art
+// @return error
+func (m *MemoryArticleSearchDao) Upsert(ctx context.Context, art SearchArticle) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.index.Put(art.Id, map[string]string{
+		searchFieldTitle:   art.Title,
+		searchFieldAuthor:  art.AuthorName,
+		searchFieldContent: art.Content,
+	})
+	m.docs[art.Id] = art
+
+	return nil
+}
+
+// @func: Delete
+// @date: 2024-01-23 15:22:10
+// @brief: 帖子搜索-删除索引, 不存在时忽略
+// @author: Kewin Li
+// @receiver m
+// @param ctx
+// @param id
+// @return error
+func (m *MemoryArticleSearchDao) Delete(ctx context.Context, id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.index.Delete(id)
+	delete(m.docs, id)
+
+	return nil
+}
+
+// @func: Search
+// @date: 2024-01-23 15:24:42
+// @brief: 帖子搜索-按相关度分页检索
+// @author: Kewin Li
+// @receiver m
+// @param ctx
+// @param query
+// @param offset
+// @param limit
+// @return []SearchArticleHit
+// @return int 命中总数
+// @return error
+func (m *MemoryArticleSearchDao) Search(ctx context.Context, query string, offset int, limit int) ([]SearchArticleHit, int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	hits, total := m.index.Search(query, offset, limit)
+	res := make([]SearchArticleHit, 0, len(hits))
+	for _, hit := range hits {
+		res = append(res, SearchArticleHit{
+			Article: m.docs[hit.Id],
+			Score:   hit.Score,
+		})
+	}
+
+	return res, total, nil
+}
+
+// @func: Prune
+// @date: 2024-01-23 15:27:18
+// @brief: 帖子搜索-重建后清理已不在线上库的索引, 重建开始后才写入的索引不受影响
+// @author: Kewin Li
+// @receiver m
+// @param ctx
+// @param utimeBefore 重建开始时间
+// @param keep 本次重建写入的帖子
+// @return int 清理数
+// @return error
+func (m *MemoryArticleSearchDao) Prune(ctx context.Context, utimeBefore int64, keep map[int64]struct{}) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cnt := 0
+	for id, doc := range m.docs {
+		if _, ok := keep[id]; ok || doc.Utime >= utimeBefore {
+			continue
+		}
+
+		m.index.Delete(id)
+		delete(m.docs, id)
+		cnt++
+	}
+
+	return cnt, nil
+}
+
+// SearchArticle
+// @Description: 帖子索引文档
+type SearchArticle struct {
+	Id         int64
+	AuthorId   int64
+	AuthorName string
+	Title      string
+	Content    string
+	// 发表时间 毫秒
+	Utime int64
+}
+
+type SearchArticleHit struct {
+	Article SearchArticle
+	Score   float64
+}
@@ -0,0 +1,88 @@
+package dao
+
+import (
+	"context"
+	"time"
+)
+
+// SplitArticleDao
+// @Description: 制作库与线上库使用不同存储时的组合实现
+//
+// 两边无法放在同一个事务中: 先写制作库再写线上库, 线上库失败时作者重新发表即可修复
+type SplitArticleDao struct {
+	author ArticleAuthorDao
+	reader ArticleReaderDao
+}
+
+func NewSplitArticleDao(author ArticleAuthorDao, reader ArticleReaderDao) ArticleDao {
+	return &SplitArticleDao{
+		author: author,
+		reader: reader,
+	}
+}
+
+func (s *SplitArticleDao) Insert(ctx context.Context, art Article) (int64, error) {
+	return s.author.Create(ctx, art)
+}
+
+func (s *SplitArticleDao) UpdateById(ctx context.Context, art Article) error {
+	return s.author.Update(ctx, art)
+}
+
+// @func: Sync
+// @date: 2024-01-26 11:05:20
+// @brief: 帖子发表-数据同步-制作库与线上库分别写入
+// @author: Kewin Li
+// @receiver s
+// @param ctx
+// @param art
+// @return int64
+// @return error
+func (s *SplitArticleDao) Sync(ctx context.Context, art Article) (int64, error) {
+	var err error
+	if art.Id > 0 {
+		err = s.author.Update(ctx, art)
+	} else {
+		art.Id, err = s.author.Create(ctx, art)
+	}
+	if err != nil {
+		return art.Id, err
+	}
+
+	return art.Id, s.reader.Upsert(ctx, art)
+}
+
+// @func: SyncStatus
+// @date: 2024-01-26 11:06:42
+// @brief: 帖子状态同步-制作库与线上库分别修改
+// @author: Kewin Li
+// @receiver s
+// @param ctx
+// @param artId
+// @param authorId
+// @param status
+// @return error
+func (s *SplitArticleDao) SyncStatus(ctx context.Context, artId int64, authorId int64, status uint8) error {
+	err := s.author.UpdateStatus(ctx, artId, authorId, status)
+	if err != nil {
+		return err
+	}
+
+	return s.reader.UpdateStatus(ctx, artId, status)
+}
+
+func (s *SplitArticleDao) GetByAuthor(ctx context.Context, userId int64, offset int, limit int) ([]Article, error) {
+	return s.author.GetByAuthor(ctx, userId, offset, limit)
+}
+
+func (s *SplitArticleDao) GetById(ctx context.Context, artId int64) (Article, error) {
+	return s.author.GetById(ctx, artId)
+}
+
+func (s *SplitArticleDao) GetPubById(ctx context.Context, artId int64) (PublishedArticle, error) {
+	return s.reader.GetPubById(ctx, artId)
+}
+
+func (s *SplitArticleDao) ListPub(ctx context.Context, start time.Time, offset int, limit int) ([]PublishedArticle, error) {
+	return s.reader.ListPub(ctx, start, offset, limit)
+}
@@ -0,0 +1,165 @@
+// Package dao
+// @Description: 单元测试-制作库与线上库分别存储
+package dao
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"testing"
+)
+
+// @func: TestSplitArticleDao_Sync
+// @date: 2024-01-28 17:30:16
+// @brief: 单元测试-发表时先写制作库再写线上库, 制作库失败不写线上库, 线上库失败保留制作库并返回帖子ID
+// @author: Kewin Li
+// @param t
+func TestSplitArticleDao_Sync(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		art       Article
+		authorErr error
+		readerErr error
+
+		wantCalls []string
+		wantId    int64
+		wantErr   error
+	}{
+		{
+			name:      "新建帖子, 先创建再写线上库",
+			art:       Article{Title: "标题"},
+			wantCalls: []string{"author.Create", "reader.Upsert:10"},
+			wantId:    10,
+		},
+		{
+			name:      "已有帖子, 先更新再写线上库",
+			art:       Article{Id: 1, Title: "标题"},
+			wantCalls: []string{"author.Update", "reader.Upsert:1"},
+			wantId:    1,
+		},
+		{
+			name:      "制作库失败, 不写线上库",
+			art:       Article{Id: 1, Title: "标题"},
+			authorErr: errors.New("mock db error"),
+			wantCalls: []string{"author.Update"},
+			wantId:    1,
+			wantErr:   errors.New("mock db error"),
+		},
+		{
+			name:      "新建帖子, 制作库成功线上库失败, 返回已创建的帖子ID",
+			art:       Article{Title: "标题"},
+			readerErr: errors.New("mock s3 error"),
+			wantCalls: []string{"author.Create", "reader.Upsert:10"},
+			wantId:    10,
+			wantErr:   errors.New("mock s3 error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls []string
+			splitDao := NewSplitArticleDao(
+				&fakeArticleAuthorDao{calls: &calls, id: 10, err: tc.authorErr},
+				&fakeArticleReaderDao{calls: &calls, err: tc.readerErr})
+
+			id, err := splitDao.Sync(context.Background(), tc.art)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantId, id)
+			assert.Equal(t, tc.wantCalls, calls)
+		})
+	}
+}
+
+// @func: TestSplitArticleDao_SyncStatus
+// @date: 2024-01-28 17:35:02
+// @brief: 单元测试-状态同步先改制作库再改线上库, 制作库失败不改线上库
+// @author: Kewin Li
+// @param t
+func TestSplitArticleDao_SyncStatus(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		authorErr error
+		readerErr error
+
+		wantCalls []string
+		wantErr   error
+	}{
+		{
+			name:      "修改成功",
+			wantCalls: []string{"author.UpdateStatus", "reader.UpdateStatus"},
+		},
+		{
+			name:      "制作库失败, 不改线上库",
+			authorErr: errors.New("mock db error"),
+			wantCalls: []string{"author.UpdateStatus"},
+			wantErr:   errors.New("mock db error"),
+		},
+		{
+			name:      "线上库失败",
+			readerErr: errors.New("mock s3 error"),
+			wantCalls: []string{"author.UpdateStatus", "reader.UpdateStatus"},
+			wantErr:   errors.New("mock s3 error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls []string
+			splitDao := NewSplitArticleDao(
+				&fakeArticleAuthorDao{calls: &calls, err: tc.authorErr},
+				&fakeArticleReaderDao{calls: &calls, err: tc.readerErr})
+
+			err := splitDao.SyncStatus(context.Background(), 1, 123, 2)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantCalls, calls)
+		})
+	}
+}
+
+// fakeArticleAuthorDao
+// @Description: 记录调用顺序的制作库, 未用到的方法由内嵌接口兜底
+type fakeArticleAuthorDao struct {
+	ArticleAuthorDao
+	calls *[]string
+	id    int64
+	err   error
+}
+
+func (f *fakeArticleAuthorDao) Create(ctx context.Context, art Article) (int64, error) {
+	*f.calls = append(*f.calls, "author.Create")
+	if f.err != nil {
+		return 0, f.err
+	}
+	return f.id, nil
+}
+
+func (f *fakeArticleAuthorDao) Update(ctx context.Context, art Article) error {
+	*f.calls = append(*f.calls, "author.Update")
+	return f.err
+}
+
+func (f *fakeArticleAuthorDao) UpdateStatus(ctx context.Context, artId int64, authorId int64, status uint8) error {
+	*f.calls = append(*f.calls, "author.UpdateStatus")
+	return f.err
+}
+
+// fakeArticleReaderDao
+// @Description: 记录调用顺序的线上库, 未用到的方法由内嵌接口兜底
+type fakeArticleReaderDao struct {
+	ArticleReaderDao
+	calls *[]string
+	err   error
+}
+
+func (f *fakeArticleReaderDao) Upsert(ctx context.Context, art Article) error {
+	*f.calls = append(*f.calls, "reader.Upsert:"+strconv.FormatInt(art.Id, 10))
+	return f.err
+}
+
+func (f *fakeArticleReaderDao) UpdateStatus(ctx context.Context, artId int64, status uint8) error {
+	*f.calls = append(*f.calls, "reader.UpdateStatus")
+	return f.err
+}
@@ -0,0 +1,302 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"kitbook/internal/domain"
+	"strings"
+	"time"
+)
+
+// ErrTagNotFound 关联标签时未查到标签ID
+var ErrTagNotFound = errors.New("标签不存在")
+
+// LIKE查询时转义通配符
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// 帖子与标签的关联表, 制作库、线上库各一张
+const (
+	tableArticleTags    = "article_tags"
+	tablePubArticleTags = "published_article_tags"
+)
+
+type ArticleTagDao interface {
+	SetTags(ctx context.Context, artId int64, tags []string) error
+	SyncTags(ctx context.Context, artId int64, tags []string) error
+	FindTags(ctx context.Context, artIds []int64) (map[int64][]string, error)
+	FindPubTags(ctx context.Context, artIds []int64) (map[int64][]string, error)
+	ListPubByTag(ctx context.Context, tag string, offset int, limit int) ([]PublishedArticle, error)
+	CountPubByTag(ctx context.Context, tag string) (int64, error)
+	SearchTags(ctx context.Context, prefix string, limit int) ([]TagCnt, error)
+}
+
+// GormArticleTagDao
+// @Description: 帖子标签, 标签表与帖子多对多关联, 制作库、线上库各一张关联表
+type GormArticleTagDao struct {
+	db *gorm.DB
+}
+
+func NewGormArticleTagDao(db *gorm.DB) ArticleTagDao {
+	return &GormArticleTagDao{
+		db: db,
+	}
+}
+
+// @func: SetTags
+// @date: 2024-01-21 15:10:26
+// @brief: 帖子标签-覆盖制作库帖子的标签
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param artId
+// @param tags
+// @return error
+func (g *GormArticleTagDao) SetTags(ctx context.Context, artId int64, tags []string) error {
+	return g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return g.setTags(tx, tableArticleTags, artId, tags)
+	})
+}
+
+// @func: SyncTags
+// @date: 2024-01-21 15:12:40
+// @brief: 帖子标签-发表时同时覆盖制作库、线上库帖子的标签
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param artId
+// @param tags
+// @return error
+func (g *GormArticleTagDao) SyncTags(ctx context.Context, artId int64, tags []string) error {
+	return g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := g.setTags(tx, tableArticleTags, artId, tags)
+		if err != nil {
+			return err
+		}
+
+		return g.setTags(tx, tablePubArticleTags, artId, tags)
+	})
+}
+
+// @func: setTags
+// @date: 2024-01-21 15:15:08
+// @brief: 帖子标签-先删除帖子原有关联, 再关联新标签, 不存在的标签自动创建, 标签名不区分大小写
+// @author: Kewin Li
+// @receiver g
+// @param tx
+// @param table 关联表
+// @param artId
+// @param tags
+// @return error
+func (g *GormArticleTagDao) setTags(tx *gorm.DB, table string, artId int64, tags []string) error {
+	err := tx.Table(table).Where("art_id = ?", artId).Delete(&ArticleTag{}).Error
+	if err != nil || len(tags) == 0 {
+		return err
+	}
+
+	tags = domain.NormalizeTags(tags)
+	now := time.Now().UnixMilli()
+	newTags := make([]Tag, 0, len(tags))
+	for _, name := range tags {
+		newTags = append(newTags, Tag{Name: name, Ctime: now})
+	}
+	err = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&newTags).Error
+	if err != nil {
+		return err
+	}
+
+	// 已存在的标签插入时不会回填ID, 需要重新查询
+	var res []Tag
+	err = tx.Where("name IN ?", tags).Find(&res).Error
+	if err != nil {
+		return err
+	}
+
+	// 表排序规则不区分大小写, 已存在的标签名大小写可能与填写的不同
+	ids := make(map[string]int64, len(res))
+	for _, tag := range res {
+		ids[domain.NormalizeTag(tag.Name)] = tag.Id
+	}
+
+	// 按作者填写的顺序关联
+	rels := make([]ArticleTag, 0, len(tags))
+	for _, name := range tags {
+		id, ok := ids[name]
+		if !ok {
+			return ErrTagNotFound
+		}
+		rels = append(rels, ArticleTag{ArtId: artId, TagId: id, Ctime: now})
+	}
+
+	return tx.Table(table).Create(&rels).Error
+}
+
+// @func: FindTags
+// @date: 2024-01-21 15:20:32
+// @brief: 帖子标签-批量查询制作库帖子的标签
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param artIds
+// @return map[int64][]string 帖子ID-->标签
+// @return error
+func (g *GormArticleTagDao) FindTags(ctx context.Context, artIds []int64) (map[int64][]string, error) {
+	return g.findTags(ctx, tableArticleTags, artIds)
+}
+
+// @func: FindPubTags
+// @date: 2024-01-21 15:21:15
+// @brief: 帖子标签-批量查询线上库帖子的标签
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param artIds
+// @return map[int64][]string 帖子ID-->标签
+// @return error
+func (g *GormArticleTagDao) FindPubTags(ctx context.Context, artIds []int64) (map[int64][]string, error) {
+	return g.findTags(ctx, tablePubArticleTags, artIds)
+}
+
+// @func: findTags
+// @date: 2024-01-21 15:22:48
+// @brief: 帖子标签-按关联表批量查询帖子的标签, 保持添加时的顺序
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param table 关联表
+// @param artIds
+// @return map[int64][]string
+// @return error
+func (g *GormArticleTagDao) findTags(ctx context.Context, table string, artIds []int64) (map[int64][]string, error) {
+	res := make(map[int64][]string, len(artIds))
+	if len(artIds) == 0 {
+		return res, nil
+	}
+
+	var rows []struct {
+		ArtId int64
+		Name  string
+	}
+	err := g.db.WithContext(ctx).Table(table).
+		Select(table+".art_id, tags.name").
+		Joins("JOIN tags ON tags.id = "+table+".tag_id").
+		Where(table+".art_id IN ?", artIds).
+		Order(table + ".id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		res[row.ArtId] = append(res[row.ArtId], row.Name)
+	}
+
+	return res, nil
+}
+
+// @func: ListPubByTag
+// @date: 2024-01-21 15:26:30
+// @brief: 帖子标签-分页查询带有该标签的已发表帖子, 最新修改的排在前面
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param tag
+// @param offset
+// @param limit
+// @return []PublishedArticle
+// @return error
+func (g *GormArticleTagDao) ListPubByTag(ctx context.Context, tag string, offset int, limit int) ([]PublishedArticle, error) {
+	var arts []PublishedArticle
+	err := g.pubByTag(ctx, tag).
+		Select("published_articles.*").
+		Order("published_articles.utime DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&arts).Error
+
+	return arts, err
+}
+
+// @func: CountPubByTag
+// @date: 2024-01-21 15:28:12
+// @brief: 帖子标签-统计带有该标签的已发表帖子数
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param tag
+// @return int64
+// @return error
+func (g *GormArticleTagDao) CountPubByTag(ctx context.Context, tag string) (int64, error) {
+	var cnt int64
+	err := g.pubByTag(ctx, tag).Count(&cnt).Error
+	return cnt, err
+}
+
+// @func: pubByTag
+// @date: 2024-01-21 15:29:40
+// @brief: 帖子标签-带有该标签的已发表帖子
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param tag
+// @return *gorm.DB
+func (g *GormArticleTagDao) pubByTag(ctx context.Context, tag string) *gorm.DB {
+	return g.db.WithContext(ctx).Model(&PublishedArticle{}).
+		Joins("JOIN "+tablePubArticleTags+" ON "+tablePubArticleTags+".art_id = published_articles.id").
+		Joins("JOIN tags ON tags.id = "+tablePubArticleTags+".tag_id").
+		Where("tags.name = ? AND published_articles.status = ?", tag, domain.ArticleStatusPublished)
+}
+
+// @func: SearchTags
+// @date: 2024-01-21 15:32:18
+// @brief: 帖子标签-按前缀补全标签, 已发表帖子数多的排在前面
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param prefix 为空时返回最热门的标签
+// @param limit
+// @return []TagCnt
+// @return error
+func (g *GormArticleTagDao) SearchTags(ctx context.Context, prefix string, limit int) ([]TagCnt, error) {
+	var res []TagCnt
+	err := g.db.WithContext(ctx).Model(&Tag{}).
+		Select("tags.name, COUNT(published_articles.id) AS cnt").
+		Joins("LEFT JOIN "+tablePubArticleTags+" ON "+tablePubArticleTags+".tag_id = tags.id").
+		Joins("LEFT JOIN published_articles ON published_articles.id = "+tablePubArticleTags+".art_id AND published_articles.status = ?",
+			domain.ArticleStatusPublished).
+		Where("tags.name LIKE ?", likeEscaper.Replace(prefix)+"%").
+		Group("tags.id, tags.name").
+		Order("cnt DESC, tags.name").
+		Limit(limit).
+		Scan(&res).Error
+
+	return res, err
+}
+
+// Tag
+// @Description: 标签表, 作者编辑帖子时自动创建
+type Tag struct {
+	Id    int64  `gorm:"primaryKey, autoIncrement"`
+	Name  string `gorm:"type:varchar(64);uniqueIndex"`
+	Ctime int64
+}
+
+// ArticleTag
+// @Description: 制作库帖子与标签的关联表
+type ArticleTag struct {
+	Id    int64 `gorm:"primaryKey, autoIncrement"`
+	ArtId int64 `gorm:"uniqueIndex:art_tag"`
+	TagId int64 `gorm:"uniqueIndex:art_tag;index"`
+	Ctime int64
+}
+
+// PublishedArticleTag 线上库帖子与标签的关联表
+type PublishedArticleTag ArticleTag
+
+// TagCnt
+// @Description: 标签及已发表的帖子数
+type TagCnt struct {
+	Name string
+	Cnt  int64
+}
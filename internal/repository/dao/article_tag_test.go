@@ -0,0 +1,97 @@
+// Package dao
+// @Description: 单元测试-帖子标签
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"testing"
+)
+
+// @func: TestGormArticleTagDao_SetTags
+// @date: 2024-01-21 16:02:18
+// @brief: 单元测试-覆盖帖子标签, 标签名不区分大小写
+// @author: Kewin Li
+// @param t
+func TestGormArticleTagDao_SetTags(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock func(t *testing.T) *sql.DB
+
+		tags    []string
+		wantErr error
+	}{
+		{
+			name: "大小写不同的标签合并, 关联已存在的标签",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM `article_tags` WHERE art_id = \\?").
+					WithArgs(int64(1)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT INTO `tags` .* ON DUPLICATE KEY UPDATE").
+					WithArgs("go", sqlmock.AnyArg(), "redis", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(2, 1))
+				// 已存在的标签名为Go, 排序规则不区分大小写
+				mock.ExpectQuery("SELECT \\* FROM `tags` WHERE name IN \\(\\?,\\?\\)").
+					WithArgs("go", "redis").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).
+						AddRow(1, "Go").
+						AddRow(2, "redis"))
+				mock.ExpectExec("INSERT INTO `article_tags` \\(`art_id`,`tag_id`,`ctime`\\)").
+					WithArgs(int64(1), int64(1), sqlmock.AnyArg(), int64(1), int64(2), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 2))
+				mock.ExpectCommit()
+				return db
+			},
+			tags: []string{"Go", "go", " Redis "},
+		},
+		{
+			name: "标签ID缺失, 不写入ID为0的关联",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM `article_tags` WHERE art_id = \\?").
+					WithArgs(int64(1)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT INTO `tags` .* ON DUPLICATE KEY UPDATE").
+					WithArgs("go", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery("SELECT \\* FROM `tags` WHERE name IN \\(\\?\\)").
+					WithArgs("go").
+					WillReturnRows(sqlmock.NewRows([]string{"id", "name"}))
+				mock.ExpectRollback()
+				return db
+			},
+			tags:    []string{"go"},
+			wantErr: ErrTagNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlDB := tc.mock(t)
+			defer sqlDB.Close()
+
+			db, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlDB,
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{
+				SkipDefaultTransaction: true,
+				DisableAutomaticPing:   true,
+			})
+			assert.NoError(t, err)
+
+			d := NewGormArticleTagDao(db)
+			err = d.SetTags(context.Background(), 1, tc.tags)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"time"
+)
+
+var ErrCollectionMismatch = errors.New("收藏夹ID和用户ID不匹配")
+
+type CollectionDao interface {
+	Insert(ctx context.Context, c Collection) (int64, error)
+	Update(ctx context.Context, c Collection) error
+	Delete(ctx context.Context, id int64, uid int64) ([]UserCollectInfo, error)
+	FindById(ctx context.Context, id int64) (Collection, error)
+	FindByUid(ctx context.Context, uid int64, withPrivate bool) ([]Collection, error)
+	FindItems(ctx context.Context, uid int64, collectId int64, offset int, limit int) ([]UserCollectInfo, error)
+	MoveItems(ctx context.Context, uid int64, srcId int64, dstId int64, biz string, bizIds []int64) (int64, error)
+}
+
+type GormCollectionDao struct {
+	db *gorm.DB
+}
+
+func NewGormCollectionDao(db *gorm.DB) CollectionDao {
+	return &GormCollectionDao{
+		db: db,
+	}
+}
+
+// @func: Insert
+// @date: 2024-01-14 14:10:22
+// @brief: 收藏夹-新建
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param c
+// @return int64
+// @return error
+func (g *GormCollectionDao) Insert(ctx context.Context, c Collection) (int64, error) {
+	now := time.Now().UnixMilli()
+	c.Ctime = now
+	c.Utime = now
+
+	err := g.db.WithContext(ctx).Create(&c).Error
+	return c.Id, err
+}
+
+// @func: Update
+// @date: 2024-01-14 14:12:47
+// @brief: 收藏夹-修改名称、描述、隐私
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param c
+// @return error
+func (g *GormCollectionDao) Update(ctx context.Context, c Collection) error {
+	res := g.db.WithContext(ctx).Model(&Collection{}).
+		Where("id = ? AND uid = ?", c.Id, c.Uid).
+		Updates(map[string]any{
+			"name":        c.Name,
+			"description": c.Description,
+			"private":     c.Private,
+			"utime":       time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+
+	// 防止修改别人的收藏夹
+	if res.RowsAffected == 0 {
+		return ErrCollectionMismatch
+	}
+
+	return nil
+}
+
+// @func: Delete
+// @date: 2024-01-14 14:16:05
+// @brief: 收藏夹-删除收藏夹, 连带取消其中全部收藏并更新收藏数
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param id
+// @param uid
+// @return []UserCollectInfo 被取消的收藏, 供上层同步更新收藏数缓存
+// @return error
+func (g *GormCollectionDao) Delete(ctx context.Context, id int64, uid int64) ([]UserCollectInfo, error) {
+	now := time.Now().UnixMilli()
+
+	var items []UserCollectInfo
+	err := g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 1. 删除收藏夹
+		res := tx.Where("id = ? AND uid = ?", id, uid).Delete(&Collection{})
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return ErrCollectionMismatch
+		}
+
+		// 2. 软删除收藏夹中的收藏
+		err := tx.Where("user_id = ? AND collect_id = ? AND status = 1", uid, id).
+			Find(&items).Error
+		if err != nil {
+			return err
+		}
+
+		if len(items) == 0 {
+			return nil
+		}
+
+		ids := make([]int64, 0, len(items))
+		for _, item := range items {
+			ids = append(ids, item.Id)
+		}
+
+		err = tx.Model(&UserCollectInfo{}).
+			Where("id IN ?", ids).
+			Updates(map[string]any{
+				"status": 0,
+				"utime":  now,
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		// 3. 互动表 收藏数-1
+		for _, item := range items {
+			err = tx.Model(&Interactive{}).
+				Where("biz_id = ? AND biz = ? AND collect_cnt > 0", item.BizId, item.Biz).
+				Updates(map[string]any{
+					"collect_cnt": gorm.Expr("`collect_cnt` - 1"),
+					"utime":       now,
+				}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// @func: FindById
+// @date: 2024-01-14 14:25:31
+// @brief: 收藏夹-查询
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param id
+// @return Collection
+// @return error
+func (g *GormCollectionDao) FindById(ctx context.Context, id int64) (Collection, error) {
+	var c Collection
+	err := g.db.WithContext(ctx).Where("id = ?", id).First(&c).Error
+	return c, err
+}
+
+// @func: FindByUid
+// @date: 2024-01-14 14:27:14
+// @brief: 收藏夹-查询用户的全部收藏夹
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param uid
+// @param withPrivate 是否包含私密收藏夹
+// @return []Collection
+// @return error
+func (g *GormCollectionDao) FindByUid(ctx context.Context, uid int64, withPrivate bool) ([]Collection, error) {
+	var cs []Collection
+	db := g.db.WithContext(ctx).Where("uid = ?", uid)
+	if !withPrivate {
+		db = db.Where("private = ?", false)
+	}
+
+	err := db.Order("id ASC").Find(&cs).Error
+	return cs, err
+}
+
+// @func: FindItems
+// @date: 2024-01-14 14:30:48
+// @brief: 收藏夹-按收藏时间倒序分页查询收藏夹中的收藏
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param uid
+// @param collectId 0表示默认收藏夹
+// @param offset
+// @param limit
+// @return []UserCollectInfo
+// @return error
+func (g *GormCollectionDao) FindItems(ctx context.Context, uid int64, collectId int64, offset int, limit int) ([]UserCollectInfo, error) {
+	var items []UserCollectInfo
+	err := g.db.WithContext(ctx).
+		Where("user_id = ? AND collect_id = ? AND status = 1", uid, collectId).
+		Order("utime DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&items).Error
+	return items, err
+}
+
+// @func: MoveItems
+// @date: 2024-01-14 14:35:19
+// @brief: 收藏夹-把收藏从一个收藏夹移动到另一个收藏夹
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param uid
+// @param srcId
+// @param dstId
+// @param biz
+// @param bizIds
+// @return int64 实际移动的条数
+// @return error
+func (g *GormCollectionDao) MoveItems(ctx context.Context, uid int64, srcId int64, dstId int64, biz string, bizIds []int64) (int64, error) {
+	res := g.db.WithContext(ctx).Model(&UserCollectInfo{}).
+		Where("user_id = ? AND collect_id = ? AND biz = ? AND biz_id IN ? AND status = 1", uid, srcId, biz, bizIds).
+		Updates(map[string]any{
+			"collect_id": dstId,
+			"utime":      time.Now().UnixMilli(),
+		})
+	return res.RowsAffected, res.Error
+}
+
+// Collection
+// @Description: 收藏夹表
+type Collection struct {
+	Id  int64 `gorm:"primaryKey, autoIncrement"`
+	Uid int64 `gorm:"index"`
+
+	Name        string `gorm:"type:varchar(128)"`
+	Description string `gorm:"type:varchar(1024)"`
+	// 私密收藏夹仅自己可见
+	Private bool
+
+	Ctime int64
+	Utime int64
+}
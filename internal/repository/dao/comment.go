@@ -0,0 +1,234 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+var ErrCommentMismatch = errors.New("评论ID和用户ID不匹配")
+
+type CommentDao interface {
+	Insert(ctx context.Context, c Comment) (int64, error)
+	Delete(ctx context.Context, id int64, uid int64) (Comment, int64, error)
+	FindById(ctx context.Context, id int64) (Comment, error)
+	FindRootsByBiz(ctx context.Context, biz string, bizId int64, minId int64, limit int) ([]Comment, error)
+	FindRepliesByRoot(ctx context.Context, rootId int64, maxId int64, limit int) ([]Comment, error)
+}
+
+type GormCommentDao struct {
+	db *gorm.DB
+}
+
+func NewGormCommentDao(db *gorm.DB) CommentDao {
+	return &GormCommentDao{
+		db: db,
+	}
+}
+
+// @func: Insert
+// @date: 2024-01-08 20:52:33
+// @brief: 评论-新增评论, 同时互动表评论数+1
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param c
+// @return int64
+// @return error
+func (g *GormCommentDao) Insert(ctx context.Context, c Comment) (int64, error) {
+	now := time.Now().UnixMilli()
+	c.Ctime = now
+	c.Utime = now
+
+	err := g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 1. 评论表
+		err := tx.Create(&c).Error
+		if err != nil {
+			return err
+		}
+
+		// 2. 互动表, 评论数+1
+		return tx.Clauses(clause.OnConflict{
+			DoUpdates: clause.Assignments(map[string]any{
+				"comment_cnt": gorm.Expr("`comment_cnt` + 1"),
+				"utime":       now,
+			}),
+		}).Create(&Interactive{
+			BizId:      c.BizId,
+			Biz:        c.Biz,
+			CommentCnt: 1,
+			Utime:      now,
+			Ctime:      now,
+		}).Error
+	})
+
+	return c.Id, err
+}
+
+// @func: Delete
+// @date: 2024-01-08 21:03:18
+// @brief: 评论-评论者删除评论, 连带删除该评论下的所有回复
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param id
+// @param uid
+// @return Comment 被删除的评论
+// @return int64 一共删除的评论条数
+// @return error
+func (g *GormCommentDao) Delete(ctx context.Context, id int64, uid int64) (Comment, int64, error) {
+	var c Comment
+	var cnt int64
+
+	err := g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("id = ?", id).First(&c).Error
+		if err != nil {
+			return err
+		}
+
+		// 防攻击: 只能删除自己的评论
+		if c.Uid != uid {
+			return ErrCommentMismatch
+		}
+
+		ids, err := g.findSubtreeIds(tx, c)
+		if err != nil {
+			return err
+		}
+
+		res := tx.Where("id IN ?", ids).Delete(&Comment{})
+		if res.Error != nil {
+			return res.Error
+		}
+		cnt = res.RowsAffected
+
+		// 互动表, 评论数减去删除的条数, 注意不要越界为负数
+		return tx.Model(&Interactive{}).
+			Where("biz_id = ? AND biz = ?", c.BizId, c.Biz).
+			Updates(map[string]any{
+				"comment_cnt": gorm.Expr("GREATEST(`comment_cnt` - ?, 0)", cnt),
+				"utime":       time.Now().UnixMilli(),
+			}).Error
+	})
+
+	return c, cnt, err
+}
+
+// @func: findSubtreeIds
+// @date: 2024-01-08 21:05:51
+// @brief: 评论-查出以该评论为根的子树上所有评论ID
+// @author: Kewin Li
+// @receiver g
+// @param tx
+// @param c
+// @return []int64
+// @return error
+func (g *GormCommentDao) findSubtreeIds(tx *gorm.DB, c Comment) ([]int64, error) {
+	ids := []int64{c.Id}
+
+	// 根评论, 整棵评论树都需要删除
+	if !c.RootId.Valid {
+		var replyIds []int64
+		err := tx.Model(&Comment{}).Where("root_id = ?", c.Id).Pluck("id", &replyIds).Error
+		return append(ids, replyIds...), err
+	}
+
+	// 非根评论, 在同一棵评论树内逐层查找回复
+	parents := []int64{c.Id}
+	for len(parents) > 0 {
+		var children []int64
+		err := tx.Model(&Comment{}).
+			Where("root_id = ? AND pid IN ?", c.RootId.Int64, parents).
+			Pluck("id", &children).Error
+		if err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, children...)
+		parents = children
+	}
+
+	return ids, nil
+}
+
+// @func: FindById
+// @date: 2024-01-08 21:14:02
+// @brief: 评论-按ID查询
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param id
+// @return Comment
+// @return error
+func (g *GormCommentDao) FindById(ctx context.Context, id int64) (Comment, error) {
+	var c Comment
+	err := g.db.WithContext(ctx).Where("id = ?", id).First(&c).Error
+	return c, err
+}
+
+// @func: FindRootsByBiz
+// @date: 2024-01-08 21:17:25
+// @brief: 评论-按ID倒序分页查询根评论
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param biz
+// @param bizId
+// @param minId 上一页最后一条评论ID, 只查询比它更早的评论
+// @param limit
+// @return []Comment
+// @return error
+func (g *GormCommentDao) FindRootsByBiz(ctx context.Context, biz string, bizId int64, minId int64, limit int) ([]Comment, error) {
+	var cs []Comment
+	err := g.db.WithContext(ctx).
+		Where("biz = ? AND biz_id = ? AND id < ? AND root_id IS NULL", biz, bizId, minId).
+		Order("id DESC").
+		Limit(limit).
+		Find(&cs).Error
+	return cs, err
+}
+
+// @func: FindRepliesByRoot
+// @date: 2024-01-08 21:22:40
+// @brief: 评论-按ID正序分页查询根评论下的回复
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param rootId
+// @param maxId 上一页最后一条回复ID, 只查询比它更晚的回复
+// @param limit
+// @return []Comment
+// @return error
+func (g *GormCommentDao) FindRepliesByRoot(ctx context.Context, rootId int64, maxId int64, limit int) ([]Comment, error) {
+	var cs []Comment
+	err := g.db.WithContext(ctx).
+		Where("root_id = ? AND id > ?", rootId, maxId).
+		Order("id ASC").
+		Limit(limit).
+		Find(&cs).Error
+	return cs, err
+}
+
+// Comment
+// @Description: 评论表
+type Comment struct {
+	Id int64 `gorm:"primaryKey, autoIncrement"`
+	// 评论者
+	Uid int64 `gorm:"index"`
+	// Biz + BizId 表示评论的是哪个业务的哪一条记录
+	Biz   string `gorm:"type:varchar(128);index:biz_type_id"`
+	BizId int64  `gorm:"index:biz_type_id"`
+
+	Content string `gorm:"type:text"`
+
+	// 根评论ID, NULL表示自身就是根评论
+	RootId sql.NullInt64 `gorm:"index"`
+	// 直接回复的评论ID
+	Pid sql.NullInt64 `gorm:"index"`
+
+	Ctime int64
+	Utime int64
+}
@@ -0,0 +1,129 @@
+package dao
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type FeedDao interface {
+	CreatePushEvents(ctx context.Context, events []FeedPushEvent) error
+	CreatePullEvent(ctx context.Context, event FeedPullEvent) error
+	FindPushEvents(ctx context.Context, uid int64, end int64, limit int) ([]FeedPushEvent, error)
+	FindPullEvents(ctx context.Context, authorIds []int64, end int64, limit int) ([]FeedPullEvent, error)
+}
+
+type GormFeedDao struct {
+	db *gorm.DB
+}
+
+func NewGormFeedDao(db *gorm.DB) FeedDao {
+	return &GormFeedDao{
+		db: db,
+	}
+}
+
+// @func: CreatePushEvents
+// @date: 2024-01-13 15:41:09
+// @brief: feed流-推模式, 批量写入粉丝的收件箱
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param events
+// @return error
+func (g *GormFeedDao) CreatePushEvents(ctx context.Context, events []FeedPushEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	// 消息重复消费时忽略已写入的事件
+	return g.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		CreateInBatches(events, len(events)).Error
+}
+
+// @func: CreatePullEvent
+// @date: 2024-01-13 15:43:52
+// @brief: feed流-拉模式, 写入作者的发件箱
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param event
+// @return error
+func (g *GormFeedDao) CreatePullEvent(ctx context.Context, event FeedPullEvent) error {
+	return g.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&event).Error
+}
+
+// @func: FindPushEvents
+// @date: 2024-01-13 15:47:30
+// @brief: feed流-查询收件箱, 按发表时间倒序
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param uid
+// @param end 上一页最后一条事件的发表时间
+// @param limit
+// @return []FeedPushEvent
+// @return error
+func (g *GormFeedDao) FindPushEvents(ctx context.Context, uid int64, end int64, limit int) ([]FeedPushEvent, error) {
+	var events []FeedPushEvent
+	err := g.db.WithContext(ctx).
+		Where("uid = ? AND ctime < ?", uid, end).
+		Order("ctime DESC").
+		Limit(limit).
+		Find(&events).Error
+	return events, err
+}
+
+// @func: FindPullEvents
+// @date: 2024-01-13 15:50:18
+// @brief: feed流-查询多个作者的发件箱, 按发表时间倒序
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param authorIds
+// @param end 上一页最后一条事件的发表时间
+// @param limit
+// @return []FeedPullEvent
+// @return error
+func (g *GormFeedDao) FindPullEvents(ctx context.Context, authorIds []int64, end int64, limit int) ([]FeedPullEvent, error) {
+	var events []FeedPullEvent
+	if len(authorIds) == 0 {
+		return events, nil
+	}
+
+	err := g.db.WithContext(ctx).
+		Where("author_id IN ? AND ctime < ?", authorIds, end).
+		Order("ctime DESC").
+		Limit(limit).
+		Find(&events).Error
+	return events, err
+}
+
+// FeedPushEvent
+// @Description: 收件箱(推模式), 发表时写入每个粉丝的收件箱
+type FeedPushEvent struct {
+	Id int64 `gorm:"primaryKey, autoIncrement"`
+
+	// 收件人, <uid, ctime> 支持按时间倒序查询
+	Uid   int64 `gorm:"uniqueIndex:uid_art;index:uid_ctime"`
+	ArtId int64 `gorm:"uniqueIndex:uid_art"`
+
+	AuthorId int64
+	// 帖子发表时间
+	Ctime int64 `gorm:"index:uid_ctime"`
+}
+
+// FeedPullEvent
+// @Description: 发件箱(拉模式), 粉丝多的作者发表时只写自己的发件箱
+type FeedPullEvent struct {
+	Id int64 `gorm:"primaryKey, autoIncrement"`
+
+	ArtId int64 `gorm:"unique"`
+	// <author_id, ctime> 支持按时间倒序查询
+	AuthorId int64 `gorm:"index:author_ctime"`
+	// 帖子发表时间
+	Ctime int64 `gorm:"index:author_ctime"`
+}
@@ -0,0 +1,268 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+const (
+	followStatusInvalid = iota // 已取消关注
+	followStatusValid          // 关注有效
+)
+
+var ErrRepeatFollow = errors.New("重复关注/取消关注")
+
+type FollowDao interface {
+	Follow(ctx context.Context, follower int64, followee int64) error
+	Unfollow(ctx context.Context, follower int64, followee int64) error
+	FollowerList(ctx context.Context, followee int64, maxId int64, limit int) ([]FollowRelation, error)
+	FolloweeList(ctx context.Context, follower int64, maxId int64, limit int) ([]FollowRelation, error)
+	FollowRelationDetail(ctx context.Context, follower int64, followee int64) (FollowRelation, error)
+	GetStatics(ctx context.Context, uid int64) (FollowStatics, error)
+}
+
+type GormFollowDao struct {
+	db *gorm.DB
+}
+
+func NewGormFollowDao(db *gorm.DB) FollowDao {
+	return &GormFollowDao{
+		db: db,
+	}
+}
+
+// @func: Follow
+// @date: 2024-01-12 20:40:12
+// @brief: 关注-新增关注关系, 同时更新双方的粉丝数、关注数
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param follower
+// @param followee
+// @return error
+func (g *GormFollowDao) Follow(ctx context.Context, follower int64, followee int64) error {
+	now := time.Now().UnixMilli()
+
+	return g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 1. 之前取消过关注, 恢复关注关系
+		res := tx.Model(&FollowRelation{}).
+			Where("follower = ? AND followee = ? AND status = ?", follower, followee, followStatusInvalid).
+			Updates(map[string]any{
+				"status": followStatusValid,
+				"utime":  now,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+
+		// 2. 从未关注过, 新建关注关系
+		if res.RowsAffected == 0 {
+			err := tx.Create(&FollowRelation{
+				Follower: follower,
+				Followee: followee,
+				Status:   followStatusValid,
+				Ctime:    now,
+				Utime:    now,
+			}).Error
+
+			if me, ok := err.(*mysql.MySQLError); ok {
+				const duplicateErr uint16 = 1062
+				// 已经关注过
+				if me.Number == duplicateErr {
+					return ErrRepeatFollow
+				}
+			}
+
+			if err != nil {
+				return err
+			}
+		}
+
+		// 3. 被关注者粉丝数+1, 关注者关注数+1
+		err := g.upsertStatics(tx, followee, "followers", 1, now)
+		if err != nil {
+			return err
+		}
+		return g.upsertStatics(tx, follower, "followees", 1, now)
+	})
+}
+
+// @func: Unfollow
+// @date: 2024-01-12 20:52:37
+// @brief: 关注-取消关注(软删除), 同时更新双方的粉丝数、关注数
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param follower
+// @param followee
+// @return error
+func (g *GormFollowDao) Unfollow(ctx context.Context, follower int64, followee int64) error {
+	now := time.Now().UnixMilli()
+
+	return g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 注意：防止受到攻击, 需要关注状态有效才能取消关注
+		res := tx.Model(&FollowRelation{}).
+			Where("follower = ? AND followee = ? AND status = ?", follower, followee, followStatusValid).
+			Updates(map[string]any{
+				"status": followStatusInvalid,
+				"utime":  now,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return ErrRepeatFollow
+		}
+
+		err := g.upsertStatics(tx, followee, "followers", -1, now)
+		if err != nil {
+			return err
+		}
+		return g.upsertStatics(tx, follower, "followees", -1, now)
+	})
+}
+
+// @func: upsertStatics
+// @date: 2024-01-12 21:01:44
+// @brief: 关注-粉丝数/关注数增减(UpSert语义)
+// @author: Kewin Li
+// @receiver g
+// @param tx
+// @param uid
+// @param field followers 或 followees
+// @param delta
+// @param now
+// @return error
+func (g *GormFollowDao) upsertStatics(tx *gorm.DB, uid int64, field string, delta int64, now int64) error {
+	statics := FollowStatics{
+		Uid:   uid,
+		Ctime: now,
+		Utime: now,
+	}
+	if delta > 0 {
+		if field == "followers" {
+			statics.Followers = delta
+		} else {
+			statics.Followees = delta
+		}
+	}
+
+	return tx.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]any{
+			// 计数不要越界为负数
+			field:   gorm.Expr("GREATEST(`"+field+"` + ?, 0)", delta),
+			"utime": now,
+		}),
+	}).Create(&statics).Error
+}
+
+// @func: FollowerList
+// @date: 2024-01-12 21:08:20
+// @brief: 关注-粉丝列表, 按关注时间倒序游标分页
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param followee
+// @param maxId 上一页最后一条关注关系ID
+// @param limit
+// @return []FollowRelation
+// @return error
+func (g *GormFollowDao) FollowerList(ctx context.Context, followee int64, maxId int64, limit int) ([]FollowRelation, error) {
+	var relations []FollowRelation
+	err := g.db.WithContext(ctx).
+		Where("followee = ? AND status = ? AND id < ?", followee, followStatusValid, maxId).
+		Order("id DESC").
+		Limit(limit).
+		Find(&relations).Error
+	return relations, err
+}
+
+// @func: FolloweeList
+// @date: 2024-01-12 21:10:03
+// @brief: 关注-关注列表, 按关注时间倒序游标分页
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param follower
+// @param maxId 上一页最后一条关注关系ID
+// @param limit
+// @return []FollowRelation
+// @return error
+func (g *GormFollowDao) FolloweeList(ctx context.Context, follower int64, maxId int64, limit int) ([]FollowRelation, error) {
+	var relations []FollowRelation
+	err := g.db.WithContext(ctx).
+		Where("follower = ? AND status = ? AND id < ?", follower, followStatusValid, maxId).
+		Order("id DESC").
+		Limit(limit).
+		Find(&relations).Error
+	return relations, err
+}
+
+// @func: FollowRelationDetail
+// @date: 2024-01-12 21:12:30
+// @brief: 关注-查询有效的关注关系
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param follower
+// @param followee
+// @return FollowRelation
+// @return error
+func (g *GormFollowDao) FollowRelationDetail(ctx context.Context, follower int64, followee int64) (FollowRelation, error) {
+	var relation FollowRelation
+	err := g.db.WithContext(ctx).
+		Where("follower = ? AND followee = ? AND status = ?", follower, followee, followStatusValid).
+		First(&relation).Error
+	return relation, err
+}
+
+// @func: GetStatics
+// @date: 2024-01-12 21:14:16
+// @brief: 关注-查询粉丝数、关注数
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param uid
+// @return FollowStatics
+// @return error
+func (g *GormFollowDao) GetStatics(ctx context.Context, uid int64) (FollowStatics, error) {
+	var statics FollowStatics
+	err := g.db.WithContext(ctx).Where("uid = ?", uid).First(&statics).Error
+	return statics, err
+}
+
+// FollowRelation
+// @Description: 关注关系表
+type FollowRelation struct {
+	Id int64 `gorm:"primaryKey, autoIncrement"`
+
+	// <follower, followee> 联合唯一索引, 同时支持查询关注列表
+	Follower int64 `gorm:"uniqueIndex:follower_followee"`
+	// 查询粉丝列表
+	Followee int64 `gorm:"uniqueIndex:follower_followee;index"`
+
+	// 关注是否有效
+	Status uint8
+	Ctime  int64
+	Utime  int64
+}
+
+// FollowStatics
+// @Description: 粉丝数、关注数统计表
+type FollowStatics struct {
+	Id  int64 `gorm:"primaryKey, autoIncrement"`
+	Uid int64 `gorm:"unique"`
+
+	// 粉丝数
+	Followers int64
+	// 关注数
+	Followees int64
+
+	Ctime int64
+	Utime int64
+}
@@ -0,0 +1,138 @@
+package dao
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type HistoryDao interface {
+	Upsert(ctx context.Context, record HistoryRecord) error
+	BatchUpsert(ctx context.Context, records []HistoryRecord) error
+	GetByUser(ctx context.Context, userId int64, biz string, end int64, endId int64, limit int) ([]HistoryRecord, error)
+	DelByUser(ctx context.Context, userId int64, biz string) error
+}
+
+type GormHistoryDao struct {
+	db *gorm.DB
+}
+
+func NewGormHistoryDao(db *gorm.DB) HistoryDao {
+	return &GormHistoryDao{
+		db: db,
+	}
+}
+
+// @func: Upsert
+// @date: 2024-01-06 15:20:31
+// @brief: 浏览记录-新增记录(UpSert语义), 重复浏览只刷新浏览时间
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param record
+// @return error
+func (g *GormHistoryDao) Upsert(ctx context.Context, record HistoryRecord) error {
+	now := time.Now().UnixMilli()
+	if record.Utime <= 0 {
+		record.Utime = now
+	}
+	record.Ctime = now
+
+	return g.db.WithContext(ctx).Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]any{
+			// 乱序消费时不能让旧的浏览时间覆盖新的浏览时间
+			"utime": gorm.Expr("GREATEST(`utime`, ?)", record.Utime),
+		}),
+	}).Create(&record).Error
+}
+
+// @func: BatchUpsert
+// @date: 2024-01-06 15:24:10
+// @brief: 浏览记录-一条多行INSERT ... ON DUPLICATE KEY UPDATE提交多条记录
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param records
+// @return error
+func (g *GormHistoryDao) BatchUpsert(ctx context.Context, records []HistoryRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	now := time.Now().UnixMilli()
+	rows := make([]HistoryRecord, 0, len(records))
+	for _, record := range records {
+		if record.Utime <= 0 {
+			record.Utime = now
+		}
+		record.Ctime = now
+		rows = append(rows, record)
+	}
+
+	return g.db.WithContext(ctx).Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]any{
+			// 乱序消费时不能让旧的浏览时间覆盖新的浏览时间
+			"utime": gorm.Expr("GREATEST(`utime`, VALUES(`utime`))"),
+		}),
+	}).Create(&rows).Error
+}
+
+// @func: GetByUser
+// @date: 2024-01-06 15:30:46
+// @brief: 浏览记录-按(浏览时间, ID)倒序分页查询, 批量写入时浏览时间相同的记录不会在翻页时漏掉
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param userId
+// @param biz 为空时查询全部业务
+// @param end 查询的时间游标, 上一页最后一条记录的浏览时间
+// @param endId 上一页最后一条记录的ID, 浏览时间与游标相同时只查询ID更小的记录
+// @param limit
+// @return []HistoryRecord
+// @return error
+func (g *GormHistoryDao) GetByUser(ctx context.Context, userId int64, biz string, end int64, endId int64, limit int) ([]HistoryRecord, error) {
+	var records []HistoryRecord
+
+	db := g.db.WithContext(ctx).Where("user_id = ? AND (utime < ? OR (utime = ? AND id < ?))", userId, end, end, endId)
+	if biz != "" {
+		db = db.Where("biz = ?", biz)
+	}
+
+	err := db.Order("utime DESC, id DESC").Limit(limit).Find(&records).Error
+	return records, err
+}
+
+// @func: DelByUser
+// @date: 2024-01-06 15:36:02
+// @brief: 浏览记录-清空用户浏览记录
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param userId
+// @param biz 为空时清空全部业务
+// @return error
+func (g *GormHistoryDao) DelByUser(ctx context.Context, userId int64, biz string) error {
+	db := g.db.WithContext(ctx).Where("user_id = ?", userId)
+	if biz != "" {
+		db = db.Where("biz = ?", biz)
+	}
+
+	return db.Delete(&HistoryRecord{}).Error
+}
+
+// HistoryRecord
+// @Description: 用户浏览记录表, 同一个用户对同一个资源只保留一条记录
+type HistoryRecord struct {
+	Id int64 `gorm:"primaryKey, autoIncrement"`
+	// 以用户ID为主字段查询
+	UserId int64 `gorm:"uniqueIndex:uid_biz_type_id;index:uid_utime"`
+	// BizId + Biz 共同表示哪个业务的哪一条记录
+	BizId int64  `gorm:"uniqueIndex:uid_biz_type_id"`
+	Biz   string `gorm:"type:varchar(128);uniqueIndex:uid_biz_type_id"`
+
+	// 最近一次浏览时间
+	Utime int64 `gorm:"index:uid_utime"`
+	// 第一次浏览时间
+	Ctime int64
+}
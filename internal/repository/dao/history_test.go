@@ -0,0 +1,178 @@
+// Package dao
+// @Description: 单元测试-浏览记录模块
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"testing"
+)
+
+// @func: TestGormHistoryDao_BatchUpsert
+// @date: 2024-01-26 14:10:20
+// @brief: 单元测试-dao层-批量提交浏览记录只执行一条多行INSERT
+// @author: Kewin Li
+// @param t
+func TestGormHistoryDao_BatchUpsert(t *testing.T) {
+	records := []HistoryRecord{
+		{UserId: 1, BizId: 10, Biz: "article", Utime: 100},
+		{UserId: 1, BizId: 11, Biz: "article", Utime: 200},
+		{UserId: 2, BizId: 10, Biz: "article"},
+	}
+	// 每条记录5列
+	args := make([]driver.Value, 0, len(records)*5)
+	for i := 0; i < cap(args); i++ {
+		args = append(args, sqlmock.AnyArg())
+	}
+
+	testCases := []struct {
+		name string
+
+		mock func(t *testing.T) *sql.DB
+
+		records []HistoryRecord
+
+		wantErr error
+	}{
+		{
+			name: "多条记录一条语句提交",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectExec("INSERT INTO `history_records` .* VALUES \\(.+\\),\\(.+\\),\\(.+\\) " +
+					"ON DUPLICATE KEY UPDATE `utime`=GREATEST\\(`utime`, VALUES\\(`utime`\\)\\)").
+					WithArgs(args...).
+					WillReturnResult(sqlmock.NewResult(3, 3))
+
+				return db
+			},
+
+			records: records,
+		},
+		{
+			name: "空记录不访问数据库",
+			mock: func(t *testing.T) *sql.DB {
+				db, _, err := sqlmock.New()
+				assert.NoError(t, err)
+				return db
+			},
+		},
+		{
+			name: "数据库错误",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectExec("INSERT INTO `history_records`").
+					WithArgs(args...).
+					WillReturnError(errors.New("数据库错误"))
+
+				return db
+			},
+
+			records: records,
+
+			wantErr: errors.New("数据库错误"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlDB := tc.mock(t)
+			db, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlDB,
+				SkipInitializeWithVersion: true, //是否跳过版本查询
+			}), &gorm.Config{
+				DisableAutomaticPing:   true, // 是否禁止ping数据库
+				SkipDefaultTransaction: true, // 是否禁止事务
+			})
+			assert.NoError(t, err)
+
+			d := NewGormHistoryDao(db)
+			err = d.BatchUpsert(context.Background(), tc.records)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+// @func: TestGormHistoryDao_GetByUser
+// @date: 2024-01-28 18:20:40
+// @brief: 单元测试-dao层-按(浏览时间, ID)游标翻页, 浏览时间相同的记录不会漏掉
+// @author: Kewin Li
+// @param t
+func TestGormHistoryDao_GetByUser(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock func(t *testing.T) *sql.DB
+
+		biz string
+
+		wantRecords []HistoryRecord
+		wantErr     error
+	}{
+		{
+			name: "下一页包含与游标浏览时间相同且ID更小的记录",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectQuery("SELECT \\* FROM `history_records` " +
+					"WHERE user_id = \\? AND \\(utime < \\? OR \\(utime = \\? AND id < \\?\\)\\) " +
+					"ORDER BY utime DESC, id DESC LIMIT 2").
+					WithArgs(int64(1), int64(100), int64(100), int64(5)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "biz_id", "biz", "utime"}).
+						AddRow(4, 1, 11, "article", 100).
+						AddRow(3, 1, 12, "article", 100))
+
+				return db
+			},
+
+			wantRecords: []HistoryRecord{
+				{Id: 4, UserId: 1, BizId: 11, Biz: "article", Utime: 100},
+				{Id: 3, UserId: 1, BizId: 12, Biz: "article", Utime: 100},
+			},
+		},
+		{
+			name: "按业务过滤",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectQuery("SELECT \\* FROM `history_records` " +
+					"WHERE \\(user_id = \\? AND \\(utime < \\? OR \\(utime = \\? AND id < \\?\\)\\)\\) AND biz = \\? " +
+					"ORDER BY utime DESC, id DESC LIMIT 2").
+					WithArgs(int64(1), int64(100), int64(100), int64(5), "article").
+					WillReturnError(errors.New("数据库错误"))
+
+				return db
+			},
+
+			biz: "article",
+
+			wantErr: errors.New("数据库错误"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlDB := tc.mock(t)
+			db, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlDB,
+				SkipInitializeWithVersion: true, //是否跳过版本查询
+			}), &gorm.Config{
+				DisableAutomaticPing:   true, // 是否禁止ping数据库
+				SkipDefaultTransaction: true, // 是否禁止事务
+			})
+			assert.NoError(t, err)
+
+			d := NewGormHistoryDao(db)
+			records, err := d.GetByUser(context.Background(), 1, tc.biz, 100, 5, 2)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantRecords, records)
+		})
+	}
+}
@@ -11,13 +11,32 @@ import (
 
 func InitTables(db *gorm.DB) error {
 	return db.AutoMigrate(
-		&User{},             //用户表
-		&Article{},          //帖子表-制作库
-		&PublishedArticle{}, //帖子表-线上库
-		&Interactive{},      //互动表, 阅读数+点赞数+收藏数
-		&UserLikeInfo{},     //用户点赞信息表
-		&UserCollectInfo{},  //用户收藏信息表
-		&Job{},              //任务调度表
+		&User{},                //用户表
+		&Article{},             //帖子表-制作库
+		&PublishedArticle{},    //帖子表-线上库
+		&Interactive{},         //互动表, 阅读数+点赞数+收藏数
+		&UserLikeInfo{},        //用户点赞信息表
+		&UserCollectInfo{},     //用户收藏信息表
+		&Job{},                 //任务调度表
+		&HistoryRecord{},       //用户浏览记录表
+		&Comment{},             //评论表
+		&FollowRelation{},      //关注关系表
+		&FollowStatics{},       //粉丝数、关注数统计表
+		&FeedPushEvent{},       //feed流收件箱
+		&FeedPullEvent{},       //feed流发件箱
+		&Collection{},          //收藏夹表
+		&JobExecution{},        //任务执行日志表
+		&JobShard{},            //分片任务分片表
+		&JobDependency{},       //任务依赖关系表
+		&Tag{},                 //标签表
+		&ArticleTag{},          //帖子标签关联表-制作库
+		&PublishedArticleTag{}, //帖子标签关联表-线上库
+		&ArticleRevision{},     //帖子历史版本表
+		&ArticleSchedule{},     //帖子定时发表计划表
+		&Upload{},              //上传文件表
+		&UploadRef{},           //上传文件引用表
+		&ArticleReview{},       //帖子审核记录表
+		&Notification{},        //站内通知表
 	)
 }
 
@@ -36,6 +55,10 @@ func InitCollection(mdb *mongo.Database) error {
 		},
 	})
 
+	if err != nil {
+		return err
+	}
+
 	colLive := mdb.Collection("published_articles")
 	_, err = colLive.Indexes().CreateMany(ctx, []mongo.IndexModel{
 		{
@@ -45,11 +68,11 @@ func InitCollection(mdb *mongo.Database) error {
 		{
 			Keys: bson.D{{"author_id", 1}},
 		},
+		{
+			// 分批查询已发表的帖子
+			Keys: bson.D{{"status", 1}, {"id", 1}},
+		},
 	})
 
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return err
 }
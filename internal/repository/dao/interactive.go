@@ -360,6 +360,8 @@ type Interactive struct {
 	LikeCnt int64
 	// 收藏数
 	CollectCnt int64
+	// 评论数
+	CommentCnt int64
 	Utime      int64
 	Ctime      int64
 }
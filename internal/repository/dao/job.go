@@ -3,68 +3,181 @@ package dao
 import (
 	"context"
 	"errors"
+	"fmt"
+	"github.com/go-sql-driver/mysql"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"time"
 )
 
+const (
+	jobModeSingle    = iota // 单结点执行
+	jobModeBroadcast        // 每个结点各自执行
+	jobModeSharding         // 拆分为多个分片执行
+)
+
 const (
 	jobStatusWaiting = iota // 任务等待调度
 	jobStatusRunning        // 任务正在运行
 	jobStatusPaused         // 任务暂停调度
 )
 
-var ErrPreemptJobInvalid = errors.New("抢占任务失败")
+const (
+	jobResultNone    = iota // 从未执行
+	jobResultSuccess        // 执行成功
+	jobResultFailed         // 执行失败
+)
+
+const (
+	shardStatusWaiting  = iota // 分片等待抢占
+	shardStatusRunning         // 分片正在运行
+	shardStatusFinished        // 分片执行完毕
+)
+
+// 抢占时每页查询的到期任务数
+const preemptBatch = 10
+
+// 存在上游任务在下游最近一次成功之后尚未成功过, 下游任务不可调度
+const upstreamPendingSQL = "EXISTS (SELECT 1 FROM `job_dependencies` AS d JOIN `jobs` AS u ON u.id = d.upstream_id " +
+	"WHERE d.job_id = `jobs`.id AND u.last_success_time <= `jobs`.last_success_time)"
+
+var (
+	ErrPreemptJobInvalid = errors.New("抢占任务失败")
+	ErrDuplicateJob      = errors.New("任务名称已存在")
+	ErrJobStatusMismatch = errors.New("任务状态不允许该操作")
+	// 本轮的分片已全部被抢占
+	errNoWaitingShard = errors.New("没有等待中的分片")
+)
 
 type JobDao interface {
-	Preempt(ctx context.Context) (Job, error)
-	Release(ctx context.Context, jobId int64) error
-	UpdateUtime(ctx context.Context, jobId int64) error
+	Preempt(ctx context.Context, accept func(job Job) bool) (Job, error)
+	Release(ctx context.Context, jobId int64, version int) error
+	UpdateUtime(ctx context.Context, jobId int64, version int) error
+	ReclaimStuck(ctx context.Context, deadline time.Time) (int64, error)
 	UpdateNextTime(ctx context.Context, jobId int64, nextTime time.Time) error
+	MarkMisfire(ctx context.Context, jobId int64, version int, missed int, catchUp int) error
+	SkipMisfire(ctx context.Context, jobId int64, version int, missed int, nextTime time.Time) error
+
+	// 分片、广播模式
+	PreemptShard(ctx context.Context, accept func(job Job) bool) (Job, JobShard, error)
+	UpdateShardUtime(ctx context.Context, shardId int64, version int) error
+	FinishShard(ctx context.Context, exec JobExecution, shard JobShard, nextTime time.Time) error
+	FindBroadcast(ctx context.Context) ([]Job, error)
+	FinishBroadcast(ctx context.Context, exec JobExecution) error
+
+	Insert(ctx context.Context, job Job, upstreams []int64) (int64, error)
+	Update(ctx context.Context, job Job, upstreams []int64) error
+	Delete(ctx context.Context, jobId int64) error
+	Pause(ctx context.Context, jobId int64) error
+	Resume(ctx context.Context, jobId int64, nextTime time.Time) error
+	Trigger(ctx context.Context, jobId int64) error
+	Finish(ctx context.Context, exec JobExecution, version int, retryCnt int, catchUp int, nextTime time.Time) error
+	FindById(ctx context.Context, jobId int64) (Job, error)
+	FindList(ctx context.Context, offset int, limit int) ([]Job, error)
+	FindByIds(ctx context.Context, jobIds []int64) ([]Job, error)
+	FindDependencies(ctx context.Context) ([]JobDependency, error)
+	FindUpstreams(ctx context.Context, jobIds []int64) ([]JobDependency, error)
+	FindExecutions(ctx context.Context, jobId int64, offset int, limit int) ([]JobExecution, error)
 }
 
 type GormJobDao struct {
 	db *gorm.DB
 }
 
+func NewGormJobDao(db *gorm.DB) JobDao {
+	return &GormJobDao{
+		db: db,
+	}
+}
+
 // @func: Preempt
 // @date: 2023-12-31 19:22:12
-// @brief: MySQL任务调度-任务抢占-乐观锁机制
+// @brief: MySQL任务调度-任务抢占-乐观锁机制, 仅抢占单结点模式且上游均已完成的任务
 // @author: Kewin Li
 // @receiver g
 // @param ctx
+// @param accept 当前结点能否执行该任务
 // @return Job
 // @return error
-func (g *GormJobDao) Preempt(ctx context.Context) (Job, error) {
-	for {
-
-		var job Job
-		now := time.Now().UnixMilli()
+func (g *GormJobDao) Preempt(ctx context.Context, accept func(job Job) bool) (Job, error) {
+	now := time.Now().UnixMilli()
 
-		err := g.db.WithContext(ctx).
-			Where("next_time < ? AND status = ?", now, jobStatusWaiting).
-			First(&job).Error
-		if err != nil {
-			return job, err
+	var res Job
+	err := g.scanDue(ctx, jobModeSingle, now, func(job Job) (bool, error) {
+		// 当前结点不满足亲和标签, 留给其他结点
+		if !accept(job) {
+			return false, nil
 		}
 
-		res := g.db.WithContext(ctx).Model(&Job{}).Where("id = ?", job.Id).Updates(map[string]any{
+		result := g.db.WithContext(ctx).Model(&Job{}).
+			Where("id = ? AND version = ?", job.Id, job.Version).Updates(map[string]any{
 			"status":  jobStatusRunning,
 			"version": job.Version + 1,
 			"utime":   now,
 		})
-		err = res.Error
-		if err != nil {
-			return Job{}, err
+		if result.Error != nil {
+			return false, result.Error
 		}
 
 		// 没有抢占到任务
-		if res.RowsAffected == 0 {
-			continue
+		if result.RowsAffected == 0 {
+			return false, nil
 		}
 
-		return job, err
-	}
+		job.Version = job.Version + 1
+		res = job
+		return true, nil
+	})
+
+	return res, err
+}
+
+// @func: scanDue
+// @date: 2024-01-20 15:02:18
+// @brief: MySQL任务调度-按(next_time, id)游标分页遍历到期任务, 亲和标签不匹配的任务不会挡住后面的任务
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param mode 执行模式
+// @param now
+// @param visit 返回true表示已抢占到任务, 停止遍历
+// @return error 遍历完全部到期任务仍未抢占到时返回ErrRecordNotFound
+func (g *GormJobDao) scanDue(ctx context.Context, mode int, now int64, visit func(job Job) (bool, error)) error {
+	var lastTime, lastId int64
+	first := true
+	for {
+		query := g.db.WithContext(ctx).
+			Where("next_time < ? AND status = ? AND mode = ?", now, jobStatusWaiting, mode).
+			Not(upstreamPendingSQL)
+		if !first {
+			query = query.Where("(next_time > ? OR (next_time = ? AND id > ?))", lastTime, lastTime, lastId)
+		}
+
+		var jobs []Job
+		err := query.Order("next_time ASC, id ASC").
+			Limit(preemptBatch).
+			Find(&jobs).Error
+		if err != nil {
+			return err
+		}
+
+		for _, job := range jobs {
+			ok, err := visit(job)
+			if err != nil {
+				return err
+			}
+			if ok {
+				return nil
+			}
+		}
+
+		if len(jobs) < preemptBatch {
+			return ErrRecordNotFound
+		}
 
+		first = false
+		lastTime, lastId = jobs[len(jobs)-1].NextTime, jobs[len(jobs)-1].Id
+	}
 }
 
 // @func: Release
@@ -74,10 +187,14 @@ func (g *GormJobDao) Preempt(ctx context.Context) (Job, error) {
 // @receiver g
 // @param ctx
 // @param jobId
+// @param version 抢占时的版本号
 // @return error
-func (g *GormJobDao) Release(ctx context.Context, jobId int64) error {
+func (g *GormJobDao) Release(ctx context.Context, jobId int64, version int) error {
 	now := time.Now().UnixMilli()
-	return g.db.WithContext(ctx).Model(&Job{}).Where("id = ?", jobId).Updates(map[string]any{
+	// 运行期间被暂停的任务, 释放后保持暂停状态
+	// 已被回收的任务版本号已变化, 不再释放
+	return g.db.WithContext(ctx).Model(&Job{}).
+		Where("id = ? AND version = ? AND status = ?", jobId, version, jobStatusRunning).Updates(map[string]any{
 		"status": jobStatusWaiting,
 		"utime":  now,
 	}).Error
@@ -90,14 +207,48 @@ func (g *GormJobDao) Release(ctx context.Context, jobId int64) error {
 // @receiver g
 // @param ctx
 // @param jobId
+// @param version 抢占时的版本号
 // @return error
-func (g *GormJobDao) UpdateUtime(ctx context.Context, jobId int64) error {
+func (g *GormJobDao) UpdateUtime(ctx context.Context, jobId int64, version int) error {
 	now := time.Now().UnixMilli()
-	return g.db.WithContext(ctx).Model(&Job{}).Where("id = ?", jobId).Updates(map[string]any{
+	return g.db.WithContext(ctx).Model(&Job{}).
+		Where("id = ? AND version = ?", jobId, version).Updates(map[string]any{
 		"utime": now,
 	}).Error
 }
 
+// @func: ReclaimStuck
+// @date: 2024-01-18 10:10:25
+// @brief: MySQL任务调度-回收续约超时的任务, 结点宕机后任务不会一直处于运行状态
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param deadline 最近一次续约早于该时间点视为结点已宕机
+// @return int64 回收的任务数
+// @return error
+func (g *GormJobDao) ReclaimStuck(ctx context.Context, deadline time.Time) (int64, error) {
+	updates := map[string]any{
+		"status": jobStatusWaiting,
+		// 版本号+1, 旧结点恢复后续约、释放都会失效
+		"version": gorm.Expr("`version` + 1"),
+		"utime":   time.Now().UnixMilli(),
+	}
+
+	res := g.db.WithContext(ctx).Model(&Job{}).
+		Where("status = ? AND utime < ?", jobStatusRunning, deadline.UnixMilli()).
+		Updates(updates)
+	if res.Error != nil {
+		return 0, res.Error
+	}
+	cnt := res.RowsAffected
+
+	// 分片同样需要回收, 交给其他结点重新抢占
+	res = g.db.WithContext(ctx).Model(&JobShard{}).
+		Where("status = ? AND utime < ?", shardStatusRunning, deadline.UnixMilli()).
+		Updates(updates)
+	return cnt + res.RowsAffected, res.Error
+}
+
 // @func: UpdateNextTime
 // @date: 2023-12-31 21:55:39
 // @brief: MySQL任务调度-更新下一次任务调度时间点
@@ -115,13 +266,658 @@ func (g *GormJobDao) UpdateNextTime(ctx context.Context, jobId int64, nextTime t
 	}).Error
 }
 
+// @func: MarkMisfire
+// @date: 2024-01-19 14:20:15
+// @brief: MySQL任务调度-记录错过的调度次数及待补跑次数
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param jobId
+// @param version 抢占时的版本号
+// @param missed 本次错过(不执行)的调度次数
+// @param catchUp 本次执行完毕后还需补跑的次数
+// @return error
+func (g *GormJobDao) MarkMisfire(ctx context.Context, jobId int64, version int, missed int, catchUp int) error {
+	return g.db.WithContext(ctx).Model(&Job{}).
+		Where("id = ? AND version = ?", jobId, version).Updates(map[string]any{
+		"misfire_cnt": gorm.Expr("`misfire_cnt` + ?", missed),
+		"catch_up":    catchUp,
+		"utime":       time.Now().UnixMilli(),
+	}).Error
+}
+
+// @func: SkipMisfire
+// @date: 2024-01-19 14:23:40
+// @brief: MySQL任务调度-丢弃错过的调度, 不执行直接释放并等待下一个调度时间点
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param jobId
+// @param version 抢占时的版本号
+// @param missed
+// @param nextTime
+// @return error
+func (g *GormJobDao) SkipMisfire(ctx context.Context, jobId int64, version int, missed int, nextTime time.Time) error {
+	return g.db.WithContext(ctx).Model(&Job{}).
+		Where("id = ? AND version = ? AND status = ?", jobId, version, jobStatusRunning).Updates(map[string]any{
+		"status":      jobStatusWaiting,
+		"misfire_cnt": gorm.Expr("`misfire_cnt` + ?", missed),
+		"catch_up":    0,
+		"next_time":   nextTime.UnixMilli(),
+		"utime":       time.Now().UnixMilli(),
+	}).Error
+}
+
+// @func: PreemptShard
+// @date: 2024-01-18 15:10:22
+// @brief: MySQL任务调度-分片抢占, 到期任务的本轮分片按需生成, 各结点以乐观锁抢占其中一个
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param accept 当前结点能否执行该任务
+// @return Job
+// @return JobShard
+// @return error
+func (g *GormJobDao) PreemptShard(ctx context.Context, accept func(job Job) bool) (Job, JobShard, error) {
+	now := time.Now().UnixMilli()
+
+	var res Job
+	var shard JobShard
+	err := g.scanDue(ctx, jobModeSharding, now, func(job Job) (bool, error) {
+		if !accept(job) {
+			return false, nil
+		}
+
+		var err error
+		shard, err = g.preemptShard(ctx, job, now)
+		switch err {
+		case nil:
+			res = job
+			return true, nil
+		case errNoWaitingShard:
+			// 本轮分片已被其他结点抢完, 等待执行完毕后进入下一轮
+			return false, nil
+		default:
+			return false, err
+		}
+	})
+	if err != nil {
+		return Job{}, JobShard{}, err
+	}
+
+	return res, shard, nil
+}
+
+// @func: preemptShard
+// @date: 2024-01-18 15:16:40
+// @brief: MySQL任务调度-抢占任务本轮的一个分片, 以任务的调度时间点作为轮次
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param job
+// @param now
+// @return JobShard
+// @return error
+func (g *GormJobDao) preemptShard(ctx context.Context, job Job, now int64) (JobShard, error) {
+	var cnt int64
+	err := g.db.WithContext(ctx).Model(&JobShard{}).
+		Where("job_id = ? AND round = ?", job.Id, job.NextTime).
+		Count(&cnt).Error
+	if err != nil {
+		return JobShard{}, err
+	}
+
+	if cnt == 0 {
+		shards := make([]JobShard, 0, job.ShardTotal)
+		for i := 0; i < job.ShardTotal; i++ {
+			shards = append(shards, JobShard{
+				JobId:      job.Id,
+				Round:      job.NextTime,
+				ShardIndex: i,
+				Utime:      now,
+				Ctime:      now,
+			})
+		}
+
+		// 多个结点同时生成时依赖唯一索引去重
+		err = g.db.WithContext(ctx).
+			Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&shards).Error
+		if err != nil {
+			return JobShard{}, err
+		}
+	}
+
+	for {
+		var shard JobShard
+		err = g.db.WithContext(ctx).
+			Where("job_id = ? AND round = ? AND status = ?", job.Id, job.NextTime, shardStatusWaiting).
+			First(&shard).Error
+		if err == gorm.ErrRecordNotFound {
+			return JobShard{}, errNoWaitingShard
+		}
+		if err != nil {
+			return JobShard{}, err
+		}
+
+		res := g.db.WithContext(ctx).Model(&JobShard{}).
+			Where("id = ? AND version = ?", shard.Id, shard.Version).Updates(map[string]any{
+			"status":  shardStatusRunning,
+			"version": shard.Version + 1,
+			"utime":   now,
+		})
+		if res.Error != nil {
+			return JobShard{}, res.Error
+		}
+
+		// 被其他结点抢先
+		if res.RowsAffected == 0 {
+			continue
+		}
+
+		shard.Version = shard.Version + 1
+		return shard, nil
+	}
+}
+
+// @func: UpdateShardUtime
+// @date: 2024-01-18 15:22:05
+// @brief: MySQL任务调度-分片续约
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param shardId
+// @param version 抢占时的版本号
+// @return error
+func (g *GormJobDao) UpdateShardUtime(ctx context.Context, shardId int64, version int) error {
+	return g.db.WithContext(ctx).Model(&JobShard{}).
+		Where("id = ? AND version = ?", shardId, version).Updates(map[string]any{
+		"utime": time.Now().UnixMilli(),
+	}).Error
+}
+
+// @func: FinishShard
+// @date: 2024-01-18 15:30:48
+// @brief: MySQL任务调度-分片执行完毕, 记录执行日志, 本轮最后一个分片完成时汇总结果并进入下一轮
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param exec
+// @param shard 抢占时的分片, 已被回收时只记录执行日志
+// @param nextTime
+// @return error
+func (g *GormJobDao) FinishShard(ctx context.Context, exec JobExecution, shard JobShard, nextTime time.Time) error {
+	now := time.Now().UnixMilli()
+	exec.Ctime = now
+
+	return g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 锁住任务, 多个分片同时完成时串行判断是否为本轮最后一个
+		var job Job
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", shard.JobId).First(&job).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Create(&exec).Error
+		if err != nil {
+			return err
+		}
+
+		res := tx.Model(&JobShard{}).
+			Where("id = ? AND version = ? AND status = ?", shard.Id, shard.Version, shardStatusRunning).
+			Updates(map[string]any{
+				"status": shardStatusFinished,
+				"result": exec.Result,
+				"utime":  now,
+			})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+
+		var unfinished int64
+		err = tx.Model(&JobShard{}).
+			Where("job_id = ? AND round = ? AND status <> ?", shard.JobId, shard.Round, shardStatusFinished).
+			Count(&unfinished).Error
+		if err != nil || unfinished > 0 {
+			return err
+		}
+
+		var failed int64
+		err = tx.Model(&JobShard{}).
+			Where("job_id = ? AND round = ? AND result = ?", shard.JobId, shard.Round, jobResultFailed).
+			Count(&failed).Error
+		if err != nil {
+			return err
+		}
+
+		updates := map[string]any{
+			"last_exec_time": shard.Round,
+			"last_result":    jobResultSuccess,
+			"last_error":     "",
+			"next_time":      nextTime.UnixMilli(),
+			"utime":          now,
+		}
+		if failed > 0 {
+			updates["last_result"] = jobResultFailed
+			updates["last_error"] = fmt.Sprintf("%d/%d个分片执行失败", failed, job.ShardTotal)
+		} else {
+			updates["last_success_time"] = shard.Round
+		}
+
+		// 轮次已被修改(如立即触发)时不再推进
+		return tx.Model(&Job{}).
+			Where("id = ? AND next_time = ?", shard.JobId, shard.Round).
+			Updates(updates).Error
+	})
+}
+
+// @func: FindBroadcast
+// @date: 2024-01-18 15:40:12
+// @brief: MySQL任务调度-查询所有等待调度的广播任务
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @return []Job
+// @return error
+func (g *GormJobDao) FindBroadcast(ctx context.Context) ([]Job, error) {
+	var jobs []Job
+	err := g.db.WithContext(ctx).
+		Where("mode = ? AND status = ?", jobModeBroadcast, jobStatusWaiting).
+		Find(&jobs).Error
+	return jobs, err
+}
+
+// @func: FinishBroadcast
+// @date: 2024-01-18 15:42:30
+// @brief: MySQL任务调度-广播任务在某个结点执行完毕, 记录执行日志并更新最近一次执行结果
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param exec
+// @return error
+func (g *GormJobDao) FinishBroadcast(ctx context.Context, exec JobExecution) error {
+	now := time.Now().UnixMilli()
+	exec.Ctime = now
+
+	return g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(&exec).Error
+		if err != nil {
+			return err
+		}
+
+		updates := map[string]any{
+			"last_exec_time": exec.StartTime,
+			"last_result":    exec.Result,
+			"last_error":     exec.Error,
+			"utime":          now,
+		}
+		if exec.Result == jobResultSuccess {
+			updates["last_success_time"] = exec.StartTime
+		}
+
+		// 各结点按本地时钟调度, 不修改下一次调度时间点
+		return tx.Model(&Job{}).Where("id = ?", exec.JobId).Updates(updates).Error
+	})
+}
+
+// @func: Insert
+// @date: 2024-01-16 14:20:08
+// @brief: 任务管理-新建任务及其上游依赖
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param job
+// @param upstreams
+// @return int64
+// @return error
+func (g *GormJobDao) Insert(ctx context.Context, job Job, upstreams []int64) (int64, error) {
+	now := time.Now().UnixMilli()
+	job.Ctime = now
+	job.Utime = now
+
+	err := g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(&job).Error
+		if err != nil {
+			return err
+		}
+
+		return g.insertUpstreams(tx, job.Id, upstreams, now)
+	})
+	if me, ok := err.(*mysql.MySQLError); ok {
+		const duplicateErr uint16 = 1062
+		if me.Number == duplicateErr {
+			return 0, ErrDuplicateJob
+		}
+	}
+
+	return job.Id, err
+}
+
+// @func: Update
+// @date: 2024-01-16 14:23:40
+// @brief: 任务管理-修改cron表达式及时区、执行器及其配置、重试策略、执行模式及亲和标签、错过策略、上游依赖, 同时更新下一次调度时间点
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param job
+// @param upstreams 覆盖原有的上游依赖
+// @return error
+func (g *GormJobDao) Update(ctx context.Context, job Job, upstreams []int64) error {
+	now := time.Now().UnixMilli()
+
+	return g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&Job{}).
+			Where("id = ?", job.Id).
+			Updates(map[string]any{
+				"expression":     job.Expression,
+				"time_zone":      job.TimeZone,
+				"executor_name":  job.ExecutorName,
+				"cfg":            job.Cfg,
+				"max_retries":    job.MaxRetries,
+				"retry_interval": job.RetryInterval,
+				"retry_cnt":      0,
+				"mode":           job.Mode,
+				"shard_total":    job.ShardTotal,
+				"affinity":       job.Affinity,
+				"misfire_policy": job.MisfirePolicy,
+				"misfire_cap":    job.MisfireCap,
+				"catch_up":       0,
+				"next_time":      job.NextTime,
+				"utime":          now,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return ErrRecordNotFound
+		}
+
+		err := tx.Where("job_id = ?", job.Id).Delete(&JobDependency{}).Error
+		if err != nil {
+			return err
+		}
+
+		return g.insertUpstreams(tx, job.Id, upstreams, now)
+	})
+}
+
+// @func: insertUpstreams
+// @date: 2024-01-19 10:30:15
+// @brief: 任务管理-写入上游依赖
+// @author: Kewin Li
+// @receiver g
+// @param tx
+// @param jobId
+// @param upstreams
+// @param now
+// @return error
+func (g *GormJobDao) insertUpstreams(tx *gorm.DB, jobId int64, upstreams []int64, now int64) error {
+	if len(upstreams) == 0 {
+		return nil
+	}
+
+	deps := make([]JobDependency, 0, len(upstreams))
+	for _, up := range upstreams {
+		deps = append(deps, JobDependency{
+			JobId:      jobId,
+			UpstreamId: up,
+			Ctime:      now,
+		})
+	}
+
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&deps).Error
+}
+
+// @func: Delete
+// @date: 2024-01-16 14:25:12
+// @brief: 任务管理-删除任务及其依赖关系
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param jobId
+// @return error
+func (g *GormJobDao) Delete(ctx context.Context, jobId int64) error {
+	return g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("id = ?", jobId).Delete(&Job{})
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return ErrRecordNotFound
+		}
+
+		// 下游任务不再依赖已删除的任务
+		return tx.Where("job_id = ? OR upstream_id = ?", jobId, jobId).Delete(&JobDependency{}).Error
+	})
+}
+
+// @func: Pause
+// @date: 2024-01-16 14:27:36
+// @brief: 任务管理-暂停调度, 正在运行的任务执行完毕后不再调度
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param jobId
+// @return error
+func (g *GormJobDao) Pause(ctx context.Context, jobId int64) error {
+	return g.updateStatus(ctx, jobId, []int{jobStatusWaiting, jobStatusRunning}, map[string]any{
+		"status": jobStatusPaused,
+	})
+}
+
+// @func: Resume
+// @date: 2024-01-16 14:29:03
+// @brief: 任务管理-恢复调度, 从当前时间重新计算下一次调度时间点, 不补跑暂停期间的调度
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param jobId
+// @param nextTime
+// @return error
+func (g *GormJobDao) Resume(ctx context.Context, jobId int64, nextTime time.Time) error {
+	return g.updateStatus(ctx, jobId, []int{jobStatusPaused}, map[string]any{
+		"status":    jobStatusWaiting,
+		"next_time": nextTime.UnixMilli(),
+		"retry_cnt": 0,
+		"catch_up":  0,
+	})
+}
+
+// @func: Trigger
+// @date: 2024-01-16 14:31:45
+// @brief: 任务管理-立即触发, 把下一次调度时间点提前到当前时间
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param jobId
+// @return error
+func (g *GormJobDao) Trigger(ctx context.Context, jobId int64) error {
+	return g.updateStatus(ctx, jobId, []int{jobStatusWaiting}, map[string]any{
+		"next_time": time.Now().UnixMilli(),
+	})
+}
+
+// @func: updateStatus
+// @date: 2024-01-16 14:33:20
+// @brief: 任务管理-仅在任务处于指定状态时更新
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param jobId
+// @param from 允许操作的状态
+// @param updates
+// @return error
+func (g *GormJobDao) updateStatus(ctx context.Context, jobId int64, from []int, updates map[string]any) error {
+	updates["utime"] = time.Now().UnixMilli()
+
+	res := g.db.WithContext(ctx).Model(&Job{}).
+		Where("id = ? AND status IN ?", jobId, from).
+		Updates(updates)
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrJobStatusMismatch
+	}
+
+	return nil
+}
+
+// @func: Finish
+// @date: 2024-01-17 10:20:42
+// @brief: MySQL任务调度-任务执行完毕, 记录执行日志并更新最近一次执行结果、重试次数、下一次调度时间点
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param exec
+// @param version 抢占时的版本号, 任务已被回收时只记录执行日志
+// @param retryCnt
+// @param catchUp 剩余待补跑的次数
+// @param nextTime
+// @return error
+func (g *GormJobDao) Finish(ctx context.Context, exec JobExecution, version int, retryCnt int, catchUp int, nextTime time.Time) error {
+	now := time.Now().UnixMilli()
+	exec.Ctime = now
+
+	return g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(&exec).Error
+		if err != nil {
+			return err
+		}
+
+		updates := map[string]any{
+			"last_exec_time": exec.StartTime,
+			"last_result":    exec.Result,
+			"last_error":     exec.Error,
+			"retry_cnt":      retryCnt,
+			"catch_up":       catchUp,
+			"next_time":      nextTime.UnixMilli(),
+			"utime":          now,
+		}
+		if exec.Result == jobResultSuccess {
+			updates["last_success_time"] = exec.StartTime
+		}
+
+		return tx.Model(&Job{}).Where("id = ? AND version = ?", exec.JobId, version).Updates(updates).Error
+	})
+}
+
+// @func: FindById
+// @date: 2024-01-16 14:38:15
+// @brief: 任务管理-查询任务
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param jobId
+// @return Job
+// @return error
+func (g *GormJobDao) FindById(ctx context.Context, jobId int64) (Job, error) {
+	var job Job
+	err := g.db.WithContext(ctx).Where("id = ?", jobId).First(&job).Error
+	return job, err
+}
+
+// @func: FindList
+// @date: 2024-01-16 14:39:47
+// @brief: 任务管理-分页查询任务列表
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param offset
+// @param limit
+// @return []Job
+// @return error
+func (g *GormJobDao) FindList(ctx context.Context, offset int, limit int) ([]Job, error) {
+	var jobs []Job
+	err := g.db.WithContext(ctx).
+		Order("id ASC").
+		Offset(offset).
+		Limit(limit).
+		Find(&jobs).Error
+	return jobs, err
+}
+
+// @func: FindByIds
+// @date: 2024-01-19 10:35:42
+// @brief: 任务管理-批量查询任务
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param jobIds
+// @return []Job
+// @return error
+func (g *GormJobDao) FindByIds(ctx context.Context, jobIds []int64) ([]Job, error) {
+	var jobs []Job
+	err := g.db.WithContext(ctx).Where("id IN ?", jobIds).Find(&jobs).Error
+	return jobs, err
+}
+
+// @func: FindDependencies
+// @date: 2024-01-19 10:37:10
+// @brief: 任务管理-查询全部依赖关系
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @return []JobDependency
+// @return error
+func (g *GormJobDao) FindDependencies(ctx context.Context) ([]JobDependency, error) {
+	var deps []JobDependency
+	err := g.db.WithContext(ctx).Find(&deps).Error
+	return deps, err
+}
+
+// @func: FindUpstreams
+// @date: 2024-01-19 10:38:26
+// @brief: 任务管理-查询指定任务的上游依赖
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param jobIds
+// @return []JobDependency
+// @return error
+func (g *GormJobDao) FindUpstreams(ctx context.Context, jobIds []int64) ([]JobDependency, error) {
+	var deps []JobDependency
+	err := g.db.WithContext(ctx).Where("job_id IN ?", jobIds).Find(&deps).Error
+	return deps, err
+}
+
+// @func: FindExecutions
+// @date: 2024-01-17 10:26:18
+// @brief: 任务管理-按时间倒序分页查询任务的执行记录
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param jobId
+// @param offset
+// @param limit
+// @return []JobExecution
+// @return error
+func (g *GormJobDao) FindExecutions(ctx context.Context, jobId int64, offset int, limit int) ([]JobExecution, error) {
+	var execs []JobExecution
+	err := g.db.WithContext(ctx).
+		Where("job_id = ?", jobId).
+		Order("id DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&execs).Error
+	return execs, err
+}
+
 type Job struct {
 	Id   int64  `gorm:"primaryKey, autoIncrement"`
 	Name string `gorm:"type:varchar(128);unique"`
 
 	Expression string `gorm:"type:varchar(128)"`
+	// cron表达式所在时区
+	TimeZone string `gorm:"type:varchar(64)"`
 
 	ExecutorName string `gorm:"type:varchar(128)"`
+	// 执行器配置
+	Cfg string `gorm:"type:text"`
 	// 当前任务的抢占状态
 	Status int
 	// 乐观锁 版本号
@@ -129,6 +925,81 @@ type Job struct {
 	// 任务下一次执行的时间点
 	NextTime int64 `gorm:"index"`
 
+	// 执行模式
+	Mode int
+	// 分片总数
+	ShardTotal int
+	// 亲和标签 JSON格式
+	Affinity string `gorm:"type:varchar(512)"`
+
+	// 错过调度的处理策略
+	MisfirePolicy int
+	// 补跑次数上限
+	MisfireCap int
+	// 剩余待补跑的次数
+	CatchUp int
+	// 累计错过的调度次数
+	MisfireCnt int64
+
+	// 重试策略
+	MaxRetries int
+	// 首次重试间隔 毫秒
+	RetryInterval int64
+	// 当前连续重试次数
+	RetryCnt int
+
+	// 最近一次执行情况
+	LastExecTime int64
+	LastResult   int
+	LastError    string `gorm:"type:varchar(1024)"`
+	// 最近一次执行成功的开始时间
+	LastSuccessTime int64
+
+	Utime int64
+	Ctime int64
+}
+
+// JobExecution
+// @Description: 任务执行日志表
+type JobExecution struct {
+	Id    int64 `gorm:"primaryKey, autoIncrement"`
+	JobId int64 `gorm:"index"`
+	// 执行任务的结点
+	Node       string `gorm:"type:varchar(128)"`
+	ShardIndex int
+	Attempt    int
+	Result     int
+	Error      string `gorm:"type:varchar(1024)"`
+
+	StartTime int64
+	EndTime   int64
+	Ctime     int64
+}
+
+// JobShard
+// @Description: 分片任务每一轮的分片表
+type JobShard struct {
+	Id    int64 `gorm:"primaryKey, autoIncrement"`
+	JobId int64 `gorm:"uniqueIndex:job_round_shard"`
+	// 轮次, 即该轮的调度时间点
+	Round      int64 `gorm:"uniqueIndex:job_round_shard"`
+	ShardIndex int   `gorm:"uniqueIndex:job_round_shard"`
+	// 分片的抢占状态
+	Status int `gorm:"index"`
+	// 乐观锁 版本号
+	Version int
+	Result  int
+
 	Utime int64
 	Ctime int64
 }
+
+// JobDependency
+// @Description: 任务依赖关系表
+type JobDependency struct {
+	Id    int64 `gorm:"primaryKey, autoIncrement"`
+	JobId int64 `gorm:"uniqueIndex:job_upstream"`
+	// 上游任务
+	UpstreamId int64 `gorm:"uniqueIndex:job_upstream;index"`
+	Ctime      int64
+}
@@ -0,0 +1,195 @@
+// Package dao
+// @Description: 单元测试-任务调度模块
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+// @func: TestGormJobDao_ReclaimStuck
+// @date: 2024-01-18 11:05:42
+// @brief: 单元测试-回收续约超时的任务
+// @author: Kewin Li
+// @param t
+func TestGormJobDao_ReclaimStuck(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock func(t *testing.T) *sql.DB
+
+		wantCnt int64
+		wantErr error
+	}{
+		{
+			name: "回收成功",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectExec("UPDATE `jobs` SET .*`version`=`version` \\+ 1.* WHERE status = \\? AND utime < \\?").
+					WithArgs(jobStatusWaiting, sqlmock.AnyArg(), jobStatusRunning, sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec("UPDATE `job_shards` SET .*`version`=`version` \\+ 1.* WHERE status = \\? AND utime < \\?").
+					WithArgs(jobStatusWaiting, sqlmock.AnyArg(), shardStatusRunning, sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				return db
+			},
+			wantCnt: 3,
+		},
+		{
+			name: "数据库错误",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectExec("UPDATE `jobs` SET .*").
+					WithArgs(jobStatusWaiting, sqlmock.AnyArg(), jobStatusRunning, sqlmock.AnyArg()).
+					WillReturnError(errors.New("数据库错误"))
+				return db
+			},
+			wantErr: errors.New("数据库错误"),
+		},
+		{
+			name: "回收分片失败",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectExec("UPDATE `jobs` SET .*").
+					WithArgs(jobStatusWaiting, sqlmock.AnyArg(), jobStatusRunning, sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec("UPDATE `job_shards` SET .*").
+					WithArgs(jobStatusWaiting, sqlmock.AnyArg(), shardStatusRunning, sqlmock.AnyArg()).
+					WillReturnError(errors.New("数据库错误"))
+				return db
+			},
+			wantCnt: 2,
+			wantErr: errors.New("数据库错误"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlDB := tc.mock(t)
+			defer sqlDB.Close()
+
+			db, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlDB,
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{
+				SkipDefaultTransaction: true,
+				DisableAutomaticPing:   true,
+			})
+			assert.NoError(t, err)
+
+			d := NewGormJobDao(db)
+			cnt, err := d.ReclaimStuck(context.Background(), time.Now().Add(-time.Minute))
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantCnt, cnt)
+		})
+	}
+}
+
+// @func: TestGormJobDao_Preempt
+// @date: 2024-01-20 15:20:36
+// @brief: 单元测试-抢占任务, 亲和标签不匹配的任务不会挡住后面的任务
+// @author: Kewin Li
+// @param t
+func TestGormJobDao_Preempt(t *testing.T) {
+	// 只接受没有亲和标签的任务
+	accept := func(job Job) bool {
+		return job.Affinity == ""
+	}
+
+	// 一页全是当前结点无法执行的任务
+	rejectedPage := func() *sqlmock.Rows {
+		rows := sqlmock.NewRows([]string{"id", "next_time", "version", "affinity"})
+		for i := 1; i <= preemptBatch; i++ {
+			rows.AddRow(i, 100, 1, `{"gpu":"true"}`)
+		}
+		return rows
+	}
+
+	testCases := []struct {
+		name string
+
+		mock func(t *testing.T) *sql.DB
+
+		wantId  int64
+		wantErr error
+	}{
+		{
+			name: "首页全部不匹配, 翻页后抢占成功",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectQuery("SELECT \\* FROM `jobs` WHERE .*ORDER BY next_time ASC, id ASC LIMIT 10").
+					WithArgs(sqlmock.AnyArg(), jobStatusWaiting, jobModeSingle).
+					WillReturnRows(rejectedPage())
+				mock.ExpectQuery("SELECT \\* FROM `jobs` WHERE .*\\(next_time > \\? OR \\(next_time = \\? AND id > \\?\\)\\).*ORDER BY next_time ASC, id ASC LIMIT 10").
+					WithArgs(sqlmock.AnyArg(), jobStatusWaiting, jobModeSingle, int64(100), int64(100), int64(preemptBatch)).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "next_time", "version", "affinity"}).
+						AddRow(11, 200, 3, ""))
+				mock.ExpectExec("UPDATE `jobs` SET .* WHERE id = \\? AND version = \\?").
+					WithArgs(jobStatusRunning, sqlmock.AnyArg(), 4, 11, 3).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				return db
+			},
+			wantId: 11,
+		},
+		{
+			name: "全部不匹配",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectQuery("SELECT \\* FROM `jobs` WHERE .*").
+					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnRows(rejectedPage())
+				mock.ExpectQuery("SELECT \\* FROM `jobs` WHERE .*").
+					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+						sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "next_time", "version", "affinity"}))
+				return db
+			},
+			wantErr: ErrRecordNotFound,
+		},
+		{
+			name: "数据库错误",
+			mock: func(t *testing.T) *sql.DB {
+				db, mock, err := sqlmock.New()
+				assert.NoError(t, err)
+				mock.ExpectQuery("SELECT \\* FROM `jobs` WHERE .*").
+					WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WillReturnError(errors.New("数据库错误"))
+				return db
+			},
+			wantErr: errors.New("数据库错误"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlDB := tc.mock(t)
+			defer sqlDB.Close()
+
+			db, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlDB,
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{
+				SkipDefaultTransaction: true,
+				DisableAutomaticPing:   true,
+			})
+			assert.NoError(t, err)
+
+			d := NewGormJobDao(db)
+			job, err := d.Preempt(context.Background(), accept)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantId, job.Id)
+		})
+	}
+}
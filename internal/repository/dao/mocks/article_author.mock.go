@@ -54,6 +54,36 @@ func (mr *MockArticleAuthorDaoMockRecorder) Create(ctx, art any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockArticleAuthorDao)(nil).Create), ctx, art)
 }
 
+// GetByAuthor mocks base method.
+func (m *MockArticleAuthorDao) GetByAuthor(ctx context.Context, userId int64, offset, limit int) ([]dao.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByAuthor", ctx, userId, offset, limit)
+	ret0, _ := ret[0].([]dao.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByAuthor indicates an expected call of GetByAuthor.
+func (mr *MockArticleAuthorDaoMockRecorder) GetByAuthor(ctx, userId, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByAuthor", reflect.TypeOf((*MockArticleAuthorDao)(nil).GetByAuthor), ctx, userId, offset, limit)
+}
+
+// GetById mocks base method.
+func (m *MockArticleAuthorDao) GetById(ctx context.Context, artId int64) (dao.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetById", ctx, artId)
+	ret0, _ := ret[0].(dao.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetById indicates an expected call of GetById.
+func (mr *MockArticleAuthorDaoMockRecorder) GetById(ctx, artId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockArticleAuthorDao)(nil).GetById), ctx, artId)
+}
+
 // Update mocks base method.
 func (m *MockArticleAuthorDao) Update(ctx context.Context, art dao.Article) error {
 	m.ctrl.T.Helper()
@@ -67,3 +97,17 @@ func (mr *MockArticleAuthorDaoMockRecorder) Update(ctx, art any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockArticleAuthorDao)(nil).Update), ctx, art)
 }
+
+// UpdateStatus mocks base method.
+func (m *MockArticleAuthorDao) UpdateStatus(ctx context.Context, artId, authorId int64, status uint8) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatus", ctx, artId, authorId, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateStatus indicates an expected call of UpdateStatus.
+func (mr *MockArticleAuthorDaoMockRecorder) UpdateStatus(ctx, artId, authorId, status any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockArticleAuthorDao)(nil).UpdateStatus), ctx, artId, authorId, status)
+}
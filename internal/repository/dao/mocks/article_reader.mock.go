@@ -12,6 +12,7 @@ import (
 	context "context"
 	dao "kitbook/internal/repository/dao"
 	reflect "reflect"
+	time "time"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -39,6 +40,50 @@ func (m *MockArticleReaderDao) EXPECT() *MockArticleReaderDaoMockRecorder {
 	return m.recorder
 }
 
+// GetPubById mocks base method.
+func (m *MockArticleReaderDao) GetPubById(ctx context.Context, artId int64) (dao.PublishedArticle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPubById", ctx, artId)
+	ret0, _ := ret[0].(dao.PublishedArticle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPubById indicates an expected call of GetPubById.
+func (mr *MockArticleReaderDaoMockRecorder) GetPubById(ctx, artId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPubById", reflect.TypeOf((*MockArticleReaderDao)(nil).GetPubById), ctx, artId)
+}
+
+// ListPub mocks base method.
+func (m *MockArticleReaderDao) ListPub(ctx context.Context, start time.Time, offset, limit int) ([]dao.PublishedArticle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPub", ctx, start, offset, limit)
+	ret0, _ := ret[0].([]dao.PublishedArticle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPub indicates an expected call of ListPub.
+func (mr *MockArticleReaderDaoMockRecorder) ListPub(ctx, start, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPub", reflect.TypeOf((*MockArticleReaderDao)(nil).ListPub), ctx, start, offset, limit)
+}
+
+// UpdateStatus mocks base method.
+func (m *MockArticleReaderDao) UpdateStatus(ctx context.Context, artId int64, status uint8) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatus", ctx, artId, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateStatus indicates an expected call of UpdateStatus.
+func (mr *MockArticleReaderDaoMockRecorder) UpdateStatus(ctx, artId, status any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockArticleReaderDao)(nil).UpdateStatus), ctx, artId, status)
+}
+
 // Upsert mocks base method.
 func (m *MockArticleReaderDao) Upsert(crx context.Context, art dao.Article) error {
 	m.ctrl.T.Helper()
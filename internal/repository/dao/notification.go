@@ -0,0 +1,75 @@
+package dao
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type NotificationDao interface {
+	Insert(ctx context.Context, n Notification) error
+	FindByUid(ctx context.Context, uid int64, end int64, limit int) ([]Notification, error)
+}
+
+type GormNotificationDao struct {
+	db *gorm.DB
+}
+
+func NewGormNotificationDao(db *gorm.DB) NotificationDao {
+	return &GormNotificationDao{
+		db: db,
+	}
+}
+
+// @func: Insert
+// @date: 2024-01-28 10:12:36
+// @brief: 站内通知-新增通知, 同一事件重复投递时忽略
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param n
+// @return error
+func (g *GormNotificationDao) Insert(ctx context.Context, n Notification) error {
+	n.Ctime = time.Now().UnixMilli()
+
+	return g.db.WithContext(ctx).Clauses(clause.OnConflict{
+		DoNothing: true,
+	}).Create(&n).Error
+}
+
+// @func: FindByUid
+// @date: 2024-01-28 10:15:02
+// @brief: 站内通知-按通知时间倒序分页查询
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param uid
+// @param end 查询的时间游标, 只查询该时间点之前的通知
+// @param limit
+// @return []Notification
+// @return error
+func (g *GormNotificationDao) FindByUid(ctx context.Context, uid int64, end int64, limit int) ([]Notification, error) {
+	var ns []Notification
+	err := g.db.WithContext(ctx).
+		Where("uid = ? AND ctime < ?", uid, end).
+		Order("ctime DESC").
+		Limit(limit).
+		Find(&ns).Error
+
+	return ns, err
+}
+
+// Notification
+// @Description: 站内通知表
+type Notification struct {
+	Id int64 `gorm:"primaryKey, autoIncrement"`
+	// 以用户ID为主字段查询
+	Uid   int64  `gorm:"uniqueIndex:uid_biz_id_etime;index:uid_ctime"`
+	Biz   string `gorm:"type:varchar(128);uniqueIndex:uid_biz_id_etime"`
+	BizId int64  `gorm:"uniqueIndex:uid_biz_id_etime"`
+	// 事件发生时间, 消息重复投递时靠唯一索引去重
+	Etime   int64  `gorm:"uniqueIndex:uid_biz_id_etime"`
+	Content string `gorm:"type:varchar(1024)"`
+	Ctime   int64  `gorm:"index:uid_ctime"`
+}
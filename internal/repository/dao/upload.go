@@ -0,0 +1,248 @@
+package dao
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// 上传状态, 与domain层保持一致
+const (
+	uploadStatusPending uint8 = iota + 1
+	uploadStatusReady
+)
+
+type UploadDao interface {
+	Insert(ctx context.Context, u Upload) (Upload, error)
+	FindById(ctx context.Context, id int64, userId int64) (Upload, error)
+	FindByUserHash(ctx context.Context, userId int64, hash string) (Upload, error)
+	FindReadyByHash(ctx context.Context, hash string) (Upload, error)
+	MarkReady(ctx context.Context, id int64, contentType string) error
+	SumSize(ctx context.Context, userId int64) (int64, error)
+	ListBefore(ctx context.Context, utimeBefore int64, afterId int64, limit int) ([]Upload, error)
+	Delete(ctx context.Context, id int64) error
+	CountByHash(ctx context.Context, hash string) (int64, error)
+	IsReferenced(ctx context.Context, userId int64, hash string) (bool, error)
+}
+
+// GormUploadDao
+// @Description: 上传记录, 每个用户的同一内容只有一条记录, 对象存储中同一内容只有一个对象
+type GormUploadDao struct {
+	db *gorm.DB
+}
+
+func NewGormUploadDao(db *gorm.DB) UploadDao {
+	return &GormUploadDao{
+		db: db,
+	}
+}
+
+// @func: Insert
+// @date: 2024-01-24 14:50:16
+// @brief: 上传记录-新增, 该用户已上传过相同内容时返回已有记录
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param u
+// @return Upload
+// @return error
+func (g *GormUploadDao) Insert(ctx context.Context, u Upload) (Upload, error) {
+	now := time.Now().UnixMilli()
+	u.Ctime = now
+	u.Utime = now
+
+	err := g.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "hash"}},
+		DoNothing: true,
+	}).Create(&u).Error
+	if err != nil {
+		return Upload{}, err
+	}
+
+	return g.FindByUserHash(ctx, u.UserId, u.Hash)
+}
+
+// @func: FindById
+// @date: 2024-01-24 14:51:40
+// @brief: 上传记录-按ID查询用户自己的记录
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param id
+// @param userId
+// @return Upload
+// @return error 不存在时返回ErrRecordNotFound
+func (g *GormUploadDao) FindById(ctx context.Context, id int64, userId int64) (Upload, error) {
+	var u Upload
+	err := g.db.WithContext(ctx).
+		Where("id = ? AND user_id = ?", id, userId).
+		First(&u).Error
+
+	return u, err
+}
+
+// @func: FindByUserHash
+// @date: 2024-01-24 14:52:52
+// @brief: 上传记录-查询用户上传过的某内容
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param userId
+// @param hash
+// @return Upload
+// @return error 不存在时返回ErrRecordNotFound
+func (g *GormUploadDao) FindByUserHash(ctx context.Context, userId int64, hash string) (Upload, error) {
+	var u Upload
+	err := g.db.WithContext(ctx).
+		Where("user_id = ? AND hash = ?", userId, hash).
+		First(&u).Error
+
+	return u, err
+}
+
+// @func: FindReadyByHash
+// @date: 2024-01-24 14:54:06
+// @brief: 上传记录-查询任意用户已就绪的某内容, 存在说明对象存储中已有该内容
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param hash
+// @return Upload
+// @return error 不存在时返回ErrRecordNotFound
+func (g *GormUploadDao) FindReadyByHash(ctx context.Context, hash string) (Upload, error) {
+	var u Upload
+	err := g.db.WithContext(ctx).
+		Where("hash = ? AND status = ?", hash, uploadStatusReady).
+		Order("id").
+		First(&u).Error
+
+	return u, err
+}
+
+// @func: MarkReady
+// @date: 2024-01-24 14:55:20
+// @brief: 上传记录-校验通过, 以检测到的类型为准
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param id
+// @param contentType
+// @return error
+func (g *GormUploadDao) MarkReady(ctx context.Context, id int64, contentType string) error {
+	return g.db.WithContext(ctx).Model(&Upload{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"status":       uploadStatusReady,
+			"content_type": contentType,
+			"utime":        time.Now().UnixMilli(),
+		}).Error
+}
+
+// @func: SumSize
+// @date: 2024-01-24 14:56:36
+// @brief: 上传记录-用户已用空间, 包含等待上传的记录
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param userId
+// @return int64
+// @return error
+func (g *GormUploadDao) SumSize(ctx context.Context, userId int64) (int64, error) {
+	var sum int64
+	err := g.db.WithContext(ctx).Model(&Upload{}).
+		Select("COALESCE(SUM(size), 0)").
+		Where("user_id = ?", userId).
+		Scan(&sum).Error
+
+	return sum, err
+}
+
+// @func: ListBefore
+// @date: 2024-01-24 14:58:02
+// @brief: 上传记录-按ID分页查询更新时间早于某时间点的记录, 用于清理
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param utimeBefore
+// @param afterId
+// @param limit
+// @return []Upload
+// @return error
+func (g *GormUploadDao) ListBefore(ctx context.Context, utimeBefore int64, afterId int64, limit int) ([]Upload, error) {
+	var res []Upload
+	err := g.db.WithContext(ctx).
+		Where("utime < ? AND id > ?", utimeBefore, afterId).
+		Order("id").
+		Limit(limit).
+		Find(&res).Error
+
+	return res, err
+}
+
+// @func: Delete
+// @date: 2024-01-24 14:59:16
+// @brief: 上传记录-删除
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param id
+// @return error
+func (g *GormUploadDao) Delete(ctx context.Context, id int64) error {
+	return g.db.WithContext(ctx).Where("id = ?", id).Delete(&Upload{}).Error
+}
+
+// @func: CountByHash
+// @date: 2024-01-24 15:00:30
+// @brief: 上传记录-引用某内容的记录数, 为0时对象可以删除
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param hash
+// @return int64
+// @return error
+func (g *GormUploadDao) CountByHash(ctx context.Context, hash string) (int64, error) {
+	var cnt int64
+	err := g.db.WithContext(ctx).Model(&Upload{}).
+		Where("hash = ?", hash).
+		Count(&cnt).Error
+
+	return cnt, err
+}
+
+// @func: IsReferenced
+// @date: 2024-01-24 15:02:48
+// @brief: 上传记录-用户的制作库、线上库、历史版本中是否还引用该文件, 历史版本可能被回滚因此也算引用
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param userId
+// @param hash
+// @return bool
+// @return error
+func (g *GormUploadDao) IsReferenced(ctx context.Context, userId int64, hash string) (bool, error) {
+	// 引用在保存帖子时写入引用表, 不扫描帖子正文
+	var ids []int64
+	err := g.db.WithContext(ctx).Model(&UploadRef{}).
+		Where("user_id = ? AND hash = ?", userId, hash).
+		Limit(1).
+		Pluck("id", &ids).Error
+
+	return len(ids) > 0, err
+}
+
+// Upload
+// @Description: 上传记录表
+type Upload struct {
+	Id     int64 `gorm:"primaryKey, autoIncrement"`
+	UserId int64 `gorm:"uniqueIndex:user_hash"`
+	// 内容SHA256, 也是对象存储的key
+	Hash        string `gorm:"type:char(64);uniqueIndex:user_hash;index"`
+	Size        int64
+	ContentType string `gorm:"type:varchar(128)"`
+	Filename    string `gorm:"type:varchar(256)"`
+	Kind        uint8
+	Status      uint8
+	Ctime       int64
+	Utime       int64 `gorm:"index"`
+}
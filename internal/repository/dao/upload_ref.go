@@ -0,0 +1,149 @@
+package dao
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// 引用文件的位置, 制作库、线上库、历史版本分开记录
+const (
+	uploadRefBizArticle    = "article"
+	uploadRefBizPubArticle = "published_article"
+	uploadRefBizRevision   = "article_revision"
+)
+
+type UploadRefDao interface {
+	SetArticleRefs(ctx context.Context, artId int64, userId int64, hashes []string) error
+	SyncArticleRefs(ctx context.Context, artId int64, userId int64, hashes []string) error
+	AddRevisionRefs(ctx context.Context, artId int64, userId int64, hashes []string) error
+}
+
+// GormUploadRefDao
+// @Description: 帖子引用的上传文件, 保存帖子时写入, 清理孤儿文件时按用户+哈希查询, 避免扫描帖子正文
+type GormUploadRefDao struct {
+	db *gorm.DB
+}
+
+func NewGormUploadRefDao(db *gorm.DB) UploadRefDao {
+	return &GormUploadRefDao{
+		db: db,
+	}
+}
+
+// @func: SetArticleRefs
+// @date: 2024-01-28 15:20:36
+// @brief: 上传引用-覆盖制作库帖子引用的文件
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param artId
+// @param userId
+// @param hashes
+// @return error
+func (g *GormUploadRefDao) SetArticleRefs(ctx context.Context, artId int64, userId int64, hashes []string) error {
+	return g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return g.setRefs(tx, uploadRefBizArticle, artId, userId, hashes)
+	})
+}
+
+// @func: SyncArticleRefs
+// @date: 2024-01-28 15:22:10
+// @brief: 上传引用-发表时同时覆盖制作库、线上库帖子引用的文件
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param artId
+// @param userId
+// @param hashes
+// @return error
+func (g *GormUploadRefDao) SyncArticleRefs(ctx context.Context, artId int64, userId int64, hashes []string) error {
+	return g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := g.setRefs(tx, uploadRefBizArticle, artId, userId, hashes)
+		if err != nil {
+			return err
+		}
+
+		return g.setRefs(tx, uploadRefBizPubArticle, artId, userId, hashes)
+	})
+}
+
+// @func: AddRevisionRefs
+// @date: 2024-01-28 15:23:45
+// @brief: 上传引用-历史版本只增不删, 历史版本可能被回滚, 引用过的文件一直保留
+// @author: Kewin Li
+// @receiver g
+// @param ctx
+// @param artId
+// @param userId
+// @param hashes
+// @return error
+func (g *GormUploadRefDao) AddRevisionRefs(ctx context.Context, artId int64, userId int64, hashes []string) error {
+	return g.addRefs(g.db.WithContext(ctx), uploadRefBizRevision, artId, userId, hashes)
+}
+
+// @func: setRefs
+// @date: 2024-01-28 15:25:02
+// @brief: 上传引用-先删除原有引用, 再写入新引用
+// @author: Kewin Li
+// @receiver g
+// @param tx
+// @param biz
+// @param artId
+// @param userId
+// @param hashes
+// @return error
+func (g *GormUploadRefDao) setRefs(tx *gorm.DB, biz string, artId int64, userId int64, hashes []string) error {
+	err := tx.Where("biz = ? AND art_id = ?", biz, artId).Delete(&UploadRef{}).Error
+	if err != nil {
+		return err
+	}
+
+	return g.addRefs(tx, biz, artId, userId, hashes)
+}
+
+// @func: addRefs
+// @date: 2024-01-28 15:26:18
+// @brief: 上传引用-写入引用, 已存在的忽略
+// @author: Kewin Li
+// @receiver g
+// @param tx
+// @param biz
+// @param artId
+// @param userId
+// @param hashes
+// @return error
+func (g *GormUploadRefDao) addRefs(tx *gorm.DB, biz string, artId int64, userId int64, hashes []string) error {
+	if len(hashes) == 0 {
+		return nil
+	}
+
+	now := time.Now().UnixMilli()
+	refs := make([]UploadRef, 0, len(hashes))
+	for _, hash := range hashes {
+		refs = append(refs, UploadRef{
+			UserId: userId,
+			Hash:   hash,
+			Biz:    biz,
+			ArtId:  artId,
+			Ctime:  now,
+		})
+	}
+
+	return tx.Clauses(clause.OnConflict{
+		DoNothing: true,
+	}).Create(&refs).Error
+}
+
+// UploadRef
+// @Description: 上传文件引用表, 一个帖子在制作库、线上库、历史版本中引用一个文件各一条记录
+type UploadRef struct {
+	Id    int64  `gorm:"primaryKey, autoIncrement"`
+	Biz   string `gorm:"type:varchar(32);uniqueIndex:biz_art_hash"`
+	ArtId int64  `gorm:"uniqueIndex:biz_art_hash"`
+	Hash  string `gorm:"type:char(64);uniqueIndex:biz_art_hash;index:user_hash"`
+	// 帖子作者, 文件只统计作者自己的引用
+	UserId int64 `gorm:"index:user_hash"`
+	Ctime  int64
+}
@@ -0,0 +1,160 @@
+// Package dao
+// @Description: 单元测试-上传文件引用
+package dao
+
+import (
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"strings"
+	"testing"
+)
+
+// @func: TestGormUploadRefDao_SyncArticleRefs
+// @date: 2024-01-28 16:05:20
+// @brief: 单元测试-发表时覆盖制作库、线上库的引用, 任一失败整体回滚
+// @author: Kewin Li
+// @param t
+func TestGormUploadRefDao_SyncArticleRefs(t *testing.T) {
+	hash := strings.Repeat("ab", 32)
+
+	testCases := []struct {
+		name string
+
+		mock   func(mock sqlmock.Sqlmock)
+		hashes []string
+
+		wantErr error
+	}{
+		{
+			name: "覆盖成功",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM `upload_refs` WHERE biz = \\? AND art_id = \\?").
+					WithArgs(uploadRefBizArticle, int64(1)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO `upload_refs`").
+					WithArgs(uploadRefBizArticle, int64(1), hash, int64(123), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("DELETE FROM `upload_refs` WHERE biz = \\? AND art_id = \\?").
+					WithArgs(uploadRefBizPubArticle, int64(1)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT INTO `upload_refs`").
+					WithArgs(uploadRefBizPubArticle, int64(1), hash, int64(123), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(2, 1))
+				mock.ExpectCommit()
+			},
+			hashes: []string{hash},
+		},
+		{
+			name: "不再引用文件, 只删除",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM `upload_refs`").
+					WithArgs(uploadRefBizArticle, int64(1)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("DELETE FROM `upload_refs`").
+					WithArgs(uploadRefBizPubArticle, int64(1)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "线上库写入失败, 回滚",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("DELETE FROM `upload_refs`").
+					WithArgs(uploadRefBizArticle, int64(1)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT INTO `upload_refs`").
+					WithArgs(uploadRefBizArticle, int64(1), hash, int64(123), sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("DELETE FROM `upload_refs`").
+					WithArgs(uploadRefBizPubArticle, int64(1)).
+					WillReturnError(errors.New("数据库错误"))
+				mock.ExpectRollback()
+			},
+			hashes:  []string{hash},
+			wantErr: errors.New("数据库错误"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlDB, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer sqlDB.Close()
+			tc.mock(mock)
+
+			db, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlDB,
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{
+				SkipDefaultTransaction: true,
+				DisableAutomaticPing:   true,
+			})
+			require.NoError(t, err)
+
+			err = NewGormUploadRefDao(db).SyncArticleRefs(context.Background(), 1, 123, tc.hashes)
+			assert.Equal(t, tc.wantErr, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// @func: TestGormUploadDao_IsReferenced
+// @date: 2024-01-28 16:10:42
+// @brief: 单元测试-按引用表判断文件是否还被作者引用
+// @author: Kewin Li
+// @param t
+func TestGormUploadDao_IsReferenced(t *testing.T) {
+	hash := strings.Repeat("ab", 32)
+
+	testCases := []struct {
+		name string
+
+		rows *sqlmock.Rows
+
+		want bool
+	}{
+		{
+			name: "仍被引用",
+			rows: sqlmock.NewRows([]string{"id"}).AddRow(1),
+			want: true,
+		},
+		{
+			name: "没有引用",
+			rows: sqlmock.NewRows([]string{"id"}),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sqlDB, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer sqlDB.Close()
+
+			mock.ExpectQuery("SELECT `id` FROM `upload_refs` WHERE user_id = \\? AND hash = \\? LIMIT 1").
+				WithArgs(int64(123), hash).
+				WillReturnRows(tc.rows)
+
+			db, err := gorm.Open(mysql.New(mysql.Config{
+				Conn:                      sqlDB,
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{
+				SkipDefaultTransaction: true,
+				DisableAutomaticPing:   true,
+			})
+			require.NoError(t, err)
+
+			ok, err := NewGormUploadDao(db).IsReferenced(context.Background(), 123, hash)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, ok)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"kitbook/internal/domain"
+	"kitbook/internal/repository/dao"
+	"time"
+)
+
+type FeedRepository interface {
+	CreatePushEvents(ctx context.Context, events []domain.FeedEvent) error
+	CreatePullEvent(ctx context.Context, event domain.FeedEvent) error
+	FindPushEvents(ctx context.Context, uid int64, end time.Time, limit int) ([]domain.FeedEvent, error)
+	FindPullEvents(ctx context.Context, authorIds []int64, end time.Time, limit int) ([]domain.FeedEvent, error)
+}
+
+type NormalFeedRepository struct {
+	dao dao.FeedDao
+}
+
+func NewNormalFeedRepository(dao dao.FeedDao) FeedRepository {
+	return &NormalFeedRepository{
+		dao: dao,
+	}
+}
+
+// @func: CreatePushEvents
+// @date: 2024-01-13 16:02:14
+// @brief: feed流-写入粉丝收件箱
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param events
+// @return error
+func (n *NormalFeedRepository) CreatePushEvents(ctx context.Context, events []domain.FeedEvent) error {
+	eventsDao := make([]dao.FeedPushEvent, 0, len(events))
+	for _, event := range events {
+		eventsDao = append(eventsDao, dao.FeedPushEvent{
+			Uid:      event.Uid,
+			ArtId:    event.ArtId,
+			AuthorId: event.AuthorId,
+			Ctime:    event.Ctime.UnixMilli(),
+		})
+	}
+
+	return n.dao.CreatePushEvents(ctx, eventsDao)
+}
+
+// @func: CreatePullEvent
+// @date: 2024-01-13 16:03:40
+// @brief: feed流-写入作者发件箱
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param event
+// @return error
+func (n *NormalFeedRepository) CreatePullEvent(ctx context.Context, event domain.FeedEvent) error {
+	return n.dao.CreatePullEvent(ctx, dao.FeedPullEvent{
+		ArtId:    event.ArtId,
+		AuthorId: event.AuthorId,
+		Ctime:    event.Ctime.UnixMilli(),
+	})
+}
+
+// @func: FindPushEvents
+// @date: 2024-01-13 16:05:21
+// @brief: feed流-查询收件箱
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param uid
+// @param end
+// @param limit
+// @return []domain.FeedEvent
+// @return error
+func (n *NormalFeedRepository) FindPushEvents(ctx context.Context, uid int64, end time.Time, limit int) ([]domain.FeedEvent, error) {
+	eventsDao, err := n.dao.FindPushEvents(ctx, uid, end.UnixMilli(), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]domain.FeedEvent, 0, len(eventsDao))
+	for _, event := range eventsDao {
+		events = append(events, domain.FeedEvent{
+			Id:       event.Id,
+			Uid:      event.Uid,
+			ArtId:    event.ArtId,
+			AuthorId: event.AuthorId,
+			Ctime:    time.UnixMilli(event.Ctime),
+		})
+	}
+
+	return events, nil
+}
+
+// @func: FindPullEvents
+// @date: 2024-01-13 16:07:02
+// @brief: feed流-查询作者发件箱
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param authorIds
+// @param end
+// @param limit
+// @return []domain.FeedEvent
+// @return error
+func (n *NormalFeedRepository) FindPullEvents(ctx context.Context, authorIds []int64, end time.Time, limit int) ([]domain.FeedEvent, error) {
+	eventsDao, err := n.dao.FindPullEvents(ctx, authorIds, end.UnixMilli(), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]domain.FeedEvent, 0, len(eventsDao))
+	for _, event := range eventsDao {
+		events = append(events, domain.FeedEvent{
+			Id:       event.Id,
+			ArtId:    event.ArtId,
+			AuthorId: event.AuthorId,
+			Ctime:    time.UnixMilli(event.Ctime),
+		})
+	}
+
+	return events, nil
+}
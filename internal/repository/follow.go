@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"context"
+	"kitbook/internal/domain"
+	"kitbook/internal/repository/cache"
+	"kitbook/internal/repository/dao"
+	"kitbook/pkg/logger"
+	"time"
+)
+
+var ErrRepeatFollow = dao.ErrRepeatFollow
+
+type FollowRepository interface {
+	Follow(ctx context.Context, follower int64, followee int64) error
+	Unfollow(ctx context.Context, follower int64, followee int64) error
+	GetFollowers(ctx context.Context, followee int64, maxId int64, limit int) ([]domain.FollowRelation, error)
+	GetFollowees(ctx context.Context, follower int64, maxId int64, limit int) ([]domain.FollowRelation, error)
+	IsFollowing(ctx context.Context, follower int64, followee int64) (bool, error)
+	GetStatics(ctx context.Context, uid int64) (domain.FollowStatics, error)
+}
+
+type CacheFollowRepository struct {
+	dao   dao.FollowDao
+	cache cache.FollowCache
+	l     logger.Logger
+}
+
+func NewCacheFollowRepository(dao dao.FollowDao, cache cache.FollowCache, l logger.Logger) FollowRepository {
+	return &CacheFollowRepository{
+		dao:   dao,
+		cache: cache,
+		l:     l,
+	}
+}
+
+// @func: Follow
+// @date: 2024-01-12 21:42:18
+// @brief: 关注
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param follower
+// @param followee
+// @return error
+func (c *CacheFollowRepository) Follow(ctx context.Context, follower int64, followee int64) error {
+	err := c.dao.Follow(ctx, follower, followee)
+	if err != nil {
+		return err
+	}
+
+	// 更新缓存, 部分失败问题不影响关注本身
+	err = c.cache.Follow(ctx, follower, followee)
+	if err != nil {
+		c.l.WARN("关注数缓存更新失败",
+			logger.Error(err),
+			logger.Int[int64]("follower", follower),
+			logger.Int[int64]("followee", followee))
+	}
+
+	return nil
+}
+
+// @func: Unfollow
+// @date: 2024-01-12 21:43:02
+// @brief: 取消关注
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param follower
+// @param followee
+// @return error
+func (c *CacheFollowRepository) Unfollow(ctx context.Context, follower int64, followee int64) error {
+	err := c.dao.Unfollow(ctx, follower, followee)
+	if err != nil {
+		return err
+	}
+
+	// 更新缓存, 部分失败问题不影响取消关注本身
+	err = c.cache.Unfollow(ctx, follower, followee)
+	if err != nil {
+		c.l.WARN("关注数缓存更新失败",
+			logger.Error(err),
+			logger.Int[int64]("follower", follower),
+			logger.Int[int64]("followee", followee))
+	}
+
+	return nil
+}
+
+// @func: GetFollowers
+// @date: 2024-01-12 21:44:37
+// @brief: 粉丝列表
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param followee
+// @param maxId
+// @param limit
+// @return []domain.FollowRelation
+// @return error
+func (c *CacheFollowRepository) GetFollowers(ctx context.Context, followee int64, maxId int64, limit int) ([]domain.FollowRelation, error) {
+	relations, err := c.dao.FollowerList(ctx, followee, maxId, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.ConvertsDomainFollowRelations(relations), nil
+}
+
+// @func: GetFollowees
+// @date: 2024-01-12 21:45:20
+// @brief: 关注列表
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param follower
+// @param maxId
+// @param limit
+// @return []domain.FollowRelation
+// @return error
+func (c *CacheFollowRepository) GetFollowees(ctx context.Context, follower int64, maxId int64, limit int) ([]domain.FollowRelation, error) {
+	relations, err := c.dao.FolloweeList(ctx, follower, maxId, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.ConvertsDomainFollowRelations(relations), nil
+}
+
+// @func: IsFollowing
+// @date: 2024-01-12 21:46:55
+// @brief: follower 是否关注了 followee
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param follower
+// @param followee
+// @return bool
+// @return error
+func (c *CacheFollowRepository) IsFollowing(ctx context.Context, follower int64, followee int64) (bool, error) {
+	_, err := c.dao.FollowRelationDetail(ctx, follower, followee)
+	switch err {
+	case nil:
+		return true, nil
+	case dao.ErrRecordNotFound:
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// @func: GetStatics
+// @date: 2024-01-12 21:48:13
+// @brief: 粉丝数、关注数聚合查询
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param uid
+// @return domain.FollowStatics
+// @return error
+func (c *CacheFollowRepository) GetStatics(ctx context.Context, uid int64) (domain.FollowStatics, error) {
+	// 1. 查缓存
+	statics, err := c.cache.GetStatics(ctx, uid)
+	if err == nil {
+		return statics, nil
+	}
+
+	// 2. 没缓存，查库
+	staticsDao, err := c.dao.GetStatics(ctx, uid)
+	switch err {
+	case nil:
+	case dao.ErrRecordNotFound:
+		// 没有任何关注关系
+	default:
+		return domain.FollowStatics{}, err
+	}
+
+	statics = domain.FollowStatics{
+		Uid:       uid,
+		Followers: staticsDao.Followers,
+		Followees: staticsDao.Followees,
+	}
+
+	// 3. 缓存回写
+	go func() {
+		ctx2, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		err2 := c.cache.SetStatics(ctx2, statics)
+		if err2 != nil {
+			c.l.WARN("关注数缓存回写失败",
+				logger.Error(err2),
+				logger.Int[int64]("uid", uid))
+		}
+	}()
+
+	return statics, nil
+}
+
+// @func: ConvertsDomainFollowRelations
+// @date: 2024-01-12 21:52:40
+// @brief: FollowRelation DAO--->Domain
+// @author: Kewin Li
+// @receiver c
+// @param relations
+// @return []domain.FollowRelation
+func (c *CacheFollowRepository) ConvertsDomainFollowRelations(relations []dao.FollowRelation) []domain.FollowRelation {
+	res := make([]domain.FollowRelation, 0, len(relations))
+	for _, relation := range relations {
+		res = append(res, domain.FollowRelation{
+			Id:       relation.Id,
+			Follower: relation.Follower,
+			Followee: relation.Followee,
+			Ctime:    time.UnixMilli(relation.Ctime),
+		})
+	}
+
+	return res
+}
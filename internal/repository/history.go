@@ -3,8 +3,130 @@ package repository
 import (
 	"context"
 	"kitbook/internal/domain"
+	"kitbook/internal/repository/dao"
+	"time"
 )
 
 type HistoryRepository interface {
 	AddRecord(ctx context.Context, record domain.HistoryRecord) error
+	BatchAddRecord(ctx context.Context, records []domain.HistoryRecord) error
+	GetByUser(ctx context.Context, userId int64, biz string, end time.Time, endId int64, limit int) ([]domain.HistoryRecord, error)
+	Clear(ctx context.Context, userId int64, biz string) error
+}
+
+type NormalHistoryRepository struct {
+	dao dao.HistoryDao
+}
+
+func NewNormalHistoryRepository(dao dao.HistoryDao) HistoryRepository {
+	return &NormalHistoryRepository{
+		dao: dao,
+	}
+}
+
+// @func: AddRecord
+// @date: 2024-01-06 15:40:12
+// @brief: 浏览记录-新增一条记录
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param record
+// @return error
+func (n *NormalHistoryRepository) AddRecord(ctx context.Context, record domain.HistoryRecord) error {
+	return n.dao.Upsert(ctx, n.ConvertsDaoHistoryRecord(&record))
+}
+
+// @func: BatchAddRecord
+// @date: 2024-01-06 15:41:37
+// @brief: 浏览记录-批量新增记录
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param records
+// @return error
+func (n *NormalHistoryRepository) BatchAddRecord(ctx context.Context, records []domain.HistoryRecord) error {
+	recordsDao := make([]dao.HistoryRecord, 0, len(records))
+	for _, record := range records {
+		recordsDao = append(recordsDao, n.ConvertsDaoHistoryRecord(&record))
+	}
+
+	return n.dao.BatchUpsert(ctx, recordsDao)
+}
+
+// @func: GetByUser
+// @date: 2024-01-06 15:43:55
+// @brief: 浏览记录-按浏览时间倒序分页查询
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param userId
+// @param biz
+// @param end
+// @param endId
+// @param limit
+// @return []domain.HistoryRecord
+// @return error
+func (n *NormalHistoryRepository) GetByUser(ctx context.Context, userId int64, biz string, end time.Time, endId int64, limit int) ([]domain.HistoryRecord, error) {
+	recordsDao, err := n.dao.GetByUser(ctx, userId, biz, end.UnixMilli(), endId, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]domain.HistoryRecord, 0, len(recordsDao))
+	for _, record := range recordsDao {
+		records = append(records, n.ConvertsDomainHistoryRecord(&record))
+	}
+
+	return records, nil
+}
+
+// @func: Clear
+// @date: 2024-01-06 15:46:20
+// @brief: 浏览记录-清空用户浏览记录
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param userId
+// @param biz
+// @return error
+func (n *NormalHistoryRepository) Clear(ctx context.Context, userId int64, biz string) error {
+	return n.dao.DelByUser(ctx, userId, biz)
+}
+
+// @func: ConvertsDomainHistoryRecord
+// @date: 2024-01-06 15:47:02
+// @brief: HistoryRecord DAO--->Domain
+// @author: Kewin Li
+// @receiver n
+// @param record
+// @return domain.HistoryRecord
+func (n *NormalHistoryRepository) ConvertsDomainHistoryRecord(record *dao.HistoryRecord) domain.HistoryRecord {
+	return domain.HistoryRecord{
+		Id:     record.Id,
+		BizId:  record.BizId,
+		Biz:    record.Biz,
+		UserId: record.UserId,
+		Utime:  time.UnixMilli(record.Utime),
+	}
+}
+
+// @func: ConvertsDaoHistoryRecord
+// @date: 2024-01-06 15:47:40
+// @brief: HistoryRecord Domain--->DAO
+// @author: Kewin Li
+// @receiver n
+// @param record
+// @return dao.HistoryRecord
+func (n *NormalHistoryRepository) ConvertsDaoHistoryRecord(record *domain.HistoryRecord) dao.HistoryRecord {
+	var utime int64
+	if !record.Utime.IsZero() {
+		utime = record.Utime.UnixMilli()
+	}
+
+	return dao.HistoryRecord{
+		BizId:  record.BizId,
+		Biz:    record.Biz,
+		UserId: record.UserId,
+		Utime:  utime,
+	}
 }
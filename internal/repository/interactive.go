@@ -337,6 +337,7 @@ func (a *ArticleInteractiveRepository) ConvertsDomainInteractive(i *dao.Interact
 		ReadCnt:    i.ReadCnt,
 		LikeCnt:    i.LikeCnt,
 		CollectCnt: i.CollectCnt,
+		CommentCnt: i.CommentCnt,
 	}
 }
 
@@ -353,5 +354,6 @@ func (a *ArticleInteractiveRepository) ConvertsDaoInteractive(i *domain.Interact
 		ReadCnt:    i.ReadCnt,
 		LikeCnt:    i.LikeCnt,
 		CollectCnt: i.CollectCnt,
+		CommentCnt: i.CommentCnt,
 	}
 }
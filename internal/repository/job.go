@@ -2,38 +2,108 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"kitbook/internal/domain"
+	"kitbook/internal/repository/cache"
 	"kitbook/internal/repository/dao"
 	"time"
 )
 
+var (
+	ErrDuplicateJob      = dao.ErrDuplicateJob
+	ErrJobNotFound       = dao.ErrRecordNotFound
+	ErrJobStatusMismatch = dao.ErrJobStatusMismatch
+	ErrCallbackNotFound  = cache.ErrKeyNotExist
+	ErrInvalidCallback   = cache.ErrInvalidCallbackToken
+)
+
 type JobRepository interface {
-	Preempt(ctx context.Context) (domain.Job, error)
-	Release(ctx context.Context, jobId int64) error
-	UpdateUtime(ctx context.Context, jobId int64) error
+	Preempt(ctx context.Context, labels map[string]string) (domain.Job, error)
+	Release(ctx context.Context, jobId int64, version int) error
+	UpdateUtime(ctx context.Context, jobId int64, version int) error
+	ReclaimStuck(ctx context.Context, deadline time.Time) (int64, error)
 	UpdateNextTime(ctx context.Context, jobId int64, nextTime time.Time) error
+	MarkMisfire(ctx context.Context, jobId int64, version int, missed int, catchUp int) error
+	SkipMisfire(ctx context.Context, jobId int64, version int, missed int, nextTime time.Time) error
+
+	// 分片、广播模式
+	PreemptShard(ctx context.Context, labels map[string]string) (domain.Job, error)
+	UpdateShardUtime(ctx context.Context, shardId int64, version int) error
+	FinishShard(ctx context.Context, exec domain.JobExecution, job domain.Job, nextTime time.Time) error
+	ListBroadcast(ctx context.Context) ([]domain.Job, error)
+	FinishBroadcast(ctx context.Context, exec domain.JobExecution) error
+
+	Create(ctx context.Context, job domain.Job) (int64, error)
+	Update(ctx context.Context, job domain.Job) error
+	Delete(ctx context.Context, jobId int64) error
+	Pause(ctx context.Context, jobId int64) error
+	Resume(ctx context.Context, jobId int64, nextTime time.Time) error
+	Trigger(ctx context.Context, jobId int64) error
+	Finish(ctx context.Context, exec domain.JobExecution, version int, retryCnt int, catchUp int, nextTime time.Time) error
+	FindById(ctx context.Context, jobId int64) (domain.Job, error)
+	List(ctx context.Context, offset int, limit int) ([]domain.Job, error)
+	FindByIds(ctx context.Context, jobIds []int64) ([]domain.Job, error)
+	// 全部任务的依赖关系
+	Dependencies(ctx context.Context) (domain.JobDAG, error)
+	ListExecutions(ctx context.Context, jobId int64, offset int, limit int) ([]domain.JobExecution, error)
+
+	// 远程任务回调
+	RegisterCallback(ctx context.Context, token string, expiration time.Duration) error
+	SaveCallback(ctx context.Context, cb domain.JobCallback) error
+	GetCallback(ctx context.Context, token string) (domain.JobCallback, error)
+
+	// 调度结点负载
+	ReportNode(ctx context.Context, node domain.JobNode) error
+	ListNodes(ctx context.Context) ([]domain.JobNode, error)
 }
 
 type PreemptJobRepository struct {
-	dao dao.JobDao
+	dao       dao.JobDao
+	cache     cache.JobCallbackCache
+	nodeCache cache.JobNodeCache
+}
+
+func NewPreemptJobRepository(dao dao.JobDao, cache cache.JobCallbackCache, nodeCache cache.JobNodeCache) JobRepository {
+	return &PreemptJobRepository{
+		dao:       dao,
+		cache:     cache,
+		nodeCache: nodeCache,
+	}
 }
 
-func (p *PreemptJobRepository) Preempt(ctx context.Context) (domain.Job, error) {
-	job, err := p.dao.Preempt(ctx)
+// @func: Preempt
+// @date: 2024-01-20 10:30:12
+// @brief: MySQL任务调度-抢占满足结点亲和标签的任务
+// @author: Kewin Li
+// @receiver p
+// @param ctx
+// @param labels 当前结点标签
+// @return domain.Job
+// @return error
+func (p *PreemptJobRepository) Preempt(ctx context.Context, labels map[string]string) (domain.Job, error) {
+	job, err := p.dao.Preempt(ctx, p.acceptFunc(labels))
 	if err != nil {
 		return domain.Job{}, err
 	}
-	return domain.Job{
-		Id:           job.Id,
-		Expression:   job.Expression,
-		Name:         job.Name,
-		ExecutorName: job.ExecutorName,
-	}, err
+	return p.ConvertsDomainJob(&job), err
+
+}
 
+// @func: acceptFunc
+// @date: 2024-01-20 10:32:48
+// @brief: MySQL任务调度-按结点标签过滤可抢占的任务
+// @author: Kewin Li
+// @receiver p
+// @param labels
+// @return func(job dao.Job) bool
+func (p *PreemptJobRepository) acceptFunc(labels map[string]string) func(job dao.Job) bool {
+	return func(job dao.Job) bool {
+		return p.ConvertsDomainJob(&job).MatchLabels(labels)
+	}
 }
 
-func (p *PreemptJobRepository) Release(ctx context.Context, jobId int64) error {
-	return p.dao.Release(ctx, jobId)
+func (p *PreemptJobRepository) Release(ctx context.Context, jobId int64, version int) error {
+	return p.dao.Release(ctx, jobId, version)
 }
 
 // @func: UpdateUtime
@@ -42,9 +112,23 @@ func (p *PreemptJobRepository) Release(ctx context.Context, jobId int64) error {
 // @author: Kewin Li
 // @receiver p
 // @param jobId
+// @param version
+// @return error
+func (p *PreemptJobRepository) UpdateUtime(ctx context.Context, jobId int64, version int) error {
+	return p.dao.UpdateUtime(ctx, jobId, version)
+}
+
+// @func: ReclaimStuck
+// @date: 2024-01-18 10:18:40
+// @brief: MySQL任务调度-回收续约超时的任务
+// @author: Kewin Li
+// @receiver p
+// @param ctx
+// @param deadline
+// @return int64
 // @return error
-func (p *PreemptJobRepository) UpdateUtime(ctx context.Context, jobId int64) error {
-	return p.dao.UpdateUtime(ctx, jobId)
+func (p *PreemptJobRepository) ReclaimStuck(ctx context.Context, deadline time.Time) (int64, error) {
+	return p.dao.ReclaimStuck(ctx, deadline)
 }
 
 // @func: UpdateNextTime
@@ -59,3 +143,530 @@ func (p *PreemptJobRepository) UpdateUtime(ctx context.Context, jobId int64) err
 func (p *PreemptJobRepository) UpdateNextTime(ctx context.Context, jobId int64, nextTime time.Time) error {
 	return p.dao.UpdateNextTime(ctx, jobId, nextTime)
 }
+
+// @func: MarkMisfire
+// @date: 2024-01-19 14:30:05
+// @brief: MySQL任务调度-记录错过的调度次数及待补跑次数
+// @author: Kewin Li
+// @receiver p
+// @param ctx
+// @param jobId
+// @param version
+// @param missed
+// @param catchUp
+// @return error
+func (p *PreemptJobRepository) MarkMisfire(ctx context.Context, jobId int64, version int, missed int, catchUp int) error {
+	return p.dao.MarkMisfire(ctx, jobId, version, missed, catchUp)
+}
+
+// @func: SkipMisfire
+// @date: 2024-01-19 14:31:12
+// @brief: MySQL任务调度-丢弃错过的调度并释放任务
+// @author: Kewin Li
+// @receiver p
+// @param ctx
+// @param jobId
+// @param version
+// @param missed
+// @param nextTime
+// @return error
+func (p *PreemptJobRepository) SkipMisfire(ctx context.Context, jobId int64, version int, missed int, nextTime time.Time) error {
+	return p.dao.SkipMisfire(ctx, jobId, version, missed, nextTime)
+}
+
+// @func: PreemptShard
+// @date: 2024-01-18 15:50:06
+// @brief: MySQL任务调度-抢占分片任务本轮的一个分片
+// @author: Kewin Li
+// @receiver p
+// @param ctx
+// @param labels 当前结点标签
+// @return domain.Job 已填充本次执行的分片
+// @return error
+func (p *PreemptJobRepository) PreemptShard(ctx context.Context, labels map[string]string) (domain.Job, error) {
+	job, shard, err := p.dao.PreemptShard(ctx, p.acceptFunc(labels))
+	if err != nil {
+		return domain.Job{}, err
+	}
+
+	res := p.ConvertsDomainJob(&job)
+	res.Shard = domain.JobShard{
+		Id:      shard.Id,
+		Index:   shard.ShardIndex,
+		Version: shard.Version,
+		Round:   time.UnixMilli(shard.Round),
+	}
+	return res, nil
+}
+
+// @func: UpdateShardUtime
+// @date: 2024-01-18 15:51:20
+// @brief: MySQL任务调度-分片续约
+// @author: Kewin Li
+// @receiver p
+// @param ctx
+// @param shardId
+// @param version
+// @return error
+func (p *PreemptJobRepository) UpdateShardUtime(ctx context.Context, shardId int64, version int) error {
+	return p.dao.UpdateShardUtime(ctx, shardId, version)
+}
+
+// @func: FinishShard
+// @date: 2024-01-18 15:52:44
+// @brief: MySQL任务调度-分片执行完毕
+// @author: Kewin Li
+// @receiver p
+// @param ctx
+// @param exec
+// @param job 已填充本次执行的分片
+// @param nextTime 本轮全部完成后的下一次调度时间点
+// @return error
+func (p *PreemptJobRepository) FinishShard(ctx context.Context, exec domain.JobExecution, job domain.Job, nextTime time.Time) error {
+	return p.dao.FinishShard(ctx, p.convertsDaoExecution(&exec), dao.JobShard{
+		Id:         job.Shard.Id,
+		JobId:      job.Id,
+		Round:      job.Shard.Round.UnixMilli(),
+		ShardIndex: job.Shard.Index,
+		Version:    job.Shard.Version,
+	}, nextTime)
+}
+
+// @func: ListBroadcast
+// @date: 2024-01-18 15:54:10
+// @brief: MySQL任务调度-查询等待调度的广播任务
+// @author: Kewin Li
+// @receiver p
+// @param ctx
+// @return []domain.Job
+// @return error
+func (p *PreemptJobRepository) ListBroadcast(ctx context.Context) ([]domain.Job, error) {
+	jobs, err := p.dao.FindBroadcast(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]domain.Job, 0, len(jobs))
+	for _, job := range jobs {
+		res = append(res, p.ConvertsDomainJob(&job))
+	}
+
+	return res, nil
+}
+
+// @func: FinishBroadcast
+// @date: 2024-01-18 15:55:32
+// @brief: MySQL任务调度-广播任务在当前结点执行完毕
+// @author: Kewin Li
+// @receiver p
+// @param ctx
+// @param exec
+// @return error
+func (p *PreemptJobRepository) FinishBroadcast(ctx context.Context, exec domain.JobExecution) error {
+	return p.dao.FinishBroadcast(ctx, p.convertsDaoExecution(&exec))
+}
+
+// @func: Create
+// @date: 2024-01-16 14:50:12
+// @brief: 任务管理-新建任务
+// @author: Kewin Li
+// @receiver p
+// @param ctx
+// @param job
+// @return int64
+// @return error
+func (p *PreemptJobRepository) Create(ctx context.Context, job domain.Job) (int64, error) {
+	return p.dao.Insert(ctx, p.ConvertsDaoJob(&job), job.Upstreams)
+}
+
+// @func: Update
+// @date: 2024-01-16 14:51:03
+// @brief: 任务管理-修改任务
+// @author: Kewin Li
+// @receiver p
+// @param ctx
+// @param job
+// @return error
+func (p *PreemptJobRepository) Update(ctx context.Context, job domain.Job) error {
+	return p.dao.Update(ctx, p.ConvertsDaoJob(&job), job.Upstreams)
+}
+
+// @func: Delete
+// @date: 2024-01-16 14:51:40
+// @brief: 任务管理-删除任务
+// @author: Kewin Li
+// @receiver p
+// @param ctx
+// @param jobId
+// @return error
+func (p *PreemptJobRepository) Delete(ctx context.Context, jobId int64) error {
+	return p.dao.Delete(ctx, jobId)
+}
+
+// @func: Pause
+// @date: 2024-01-16 14:52:18
+// @brief: 任务管理-暂停调度
+// @author: Kewin Li
+// @receiver p
+// @param ctx
+// @param jobId
+// @return error
+func (p *PreemptJobRepository) Pause(ctx context.Context, jobId int64) error {
+	return p.dao.Pause(ctx, jobId)
+}
+
+// @func: Resume
+// @date: 2024-01-16 14:52:55
+// @brief: 任务管理-恢复调度
+// @author: Kewin Li
+// @receiver p
+// @param ctx
+// @param jobId
+// @param nextTime
+// @return error
+func (p *PreemptJobRepository) Resume(ctx context.Context, jobId int64, nextTime time.Time) error {
+	return p.dao.Resume(ctx, jobId, nextTime)
+}
+
+// @func: Trigger
+// @date: 2024-01-16 14:53:30
+// @brief: 任务管理-立即触发
+// @author: Kewin Li
+// @receiver p
+// @param ctx
+// @param jobId
+// @return error
+func (p *PreemptJobRepository) Trigger(ctx context.Context, jobId int64) error {
+	return p.dao.Trigger(ctx, jobId)
+}
+
+// @func: Finish
+// @date: 2024-01-17 10:35:02
+// @brief: MySQL任务调度-记录执行日志并更新下一次调度时间点
+// @author: Kewin Li
+// @receiver p
+// @param ctx
+// @param exec
+// @param version
+// @param retryCnt
+// @param catchUp
+// @param nextTime
+// @return error
+func (p *PreemptJobRepository) Finish(ctx context.Context, exec domain.JobExecution, version int, retryCnt int, catchUp int, nextTime time.Time) error {
+	return p.dao.Finish(ctx, p.convertsDaoExecution(&exec), version, retryCnt, catchUp, nextTime)
+}
+
+// @func: FindById
+// @date: 2024-01-16 14:54:47
+// @brief: 任务管理-查询任务
+// @author: Kewin Li
+// @receiver p
+// @param ctx
+// @param jobId
+// @return domain.Job
+// @return error
+func (p *PreemptJobRepository) FindById(ctx context.Context, jobId int64) (domain.Job, error) {
+	job, err := p.dao.FindById(ctx, jobId)
+	if err != nil {
+		return domain.Job{}, err
+	}
+
+	res, err := p.withUpstreams(ctx, []dao.Job{job})
+	if err != nil {
+		return domain.Job{}, err
+	}
+
+	return res[0], nil
+}
+
+// @func: List
+// @date: 2024-01-16 14:55:21
+// @brief: 任务管理-分页查询任务列表
+// @author: Kewin Li
+// @receiver p
+// @param ctx
+// @param offset
+// @param limit
+// @return []domain.Job
+// @return error
+func (p *PreemptJobRepository) List(ctx context.Context, offset int, limit int) ([]domain.Job, error) {
+	jobs, err := p.dao.FindList(ctx, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.withUpstreams(ctx, jobs)
+}
+
+// @func: FindByIds
+// @date: 2024-01-19 10:45:20
+// @brief: 任务管理-批量查询任务, 包含上游依赖
+// @author: Kewin Li
+// @receiver p
+// @param ctx
+// @param jobIds
+// @return []domain.Job
+// @return error
+func (p *PreemptJobRepository) FindByIds(ctx context.Context, jobIds []int64) ([]domain.Job, error) {
+	jobs, err := p.dao.FindByIds(ctx, jobIds)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.withUpstreams(ctx, jobs)
+}
+
+// @func: Dependencies
+// @date: 2024-01-19 10:47:05
+// @brief: 任务管理-查询全部任务的依赖关系
+// @author: Kewin Li
+// @receiver p
+// @param ctx
+// @return domain.JobDAG
+// @return error
+func (p *PreemptJobRepository) Dependencies(ctx context.Context) (domain.JobDAG, error) {
+	deps, err := p.dao.FindDependencies(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	dag := domain.JobDAG{}
+	for _, dep := range deps {
+		dag[dep.JobId] = append(dag[dep.JobId], dep.UpstreamId)
+	}
+
+	return dag, nil
+}
+
+// @func: withUpstreams
+// @date: 2024-01-19 10:49:38
+// @brief: 任务管理-转换为领域对象并填充上游依赖
+// @author: Kewin Li
+// @receiver p
+// @param ctx
+// @param jobs
+// @return []domain.Job
+// @return error
+func (p *PreemptJobRepository) withUpstreams(ctx context.Context, jobs []dao.Job) ([]domain.Job, error) {
+	if len(jobs) == 0 {
+		return []domain.Job{}, nil
+	}
+
+	ids := make([]int64, 0, len(jobs))
+	for _, job := range jobs {
+		ids = append(ids, job.Id)
+	}
+
+	deps, err := p.dao.FindUpstreams(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	upstreams := make(map[int64][]int64, len(jobs))
+	for _, dep := range deps {
+		upstreams[dep.JobId] = append(upstreams[dep.JobId], dep.UpstreamId)
+	}
+
+	res := make([]domain.Job, 0, len(jobs))
+	for _, job := range jobs {
+		dj := p.ConvertsDomainJob(&job)
+		dj.Upstreams = upstreams[job.Id]
+		res = append(res, dj)
+	}
+
+	return res, nil
+}
+
+// @func: ListExecutions
+// @date: 2024-01-17 10:37:45
+// @brief: 任务管理-分页查询任务的执行记录
+// @author: Kewin Li
+// @receiver p
+// @param ctx
+// @param jobId
+// @param offset
+// @param limit
+// @return []domain.JobExecution
+// @return error
+func (p *PreemptJobRepository) ListExecutions(ctx context.Context, jobId int64, offset int, limit int) ([]domain.JobExecution, error) {
+	execs, err := p.dao.FindExecutions(ctx, jobId, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]domain.JobExecution, 0, len(execs))
+	for _, exec := range execs {
+		res = append(res, domain.JobExecution{
+			Id:         exec.Id,
+			JobId:      exec.JobId,
+			Node:       exec.Node,
+			ShardIndex: exec.ShardIndex,
+			Attempt:    exec.Attempt,
+			Result:     domain.JobResult(exec.Result),
+			Error:      exec.Error,
+			StartTime:  time.UnixMilli(exec.StartTime),
+			EndTime:    time.UnixMilli(exec.EndTime),
+		})
+	}
+
+	return res, nil
+}
+
+// @func: RegisterCallback
+// @date: 2024-01-17 14:19:56
+// @brief: 远程任务回调-登记等待回调的token
+// @author: Kewin Li
+// @receiver p
+// @param ctx
+// @param token
+// @param expiration
+// @return error
+func (p *PreemptJobRepository) RegisterCallback(ctx context.Context, token string, expiration time.Duration) error {
+	return p.cache.Register(ctx, token, expiration)
+}
+
+// @func: SaveCallback
+// @date: 2024-01-17 14:20:41
+// @brief: 远程任务回调-保存回调结果
+// @author: Kewin Li
+// @receiver p
+// @param ctx
+// @param cb
+// @return error
+func (p *PreemptJobRepository) SaveCallback(ctx context.Context, cb domain.JobCallback) error {
+	return p.cache.Set(ctx, cb)
+}
+
+// @func: GetCallback
+// @date: 2024-01-17 14:21:15
+// @brief: 远程任务回调-查询回调结果
+// @author: Kewin Li
+// @receiver p
+// @param ctx
+// @param token
+// @return domain.JobCallback
+// @return error
+func (p *PreemptJobRepository) GetCallback(ctx context.Context, token string) (domain.JobCallback, error) {
+	return p.cache.Get(ctx, token)
+}
+
+// @func: ReportNode
+// @date: 2024-01-20 10:35:20
+// @brief: 调度结点-上报负载
+// @author: Kewin Li
+// @receiver p
+// @param ctx
+// @param node
+// @return error
+func (p *PreemptJobRepository) ReportNode(ctx context.Context, node domain.JobNode) error {
+	return p.nodeCache.Report(ctx, node)
+}
+
+// @func: ListNodes
+// @date: 2024-01-20 10:36:02
+// @brief: 调度结点-查询在线结点的负载
+// @author: Kewin Li
+// @receiver p
+// @param ctx
+// @return []domain.JobNode
+// @return error
+func (p *PreemptJobRepository) ListNodes(ctx context.Context) ([]domain.JobNode, error) {
+	return p.nodeCache.List(ctx)
+}
+
+// @func: ConvertsDaoJob
+// @date: 2024-01-16 14:56:10
+// @brief: Job Domain--->DAO
+// @author: Kewin Li
+// @receiver p
+// @param job
+// @return dao.Job
+func (p *PreemptJobRepository) ConvertsDaoJob(job *domain.Job) dao.Job {
+	var affinity string
+	if len(job.Affinity) > 0 {
+		val, _ := json.Marshal(job.Affinity)
+		affinity = string(val)
+	}
+
+	return dao.Job{
+		Id:            job.Id,
+		Name:          job.Name,
+		Expression:    job.Expression,
+		TimeZone:      job.TimeZone,
+		ExecutorName:  job.ExecutorName,
+		Cfg:           job.Cfg,
+		Status:        int(job.Status),
+		NextTime:      job.NextExecTime.UnixMilli(),
+		MaxRetries:    job.MaxRetries,
+		RetryInterval: job.RetryInterval.Milliseconds(),
+		RetryCnt:      job.RetryCnt,
+		Mode:          int(job.Mode),
+		ShardTotal:    job.ShardTotal,
+		Affinity:      affinity,
+		MisfirePolicy: int(job.MisfirePolicy),
+		MisfireCap:    job.MisfireCap,
+	}
+}
+
+// @func: ConvertsDomainJob
+// @date: 2024-01-16 14:56:44
+// @brief: Job DAO--->Domain
+// @author: Kewin Li
+// @receiver p
+// @param job
+// @return domain.Job
+func (p *PreemptJobRepository) ConvertsDomainJob(job *dao.Job) domain.Job {
+	var affinity map[string]string
+	if job.Affinity != "" {
+		// 解析失败时不限制结点, 避免任务无法调度
+		_ = json.Unmarshal([]byte(job.Affinity), &affinity)
+	}
+
+	return domain.Job{
+		Id:              job.Id,
+		Name:            job.Name,
+		Expression:      job.Expression,
+		TimeZone:        job.TimeZone,
+		ExecutorName:    job.ExecutorName,
+		Cfg:             job.Cfg,
+		Status:          domain.JobStatus(job.Status),
+		Version:         job.Version,
+		NextExecTime:    time.UnixMilli(job.NextTime),
+		MaxRetries:      job.MaxRetries,
+		RetryInterval:   time.Duration(job.RetryInterval) * time.Millisecond,
+		RetryCnt:        job.RetryCnt,
+		Mode:            domain.JobMode(job.Mode),
+		ShardTotal:      job.ShardTotal,
+		Affinity:        affinity,
+		MisfirePolicy:   domain.JobMisfirePolicy(job.MisfirePolicy),
+		MisfireCap:      job.MisfireCap,
+		CatchUp:         job.CatchUp,
+		MisfireCnt:      job.MisfireCnt,
+		LastExecTime:    time.UnixMilli(job.LastExecTime),
+		LastResult:      domain.JobResult(job.LastResult),
+		LastError:       job.LastError,
+		LastSuccessTime: time.UnixMilli(job.LastSuccessTime),
+		Ctime:           time.UnixMilli(job.Ctime),
+		Utime:           time.UnixMilli(job.Utime),
+	}
+}
+
+// @func: convertsDaoExecution
+// @date: 2024-01-18 15:57:05
+// @brief: JobExecution Domain--->DAO
+// @author: Kewin Li
+// @receiver p
+// @param exec
+// @return dao.JobExecution
+func (p *PreemptJobRepository) convertsDaoExecution(exec *domain.JobExecution) dao.JobExecution {
+	return dao.JobExecution{
+		JobId:      exec.JobId,
+		Node:       exec.Node,
+		ShardIndex: exec.ShardIndex,
+		Attempt:    exec.Attempt,
+		Result:     int(exec.Result),
+		Error:      exec.Error,
+		StartTime:  exec.StartTime.UnixMilli(),
+		EndTime:    exec.EndTime.UnixMilli(),
+	}
+}
@@ -40,6 +40,21 @@ func (m *MockArticleRepository) EXPECT() *MockArticleRepositoryMockRecorder {
 	return m.recorder
 }
 
+// CountPubByTag mocks base method.
+func (m *MockArticleRepository) CountPubByTag(ctx context.Context, tag string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountPubByTag", ctx, tag)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountPubByTag indicates an expected call of CountPubByTag.
+func (mr *MockArticleRepositoryMockRecorder) CountPubByTag(ctx, tag any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountPubByTag", reflect.TypeOf((*MockArticleRepository)(nil).CountPubByTag), ctx, tag)
+}
+
 // Create mocks base method.
 func (m *MockArticleRepository) Create(ctx context.Context, art domain.Article) (int64, error) {
 	m.ctrl.T.Helper()
@@ -115,6 +130,51 @@ func (mr *MockArticleRepositoryMockRecorder) ListPub(ctx, start, offset, limit a
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPub", reflect.TypeOf((*MockArticleRepository)(nil).ListPub), ctx, start, offset, limit)
 }
 
+// ListPubByTag mocks base method.
+func (m *MockArticleRepository) ListPubByTag(ctx context.Context, tag string, offset, limit int) ([]domain.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPubByTag", ctx, tag, offset, limit)
+	ret0, _ := ret[0].([]domain.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPubByTag indicates an expected call of ListPubByTag.
+func (mr *MockArticleRepositoryMockRecorder) ListPubByTag(ctx, tag, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPubByTag", reflect.TypeOf((*MockArticleRepository)(nil).ListPubByTag), ctx, tag, offset, limit)
+}
+
+// SaveWithRevision mocks base method.
+func (m *MockArticleRepository) SaveWithRevision(ctx context.Context, art domain.Article, kind domain.RevisionKind) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveWithRevision", ctx, art, kind)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveWithRevision indicates an expected call of SaveWithRevision.
+func (mr *MockArticleRepositoryMockRecorder) SaveWithRevision(ctx, art, kind any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveWithRevision", reflect.TypeOf((*MockArticleRepository)(nil).SaveWithRevision), ctx, art, kind)
+}
+
+// SearchTags mocks base method.
+func (m *MockArticleRepository) SearchTags(ctx context.Context, prefix string, limit int) ([]domain.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchTags", ctx, prefix, limit)
+	ret0, _ := ret[0].([]domain.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchTags indicates an expected call of SearchTags.
+func (mr *MockArticleRepositoryMockRecorder) SearchTags(ctx, prefix, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchTags", reflect.TypeOf((*MockArticleRepository)(nil).SearchTags), ctx, prefix, limit)
+}
+
 // Sync mocks base method.
 func (m *MockArticleRepository) Sync(ctx context.Context, art domain.Article) (int64, error) {
 	m.ctrl.T.Helper()
@@ -144,6 +204,21 @@ func (mr *MockArticleRepositoryMockRecorder) SyncStatus(ctx, artId, authorId, st
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncStatus", reflect.TypeOf((*MockArticleRepository)(nil).SyncStatus), ctx, artId, authorId, status)
 }
 
+// SyncWithRevision mocks base method.
+func (m *MockArticleRepository) SyncWithRevision(ctx context.Context, art domain.Article, kind domain.RevisionKind) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncWithRevision", ctx, art, kind)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SyncWithRevision indicates an expected call of SyncWithRevision.
+func (mr *MockArticleRepositoryMockRecorder) SyncWithRevision(ctx, art, kind any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncWithRevision", reflect.TypeOf((*MockArticleRepository)(nil).SyncWithRevision), ctx, art, kind)
+}
+
 // Update mocks base method.
 func (m *MockArticleRepository) Update(ctx context.Context, art domain.Article) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"kitbook/internal/domain"
+	"kitbook/internal/repository/dao"
+	"time"
+)
+
+type NotificationRepository interface {
+	Create(ctx context.Context, n domain.Notification) error
+	FindByUid(ctx context.Context, uid int64, end time.Time, limit int) ([]domain.Notification, error)
+}
+
+type NormalNotificationRepository struct {
+	dao dao.NotificationDao
+}
+
+func NewNormalNotificationRepository(dao dao.NotificationDao) NotificationRepository {
+	return &NormalNotificationRepository{
+		dao: dao,
+	}
+}
+
+// @func: Create
+// @date: 2024-01-28 10:20:14
+// @brief: 站内通知-新增通知
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param notification
+// @return error
+func (n *NormalNotificationRepository) Create(ctx context.Context, notification domain.Notification) error {
+	return n.dao.Insert(ctx, dao.Notification{
+		Uid:     notification.Uid,
+		Biz:     notification.Biz,
+		BizId:   notification.BizId,
+		Etime:   notification.Etime.UnixMilli(),
+		Content: notification.Content,
+	})
+}
+
+// @func: FindByUid
+// @date: 2024-01-28 10:22:40
+// @brief: 站内通知-按通知时间倒序分页查询
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param uid
+// @param end
+// @param limit
+// @return []domain.Notification
+// @return error
+func (n *NormalNotificationRepository) FindByUid(ctx context.Context, uid int64, end time.Time, limit int) ([]domain.Notification, error) {
+	ns, err := n.dao.FindByUid(ctx, uid, end.UnixMilli(), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]domain.Notification, 0, len(ns))
+	for _, notification := range ns {
+		res = append(res, domain.Notification{
+			Id:      notification.Id,
+			Uid:     notification.Uid,
+			Biz:     notification.Biz,
+			BizId:   notification.BizId,
+			Etime:   time.UnixMilli(notification.Etime),
+			Content: notification.Content,
+			Ctime:   time.UnixMilli(notification.Ctime),
+		})
+	}
+
+	return res, nil
+}
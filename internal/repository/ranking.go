@@ -4,73 +4,56 @@ import (
 	"context"
 	"kitbook/internal/domain"
 	"kitbook/internal/repository/cache"
+	"kitbook/pkg/logger"
+	"time"
 )
 
+var ErrRankingItemNotFound = cache.ErrKeyNotExist
+
 type RankingRepository interface {
-	ReplaceTopN(ctx context.Context, arts []domain.Article) error
-	GetTopN(ctx context.Context) ([]domain.Article, error)
+	ReplaceTopN(ctx context.Context, name string, arts []domain.Article) error
+	GetTopN(ctx context.Context, name string) ([]domain.Article, error)
+	RefreshLocalCache(ctx context.Context, name string) error
 }
 
+// CacheRankingRepository
+// @Description: 双缓存设计, 本地缓存 + redis缓存
 type CacheRankingRepository struct {
-	cache cache.RankingCache
-
-	// 以下为双缓存使用 V1
-	redisCache *cache.RedisRankingCache
+	redisCache cache.RankingCache
 	localCache *cache.LocalRankingCache
-}
 
-func NewCacheRankingRepository(cache cache.RankingCache) RankingRepository {
-	return &CacheRankingRepository{
-		cache: cache,
-	}
+	l logger.Logger
 }
 
-func NewCacheRankingRepositoryV1(redisCache *cache.RedisRankingCache,
-	localCache *cache.LocalRankingCache) RankingRepository {
+func NewCacheRankingRepository(redisCache cache.RankingCache,
+	localCache *cache.LocalRankingCache,
+	l logger.Logger) RankingRepository {
 	return &CacheRankingRepository{
 		redisCache: redisCache,
 		localCache: localCache,
+		l:          l,
 	}
 }
 
 // @func: ReplaceTopN
-// @date: 2023-12-29 22:58:53
-// @brief: 热榜服务-热榜数据放入缓存
-// @author: Kewin Li
-// @receiver c
-// @param ctx
-func (c *CacheRankingRepository) ReplaceTopN(ctx context.Context, arts []domain.Article) error {
-	return c.cache.Set(ctx, arts)
-}
-
-// @func: ReplaceTopNV1
 // @date: 2023-12-30 22:18:36
 // @brief: 热榜服务-热榜数据放入缓存-双缓存设计
 // @author: Kewin Li
 // @receiver c
 // @param ctx
+// @param name 榜单名称
 // @param arts
 // @return error
-func (c *CacheRankingRepository) ReplaceTopNV1(ctx context.Context, arts []domain.Article) error {
-	err := c.localCache.Set(ctx, arts)
+func (c *CacheRankingRepository) ReplaceTopN(ctx context.Context, name string, arts []domain.Article) error {
+	err := c.localCache.Set(ctx, name, arts)
 	if err != nil {
-		//TODO: 日志埋点, 本地缓存更新失败
 		// 没有必要报错退出
+		c.l.WARN("本地热榜缓存更新失败",
+			logger.Error(err),
+			logger.Field{"name", name})
 	}
 
-	return c.redisCache.Set(ctx, arts)
-}
-
-// @func: GetTopN
-// @date: 2023-12-30 21:47:36
-// @brief: 热榜服务-热榜数据取出缓存
-// @author: Kewin Li
-// @receiver c
-// @param ctx
-// @return []domain.Article
-// @return error
-func (c *CacheRankingRepository) GetTopN(ctx context.Context) ([]domain.Article, error) {
-	return c.cache.Get(ctx)
+	return c.redisCache.Set(ctx, name, arts)
 }
 
 // @func: GetTopN
@@ -79,30 +62,168 @@ func (c *CacheRankingRepository) GetTopN(ctx context.Context) ([]domain.Article,
 // @author: Kewin Li
 // @receiver c
 // @param ctx
+// @param name 榜单名称
 // @return []domain.Article
 // @return error
-func (c *CacheRankingRepository) GetTopNV1(ctx context.Context) ([]domain.Article, error) {
+func (c *CacheRankingRepository) GetTopN(ctx context.Context, name string) ([]domain.Article, error) {
 	// 1. 先查本地缓存
-	arts, err := c.localCache.Get(ctx)
+	arts, err := c.localCache.Get(ctx, name)
 	// 注意：这里查询失败有两层含义：本地缓存出错、本地缓存数据过期
 	if err == nil {
 		return arts, nil
 	}
 
 	// 2. 再查redis缓存
-	arts, err = c.redisCache.Get(ctx)
+	arts, err = c.redisCache.Get(ctx, name)
 	// 2.1 redis查询失败可以考虑直接使用过期的本地缓存数据
 	if err != nil {
-		return c.localCache.ForceGet(ctx)
+		arts, err2 := c.localCache.ForceGet(ctx, name)
+		if err2 != nil {
+			return nil, err
+		}
+
+		c.l.WARN("redis热榜缓存查询失败, 使用过期本地缓存",
+			logger.Error(err),
+			logger.Field{"name", name})
+		return arts, nil
 	}
 
 	// 3. 本地缓存回写
-	err = c.redisCache.Set(ctx, arts)
+	err = c.localCache.Set(ctx, name, arts)
 	if err != nil {
-		//TODO: 日志埋点, 本地缓存回写失败
 		// 没有必要直接报错返回
+		c.l.WARN("本地热榜缓存回写失败",
+			logger.Error(err),
+			logger.Field{"name", name})
 	}
 
 	return arts, nil
+}
+
+// @func: RefreshLocalCache
+// @date: 2024-01-16 10:12:45
+// @brief: 热榜服务-从redis缓存刷新本地缓存
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param name 榜单名称
+// @return error
+func (c *CacheRankingRepository) RefreshLocalCache(ctx context.Context, name string) error {
+	arts, err := c.redisCache.Get(ctx, name)
+	if err != nil {
+		// 刷新失败保留原有本地缓存, 等待下次刷新
+		return err
+	}
+
+	return c.localCache.Set(ctx, name, arts)
+}
+
+type RankingScoreRepository interface {
+	Track(ctx context.Context, item domain.RankingItem, expiration time.Duration) error
+	Incr(ctx context.Context, artId int64, delta domain.Interactive) (domain.RankingItem, error)
+	List(ctx context.Context) ([]domain.RankingItem, error)
+	Untrack(ctx context.Context, artIds []int64) error
+	SetScores(ctx context.Context, name string, scores map[int64]float64, removed []int64) error
+	TopN(ctx context.Context, name string, n int) ([]domain.Article, error)
+}
+
+// CacheRankingScoreRepository
+// @Description: 增量热榜, 分数实时写入redis有序集合
+type CacheRankingScoreRepository struct {
+	cache cache.RankingScoreCache
+}
+
+func NewCacheRankingScoreRepository(cache cache.RankingScoreCache) RankingScoreRepository {
+	return &CacheRankingScoreRepository{
+		cache: cache,
+	}
+}
+
+// @func: Track
+// @date: 2024-01-21 11:20:36
+// @brief: 增量热榜-开始跟踪帖子
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param item
+// @param expiration 剩余统计窗口
+// @return error
+func (c *CacheRankingScoreRepository) Track(ctx context.Context, item domain.RankingItem, expiration time.Duration) error {
+	return c.cache.Track(ctx, item, expiration)
+}
+
+// @func: Incr
+// @date: 2024-01-21 11:21:50
+// @brief: 增量热榜-累加帖子互动数, 帖子未被跟踪时返回ErrRankingItemNotFound
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param artId
+// @param delta
+// @return domain.RankingItem
+// @return error
+func (c *CacheRankingScoreRepository) Incr(ctx context.Context, artId int64, delta domain.Interactive) (domain.RankingItem, error) {
+	return c.cache.Incr(ctx, artId, delta)
+}
+
+// @func: List
+// @date: 2024-01-21 11:22:42
+// @brief: 增量热榜-查询全部跟踪中的帖子
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @return []domain.RankingItem
+// @return error
+func (c *CacheRankingScoreRepository) List(ctx context.Context) ([]domain.RankingItem, error) {
+	return c.cache.List(ctx)
+}
+
+// @func: Untrack
+// @date: 2024-01-21 11:23:15
+// @brief: 增量热榜-停止跟踪帖子
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param artIds
+// @return error
+func (c *CacheRankingScoreRepository) Untrack(ctx context.Context, artIds []int64) error {
+	return c.cache.Untrack(ctx, artIds)
+}
+
+// @func: SetScores
+// @date: 2024-01-21 11:24:06
+// @brief: 增量热榜-更新榜单分数
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param name 榜单名称
+// @param scores
+// @param removed 移出榜单的帖子
+// @return error
+func (c *CacheRankingScoreRepository) SetScores(ctx context.Context, name string, scores map[int64]float64, removed []int64) error {
+	return c.cache.SetScores(ctx, name, scores, removed)
+}
+
+// @func: TopN
+// @date: 2024-01-21 11:25:30
+// @brief: 增量热榜-查询榜单前n个帖子
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param name 榜单名称
+// @param n
+// @return []domain.Article
+// @return error
+func (c *CacheRankingScoreRepository) TopN(ctx context.Context, name string, n int) ([]domain.Article, error) {
+	items, err := c.cache.TopN(ctx, name, n)
+	if err != nil {
+		return nil, err
+	}
 
+	arts := make([]domain.Article, 0, len(items))
+	for _, item := range items {
+		arts = append(arts, item.Article)
+	}
+
+	return arts, nil
 }
@@ -0,0 +1,109 @@
+// Package repository
+// @Description: 数据转发层-热榜模块-单元测试
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"kitbook/internal/domain"
+	"kitbook/internal/repository/cache"
+	cachemocks "kitbook/internal/repository/cache/mocks"
+	"kitbook/pkg/logger"
+	"testing"
+	"time"
+)
+
+// @func: TestCacheRankingRepository_GetTopN
+// @date: 2024-01-16 11:30:25
+// @brief: 单元测试-热榜查询-双缓存
+// @author: Kewin Li
+// @param t
+func TestCacheRankingRepository_GetTopN(t *testing.T) {
+	const name = "hot"
+	arts := []domain.Article{{Id: 1}, {Id: 2}}
+
+	testCases := []struct {
+		name string
+
+		mock func(ctrl *gomock.Controller) cache.RankingCache
+		// 本地缓存初始状态
+		local func() *cache.LocalRankingCache
+
+		wantArts []domain.Article
+		wantErr  error
+	}{
+		{
+			name: "本地缓存命中",
+			mock: func(ctrl *gomock.Controller) cache.RankingCache {
+				return cachemocks.NewMockRankingCache(ctrl)
+			},
+			local: func() *cache.LocalRankingCache {
+				lc := cache.NewLocalRankingCache(time.Minute)
+				_ = lc.Set(context.Background(), name, arts)
+				return lc
+			},
+			wantArts: arts,
+		},
+		{
+			name: "本地缓存未命中, redis命中",
+			mock: func(ctrl *gomock.Controller) cache.RankingCache {
+				c := cachemocks.NewMockRankingCache(ctrl)
+				c.EXPECT().Get(gomock.Any(), name).Return(arts, nil)
+				return c
+			},
+			local: func() *cache.LocalRankingCache {
+				return cache.NewLocalRankingCache(time.Minute)
+			},
+			wantArts: arts,
+		},
+		{
+			name: "本地缓存过期, redis失败, 使用过期本地缓存",
+			mock: func(ctrl *gomock.Controller) cache.RankingCache {
+				c := cachemocks.NewMockRankingCache(ctrl)
+				c.EXPECT().Get(gomock.Any(), name).Return(nil, errors.New("redis错误"))
+				return c
+			},
+			local: func() *cache.LocalRankingCache {
+				lc := cache.NewLocalRankingCache(-time.Minute)
+				_ = lc.Set(context.Background(), name, arts)
+				return lc
+			},
+			wantArts: arts,
+		},
+		{
+			name: "本地缓存为空, redis失败",
+			mock: func(ctrl *gomock.Controller) cache.RankingCache {
+				c := cachemocks.NewMockRankingCache(ctrl)
+				c.EXPECT().Get(gomock.Any(), name).Return(nil, errors.New("redis错误"))
+				return c
+			},
+			local: func() *cache.LocalRankingCache {
+				return cache.NewLocalRankingCache(time.Minute)
+			},
+			wantErr: errors.New("redis错误"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			lc := tc.local()
+			repo := NewCacheRankingRepository(tc.mock(ctrl), lc, logger.NewNopLogger())
+
+			res, err := repo.GetTopN(context.Background(), name)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantArts, res)
+
+			// redis命中后回写本地缓存
+			if err == nil {
+				local, err := lc.ForceGet(context.Background(), name)
+				assert.NoError(t, err)
+				assert.Equal(t, tc.wantArts, local)
+			}
+		})
+	}
+}
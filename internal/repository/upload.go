@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"kitbook/internal/domain"
+	"kitbook/internal/repository/dao"
+	"time"
+)
+
+var ErrUploadNotFound = errors.New("上传记录不存在")
+
+type UploadRepository interface {
+	Create(ctx context.Context, u domain.Upload) (domain.Upload, error)
+	FindById(ctx context.Context, id int64, userId int64) (domain.Upload, error)
+	FindByUserHash(ctx context.Context, userId int64, hash string) (domain.Upload, error)
+	FindReadyByHash(ctx context.Context, hash string) (domain.Upload, error)
+	MarkReady(ctx context.Context, id int64, contentType string) error
+	UsedQuota(ctx context.Context, userId int64) (int64, error)
+	ListStale(ctx context.Context, before time.Time, afterId int64, limit int) ([]domain.Upload, error)
+	Delete(ctx context.Context, id int64) error
+	CountByHash(ctx context.Context, hash string) (int64, error)
+	IsReferenced(ctx context.Context, userId int64, hash string) (bool, error)
+}
+
+type NormalUploadRepository struct {
+	dao dao.UploadDao
+}
+
+func NewNormalUploadRepository(dao dao.UploadDao) UploadRepository {
+	return &NormalUploadRepository{
+		dao: dao,
+	}
+}
+
+// @func: Create
+// @date: 2024-01-24 15:10:12
+// @brief: 上传-新增记录, 用户已上传过相同内容时返回已有记录
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param u
+// @return domain.Upload
+// @return error
+func (n *NormalUploadRepository) Create(ctx context.Context, u domain.Upload) (domain.Upload, error) {
+	res, err := n.dao.Insert(ctx, n.ConvertsDaoUpload(&u))
+	if err != nil {
+		return domain.Upload{}, err
+	}
+
+	return n.ConvertsDomainUpload(&res), nil
+}
+
+// @func: FindById
+// @date: 2024-01-24 15:11:26
+// @brief: 上传-查询用户自己的记录
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param id
+// @param userId
+// @return domain.Upload
+// @return error 不存在时返回ErrUploadNotFound
+func (n *NormalUploadRepository) FindById(ctx context.Context, id int64, userId int64) (domain.Upload, error) {
+	return n.find(n.dao.FindById(ctx, id, userId))
+}
+
+// @func: FindByUserHash
+// @date: 2024-01-24 15:12:30
+// @brief: 上传-查询用户上传过的某内容
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param userId
+// @param hash
+// @return domain.Upload
+// @return error 不存在时返回ErrUploadNotFound
+func (n *NormalUploadRepository) FindByUserHash(ctx context.Context, userId int64, hash string) (domain.Upload, error) {
+	return n.find(n.dao.FindByUserHash(ctx, userId, hash))
+}
+
+// @func: FindReadyByHash
+// @date: 2024-01-24 15:13:42
+// @brief: 上传-查询任意用户已就绪的某内容
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param hash
+// @return domain.Upload
+// @return error 不存在时返回ErrUploadNotFound
+func (n *NormalUploadRepository) FindReadyByHash(ctx context.Context, hash string) (domain.Upload, error) {
+	return n.find(n.dao.FindReadyByHash(ctx, hash))
+}
+
+// @func: MarkReady
+// @date: 2024-01-24 15:14:50
+// @brief: 上传-校验通过
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param id
+// @param contentType
+// @return error
+func (n *NormalUploadRepository) MarkReady(ctx context.Context, id int64, contentType string) error {
+	return n.dao.MarkReady(ctx, id, contentType)
+}
+
+// @func: UsedQuota
+// @date: 2024-01-24 15:15:56
+// @brief: 上传-用户已用空间(字节)
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param userId
+// @return int64
+// @return error
+func (n *NormalUploadRepository) UsedQuota(ctx context.Context, userId int64) (int64, error) {
+	return n.dao.SumSize(ctx, userId)
+}
+
+// @func: ListStale
+// @date: 2024-01-24 15:17:08
+// @brief: 上传-按ID分页查询更新时间早于before的记录
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param before
+// @param afterId
+// @param limit
+// @return []domain.Upload
+// @return error
+func (n *NormalUploadRepository) ListStale(ctx context.Context, before time.Time, afterId int64, limit int) ([]domain.Upload, error) {
+	us, err := n.dao.ListBefore(ctx, before.UnixMilli(), afterId, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]domain.Upload, 0, len(us))
+	for _, u := range us {
+		res = append(res, n.ConvertsDomainUpload(&u))
+	}
+
+	return res, nil
+}
+
+// @func: Delete
+// @date: 2024-01-24 15:18:20
+// @brief: 上传-删除记录
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param id
+// @return error
+func (n *NormalUploadRepository) Delete(ctx context.Context, id int64) error {
+	return n.dao.Delete(ctx, id)
+}
+
+// @func: CountByHash
+// @date: 2024-01-24 15:19:32
+// @brief: 上传-引用某内容的记录数
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param hash
+// @return int64
+// @return error
+func (n *NormalUploadRepository) CountByHash(ctx context.Context, hash string) (int64, error) {
+	return n.dao.CountByHash(ctx, hash)
+}
+
+// @func: IsReferenced
+// @date: 2024-01-24 15:20:44
+// @brief: 上传-用户的帖子中是否还引用该文件
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param userId
+// @param hash
+// @return bool
+// @return error
+func (n *NormalUploadRepository) IsReferenced(ctx context.Context, userId int64, hash string) (bool, error) {
+	return n.dao.IsReferenced(ctx, userId, hash)
+}
+
+func (n *NormalUploadRepository) find(u dao.Upload, err error) (domain.Upload, error) {
+	if err == dao.ErrRecordNotFound {
+		return domain.Upload{}, ErrUploadNotFound
+	}
+	if err != nil {
+		return domain.Upload{}, err
+	}
+
+	return n.ConvertsDomainUpload(&u), nil
+}
+
+func (n *NormalUploadRepository) ConvertsDaoUpload(u *domain.Upload) dao.Upload {
+	return dao.Upload{
+		Id:          u.Id,
+		UserId:      u.UserId,
+		Hash:        u.Hash,
+		Size:        u.Size,
+		ContentType: u.ContentType,
+		Filename:    u.Filename,
+		Kind:        u.Kind.ToUint8(),
+		Status:      u.Status.ToUint8(),
+	}
+}
+
+func (n *NormalUploadRepository) ConvertsDomainUpload(u *dao.Upload) domain.Upload {
+	return domain.Upload{
+		Id:          u.Id,
+		UserId:      u.UserId,
+		Hash:        u.Hash,
+		Size:        u.Size,
+		ContentType: u.ContentType,
+		Filename:    u.Filename,
+		Kind:        domain.UploadKind(u.Kind),
+		Status:      domain.UploadStatus(u.Status),
+		Ctime:       time.UnixMilli(u.Ctime),
+		Utime:       time.UnixMilli(u.Utime),
+	}
+}
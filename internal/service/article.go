@@ -5,23 +5,65 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"kitbook/internal/domain"
 	"kitbook/internal/events/article"
 	"kitbook/internal/repository"
+	"kitbook/internal/service/moderation"
 	"kitbook/pkg/logger"
+	"strings"
 	"time"
 )
 
-var ErrInvalidUpdate = errors.New("非法操作")
+var (
+	ErrInvalidUpdate         = errors.New("非法操作")
+	ErrRevisionNotFound      = repository.ErrRevisionNotFound
+	ErrInvalidPublishTime    = errors.New("定时发表时间不合法")
+	ErrScheduleNotFound      = repository.ErrScheduleNotFound
+	ErrScheduleNotCancelable = repository.ErrScheduleMismatch
+	ErrVersionConflict       = repository.ErrVersionConflict
+	ErrArticleUnderReview    = errors.New("帖子审核中")
+	ErrArticleRejected       = errors.New("帖子审核未通过")
+	ErrReviewNotFound        = repository.ErrReviewNotFound
+	ErrReviewResolved        = repository.ErrReviewResolved
+	ErrReviewStale           = errors.New("帖子已修改, 审核失效")
+
+	errScheduleOutdated = errors.New("帖子已修改, 定时发表计划失效")
+)
+
+const (
+	// 定时发表每次最多处理的计划数
+	schedulePublishBatch = 100
+	// 认领后超过该时长仍未完成, 视为认领结点宕机, 允许重新认领
+	scheduleClaimTimeout = 5 * time.Minute
+)
 
 type ArticleService interface {
-	Save(ctx context.Context, art domain.Article) (int64, error)
-	Publish(ctx context.Context, art domain.Article) (int64, error)
+	Save(ctx context.Context, art domain.Article) (int64, int64, error)
+	Autosave(ctx context.Context, art domain.Article) (int64, int64, error)
+	Publish(ctx context.Context, art domain.Article) (int64, int64, error)
 	Withdraw(ctx context.Context, art domain.Article) error
 	GetByAuthor(ctx context.Context, userId int64, offset int, limit int) ([]domain.Article, error)
 	GetById(ctx context.Context, artId int64) (domain.Article, error)
 	GetPubById(ctx context.Context, artId int64, userId int64) (domain.Article, error)
 	ListPub(ctx context.Context, start time.Time, offset int, limit int) ([]domain.Article, error)
+	ListPubByTag(ctx context.Context, tag string, offset int, limit int) ([]domain.Article, int64, error)
+	SearchTags(ctx context.Context, prefix string, limit int) ([]domain.Tag, error)
+	ListRevisions(ctx context.Context, artId int64, authorId int64, offset int, limit int) ([]domain.ArticleRevision, error)
+	DiffRevisions(ctx context.Context, artId int64, authorId int64, from int, to int) (domain.ArticleDiff, error)
+	Rollback(ctx context.Context, artId int64, authorId int64, version int) error
+
+	// 定时发表
+	Schedule(ctx context.Context, art domain.Article, publishTime time.Time) (int64, int64, error)
+	CancelSchedule(ctx context.Context, artId int64, authorId int64) error
+	GetSchedule(ctx context.Context, artId int64, authorId int64) (domain.ArticleSchedule, error)
+	PublishDue(ctx context.Context, now time.Time) (int, error)
+
+	// 发表审核
+	GetReview(ctx context.Context, artId int64, authorId int64) (domain.ArticleReview, error)
+	ListPendingReviews(ctx context.Context, offset int, limit int) ([]domain.ArticleReview, error)
+	ApproveReview(ctx context.Context, reviewId int64, reviewerId int64) error
+	RejectReview(ctx context.Context, reviewId int64, reviewerId int64, reasons []string) error
 }
 
 // NormalArticleService
@@ -29,6 +71,12 @@ type ArticleService interface {
 type NormalArticleService struct {
 	//  不分库
 	repo repository.ArticleRepository
+	// 每次保存、发表生成一个历史版本
+	revisionRepo repository.ArticleRevisionRepository
+	scheduleRepo repository.ArticleScheduleRepository
+	// 发表前经审核链审核, 审核记录供作者查看、审核员处理
+	reviewRepo repository.ArticleReviewRepository
+	moderator  moderation.Moderator
 
 	producer article.Producer
 
@@ -41,11 +89,20 @@ type NormalArticleService struct {
 }
 
 func NewNormalArticleService(repo repository.ArticleRepository,
+	revisionRepo repository.ArticleRevisionRepository,
+	scheduleRepo repository.ArticleScheduleRepository,
+	reviewRepo repository.ArticleReviewRepository,
+	moderator moderation.Moderator,
 	producer article.Producer,
 	l logger.Logger) ArticleService {
 	return &NormalArticleService{
-		repo:     repo,
-		producer: producer,
+		repo:         repo,
+		revisionRepo: revisionRepo,
+		scheduleRepo: scheduleRepo,
+		reviewRepo:   reviewRepo,
+		moderator:    moderator,
+		producer:     producer,
+		l:            l,
 	}
 }
 
@@ -75,21 +132,169 @@ func NewNormalArticleServiceV1(
 // @receiver n
 // @param ctx
 // @param art
-func (n *NormalArticleService) Save(ctx context.Context, art domain.Article) (int64, error) {
+// @return int64 帖子ID
+// @return int64 保存后的版本号
+// @return error
+func (n *NormalArticleService) Save(ctx context.Context, art domain.Article) (int64, int64, error) {
 	// 帖子未发表
 	art.Status = domain.ArticleStatusUnpublished
+	return n.save(ctx, art, domain.RevisionKindSave)
+}
 
+// @func: Autosave
+// @date: 2024-01-23 10:40:26
+// @brief: 帖子服务-自动保存草稿, 只写制作库, 不修改帖子状态、不影响线上库、不生成历史版本
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param art
+// @return int64 帖子ID
+// @return int64 保存后的版本号
+// @return error
+func (n *NormalArticleService) Autosave(ctx context.Context, art domain.Article) (int64, int64, error) {
 	if art.Id > 0 {
+		// 保持原状态
+		art.Status = domain.ArticleStatusUnknow
 		err := n.repo.Update(ctx, art)
 		if err == repository.ErrUserMismatch {
-			return -1, ErrInvalidUpdate
+			return -1, 0, ErrInvalidUpdate
+		}
+		if err != nil {
+			return art.Id, 0, err
 		}
-		return art.Id, err
+
+		return art.Id, art.Version + 1, nil
+	}
+
+	art.Status = domain.ArticleStatusUnpublished
+	id, err := n.repo.Create(ctx, art)
+	if err != nil {
+		return id, 0, err
 	}
-	return n.repo.Create(ctx, art)
+
+	return id, 1, nil
 }
 
-// @func: Publish(ctx context.Context, art domain.Article)
+// @func: save
+// @date: 2024-01-22 11:05:30
+// @brief: 帖子服务-保存到制作库并生成历史版本, 状态由调用方指定
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param art
+// @param kind 版本来源: 保存、回滚
+// @return int64 帖子ID
+// @return int64 保存后的版本号
+// @return error
+func (n *NormalArticleService) save(ctx context.Context, art domain.Article, kind domain.RevisionKind) (int64, int64, error) {
+	id, err := n.repo.SaveWithRevision(ctx, art, kind)
+	if err == repository.ErrUserMismatch {
+		return -1, 0, ErrInvalidUpdate
+	}
+	if err != nil {
+		return id, 0, err
+	}
+
+	if art.Id <= 0 {
+		return id, 1, nil
+	}
+
+	// 定时发表的帖子改为草稿后, 等待中的计划随之取消
+	if art.Status == domain.ArticleStatusUnpublished {
+		n.cancelScheduleOnEdit(ctx, id, art.Author.Id)
+	}
+
+	return id, art.Version + 1, nil
+}
+
+// @func: cancelScheduleOnEdit
+// @date: 2024-01-28 11:20:35
+// @brief: 帖子服务-帖子修改后取消等待中的定时发表计划, 失败时由发表前的状态校验兜底
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param artId
+// @param authorId
+func (n *NormalArticleService) cancelScheduleOnEdit(ctx context.Context, artId int64, authorId int64) {
+	err := n.scheduleRepo.Cancel(ctx, artId, authorId)
+	if err == nil || err == repository.ErrScheduleMismatch {
+		// 没有等待中的计划
+		return
+	}
+
+	n.l.WARN("帖子修改后取消定时发表失败",
+		logger.Error(err),
+		logger.Int[int64]("artId", artId),
+		logger.Int[int64]("authorId", authorId))
+}
+
+// @func: Publish
+// @date: 2024-01-25 14:05:20
+// @brief: 帖子服务-提交发表, 先保存为审核中并生成历史版本, 再经审核链审核; 通过则发表, 拒绝或转人工时线上库保持原样
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param art
+// @return int64 帖子ID
+// @return int64 制作库最新版本号
+// @return error 拒绝时返回ErrArticleRejected, 转人工审核时返回ErrArticleUnderReview, 原因可通过GetReview查询
+func (n *NormalArticleService) Publish(ctx context.Context, art domain.Article) (int64, int64, error) {
+	art.Status = domain.ArticleStatusReviewing
+	id, err := n.repo.SaveWithRevision(ctx, art, domain.RevisionKindSave)
+	if err == repository.ErrUserMismatch {
+		return -1, 0, ErrInvalidUpdate
+	}
+	if err != nil {
+		return id, 0, err
+	}
+	if art.Id > 0 {
+		art.Version++
+	} else {
+		art.Id = id
+		art.Version = 1
+	}
+
+	// 审核链自身不会失败, 审核器故障时已转人工审核
+	res, _ := n.moderator.Moderate(ctx, art)
+	review := domain.ArticleReview{
+		ArtId:    art.Id,
+		AuthorId: art.Author.Id,
+		Version:  art.Version,
+		Title:    art.Title,
+		Content:  art.Content,
+		Reasons:  res.Reasons,
+	}
+
+	switch res.Verdict {
+	case domain.ModerationVerdictPass:
+		review.Status = domain.ReviewStatusApproved
+		n.createReview(ctx, review)
+		id, err = n.publish(ctx, art)
+		if err != nil {
+			return id, art.Version, err
+		}
+		// 发表时制作库再次更新
+		return id, art.Version + 1, nil
+
+	case domain.ModerationVerdictReject:
+		review.Status = domain.ReviewStatusRejected
+		_, err = n.reviewRepo.Create(ctx, review)
+		if err != nil {
+			return art.Id, art.Version, err
+		}
+		return art.Id, n.markRejected(ctx, art), ErrArticleRejected
+
+	default:
+		review.Status = domain.ReviewStatusPending
+		_, err = n.reviewRepo.Create(ctx, review)
+		if err != nil {
+			return art.Id, art.Version, err
+		}
+		return art.Id, art.Version, ErrArticleUnderReview
+	}
+}
+
+// @func: publish
 // @date: 2023-11-25 23:57:48
 // @brief: 帖子服务-帖子发表-dao层数据同步
 // @author: Kewin Li
@@ -97,12 +302,33 @@ func (n *NormalArticleService) Save(ctx context.Context, art domain.Article) (in
 // @param ctx
 // @param art
 // @return error
-func (n *NormalArticleService) Publish(ctx context.Context, art domain.Article) (int64, error) {
+func (n *NormalArticleService) publish(ctx context.Context, art domain.Article) (int64, error) {
 	art.Status = domain.ArticleStatusPublished
-	id, err := n.repo.Sync(ctx, art)
+	id, err := n.repo.SyncWithRevision(ctx, art, domain.RevisionKindPublish)
 	if err == repository.ErrUserMismatch {
 		return -1, ErrInvalidUpdate
 	}
+
+	// 发送帖子发表消息, 推送到粉丝的feed流
+	if err == nil {
+		art.Id = id
+
+		go func() {
+			err2 := n.producer.ProducerPublishEvent(article.PublishEvent{
+				ArtId:    id,
+				AuthorId: art.Author.Id,
+				Ctime:    time.Now().UnixMilli(),
+			})
+
+			if err2 != nil {
+				n.l.ERROR("帖子发表消息发送失败",
+					logger.Error(err2),
+					logger.Int[int64]("artId", id),
+					logger.Int[int64]("authorId", art.Author.Id))
+			}
+		}()
+	}
+
 	return id, err
 }
 
@@ -161,6 +387,24 @@ func (n *NormalArticleService) Withdraw(ctx context.Context, art domain.Article)
 	if err == repository.ErrUserMismatch {
 		return ErrInvalidUpdate
 	}
+
+	// 发送帖子撤回消息, 从搜索索引中删除
+	if err == nil {
+		go func() {
+			err2 := n.producer.ProducerWithdrawEvent(article.WithdrawEvent{
+				ArtId:    art.Id,
+				AuthorId: art.Author.Id,
+			})
+
+			if err2 != nil {
+				n.l.ERROR("帖子撤回消息发送失败",
+					logger.Error(err2),
+					logger.Int[int64]("artId", art.Id),
+					logger.Int[int64]("authorId", art.Author.Id))
+			}
+		}()
+	}
+
 	return err
 }
 
@@ -242,3 +486,511 @@ func (n *NormalArticleService) GetPubById(ctx context.Context, artId int64, user
 func (n *NormalArticleService) ListPub(ctx context.Context, start time.Time, offset int, limit int) ([]domain.Article, error) {
 	return n.repo.ListPub(ctx, start, offset, limit)
 }
+
+// @func: ListPubByTag
+// @date: 2024-01-21 16:02:35
+// @brief: 帖子服务-分页查询带有该标签的已发表帖子及总数
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param tag
+// @param offset
+// @param limit
+// @return []domain.Article
+// @return int64 带有该标签的已发表帖子总数
+// @return error
+func (n *NormalArticleService) ListPubByTag(ctx context.Context, tag string, offset int, limit int) ([]domain.Article, int64, error) {
+	total, err := n.repo.CountPubByTag(ctx, tag)
+	if err != nil || total == 0 {
+		return []domain.Article{}, total, err
+	}
+
+	arts, err := n.repo.ListPubByTag(ctx, tag, offset, limit)
+	return arts, total, err
+}
+
+// @func: SearchTags
+// @date: 2024-01-21 16:04:18
+// @brief: 帖子服务-标签补全
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param prefix
+// @param limit
+// @return []domain.Tag
+// @return error
+func (n *NormalArticleService) SearchTags(ctx context.Context, prefix string, limit int) ([]domain.Tag, error) {
+	return n.repo.SearchTags(ctx, prefix, limit)
+}
+
+// @func: ListRevisions
+// @date: 2024-01-22 11:12:40
+// @brief: 帖子服务-分页查询帖子的历史版本, 新版本在前
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param artId
+// @param authorId
+// @param offset
+// @param limit
+// @return []domain.ArticleRevision
+// @return error
+func (n *NormalArticleService) ListRevisions(ctx context.Context, artId int64, authorId int64, offset int, limit int) ([]domain.ArticleRevision, error) {
+	return n.revisionRepo.List(ctx, artId, authorId, offset, limit)
+}
+
+// @func: DiffRevisions
+// @date: 2024-01-22 11:15:05
+// @brief: 帖子服务-比较帖子的两个历史版本
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param artId
+// @param authorId
+// @param from 旧版本号
+// @param to 新版本号
+// @return domain.ArticleDiff
+// @return error
+func (n *NormalArticleService) DiffRevisions(ctx context.Context, artId int64, authorId int64, from int, to int) (domain.ArticleDiff, error) {
+	fromRev, err := n.revisionRepo.Get(ctx, artId, authorId, from)
+	if err != nil {
+		return domain.ArticleDiff{}, err
+	}
+
+	toRev, err := n.revisionRepo.Get(ctx, artId, authorId, to)
+	if err != nil {
+		return domain.ArticleDiff{}, err
+	}
+
+	return domain.ArticleDiff{
+		From:  fromRev,
+		To:    toRev,
+		Lines: domain.DiffLines(fromRev.Content, toRev.Content),
+	}, nil
+}
+
+// @func: Rollback
+// @date: 2024-01-22 11:18:36
+// @brief: 帖子服务-将历史版本恢复为当前草稿, 并生成一个新版本
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param artId
+// @param authorId
+// @param version
+// @return error
+func (n *NormalArticleService) Rollback(ctx context.Context, artId int64, authorId int64, version int) error {
+	rev, err := n.revisionRepo.Get(ctx, artId, authorId, version)
+	if err != nil {
+		return err
+	}
+
+	// 回滚基于当前草稿, 以当前版本号覆盖
+	cur, err := n.repo.GetById(ctx, artId)
+	if err != nil {
+		return err
+	}
+	if cur.Author.Id != authorId {
+		return ErrInvalidUpdate
+	}
+
+	_, _, err = n.save(ctx, domain.Article{
+		Id:       artId,
+		Title:    rev.Title,
+		Content:  rev.Content,
+		Category: rev.Category,
+		Tags:     rev.Tags,
+		Author: domain.Author{
+			Id: authorId,
+		},
+		Status:  domain.ArticleStatusUnpublished,
+		Version: cur.Version,
+	}, domain.RevisionKindRollback)
+
+	return err
+}
+
+// @func: Schedule
+// @date: 2024-01-22 16:02:18
+// @brief: 帖子服务-保存帖子并设置定时发表, 已有计划时覆盖发表时间
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param art
+// @param publishTime
+// @return int64 帖子ID
+// @return int64 保存后的版本号
+// @return error
+func (n *NormalArticleService) Schedule(ctx context.Context, art domain.Article, publishTime time.Time) (int64, int64, error) {
+	if !publishTime.After(time.Now()) {
+		return -1, 0, ErrInvalidPublishTime
+	}
+
+	art.Status = domain.ArticleStatusScheduled
+	id, version, err := n.save(ctx, art, domain.RevisionKindSave)
+	if err != nil {
+		return id, version, err
+	}
+
+	err = n.scheduleRepo.Save(ctx, domain.ArticleSchedule{
+		ArtId:       id,
+		AuthorId:    art.Author.Id,
+		PublishTime: publishTime,
+	})
+
+	return id, version, err
+}
+
+// @func: CancelSchedule
+// @date: 2024-01-22 16:06:40
+// @brief: 帖子服务-取消定时发表, 帖子恢复为草稿
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param artId
+// @param authorId
+// @return error
+func (n *NormalArticleService) CancelSchedule(ctx context.Context, artId int64, authorId int64) error {
+	err := n.scheduleRepo.Cancel(ctx, artId, authorId)
+	if err != nil {
+		return err
+	}
+
+	art, err := n.repo.GetById(ctx, artId)
+	if err != nil {
+		return err
+	}
+	if art.Status != domain.ArticleStatusScheduled {
+		return nil
+	}
+
+	art.Status = domain.ArticleStatusUnpublished
+	err = n.repo.Update(ctx, art)
+	if err == repository.ErrUserMismatch {
+		return ErrInvalidUpdate
+	}
+
+	return err
+}
+
+// @func: GetSchedule
+// @date: 2024-01-22 16:09:12
+// @brief: 帖子服务-查询帖子的定时发表计划
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param artId
+// @param authorId
+// @return domain.ArticleSchedule
+// @return error
+func (n *NormalArticleService) GetSchedule(ctx context.Context, artId int64, authorId int64) (domain.ArticleSchedule, error) {
+	return n.scheduleRepo.Get(ctx, artId, authorId)
+}
+
+// @func: PublishDue
+// @date: 2024-01-22 16:12:35
+// @brief: 帖子服务-发表到期的定时帖子, 由任务调度器定时调用, 认领成功的结点才会发表
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param now
+// @return int 本次发表成功的帖子数
+// @return error
+func (n *NormalArticleService) PublishDue(ctx context.Context, now time.Time) (int, error) {
+	schedules, err := n.scheduleRepo.FindDue(ctx, now, now.Add(-scheduleClaimTimeout), schedulePublishBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	cnt := 0
+	for _, s := range schedules {
+		if ctx.Err() != nil {
+			return cnt, ctx.Err()
+		}
+
+		ok, err := n.scheduleRepo.Claim(ctx, s)
+		if err != nil {
+			return cnt, err
+		}
+		if !ok {
+			// 已被其他结点认领或作者已修改
+			continue
+		}
+
+		err = n.publishScheduled(ctx, s)
+		if err == errScheduleOutdated {
+			err = n.scheduleRepo.Finish(ctx, s.Id, domain.ScheduleStatusCanceled, err.Error())
+			if err != nil {
+				n.l.ERROR("定时发表结果记录失败",
+					logger.Error(err),
+					logger.Int[int64]("artId", s.ArtId))
+			}
+			continue
+		}
+		if err != nil {
+			n.scheduleFailed(ctx, s, err)
+			continue
+		}
+
+		err = n.scheduleRepo.Finish(ctx, s.Id, domain.ScheduleStatusDone, "")
+		if err != nil {
+			n.l.ERROR("定时发表结果记录失败",
+				logger.Error(err),
+				logger.Int[int64]("artId", s.ArtId))
+		}
+		cnt++
+	}
+
+	return cnt, nil
+}
+
+// @func: publishScheduled
+// @date: 2024-01-22 16:16:08
+// @brief: 帖子服务-发表制作库中的最新内容, 线上库为覆盖写, 重复发表不影响结果
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param s
+// @return error
+func (n *NormalArticleService) publishScheduled(ctx context.Context, s domain.ArticleSchedule) error {
+	art, err := n.repo.GetById(ctx, s.ArtId)
+	if err != nil {
+		return err
+	}
+	if art.Author.Id != s.AuthorId {
+		return ErrInvalidUpdate
+	}
+	if art.Status != domain.ArticleStatusScheduled {
+		// 设置计划后帖子已被修改或发表
+		return errScheduleOutdated
+	}
+
+	_, _, err = n.Publish(ctx, art)
+	switch err {
+	case ErrArticleUnderReview:
+		// 计划已执行, 人工审核通过后发表
+		return nil
+	case ErrArticleRejected:
+		review, err2 := n.reviewRepo.FindLatest(ctx, art.Id, art.Author.Id)
+		if err2 == nil && len(review.Reasons) > 0 {
+			return fmt.Errorf("%w: %s", err, strings.Join(review.Reasons, "; "))
+		}
+	}
+
+	return err
+}
+
+// @func: scheduleFailed
+// @date: 2024-01-22 16:18:42
+// @brief: 帖子服务-记录定时发表失败并通知作者
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param s
+// @param cause
+func (n *NormalArticleService) scheduleFailed(ctx context.Context, s domain.ArticleSchedule, cause error) {
+	fields := []logger.Field{
+		logger.Error(cause),
+		logger.Int[int64]("artId", s.ArtId),
+		logger.Int[int64]("authorId", s.AuthorId),
+	}
+	n.l.ERROR("定时发表失败", fields...)
+
+	err := n.scheduleRepo.Finish(ctx, s.Id, domain.ScheduleStatusFailed, cause.Error())
+	if err != nil {
+		n.l.ERROR("定时发表结果记录失败", append(fields, logger.Field{"err", err.Error()})...)
+	}
+
+	err = n.producer.ProducerScheduleFailedEvent(article.ScheduleFailedEvent{
+		ArtId:       s.ArtId,
+		AuthorId:    s.AuthorId,
+		PublishTime: s.PublishTime.UnixMilli(),
+		Reason:      cause.Error(),
+	})
+	if err != nil {
+		n.l.ERROR("定时发表失败通知发送失败", append(fields, logger.Field{"err", err.Error()})...)
+	}
+}
+
+// @func: GetReview
+// @date: 2024-01-25 14:20:36
+// @brief: 帖子服务-作者查询帖子最近一次审核的结果及原因
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param artId
+// @param authorId
+// @return domain.ArticleReview
+// @return error
+func (n *NormalArticleService) GetReview(ctx context.Context, artId int64, authorId int64) (domain.ArticleReview, error) {
+	return n.reviewRepo.FindLatest(ctx, artId, authorId)
+}
+
+// @func: ListPendingReviews
+// @date: 2024-01-25 14:22:08
+// @brief: 帖子服务-审核员分页查询人工审核队列
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param offset
+// @param limit
+// @return []domain.ArticleReview
+// @return error
+func (n *NormalArticleService) ListPendingReviews(ctx context.Context, offset int, limit int) ([]domain.ArticleReview, error) {
+	return n.reviewRepo.ListPending(ctx, offset, limit)
+}
+
+// @func: ApproveReview
+// @date: 2024-01-25 14:24:30
+// @brief: 帖子服务-人工审核通过并发表; 提交后作者修改过帖子时审核失效, 需作者重新提交; 发表失败时撤销通过, 可重试
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param reviewId
+// @param reviewerId
+// @return error
+func (n *NormalArticleService) ApproveReview(ctx context.Context, reviewId int64, reviewerId int64) error {
+	review, art, err := n.pendingReview(ctx, reviewId, reviewerId)
+	if err != nil {
+		return err
+	}
+
+	// 先处理审核记录, 多个审核员同时处理时只有一个能发表
+	err = n.reviewRepo.Resolve(ctx, review.Id, domain.ReviewStatusApproved, reviewerId, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = n.publish(ctx, art)
+	switch err {
+	case nil:
+		return nil
+	case ErrVersionConflict:
+		// 处理期间作者修改了帖子, 审核失效
+		n.revertApproval(ctx, review.Id, domain.ReviewStatusCanceled)
+		return ErrReviewStale
+	default:
+		// 退回人工审核队列, 审核员可重试
+		n.revertApproval(ctx, review.Id, domain.ReviewStatusPending)
+		return err
+	}
+}
+
+// @func: RejectReview
+// @date: 2024-01-25 14:27:52
+// @brief: 帖子服务-人工审核拒绝, 原因展示给作者
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param reviewId
+// @param reviewerId
+// @param reasons
+// @return error
+func (n *NormalArticleService) RejectReview(ctx context.Context, reviewId int64, reviewerId int64, reasons []string) error {
+	review, art, err := n.pendingReview(ctx, reviewId, reviewerId)
+	if err != nil {
+		return err
+	}
+
+	err = n.reviewRepo.Resolve(ctx, review.Id, domain.ReviewStatusRejected, reviewerId, reasons)
+	if err != nil {
+		return err
+	}
+
+	n.markRejected(ctx, art)
+	return nil
+}
+
+// @func: pendingReview
+// @date: 2024-01-25 14:30:16
+// @brief: 帖子服务-查询待处理的审核及对应帖子, 帖子已不是提交时的版本则将审核置为失效
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param reviewId
+// @param reviewerId
+// @return domain.ArticleReview
+// @return domain.Article
+// @return error
+func (n *NormalArticleService) pendingReview(ctx context.Context, reviewId int64, reviewerId int64) (domain.ArticleReview, domain.Article, error) {
+	review, err := n.reviewRepo.FindById(ctx, reviewId)
+	if err != nil {
+		return domain.ArticleReview{}, domain.Article{}, err
+	}
+	if review.Status != domain.ReviewStatusPending {
+		return domain.ArticleReview{}, domain.Article{}, ErrReviewResolved
+	}
+
+	art, err := n.repo.GetById(ctx, review.ArtId)
+	if err != nil {
+		return domain.ArticleReview{}, domain.Article{}, err
+	}
+
+	if art.Author.Id != review.AuthorId || art.Version != review.Version ||
+		art.Status != domain.ArticleStatusReviewing {
+		err = n.reviewRepo.Resolve(ctx, review.Id, domain.ReviewStatusCanceled, reviewerId, nil)
+		if err != nil && err != ErrReviewResolved {
+			return domain.ArticleReview{}, domain.Article{}, err
+		}
+		return domain.ArticleReview{}, domain.Article{}, ErrReviewStale
+	}
+
+	return review, art, nil
+}
+
+// @func: revertApproval
+// @date: 2024-01-28 19:05:12
+// @brief: 帖子服务-发表失败时撤销审核通过, 避免帖子未发表而审核显示已通过
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param reviewId
+// @param status 改回待处理或置为失效
+func (n *NormalArticleService) revertApproval(ctx context.Context, reviewId int64, status domain.ReviewStatus) {
+	err := n.reviewRepo.RevertApproval(ctx, reviewId, status)
+	if err != nil {
+		n.l.ERROR("帖子审核通过撤销失败",
+			logger.Error(err),
+			logger.Int[int64]("reviewId", reviewId),
+			logger.Field{"status", status.String()})
+	}
+}
+
+// @func: createReview
+// @date: 2024-01-25 14:33:40
+// @brief: 帖子服务-记录自动审核通过, 仅用于追溯, 失败不影响发表
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param review
+func (n *NormalArticleService) createReview(ctx context.Context, review domain.ArticleReview) {
+	_, err := n.reviewRepo.Create(ctx, review)
+	if err != nil {
+		n.l.ERROR("帖子审核记录保存失败",
+			logger.Error(err),
+			logger.Int[int64]("artId", review.ArtId),
+			logger.Field{"status", review.Status.String()})
+	}
+}
+
+// @func: markRejected
+// @date: 2024-01-25 14:35:06
+// @brief: 帖子服务-制作库标记为审核未通过, 线上库保持原样; 作者已修改时不覆盖
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param art
+// @return int64 制作库最新版本号
+func (n *NormalArticleService) markRejected(ctx context.Context, art domain.Article) int64 {
+	art.Status = domain.ArticleStatusRejected
+	err := n.repo.Update(ctx, art)
+	if err != nil {
+		n.l.WARN("帖子审核未通过状态更新失败",
+			logger.Error(err),
+			logger.Int[int64]("artId", art.Id),
+			logger.Int[int64]("authorId", art.Author.Id))
+		return art.Version
+	}
+
+	return art.Version + 1
+}
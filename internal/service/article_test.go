@@ -8,10 +8,15 @@ import (
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 	"kitbook/internal/domain"
+	"kitbook/internal/events/article"
+	evtmocks "kitbook/internal/events/article/mocks"
 	"kitbook/internal/repository"
 	repomocks "kitbook/internal/repository/mocks"
+	"kitbook/internal/service/moderation"
+	moderationmocks "kitbook/internal/service/moderation/mocks"
 	"kitbook/pkg/logger"
 	"testing"
+	"time"
 )
 
 // @func: TestNormalArticleService_Publish
@@ -225,10 +230,801 @@ func TestNormalArticleService_Publish(t *testing.T) {
 			authorRepo, readerRepo := tc.mock(ctrl)
 			svc := NewNormalArticleServiceV1(authorRepo, readerRepo, logger.NewNopLogger())
 
-			artId, err := svc.Publish(context.Background(), tc.art)
+			artId, _, err := svc.Publish(context.Background(), tc.art)
 
 			assert.Equal(t, tc.wantErr, err)
 			assert.Equal(t, tc.wantArtId, artId)
 		})
 	}
 }
+
+// @func: TestNormalArticleService_Save
+// @date: 2024-01-28 11:40:12
+// @brief: 单元测试-保存草稿, 已有帖子取消等待中的定时发表计划
+// @author: Kewin Li
+// @param t
+func TestNormalArticleService_Save(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock func(ctrl *gomock.Controller) (
+			repository.ArticleRepository,
+			repository.ArticleScheduleRepository)
+
+		art domain.Article
+
+		wantId      int64
+		wantVersion int64
+		wantErr     error
+	}{
+		{
+			name: "新建草稿, 不取消计划",
+			mock: func(ctrl *gomock.Controller) (
+				repository.ArticleRepository,
+				repository.ArticleScheduleRepository) {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().SaveWithRevision(gomock.Any(), domain.Article{
+					Title:  "新标题",
+					Author: domain.Author{Id: 123},
+					Status: domain.ArticleStatusUnpublished,
+				}, domain.RevisionKindSave).Return(int64(2), nil)
+
+				return repo, repomocks.NewMockArticleScheduleRepository(ctrl)
+			},
+
+			art: domain.Article{
+				Title:  "新标题",
+				Author: domain.Author{Id: 123},
+			},
+
+			wantId:      2,
+			wantVersion: 1,
+		},
+		{
+			name: "修改定时帖子, 取消计划",
+			mock: func(ctrl *gomock.Controller) (
+				repository.ArticleRepository,
+				repository.ArticleScheduleRepository) {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				scheduleRepo := repomocks.NewMockArticleScheduleRepository(ctrl)
+				repo.EXPECT().SaveWithRevision(gomock.Any(), domain.Article{
+					Id:      1,
+					Title:   "修改后标题",
+					Author:  domain.Author{Id: 123},
+					Status:  domain.ArticleStatusUnpublished,
+					Version: 3,
+				}, domain.RevisionKindSave).Return(int64(1), nil)
+				scheduleRepo.EXPECT().Cancel(gomock.Any(), int64(1), int64(123)).Return(nil)
+
+				return repo, scheduleRepo
+			},
+
+			art: domain.Article{
+				Id:      1,
+				Title:   "修改后标题",
+				Author:  domain.Author{Id: 123},
+				Version: 3,
+			},
+
+			wantId:      1,
+			wantVersion: 4,
+		},
+		{
+			name: "取消计划失败, 保存仍成功",
+			mock: func(ctrl *gomock.Controller) (
+				repository.ArticleRepository,
+				repository.ArticleScheduleRepository) {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				scheduleRepo := repomocks.NewMockArticleScheduleRepository(ctrl)
+				repo.EXPECT().SaveWithRevision(gomock.Any(), gomock.Any(), domain.RevisionKindSave).
+					Return(int64(1), nil)
+				scheduleRepo.EXPECT().Cancel(gomock.Any(), int64(1), int64(123)).
+					Return(errors.New("模拟数据库错误"))
+
+				return repo, scheduleRepo
+			},
+
+			art: domain.Article{
+				Id:      1,
+				Author:  domain.Author{Id: 123},
+				Version: 3,
+			},
+
+			wantId:      1,
+			wantVersion: 4,
+		},
+		{
+			name: "保存失败, 不取消计划",
+			mock: func(ctrl *gomock.Controller) (
+				repository.ArticleRepository,
+				repository.ArticleScheduleRepository) {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().SaveWithRevision(gomock.Any(), gomock.Any(), domain.RevisionKindSave).
+					Return(int64(1), repository.ErrVersionConflict)
+
+				return repo, repomocks.NewMockArticleScheduleRepository(ctrl)
+			},
+
+			art: domain.Article{
+				Id:     1,
+				Author: domain.Author{Id: 123},
+			},
+
+			wantId:  1,
+			wantErr: ErrVersionConflict,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo, scheduleRepo := tc.mock(ctrl)
+			svc := NewNormalArticleService(repo, nil, scheduleRepo, nil, nil, nil, logger.NewNopLogger())
+
+			id, version, err := svc.Save(context.Background(), tc.art)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantId, id)
+			assert.Equal(t, tc.wantVersion, version)
+		})
+	}
+}
+
+// @func: TestNormalArticleService_Rollback
+// @date: 2024-01-22 11:50:26
+// @brief: 单元测试-回滚到历史版本
+// @author: Kewin Li
+// @param t
+func TestNormalArticleService_Rollback(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock func(ctrl *gomock.Controller) (
+			repository.ArticleRepository,
+			repository.ArticleRevisionRepository,
+			repository.ArticleScheduleRepository)
+
+		artId    int64
+		authorId int64
+		version  int
+
+		wantErr error
+	}{
+		{
+			name: "回滚成功, 生成回滚版本",
+			mock: func(ctrl *gomock.Controller) (
+				repository.ArticleRepository,
+				repository.ArticleRevisionRepository,
+				repository.ArticleScheduleRepository) {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				revisionRepo := repomocks.NewMockArticleRevisionRepository(ctrl)
+				scheduleRepo := repomocks.NewMockArticleScheduleRepository(ctrl)
+
+				revisionRepo.EXPECT().Get(gomock.Any(), int64(1), int64(123), 2).
+					Return(domain.ArticleRevision{
+						ArtId:    1,
+						AuthorId: 123,
+						Version:  2,
+						Kind:     domain.RevisionKindPublish,
+						Title:    "旧标题",
+						Content:  "旧内容",
+						Tags:     []string{"Go"},
+					}, nil)
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).
+					Return(domain.Article{Id: 1, Author: domain.Author{Id: 123}, Version: 3}, nil)
+
+				art := domain.Article{
+					Id:      1,
+					Title:   "旧标题",
+					Content: "旧内容",
+					Tags:    []string{"Go"},
+					Author:  domain.Author{Id: 123},
+					Status:  domain.ArticleStatusUnpublished,
+					Version: 3,
+				}
+				// 修改与回滚版本在同一事务中写入
+				repo.EXPECT().SaveWithRevision(gomock.Any(), art, domain.RevisionKindRollback).
+					Return(int64(1), nil)
+				// 回滚为草稿, 取消等待中的定时发表计划
+				scheduleRepo.EXPECT().Cancel(gomock.Any(), int64(1), int64(123)).
+					Return(repository.ErrScheduleMismatch)
+
+				return repo, revisionRepo, scheduleRepo
+			},
+
+			artId:    1,
+			authorId: 123,
+			version:  2,
+		},
+		{
+			name: "版本不存在或不属于该作者",
+			mock: func(ctrl *gomock.Controller) (
+				repository.ArticleRepository,
+				repository.ArticleRevisionRepository,
+				repository.ArticleScheduleRepository) {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				revisionRepo := repomocks.NewMockArticleRevisionRepository(ctrl)
+				scheduleRepo := repomocks.NewMockArticleScheduleRepository(ctrl)
+
+				revisionRepo.EXPECT().Get(gomock.Any(), int64(1), int64(456), 2).
+					Return(domain.ArticleRevision{}, repository.ErrRevisionNotFound)
+
+				return repo, revisionRepo, scheduleRepo
+			},
+
+			artId:    1,
+			authorId: 456,
+			version:  2,
+
+			wantErr: ErrRevisionNotFound,
+		},
+		{
+			name: "保存草稿失败",
+			mock: func(ctrl *gomock.Controller) (
+				repository.ArticleRepository,
+				repository.ArticleRevisionRepository,
+				repository.ArticleScheduleRepository) {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				revisionRepo := repomocks.NewMockArticleRevisionRepository(ctrl)
+				scheduleRepo := repomocks.NewMockArticleScheduleRepository(ctrl)
+
+				revisionRepo.EXPECT().Get(gomock.Any(), int64(1), int64(123), 2).
+					Return(domain.ArticleRevision{ArtId: 1, AuthorId: 123, Version: 2}, nil)
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).
+					Return(domain.Article{Id: 1, Author: domain.Author{Id: 123}, Version: 3}, nil)
+				repo.EXPECT().SaveWithRevision(gomock.Any(), gomock.Any(), domain.RevisionKindRollback).
+					Return(int64(1), errors.New("模拟数据库错误"))
+
+				return repo, revisionRepo, scheduleRepo
+			},
+
+			artId:    1,
+			authorId: 123,
+			version:  2,
+
+			wantErr: errors.New("模拟数据库错误"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo, revisionRepo, scheduleRepo := tc.mock(ctrl)
+			svc := NewNormalArticleService(repo, revisionRepo, scheduleRepo, nil, nil, nil, logger.NewNopLogger())
+
+			err := svc.Rollback(context.Background(), tc.artId, tc.authorId, tc.version)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+// @func: TestNormalArticleService_Autosave
+// @date: 2024-01-23 11:20:36
+// @brief: 单元测试-自动保存草稿
+// @author: Kewin Li
+// @param t
+func TestNormalArticleService_Autosave(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock func(ctrl *gomock.Controller) repository.ArticleRepository
+
+		art domain.Article
+
+		wantId      int64
+		wantVersion int64
+		wantErr     error
+	}{
+		{
+			name: "修改草稿成功, 保持原状态, 版本号加1",
+			mock: func(ctrl *gomock.Controller) repository.ArticleRepository {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().Update(gomock.Any(), domain.Article{
+					Id:      1,
+					Title:   "草稿标题",
+					Author:  domain.Author{Id: 123},
+					Status:  domain.ArticleStatusUnknow,
+					Version: 3,
+				}).Return(nil)
+
+				return repo
+			},
+
+			art: domain.Article{
+				Id:      1,
+				Title:   "草稿标题",
+				Author:  domain.Author{Id: 123},
+				Version: 3,
+			},
+
+			wantId:      1,
+			wantVersion: 4,
+		},
+		{
+			name: "新建草稿成功",
+			mock: func(ctrl *gomock.Controller) repository.ArticleRepository {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().Create(gomock.Any(), domain.Article{
+					Title:  "草稿标题",
+					Author: domain.Author{Id: 123},
+					Status: domain.ArticleStatusUnpublished,
+				}).Return(int64(2), nil)
+
+				return repo
+			},
+
+			art: domain.Article{
+				Title:  "草稿标题",
+				Author: domain.Author{Id: 123},
+			},
+
+			wantId:      2,
+			wantVersion: 1,
+		},
+		{
+			name: "版本号已过期",
+			mock: func(ctrl *gomock.Controller) repository.ArticleRepository {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().Update(gomock.Any(), gomock.Any()).
+					Return(repository.ErrVersionConflict)
+
+				return repo
+			},
+
+			art: domain.Article{
+				Id:      1,
+				Author:  domain.Author{Id: 123},
+				Version: 2,
+			},
+
+			wantId:  1,
+			wantErr: ErrVersionConflict,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			svc := NewNormalArticleService(tc.mock(ctrl), nil, nil, nil, nil, nil, logger.NewNopLogger())
+
+			id, version, err := svc.Autosave(context.Background(), tc.art)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantId, id)
+			assert.Equal(t, tc.wantVersion, version)
+		})
+	}
+}
+
+// @func: TestNormalArticleService_PublishDue
+// @date: 2024-01-22 17:05:40
+// @brief: 单元测试-发表到期的定时帖子
+// @author: Kewin Li
+// @param t
+func TestNormalArticleService_PublishDue(t *testing.T) {
+	now := time.Now()
+	schedule := domain.ArticleSchedule{
+		Id:          10,
+		ArtId:       1,
+		AuthorId:    123,
+		PublishTime: now.Add(-time.Second),
+		Status:      domain.ScheduleStatusWaiting,
+	}
+	art := domain.Article{
+		Id:      1,
+		Title:   "定时标题",
+		Content: "定时内容",
+		Author:  domain.Author{Id: 123},
+		Status:  domain.ArticleStatusScheduled,
+		Version: 2,
+	}
+	reviewing := art
+	reviewing.Status = domain.ArticleStatusReviewing
+
+	testCases := []struct {
+		name string
+
+		mock func(ctrl *gomock.Controller) (
+			repository.ArticleRepository,
+			repository.ArticleRevisionRepository,
+			repository.ArticleScheduleRepository,
+			repository.ArticleReviewRepository,
+			moderation.Moderator,
+			article.Producer)
+
+		wantCnt int
+		wantErr error
+	}{
+		{
+			name: "认领成功, 审核通过并发表",
+			mock: func(ctrl *gomock.Controller) (
+				repository.ArticleRepository,
+				repository.ArticleRevisionRepository,
+				repository.ArticleScheduleRepository,
+				repository.ArticleReviewRepository,
+				moderation.Moderator,
+				article.Producer) {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				revisionRepo := repomocks.NewMockArticleRevisionRepository(ctrl)
+				scheduleRepo := repomocks.NewMockArticleScheduleRepository(ctrl)
+				reviewRepo := repomocks.NewMockArticleReviewRepository(ctrl)
+				moderator := moderationmocks.NewMockModerator(ctrl)
+				producer := evtmocks.NewMockProducer(ctrl)
+
+				scheduleRepo.EXPECT().FindDue(gomock.Any(), now, now.Add(-scheduleClaimTimeout), schedulePublishBatch).
+					Return([]domain.ArticleSchedule{schedule}, nil)
+				scheduleRepo.EXPECT().Claim(gomock.Any(), schedule).Return(true, nil)
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(art, nil)
+				repo.EXPECT().SaveWithRevision(gomock.Any(), reviewing, domain.RevisionKindSave).Return(int64(1), nil)
+				moderator.EXPECT().Moderate(gomock.Any(), gomock.Any()).
+					Return(domain.ModerationResult{Verdict: domain.ModerationVerdictPass}, nil)
+				reviewRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(domain.ArticleReview{}, nil)
+
+				published := art
+				published.Status = domain.ArticleStatusPublished
+				published.Version = 3
+				repo.EXPECT().SyncWithRevision(gomock.Any(), published, domain.RevisionKindPublish).Return(int64(1), nil)
+				producer.EXPECT().ProducerPublishEvent(gomock.Any()).Return(nil).AnyTimes()
+				scheduleRepo.EXPECT().Finish(gomock.Any(), int64(10), domain.ScheduleStatusDone, "").Return(nil)
+
+				return repo, revisionRepo, scheduleRepo, reviewRepo, moderator, producer
+			},
+
+			wantCnt: 1,
+		},
+		{
+			name: "帖子已改为草稿, 计划取消",
+			mock: func(ctrl *gomock.Controller) (
+				repository.ArticleRepository,
+				repository.ArticleRevisionRepository,
+				repository.ArticleScheduleRepository,
+				repository.ArticleReviewRepository,
+				moderation.Moderator,
+				article.Producer) {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				scheduleRepo := repomocks.NewMockArticleScheduleRepository(ctrl)
+
+				draft := art
+				draft.Status = domain.ArticleStatusUnpublished
+
+				scheduleRepo.EXPECT().FindDue(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return([]domain.ArticleSchedule{schedule}, nil)
+				scheduleRepo.EXPECT().Claim(gomock.Any(), schedule).Return(true, nil)
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(draft, nil)
+				scheduleRepo.EXPECT().Finish(gomock.Any(), int64(10), domain.ScheduleStatusCanceled,
+					errScheduleOutdated.Error()).Return(nil)
+
+				return repo, repomocks.NewMockArticleRevisionRepository(ctrl), scheduleRepo,
+					repomocks.NewMockArticleReviewRepository(ctrl),
+					moderationmocks.NewMockModerator(ctrl), evtmocks.NewMockProducer(ctrl)
+			},
+		},
+		{
+			name: "已被其他结点认领",
+			mock: func(ctrl *gomock.Controller) (
+				repository.ArticleRepository,
+				repository.ArticleRevisionRepository,
+				repository.ArticleScheduleRepository,
+				repository.ArticleReviewRepository,
+				moderation.Moderator,
+				article.Producer) {
+				scheduleRepo := repomocks.NewMockArticleScheduleRepository(ctrl)
+
+				scheduleRepo.EXPECT().FindDue(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return([]domain.ArticleSchedule{schedule}, nil)
+				scheduleRepo.EXPECT().Claim(gomock.Any(), schedule).Return(false, nil)
+
+				return repomocks.NewMockArticleRepository(ctrl), repomocks.NewMockArticleRevisionRepository(ctrl),
+					scheduleRepo, repomocks.NewMockArticleReviewRepository(ctrl),
+					moderationmocks.NewMockModerator(ctrl), evtmocks.NewMockProducer(ctrl)
+			},
+		},
+		{
+			name: "转人工审核, 计划完成",
+			mock: func(ctrl *gomock.Controller) (
+				repository.ArticleRepository,
+				repository.ArticleRevisionRepository,
+				repository.ArticleScheduleRepository,
+				repository.ArticleReviewRepository,
+				moderation.Moderator,
+				article.Producer) {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				scheduleRepo := repomocks.NewMockArticleScheduleRepository(ctrl)
+				reviewRepo := repomocks.NewMockArticleReviewRepository(ctrl)
+				moderator := moderationmocks.NewMockModerator(ctrl)
+
+				scheduleRepo.EXPECT().FindDue(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return([]domain.ArticleSchedule{schedule}, nil)
+				scheduleRepo.EXPECT().Claim(gomock.Any(), schedule).Return(true, nil)
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(art, nil)
+				repo.EXPECT().SaveWithRevision(gomock.Any(), reviewing, domain.RevisionKindSave).Return(int64(1), nil)
+				moderator.EXPECT().Moderate(gomock.Any(), gomock.Any()).
+					Return(domain.ModerationResult{
+						Verdict: domain.ModerationVerdictReview,
+						Reasons: []string{"包含敏感词, 需人工审核: 投资"},
+					}, nil)
+				reviewRepo.EXPECT().Create(gomock.Any(), domain.ArticleReview{
+					ArtId:    1,
+					AuthorId: 123,
+					Version:  3,
+					Title:    "定时标题",
+					Content:  "定时内容",
+					Status:   domain.ReviewStatusPending,
+					Reasons:  []string{"包含敏感词, 需人工审核: 投资"},
+				}).Return(domain.ArticleReview{Id: 5}, nil)
+				scheduleRepo.EXPECT().Finish(gomock.Any(), int64(10), domain.ScheduleStatusDone, "").Return(nil)
+
+				return repo, repomocks.NewMockArticleRevisionRepository(ctrl), scheduleRepo, reviewRepo,
+					moderator, evtmocks.NewMockProducer(ctrl)
+			},
+
+			wantCnt: 1,
+		},
+		{
+			name: "审核未通过, 记录原因并通知作者",
+			mock: func(ctrl *gomock.Controller) (
+				repository.ArticleRepository,
+				repository.ArticleRevisionRepository,
+				repository.ArticleScheduleRepository,
+				repository.ArticleReviewRepository,
+				moderation.Moderator,
+				article.Producer) {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				scheduleRepo := repomocks.NewMockArticleScheduleRepository(ctrl)
+				reviewRepo := repomocks.NewMockArticleReviewRepository(ctrl)
+				moderator := moderationmocks.NewMockModerator(ctrl)
+				producer := evtmocks.NewMockProducer(ctrl)
+				reasons := []string{"包含违禁词: 赌博"}
+
+				scheduleRepo.EXPECT().FindDue(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return([]domain.ArticleSchedule{schedule}, nil)
+				scheduleRepo.EXPECT().Claim(gomock.Any(), schedule).Return(true, nil)
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(art, nil)
+				repo.EXPECT().SaveWithRevision(gomock.Any(), reviewing, domain.RevisionKindSave).Return(int64(1), nil)
+				moderator.EXPECT().Moderate(gomock.Any(), gomock.Any()).
+					Return(domain.ModerationResult{Verdict: domain.ModerationVerdictReject, Reasons: reasons}, nil)
+				reviewRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(domain.ArticleReview{}, nil)
+
+				rejected := art
+				rejected.Status = domain.ArticleStatusRejected
+				rejected.Version = 3
+				repo.EXPECT().Update(gomock.Any(), rejected).Return(nil)
+				reviewRepo.EXPECT().FindLatest(gomock.Any(), int64(1), int64(123)).
+					Return(domain.ArticleReview{Status: domain.ReviewStatusRejected, Reasons: reasons}, nil)
+				scheduleRepo.EXPECT().Finish(gomock.Any(), int64(10), domain.ScheduleStatusFailed,
+					"帖子审核未通过: 包含违禁词: 赌博").Return(nil)
+				producer.EXPECT().ProducerScheduleFailedEvent(article.ScheduleFailedEvent{
+					ArtId:       1,
+					AuthorId:    123,
+					PublishTime: schedule.PublishTime.UnixMilli(),
+					Reason:      "帖子审核未通过: 包含违禁词: 赌博",
+				}).Return(nil)
+
+				return repo, repomocks.NewMockArticleRevisionRepository(ctrl), scheduleRepo, reviewRepo,
+					moderator, producer
+			},
+		},
+		{
+			name: "发表失败, 记录原因并通知作者",
+			mock: func(ctrl *gomock.Controller) (
+				repository.ArticleRepository,
+				repository.ArticleRevisionRepository,
+				repository.ArticleScheduleRepository,
+				repository.ArticleReviewRepository,
+				moderation.Moderator,
+				article.Producer) {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				scheduleRepo := repomocks.NewMockArticleScheduleRepository(ctrl)
+				reviewRepo := repomocks.NewMockArticleReviewRepository(ctrl)
+				moderator := moderationmocks.NewMockModerator(ctrl)
+				producer := evtmocks.NewMockProducer(ctrl)
+
+				scheduleRepo.EXPECT().FindDue(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return([]domain.ArticleSchedule{schedule}, nil)
+				scheduleRepo.EXPECT().Claim(gomock.Any(), schedule).Return(true, nil)
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(art, nil)
+				repo.EXPECT().SaveWithRevision(gomock.Any(), gomock.Any(), domain.RevisionKindSave).Return(int64(1), nil)
+				moderator.EXPECT().Moderate(gomock.Any(), gomock.Any()).
+					Return(domain.ModerationResult{Verdict: domain.ModerationVerdictPass}, nil)
+				reviewRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(domain.ArticleReview{}, nil)
+				repo.EXPECT().SyncWithRevision(gomock.Any(), gomock.Any(), domain.RevisionKindPublish).
+					Return(int64(0), errors.New("模拟数据库错误"))
+				scheduleRepo.EXPECT().Finish(gomock.Any(), int64(10), domain.ScheduleStatusFailed, "模拟数据库错误").Return(nil)
+				producer.EXPECT().ProducerScheduleFailedEvent(article.ScheduleFailedEvent{
+					ArtId:       1,
+					AuthorId:    123,
+					PublishTime: schedule.PublishTime.UnixMilli(),
+					Reason:      "模拟数据库错误",
+				}).Return(nil)
+
+				return repo, repomocks.NewMockArticleRevisionRepository(ctrl), scheduleRepo, reviewRepo,
+					moderator, producer
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo, revisionRepo, scheduleRepo, reviewRepo, moderator, producer := tc.mock(ctrl)
+			svc := NewNormalArticleService(repo, revisionRepo, scheduleRepo, reviewRepo, moderator, producer,
+				logger.NewNopLogger())
+
+			cnt, err := svc.PublishDue(context.Background(), now)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantCnt, cnt)
+		})
+	}
+}
+
+// @func: TestNormalArticleService_ApproveReview
+// @date: 2024-01-25 15:10:24
+// @brief: 单元测试-人工审核通过
+// @author: Kewin Li
+// @param t
+func TestNormalArticleService_ApproveReview(t *testing.T) {
+	review := domain.ArticleReview{
+		Id:       5,
+		ArtId:    1,
+		AuthorId: 123,
+		Version:  3,
+		Status:   domain.ReviewStatusPending,
+	}
+	art := domain.Article{
+		Id:      1,
+		Title:   "标题",
+		Author:  domain.Author{Id: 123},
+		Status:  domain.ArticleStatusReviewing,
+		Version: 3,
+	}
+
+	testCases := []struct {
+		name string
+
+		mock func(ctrl *gomock.Controller) (
+			repository.ArticleRepository,
+			repository.ArticleRevisionRepository,
+			repository.ArticleReviewRepository,
+			article.Producer)
+
+		wantErr error
+	}{
+		{
+			name: "审核通过并发表",
+			mock: func(ctrl *gomock.Controller) (
+				repository.ArticleRepository,
+				repository.ArticleRevisionRepository,
+				repository.ArticleReviewRepository,
+				article.Producer) {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				revisionRepo := repomocks.NewMockArticleRevisionRepository(ctrl)
+				reviewRepo := repomocks.NewMockArticleReviewRepository(ctrl)
+				producer := evtmocks.NewMockProducer(ctrl)
+
+				reviewRepo.EXPECT().FindById(gomock.Any(), int64(5)).Return(review, nil)
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(art, nil)
+				reviewRepo.EXPECT().Resolve(gomock.Any(), int64(5), domain.ReviewStatusApproved, int64(1), nil).
+					Return(nil)
+
+				published := art
+				published.Status = domain.ArticleStatusPublished
+				repo.EXPECT().SyncWithRevision(gomock.Any(), published, domain.RevisionKindPublish).Return(int64(1), nil)
+				producer.EXPECT().ProducerPublishEvent(gomock.Any()).Return(nil).AnyTimes()
+
+				return repo, revisionRepo, reviewRepo, producer
+			},
+		},
+		{
+			name: "作者已修改, 审核失效",
+			mock: func(ctrl *gomock.Controller) (
+				repository.ArticleRepository,
+				repository.ArticleRevisionRepository,
+				repository.ArticleReviewRepository,
+				article.Producer) {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				reviewRepo := repomocks.NewMockArticleReviewRepository(ctrl)
+
+				edited := art
+				edited.Status = domain.ArticleStatusUnpublished
+				edited.Version = 4
+				reviewRepo.EXPECT().FindById(gomock.Any(), int64(5)).Return(review, nil)
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(edited, nil)
+				reviewRepo.EXPECT().Resolve(gomock.Any(), int64(5), domain.ReviewStatusCanceled, int64(1), nil).
+					Return(nil)
+
+				return repo, repomocks.NewMockArticleRevisionRepository(ctrl), reviewRepo,
+					evtmocks.NewMockProducer(ctrl)
+			},
+
+			wantErr: ErrReviewStale,
+		},
+		{
+			name: "已被其他审核员处理",
+			mock: func(ctrl *gomock.Controller) (
+				repository.ArticleRepository,
+				repository.ArticleRevisionRepository,
+				repository.ArticleReviewRepository,
+				article.Producer) {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				reviewRepo := repomocks.NewMockArticleReviewRepository(ctrl)
+
+				reviewRepo.EXPECT().FindById(gomock.Any(), int64(5)).Return(review, nil)
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(art, nil)
+				reviewRepo.EXPECT().Resolve(gomock.Any(), int64(5), domain.ReviewStatusApproved, int64(1), nil).
+					Return(repository.ErrReviewResolved)
+
+				return repo, repomocks.NewMockArticleRevisionRepository(ctrl), reviewRepo,
+					evtmocks.NewMockProducer(ctrl)
+			},
+
+			wantErr: ErrReviewResolved,
+		},
+		{
+			name: "发表失败, 审核退回待处理",
+			mock: func(ctrl *gomock.Controller) (
+				repository.ArticleRepository,
+				repository.ArticleRevisionRepository,
+				repository.ArticleReviewRepository,
+				article.Producer) {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				reviewRepo := repomocks.NewMockArticleReviewRepository(ctrl)
+
+				reviewRepo.EXPECT().FindById(gomock.Any(), int64(5)).Return(review, nil)
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(art, nil)
+				reviewRepo.EXPECT().Resolve(gomock.Any(), int64(5), domain.ReviewStatusApproved, int64(1), nil).
+					Return(nil)
+				repo.EXPECT().SyncWithRevision(gomock.Any(), gomock.Any(), domain.RevisionKindPublish).
+					Return(int64(0), errors.New("模拟数据库错误"))
+				reviewRepo.EXPECT().RevertApproval(gomock.Any(), int64(5), domain.ReviewStatusPending).Return(nil)
+
+				return repo, repomocks.NewMockArticleRevisionRepository(ctrl), reviewRepo,
+					evtmocks.NewMockProducer(ctrl)
+			},
+
+			wantErr: errors.New("模拟数据库错误"),
+		},
+		{
+			name: "发表时作者已修改, 审核失效",
+			mock: func(ctrl *gomock.Controller) (
+				repository.ArticleRepository,
+				repository.ArticleRevisionRepository,
+				repository.ArticleReviewRepository,
+				article.Producer) {
+				repo := repomocks.NewMockArticleRepository(ctrl)
+				reviewRepo := repomocks.NewMockArticleReviewRepository(ctrl)
+
+				reviewRepo.EXPECT().FindById(gomock.Any(), int64(5)).Return(review, nil)
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(art, nil)
+				reviewRepo.EXPECT().Resolve(gomock.Any(), int64(5), domain.ReviewStatusApproved, int64(1), nil).
+					Return(nil)
+				repo.EXPECT().SyncWithRevision(gomock.Any(), gomock.Any(), domain.RevisionKindPublish).
+					Return(int64(0), repository.ErrVersionConflict)
+				reviewRepo.EXPECT().RevertApproval(gomock.Any(), int64(5), domain.ReviewStatusCanceled).Return(nil)
+
+				return repo, repomocks.NewMockArticleRevisionRepository(ctrl), reviewRepo,
+					evtmocks.NewMockProducer(ctrl)
+			},
+
+			wantErr: ErrReviewStale,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo, revisionRepo, reviewRepo, producer := tc.mock(ctrl)
+			svc := NewNormalArticleService(repo, revisionRepo, nil, reviewRepo, nil, producer, logger.NewNopLogger())
+
+			err := svc.ApproveReview(context.Background(), 5, 1)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"kitbook/internal/domain"
+	"kitbook/internal/repository"
+)
+
+var ErrCollectionNotFound = errors.New("收藏夹不存在或不可见")
+
+type CollectionService interface {
+	CreateCollection(ctx context.Context, c domain.Collection) (int64, error)
+	UpdateCollection(ctx context.Context, c domain.Collection) error
+	DeleteCollection(ctx context.Context, id int64, uid int64) error
+	ListCollections(ctx context.Context, uid int64, viewer int64) ([]domain.Collection, error)
+	ListItems(ctx context.Context, collectId int64, viewer int64, offset int, limit int) ([]domain.CollectionItem, error)
+	MoveItems(ctx context.Context, uid int64, srcId int64, dstId int64, biz string, bizIds []int64) (int64, error)
+}
+
+type NormalCollectionService struct {
+	repo repository.CollectionRepository
+}
+
+func NewNormalCollectionService(repo repository.CollectionRepository) CollectionService {
+	return &NormalCollectionService{
+		repo: repo,
+	}
+}
+
+// @func: CreateCollection
+// @date: 2024-01-14 15:20:35
+// @brief: 收藏夹服务-新建收藏夹
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param c
+// @return int64
+// @return error
+func (n *NormalCollectionService) CreateCollection(ctx context.Context, c domain.Collection) (int64, error) {
+	return n.repo.Create(ctx, c)
+}
+
+// @func: UpdateCollection
+// @date: 2024-01-14 15:21:18
+// @brief: 收藏夹服务-修改收藏夹
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param c
+// @return error
+func (n *NormalCollectionService) UpdateCollection(ctx context.Context, c domain.Collection) error {
+	err := n.repo.Update(ctx, c)
+	if err == repository.ErrCollectionMismatch {
+		return ErrInvalidUpdate
+	}
+	return err
+}
+
+// @func: DeleteCollection
+// @date: 2024-01-14 15:22:03
+// @brief: 收藏夹服务-删除收藏夹及其中的收藏
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param id
+// @param uid
+// @return error
+func (n *NormalCollectionService) DeleteCollection(ctx context.Context, id int64, uid int64) error {
+	err := n.repo.Delete(ctx, id, uid)
+	if err == repository.ErrCollectionMismatch {
+		return ErrInvalidUpdate
+	}
+	return err
+}
+
+// @func: ListCollections
+// @date: 2024-01-14 15:23:40
+// @brief: 收藏夹服务-查询收藏夹列表, 查看他人时不展示私密收藏夹
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param uid 收藏夹所属用户
+// @param viewer 查看者
+// @return []domain.Collection
+// @return error
+func (n *NormalCollectionService) ListCollections(ctx context.Context, uid int64, viewer int64) ([]domain.Collection, error) {
+	return n.repo.ListByUser(ctx, uid, uid == viewer)
+}
+
+// @func: ListItems
+// @date: 2024-01-14 15:26:12
+// @brief: 收藏夹服务-分页查询收藏夹中的收藏
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param collectId 0表示查看者自己的默认收藏夹
+// @param viewer 查看者
+// @param offset
+// @param limit
+// @return []domain.CollectionItem
+// @return error
+func (n *NormalCollectionService) ListItems(ctx context.Context, collectId int64, viewer int64, offset int, limit int) ([]domain.CollectionItem, error) {
+	// 默认收藏夹
+	if collectId == 0 {
+		return n.repo.ListItems(ctx, viewer, 0, offset, limit)
+	}
+
+	c, err := n.repo.FindById(ctx, collectId)
+	switch err {
+	case nil:
+	case repository.ErrCollectionNotFound:
+		return nil, ErrCollectionNotFound
+	default:
+		return nil, err
+	}
+
+	// 私密收藏夹仅自己可见
+	if c.Private && c.Uid != viewer {
+		return nil, ErrCollectionNotFound
+	}
+
+	return n.repo.ListItems(ctx, c.Uid, collectId, offset, limit)
+}
+
+// @func: MoveItems
+// @date: 2024-01-14 15:31:47
+// @brief: 收藏夹服务-在自己的收藏夹之间移动收藏
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param uid
+// @param srcId 0表示默认收藏夹
+// @param dstId 0表示默认收藏夹
+// @param biz
+// @param bizIds
+// @return int64 实际移动的条数
+// @return error
+func (n *NormalCollectionService) MoveItems(ctx context.Context, uid int64, srcId int64, dstId int64, biz string, bizIds []int64) (int64, error) {
+	if srcId == dstId || len(bizIds) == 0 {
+		return 0, nil
+	}
+
+	// 源、目标收藏夹都必须属于自己
+	for _, id := range []int64{srcId, dstId} {
+		if id == 0 {
+			continue
+		}
+
+		c, err := n.repo.FindById(ctx, id)
+		switch err {
+		case nil:
+		case repository.ErrCollectionNotFound:
+			return 0, ErrInvalidUpdate
+		default:
+			return 0, err
+		}
+
+		if c.Uid != uid {
+			return 0, ErrInvalidUpdate
+		}
+	}
+
+	return n.repo.MoveItems(ctx, uid, srcId, dstId, biz, bizIds)
+}
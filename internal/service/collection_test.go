@@ -0,0 +1,157 @@
+// Package service
+// @Description: 收藏夹服务-单元测试
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"kitbook/internal/domain"
+	"kitbook/internal/repository"
+	repomocks "kitbook/internal/repository/mocks"
+	"testing"
+)
+
+// @func: TestNormalCollectionService_ListItems
+// @date: 2024-01-14 16:40:22
+// @brief: 单元测试-查询收藏夹中的收藏
+// @author: Kewin Li
+// @param t
+func TestNormalCollectionService_ListItems(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock func(ctrl *gomock.Controller) repository.CollectionRepository
+
+		collectId int64
+		viewer    int64
+
+		wantItems []domain.CollectionItem
+		wantErr   error
+	}{
+		{
+			name: "查看他人的公开收藏夹",
+			mock: func(ctrl *gomock.Controller) repository.CollectionRepository {
+				repo := repomocks.NewMockCollectionRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(1)).
+					Return(domain.Collection{Id: 1, Uid: 100}, nil)
+				repo.EXPECT().ListItems(gomock.Any(), int64(100), int64(1), 0, 10).
+					Return([]domain.CollectionItem{{Id: 1, CollectId: 1}}, nil)
+				return repo
+			},
+			collectId: 1,
+			viewer:    200,
+			wantItems: []domain.CollectionItem{{Id: 1, CollectId: 1}},
+		},
+		{
+			name: "查看他人的私密收藏夹",
+			mock: func(ctrl *gomock.Controller) repository.CollectionRepository {
+				repo := repomocks.NewMockCollectionRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(1)).
+					Return(domain.Collection{Id: 1, Uid: 100, Private: true}, nil)
+				return repo
+			},
+			collectId: 1,
+			viewer:    200,
+			wantErr:   ErrCollectionNotFound,
+		},
+		{
+			name: "查看自己的默认收藏夹",
+			mock: func(ctrl *gomock.Controller) repository.CollectionRepository {
+				repo := repomocks.NewMockCollectionRepository(ctrl)
+				repo.EXPECT().ListItems(gomock.Any(), int64(200), int64(0), 0, 10).
+					Return([]domain.CollectionItem{{Id: 2}}, nil)
+				return repo
+			},
+			collectId: 0,
+			viewer:    200,
+			wantItems: []domain.CollectionItem{{Id: 2}},
+		},
+		{
+			name: "收藏夹不存在",
+			mock: func(ctrl *gomock.Controller) repository.CollectionRepository {
+				repo := repomocks.NewMockCollectionRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(3)).
+					Return(domain.Collection{}, repository.ErrCollectionNotFound)
+				return repo
+			},
+			collectId: 3,
+			viewer:    200,
+			wantErr:   ErrCollectionNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			svc := NewNormalCollectionService(tc.mock(ctrl))
+			items, err := svc.ListItems(context.Background(), tc.collectId, tc.viewer, 0, 10)
+
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantItems, items)
+		})
+	}
+}
+
+// @func: TestNormalCollectionService_MoveItems
+// @date: 2024-01-14 16:52:05
+// @brief: 单元测试-移动收藏
+// @author: Kewin Li
+// @param t
+func TestNormalCollectionService_MoveItems(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock func(ctrl *gomock.Controller) repository.CollectionRepository
+
+		srcId int64
+		dstId int64
+
+		wantCnt int64
+		wantErr error
+	}{
+		{
+			name: "从默认收藏夹移动到自己的收藏夹",
+			mock: func(ctrl *gomock.Controller) repository.CollectionRepository {
+				repo := repomocks.NewMockCollectionRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(2)).
+					Return(domain.Collection{Id: 2, Uid: 100}, nil)
+				repo.EXPECT().MoveItems(gomock.Any(), int64(100), int64(0), int64(2), "article", []int64{10, 11}).
+					Return(int64(2), nil)
+				return repo
+			},
+			srcId:   0,
+			dstId:   2,
+			wantCnt: 2,
+		},
+		{
+			name: "目标收藏夹不属于自己",
+			mock: func(ctrl *gomock.Controller) repository.CollectionRepository {
+				repo := repomocks.NewMockCollectionRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(1)).
+					Return(domain.Collection{Id: 1, Uid: 100}, nil)
+				repo.EXPECT().FindById(gomock.Any(), int64(3)).
+					Return(domain.Collection{Id: 3, Uid: 300}, nil)
+				return repo
+			},
+			srcId:   1,
+			dstId:   3,
+			wantErr: ErrInvalidUpdate,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			svc := NewNormalCollectionService(tc.mock(ctrl))
+			cnt, err := svc.MoveItems(context.Background(), 100, tc.srcId, tc.dstId, "article", []int64{10, 11})
+
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantCnt, cnt)
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"kitbook/internal/domain"
+	"kitbook/internal/repository"
+	"math"
+)
+
+var (
+	ErrInvalidComment        = errors.New("回复的评论不存在")
+	ErrCommentTargetNotFound = errors.New("评论的帖子不存在")
+)
+
+// 评论业务类型-帖子
+const commentBizArticle = "article"
+
+type CommentService interface {
+	CreateComment(ctx context.Context, c domain.Comment) (int64, error)
+	DeleteComment(ctx context.Context, id int64, uid int64) error
+	GetCommentList(ctx context.Context, biz string, bizId int64, minId int64, limit int) ([]domain.Comment, error)
+	GetMoreReplies(ctx context.Context, rootId int64, maxId int64, limit int) ([]domain.Comment, error)
+}
+
+type NormalCommentService struct {
+	repo    repository.CommentRepository
+	artRepo repository.ArticleRepository
+
+	// 每条根评论预加载的回复条数
+	replyLimit int
+}
+
+func NewNormalCommentService(repo repository.CommentRepository, artRepo repository.ArticleRepository) CommentService {
+	return &NormalCommentService{
+		repo:       repo,
+		artRepo:    artRepo,
+		replyLimit: 3,
+	}
+}
+
+// @func: CreateComment
+// @date: 2024-01-08 22:05:14
+// @brief: 评论服务-发表评论/回复评论
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param c
+// @return int64
+// @return error
+func (n *NormalCommentService) CreateComment(ctx context.Context, c domain.Comment) (int64, error) {
+	err := n.checkTarget(ctx, c)
+	if err != nil {
+		return 0, err
+	}
+
+	// 根评论
+	if c.ParentComment == nil {
+		c.RootComment = nil
+		return n.repo.CreateComment(ctx, c)
+	}
+
+	// 回复评论, 根评论以回复的评论为准, 不信任前端传入
+	parent, err := n.repo.FindById(ctx, c.ParentComment.Id)
+	switch err {
+	case nil:
+	case repository.ErrCommentNotFound:
+		return 0, ErrInvalidComment
+	default:
+		return 0, err
+	}
+
+	if parent.Biz != c.Biz || parent.BizId != c.BizId {
+		return 0, ErrInvalidComment
+	}
+
+	if parent.IsRoot() {
+		c.RootComment = &domain.Comment{Id: parent.Id}
+	} else {
+		c.RootComment = &domain.Comment{Id: parent.RootComment.Id}
+	}
+
+	return n.repo.CreateComment(ctx, c)
+}
+
+// @func: checkTarget
+// @date: 2024-01-08 22:08:36
+// @brief: 评论服务-校验评论对象, 目前只支持评论已发表的帖子
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param c
+// @return error
+func (n *NormalCommentService) checkTarget(ctx context.Context, c domain.Comment) error {
+	if c.Biz != commentBizArticle {
+		return ErrCommentTargetNotFound
+	}
+
+	art, err := n.artRepo.GetPubById(ctx, c.BizId)
+	switch err {
+	case nil:
+	case repository.ErrArticleNotFound:
+		return ErrCommentTargetNotFound
+	default:
+		return err
+	}
+
+	if art.Status != domain.ArticleStatusPublished {
+		return ErrCommentTargetNotFound
+	}
+
+	return nil
+}
+
+// @func: DeleteComment
+// @date: 2024-01-08 22:12:47
+// @brief: 评论服务-评论者删除评论
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param id
+// @param uid
+// @return error
+func (n *NormalCommentService) DeleteComment(ctx context.Context, id int64, uid int64) error {
+	err := n.repo.DeleteComment(ctx, id, uid)
+	if err == repository.ErrCommentMismatch || err == repository.ErrCommentNotFound {
+		return ErrInvalidUpdate
+	}
+	return err
+}
+
+// @func: GetCommentList
+// @date: 2024-01-08 22:15:20
+// @brief: 评论服务-游标分页查询根评论
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param biz
+// @param bizId
+// @param minId 上一页最后一条根评论ID, <=0表示查询第一页
+// @param limit
+// @return []domain.Comment
+// @return error
+func (n *NormalCommentService) GetCommentList(ctx context.Context, biz string, bizId int64, minId int64, limit int) ([]domain.Comment, error) {
+	if minId <= 0 {
+		minId = math.MaxInt64
+	}
+	return n.repo.FindByBiz(ctx, biz, bizId, minId, limit, n.replyLimit)
+}
+
+// @func: GetMoreReplies
+// @date: 2024-01-08 22:18:03
+// @brief: 评论服务-游标分页查询更多回复
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param rootId
+// @param maxId 上一页最后一条回复ID
+// @param limit
+// @return []domain.Comment
+// @return error
+func (n *NormalCommentService) GetMoreReplies(ctx context.Context, rootId int64, maxId int64, limit int) ([]domain.Comment, error) {
+	return n.repo.GetMoreReplies(ctx, rootId, maxId, limit)
+}
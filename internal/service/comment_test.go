@@ -0,0 +1,231 @@
+// Package service
+// @Description: 评论服务-单元测试
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"kitbook/internal/domain"
+	"kitbook/internal/repository"
+	repomocks "kitbook/internal/repository/mocks"
+	"testing"
+)
+
+// @func: TestNormalCommentService_CreateComment
+// @date: 2024-01-08 23:10:26
+// @brief: 单元测试-发表评论/回复评论
+// @author: Kewin Li
+// @param t
+func TestNormalCommentService_CreateComment(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock func(ctrl *gomock.Controller) (repository.CommentRepository, repository.ArticleRepository)
+
+		cmt domain.Comment
+
+		wantId  int64
+		wantErr error
+	}{
+		{
+			name: "发表根评论",
+			mock: func(ctrl *gomock.Controller) (repository.CommentRepository, repository.ArticleRepository) {
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().GetPubById(gomock.Any(), int64(1)).Return(domain.Article{
+					Id:     1,
+					Status: domain.ArticleStatusPublished,
+				}, nil)
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().CreateComment(gomock.Any(), domain.Comment{
+					Commenter: domain.User{Id: 123},
+					Biz:       "article",
+					BizId:     1,
+					Content:   "根评论",
+				}).Return(int64(10), nil)
+				return repo, artRepo
+			},
+			cmt: domain.Comment{
+				Commenter: domain.User{Id: 123},
+				Biz:       "article",
+				BizId:     1,
+				Content:   "根评论",
+				// 前端传入的根评论不可信
+				RootComment: &domain.Comment{Id: 99},
+			},
+			wantId: 10,
+		},
+		{
+			name: "回复根评论, 根评论为回复的评论",
+			mock: func(ctrl *gomock.Controller) (repository.CommentRepository, repository.ArticleRepository) {
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().GetPubById(gomock.Any(), int64(1)).Return(domain.Article{
+					Id:     1,
+					Status: domain.ArticleStatusPublished,
+				}, nil)
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(10)).Return(domain.Comment{
+					Id:    10,
+					Biz:   "article",
+					BizId: 1,
+				}, nil)
+				repo.EXPECT().CreateComment(gomock.Any(), domain.Comment{
+					Commenter:     domain.User{Id: 123},
+					Biz:           "article",
+					BizId:         1,
+					Content:       "回复",
+					RootComment:   &domain.Comment{Id: 10},
+					ParentComment: &domain.Comment{Id: 10},
+				}).Return(int64(11), nil)
+				return repo, artRepo
+			},
+			cmt: domain.Comment{
+				Commenter:     domain.User{Id: 123},
+				Biz:           "article",
+				BizId:         1,
+				Content:       "回复",
+				ParentComment: &domain.Comment{Id: 10},
+			},
+			wantId: 11,
+		},
+		{
+			name: "回复子评论, 根评论沿用子评论的根评论",
+			mock: func(ctrl *gomock.Controller) (repository.CommentRepository, repository.ArticleRepository) {
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().GetPubById(gomock.Any(), int64(1)).Return(domain.Article{
+					Id:     1,
+					Status: domain.ArticleStatusPublished,
+				}, nil)
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(11)).Return(domain.Comment{
+					Id:            11,
+					Biz:           "article",
+					BizId:         1,
+					RootComment:   &domain.Comment{Id: 10},
+					ParentComment: &domain.Comment{Id: 10},
+				}, nil)
+				repo.EXPECT().CreateComment(gomock.Any(), domain.Comment{
+					Commenter:     domain.User{Id: 123},
+					Biz:           "article",
+					BizId:         1,
+					Content:       "回复的回复",
+					RootComment:   &domain.Comment{Id: 10},
+					ParentComment: &domain.Comment{Id: 11},
+				}).Return(int64(12), nil)
+				return repo, artRepo
+			},
+			cmt: domain.Comment{
+				Commenter:     domain.User{Id: 123},
+				Biz:           "article",
+				BizId:         1,
+				Content:       "回复的回复",
+				ParentComment: &domain.Comment{Id: 11},
+			},
+			wantId: 12,
+		},
+		{
+			name: "回复的评论不存在",
+			mock: func(ctrl *gomock.Controller) (repository.CommentRepository, repository.ArticleRepository) {
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().GetPubById(gomock.Any(), int64(1)).Return(domain.Article{
+					Id:     1,
+					Status: domain.ArticleStatusPublished,
+				}, nil)
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(20)).
+					Return(domain.Comment{}, repository.ErrCommentNotFound)
+				return repo, artRepo
+			},
+			cmt: domain.Comment{
+				Biz:           "article",
+				BizId:         1,
+				ParentComment: &domain.Comment{Id: 20},
+			},
+			wantErr: ErrInvalidComment,
+		},
+		{
+			name: "回复的评论不属于同一篇帖子",
+			mock: func(ctrl *gomock.Controller) (repository.CommentRepository, repository.ArticleRepository) {
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().GetPubById(gomock.Any(), int64(1)).Return(domain.Article{
+					Id:     1,
+					Status: domain.ArticleStatusPublished,
+				}, nil)
+				repo := repomocks.NewMockCommentRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(10)).Return(domain.Comment{
+					Id:    10,
+					Biz:   "article",
+					BizId: 2,
+				}, nil)
+				return repo, artRepo
+			},
+			cmt: domain.Comment{
+				Biz:           "article",
+				BizId:         1,
+				ParentComment: &domain.Comment{Id: 10},
+			},
+			wantErr: ErrInvalidComment,
+		},
+		{
+			name: "评论的帖子不存在",
+			mock: func(ctrl *gomock.Controller) (repository.CommentRepository, repository.ArticleRepository) {
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().GetPubById(gomock.Any(), int64(1)).
+					Return(domain.Article{}, repository.ErrArticleNotFound)
+				return repomocks.NewMockCommentRepository(ctrl), artRepo
+			},
+			cmt: domain.Comment{
+				Commenter: domain.User{Id: 123},
+				Biz:       "article",
+				BizId:     1,
+				Content:   "根评论",
+			},
+			wantErr: ErrCommentTargetNotFound,
+		},
+		{
+			name: "评论的帖子未发表",
+			mock: func(ctrl *gomock.Controller) (repository.CommentRepository, repository.ArticleRepository) {
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().GetPubById(gomock.Any(), int64(1)).Return(domain.Article{
+					Id:     1,
+					Status: domain.ArticleStatusPrivate,
+				}, nil)
+				return repomocks.NewMockCommentRepository(ctrl), artRepo
+			},
+			cmt: domain.Comment{
+				Commenter: domain.User{Id: 123},
+				Biz:       "article",
+				BizId:     1,
+				Content:   "根评论",
+			},
+			wantErr: ErrCommentTargetNotFound,
+		},
+		{
+			name: "不支持的评论业务",
+			mock: func(ctrl *gomock.Controller) (repository.CommentRepository, repository.ArticleRepository) {
+				return repomocks.NewMockCommentRepository(ctrl), repomocks.NewMockArticleRepository(ctrl)
+			},
+			cmt: domain.Comment{
+				Commenter: domain.User{Id: 123},
+				Biz:       "video",
+				BizId:     1,
+				Content:   "根评论",
+			},
+			wantErr: ErrCommentTargetNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo, artRepo := tc.mock(ctrl)
+			svc := NewNormalCommentService(repo, artRepo)
+			id, err := svc.CreateComment(context.Background(), tc.cmt)
+
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantId, id)
+		})
+	}
+}
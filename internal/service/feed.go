@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"golang.org/x/sync/errgroup"
+	"kitbook/internal/domain"
+	"kitbook/internal/repository"
+	"sort"
+	"time"
+)
+
+const (
+	// 粉丝数不超过该阈值的作者使用推模式, 否则使用拉模式
+	feedPushThreshold = 1000
+	// 分批查询粉丝/关注列表的批次大小
+	feedFollowBatchSize = 500
+)
+
+type FeedService interface {
+	CreateFeedEvent(ctx context.Context, event domain.FeedEvent) error
+	GetFeed(ctx context.Context, uid int64, end time.Time, limit int) ([]domain.FeedEvent, error)
+}
+
+// PushPullFeedService
+// @Description: 推拉结合的feed流服务
+type PushPullFeedService struct {
+	repo      repository.FeedRepository
+	followSvc FollowService
+
+	pushThreshold int64
+}
+
+func NewPushPullFeedService(repo repository.FeedRepository, followSvc FollowService) FeedService {
+	return &PushPullFeedService{
+		repo:          repo,
+		followSvc:     followSvc,
+		pushThreshold: feedPushThreshold,
+	}
+}
+
+// @func: CreateFeedEvent
+// @date: 2024-01-13 16:20:45
+// @brief: feed流-作者发表帖子, 粉丝少的推到粉丝收件箱, 粉丝多的写入自己的发件箱等粉丝来拉
+// @author: Kewin Li
+// @receiver p
+// @param ctx
+// @param event
+// @return error
+func (p *PushPullFeedService) CreateFeedEvent(ctx context.Context, event domain.FeedEvent) error {
+	statics, err := p.followSvc.GetStatics(ctx, event.AuthorId)
+	if err != nil {
+		return err
+	}
+
+	// 1. 拉模式
+	if statics.Followers > p.pushThreshold {
+		return p.repo.CreatePullEvent(ctx, event)
+	}
+
+	// 2. 推模式, 分批写扩散
+	var maxId int64
+	for {
+		followers, err := p.followSvc.GetFollowers(ctx, event.AuthorId, maxId, feedFollowBatchSize)
+		if err != nil {
+			return err
+		}
+
+		events := make([]domain.FeedEvent, 0, len(followers))
+		for _, follower := range followers {
+			evt := event
+			evt.Uid = follower.Follower
+			events = append(events, evt)
+		}
+
+		err = p.repo.CreatePushEvents(ctx, events)
+		if err != nil {
+			return err
+		}
+
+		if len(followers) < feedFollowBatchSize {
+			return nil
+		}
+		maxId = followers[len(followers)-1].Id
+	}
+}
+
+// @func: GetFeed
+// @date: 2024-01-13 16:31:20
+// @brief: feed流-查询用户的feed流, 合并收件箱与关注作者的发件箱
+// @author: Kewin Li
+// @receiver p
+// @param ctx
+// @param uid
+// @param end 上一页最后一条事件的发表时间
+// @param limit
+// @return []domain.FeedEvent
+// @return error
+func (p *PushPullFeedService) GetFeed(ctx context.Context, uid int64, end time.Time, limit int) ([]domain.FeedEvent, error) {
+	var pushEvents, pullEvents []domain.FeedEvent
+
+	var eg errgroup.Group
+	// 1. 收件箱
+	eg.Go(func() error {
+		var err error
+		pushEvents, err = p.repo.FindPushEvents(ctx, uid, end, limit)
+		return err
+	})
+
+	// 2. 关注作者的发件箱
+	// 推模式的作者不会写发件箱, 所以直接按全部关注的作者查询也不会重复
+	eg.Go(func() error {
+		authorIds, err := p.getFolloweeIds(ctx, uid)
+		if err != nil {
+			return err
+		}
+
+		pullEvents, err = p.repo.FindPullEvents(ctx, authorIds, end, limit)
+		return err
+	})
+
+	err := eg.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. 按发表时间倒序合并, 取前limit条
+	events := append(pushEvents, pullEvents...)
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Ctime.After(events[j].Ctime)
+	})
+
+	if len(events) > limit {
+		events = events[:limit]
+	}
+
+	return events, nil
+}
+
+// @func: getFolloweeIds
+// @date: 2024-01-13 16:38:52
+// @brief: feed流-分批查出用户关注的全部作者
+// @author: Kewin Li
+// @receiver p
+// @param ctx
+// @param uid
+// @return []int64
+// @return error
+func (p *PushPullFeedService) getFolloweeIds(ctx context.Context, uid int64) ([]int64, error) {
+	var ids []int64
+	var maxId int64
+	for {
+		followees, err := p.followSvc.GetFollowees(ctx, uid, maxId, feedFollowBatchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, followee := range followees {
+			ids = append(ids, followee.Followee)
+		}
+
+		if len(followees) < feedFollowBatchSize {
+			return ids, nil
+		}
+		maxId = followees[len(followees)-1].Id
+	}
+}
@@ -0,0 +1,123 @@
+// Package service
+// @Description: feed流服务-单元测试
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"kitbook/internal/domain"
+	"kitbook/internal/repository"
+	repomocks "kitbook/internal/repository/mocks"
+	svcmocks "kitbook/internal/service/mocks"
+	"testing"
+	"time"
+)
+
+// @func: TestPushPullFeedService_CreateFeedEvent
+// @date: 2024-01-13 17:30:12
+// @brief: 单元测试-推拉模式写入feed事件
+// @author: Kewin Li
+// @param t
+func TestPushPullFeedService_CreateFeedEvent(t *testing.T) {
+	now := time.UnixMilli(time.Now().UnixMilli())
+
+	testCases := []struct {
+		name string
+
+		mock func(ctrl *gomock.Controller) (repository.FeedRepository, FollowService)
+
+		event domain.FeedEvent
+
+		wantErr error
+	}{
+		{
+			name: "粉丝少, 推到粉丝收件箱",
+			mock: func(ctrl *gomock.Controller) (repository.FeedRepository, FollowService) {
+				repo := repomocks.NewMockFeedRepository(ctrl)
+				followSvc := svcmocks.NewMockFollowService(ctrl)
+
+				followSvc.EXPECT().GetStatics(gomock.Any(), int64(1)).
+					Return(domain.FollowStatics{Uid: 1, Followers: 2}, nil)
+				followSvc.EXPECT().GetFollowers(gomock.Any(), int64(1), int64(0), feedFollowBatchSize).
+					Return([]domain.FollowRelation{
+						{Id: 11, Follower: 2, Followee: 1},
+						{Id: 10, Follower: 3, Followee: 1},
+					}, nil)
+				repo.EXPECT().CreatePushEvents(gomock.Any(), []domain.FeedEvent{
+					{Uid: 2, ArtId: 100, AuthorId: 1, Ctime: now},
+					{Uid: 3, ArtId: 100, AuthorId: 1, Ctime: now},
+				}).Return(nil)
+
+				return repo, followSvc
+			},
+			event: domain.FeedEvent{ArtId: 100, AuthorId: 1, Ctime: now},
+		},
+		{
+			name: "粉丝多, 写入作者发件箱",
+			mock: func(ctrl *gomock.Controller) (repository.FeedRepository, FollowService) {
+				repo := repomocks.NewMockFeedRepository(ctrl)
+				followSvc := svcmocks.NewMockFollowService(ctrl)
+
+				followSvc.EXPECT().GetStatics(gomock.Any(), int64(1)).
+					Return(domain.FollowStatics{Uid: 1, Followers: feedPushThreshold + 1}, nil)
+				repo.EXPECT().CreatePullEvent(gomock.Any(), domain.FeedEvent{ArtId: 100, AuthorId: 1, Ctime: now}).
+					Return(nil)
+
+				return repo, followSvc
+			},
+			event: domain.FeedEvent{ArtId: 100, AuthorId: 1, Ctime: now},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			svc := NewPushPullFeedService(tc.mock(ctrl))
+			err := svc.CreateFeedEvent(context.Background(), tc.event)
+
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+// @func: TestPushPullFeedService_GetFeed
+// @date: 2024-01-13 17:42:35
+// @brief: 单元测试-合并收件箱与发件箱
+// @author: Kewin Li
+// @param t
+func TestPushPullFeedService_GetFeed(t *testing.T) {
+	end := time.UnixMilli(time.Now().UnixMilli())
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := repomocks.NewMockFeedRepository(ctrl)
+	followSvc := svcmocks.NewMockFollowService(ctrl)
+
+	repo.EXPECT().FindPushEvents(gomock.Any(), int64(1), end, 3).Return([]domain.FeedEvent{
+		{Uid: 1, ArtId: 10, AuthorId: 2, Ctime: end.Add(-time.Minute)},
+		{Uid: 1, ArtId: 8, AuthorId: 2, Ctime: end.Add(-3 * time.Minute)},
+	}, nil)
+	followSvc.EXPECT().GetFollowees(gomock.Any(), int64(1), int64(0), feedFollowBatchSize).
+		Return([]domain.FollowRelation{
+			{Id: 5, Follower: 1, Followee: 2},
+			{Id: 4, Follower: 1, Followee: 3},
+		}, nil)
+	repo.EXPECT().FindPullEvents(gomock.Any(), []int64{2, 3}, end, 3).Return([]domain.FeedEvent{
+		{ArtId: 9, AuthorId: 3, Ctime: end.Add(-2 * time.Minute)},
+		{ArtId: 7, AuthorId: 3, Ctime: end.Add(-4 * time.Minute)},
+	}, nil)
+
+	svc := NewPushPullFeedService(repo, followSvc)
+	events, err := svc.GetFeed(context.Background(), 1, end, 3)
+
+	assert.NoError(t, err)
+	artIds := make([]int64, 0, len(events))
+	for _, event := range events {
+		artIds = append(artIds, event.ArtId)
+	}
+	assert.Equal(t, []int64{10, 9, 8}, artIds)
+}
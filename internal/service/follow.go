@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"golang.org/x/sync/errgroup"
+	"kitbook/internal/domain"
+	"kitbook/internal/repository"
+	"math"
+)
+
+var (
+	ErrFollowSelf   = errors.New("不能关注自己")
+	ErrRepeatFollow = repository.ErrRepeatFollow
+)
+
+type FollowService interface {
+	Follow(ctx context.Context, follower int64, followee int64) error
+	CancelFollow(ctx context.Context, follower int64, followee int64) error
+	GetFollowers(ctx context.Context, uid int64, maxId int64, limit int) ([]domain.FollowRelation, error)
+	GetFollowees(ctx context.Context, uid int64, maxId int64, limit int) ([]domain.FollowRelation, error)
+	GetStatics(ctx context.Context, uid int64) (domain.FollowStatics, error)
+	GetFollowState(ctx context.Context, uid int64, target int64) (domain.FollowState, error)
+}
+
+type NormalFollowService struct {
+	repo repository.FollowRepository
+}
+
+func NewNormalFollowService(repo repository.FollowRepository) FollowService {
+	return &NormalFollowService{
+		repo: repo,
+	}
+}
+
+// @func: Follow
+// @date: 2024-01-12 22:01:33
+// @brief: 关注服务-关注
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param follower
+// @param followee
+// @return error
+func (n *NormalFollowService) Follow(ctx context.Context, follower int64, followee int64) error {
+	if follower == followee {
+		return ErrFollowSelf
+	}
+	return n.repo.Follow(ctx, follower, followee)
+}
+
+// @func: CancelFollow
+// @date: 2024-01-12 22:02:10
+// @brief: 关注服务-取消关注
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param follower
+// @param followee
+// @return error
+func (n *NormalFollowService) CancelFollow(ctx context.Context, follower int64, followee int64) error {
+	return n.repo.Unfollow(ctx, follower, followee)
+}
+
+// @func: GetFollowers
+// @date: 2024-01-12 22:03:25
+// @brief: 关注服务-粉丝列表
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param uid
+// @param maxId 上一页最后一条记录的游标, <=0表示查询第一页
+// @param limit
+// @return []domain.FollowRelation
+// @return error
+func (n *NormalFollowService) GetFollowers(ctx context.Context, uid int64, maxId int64, limit int) ([]domain.FollowRelation, error) {
+	if maxId <= 0 {
+		maxId = math.MaxInt64
+	}
+	return n.repo.GetFollowers(ctx, uid, maxId, limit)
+}
+
+// @func: GetFollowees
+// @date: 2024-01-12 22:04:02
+// @brief: 关注服务-关注列表
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param uid
+// @param maxId 上一页最后一条记录的游标, <=0表示查询第一页
+// @param limit
+// @return []domain.FollowRelation
+// @return error
+func (n *NormalFollowService) GetFollowees(ctx context.Context, uid int64, maxId int64, limit int) ([]domain.FollowRelation, error) {
+	if maxId <= 0 {
+		maxId = math.MaxInt64
+	}
+	return n.repo.GetFollowees(ctx, uid, maxId, limit)
+}
+
+// @func: GetStatics
+// @date: 2024-01-12 22:05:11
+// @brief: 关注服务-粉丝数、关注数
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param uid
+// @return domain.FollowStatics
+// @return error
+func (n *NormalFollowService) GetStatics(ctx context.Context, uid int64) (domain.FollowStatics, error) {
+	return n.repo.GetStatics(ctx, uid)
+}
+
+// @func: GetFollowState
+// @date: 2024-01-12 22:06:48
+// @brief: 关注服务-两个用户之间的关注状态, 用于判断是否互相关注
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param uid
+// @param target
+// @return domain.FollowState
+// @return error
+func (n *NormalFollowService) GetFollowState(ctx context.Context, uid int64, target int64) (domain.FollowState, error) {
+	var state domain.FollowState
+	if uid == target {
+		return state, nil
+	}
+
+	var eg errgroup.Group
+	eg.Go(func() error {
+		var err error
+		state.Followed, err = n.repo.IsFollowing(ctx, uid, target)
+		return err
+	})
+
+	eg.Go(func() error {
+		var err error
+		state.FollowedBack, err = n.repo.IsFollowing(ctx, target, uid)
+		return err
+	})
+
+	return state, eg.Wait()
+}
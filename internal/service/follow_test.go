@@ -0,0 +1,158 @@
+// Package service
+// @Description: 关注服务-单元测试
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"kitbook/internal/domain"
+	"kitbook/internal/repository"
+	repomocks "kitbook/internal/repository/mocks"
+	"testing"
+)
+
+// @func: TestNormalFollowService_Follow
+// @date: 2024-01-12 23:05:37
+// @brief: 单元测试-关注
+// @author: Kewin Li
+// @param t
+func TestNormalFollowService_Follow(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock func(ctrl *gomock.Controller) repository.FollowRepository
+
+		follower int64
+		followee int64
+
+		wantErr error
+	}{
+		{
+			name: "关注成功",
+			mock: func(ctrl *gomock.Controller) repository.FollowRepository {
+				repo := repomocks.NewMockFollowRepository(ctrl)
+				repo.EXPECT().Follow(gomock.Any(), int64(1), int64(2)).Return(nil)
+				return repo
+			},
+			follower: 1,
+			followee: 2,
+		},
+		{
+			name: "不能关注自己",
+			mock: func(ctrl *gomock.Controller) repository.FollowRepository {
+				return repomocks.NewMockFollowRepository(ctrl)
+			},
+			follower: 1,
+			followee: 1,
+			wantErr:  ErrFollowSelf,
+		},
+		{
+			name: "重复关注",
+			mock: func(ctrl *gomock.Controller) repository.FollowRepository {
+				repo := repomocks.NewMockFollowRepository(ctrl)
+				repo.EXPECT().Follow(gomock.Any(), int64(1), int64(2)).Return(repository.ErrRepeatFollow)
+				return repo
+			},
+			follower: 1,
+			followee: 2,
+			wantErr:  ErrRepeatFollow,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			svc := NewNormalFollowService(tc.mock(ctrl))
+			err := svc.Follow(context.Background(), tc.follower, tc.followee)
+
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+// @func: TestNormalFollowService_GetFollowState
+// @date: 2024-01-12 23:12:04
+// @brief: 单元测试-互相关注状态
+// @author: Kewin Li
+// @param t
+func TestNormalFollowService_GetFollowState(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock func(ctrl *gomock.Controller) repository.FollowRepository
+
+		uid    int64
+		target int64
+
+		wantState  domain.FollowState
+		wantMutual bool
+		wantErr    error
+	}{
+		{
+			name: "互相关注",
+			mock: func(ctrl *gomock.Controller) repository.FollowRepository {
+				repo := repomocks.NewMockFollowRepository(ctrl)
+				repo.EXPECT().IsFollowing(gomock.Any(), int64(1), int64(2)).Return(true, nil)
+				repo.EXPECT().IsFollowing(gomock.Any(), int64(2), int64(1)).Return(true, nil)
+				return repo
+			},
+			uid:        1,
+			target:     2,
+			wantState:  domain.FollowState{Followed: true, FollowedBack: true},
+			wantMutual: true,
+		},
+		{
+			name: "单向关注",
+			mock: func(ctrl *gomock.Controller) repository.FollowRepository {
+				repo := repomocks.NewMockFollowRepository(ctrl)
+				repo.EXPECT().IsFollowing(gomock.Any(), int64(1), int64(2)).Return(true, nil)
+				repo.EXPECT().IsFollowing(gomock.Any(), int64(2), int64(1)).Return(false, nil)
+				return repo
+			},
+			uid:       1,
+			target:    2,
+			wantState: domain.FollowState{Followed: true},
+		},
+		{
+			name: "查看自己",
+			mock: func(ctrl *gomock.Controller) repository.FollowRepository {
+				return repomocks.NewMockFollowRepository(ctrl)
+			},
+			uid:    1,
+			target: 1,
+		},
+		{
+			name: "查询失败",
+			mock: func(ctrl *gomock.Controller) repository.FollowRepository {
+				repo := repomocks.NewMockFollowRepository(ctrl)
+				repo.EXPECT().IsFollowing(gomock.Any(), int64(1), int64(2)).Return(false, errors.New("模拟数据库错误"))
+				repo.EXPECT().IsFollowing(gomock.Any(), int64(2), int64(1)).Return(false, nil)
+				return repo
+			},
+			uid:     1,
+			target:  2,
+			wantErr: errors.New("模拟数据库错误"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			svc := NewNormalFollowService(tc.mock(ctrl))
+			state, err := svc.GetFollowState(context.Background(), tc.uid, tc.target)
+
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tc.wantState, state)
+			assert.Equal(t, tc.wantMutual, state.Mutual())
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"kitbook/internal/domain"
+	"kitbook/internal/repository"
+	"time"
+)
+
+type HistoryService interface {
+	GetHistory(ctx context.Context, userId int64, biz string, end time.Time, endId int64, limit int) ([]domain.HistoryRecord, error)
+	Clear(ctx context.Context, userId int64, biz string) error
+}
+
+type NormalHistoryService struct {
+	repo repository.HistoryRepository
+}
+
+func NewNormalHistoryService(repo repository.HistoryRepository) HistoryService {
+	return &NormalHistoryService{
+		repo: repo,
+	}
+}
+
+// @func: GetHistory
+// @date: 2024-01-06 16:20:33
+// @brief: 浏览记录-按浏览时间倒序分页查询
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param userId
+// @param biz 为空时查询全部业务
+// @param end 上一页最后一条记录的浏览时间
+// @param endId 上一页最后一条记录的ID
+// @param limit
+// @return []domain.HistoryRecord
+// @return error
+func (n *NormalHistoryService) GetHistory(ctx context.Context, userId int64, biz string, end time.Time, endId int64, limit int) ([]domain.HistoryRecord, error) {
+	return n.repo.GetByUser(ctx, userId, biz, end, endId, limit)
+}
+
+// @func: Clear
+// @date: 2024-01-06 16:22:08
+// @brief: 浏览记录-清空浏览记录
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param userId
+// @param biz 为空时清空全部业务
+// @return error
+func (n *NormalHistoryService) Clear(ctx context.Context, userId int64, biz string) error {
+	return n.repo.Clear(ctx, userId, biz)
+}
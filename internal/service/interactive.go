@@ -4,6 +4,7 @@ import (
 	"context"
 	"golang.org/x/sync/errgroup"
 	"kitbook/internal/domain"
+	"kitbook/internal/events/article"
 	"kitbook/internal/repository"
 	"kitbook/pkg/logger"
 )
@@ -19,15 +20,19 @@ type InteractiveService interface {
 }
 
 type ArticleInteractiveService struct {
-	repo repository.InteractiveRepository
+	repo     repository.InteractiveRepository
+	producer article.Producer
 
 	l logger.Logger
 }
 
-func NewArticleInteractiveService(repo repository.InteractiveRepository, l logger.Logger) InteractiveService {
+func NewArticleInteractiveService(repo repository.InteractiveRepository,
+	producer article.Producer,
+	l logger.Logger) InteractiveService {
 	return &ArticleInteractiveService{
-		repo: repo,
-		l:    l,
+		repo:     repo,
+		producer: producer,
+		l:        l,
 	}
 }
 
@@ -54,7 +59,12 @@ func (a *ArticleInteractiveService) IncreaseReadCnt(ctx context.Context, biz str
 // @param userId
 // @return error
 func (a *ArticleInteractiveService) Like(ctx context.Context, biz string, bizId int64, userId int64) error {
-	return a.repo.IncreaseLikeCnt(ctx, biz, bizId, userId)
+	err := a.repo.IncreaseLikeCnt(ctx, biz, bizId, userId)
+	if err == nil {
+		a.produceInteractiveEvent(bizId, 1, 0)
+	}
+
+	return err
 }
 
 // @func: CancelLike
@@ -67,7 +77,12 @@ func (a *ArticleInteractiveService) Like(ctx context.Context, biz string, bizId
 // @param userId
 // @return error
 func (a *ArticleInteractiveService) CancelLike(ctx context.Context, biz string, bizId int64, userId int64) error {
-	return a.repo.DecreaseLikeCnt(ctx, biz, bizId, userId)
+	err := a.repo.DecreaseLikeCnt(ctx, biz, bizId, userId)
+	if err == nil {
+		a.produceInteractiveEvent(bizId, -1, 0)
+	}
+
+	return err
 }
 
 // @func:
@@ -77,7 +92,12 @@ func (a *ArticleInteractiveService) CancelLike(ctx context.Context, biz string,
 // @receiver a
 // @return unc
 func (a *ArticleInteractiveService) Collect(ctx context.Context, biz string, bizId int64, collectId int64, userId int64) error {
-	return a.repo.IncreaseCollectItem(ctx, biz, bizId, collectId, userId)
+	err := a.repo.IncreaseCollectItem(ctx, biz, bizId, collectId, userId)
+	if err == nil {
+		a.produceInteractiveEvent(bizId, 0, 1)
+	}
+
+	return err
 }
 
 // @func:
@@ -87,7 +107,38 @@ func (a *ArticleInteractiveService) Collect(ctx context.Context, biz string, biz
 // @receiver a
 // @return unc
 func (a *ArticleInteractiveService) CancelCollect(ctx context.Context, biz string, bizId int64, collectId int64, userId int64) error {
-	return a.repo.DecreaseCollectItem(ctx, biz, bizId, collectId, userId)
+	err := a.repo.DecreaseCollectItem(ctx, biz, bizId, collectId, userId)
+	if err == nil {
+		a.produceInteractiveEvent(bizId, 0, -1)
+	}
+
+	return err
+}
+
+// @func: produceInteractiveEvent
+// @date: 2024-01-21 10:20:48
+// @brief: 异步发送点赞、收藏消息, 发送失败不影响互动本身
+// @author: Kewin Li
+// @receiver a
+// @param bizId
+// @param likeDelta
+// @param collectDelta
+func (a *ArticleInteractiveService) produceInteractiveEvent(bizId int64, likeDelta int64, collectDelta int64) {
+	go func() {
+		err := a.producer.ProducerInteractiveEvent(article.InteractiveEvent{
+			ArtId:        bizId,
+			LikeDelta:    likeDelta,
+			CollectDelta: collectDelta,
+		})
+
+		if err != nil {
+			a.l.ERROR("点赞、收藏消息发送失败",
+				logger.Error(err),
+				logger.Int[int64]("artId", bizId),
+				logger.Int[int64]("likeDelta", likeDelta),
+				logger.Int[int64]("collectDelta", collectDelta))
+		}
+	}()
 }
 
 // @func: Get
@@ -2,59 +2,113 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"kitbook/internal/domain"
 	"kitbook/internal/repository"
 	"kitbook/pkg/logger"
 	"time"
 )
 
+var (
+	ErrInvalidJobExpression = errors.New("cron表达式不合法")
+	ErrDuplicateJob         = repository.ErrDuplicateJob
+	ErrJobNotFound          = errors.New("任务不存在")
+	ErrJobStatusMismatch    = repository.ErrJobStatusMismatch
+	ErrInvalidJobUpstream   = errors.New("上游任务不存在")
+	ErrJobDependencyCycle   = errors.New("任务依赖存在环")
+	ErrInvalidJobCallback   = errors.New("回调token无效或已过期")
+)
+
+// 执行失败原因最大长度, 与表字段长度一致
+const jobErrorMaxLen = 1024
+
 type JobService interface {
-	Preempt(ctx context.Context) (domain.Job, error)
+	Preempt(ctx context.Context, labels map[string]string) (domain.Job, error)
+	PreemptShard(ctx context.Context, labels map[string]string) (domain.Job, error)
+	ListBroadcast(ctx context.Context) ([]domain.Job, error)
 	ResetNextTime(ctx context.Context, job domain.Job) error
+	Finish(ctx context.Context, job domain.Job, exec domain.JobExecution) error
+
+	// 任务管理
+	Create(ctx context.Context, job domain.Job) (int64, error)
+	Update(ctx context.Context, job domain.Job) error
+	Delete(ctx context.Context, jobId int64) error
+	Pause(ctx context.Context, jobId int64) error
+	Resume(ctx context.Context, jobId int64) error
+	Trigger(ctx context.Context, jobId int64) error
+	List(ctx context.Context, offset int, limit int) ([]domain.Job, error)
+	ListExecutions(ctx context.Context, jobId int64, offset int, limit int) ([]domain.JobExecution, error)
+	// 任务所在的依赖图, 包含所有直接或间接关联的任务
+	DAG(ctx context.Context, jobId int64) ([]domain.Job, error)
+
+	// 远程任务回调
+	RegisterCallback(ctx context.Context, token string, expiration time.Duration) error
+	Callback(ctx context.Context, cb domain.JobCallback) error
+	WaitCallback(ctx context.Context, token string) error
+
+	// 回收续约超时的任务
+	ReclaimStuck(ctx context.Context, lease time.Duration) (int64, error)
+
+	// 调度结点负载
+	ReportNode(ctx context.Context, node domain.JobNode) error
+	ListNodes(ctx context.Context) ([]domain.JobNode, error)
 }
 
 type CronJobService struct {
 	repo            repository.JobRepository
 	refreshInterval time.Duration
-	l               logger.Logger
+	// 抢占时已超过调度时间点该时长视为错过调度
+	misfireThreshold time.Duration
+	// 等待远程任务回调时的轮询间隔
+	callbackInterval time.Duration
+	l                logger.Logger
 }
 
-func NewCronJobService(repo repository.JobRepository, l logger.Logger) *CronJobService {
+func NewCronJobService(repo repository.JobRepository, l logger.Logger) JobService {
 	return &CronJobService{
-		repo:            repo,
-		refreshInterval: time.Minute,
-		l:               l}
+		repo:             repo,
+		refreshInterval:  time.Minute,
+		misfireThreshold: time.Minute,
+		callbackInterval: time.Second,
+		l:                l}
 }
 
 // @func: Preempt
 // @date: 2023-12-31 19:10:19
-// @brief: MySQL任务调度-抢占任务
+// @brief: MySQL任务调度-抢占任务, 按错过策略处理已超时的调度
 // @author: Kewin Li
 // @receiver c
 // @param ctx
+// @param labels 当前结点标签, 只抢占亲和标签满足的任务
 // @return domain.Job
 // @return error
-func (c *CronJobService) Preempt(ctx context.Context) (domain.Job, error) {
-	job, err := c.repo.Preempt(ctx)
-	if err != nil {
-		return domain.Job{}, err
-	}
+func (c *CronJobService) Preempt(ctx context.Context, labels map[string]string) (domain.Job, error) {
+	var job domain.Job
+	var err error
+	for {
+		job, err = c.repo.Preempt(ctx, labels)
+		if err != nil {
+			return domain.Job{}, err
+		}
 
-	ticker := time.NewTicker(c.refreshInterval)
-	go func() {
-		// 等待定时器到期
-		for range ticker.C {
-			c.refresh(ctx, job.Id)
+		// 错过的调度被丢弃时继续抢占下一个任务
+		if c.handleMisfire(ctx, &job) {
+			break
 		}
-	}()
+	}
+
+	stop := c.keepAlive(job.Id, func(ctx context.Context) error {
+		return c.repo.UpdateUtime(ctx, job.Id, job.Version)
+	})
 
 	job.CancelFunc = func() {
 		// 关闭自动续约，否则goroutine泄漏
-		ticker.Stop()
+		stop()
 		ctx2, cancel := context.WithTimeout(context.Background(), time.Second)
 		defer cancel()
 
-		err2 := c.repo.Release(ctx2, job.Id)
+		err2 := c.repo.Release(ctx2, job.Id, job.Version)
 		if err2 != nil {
 			c.l.ERROR("释放定时任务失败",
 				logger.Error(err2),
@@ -65,6 +119,159 @@ func (c *CronJobService) Preempt(ctx context.Context) (domain.Job, error) {
 	return job, nil
 }
 
+// @func: handleMisfire
+// @date: 2024-01-19 14:45:30
+// @brief: MySQL任务调度-按错过策略处理抢占到的任务, 记录错过的调度次数
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param job
+// @return bool 是否需要执行
+func (c *CronJobService) handleMisfire(ctx context.Context, job *domain.Job) bool {
+	now := time.Now()
+	if now.Sub(c.eligibleTime(ctx, job)) <= c.misfireThreshold {
+		return true
+	}
+
+	due := job.DueFires(now)
+	var missed, catchUp int
+	switch job.MisfirePolicy {
+	case domain.MisfireSkip:
+		err := c.repo.SkipMisfire(ctx, job.Id, job.Version, due, job.NextTimeAfter(now))
+		if err != nil {
+			c.l.ERROR("丢弃错过的调度失败",
+				logger.Error(err),
+				logger.Int[int64]("job_id", job.Id))
+		}
+
+		c.l.WARN("任务错过调度, 已丢弃",
+			logger.Int[int64]("job_id", job.Id),
+			logger.Int[int]("missed", due))
+		return false
+	case domain.MisfireFireAll:
+		runs := max(min(due, job.MisfireCap), 1)
+		catchUp = runs - 1
+		missed = due - runs
+	default:
+		missed = due - 1
+	}
+
+	if missed == 0 && catchUp == 0 {
+		return true
+	}
+
+	job.CatchUp = catchUp
+	err := c.repo.MarkMisfire(ctx, job.Id, job.Version, missed, catchUp)
+	if err != nil {
+		c.l.ERROR("记录错过的调度失败",
+			logger.Error(err),
+			logger.Int[int64]("job_id", job.Id))
+	}
+
+	c.l.WARN("任务错过调度",
+		logger.Int[int64]("job_id", job.Id),
+		logger.Field{"policy", job.MisfirePolicy.String()},
+		logger.Int[int]("missed", missed),
+		logger.Int[int]("catch_up", catchUp))
+	return true
+}
+
+// @func: eligibleTime
+// @date: 2024-01-19 14:52:06
+// @brief: MySQL任务调度-任务可被调度的时间点, 有上游的任务要等最晚的上游执行成功后才可调度
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param job
+// @return time.Time
+func (c *CronJobService) eligibleTime(ctx context.Context, job *domain.Job) time.Time {
+	eligible := job.NextExecTime
+	if len(job.Upstreams) == 0 {
+		return eligible
+	}
+
+	upstreams, err := c.repo.FindByIds(ctx, job.Upstreams)
+	if err != nil {
+		// 无法判断上游何时完成, 按刚满足依赖处理, 避免误丢弃下游调度
+		c.l.WARN("查询上游任务失败, 不做错过调度处理",
+			logger.Error(err),
+			logger.Int[int64]("job_id", job.Id))
+		return time.Now()
+	}
+
+	for _, up := range upstreams {
+		if up.LastSuccessTime.After(eligible) {
+			eligible = up.LastSuccessTime
+		}
+	}
+
+	return eligible
+}
+
+// @func: PreemptShard
+// @date: 2024-01-18 16:05:18
+// @brief: MySQL任务调度-抢占分片任务的一个分片, 执行期间自动续约
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param labels 当前结点标签
+// @return domain.Job 已填充本次执行的分片
+// @return error
+func (c *CronJobService) PreemptShard(ctx context.Context, labels map[string]string) (domain.Job, error) {
+	job, err := c.repo.PreemptShard(ctx, labels)
+	if err != nil {
+		return domain.Job{}, err
+	}
+
+	// 分片执行完毕时由Finish修改状态, 无需释放
+	job.CancelFunc = c.keepAlive(job.Id, func(ctx context.Context) error {
+		return c.repo.UpdateShardUtime(ctx, job.Shard.Id, job.Shard.Version)
+	})
+
+	return job, nil
+}
+
+// @func: ListBroadcast
+// @date: 2024-01-18 16:08:40
+// @brief: MySQL任务调度-查询等待调度的广播任务, 由各结点按本地时钟执行
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @return []domain.Job
+// @return error
+func (c *CronJobService) ListBroadcast(ctx context.Context) ([]domain.Job, error) {
+	return c.repo.ListBroadcast(ctx)
+}
+
+// @func: keepAlive
+// @date: 2024-01-18 16:02:36
+// @brief: MySQL任务调度-定时续约, 返回停止续约的方法
+// @author: Kewin Li
+// @receiver c
+// @param jobId
+// @param refresh
+// @return func()
+func (c *CronJobService) keepAlive(jobId int64, refresh func(ctx context.Context) error) func() {
+	ticker := time.NewTicker(c.refreshInterval)
+	done := make(chan struct{})
+	go func() {
+		// 等待定时器到期
+		for {
+			select {
+			case <-ticker.C:
+				c.refresh(jobId, refresh)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
+
 // //  @func: refresh
 //
 //	@date: 2023-12-31 19:46:13
@@ -72,8 +279,13 @@ func (c *CronJobService) Preempt(ctx context.Context) (domain.Job, error) {
 //	@author: Kewin Li
 //	@receiver c
 //	@param jobId
-func (c *CronJobService) refresh(ctx context.Context, jobId int64) {
-	err := c.repo.UpdateUtime(ctx, jobId)
+//	@param refresh
+func (c *CronJobService) refresh(jobId int64, refresh func(ctx context.Context) error) {
+	// 抢占时的ctx已经结束, 续约使用独立的ctx
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	err := refresh(ctx)
 	if err != nil {
 		c.l.ERROR("任务续约失败",
 			logger.Error(err),
@@ -93,3 +305,379 @@ func (c *CronJobService) ResetNextTime(ctx context.Context, job domain.Job) erro
 	nextTime := job.NextTime()
 	return c.repo.UpdateNextTime(ctx, job.Id, nextTime)
 }
+
+// @func: Finish
+// @date: 2024-01-17 10:45:30
+// @brief: MySQL任务调度-任务执行完毕, 记录执行日志, 失败时按重试策略提前调度, 有待补跑时立即调度, 否则按cron表达式计算下一次调度时间点
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param job
+// @param exec
+// @return error
+func (c *CronJobService) Finish(ctx context.Context, job domain.Job, exec domain.JobExecution) error {
+	exec.JobId = job.Id
+	exec.Attempt = job.RetryCnt + 1
+
+	msg := []rune(exec.Error)
+	if len(msg) > jobErrorMaxLen {
+		exec.Error = string(msg[:jobErrorMaxLen])
+	}
+
+	// 广播、分片任务不做失败重试
+	switch job.Mode {
+	case domain.JobModeBroadcast:
+		return c.repo.FinishBroadcast(ctx, exec)
+	case domain.JobModeSharding:
+		exec.ShardIndex = job.Shard.Index
+		return c.repo.FinishShard(ctx, exec, job, job.NextTime())
+	}
+
+	if exec.Result == domain.JobResultFailed {
+		retryAt, ok := job.RetryTime(exec.EndTime)
+		if ok {
+			return c.repo.Finish(ctx, exec, job.Version, job.RetryCnt+1, job.CatchUp, retryAt)
+		}
+	}
+
+	// 还有错过的调度待补跑, 立即再次调度
+	if job.CatchUp > 0 {
+		return c.repo.Finish(ctx, exec, job.Version, 0, job.CatchUp-1, exec.EndTime)
+	}
+
+	// 执行成功或重试次数耗尽, 回到正常调度
+	return c.repo.Finish(ctx, exec, job.Version, 0, 0, job.NextTime())
+}
+
+// @func: Create
+// @date: 2024-01-16 15:12:40
+// @brief: 任务管理-新建任务, 校验cron表达式、上游依赖并计算首次调度时间点
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param job
+// @return int64
+// @return error
+func (c *CronJobService) Create(ctx context.Context, job domain.Job) (int64, error) {
+	if job.ValidExpression() != nil {
+		return 0, ErrInvalidJobExpression
+	}
+
+	err := c.checkUpstreams(ctx, job)
+	if err != nil {
+		return 0, err
+	}
+
+	job.Status = domain.JobStatusWaiting
+	job.NextExecTime = job.NextTime()
+	return c.repo.Create(ctx, job)
+}
+
+// @func: Update
+// @date: 2024-01-16 15:14:05
+// @brief: 任务管理-修改cron表达式、执行器、上游依赖, 重新计算下一次调度时间点
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param job
+// @return error
+func (c *CronJobService) Update(ctx context.Context, job domain.Job) error {
+	if job.ValidExpression() != nil {
+		return ErrInvalidJobExpression
+	}
+
+	err := c.checkUpstreams(ctx, job)
+	if err != nil {
+		return err
+	}
+
+	job.NextExecTime = job.NextTime()
+	return c.convertsErr(c.repo.Update(ctx, job))
+}
+
+// @func: checkUpstreams
+// @date: 2024-01-19 11:02:18
+// @brief: 任务管理-校验上游任务均存在且加入后依赖关系不成环
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param job
+// @return error
+func (c *CronJobService) checkUpstreams(ctx context.Context, job domain.Job) error {
+	if len(job.Upstreams) == 0 {
+		return nil
+	}
+
+	ups, err := c.repo.FindByIds(ctx, job.Upstreams)
+	if err != nil {
+		return err
+	}
+	if len(ups) != len(job.Upstreams) {
+		return ErrInvalidJobUpstream
+	}
+
+	dag, err := c.repo.Dependencies(ctx)
+	if err != nil {
+		return err
+	}
+
+	// 以修改后的依赖关系检测, 上游直接或间接依赖当前任务即成环
+	dag[job.Id] = job.Upstreams
+	for _, up := range job.Upstreams {
+		if up == job.Id || dag.DependsOn(up, job.Id) {
+			return ErrJobDependencyCycle
+		}
+	}
+
+	return nil
+}
+
+// @func: DAG
+// @date: 2024-01-19 11:08:45
+// @brief: 任务管理-查询任务所在的依赖图及各任务的运行状态
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param jobId
+// @return []domain.Job
+// @return error
+func (c *CronJobService) DAG(ctx context.Context, jobId int64) ([]domain.Job, error) {
+	dag, err := c.repo.Dependencies(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs, err := c.repo.FindByIds(ctx, dag.Component(jobId))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, job := range jobs {
+		if job.Id == jobId {
+			return jobs, nil
+		}
+	}
+
+	return nil, ErrJobNotFound
+}
+
+// @func: Delete
+// @date: 2024-01-16 15:15:31
+// @brief: 任务管理-删除任务
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param jobId
+// @return error
+func (c *CronJobService) Delete(ctx context.Context, jobId int64) error {
+	return c.convertsErr(c.repo.Delete(ctx, jobId))
+}
+
+// @func: Pause
+// @date: 2024-01-16 15:16:02
+// @brief: 任务管理-暂停调度
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param jobId
+// @return error
+func (c *CronJobService) Pause(ctx context.Context, jobId int64) error {
+	return c.changeStatus(ctx, jobId, c.repo.Pause)
+}
+
+// @func: Resume
+// @date: 2024-01-16 15:16:48
+// @brief: 任务管理-恢复调度
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param jobId
+// @return error
+func (c *CronJobService) Resume(ctx context.Context, jobId int64) error {
+	job, err := c.repo.FindById(ctx, jobId)
+	if err != nil {
+		return c.convertsErr(err)
+	}
+
+	return c.convertsErr(c.repo.Resume(ctx, jobId, job.NextTime()))
+}
+
+// @func: Trigger
+// @date: 2024-01-16 15:17:35
+// @brief: 任务管理-立即触发一次调度
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param jobId
+// @return error
+func (c *CronJobService) Trigger(ctx context.Context, jobId int64) error {
+	return c.changeStatus(ctx, jobId, c.repo.Trigger)
+}
+
+// @func: List
+// @date: 2024-01-16 15:18:10
+// @brief: 任务管理-分页查询任务列表
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param offset
+// @param limit
+// @return []domain.Job
+// @return error
+func (c *CronJobService) List(ctx context.Context, offset int, limit int) ([]domain.Job, error) {
+	return c.repo.List(ctx, offset, limit)
+}
+
+// @func: changeStatus
+// @date: 2024-01-16 15:19:22
+// @brief: 任务管理-状态变更, 任务不存在与状态不允许操作区分开
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param jobId
+// @param fn
+// @return error
+func (c *CronJobService) changeStatus(ctx context.Context, jobId int64, fn func(ctx context.Context, jobId int64) error) error {
+	err := fn(ctx, jobId)
+	if err != repository.ErrJobStatusMismatch {
+		return err
+	}
+
+	_, err = c.repo.FindById(ctx, jobId)
+	if err != nil {
+		return c.convertsErr(err)
+	}
+
+	return ErrJobStatusMismatch
+}
+
+// @func: convertsErr
+// @date: 2024-01-16 15:20:47
+// @brief: 任务管理-转换repository层错误
+// @author: Kewin Li
+// @receiver c
+// @param err
+// @return error
+func (c *CronJobService) convertsErr(err error) error {
+	if err == repository.ErrJobNotFound {
+		return ErrJobNotFound
+	}
+	return err
+}
+
+// @func: ListExecutions
+// @date: 2024-01-17 10:52:16
+// @brief: 任务管理-查询任务执行记录
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param jobId
+// @param offset
+// @param limit
+// @return []domain.JobExecution
+// @return error
+func (c *CronJobService) ListExecutions(ctx context.Context, jobId int64, offset int, limit int) ([]domain.JobExecution, error) {
+	return c.repo.ListExecutions(ctx, jobId, offset, limit)
+}
+
+// @func: RegisterCallback
+// @date: 2024-01-17 14:29:18
+// @brief: 远程任务回调-下发任务前登记token, 未登记的token回调一律拒绝
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param token
+// @param expiration 等待回调的最长时间
+// @return error
+func (c *CronJobService) RegisterCallback(ctx context.Context, token string, expiration time.Duration) error {
+	return c.repo.RegisterCallback(ctx, token, expiration)
+}
+
+// @func: Callback
+// @date: 2024-01-17 14:30:02
+// @brief: 远程任务回调-远程任务执行完毕后上报结果
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param cb
+// @return error token未登记、已过期或已回调时返回ErrInvalidJobCallback
+func (c *CronJobService) Callback(ctx context.Context, cb domain.JobCallback) error {
+	err := c.repo.SaveCallback(ctx, cb)
+	if err == repository.ErrInvalidCallback {
+		return ErrInvalidJobCallback
+	}
+	return err
+}
+
+// @func: WaitCallback
+// @date: 2024-01-17 14:32:47
+// @brief: 远程任务回调-轮询等待回调结果, 直到ctx超时或取消
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param token
+// @return error 远程任务执行失败时返回其错误信息
+func (c *CronJobService) WaitCallback(ctx context.Context, token string) error {
+	ticker := time.NewTicker(c.callbackInterval)
+	defer ticker.Stop()
+
+	for {
+		cb, err := c.repo.GetCallback(ctx, token)
+		switch err {
+		case nil:
+			if !cb.Success {
+				return fmt.Errorf("远程任务执行失败: %s", cb.Error)
+			}
+			return nil
+		case repository.ErrCallbackNotFound:
+			// 尚未回调, 继续等待
+		default:
+			c.l.WARN("查询任务回调结果失败",
+				logger.Error(err),
+				logger.Field{"token", token})
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// @func: ReclaimStuck
+// @date: 2024-01-18 10:25:12
+// @brief: MySQL任务调度-回收超过租约仍未续约的任务
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param lease 租约时长, 应大于续约间隔
+// @return int64 回收的任务数
+// @return error
+func (c *CronJobService) ReclaimStuck(ctx context.Context, lease time.Duration) (int64, error) {
+	return c.repo.ReclaimStuck(ctx, time.Now().Add(-lease))
+}
+
+// @func: ReportNode
+// @date: 2024-01-20 10:40:26
+// @brief: 调度结点-上报当前结点负载
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param node
+// @return error
+func (c *CronJobService) ReportNode(ctx context.Context, node domain.JobNode) error {
+	return c.repo.ReportNode(ctx, node)
+}
+
+// @func: ListNodes
+// @date: 2024-01-20 10:41:08
+// @brief: 调度结点-查询在线结点的负载
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @return []domain.JobNode
+// @return error
+func (c *CronJobService) ListNodes(ctx context.Context) ([]domain.JobNode, error) {
+	return c.repo.ListNodes(ctx)
+}
@@ -0,0 +1,499 @@
+// Package service
+// @Description: 任务调度服务-单元测试
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"kitbook/internal/domain"
+	"kitbook/internal/repository"
+	repomocks "kitbook/internal/repository/mocks"
+	"kitbook/pkg/logger"
+	"testing"
+	"time"
+)
+
+// @func: TestCronJobService_Create
+// @date: 2024-01-16 16:55:30
+// @brief: 单元测试-新建任务
+// @author: Kewin Li
+// @param t
+func TestCronJobService_Create(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock func(ctrl *gomock.Controller) repository.JobRepository
+
+		job     domain.Job
+		wantId  int64
+		wantErr error
+	}{
+		{
+			name: "新建成功",
+			mock: func(ctrl *gomock.Controller) repository.JobRepository {
+				repo := repomocks.NewMockJobRepository(ctrl)
+				repo.EXPECT().Create(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, job domain.Job) (int64, error) {
+						assert.Equal(t, domain.JobStatusWaiting, job.Status)
+						assert.False(t, job.NextExecTime.IsZero())
+						return 1, nil
+					})
+				return repo
+			},
+			job:    domain.Job{Name: "ranking", Expression: "@every 1m", ExecutorName: "local"},
+			wantId: 1,
+		},
+		{
+			name: "cron表达式不合法",
+			mock: func(ctrl *gomock.Controller) repository.JobRepository {
+				return repomocks.NewMockJobRepository(ctrl)
+			},
+			job:     domain.Job{Name: "ranking", Expression: "* * *", ExecutorName: "local"},
+			wantErr: ErrInvalidJobExpression,
+		},
+		{
+			name: "任务名称重复",
+			mock: func(ctrl *gomock.Controller) repository.JobRepository {
+				repo := repomocks.NewMockJobRepository(ctrl)
+				repo.EXPECT().Create(gomock.Any(), gomock.Any()).
+					Return(int64(0), repository.ErrDuplicateJob)
+				return repo
+			},
+			job:     domain.Job{Name: "ranking", Expression: "0 */5 * * * *", ExecutorName: "local"},
+			wantErr: ErrDuplicateJob,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			svc := NewCronJobService(tc.mock(ctrl), logger.NewNopLogger())
+			id, err := svc.Create(context.Background(), tc.job)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantId, id)
+		})
+	}
+}
+
+// @func: TestCronJobService_Update
+// @date: 2024-01-19 11:45:10
+// @brief: 单元测试-修改任务, 校验上游依赖
+// @author: Kewin Li
+// @param t
+func TestCronJobService_Update(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock func(ctrl *gomock.Controller) repository.JobRepository
+
+		job     domain.Job
+		wantErr error
+	}{
+		{
+			name: "修改成功",
+			mock: func(ctrl *gomock.Controller) repository.JobRepository {
+				repo := repomocks.NewMockJobRepository(ctrl)
+				repo.EXPECT().FindByIds(gomock.Any(), []int64{2}).
+					Return([]domain.Job{{Id: 2}}, nil)
+				// 3依赖1, 1依赖2不成环
+				repo.EXPECT().Dependencies(gomock.Any()).
+					Return(domain.JobDAG{3: {1}}, nil)
+				repo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+				return repo
+			},
+			job: domain.Job{Id: 1, Expression: "@every 1m", Upstreams: []int64{2}},
+		},
+		{
+			name: "上游任务不存在",
+			mock: func(ctrl *gomock.Controller) repository.JobRepository {
+				repo := repomocks.NewMockJobRepository(ctrl)
+				repo.EXPECT().FindByIds(gomock.Any(), []int64{2, 3}).
+					Return([]domain.Job{{Id: 2}}, nil)
+				return repo
+			},
+			job:     domain.Job{Id: 1, Expression: "@every 1m", Upstreams: []int64{2, 3}},
+			wantErr: ErrInvalidJobUpstream,
+		},
+		{
+			name: "间接依赖自身",
+			mock: func(ctrl *gomock.Controller) repository.JobRepository {
+				repo := repomocks.NewMockJobRepository(ctrl)
+				repo.EXPECT().FindByIds(gomock.Any(), []int64{2}).
+					Return([]domain.Job{{Id: 2}}, nil)
+				// 2依赖3, 3依赖1
+				repo.EXPECT().Dependencies(gomock.Any()).
+					Return(domain.JobDAG{2: {3}, 3: {1}}, nil)
+				return repo
+			},
+			job:     domain.Job{Id: 1, Expression: "@every 1m", Upstreams: []int64{2}},
+			wantErr: ErrJobDependencyCycle,
+		},
+		{
+			name: "依赖自身",
+			mock: func(ctrl *gomock.Controller) repository.JobRepository {
+				repo := repomocks.NewMockJobRepository(ctrl)
+				repo.EXPECT().FindByIds(gomock.Any(), []int64{1}).
+					Return([]domain.Job{{Id: 1}}, nil)
+				repo.EXPECT().Dependencies(gomock.Any()).Return(domain.JobDAG{}, nil)
+				return repo
+			},
+			job:     domain.Job{Id: 1, Expression: "@every 1m", Upstreams: []int64{1}},
+			wantErr: ErrJobDependencyCycle,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			svc := NewCronJobService(tc.mock(ctrl), logger.NewNopLogger())
+			err := svc.Update(context.Background(), tc.job)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+// @func: TestCronJobService_Preempt
+// @date: 2024-01-19 15:20:42
+// @brief: 单元测试-抢占任务, 按错过策略处理已超时的调度
+// @author: Kewin Li
+// @param t
+func TestCronJobService_Preempt(t *testing.T) {
+	now := time.Now()
+
+	testCases := []struct {
+		name string
+
+		mock func(ctrl *gomock.Controller) repository.JobRepository
+
+		wantId      int64
+		wantCatchUp int
+		wantErr     error
+	}{
+		{
+			name: "按时调度",
+			mock: func(ctrl *gomock.Controller) repository.JobRepository {
+				repo := repomocks.NewMockJobRepository(ctrl)
+				repo.EXPECT().Preempt(gomock.Any(), gomock.Any()).Return(domain.Job{Id: 1, Version: 1,
+					Expression: "@every 1m", NextExecTime: now.Add(-time.Second)}, nil)
+				return repo
+			},
+			wantId: 1,
+		},
+		{
+			name: "错过调度, 只执行一次",
+			mock: func(ctrl *gomock.Controller) repository.JobRepository {
+				repo := repomocks.NewMockJobRepository(ctrl)
+				repo.EXPECT().Preempt(gomock.Any(), gomock.Any()).Return(domain.Job{Id: 1, Version: 1,
+					Expression: "@every 1m", NextExecTime: now.Add(-5*time.Minute - time.Second)}, nil)
+				// 应调度6次, 执行1次
+				repo.EXPECT().MarkMisfire(gomock.Any(), int64(1), 1, 5, 0).Return(nil)
+				return repo
+			},
+			wantId: 1,
+		},
+		{
+			name: "错过调度, 补跑次数受上限限制",
+			mock: func(ctrl *gomock.Controller) repository.JobRepository {
+				repo := repomocks.NewMockJobRepository(ctrl)
+				repo.EXPECT().Preempt(gomock.Any(), gomock.Any()).Return(domain.Job{Id: 1, Version: 1,
+					Expression: "@every 1m", NextExecTime: now.Add(-5*time.Minute - time.Second),
+					MisfirePolicy: domain.MisfireFireAll, MisfireCap: 4}, nil)
+				// 应调度6次, 本次执行后再补跑3次, 丢弃2次
+				repo.EXPECT().MarkMisfire(gomock.Any(), int64(1), 1, 2, 3).Return(nil)
+				return repo
+			},
+			wantId:      1,
+			wantCatchUp: 3,
+		},
+		{
+			name: "错过调度被丢弃, 继续抢占下一个任务",
+			mock: func(ctrl *gomock.Controller) repository.JobRepository {
+				repo := repomocks.NewMockJobRepository(ctrl)
+				repo.EXPECT().Preempt(gomock.Any(), gomock.Any()).Return(domain.Job{Id: 1, Version: 1,
+					Expression: "@every 1m", NextExecTime: now.Add(-5*time.Minute - time.Second),
+					MisfirePolicy: domain.MisfireSkip}, nil)
+				repo.EXPECT().SkipMisfire(gomock.Any(), int64(1), 1, 6, gomock.Any()).Return(nil)
+				repo.EXPECT().Preempt(gomock.Any(), gomock.Any()).Return(domain.Job{Id: 2, Version: 1,
+					Expression: "@every 1m", NextExecTime: now}, nil)
+				return repo
+			},
+			wantId: 2,
+		},
+		{
+			name: "上游执行缓慢, 下游从上游完成时起算, 不被丢弃",
+			mock: func(ctrl *gomock.Controller) repository.JobRepository {
+				repo := repomocks.NewMockJobRepository(ctrl)
+				repo.EXPECT().Preempt(gomock.Any(), gomock.Any()).Return(domain.Job{Id: 2, Version: 1,
+					Expression: "@every 1h", NextExecTime: now.Add(-30 * time.Minute),
+					MisfirePolicy: domain.MisfireSkip, Upstreams: []int64{1}}, nil)
+				repo.EXPECT().FindByIds(gomock.Any(), []int64{1}).Return([]domain.Job{
+					{Id: 1, LastSuccessTime: now.Add(-time.Second)},
+				}, nil)
+				return repo
+			},
+			wantId: 2,
+		},
+		{
+			name: "上游早已完成, 下游仍按错过调度丢弃",
+			mock: func(ctrl *gomock.Controller) repository.JobRepository {
+				repo := repomocks.NewMockJobRepository(ctrl)
+				repo.EXPECT().Preempt(gomock.Any(), gomock.Any()).Return(domain.Job{Id: 2, Version: 1,
+					Expression: "@every 1m", NextExecTime: now.Add(-5*time.Minute - time.Second),
+					MisfirePolicy: domain.MisfireSkip, Upstreams: []int64{1}}, nil)
+				repo.EXPECT().FindByIds(gomock.Any(), []int64{1}).Return([]domain.Job{
+					{Id: 1, LastSuccessTime: now.Add(-10 * time.Minute)},
+				}, nil)
+				repo.EXPECT().SkipMisfire(gomock.Any(), int64(2), 1, 6, gomock.Any()).Return(nil)
+				repo.EXPECT().Preempt(gomock.Any(), gomock.Any()).Return(domain.Job{Id: 3, Version: 1,
+					Expression: "@every 1m", NextExecTime: now}, nil)
+				return repo
+			},
+			wantId: 3,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			svc := NewCronJobService(tc.mock(ctrl), logger.NewNopLogger())
+			job, err := svc.Preempt(context.Background(), nil)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantId, job.Id)
+			assert.Equal(t, tc.wantCatchUp, job.CatchUp)
+		})
+	}
+}
+
+// @func: TestCronJobService_Pause
+// @date: 2024-01-16 17:02:14
+// @brief: 单元测试-暂停调度, 区分任务不存在与状态不允许
+// @author: Kewin Li
+// @param t
+func TestCronJobService_Pause(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock func(ctrl *gomock.Controller) repository.JobRepository
+
+		wantErr error
+	}{
+		{
+			name: "暂停成功",
+			mock: func(ctrl *gomock.Controller) repository.JobRepository {
+				repo := repomocks.NewMockJobRepository(ctrl)
+				repo.EXPECT().Pause(gomock.Any(), int64(1)).Return(nil)
+				return repo
+			},
+		},
+		{
+			name: "任务已暂停",
+			mock: func(ctrl *gomock.Controller) repository.JobRepository {
+				repo := repomocks.NewMockJobRepository(ctrl)
+				repo.EXPECT().Pause(gomock.Any(), int64(1)).Return(repository.ErrJobStatusMismatch)
+				repo.EXPECT().FindById(gomock.Any(), int64(1)).
+					Return(domain.Job{Id: 1, Status: domain.JobStatusPaused}, nil)
+				return repo
+			},
+			wantErr: ErrJobStatusMismatch,
+		},
+		{
+			name: "任务不存在",
+			mock: func(ctrl *gomock.Controller) repository.JobRepository {
+				repo := repomocks.NewMockJobRepository(ctrl)
+				repo.EXPECT().Pause(gomock.Any(), int64(1)).Return(repository.ErrJobStatusMismatch)
+				repo.EXPECT().FindById(gomock.Any(), int64(1)).
+					Return(domain.Job{}, repository.ErrJobNotFound)
+				return repo
+			},
+			wantErr: ErrJobNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			svc := NewCronJobService(tc.mock(ctrl), logger.NewNopLogger())
+			err := svc.Pause(context.Background(), 1)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+// @func: TestCronJobService_Callback
+// @date: 2024-01-17 15:02:33
+// @brief: 单元测试-远程任务回调, 拒绝未登记或已过期的token
+// @author: Kewin Li
+// @param t
+func TestCronJobService_Callback(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock func(ctrl *gomock.Controller) repository.JobRepository
+
+		wantErr error
+	}{
+		{
+			name: "回调成功",
+			mock: func(ctrl *gomock.Controller) repository.JobRepository {
+				repo := repomocks.NewMockJobRepository(ctrl)
+				repo.EXPECT().SaveCallback(gomock.Any(), domain.JobCallback{Token: "abc", Success: true}).
+					Return(nil)
+				return repo
+			},
+		},
+		{
+			name: "token未登记或已过期",
+			mock: func(ctrl *gomock.Controller) repository.JobRepository {
+				repo := repomocks.NewMockJobRepository(ctrl)
+				repo.EXPECT().SaveCallback(gomock.Any(), domain.JobCallback{Token: "abc", Success: true}).
+					Return(repository.ErrInvalidCallback)
+				return repo
+			},
+			wantErr: ErrInvalidJobCallback,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			svc := NewCronJobService(tc.mock(ctrl), logger.NewNopLogger())
+			err := svc.Callback(context.Background(), domain.JobCallback{Token: "abc", Success: true})
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+// @func: TestCronJobService_Finish
+// @date: 2024-01-17 11:20:48
+// @brief: 单元测试-任务执行完毕, 失败时按重试策略调度
+// @author: Kewin Li
+// @param t
+func TestCronJobService_Finish(t *testing.T) {
+	now := time.Now()
+
+	testCases := []struct {
+		name string
+
+		mock func(ctrl *gomock.Controller) repository.JobRepository
+
+		job  domain.Job
+		exec domain.JobExecution
+
+		wantErr error
+	}{
+		{
+			name: "执行成功, 按cron调度",
+			mock: func(ctrl *gomock.Controller) repository.JobRepository {
+				repo := repomocks.NewMockJobRepository(ctrl)
+				repo.EXPECT().Finish(gomock.Any(), gomock.Any(), gomock.Any(), 0, 0, gomock.Any()).
+					DoAndReturn(func(ctx context.Context, exec domain.JobExecution, version int, retryCnt int, catchUp int, nextTime time.Time) error {
+						assert.Equal(t, 2, exec.Attempt)
+						assert.True(t, nextTime.After(now.Add(50*time.Minute)))
+						return nil
+					})
+				return repo
+			},
+			job: domain.Job{Id: 1, Expression: "@every 1h", MaxRetries: 3,
+				RetryInterval: time.Minute, RetryCnt: 1},
+			exec: domain.JobExecution{Result: domain.JobResultSuccess, EndTime: now},
+		},
+		{
+			name: "执行失败, 指数退避重试",
+			mock: func(ctrl *gomock.Controller) repository.JobRepository {
+				repo := repomocks.NewMockJobRepository(ctrl)
+				repo.EXPECT().Finish(gomock.Any(), gomock.Any(), gomock.Any(), 2, 0, now.Add(2*time.Minute)).Return(nil)
+				return repo
+			},
+			job: domain.Job{Id: 1, Expression: "@every 1h", MaxRetries: 3,
+				RetryInterval: time.Minute, RetryCnt: 1},
+			exec: domain.JobExecution{Result: domain.JobResultFailed, EndTime: now},
+		},
+		{
+			name: "执行失败, 重试次数耗尽",
+			mock: func(ctrl *gomock.Controller) repository.JobRepository {
+				repo := repomocks.NewMockJobRepository(ctrl)
+				repo.EXPECT().Finish(gomock.Any(), gomock.Any(), gomock.Any(), 0, 0, gomock.Any()).Return(nil)
+				return repo
+			},
+			job: domain.Job{Id: 1, Expression: "@every 1h", MaxRetries: 3,
+				RetryInterval: time.Minute, RetryCnt: 3},
+			exec: domain.JobExecution{Result: domain.JobResultFailed, EndTime: now},
+		},
+		{
+			name: "执行失败, 重试时间晚于下一次cron调度",
+			mock: func(ctrl *gomock.Controller) repository.JobRepository {
+				repo := repomocks.NewMockJobRepository(ctrl)
+				repo.EXPECT().Finish(gomock.Any(), gomock.Any(), gomock.Any(), 0, 0, gomock.Any()).Return(nil)
+				return repo
+			},
+			job: domain.Job{Id: 1, Expression: "@every 1m", MaxRetries: 3,
+				RetryInterval: time.Hour},
+			exec: domain.JobExecution{Result: domain.JobResultFailed, EndTime: now},
+		},
+		{
+			name: "还有待补跑, 立即调度",
+			mock: func(ctrl *gomock.Controller) repository.JobRepository {
+				repo := repomocks.NewMockJobRepository(ctrl)
+				repo.EXPECT().Finish(gomock.Any(), gomock.Any(), gomock.Any(), 0, 1, now).Return(nil)
+				return repo
+			},
+			job: domain.Job{Id: 1, Expression: "@every 1h", MisfirePolicy: domain.MisfireFireAll,
+				MisfireCap: 3, CatchUp: 2},
+			exec: domain.JobExecution{Result: domain.JobResultSuccess, EndTime: now},
+		},
+		{
+			name: "广播任务执行失败, 不重试",
+			mock: func(ctrl *gomock.Controller) repository.JobRepository {
+				repo := repomocks.NewMockJobRepository(ctrl)
+				repo.EXPECT().FinishBroadcast(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, exec domain.JobExecution) error {
+						assert.Equal(t, int64(1), exec.JobId)
+						assert.Equal(t, domain.JobResultFailed, exec.Result)
+						return nil
+					})
+				return repo
+			},
+			job:  domain.Job{Id: 1, Expression: "@every 1h", Mode: domain.JobModeBroadcast},
+			exec: domain.JobExecution{Result: domain.JobResultFailed, EndTime: now},
+		},
+		{
+			name: "分片执行完毕, 记录分片序号",
+			mock: func(ctrl *gomock.Controller) repository.JobRepository {
+				repo := repomocks.NewMockJobRepository(ctrl)
+				repo.EXPECT().FinishShard(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, exec domain.JobExecution, job domain.Job, nextTime time.Time) error {
+						assert.Equal(t, 2, exec.ShardIndex)
+						assert.Equal(t, int64(10), job.Shard.Id)
+						assert.True(t, nextTime.After(now.Add(50*time.Minute)))
+						return nil
+					})
+				return repo
+			},
+			job: domain.Job{Id: 1, Expression: "@every 1h", Mode: domain.JobModeSharding, ShardTotal: 4,
+				Shard: domain.JobShard{Id: 10, Index: 2, Version: 1, Round: now}},
+			exec: domain.JobExecution{Result: domain.JobResultSuccess, EndTime: now},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			svc := NewCronJobService(tc.mock(ctrl), logger.NewNopLogger())
+			err := svc.Finish(context.Background(), tc.job, tc.exec)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
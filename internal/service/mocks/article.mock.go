@@ -40,6 +40,65 @@ func (m *MockArticleService) EXPECT() *MockArticleServiceMockRecorder {
 	return m.recorder
 }
 
+// ApproveReview mocks base method.
+func (m *MockArticleService) ApproveReview(ctx context.Context, reviewId, reviewerId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApproveReview", ctx, reviewId, reviewerId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ApproveReview indicates an expected call of ApproveReview.
+func (mr *MockArticleServiceMockRecorder) ApproveReview(ctx, reviewId, reviewerId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApproveReview", reflect.TypeOf((*MockArticleService)(nil).ApproveReview), ctx, reviewId, reviewerId)
+}
+
+// Autosave mocks base method.
+func (m *MockArticleService) Autosave(ctx context.Context, art domain.Article) (int64, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Autosave", ctx, art)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Autosave indicates an expected call of Autosave.
+func (mr *MockArticleServiceMockRecorder) Autosave(ctx, art any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Autosave", reflect.TypeOf((*MockArticleService)(nil).Autosave), ctx, art)
+}
+
+// CancelSchedule mocks base method.
+func (m *MockArticleService) CancelSchedule(ctx context.Context, artId, authorId int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelSchedule", ctx, artId, authorId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelSchedule indicates an expected call of CancelSchedule.
+func (mr *MockArticleServiceMockRecorder) CancelSchedule(ctx, artId, authorId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelSchedule", reflect.TypeOf((*MockArticleService)(nil).CancelSchedule), ctx, artId, authorId)
+}
+
+// DiffRevisions mocks base method.
+func (m *MockArticleService) DiffRevisions(ctx context.Context, artId, authorId int64, from, to int) (domain.ArticleDiff, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DiffRevisions", ctx, artId, authorId, from, to)
+	ret0, _ := ret[0].(domain.ArticleDiff)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DiffRevisions indicates an expected call of DiffRevisions.
+func (mr *MockArticleServiceMockRecorder) DiffRevisions(ctx, artId, authorId, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DiffRevisions", reflect.TypeOf((*MockArticleService)(nil).DiffRevisions), ctx, artId, authorId, from, to)
+}
+
 // GetByAuthor mocks base method.
 func (m *MockArticleService) GetByAuthor(ctx context.Context, userId int64, offset, limit int) ([]domain.Article, error) {
 	m.ctrl.T.Helper()
@@ -85,6 +144,51 @@ func (mr *MockArticleServiceMockRecorder) GetPubById(ctx, artId, userId any) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPubById", reflect.TypeOf((*MockArticleService)(nil).GetPubById), ctx, artId, userId)
 }
 
+// GetReview mocks base method.
+func (m *MockArticleService) GetReview(ctx context.Context, artId, authorId int64) (domain.ArticleReview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReview", ctx, artId, authorId)
+	ret0, _ := ret[0].(domain.ArticleReview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReview indicates an expected call of GetReview.
+func (mr *MockArticleServiceMockRecorder) GetReview(ctx, artId, authorId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReview", reflect.TypeOf((*MockArticleService)(nil).GetReview), ctx, artId, authorId)
+}
+
+// GetSchedule mocks base method.
+func (m *MockArticleService) GetSchedule(ctx context.Context, artId, authorId int64) (domain.ArticleSchedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSchedule", ctx, artId, authorId)
+	ret0, _ := ret[0].(domain.ArticleSchedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSchedule indicates an expected call of GetSchedule.
+func (mr *MockArticleServiceMockRecorder) GetSchedule(ctx, artId, authorId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSchedule", reflect.TypeOf((*MockArticleService)(nil).GetSchedule), ctx, artId, authorId)
+}
+
+// ListPendingReviews mocks base method.
+func (m *MockArticleService) ListPendingReviews(ctx context.Context, offset, limit int) ([]domain.ArticleReview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPendingReviews", ctx, offset, limit)
+	ret0, _ := ret[0].([]domain.ArticleReview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPendingReviews indicates an expected call of ListPendingReviews.
+func (mr *MockArticleServiceMockRecorder) ListPendingReviews(ctx, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPendingReviews", reflect.TypeOf((*MockArticleService)(nil).ListPendingReviews), ctx, offset, limit)
+}
+
 // ListPub mocks base method.
 func (m *MockArticleService) ListPub(ctx context.Context, start time.Time, offset, limit int) ([]domain.Article, error) {
 	m.ctrl.T.Helper()
@@ -100,13 +204,45 @@ func (mr *MockArticleServiceMockRecorder) ListPub(ctx, start, offset, limit any)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPub", reflect.TypeOf((*MockArticleService)(nil).ListPub), ctx, start, offset, limit)
 }
 
+// ListPubByTag mocks base method.
+func (m *MockArticleService) ListPubByTag(ctx context.Context, tag string, offset, limit int) ([]domain.Article, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPubByTag", ctx, tag, offset, limit)
+	ret0, _ := ret[0].([]domain.Article)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListPubByTag indicates an expected call of ListPubByTag.
+func (mr *MockArticleServiceMockRecorder) ListPubByTag(ctx, tag, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPubByTag", reflect.TypeOf((*MockArticleService)(nil).ListPubByTag), ctx, tag, offset, limit)
+}
+
+// ListRevisions mocks base method.
+func (m *MockArticleService) ListRevisions(ctx context.Context, artId, authorId int64, offset, limit int) ([]domain.ArticleRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRevisions", ctx, artId, authorId, offset, limit)
+	ret0, _ := ret[0].([]domain.ArticleRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRevisions indicates an expected call of ListRevisions.
+func (mr *MockArticleServiceMockRecorder) ListRevisions(ctx, artId, authorId, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRevisions", reflect.TypeOf((*MockArticleService)(nil).ListRevisions), ctx, artId, authorId, offset, limit)
+}
+
 // Publish mocks base method.
-func (m *MockArticleService) Publish(ctx context.Context, art domain.Article) (int64, error) {
+func (m *MockArticleService) Publish(ctx context.Context, art domain.Article) (int64, int64, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Publish", ctx, art)
 	ret0, _ := ret[0].(int64)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // Publish indicates an expected call of Publish.
@@ -115,13 +251,57 @@ func (mr *MockArticleServiceMockRecorder) Publish(ctx, art any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockArticleService)(nil).Publish), ctx, art)
 }
 
+// PublishDue mocks base method.
+func (m *MockArticleService) PublishDue(ctx context.Context, now time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishDue", ctx, now)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PublishDue indicates an expected call of PublishDue.
+func (mr *MockArticleServiceMockRecorder) PublishDue(ctx, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishDue", reflect.TypeOf((*MockArticleService)(nil).PublishDue), ctx, now)
+}
+
+// RejectReview mocks base method.
+func (m *MockArticleService) RejectReview(ctx context.Context, reviewId, reviewerId int64, reasons []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RejectReview", ctx, reviewId, reviewerId, reasons)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RejectReview indicates an expected call of RejectReview.
+func (mr *MockArticleServiceMockRecorder) RejectReview(ctx, reviewId, reviewerId, reasons any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RejectReview", reflect.TypeOf((*MockArticleService)(nil).RejectReview), ctx, reviewId, reviewerId, reasons)
+}
+
+// Rollback mocks base method.
+func (m *MockArticleService) Rollback(ctx context.Context, artId, authorId int64, version int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rollback", ctx, artId, authorId, version)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Rollback indicates an expected call of Rollback.
+func (mr *MockArticleServiceMockRecorder) Rollback(ctx, artId, authorId, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rollback", reflect.TypeOf((*MockArticleService)(nil).Rollback), ctx, artId, authorId, version)
+}
+
 // Save mocks base method.
-func (m *MockArticleService) Save(ctx context.Context, art domain.Article) (int64, error) {
+func (m *MockArticleService) Save(ctx context.Context, art domain.Article) (int64, int64, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Save", ctx, art)
 	ret0, _ := ret[0].(int64)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // Save indicates an expected call of Save.
@@ -130,6 +310,37 @@ func (mr *MockArticleServiceMockRecorder) Save(ctx, art any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockArticleService)(nil).Save), ctx, art)
 }
 
+// Schedule mocks base method.
+func (m *MockArticleService) Schedule(ctx context.Context, art domain.Article, publishTime time.Time) (int64, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Schedule", ctx, art, publishTime)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Schedule indicates an expected call of Schedule.
+func (mr *MockArticleServiceMockRecorder) Schedule(ctx, art, publishTime any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Schedule", reflect.TypeOf((*MockArticleService)(nil).Schedule), ctx, art, publishTime)
+}
+
+// SearchTags mocks base method.
+func (m *MockArticleService) SearchTags(ctx context.Context, prefix string, limit int) ([]domain.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchTags", ctx, prefix, limit)
+	ret0, _ := ret[0].([]domain.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchTags indicates an expected call of SearchTags.
+func (mr *MockArticleServiceMockRecorder) SearchTags(ctx, prefix, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchTags", reflect.TypeOf((*MockArticleService)(nil).SearchTags), ctx, prefix, limit)
+}
+
 // Withdraw mocks base method.
 func (m *MockArticleService) Withdraw(ctx context.Context, art domain.Article) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,58 @@
+package moderation
+
+import (
+	"context"
+	"kitbook/internal/domain"
+	"kitbook/pkg/logger"
+)
+
+// Chain
+// @Description: 审核链, 依次执行各审核器, 取最严格的结论; 任一审核器拒绝即停止
+type Chain struct {
+	moderators []Moderator
+	l          logger.Logger
+}
+
+func NewChain(l logger.Logger, moderators ...Moderator) Moderator {
+	return &Chain{
+		moderators: moderators,
+		l:          l,
+	}
+}
+
+// @func: Moderate
+// @date: 2024-01-25 11:20:16
+// @brief: 审核链-审核器故障时转人工审核, 不直接放行也不拒绝
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+// @param art
+// @return domain.ModerationResult
+// @return error 总是nil
+func (c *Chain) Moderate(ctx context.Context, art domain.Article) (domain.ModerationResult, error) {
+	// 各审核器共用渲染结果
+	art.RenderHTML()
+	res := domain.ModerationResult{
+		Verdict: domain.ModerationVerdictPass,
+	}
+
+	for _, m := range c.moderators {
+		cur, err := m.Moderate(ctx, art)
+		if err != nil {
+			c.l.ERROR("审核器执行失败, 转人工审核",
+				logger.Error(err),
+				logger.Int[int64]("artId", art.Id))
+			cur = domain.ModerationResult{
+				Verdict: domain.ModerationVerdictReview,
+				Reasons: []string{"自动审核暂不可用, 转人工审核"},
+			}
+		}
+
+		res = res.Merge(cur)
+		if res.Verdict == domain.ModerationVerdictReject {
+			break
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,132 @@
+package moderation
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"kitbook/internal/domain"
+	"kitbook/pkg/logger"
+	"kitbook/pkg/sensitive"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// @func: TestChain_Moderate
+// @date: 2024-01-25 12:10:36
+// @brief: 单元测试-审核链
+// @author: Kewin Li
+// @param t
+func TestChain_Moderate(t *testing.T) {
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Title string `json:"title"`
+		}
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+
+		switch req.Title {
+		case "外部拒绝":
+			_, _ = w.Write([]byte(`{"verdict":"reject","reasons":["涉政"]}`))
+		case "外部故障":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			_, _ = w.Write([]byte(`{"verdict":"pass"}`))
+		}
+	}))
+	defer remote.Close()
+
+	chain := NewChain(logger.NewNopLogger(),
+		NewWordModerator(sensitive.NewMatcher([]string{"赌博"}), sensitive.NewMatcher([]string{"投资"})),
+		NewLinkModerator([]string{"bad.com"}, []string{"kitbook.com"}, 1),
+		NewRemoteModerator(remote.Client(), remote.URL, "token", time.Second),
+	)
+
+	testCases := []struct {
+		name string
+		art  domain.Article
+
+		want domain.ModerationResult
+	}{
+		{
+			name: "全部通过",
+			art: domain.Article{
+				Title:   "标题",
+				Content: "正文 [本站](https://www.kitbook.com/a) [外链](https://go.dev)",
+			},
+			want: domain.ModerationResult{Verdict: domain.ModerationVerdictPass},
+		},
+		{
+			name: "违禁词直接拒绝, 不再调用后续审核器",
+			art: domain.Article{
+				Title:   "外部故障",
+				Content: "**赌**博",
+			},
+			want: domain.ModerationResult{
+				Verdict: domain.ModerationVerdictReject,
+				Reasons: []string{"包含违禁词: 赌博"},
+			},
+		},
+		{
+			name: "敏感词与外部链接过多, 合并原因转人工审核",
+			art: domain.Article{
+				Title:   "投资",
+				Content: "[a](https://a.com) ![b](https://b.com/x.png) http://10.0.0.1/x",
+			},
+			want: domain.ModerationResult{
+				Verdict: domain.ModerationVerdictReview,
+				Reasons: []string{
+					"包含敏感词, 需人工审核: 投资",
+					"包含IP地址链接, 需人工审核: 10.0.0.1",
+					"外部链接域名超过1个, 需人工审核",
+				},
+			},
+		},
+		{
+			name: "禁止的子域名拒绝",
+			art: domain.Article{
+				Title:   "标题",
+				Content: "<a href=\"https://x.BAD.com/\">点我</a>",
+			},
+			want: domain.ModerationResult{
+				Verdict: domain.ModerationVerdictReject,
+				Reasons: []string{"包含被禁止的链接: x.bad.com"},
+			},
+		},
+		{
+			name: "外部审核拒绝",
+			art: domain.Article{
+				Title:   "外部拒绝",
+				Content: "正文",
+			},
+			want: domain.ModerationResult{
+				Verdict: domain.ModerationVerdictReject,
+				Reasons: []string{"涉政"},
+			},
+		},
+		{
+			name: "外部审核故障, 转人工审核",
+			art: domain.Article{
+				Title:   "外部故障",
+				Content: "正文",
+			},
+			want: domain.ModerationResult{
+				Verdict: domain.ModerationVerdictReview,
+				Reasons: []string{"自动审核暂不可用, 转人工审核"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := chain.Moderate(context.Background(), tc.art)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, res)
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package moderation
+
+import (
+	"context"
+	"golang.org/x/net/html"
+	"kitbook/internal/domain"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// 正文中未加链接的网址
+var plainURLRegexp = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"'` + "`" + `()\[\]{}，。！？、；：“”‘’（）【】]+`)
+
+// LinkModerator
+// @Description: 链接审核, 命中禁止的域名直接拒绝; 外部域名过多、使用IP地址的链接转人工审核
+type LinkModerator struct {
+	// 禁止的域名, 子域名同样禁止
+	blocked []string
+	// 可信域名(如本站、对象存储), 不计入外部域名
+	trusted []string
+	// 允许的外部域名数, 超过时转人工审核, <=0不限制
+	maxExternal int
+}
+
+func NewLinkModerator(blocked []string, trusted []string, maxExternal int) Moderator {
+	return &LinkModerator{
+		blocked:     normalizeDomains(blocked),
+		trusted:     normalizeDomains(trusted),
+		maxExternal: maxExternal,
+	}
+}
+
+// @func: Moderate
+// @date: 2024-01-25 11:40:26
+// @brief: 链接审核-检查渲染后的链接、图片地址及正文中的网址
+// @author: Kewin Li
+// @receiver l
+// @param ctx
+// @param art
+// @return domain.ModerationResult
+// @return error
+func (l *LinkModerator) Moderate(ctx context.Context, art domain.Article) (domain.ModerationResult, error) {
+	res := domain.ModerationResult{
+		Verdict: domain.ModerationVerdictPass,
+	}
+
+	blocked := make([]string, 0)
+	ipHosts := make([]string, 0)
+	external := make(map[string]struct{})
+	seen := make(map[string]struct{})
+
+	for _, host := range linkHosts(art.RenderHTML(), art.PlainText()) {
+		if _, ok := seen[host]; ok {
+			continue
+		}
+		seen[host] = struct{}{}
+
+		switch {
+		case matchDomain(host, l.blocked):
+			blocked = append(blocked, host)
+		case net.ParseIP(host) != nil:
+			ipHosts = append(ipHosts, host)
+		case !matchDomain(host, l.trusted):
+			external[host] = struct{}{}
+		}
+	}
+
+	if len(blocked) > 0 {
+		return domain.ModerationResult{
+			Verdict: domain.ModerationVerdictReject,
+			Reasons: []string{"包含被禁止的链接: " + strings.Join(blocked, "、")},
+		}, nil
+	}
+
+	if len(ipHosts) > 0 {
+		res = res.Merge(domain.ModerationResult{
+			Verdict: domain.ModerationVerdictReview,
+			Reasons: []string{"包含IP地址链接, 需人工审核: " + strings.Join(ipHosts, "、")},
+		})
+	}
+	if l.maxExternal > 0 && len(external) > l.maxExternal {
+		res = res.Merge(domain.ModerationResult{
+			Verdict: domain.ModerationVerdictReview,
+			Reasons: []string{"外部链接域名超过" + strconv.Itoa(l.maxExternal) + "个, 需人工审核"},
+		})
+	}
+
+	return res, nil
+}
+
+// @func: linkHosts
+// @date: 2024-01-25 11:45:50
+// @brief: 链接审核-提取HTML中href、src及纯文本中网址的域名, 相对地址忽略
+// @author: Kewin Li
+// @param doc
+// @param text
+// @return []string 小写、不含端口
+func linkHosts(doc string, text string) []string {
+	raws := make([]string, 0)
+
+	tokenizer := html.NewTokenizer(strings.NewReader(doc))
+	for {
+		tt := tokenizer.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			continue
+		}
+
+		for {
+			key, val, more := tokenizer.TagAttr()
+			if k := string(key); k == "href" || k == "src" {
+				raws = append(raws, string(val))
+			}
+			if !more {
+				break
+			}
+		}
+	}
+
+	for _, raw := range plainURLRegexp.FindAllString(text, -1) {
+		if strings.HasPrefix(strings.ToLower(raw), "www.") {
+			raw = "http://" + raw
+		}
+		raws = append(raws, raw)
+	}
+
+	hosts := make([]string, 0, len(raws))
+	for _, raw := range raws {
+		u, err := url.Parse(strings.TrimSpace(raw))
+		if err != nil || u.Host == "" {
+			continue
+		}
+		hosts = append(hosts, strings.TrimSuffix(strings.ToLower(u.Hostname()), "."))
+	}
+
+	return hosts
+}
+
+// @func: matchDomain
+// @date: 2024-01-25 11:48:16
+// @brief: 链接审核-域名是否为列表中的域名或其子域名
+// @author: Kewin Li
+// @param host
+// @param domains
+// @return bool
+func matchDomain(host string, domains []string) bool {
+	for _, d := range domains {
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func normalizeDomains(domains []string) []string {
+	res := make([]string, 0, len(domains))
+	for _, d := range domains {
+		d = strings.Trim(strings.ToLower(strings.TrimSpace(d)), ".")
+		if d != "" {
+			res = append(res, d)
+		}
+	}
+
+	return res
+}
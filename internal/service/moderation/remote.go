@@ -0,0 +1,119 @@
+package moderation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"kitbook/internal/domain"
+	"net/http"
+	"time"
+)
+
+const (
+	// 外部审核服务默认超时
+	remoteDefaultTimeout = 3 * time.Second
+	// 外部审核服务响应最大长度
+	remoteRespMaxLen = 64 * 1024
+)
+
+// RemoteModerator
+// @Description: 外部审核服务(第三方内容安全接口或自建审核服务)的接入点
+//
+// 请求: POST {"artId", "authorId", "title", "content"}
+// 响应: 200 {"verdict": "pass"|"review"|"reject", "reasons": ["..."]}
+type RemoteModerator struct {
+	client  *http.Client
+	url     string
+	token   string
+	timeout time.Duration
+}
+
+func NewRemoteModerator(client *http.Client, url string, token string, timeout time.Duration) Moderator {
+	if timeout <= 0 {
+		timeout = remoteDefaultTimeout
+	}
+
+	return &RemoteModerator{
+		client:  client,
+		url:     url,
+		token:   token,
+		timeout: timeout,
+	}
+}
+
+// @func: Moderate
+// @date: 2024-01-25 11:55:40
+// @brief: 外部审核-调用外部审核服务, 超时、响应异常返回error, 由审核链转人工审核
+// @author: Kewin Li
+// @receiver r
+// @param ctx
+// @param art
+// @return domain.ModerationResult
+// @return error
+func (r *RemoteModerator) Moderate(ctx context.Context, art domain.Article) (domain.ModerationResult, error) {
+	type Req struct {
+		ArtId    int64  `json:"artId"`
+		AuthorId int64  `json:"authorId"`
+		Title    string `json:"title"`
+		Content  string `json:"content"`
+	}
+	type Resp struct {
+		Verdict string   `json:"verdict"`
+		Reasons []string `json:"reasons"`
+	}
+
+	body, err := json.Marshal(Req{
+		ArtId:    art.Id,
+		AuthorId: art.Author.Id,
+		Title:    art.Title,
+		Content:  art.Content,
+	})
+	if err != nil {
+		return domain.ModerationResult{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.url, bytes.NewReader(body))
+	if err != nil {
+		return domain.ModerationResult{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if r.token != "" {
+		req.Header.Set("Authorization", "Bearer "+r.token)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return domain.ModerationResult{}, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, remoteRespMaxLen))
+	if err != nil {
+		return domain.ModerationResult{}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return domain.ModerationResult{}, fmt.Errorf("外部审核服务响应异常: %d %s", resp.StatusCode, string(data))
+	}
+
+	var res Resp
+	err = json.Unmarshal(data, &res)
+	if err != nil {
+		return domain.ModerationResult{}, err
+	}
+
+	switch res.Verdict {
+	case "pass":
+		return domain.ModerationResult{Verdict: domain.ModerationVerdictPass, Reasons: res.Reasons}, nil
+	case "review":
+		return domain.ModerationResult{Verdict: domain.ModerationVerdictReview, Reasons: res.Reasons}, nil
+	case "reject":
+		return domain.ModerationResult{Verdict: domain.ModerationVerdictReject, Reasons: res.Reasons}, nil
+	default:
+		return domain.ModerationResult{}, fmt.Errorf("外部审核服务结论未知: %s", res.Verdict)
+	}
+}
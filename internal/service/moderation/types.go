@@ -0,0 +1,13 @@
+// Package moderation
+// @Description: 帖子发表前的内容审核, 各审核器组成审核链
+package moderation
+
+import (
+	"context"
+	"kitbook/internal/domain"
+)
+
+type Moderator interface {
+	// Moderate 返回的error表示审核器自身故障, 不代表内容违规
+	Moderate(ctx context.Context, art domain.Article) (domain.ModerationResult, error)
+}
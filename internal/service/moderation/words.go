@@ -0,0 +1,53 @@
+package moderation
+
+import (
+	"context"
+	"kitbook/internal/domain"
+	"kitbook/pkg/sensitive"
+	"strings"
+)
+
+// WordModerator
+// @Description: 敏感词审核, 命中禁用词直接拒绝, 命中可疑词转人工审核
+type WordModerator struct {
+	banned  *sensitive.Matcher
+	suspect *sensitive.Matcher
+}
+
+func NewWordModerator(banned *sensitive.Matcher, suspect *sensitive.Matcher) Moderator {
+	return &WordModerator{
+		banned:  banned,
+		suspect: suspect,
+	}
+}
+
+// @func: Moderate
+// @date: 2024-01-25 11:30:42
+// @brief: 敏感词审核-检查标题、渲染后的正文及原文, 原文中藏在注释、链接里的词同样会被发现
+// @author: Kewin Li
+// @receiver w
+// @param ctx
+// @param art
+// @return domain.ModerationResult
+// @return error
+func (w *WordModerator) Moderate(ctx context.Context, art domain.Article) (domain.ModerationResult, error) {
+	text := art.Title + "\n" + art.PlainText() + "\n" + art.Content
+
+	if words := w.banned.FindAll(text); len(words) > 0 {
+		return domain.ModerationResult{
+			Verdict: domain.ModerationVerdictReject,
+			Reasons: []string{"包含违禁词: " + strings.Join(words, "、")},
+		}, nil
+	}
+
+	if words := w.suspect.FindAll(text); len(words) > 0 {
+		return domain.ModerationResult{
+			Verdict: domain.ModerationVerdictReview,
+			Reasons: []string{"包含敏感词, 需人工审核: " + strings.Join(words, "、")},
+		}, nil
+	}
+
+	return domain.ModerationResult{
+		Verdict: domain.ModerationVerdictPass,
+	}, nil
+}
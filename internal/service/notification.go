@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"kitbook/internal/domain"
+	"kitbook/internal/repository"
+	"time"
+)
+
+type NotificationService interface {
+	Notify(ctx context.Context, n domain.Notification) error
+	List(ctx context.Context, uid int64, end time.Time, limit int) ([]domain.Notification, error)
+}
+
+type NormalNotificationService struct {
+	repo repository.NotificationRepository
+}
+
+func NewNormalNotificationService(repo repository.NotificationRepository) NotificationService {
+	return &NormalNotificationService{
+		repo: repo,
+	}
+}
+
+// @func: Notify
+// @date: 2024-01-28 10:30:18
+// @brief: 站内通知-发送通知
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param notification
+// @return error
+func (n *NormalNotificationService) Notify(ctx context.Context, notification domain.Notification) error {
+	return n.repo.Create(ctx, notification)
+}
+
+// @func: List
+// @date: 2024-01-28 10:31:45
+// @brief: 站内通知-按通知时间倒序分页查询
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param uid
+// @param end 上一页最后一条通知的时间
+// @param limit
+// @return []domain.Notification
+// @return error
+func (n *NormalNotificationService) List(ctx context.Context, uid int64, end time.Time, limit int) ([]domain.Notification, error) {
+	return n.repo.FindByUid(ctx, uid, end, limit)
+}
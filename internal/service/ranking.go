@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"github.com/liyue201/gostl/ds/priorityqueue"
 	"kitbook/internal/domain"
 	"kitbook/internal/repository"
@@ -9,9 +10,76 @@ import (
 	"time"
 )
 
+var ErrRankingNotFound = errors.New("榜单不存在")
+
 type RankingService interface {
 	TopN(ctx context.Context) error
-	GetTopN(ctx context.Context) ([]domain.Article, error)
+	GetTopN(ctx context.Context, name string) ([]domain.Article, error)
+	RefreshLocalCache(ctx context.Context) error
+	Incr(ctx context.Context, artId int64, delta domain.Interactive) error
+}
+
+// Leaderboard
+// @Description: 命名榜单, 每个榜单有自己的分数生成函数、统计窗口和榜单大小
+type Leaderboard struct {
+	Name string
+	// 只统计窗口内更新过的帖子
+	Window time.Duration
+	// 榜单大小, 维护score最高的前N条记录
+	N int
+	// 分数生成函数
+	ScoreFunc func(intr domain.Interactive, utime time.Time) float64
+}
+
+// @func: GravityScoreFunc
+// @date: 2024-01-15 20:10:33
+// @brief: 热榜服务-按阅读数、点赞数、收藏数加权, 并随时间衰减的分数生成函数
+// @author: Kewin Li
+// @param readWeight
+// @param likeWeight
+// @param collectWeight
+// @param gravity 时间衰减系数, 0表示不衰减
+// @return func(intr domain.Interactive, utime time.Time) float64
+func GravityScoreFunc(readWeight, likeWeight, collectWeight, gravity float64) func(intr domain.Interactive, utime time.Time) float64 {
+	return func(intr domain.Interactive, utime time.Time) float64 {
+		weighted := readWeight*float64(intr.ReadCnt) +
+			likeWeight*float64(intr.LikeCnt) +
+			collectWeight*float64(intr.CollectCnt)
+		duration := time.Since(utime).Seconds()
+		return (weighted - 1) / math.Pow(duration+1, gravity)
+	}
+}
+
+// @func: DefaultLeaderboards
+// @date: 2024-01-15 20:16:52
+// @brief: 热榜服务-默认榜单, 未配置榜单时使用
+// @author: Kewin Li
+// @return []Leaderboard
+func DefaultLeaderboards() []Leaderboard {
+	const week = 7 * 24 * time.Hour
+	return []Leaderboard{
+		{
+			// 热榜: 只看点赞数, 随时间衰减
+			Name:      "hot",
+			Window:    week,
+			N:         100,
+			ScoreFunc: GravityScoreFunc(0, 1, 0, 1.5),
+		},
+		{
+			// 周榜: 一周内阅读、点赞、收藏加权
+			Name:      "weekly",
+			Window:    week,
+			N:         100,
+			ScoreFunc: GravityScoreFunc(1, 2, 3, 0),
+		},
+		{
+			// 收藏榜: 一个月内收藏最多
+			Name:      "collect",
+			Window:    30 * 24 * time.Hour,
+			N:         100,
+			ScoreFunc: GravityScoreFunc(0, 0, 1, 0),
+		},
+	}
 }
 
 type BatchRankingService struct {
@@ -21,21 +89,20 @@ type BatchRankingService struct {
 	repo repository.RankingRepository
 
 	batchSize int //一批查询出多少条数据
-	// 分数生成函数
-	scoreFunc func(likeCnt int64, utime time.Time) float64
-	n         int //总共需要多少条数据
+	// 同一批数据计算全部榜单
+	boards []Leaderboard
 }
 
-func NewBatchRankingService(intrSvc InteractiveService, artSvc ArticleService) RankingService {
+func NewBatchRankingService(intrSvc InteractiveService,
+	artSvc ArticleService,
+	repo repository.RankingRepository,
+	boards []Leaderboard) RankingService {
 	return &BatchRankingService{
 		intrSvc:   intrSvc,
 		artSvc:    artSvc,
+		repo:      repo,
 		batchSize: 100, // 每一批查100条记录
-		n:         100, // 维护score最高的前100条记录
-		scoreFunc: func(likeCnt int64, utime time.Time) float64 {
-			duration := time.Since(utime).Seconds()
-			return float64(likeCnt-1) / math.Pow(duration+1, 1.5)
-		},
+		boards:    boards,
 	}
 }
 
@@ -47,24 +114,32 @@ func NewBatchRankingService(intrSvc InteractiveService, artSvc ArticleService) R
 // @param ctx
 // @return error
 func (b *BatchRankingService) TopN(ctx context.Context) error {
-	arts, err := b.topN(ctx)
+	res, err := b.topN(ctx)
 	if err != nil {
 		return err
 	}
 
 	//最终放入缓存中
 	//拆分两个函数, 目的：先将热点算法本身测试正确，然后再连带缓存放入一起进行测试
-	return b.repo.ReplaceTopN(ctx, arts)
+	for _, board := range b.boards {
+		err = b.repo.ReplaceTopN(ctx, board.Name, res[board.Name])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 // @func: topN
 // @date: 2023-12-27 00:27:42
-// @brief: 热点算法真正实现
+// @brief: 热点算法真正实现, 分批查询帖子, 同时计算全部榜单
 // @author: Kewin Li
 // @receiver b
 // @param ctx
+// @return map[string][]domain.Article 榜单名称-->榜单数据
 // @return error
-func (b *BatchRankingService) topN(ctx context.Context) ([]domain.Article, error) {
+func (b *BatchRankingService) topN(ctx context.Context) (map[string][]domain.Article, error) {
 	type Score struct {
 		score float64
 		art   domain.Article
@@ -72,25 +147,35 @@ func (b *BatchRankingService) topN(ctx context.Context) ([]domain.Article, error
 
 	offset := 0
 	start := time.Now()
-	// 截止一周前数据
-	ddl := start.Add(-7 * 24 * time.Hour)
 
-	queue := priorityqueue.New[Score](func(a, b Score) int {
-		if a.score < b.score {
-			return -1
+	// 每个榜单一个小顶堆
+	queues := make([]*priorityqueue.PriorityQueue[Score], len(b.boards))
+	ddls := make([]time.Time, len(b.boards))
+	// 截止到统计窗口最大的榜单
+	ddl := start
+	for i, board := range b.boards {
+		queues[i] = priorityqueue.New[Score](func(a, b Score) int {
+			if a.score < b.score {
+				return -1
 
-		} else if a.score > b.score {
-			return 1
-		}
+			} else if a.score > b.score {
+				return 1
+			}
 
-		if a.art.Utime.UnixMilli() < b.art.Utime.UnixMilli() {
-			return -1
-		} else if a.art.Utime.UnixMilli() > b.art.Utime.UnixMilli() {
-			return 1
-		}
+			if a.art.Utime.UnixMilli() < b.art.Utime.UnixMilli() {
+				return -1
+			} else if a.art.Utime.UnixMilli() > b.art.Utime.UnixMilli() {
+				return 1
+			}
 
-		return 0
-	})
+			return 0
+		})
+
+		ddls[i] = start.Add(-board.Window)
+		if ddls[i].Before(ddl) {
+			ddl = ddls[i]
+		}
+	}
 
 	for {
 		// 查文章
@@ -110,40 +195,48 @@ func (b *BatchRankingService) topN(ctx context.Context) ([]domain.Article, error
 			bizIds = append(bizIds, art.Id)
 		}
 
-		// 取出点赞数
+		// 取出阅读数、点赞数、收藏数
 		intrMap, err := b.intrSvc.GetByIds(ctx, "article", bizIds)
 		if err != nil {
 			return nil, err
 		}
 
 		// 计算score
-		for _, art := range arts {
-
-			score := b.scoreFunc(intrMap[art.Id].LikeCnt, art.Utime)
+		for i, board := range b.boards {
+			queue := queues[i]
 
-			// 队列达到N后
-			if queue.Size() >= b.n {
-				top := queue.Top()
-				// 当前计算的score是否比堆顶score更小
-				// 是，不放入队列
-				// 否，拿出堆顶，进行更新
-				if score < top.score {
+			for _, art := range arts {
+				// 超出该榜单的统计窗口
+				if art.Utime.Before(ddls[i]) {
 					continue
 				}
 
-				queue.Pop()
-			}
+				score := board.ScoreFunc(intrMap[art.Id], art.Utime)
+
+				// 队列达到N后
+				if queue.Size() >= board.N {
+					top := queue.Top()
+					// 当前计算的score是否比堆顶score更小
+					// 是，不放入队列
+					// 否，拿出堆顶，进行更新
+					if score < top.score {
+						continue
+					}
+
+					queue.Pop()
+				}
 
-			// 放入优先队列
-			queue.Push(Score{
-				score: score,
-				art:   art,
-			})
+				// 放入优先队列
+				queue.Push(Score{
+					score: score,
+					art:   art,
+				})
+			}
 		}
 
 		offset += len(arts)
 		// 当前这一批数据已经不满足取出的阈值
-		// 或  当前这一批数据的最后一条记录的utime已经超出了一周前
+		// 或  当前这一批数据的最后一条记录的utime已经超出了全部榜单的统计窗口
 		// 认为没有下一批符合的数据, 就直接退出
 		if len(arts) < b.batchSize || arts[len(arts)-1].Utime.Before(ddl) {
 			break
@@ -151,10 +244,15 @@ func (b *BatchRankingService) topN(ctx context.Context) ([]domain.Article, error
 	}
 
 	//将数据从小顶堆中取出
-	res := make([]domain.Article, queue.Size())
-	for i := queue.Size() - 1; i >= 0 && !queue.Empty(); i-- {
-		res[i] = queue.Top().art
-		queue.Pop()
+	res := make(map[string][]domain.Article, len(b.boards))
+	for i, board := range b.boards {
+		queue := queues[i]
+		arts := make([]domain.Article, queue.Size())
+		for j := queue.Size() - 1; j >= 0 && !queue.Empty(); j-- {
+			arts[j] = queue.Top().art
+			queue.Pop()
+		}
+		res[board.Name] = arts
 	}
 
 	return res, nil
@@ -163,11 +261,234 @@ func (b *BatchRankingService) topN(ctx context.Context) ([]domain.Article, error
 
 // @func: GetTopN
 // @date: 2023-12-30 21:45:39
-// @brief: 热榜服务-查询热榜数据
+// @brief: 热榜服务-查询指定榜单数据
 // @author: Kewin Li
 // @receiver b
 // @param ctx
+// @param name 榜单名称
+// @return []domain.Article
+// @return error
+func (b *BatchRankingService) GetTopN(ctx context.Context, name string) ([]domain.Article, error) {
+	for _, board := range b.boards {
+		if board.Name == name {
+			return b.repo.GetTopN(ctx, name)
+		}
+	}
+
+	return nil, ErrRankingNotFound
+}
+
+// @func: RefreshLocalCache
+// @date: 2024-01-16 10:20:31
+// @brief: 热榜服务-从redis刷新全部榜单的本地缓存
+// @author: Kewin Li
+// @receiver b
+// @param ctx
+// @return error
+func (b *BatchRankingService) RefreshLocalCache(ctx context.Context) error {
+	var errs []error
+	for _, board := range b.boards {
+		// 一个榜单刷新失败不影响其他榜单
+		err := b.repo.RefreshLocalCache(ctx, board.Name)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// @func: Incr
+// @date: 2024-01-21 11:35:20
+// @brief: 热榜服务-批量模式由定时任务全量计算, 忽略互动事件
+// @author: Kewin Li
+// @receiver b
+// @param ctx
+// @param artId
+// @param delta
+// @return error
+func (b *BatchRankingService) Incr(ctx context.Context, artId int64, delta domain.Interactive) error {
+	return nil
+}
+
+// IncrRankingService
+// @Description: 增量热榜, 阅读、点赞、收藏事件实时更新帖子分数, 定时任务只负责时间衰减后的重算
+type IncrRankingService struct {
+	intrSvc InteractiveService
+	artRepo repository.ArticleRepository
+
+	repo repository.RankingScoreRepository
+
+	boards []Leaderboard
+	// 全部榜单中最大的统计窗口, 超出的帖子不再跟踪
+	window time.Duration
+}
+
+func NewIncrRankingService(intrSvc InteractiveService,
+	artRepo repository.ArticleRepository,
+	repo repository.RankingScoreRepository,
+	boards []Leaderboard) RankingService {
+	var window time.Duration
+	for _, board := range boards {
+		window = max(window, board.Window)
+	}
+
+	return &IncrRankingService{
+		intrSvc: intrSvc,
+		artRepo: artRepo,
+		repo:    repo,
+		boards:  boards,
+		window:  window,
+	}
+}
+
+// @func: Incr
+// @date: 2024-01-21 11:40:12
+// @brief: 增量热榜-累加帖子互动数并更新其在各榜单的分数, 首次出现的帖子从数据库加载
+// @author: Kewin Li
+// @receiver i
+// @param ctx
+// @param artId
+// @param delta 互动数变化量
+// @return error
+func (i *IncrRankingService) Incr(ctx context.Context, artId int64, delta domain.Interactive) error {
+	item, err := i.repo.Incr(ctx, artId, delta)
+	if err == repository.ErrRankingItemNotFound {
+		var ok bool
+		item, ok, err = i.track(ctx, artId)
+		if err == nil && !ok {
+			return nil
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	return i.updateScores(ctx, []domain.RankingItem{item}, time.Now())
+}
+
+// @func: track
+// @date: 2024-01-21 11:44:36
+// @brief: 增量热榜-开始跟踪帖子, 互动数以数据库为准(已包含本次事件)
+// @author: Kewin Li
+// @receiver i
+// @param ctx
+// @param artId
+// @return domain.RankingItem
+// @return bool 帖子未发表或已超出统计窗口时不跟踪
+// @return error
+func (i *IncrRankingService) track(ctx context.Context, artId int64) (domain.RankingItem, bool, error) {
+	art, err := i.artRepo.GetPubById(ctx, artId)
+	if err != nil {
+		return domain.RankingItem{}, false, err
+	}
+
+	expiration := i.window - time.Since(art.Utime)
+	if art.Status != domain.ArticleStatusPublished || expiration <= 0 {
+		return domain.RankingItem{}, false, nil
+	}
+
+	intrs, err := i.intrSvc.GetByIds(ctx, "article", []int64{artId})
+	if err != nil {
+		return domain.RankingItem{}, false, err
+	}
+
+	item := domain.RankingItem{
+		Article:     art,
+		Interactive: intrs[artId],
+	}
+	return item, true, i.repo.Track(ctx, item, expiration)
+}
+
+// @func: updateScores
+// @date: 2024-01-21 11:48:50
+// @brief: 增量热榜-按各榜单的分数生成函数重算分数, 超出榜单统计窗口的帖子移出榜单
+// @author: Kewin Li
+// @receiver i
+// @param ctx
+// @param items
+// @param now
+// @return error
+func (i *IncrRankingService) updateScores(ctx context.Context, items []domain.RankingItem, now time.Time) error {
+	var errs []error
+	for _, board := range i.boards {
+		ddl := now.Add(-board.Window)
+		scores := make(map[int64]float64, len(items))
+		removed := make([]int64, 0)
+		for _, item := range items {
+			if item.Article.Utime.Before(ddl) {
+				removed = append(removed, item.Article.Id)
+				continue
+			}
+			scores[item.Article.Id] = board.ScoreFunc(item.Interactive, item.Article.Utime)
+		}
+
+		// 一个榜单更新失败不影响其他榜单
+		err := i.repo.SetScores(ctx, board.Name, scores, removed)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// @func: TopN
+// @date: 2024-01-21 11:52:16
+// @brief: 增量热榜-定时重算全部跟踪中帖子的分数, 使随时间衰减的分数保持正确, 并停止跟踪超出统计窗口的帖子
+// @author: Kewin Li
+// @receiver i
+// @param ctx
+// @return error
+func (i *IncrRankingService) TopN(ctx context.Context) error {
+	items, err := i.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	err = i.updateScores(ctx, items, now)
+	if err != nil {
+		return err
+	}
+
+	ddl := now.Add(-i.window)
+	expired := make([]int64, 0)
+	for _, item := range items {
+		if item.Article.Utime.Before(ddl) {
+			expired = append(expired, item.Article.Id)
+		}
+	}
+
+	return i.repo.Untrack(ctx, expired)
+}
+
+// @func: GetTopN
+// @date: 2024-01-21 11:55:40
+// @brief: 增量热榜-直接从redis有序集合查询指定榜单
+// @author: Kewin Li
+// @receiver i
+// @param ctx
+// @param name 榜单名称
+// @return []domain.Article
+// @return error
+func (i *IncrRankingService) GetTopN(ctx context.Context, name string) ([]domain.Article, error) {
+	for _, board := range i.boards {
+		if board.Name == name {
+			return i.repo.TopN(ctx, name, board.N)
+		}
+	}
+
+	return nil, ErrRankingNotFound
+}
+
+// @func: RefreshLocalCache
+// @date: 2024-01-21 11:56:28
+// @brief: 增量热榜-分数实时变化, 不使用本地缓存
+// @author: Kewin Li
+// @receiver i
+// @param ctx
 // @return error
-func (b *BatchRankingService) GetTopN(ctx context.Context) ([]domain.Article, error) {
-	return b.repo.GetTopN(ctx)
+func (i *IncrRankingService) RefreshLocalCache(ctx context.Context) error {
+	return nil
 }
@@ -2,9 +2,12 @@ package service
 
 import (
 	"context"
+	"errors"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 	"kitbook/internal/domain"
+	"kitbook/internal/repository"
+	repomocks "kitbook/internal/repository/mocks"
 	svcmocks "kitbook/internal/service/mocks"
 	"testing"
 	"time"
@@ -24,8 +27,9 @@ func TestBatchRankingService_topN(t *testing.T) {
 
 		mock func(ctrl *gomock.Controller) (InteractiveService, ArticleService)
 
-		wantErr  error
-		wantArts []domain.Article
+		wantErr         error
+		wantArts        []domain.Article
+		wantCollectArts []domain.Article
 	}{
 		{
 			name: "成功获取",
@@ -62,14 +66,14 @@ func TestBatchRankingService_topN(t *testing.T) {
 				/*查询互动数据*/
 				// 获取第一批数据
 				intrSvc.EXPECT().GetByIds(gomock.Any(), "article", []int64{1, 2}).Return(map[int64]domain.Interactive{
-					1: {LikeCnt: 1},
-					2: {LikeCnt: 2},
+					1: {LikeCnt: 1, CollectCnt: 4},
+					2: {LikeCnt: 2, CollectCnt: 3},
 				}, nil)
 
 				// 获取第二批数据
 				intrSvc.EXPECT().GetByIds(gomock.Any(), "article", []int64{3, 4}).Return(map[int64]domain.Interactive{
-					3: {LikeCnt: 3},
-					4: {LikeCnt: 4},
+					3: {LikeCnt: 3, CollectCnt: 2},
+					4: {LikeCnt: 4, CollectCnt: 1},
 				}, nil)
 
 				// 获取第三批数据
@@ -84,6 +88,10 @@ func TestBatchRankingService_topN(t *testing.T) {
 				{Id: 3, Utime: utime},
 				{Id: 2, Utime: utime},
 			},
+			wantCollectArts: []domain.Article{
+				{Id: 1, Utime: utime},
+				{Id: 2, Utime: utime},
+			},
 		},
 	}
 
@@ -100,16 +108,225 @@ func TestBatchRankingService_topN(t *testing.T) {
 				intrSvc:   intrSvc,
 				artSvc:    artSvc,
 				batchSize: batchSize,
-				scoreFunc: func(likeCnt int64, utime time.Time) float64 {
-					return float64(likeCnt)
+				boards: []Leaderboard{
+					{
+						Name:   "like",
+						Window: 7 * 24 * time.Hour,
+						N:      3,
+						ScoreFunc: func(intr domain.Interactive, utime time.Time) float64 {
+							return float64(intr.LikeCnt)
+						},
+					},
+					{
+						Name:   "collect",
+						Window: 7 * 24 * time.Hour,
+						N:      2,
+						ScoreFunc: func(intr domain.Interactive, utime time.Time) float64 {
+							return float64(intr.CollectCnt)
+						},
+					},
 				},
-				n: 3,
 			}
 
-			arts, err := svc.topN(context.Background())
+			res, err := svc.topN(context.Background())
 			assert.Equal(t, tc.wantErr, err)
-			assert.Equal(t, tc.wantArts, arts)
+			assert.Equal(t, tc.wantArts, res["like"])
+			assert.Equal(t, tc.wantCollectArts, res["collect"])
+
+		})
+	}
+}
+
+// @func: testIncrLeaderboards
+// @date: 2024-01-21 12:40:10
+// @brief: 测试增量热榜-点赞榜统计7天, 收藏榜统计14天
+// @author: Kewin Li
+// @return []Leaderboard
+func testIncrLeaderboards() []Leaderboard {
+	return []Leaderboard{
+		{
+			Name:   "like",
+			Window: 7 * 24 * time.Hour,
+			N:      3,
+			ScoreFunc: func(intr domain.Interactive, utime time.Time) float64 {
+				return float64(intr.LikeCnt)
+			},
+		},
+		{
+			Name:   "collect",
+			Window: 14 * 24 * time.Hour,
+			N:      3,
+			ScoreFunc: func(intr domain.Interactive, utime time.Time) float64 {
+				return float64(intr.CollectCnt)
+			},
+		},
+	}
+}
+
+// @func: TestIncrRankingService_Incr
+// @date: 2024-01-21 12:42:36
+// @brief: 测试增量热榜-互动事件实时更新分数
+// @author: Kewin Li
+// @param t
+func TestIncrRankingService_Incr(t *testing.T) {
+	utime := time.Now().Add(-time.Hour)
+	testCases := []struct {
+		name string
+
+		mock func(ctrl *gomock.Controller) (InteractiveService, repository.ArticleRepository, repository.RankingScoreRepository)
+
+		delta   domain.Interactive
+		wantErr error
+	}{
+		{
+			name: "已跟踪的帖子",
+			mock: func(ctrl *gomock.Controller) (InteractiveService, repository.ArticleRepository, repository.RankingScoreRepository) {
+				repo := repomocks.NewMockRankingScoreRepository(ctrl)
+				repo.EXPECT().Incr(gomock.Any(), int64(1), domain.Interactive{LikeCnt: 1}).Return(domain.RankingItem{
+					Article:     domain.Article{Id: 1, Utime: utime},
+					Interactive: domain.Interactive{LikeCnt: 3, CollectCnt: 2},
+				}, nil)
+				repo.EXPECT().SetScores(gomock.Any(), "like", map[int64]float64{1: 3}, []int64{}).Return(nil)
+				repo.EXPECT().SetScores(gomock.Any(), "collect", map[int64]float64{1: 2}, []int64{}).Return(nil)
+
+				return svcmocks.NewMockInteractiveService(ctrl), repomocks.NewMockArticleRepository(ctrl), repo
+			},
+			delta: domain.Interactive{LikeCnt: 1},
+		},
+		{
+			name: "首次出现的帖子, 互动数以数据库为准",
+			mock: func(ctrl *gomock.Controller) (InteractiveService, repository.ArticleRepository, repository.RankingScoreRepository) {
+				art := domain.Article{Id: 1, Status: domain.ArticleStatusPublished, Utime: utime}
+				intr := domain.Interactive{BizId: 1, LikeCnt: 5}
+
+				repo := repomocks.NewMockRankingScoreRepository(ctrl)
+				repo.EXPECT().Incr(gomock.Any(), int64(1), domain.Interactive{LikeCnt: 1}).Return(domain.RankingItem{}, repository.ErrRankingItemNotFound)
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().GetPubById(gomock.Any(), int64(1)).Return(art, nil)
+				intrSvc := svcmocks.NewMockInteractiveService(ctrl)
+				intrSvc.EXPECT().GetByIds(gomock.Any(), "article", []int64{1}).Return(map[int64]domain.Interactive{1: intr}, nil)
+
+				repo.EXPECT().Track(gomock.Any(), domain.RankingItem{Article: art, Interactive: intr}, gomock.Any()).Return(nil)
+				repo.EXPECT().SetScores(gomock.Any(), "like", map[int64]float64{1: 5}, []int64{}).Return(nil)
+				repo.EXPECT().SetScores(gomock.Any(), "collect", map[int64]float64{1: 0}, []int64{}).Return(nil)
+
+				return intrSvc, artRepo, repo
+			},
+			delta: domain.Interactive{LikeCnt: 1},
+		},
+		{
+			name: "超出统计窗口的帖子不跟踪",
+			mock: func(ctrl *gomock.Controller) (InteractiveService, repository.ArticleRepository, repository.RankingScoreRepository) {
+				repo := repomocks.NewMockRankingScoreRepository(ctrl)
+				repo.EXPECT().Incr(gomock.Any(), int64(1), domain.Interactive{ReadCnt: 2}).Return(domain.RankingItem{}, repository.ErrRankingItemNotFound)
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().GetPubById(gomock.Any(), int64(1)).Return(domain.Article{
+					Id:     1,
+					Status: domain.ArticleStatusPublished,
+					Utime:  time.Now().Add(-15 * 24 * time.Hour),
+				}, nil)
+
+				return svcmocks.NewMockInteractiveService(ctrl), artRepo, repo
+			},
+			delta: domain.Interactive{ReadCnt: 2},
+		},
+		{
+			name: "更新分数失败",
+			mock: func(ctrl *gomock.Controller) (InteractiveService, repository.ArticleRepository, repository.RankingScoreRepository) {
+				repo := repomocks.NewMockRankingScoreRepository(ctrl)
+				repo.EXPECT().Incr(gomock.Any(), int64(1), domain.Interactive{CollectCnt: -1}).Return(domain.RankingItem{
+					Article:     domain.Article{Id: 1, Utime: utime},
+					Interactive: domain.Interactive{LikeCnt: 3, CollectCnt: 1},
+				}, nil)
+				repo.EXPECT().SetScores(gomock.Any(), "like", map[int64]float64{1: 3}, []int64{}).Return(errors.New("模拟redis错误"))
+				repo.EXPECT().SetScores(gomock.Any(), "collect", map[int64]float64{1: 1}, []int64{}).Return(nil)
 
+				return svcmocks.NewMockInteractiveService(ctrl), repomocks.NewMockArticleRepository(ctrl), repo
+			},
+			delta:   domain.Interactive{CollectCnt: -1},
+			wantErr: errors.Join(errors.New("模拟redis错误")),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			intrSvc, artRepo, repo := tc.mock(ctrl)
+			svc := NewIncrRankingService(intrSvc, artRepo, repo, testIncrLeaderboards())
+
+			err := svc.Incr(context.Background(), 1, tc.delta)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+// @func: TestIncrRankingService_TopN
+// @date: 2024-01-21 12:50:18
+// @brief: 测试增量热榜-定时重算分数并停止跟踪超出窗口的帖子
+// @author: Kewin Li
+// @param t
+func TestIncrRankingService_TopN(t *testing.T) {
+	now := time.Now()
+	testCases := []struct {
+		name string
+
+		mock func(ctrl *gomock.Controller) repository.RankingScoreRepository
+
+		wantErr error
+	}{
+		{
+			name: "重算成功",
+			mock: func(ctrl *gomock.Controller) repository.RankingScoreRepository {
+				repo := repomocks.NewMockRankingScoreRepository(ctrl)
+				repo.EXPECT().List(gomock.Any()).Return([]domain.RankingItem{
+					{
+						Article:     domain.Article{Id: 1, Utime: now.Add(-time.Hour)},
+						Interactive: domain.Interactive{LikeCnt: 1, CollectCnt: 2},
+					},
+					{
+						// 只在收藏榜的窗口内
+						Article:     domain.Article{Id: 2, Utime: now.Add(-10 * 24 * time.Hour)},
+						Interactive: domain.Interactive{LikeCnt: 3, CollectCnt: 4},
+					},
+					{
+						// 超出全部榜单的窗口
+						Article:     domain.Article{Id: 3, Utime: now.Add(-20 * 24 * time.Hour)},
+						Interactive: domain.Interactive{LikeCnt: 5, CollectCnt: 6},
+					},
+				}, nil)
+				repo.EXPECT().SetScores(gomock.Any(), "like", map[int64]float64{1: 1}, []int64{2, 3}).Return(nil)
+				repo.EXPECT().SetScores(gomock.Any(), "collect", map[int64]float64{1: 2, 2: 4}, []int64{3}).Return(nil)
+				repo.EXPECT().Untrack(gomock.Any(), []int64{3}).Return(nil)
+
+				return repo
+			},
+		},
+		{
+			name: "查询跟踪中的帖子失败",
+			mock: func(ctrl *gomock.Controller) repository.RankingScoreRepository {
+				repo := repomocks.NewMockRankingScoreRepository(ctrl)
+				repo.EXPECT().List(gomock.Any()).Return(nil, errors.New("模拟redis错误"))
+
+				return repo
+			},
+			wantErr: errors.New("模拟redis错误"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			svc := NewIncrRankingService(svcmocks.NewMockInteractiveService(ctrl),
+				repomocks.NewMockArticleRepository(ctrl),
+				tc.mock(ctrl),
+				testIncrLeaderboards())
+
+			err := svc.TopN(context.Background())
+			assert.Equal(t, tc.wantErr, err)
 		})
 	}
 }
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"kitbook/internal/domain"
+	"kitbook/internal/repository"
+	"kitbook/pkg/logger"
+	"time"
+)
+
+// 重建索引时每批从线上库读取的帖子数
+const searchRebuildBatch = 100
+
+type SearchService interface {
+	SearchArticles(ctx context.Context, query string, offset int, limit int) (domain.SearchArticleResult, error)
+	IndexArticle(ctx context.Context, artId int64) error
+	RemoveArticle(ctx context.Context, artId int64) error
+	RebuildArticleIndex(ctx context.Context) (int, error)
+}
+
+// NormalSearchService
+// @Description: 搜索服务, 索引内容来自线上库, 由帖子发表、撤回消息增量更新
+type NormalSearchService struct {
+	repo     repository.ArticleSearchRepository
+	artRepo  repository.ArticleRepository
+	userRepo repository.UserRepository
+
+	l logger.Logger
+}
+
+func NewNormalSearchService(repo repository.ArticleSearchRepository,
+	artRepo repository.ArticleRepository,
+	userRepo repository.UserRepository,
+	l logger.Logger) SearchService {
+	return &NormalSearchService{
+		repo:     repo,
+		artRepo:  artRepo,
+		userRepo: userRepo,
+		l:        l,
+	}
+}
+
+// @func: SearchArticles
+// @date: 2024-01-23 16:10:26
+// @brief: 搜索服务-按关键词检索已发表帖子, 相关度高的在前
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param query
+// @param offset
+// @param limit
+// @return domain.SearchArticleResult
+// @return error
+func (n *NormalSearchService) SearchArticles(ctx context.Context, query string, offset int, limit int) (domain.SearchArticleResult, error) {
+	return n.repo.Search(ctx, query, offset, limit)
+}
+
+// @func: IndexArticle
+// @date: 2024-01-23 16:12:40
+// @brief: 搜索服务-按线上库最新内容更新帖子索引, 帖子不再公开时删除索引
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param artId
+// @return error
+func (n *NormalSearchService) IndexArticle(ctx context.Context, artId int64) error {
+	art, err := n.artRepo.GetPubById(ctx, artId)
+	if err != nil {
+		return err
+	}
+
+	if art.Status != domain.ArticleStatusPublished {
+		return n.repo.Delete(ctx, artId)
+	}
+
+	return n.repo.Index(ctx, n.ConvertsSearchArticle(&art))
+}
+
+// @func: RemoveArticle
+// @date: 2024-01-23 16:14:18
+// @brief: 搜索服务-删除帖子索引
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param artId
+// @return error
+func (n *NormalSearchService) RemoveArticle(ctx context.Context, artId int64) error {
+	return n.repo.Delete(ctx, artId)
+}
+
+// @func: RebuildArticleIndex
+// @date: 2024-01-23 16:18:52
+// @brief: 搜索服务-从线上库分批重建帖子索引, 重建期间索引仍可查询, 最后清理已撤回的帖子
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @return int 本次写入索引的帖子数
+// @return error
+func (n *NormalSearchService) RebuildArticleIndex(ctx context.Context) (int, error) {
+	start := time.Now()
+	keep := make(map[int64]struct{})
+	// 作者ID -> 作者名
+	names := make(map[int64]string)
+
+	for offset := 0; ; offset += searchRebuildBatch {
+		arts, err := n.artRepo.ListPub(ctx, start, offset, searchRebuildBatch)
+		if err != nil {
+			return len(keep), err
+		}
+
+		for _, art := range arts {
+			art.Author.Name = n.authorName(ctx, names, art.Author.Id)
+			err = n.repo.Index(ctx, n.ConvertsSearchArticle(&art))
+			if err != nil {
+				return len(keep), err
+			}
+			keep[art.Id] = struct{}{}
+		}
+
+		if len(arts) < searchRebuildBatch {
+			break
+		}
+	}
+
+	pruned, err := n.repo.Prune(ctx, start, keep)
+	if err != nil {
+		return len(keep), err
+	}
+
+	n.l.INFO("搜索索引重建完成",
+		logger.Int[int]("indexed", len(keep)),
+		logger.Int[int]("pruned", pruned),
+		logger.Field{"cost", time.Since(start).String()})
+
+	return len(keep), nil
+}
+
+// @func: authorName
+// @date: 2024-01-23 16:21:30
+// @brief: 搜索服务-查询作者名, 同一次重建中只查询一次, 查询失败时不索引作者名
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param names
+// @param authorId
+// @return string
+func (n *NormalSearchService) authorName(ctx context.Context, names map[int64]string, authorId int64) string {
+	name, ok := names[authorId]
+	if ok {
+		return name
+	}
+
+	user, err := n.userRepo.FindById(ctx, authorId)
+	if err != nil {
+		n.l.WARN("搜索索引查询作者失败",
+			logger.Error(err),
+			logger.Int[int64]("authorId", authorId))
+	}
+	names[authorId] = user.Nickname
+
+	return user.Nickname
+}
+
+func (n *NormalSearchService) ConvertsSearchArticle(art *domain.Article) domain.SearchArticle {
+	return domain.SearchArticle{
+		Id:         art.Id,
+		AuthorId:   art.Author.Id,
+		AuthorName: art.Author.Name,
+		Title:      art.Title,
+		// 只索引纯文本, 避免Markdown语法被检索到、出现在摘要中
+		Content: art.PlainText(),
+		Utime:   art.Utime,
+	}
+}
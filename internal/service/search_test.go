@@ -0,0 +1,175 @@
+// Package service
+// @Description: 搜索服务-单元测试
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"kitbook/internal/domain"
+	"kitbook/internal/repository"
+	repomocks "kitbook/internal/repository/mocks"
+	"kitbook/pkg/logger"
+	"testing"
+	"time"
+)
+
+// @func: TestNormalSearchService_RebuildArticleIndex
+// @date: 2024-01-23 17:20:36
+// @brief: 单元测试-从线上库重建搜索索引
+// @author: Kewin Li
+// @param t
+func TestNormalSearchService_RebuildArticleIndex(t *testing.T) {
+	utime := time.UnixMilli(1705996800000)
+
+	testCases := []struct {
+		name string
+
+		mock func(ctrl *gomock.Controller) (
+			repository.ArticleSearchRepository,
+			repository.ArticleRepository,
+			repository.UserRepository)
+
+		wantCnt int
+		wantErr error
+	}{
+		{
+			name: "重建成功, 同一作者只查询一次, 清理已撤回帖子",
+			mock: func(ctrl *gomock.Controller) (
+				repository.ArticleSearchRepository,
+				repository.ArticleRepository,
+				repository.UserRepository) {
+				repo := repomocks.NewMockArticleSearchRepository(ctrl)
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+
+				artRepo.EXPECT().ListPub(gomock.Any(), gomock.Any(), 0, searchRebuildBatch).
+					Return([]domain.Article{
+						{Id: 1, Title: "标题1", Content: "内容1", Author: domain.Author{Id: 123}, Utime: utime},
+						{Id: 2, Title: "标题2", Content: "内容2", Author: domain.Author{Id: 123}, Utime: utime},
+					}, nil)
+				userRepo.EXPECT().FindById(gomock.Any(), int64(123)).
+					Return(domain.User{Id: 123, Nickname: "作者"}, nil)
+
+				repo.EXPECT().Index(gomock.Any(), domain.SearchArticle{
+					Id: 1, AuthorId: 123, AuthorName: "作者", Title: "标题1", Content: "内容1", Utime: utime,
+				}).Return(nil)
+				repo.EXPECT().Index(gomock.Any(), domain.SearchArticle{
+					Id: 2, AuthorId: 123, AuthorName: "作者", Title: "标题2", Content: "内容2", Utime: utime,
+				}).Return(nil)
+				repo.EXPECT().Prune(gomock.Any(), gomock.Any(), map[int64]struct{}{1: {}, 2: {}}).
+					Return(1, nil)
+
+				return repo, artRepo, userRepo
+			},
+
+			wantCnt: 2,
+		},
+		{
+			name: "查询线上库失败",
+			mock: func(ctrl *gomock.Controller) (
+				repository.ArticleSearchRepository,
+				repository.ArticleRepository,
+				repository.UserRepository) {
+				repo := repomocks.NewMockArticleSearchRepository(ctrl)
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+				userRepo := repomocks.NewMockUserRepository(ctrl)
+
+				artRepo.EXPECT().ListPub(gomock.Any(), gomock.Any(), 0, searchRebuildBatch).
+					Return(nil, errors.New("模拟数据库错误"))
+
+				return repo, artRepo, userRepo
+			},
+
+			wantErr: errors.New("模拟数据库错误"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo, artRepo, userRepo := tc.mock(ctrl)
+			svc := NewNormalSearchService(repo, artRepo, userRepo, logger.NewNopLogger())
+
+			cnt, err := svc.RebuildArticleIndex(context.Background())
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantCnt, cnt)
+		})
+	}
+}
+
+// @func: TestNormalSearchService_IndexArticle
+// @date: 2024-01-23 17:25:12
+// @brief: 单元测试-按发表消息更新索引
+// @author: Kewin Li
+// @param t
+func TestNormalSearchService_IndexArticle(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		mock func(ctrl *gomock.Controller) (
+			repository.ArticleSearchRepository,
+			repository.ArticleRepository)
+
+		artId   int64
+		wantErr error
+	}{
+		{
+			name: "已发表, 更新索引",
+			mock: func(ctrl *gomock.Controller) (
+				repository.ArticleSearchRepository,
+				repository.ArticleRepository) {
+				repo := repomocks.NewMockArticleSearchRepository(ctrl)
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+
+				artRepo.EXPECT().GetPubById(gomock.Any(), int64(1)).
+					Return(domain.Article{
+						Id:     1,
+						Title:  "标题",
+						Author: domain.Author{Id: 123, Name: "作者"},
+						Status: domain.ArticleStatusPublished,
+					}, nil)
+				repo.EXPECT().Index(gomock.Any(), domain.SearchArticle{
+					Id: 1, AuthorId: 123, AuthorName: "作者", Title: "标题",
+				}).Return(nil)
+
+				return repo, artRepo
+			},
+
+			artId: 1,
+		},
+		{
+			name: "已撤回, 删除索引",
+			mock: func(ctrl *gomock.Controller) (
+				repository.ArticleSearchRepository,
+				repository.ArticleRepository) {
+				repo := repomocks.NewMockArticleSearchRepository(ctrl)
+				artRepo := repomocks.NewMockArticleRepository(ctrl)
+
+				artRepo.EXPECT().GetPubById(gomock.Any(), int64(2)).
+					Return(domain.Article{Id: 2, Status: domain.ArticleStatusPrivate}, nil)
+				repo.EXPECT().Delete(gomock.Any(), int64(2)).Return(nil)
+
+				return repo, artRepo
+			},
+
+			artId: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo, artRepo := tc.mock(ctrl)
+			svc := NewNormalSearchService(repo, artRepo, nil, logger.NewNopLogger())
+
+			err := svc.IndexArticle(context.Background(), tc.artId)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
@@ -0,0 +1,499 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"kitbook/internal/domain"
+	"kitbook/internal/repository"
+	"kitbook/pkg/logger"
+	"kitbook/pkg/storage"
+	"mime"
+	"net/http"
+	"time"
+)
+
+var (
+	ErrUploadNotFound       = repository.ErrUploadNotFound
+	ErrUploadTooLarge       = errors.New("文件超过大小限制")
+	ErrUploadTypeNotAllowed = errors.New("不支持的文件类型")
+	ErrUploadQuotaExceeded  = errors.New("上传空间不足")
+	ErrUploadMismatch       = errors.New("上传内容与声明不一致")
+	ErrUploadNotUploaded    = errors.New("文件尚未上传")
+)
+
+// 清理孤儿文件时每批处理的记录数
+const uploadCleanBatch = 100
+
+// 允许上传的类型, 以内容检测结果为准, 不信任客户端声明和扩展名
+var uploadKinds = map[string]domain.UploadKind{
+	"image/png":          domain.UploadKindImage,
+	"image/jpeg":         domain.UploadKindImage,
+	"image/gif":          domain.UploadKindImage,
+	"image/webp":         domain.UploadKindImage,
+	"application/pdf":    domain.UploadKindAttachment,
+	"application/zip":    domain.UploadKindAttachment,
+	"application/x-gzip": domain.UploadKindAttachment,
+	"text/plain":         domain.UploadKindAttachment,
+}
+
+// UploadLimits
+// @Description: 上传限制及地址有效期
+type UploadLimits struct {
+	MaxImageSize      int64
+	MaxAttachmentSize int64
+	// 每个用户的总空间
+	UserQuota int64
+	// 预签名上传、下载地址有效期
+	PresignExpire  time.Duration
+	DownloadExpire time.Duration
+	// 上传后超过该时长仍未被任何帖子引用的文件会被清理
+	OrphanGrace time.Duration
+}
+
+// @func: DefaultUploadLimits
+// @date: 2024-01-24 15:30:12
+// @brief: 上传-默认限制: 图片10MB、附件20MB、每人1GB
+// @author: Kewin Li
+// @return UploadLimits
+func DefaultUploadLimits() UploadLimits {
+	return UploadLimits{
+		MaxImageSize:      10 << 20,
+		MaxAttachmentSize: 20 << 20,
+		UserQuota:         1 << 30,
+		PresignExpire:     15 * time.Minute,
+		DownloadExpire:    10 * time.Minute,
+		OrphanGrace:       24 * time.Hour,
+	}
+}
+
+// @func: maxSize
+// @date: 2024-01-24 15:31:26
+// @brief: 上传-某类文件的大小上限
+// @author: Kewin Li
+// @receiver u
+// @param kind
+// @return int64
+func (u UploadLimits) maxSize(kind domain.UploadKind) int64 {
+	if kind == domain.UploadKindImage {
+		return u.MaxImageSize
+	}
+	return u.MaxAttachmentSize
+}
+
+type UploadService interface {
+	Upload(ctx context.Context, userId int64, filename string, body io.Reader, size int64) (domain.Upload, error)
+	Presign(ctx context.Context, u domain.Upload) (domain.UploadPresign, error)
+	Confirm(ctx context.Context, id int64, userId int64) (domain.Upload, error)
+	DownloadURL(ctx context.Context, hash string) (string, error)
+	CleanOrphans(ctx context.Context, now time.Time) (int, error)
+}
+
+// NormalUploadService
+// @Description: 图片、附件上传, 相同内容只存储一份; 支持服务端中转上传和客户端直传对象存储两种方式
+type NormalUploadService struct {
+	repo   repository.UploadRepository
+	store  storage.Storage
+	limits UploadLimits
+
+	l logger.Logger
+}
+
+func NewNormalUploadService(repo repository.UploadRepository,
+	store storage.Storage,
+	limits UploadLimits,
+	l logger.Logger) UploadService {
+	return &NormalUploadService{
+		repo:   repo,
+		store:  store,
+		limits: limits,
+		l:      l,
+	}
+}
+
+// @func: Upload
+// @date: 2024-01-24 15:33:40
+// @brief: 上传-服务端中转上传, 检测类型、大小、配额, 对象存储中已有相同内容时不再重复存储
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param userId
+// @param filename
+// @param body
+// @param size 客户端声明的大小, 以实际读到的为准
+// @return domain.Upload
+// @return error
+func (n *NormalUploadService) Upload(ctx context.Context, userId int64, filename string, body io.Reader, size int64) (domain.Upload, error) {
+	maxSize := max(n.limits.MaxImageSize, n.limits.MaxAttachmentSize)
+	if size > maxSize {
+		return domain.Upload{}, ErrUploadTooLarge
+	}
+
+	// 多读1字节用于判断是否超限
+	data, err := io.ReadAll(io.LimitReader(body, maxSize+1))
+	if err != nil {
+		return domain.Upload{}, err
+	}
+
+	contentType, kind, err := n.sniff(data, int64(len(data)))
+	if err != nil {
+		return domain.Upload{}, err
+	}
+
+	sum := sha256.Sum256(data)
+	u := domain.Upload{
+		UserId:      userId,
+		Hash:        hex.EncodeToString(sum[:]),
+		Size:        int64(len(data)),
+		ContentType: contentType,
+		Filename:    filename,
+		Kind:        kind,
+		Status:      domain.UploadStatusReady,
+	}
+
+	existing, err := n.checkQuota(ctx, u)
+	if err != nil {
+		return domain.Upload{}, err
+	}
+	if existing.Status == domain.UploadStatusReady {
+		return existing, nil
+	}
+
+	// 其他用户已上传过相同内容, 不再重复存储
+	_, err = n.repo.FindReadyByHash(ctx, u.Hash)
+	switch err {
+	case nil:
+	case repository.ErrUploadNotFound:
+		err = n.store.Put(ctx, objectKey(u.Hash), bytes.NewReader(data), u.Size, contentType)
+		if err != nil {
+			return domain.Upload{}, err
+		}
+	default:
+		return domain.Upload{}, err
+	}
+
+	// 之前签发过上传地址但未确认, 直接转为就绪
+	if existing.Id > 0 {
+		err = n.repo.MarkReady(ctx, existing.Id, contentType)
+		existing.Status, existing.ContentType = domain.UploadStatusReady, contentType
+		return existing, err
+	}
+
+	return n.repo.Create(ctx, u)
+}
+
+// @func: Presign
+// @date: 2024-01-24 15:38:52
+// @brief: 上传-签发客户端直传地址, 内容已存在时直接就绪(秒传)
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param u 客户端声明的用户、哈希、大小、类型、文件名
+// @return domain.UploadPresign
+// @return error
+func (n *NormalUploadService) Presign(ctx context.Context, u domain.Upload) (domain.UploadPresign, error) {
+	sum, err := hex.DecodeString(u.Hash)
+	if err != nil || len(sum) != sha256.Size {
+		return domain.UploadPresign{}, ErrUploadMismatch
+	}
+
+	mediaType, _, err := mime.ParseMediaType(u.ContentType)
+	if err != nil {
+		return domain.UploadPresign{}, ErrUploadTypeNotAllowed
+	}
+	kind, ok := uploadKinds[mediaType]
+	if !ok {
+		return domain.UploadPresign{}, ErrUploadTypeNotAllowed
+	}
+	if u.Size <= 0 || u.Size > n.limits.maxSize(kind) {
+		return domain.UploadPresign{}, ErrUploadTooLarge
+	}
+	u.ContentType = mediaType
+	u.Kind = kind
+	u.Status = domain.UploadStatusPending
+
+	existing, err := n.checkQuota(ctx, u)
+	if err != nil {
+		return domain.UploadPresign{}, err
+	}
+	if existing.Status == domain.UploadStatusReady {
+		return domain.UploadPresign{Upload: existing}, nil
+	}
+
+	// 对象存储中已有该内容
+	ready, err := n.repo.FindReadyByHash(ctx, u.Hash)
+	switch err {
+	case nil:
+		if ready.Size != u.Size {
+			return domain.UploadPresign{}, ErrUploadMismatch
+		}
+		u.Status = domain.UploadStatusReady
+		u.ContentType = ready.ContentType
+		u.Kind = ready.Kind
+		if existing.Id > 0 {
+			err = n.repo.MarkReady(ctx, existing.Id, ready.ContentType)
+			existing.Status, existing.ContentType = domain.UploadStatusReady, ready.ContentType
+			return domain.UploadPresign{Upload: existing}, err
+		}
+		u, err = n.repo.Create(ctx, u)
+		return domain.UploadPresign{Upload: u}, err
+	case repository.ErrUploadNotFound:
+	default:
+		return domain.UploadPresign{}, err
+	}
+
+	if existing.Id > 0 {
+		u = existing
+	} else {
+		u, err = n.repo.Create(ctx, u)
+		if err != nil {
+			return domain.UploadPresign{}, err
+		}
+	}
+
+	req, err := n.store.PresignPut(ctx, objectKey(u.Hash), u.Size, u.ContentType, sum, n.limits.PresignExpire)
+	if err != nil {
+		return domain.UploadPresign{}, err
+	}
+
+	return domain.UploadPresign{
+		Upload:   u,
+		Method:   req.Method,
+		URL:      req.URL,
+		Headers:  req.Headers,
+		ExpireAt: req.ExpireAt,
+	}, nil
+}
+
+// @func: Confirm
+// @date: 2024-01-24 15:44:16
+// @brief: 上传-客户端直传完成后确认, 重新校验大小、哈希和实际类型
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param id
+// @param userId
+// @return domain.Upload
+// @return error
+func (n *NormalUploadService) Confirm(ctx context.Context, id int64, userId int64) (domain.Upload, error) {
+	u, err := n.repo.FindById(ctx, id, userId)
+	if err != nil {
+		return domain.Upload{}, err
+	}
+	if u.Status == domain.UploadStatusReady {
+		return u, nil
+	}
+
+	key := objectKey(u.Hash)
+	rc, err := n.store.Get(ctx, key)
+	if err == storage.ErrObjectNotFound {
+		return domain.Upload{}, ErrUploadNotUploaded
+	}
+	if err != nil {
+		return domain.Upload{}, err
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rc, u.Size+1))
+	if err != nil {
+		return domain.Upload{}, err
+	}
+
+	sum := sha256.Sum256(data)
+	contentType, kind, err := n.sniff(data, u.Size)
+	if err != nil || int64(len(data)) != u.Size || hex.EncodeToString(sum[:]) != u.Hash || kind != u.Kind {
+		n.discard(ctx, u)
+		if err == nil {
+			err = ErrUploadMismatch
+		}
+		return domain.Upload{}, err
+	}
+
+	err = n.repo.MarkReady(ctx, u.Id, contentType)
+	u.Status, u.ContentType = domain.UploadStatusReady, contentType
+
+	return u, err
+}
+
+// @func: DownloadURL
+// @date: 2024-01-24 15:48:30
+// @brief: 上传-签发有时效的下载地址
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param hash
+// @return string
+// @return error
+func (n *NormalUploadService) DownloadURL(ctx context.Context, hash string) (string, error) {
+	u, err := n.repo.FindReadyByHash(ctx, hash)
+	if err != nil {
+		return "", err
+	}
+
+	return n.store.PresignGet(ctx, objectKey(u.Hash), n.limits.DownloadExpire)
+}
+
+// @func: CleanOrphans
+// @date: 2024-01-24 15:52:06
+// @brief: 上传-清理超过宽限期仍未确认、或不再被作者任何帖子引用的文件, 没有记录引用的对象一并删除
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param now
+// @return int 清理的记录数
+// @return error
+func (n *NormalUploadService) CleanOrphans(ctx context.Context, now time.Time) (int, error) {
+	before := now.Add(-n.limits.OrphanGrace)
+	cnt := 0
+
+	for afterId := int64(0); ; {
+		us, err := n.repo.ListStale(ctx, before, afterId, uploadCleanBatch)
+		if err != nil {
+			return cnt, err
+		}
+
+		for _, u := range us {
+			afterId = u.Id
+
+			if u.Status == domain.UploadStatusReady {
+				referenced, err := n.repo.IsReferenced(ctx, u.UserId, u.Hash)
+				if err != nil {
+					return cnt, err
+				}
+				if referenced {
+					continue
+				}
+			}
+
+			err = n.repo.Delete(ctx, u.Id)
+			if err != nil {
+				return cnt, err
+			}
+			cnt++
+			n.deleteObject(ctx, u.Hash)
+		}
+
+		if len(us) < uploadCleanBatch {
+			break
+		}
+	}
+
+	n.l.INFO("孤儿文件清理完成", logger.Int[int]("cnt", cnt))
+	return cnt, nil
+}
+
+// @func: checkQuota
+// @date: 2024-01-24 15:55:20
+// @brief: 上传-检查用户配额, 用户已上传过相同内容时不重复计算并返回已有记录
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param u
+// @return domain.Upload 已有记录, 不存在时为零值
+// @return error
+func (n *NormalUploadService) checkQuota(ctx context.Context, u domain.Upload) (domain.Upload, error) {
+	existing, err := n.repo.FindByUserHash(ctx, u.UserId, u.Hash)
+	switch err {
+	case nil:
+		if existing.Size != u.Size {
+			return domain.Upload{}, ErrUploadMismatch
+		}
+		return existing, nil
+	case repository.ErrUploadNotFound:
+	default:
+		return domain.Upload{}, err
+	}
+
+	used, err := n.repo.UsedQuota(ctx, u.UserId)
+	if err != nil {
+		return domain.Upload{}, err
+	}
+	if used+u.Size > n.limits.UserQuota {
+		return domain.Upload{}, ErrUploadQuotaExceeded
+	}
+
+	return domain.Upload{}, nil
+}
+
+// @func: sniff
+// @date: 2024-01-24 15:57:42
+// @brief: 上传-按内容检测类型, 并校验该类文件的大小上限
+// @author: Kewin Li
+// @receiver n
+// @param data
+// @param size
+// @return string
+// @return domain.UploadKind
+// @return error
+func (n *NormalUploadService) sniff(data []byte, size int64) (string, domain.UploadKind, error) {
+	if len(data) == 0 {
+		return "", domain.UploadKindUnknown, ErrUploadTypeNotAllowed
+	}
+
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(data))
+	if err != nil {
+		return "", domain.UploadKindUnknown, ErrUploadTypeNotAllowed
+	}
+	kind, ok := uploadKinds[mediaType]
+	if !ok {
+		return "", domain.UploadKindUnknown, ErrUploadTypeNotAllowed
+	}
+	if size > n.limits.maxSize(kind) {
+		return "", domain.UploadKindUnknown, ErrUploadTooLarge
+	}
+
+	return mediaType, kind, nil
+}
+
+// @func: discard
+// @date: 2024-01-24 15:59:06
+// @brief: 上传-直传内容校验失败, 删除记录, 没有其他就绪记录时删除对象
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param u
+func (n *NormalUploadService) discard(ctx context.Context, u domain.Upload) {
+	err := n.repo.Delete(ctx, u.Id)
+	if err != nil {
+		n.l.WARN("上传校验失败, 删除记录失败", logger.Error(err), logger.Int[int64]("id", u.Id))
+		return
+	}
+
+	_, err = n.repo.FindReadyByHash(ctx, u.Hash)
+	if err == repository.ErrUploadNotFound {
+		err = n.store.Delete(ctx, objectKey(u.Hash))
+	}
+	if err != nil {
+		n.l.WARN("上传校验失败, 删除对象失败", logger.Error(err), logger.Field{Key: "hash", Val: u.Hash})
+	}
+}
+
+// @func: deleteObject
+// @date: 2024-01-24 16:00:30
+// @brief: 上传-没有任何记录引用时删除对象
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+// @param hash
+func (n *NormalUploadService) deleteObject(ctx context.Context, hash string) {
+	cnt, err := n.repo.CountByHash(ctx, hash)
+	if err == nil && cnt == 0 {
+		err = n.store.Delete(ctx, objectKey(hash))
+	}
+	if err != nil {
+		n.l.WARN("孤儿文件对象删除失败", logger.Error(err), logger.Field{Key: "hash", Val: hash})
+	}
+}
+
+// @func: objectKey
+// @date: 2024-01-24 16:01:42
+// @brief: 上传-对象存储key, 按哈希前两位分目录
+// @author: Kewin Li
+// @param hash
+// @return string
+func objectKey(hash string) string {
+	return "files/" + hash[:2] + "/" + hash
+}
@@ -0,0 +1,309 @@
+// Package service
+// @Description: 上传服务-单元测试
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"io"
+	"kitbook/internal/domain"
+	"kitbook/internal/repository"
+	repomocks "kitbook/internal/repository/mocks"
+	"kitbook/pkg/logger"
+	"kitbook/pkg/storage"
+	"testing"
+	"time"
+)
+
+// 最小的PNG文件头, 足以被识别为 image/png
+var testPNG = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func testHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// @func: TestNormalUploadService_Upload
+// @date: 2024-01-24 16:45:20
+// @brief: 单元测试-服务端中转上传
+// @author: Kewin Li
+// @param t
+func TestNormalUploadService_Upload(t *testing.T) {
+	hash := testHash(testPNG)
+	size := int64(len(testPNG))
+
+	testCases := []struct {
+		name string
+
+		mock func(ctrl *gomock.Controller) repository.UploadRepository
+		body []byte
+
+		wantUpload domain.Upload
+		// 对象存储中是否应有该内容
+		wantStored bool
+		wantErr    error
+	}{
+		{
+			name: "新内容, 写入存储并新增记录",
+			mock: func(ctrl *gomock.Controller) repository.UploadRepository {
+				repo := repomocks.NewMockUploadRepository(ctrl)
+				repo.EXPECT().FindByUserHash(gomock.Any(), int64(123), hash).
+					Return(domain.Upload{}, repository.ErrUploadNotFound)
+				repo.EXPECT().UsedQuota(gomock.Any(), int64(123)).Return(int64(0), nil)
+				repo.EXPECT().FindReadyByHash(gomock.Any(), hash).
+					Return(domain.Upload{}, repository.ErrUploadNotFound)
+				repo.EXPECT().Create(gomock.Any(), domain.Upload{
+					UserId:      123,
+					Hash:        hash,
+					Size:        size,
+					ContentType: "image/png",
+					Filename:    "a.png",
+					Kind:        domain.UploadKindImage,
+					Status:      domain.UploadStatusReady,
+				}).DoAndReturn(func(ctx context.Context, u domain.Upload) (domain.Upload, error) {
+					u.Id = 1
+					return u, nil
+				})
+				return repo
+			},
+			body: testPNG,
+
+			wantUpload: domain.Upload{
+				Id:          1,
+				UserId:      123,
+				Hash:        hash,
+				Size:        size,
+				ContentType: "image/png",
+				Filename:    "a.png",
+				Kind:        domain.UploadKindImage,
+				Status:      domain.UploadStatusReady,
+			},
+			wantStored: true,
+		},
+		{
+			name: "其他用户已上传过, 不重复存储",
+			mock: func(ctrl *gomock.Controller) repository.UploadRepository {
+				repo := repomocks.NewMockUploadRepository(ctrl)
+				repo.EXPECT().FindByUserHash(gomock.Any(), int64(123), hash).
+					Return(domain.Upload{}, repository.ErrUploadNotFound)
+				repo.EXPECT().UsedQuota(gomock.Any(), int64(123)).Return(int64(0), nil)
+				repo.EXPECT().FindReadyByHash(gomock.Any(), hash).
+					Return(domain.Upload{Id: 1, UserId: 456, Hash: hash, Size: size}, nil)
+				repo.EXPECT().Create(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, u domain.Upload) (domain.Upload, error) {
+						u.Id = 2
+						return u, nil
+					})
+				return repo
+			},
+			body: testPNG,
+
+			wantUpload: domain.Upload{
+				Id:          2,
+				UserId:      123,
+				Hash:        hash,
+				Size:        size,
+				ContentType: "image/png",
+				Filename:    "a.png",
+				Kind:        domain.UploadKindImage,
+				Status:      domain.UploadStatusReady,
+			},
+		},
+		{
+			name: "用户已上传过, 返回已有记录",
+			mock: func(ctrl *gomock.Controller) repository.UploadRepository {
+				repo := repomocks.NewMockUploadRepository(ctrl)
+				repo.EXPECT().FindByUserHash(gomock.Any(), int64(123), hash).
+					Return(domain.Upload{Id: 1, UserId: 123, Hash: hash, Size: size,
+						Status: domain.UploadStatusReady}, nil)
+				return repo
+			},
+			body: testPNG,
+
+			wantUpload: domain.Upload{Id: 1, UserId: 123, Hash: hash, Size: size,
+				Status: domain.UploadStatusReady},
+		},
+		{
+			name: "类型不允许, 以内容为准",
+			mock: func(ctrl *gomock.Controller) repository.UploadRepository {
+				return repomocks.NewMockUploadRepository(ctrl)
+			},
+			body: []byte("<html><script>alert(1)</script></html>"),
+
+			wantErr: ErrUploadTypeNotAllowed,
+		},
+		{
+			name: "超出用户配额",
+			mock: func(ctrl *gomock.Controller) repository.UploadRepository {
+				repo := repomocks.NewMockUploadRepository(ctrl)
+				repo.EXPECT().FindByUserHash(gomock.Any(), int64(123), hash).
+					Return(domain.Upload{}, repository.ErrUploadNotFound)
+				repo.EXPECT().UsedQuota(gomock.Any(), int64(123)).Return(int64(1<<30), nil)
+				return repo
+			},
+			body: testPNG,
+
+			wantErr: ErrUploadQuotaExceeded,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := storage.NewLocalStorage(t.TempDir(), "http://localhost/storage", []byte("secret"))
+			svc := NewNormalUploadService(tc.mock(ctrl), store, DefaultUploadLimits(), logger.NewNopLogger())
+
+			u, err := svc.Upload(context.Background(), 123, "a.png", bytes.NewReader(tc.body), int64(len(tc.body)))
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantUpload, u)
+
+			_, err = store.Stat(context.Background(), objectKey(hash))
+			assert.Equal(t, tc.wantStored, err == nil)
+		})
+	}
+}
+
+// @func: TestNormalUploadService_Confirm
+// @date: 2024-01-24 16:50:36
+// @brief: 单元测试-客户端直传后确认
+// @author: Kewin Li
+// @param t
+func TestNormalUploadService_Confirm(t *testing.T) {
+	hash := testHash(testPNG)
+	size := int64(len(testPNG))
+	pending := domain.Upload{Id: 1, UserId: 123, Hash: hash, Size: size,
+		ContentType: "image/png", Kind: domain.UploadKindImage, Status: domain.UploadStatusPending}
+
+	testCases := []struct {
+		name string
+
+		mock func(ctrl *gomock.Controller) repository.UploadRepository
+		// 客户端实际上传的内容, 为空表示未上传
+		stored []byte
+
+		wantStatus domain.UploadStatus
+		// 确认后对象是否仍在存储中
+		wantStored bool
+		wantErr    error
+	}{
+		{
+			name: "校验通过",
+			mock: func(ctrl *gomock.Controller) repository.UploadRepository {
+				repo := repomocks.NewMockUploadRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(1), int64(123)).Return(pending, nil)
+				repo.EXPECT().MarkReady(gomock.Any(), int64(1), "image/png").Return(nil)
+				return repo
+			},
+			stored: testPNG,
+
+			wantStatus: domain.UploadStatusReady,
+			wantStored: true,
+		},
+		{
+			name: "尚未上传",
+			mock: func(ctrl *gomock.Controller) repository.UploadRepository {
+				repo := repomocks.NewMockUploadRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(1), int64(123)).Return(pending, nil)
+				return repo
+			},
+
+			wantErr: ErrUploadNotUploaded,
+		},
+		{
+			name: "内容与声明不一致, 删除记录和对象",
+			mock: func(ctrl *gomock.Controller) repository.UploadRepository {
+				repo := repomocks.NewMockUploadRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(1), int64(123)).Return(pending, nil)
+				repo.EXPECT().Delete(gomock.Any(), int64(1)).Return(nil)
+				repo.EXPECT().FindReadyByHash(gomock.Any(), hash).
+					Return(domain.Upload{}, repository.ErrUploadNotFound)
+				return repo
+			},
+			// 大小相同但内容不同
+			stored: append([]byte("GIF89a"), testPNG[6:]...),
+
+			wantErr: ErrUploadMismatch,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := storage.NewLocalStorage(t.TempDir(), "http://localhost/storage", []byte("secret"))
+			if tc.stored != nil {
+				err := store.Put(context.Background(), objectKey(hash), bytes.NewReader(tc.stored),
+					int64(len(tc.stored)), "")
+				require.NoError(t, err)
+			}
+			svc := NewNormalUploadService(tc.mock(ctrl), store, DefaultUploadLimits(), logger.NewNopLogger())
+
+			u, err := svc.Confirm(context.Background(), 1, 123)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantStatus, u.Status)
+
+			rc, err := store.Get(context.Background(), objectKey(hash))
+			assert.Equal(t, tc.wantStored, err == nil)
+			if err == nil {
+				data, _ := io.ReadAll(rc)
+				rc.Close()
+				assert.Equal(t, testPNG, data)
+			}
+		})
+	}
+}
+
+// @func: TestNormalUploadService_CleanOrphans
+// @date: 2024-01-24 16:55:12
+// @brief: 单元测试-清理孤儿文件
+// @author: Kewin Li
+// @param t
+func TestNormalUploadService_CleanOrphans(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.UnixMilli(1706083200000)
+	limits := DefaultUploadLimits()
+	hashA, hashB, hashC := testHash([]byte("a")), testHash([]byte("b")), testHash([]byte("c"))
+
+	store := storage.NewLocalStorage(t.TempDir(), "http://localhost/storage", []byte("secret"))
+	for _, hash := range []string{hashA, hashB, hashC} {
+		err := store.Put(context.Background(), objectKey(hash), bytes.NewReader([]byte("x")), 1, "")
+		require.NoError(t, err)
+	}
+
+	repo := repomocks.NewMockUploadRepository(ctrl)
+	repo.EXPECT().ListStale(gomock.Any(), now.Add(-limits.OrphanGrace), int64(0), uploadCleanBatch).
+		Return([]domain.Upload{
+			// 仍被引用, 保留
+			{Id: 1, UserId: 123, Hash: hashA, Status: domain.UploadStatusReady},
+			// 不再被引用, 但其他用户还有记录, 只删记录
+			{Id: 2, UserId: 123, Hash: hashB, Status: domain.UploadStatusReady},
+			// 从未确认, 删记录和对象
+			{Id: 3, UserId: 123, Hash: hashC, Status: domain.UploadStatusPending},
+		}, nil)
+	repo.EXPECT().IsReferenced(gomock.Any(), int64(123), hashA).Return(true, nil)
+	repo.EXPECT().IsReferenced(gomock.Any(), int64(123), hashB).Return(false, nil)
+	repo.EXPECT().Delete(gomock.Any(), int64(2)).Return(nil)
+	repo.EXPECT().CountByHash(gomock.Any(), hashB).Return(int64(1), nil)
+	repo.EXPECT().Delete(gomock.Any(), int64(3)).Return(nil)
+	repo.EXPECT().CountByHash(gomock.Any(), hashC).Return(int64(0), nil)
+
+	svc := NewNormalUploadService(repo, store, limits, logger.NewNopLogger())
+	cnt, err := svc.CleanOrphans(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, 2, cnt)
+
+	for hash, want := range map[string]bool{hashA: true, hashB: true, hashC: false} {
+		_, err = store.Stat(context.Background(), objectKey(hash))
+		assert.Equal(t, want, err == nil)
+	}
+}
@@ -9,9 +9,29 @@ import (
 	"kitbook/internal/service"
 	ijwt "kitbook/internal/web/jwt"
 	"kitbook/pkg/logger"
+	"kitbook/pkg/markdown"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
+)
+
+const (
+	// 单篇帖子最多标签数
+	articleTagMaxCnt = 5
+	// 标签名称最大长度
+	articleTagMaxLen = 20
+	// 分类名称最大长度
+	articleCategoryMaxLen = 32
+	// 按标签查询单页最大条数
+	articleTagMaxLimit = 100
+	// 标签补全最大条数
+	articleTagSuggestMaxLimit = 20
+	// 历史版本单页最大条数
+	articleRevisionMaxLimit = 100
+	// 帖子已被修改(版本号过期)的响应码
+	articleVersionConflictCode = 409
 )
 
 type ArticleHandler struct {
@@ -36,6 +56,7 @@ func NewArticleHandler(svc service.ArticleService,
 func (a *ArticleHandler) RegisterRoutes(server *gin.Engine) {
 	group := server.Group("/articles")
 	group.POST("/edit", a.Edit)         // 编辑帖子
+	group.POST("/autosave", a.Autosave) // 自动保存草稿
 	group.POST("/publish", a.Publish)   // 发表帖子
 	group.POST("/withdraw", a.Withdraw) // 撤回帖子(更改可见状态)
 
@@ -45,12 +66,31 @@ func (a *ArticleHandler) RegisterRoutes(server *gin.Engine) {
 	//group.GET("/list", a.List) // 创作列表
 	// 查询参数放在Body中
 	group.POST("/list", a.List)
+	// 标签补全 /tags?prefix=?&limit=?
+	group.GET("/tags", a.Tags)
+
+	// 历史版本 /revisions/:id?offset=?&limit=?
+	group.GET("/revisions/:id", a.Revisions)
+	// 版本比较 /revisions/:id/diff?from=?&to=?
+	group.GET("/revisions/:id/diff", a.Diff)
+	// 回滚到历史版本, 恢复为草稿
+	group.POST("/revisions/rollback", a.Rollback)
+
+	// 定时发表
+	group.POST("/schedule", a.Schedule)
+	group.POST("/schedule/cancel", a.CancelSchedule)
+	group.GET("/schedule/:id", a.GetSchedule)
+
+	// 最近一次发表的审核结论及原因
+	group.GET("/review/:id", a.GetReview)
 
 	// 分第二个层次
 	pub := group.Group("/pub")
 
 	// 读者接口
 	pub.GET("/:id", a.PubDetail) // 内嵌阅读数接口
+	// 按标签查询 /tag/:tag?offset=?&limit=?
+	pub.GET("/tag/:tag", a.PubListByTag)
 
 	// 点赞接口
 	// 传入参数, true=点赞, false=取消点赞
@@ -70,14 +110,21 @@ func (a *ArticleHandler) RegisterRoutes(server *gin.Engine) {
 // @接收文章内容输入，返回文章的ID
 func (a *ArticleHandler) Edit(ctx *gin.Context) {
 	type Req struct {
-		Id      int64  `json:"id"`
-		Title   string `json:"title"`
-		Content string `json:"content"`
+		Id       int64    `json:"id"`
+		Title    string   `json:"title"`
+		Content  string   `json:"content"`
+		Category string   `json:"category"`
+		Tags     []string `json:"tags"`
+		// 编辑时所基于的版本号
+		Version int64 `json:"version"`
 	}
 
 	var req Req
 	var err error
 	var artId int64
+	var version int64
+	var tags []string
+	var ok bool
 	logKey := logger.ArticleLogMsgKey[logger.LOG_ART_EDIT]
 	claims := ijwt.UserClaims{}
 	fileds := logger.Fields{}
@@ -96,14 +143,26 @@ func (a *ArticleHandler) Edit(ctx *gin.Context) {
 	// 作者Id通过jwt来解析
 	claims = ctx.MustGet("user_token").(ijwt.UserClaims)
 
+	tags, ok = normalizeTags(req.Tags)
+	req.Category = strings.TrimSpace(req.Category)
+	if !ok || utf8.RuneCountInString(req.Category) > articleCategoryMaxLen {
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "标签或分类不合法",
+		})
+		return
+	}
+
 	// 保存
-	artId, err = a.svc.Save(ctx, domain.Article{
-		Id:      req.Id,
-		Title:   req.Title,
-		Content: req.Content,
+	artId, version, err = a.svc.Save(ctx, domain.Article{
+		Id:       req.Id,
+		Title:    req.Title,
+		Content:  req.Content,
+		Category: req.Category,
+		Tags:     tags,
 		Author: domain.Author{
 			Id: claims.UserID,
 		},
+		Version: req.Version,
 	})
 
 	switch err {
@@ -115,10 +174,16 @@ func (a *ArticleHandler) Edit(ctx *gin.Context) {
 				Add(logger.Int[int64]("userId", claims.UserID))...)
 
 		ctx.JSON(http.StatusOK, Result{
-			Msg:  "保存成功",
-			Data: artId,
+			Msg: "保存成功",
+			Data: DraftVo{
+				Id:      artId,
+				Version: version,
+			},
 		})
 
+		return
+	case service.ErrVersionConflict:
+		a.versionConflict(ctx, req.Id)
 		return
 	case service.ErrInvalidUpdate:
 		ctx.JSON(http.StatusOK, Result{
@@ -139,6 +204,141 @@ ERR:
 	return
 }
 
+// @func: Autosave
+// @date: 2024-01-23 11:05:18
+// @brief: 帖子模块-自动保存草稿, 不影响已发表的内容
+// @author: Kewin Li
+// @receiver a
+// @param ctx
+func (a *ArticleHandler) Autosave(ctx *gin.Context) {
+	type AutosaveReq struct {
+		Id       int64    `json:"id"`
+		Title    string   `json:"title"`
+		Content  string   `json:"content"`
+		Category string   `json:"category"`
+		Tags     []string `json:"tags"`
+		Version  int64    `json:"version"`
+	}
+
+	var req AutosaveReq
+	var err error
+	var artId int64
+	var version int64
+	var tags []string
+	var ok bool
+	var claims ijwt.UserClaims
+	logKey := logger.ArticleLogMsgKey[logger.LOG_ART_AUTOSAVE]
+	fields := logger.Fields{}
+
+	err = ctx.Bind(&req)
+	if err != nil {
+		fields = fields.Add(logger.String("请求解析错误"))
+		goto ERR
+	}
+
+	claims = ctx.MustGet("user_token").(ijwt.UserClaims)
+
+	tags, ok = normalizeTags(req.Tags)
+	req.Category = strings.TrimSpace(req.Category)
+	if !ok || utf8.RuneCountInString(req.Category) > articleCategoryMaxLen {
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "标签或分类不合法",
+		})
+		return
+	}
+
+	artId, version, err = a.svc.Autosave(ctx, domain.Article{
+		Id:       req.Id,
+		Title:    req.Title,
+		Content:  req.Content,
+		Category: req.Category,
+		Tags:     tags,
+		Author: domain.Author{
+			Id: claims.UserID,
+		},
+		Version: req.Version,
+	})
+
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "自动保存成功",
+			Data: DraftVo{
+				Id:      artId,
+				Version: version,
+			},
+		})
+		return
+	case service.ErrVersionConflict:
+		a.versionConflict(ctx, req.Id)
+		return
+	case service.ErrInvalidUpdate:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "非法操作",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "自动保存失败",
+		})
+	}
+
+ERR:
+	a.l.ERROR(logKey,
+		fields.Add(logger.Error(err)).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Int[int64]("artId", req.Id)).
+			Add(logger.Int[int64]("userId", claims.UserID))...)
+	return
+}
+
+// @func: versionConflict
+// @date: 2024-01-23 11:10:42
+// @brief: 帖子模块-响应版本冲突, 携带制作库最新版本号供前端合并后重新提交
+// @author: Kewin Li
+// @receiver a
+// @param ctx
+// @param artId
+func (a *ArticleHandler) versionConflict(ctx *gin.Context, artId int64) {
+	vo := DraftVo{
+		Id: artId,
+	}
+
+	art, err := a.svc.GetById(ctx, artId)
+	if err == nil {
+		vo.Version = art.Version
+	}
+
+	ctx.JSON(http.StatusOK, Result{
+		Code: articleVersionConflictCode,
+		Msg:  "帖子已被修改, 请刷新后重试",
+		Data: vo,
+	})
+}
+
+// @func: rejected
+// @date: 2024-01-25 16:05:12
+// @brief: 帖子模块-审核未通过, 响应中附带审核原因
+// @author: Kewin Li
+// @receiver a
+// @param ctx
+// @param artId
+// @param version 制作库最新版本号, 作者下次修改需携带
+// @param authorId
+func (a *ArticleHandler) rejected(ctx *gin.Context, artId int64, version int64, authorId int64) {
+	vo := ReviewVo{ArtId: artId}
+	review, err := a.svc.GetReview(ctx, artId, authorId)
+	if err == nil {
+		vo = ConvertReviewVo(&review)
+		vo.Content = ""
+	}
+	vo.Version = version
+
+	ctx.JSON(http.StatusOK, Result{
+		Msg:  "审核未通过",
+		Data: vo,
+	})
+}
+
 // @func: Publish
 // @date: 2023-11-26 00:00:30
 // @brief: 帖子模块-帖子发表
@@ -147,14 +347,21 @@ ERR:
 // @param context
 func (a *ArticleHandler) Publish(ctx *gin.Context) {
 	type Req struct {
-		Id      int64  `json:"id"`
-		Title   string `json:"title"`
-		Content string `json:"content"`
+		Id       int64    `json:"id"`
+		Title    string   `json:"title"`
+		Content  string   `json:"content"`
+		Category string   `json:"category"`
+		Tags     []string `json:"tags"`
+		// 编辑时所基于的版本号
+		Version int64 `json:"version"`
 	}
 
 	var req Req
 	var err error
 	var artId int64
+	var version int64
+	var tags []string
+	var ok bool
 	logKey := logger.ArticleLogMsgKey[logger.LOG_ART_EDIT]
 	claims := ijwt.UserClaims{}
 	fileds := logger.Fields{}
@@ -172,14 +379,26 @@ func (a *ArticleHandler) Publish(ctx *gin.Context) {
 	// 作者Id通过jwt来解析
 	claims = ctx.MustGet("user_token").(ijwt.UserClaims)
 
+	tags, ok = normalizeTags(req.Tags)
+	req.Category = strings.TrimSpace(req.Category)
+	if !ok || utf8.RuneCountInString(req.Category) > articleCategoryMaxLen {
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "标签或分类不合法",
+		})
+		return
+	}
+
 	// 发表
-	artId, err = a.svc.Publish(ctx, domain.Article{
-		Id:      req.Id,
-		Title:   req.Title,
-		Content: req.Content,
+	artId, version, err = a.svc.Publish(ctx, domain.Article{
+		Id:       req.Id,
+		Title:    req.Title,
+		Content:  req.Content,
+		Category: req.Category,
+		Tags:     tags,
 		Author: domain.Author{
 			Id: claims.UserID,
 		},
+		Version: req.Version,
 	})
 
 	switch err {
@@ -191,10 +410,28 @@ func (a *ArticleHandler) Publish(ctx *gin.Context) {
 				Add(logger.Int[int64]("userId", claims.UserID))...)
 
 		ctx.JSON(http.StatusOK, Result{
-			Msg:  "发表成功",
-			Data: artId,
+			Msg: "发表成功",
+			Data: DraftVo{
+				Id:      artId,
+				Version: version,
+			},
 		})
 
+		return
+	case service.ErrArticleUnderReview:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "已提交审核, 审核通过后自动发表",
+			Data: DraftVo{
+				Id:      artId,
+				Version: version,
+			},
+		})
+		return
+	case service.ErrArticleRejected:
+		a.rejected(ctx, artId, version, claims.UserID)
+		return
+	case service.ErrVersionConflict:
+		a.versionConflict(ctx, req.Id)
 		return
 	case service.ErrInvalidUpdate:
 		ctx.JSON(http.StatusOK, Result{
@@ -484,21 +721,30 @@ func (a *ArticleHandler) PubDetail(ctx *gin.Context) {
 				Add(logger.Int[int64]("artId", artId)).
 				Add(logger.Int[int64]("userId", claims.UserID))...)
 
+		html := art.RenderHTML()
+		wordCnt := art.WordCount()
 		ctx.JSON(http.StatusOK, Result{
 			Msg: "查询成功",
 			Data: ArticleVo{
 				Id:         art.Id,
 				Title:      art.Title,
 				Content:    art.Content,
+				HTML:       html,
 				AuthorId:   art.Author.Id,
 				AuthorName: art.Author.Name,
 				Status:     art.Status.ToUint8(),
 				Ctime:      art.Ctime.Format(time.DateTime),
 				Utime:      art.Utime.Format(time.DateTime),
+				Category:   art.Category,
+				Tags:       art.Tags,
+
+				WordCnt:        wordCnt,
+				ReadingMinutes: markdown.ReadingMinutes(wordCnt),
 
 				ReadCnt:    intr.ReadCnt,
 				LikeCnt:    intr.LikeCnt,
 				CollectCnt: intr.CollectCnt,
+				CommentCnt: intr.CommentCnt,
 				Liked:      intr.Liked,
 				Collected:  intr.Collected,
 			}})
@@ -665,3 +911,625 @@ ERR:
 
 	return
 }
+
+// @func: PubListByTag
+// @date: 2024-01-21 16:20:45
+// @brief: 帖子模块-分页查询带有该标签的已发表帖子
+// @author: Kewin Li
+// @receiver a
+// @param ctx
+func (a *ArticleHandler) PubListByTag(ctx *gin.Context) {
+	type TagReq struct {
+		Offset int `form:"offset"`
+		Limit  int `form:"limit"`
+	}
+
+	var req TagReq
+	var err error
+	var arts []domain.Article
+	var total int64
+	logKey := logger.ArticleLogMsgKey[logger.LOG_ART_PUBTAG]
+	fields := logger.Fields{}
+	tag := strings.TrimSpace(ctx.Param("tag"))
+
+	err = ctx.BindQuery(&req)
+	if err != nil {
+		fields = fields.Add(logger.String("请求参数解析错误"))
+		goto ERR
+	}
+
+	if tag == "" || utf8.RuneCountInString(tag) > articleTagMaxLen ||
+		req.Offset < 0 || req.Limit <= 0 || req.Limit > articleTagMaxLimit {
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "参数错误",
+		})
+		return
+	}
+
+	arts, total, err = a.svc.ListPubByTag(ctx, tag, req.Offset, req.Limit)
+
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "查询成功",
+			Data: TagArticlesVo{
+				Tag:      tag,
+				Total:    total,
+				Articles: ConvertArticleVos(arts, true),
+			},
+		})
+		return
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "系统错误",
+		})
+	}
+
+ERR:
+	a.l.ERROR(logKey,
+		fields.Add(logger.Error(err)).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Field{"tag", tag}).
+			Add(logger.Int[int]("offset", req.Offset))...)
+	return
+}
+
+// @func: Tags
+// @date: 2024-01-21 16:26:12
+// @brief: 帖子模块-按前缀补全标签, 返回标签及其已发表帖子数
+// @author: Kewin Li
+// @receiver a
+// @param ctx
+func (a *ArticleHandler) Tags(ctx *gin.Context) {
+	type TagsReq struct {
+		Prefix string `form:"prefix"`
+		Limit  int    `form:"limit"`
+	}
+
+	var req TagsReq
+	var err error
+	var tags []domain.Tag
+	logKey := logger.ArticleLogMsgKey[logger.LOG_ART_TAGS]
+	fields := logger.Fields{}
+
+	err = ctx.BindQuery(&req)
+	if err != nil {
+		fields = fields.Add(logger.String("请求参数解析错误"))
+		goto ERR
+	}
+
+	req.Prefix = strings.TrimSpace(req.Prefix)
+	if req.Limit <= 0 || req.Limit > articleTagSuggestMaxLimit {
+		req.Limit = articleTagSuggestMaxLimit
+	}
+	if utf8.RuneCountInString(req.Prefix) > articleTagMaxLen {
+		ctx.JSON(http.StatusOK, Result{
+			Msg:  "查询成功",
+			Data: []TagVo{},
+		})
+		return
+	}
+
+	tags, err = a.svc.SearchTags(ctx, req.Prefix, req.Limit)
+
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg:  "查询成功",
+			Data: ConvertTagVos(tags),
+		})
+		return
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "系统错误",
+		})
+	}
+
+ERR:
+	a.l.ERROR(logKey,
+		fields.Add(logger.Error(err)).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Field{"prefix", req.Prefix})...)
+	return
+}
+
+// @func: normalizeTags
+// @date: 2024-01-21 16:12:30
+// @brief: 帖子模块-标签去除首尾空白、统一小写、去空、去重, 校验数量及长度, 没有标签时返回nil
+// @author: Kewin Li
+// @param tags
+// @return []string
+// @return bool 是否合法
+func normalizeTags(tags []string) ([]string, bool) {
+	res := domain.NormalizeTags(tags)
+	for _, tag := range res {
+		if utf8.RuneCountInString(tag) > articleTagMaxLen {
+			return nil, false
+		}
+	}
+
+	if len(res) == 0 {
+		return nil, true
+	}
+
+	return res, len(res) <= articleTagMaxCnt
+}
+
+// @func: Revisions
+// @date: 2024-01-22 11:30:42
+// @brief: 帖子模块-分页查询帖子的历史版本
+// @author: Kewin Li
+// @receiver a
+// @param ctx
+func (a *ArticleHandler) Revisions(ctx *gin.Context) {
+	type RevisionsReq struct {
+		Offset int `form:"offset"`
+		Limit  int `form:"limit"`
+	}
+
+	var req RevisionsReq
+	var err error
+	var artId int64
+	var claims ijwt.UserClaims
+	var revs []domain.ArticleRevision
+	logKey := logger.ArticleLogMsgKey[logger.LOG_ART_REVISIONS]
+	fields := logger.Fields{}
+
+	artId, err = strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		fields = fields.Add(logger.String("请求参数解析错误"))
+		goto ERR
+	}
+
+	err = ctx.BindQuery(&req)
+	if err != nil {
+		fields = fields.Add(logger.String("请求参数解析错误"))
+		goto ERR
+	}
+
+	if req.Offset < 0 || req.Limit <= 0 || req.Limit > articleRevisionMaxLimit {
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "参数错误",
+		})
+		return
+	}
+
+	claims = ctx.MustGet("user_token").(ijwt.UserClaims)
+
+	revs, err = a.svc.ListRevisions(ctx, artId, claims.UserID, req.Offset, req.Limit)
+
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg:  "查询成功",
+			Data: ConvertRevisionVos(revs),
+		})
+		return
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "系统错误",
+		})
+	}
+
+ERR:
+	a.l.ERROR(logKey,
+		fields.Add(logger.Error(err)).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Int[int64]("artId", artId)).
+			Add(logger.Int[int64]("userId", claims.UserID))...)
+	return
+}
+
+// @func: Diff
+// @date: 2024-01-22 11:36:15
+// @brief: 帖子模块-比较帖子的两个历史版本
+// @author: Kewin Li
+// @receiver a
+// @param ctx
+func (a *ArticleHandler) Diff(ctx *gin.Context) {
+	type DiffReq struct {
+		From int `form:"from"`
+		To   int `form:"to"`
+	}
+
+	var req DiffReq
+	var err error
+	var artId int64
+	var claims ijwt.UserClaims
+	var diff domain.ArticleDiff
+	logKey := logger.ArticleLogMsgKey[logger.LOG_ART_DIFF]
+	fields := logger.Fields{}
+
+	artId, err = strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		fields = fields.Add(logger.String("请求参数解析错误"))
+		goto ERR
+	}
+
+	err = ctx.BindQuery(&req)
+	if err != nil {
+		fields = fields.Add(logger.String("请求参数解析错误"))
+		goto ERR
+	}
+
+	if req.From <= 0 || req.To <= 0 {
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "参数错误",
+		})
+		return
+	}
+
+	claims = ctx.MustGet("user_token").(ijwt.UserClaims)
+
+	diff, err = a.svc.DiffRevisions(ctx, artId, claims.UserID, req.From, req.To)
+
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg:  "查询成功",
+			Data: ConvertArticleDiffVo(&diff),
+		})
+		return
+	case service.ErrRevisionNotFound:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "版本不存在",
+		})
+		return
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "系统错误",
+		})
+	}
+
+ERR:
+	a.l.ERROR(logKey,
+		fields.Add(logger.Error(err)).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Int[int64]("artId", artId)).
+			Add(logger.Int[int64]("userId", claims.UserID))...)
+	return
+}
+
+// @func: Rollback
+// @date: 2024-01-22 11:42:30
+// @brief: 帖子模块-将历史版本恢复为当前草稿
+// @author: Kewin Li
+// @receiver a
+// @param ctx
+func (a *ArticleHandler) Rollback(ctx *gin.Context) {
+	type RollbackReq struct {
+		Id      int64 `json:"id"`
+		Version int   `json:"version"`
+	}
+
+	var req RollbackReq
+	var err error
+	var claims ijwt.UserClaims
+	logKey := logger.ArticleLogMsgKey[logger.LOG_ART_ROLLBACK]
+	fields := logger.Fields{}
+
+	err = ctx.Bind(&req)
+	if err != nil {
+		fields = fields.Add(logger.String("请求解析错误"))
+		goto ERR
+	}
+
+	if req.Id <= 0 || req.Version <= 0 {
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "参数错误",
+		})
+		return
+	}
+
+	claims = ctx.MustGet("user_token").(ijwt.UserClaims)
+
+	err = a.svc.Rollback(ctx, req.Id, claims.UserID, req.Version)
+
+	switch err {
+	case nil:
+		a.l.INFO(logKey,
+			fields.Add(logger.String("帖子回滚成功")).
+				Add(logger.Field{"IP", ctx.ClientIP()}).
+				Add(logger.Int[int64]("artId", req.Id)).
+				Add(logger.Int[int]("version", req.Version)).
+				Add(logger.Int[int64]("userId", claims.UserID))...)
+
+		ctx.JSON(http.StatusOK, Result{
+			Msg:  "回滚成功",
+			Data: req.Id,
+		})
+		return
+	case service.ErrRevisionNotFound:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "版本不存在",
+		})
+		return
+	case service.ErrInvalidUpdate:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "非法操作",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "回滚失败",
+		})
+	}
+
+ERR:
+	a.l.ERROR(logKey,
+		fields.Add(logger.Error(err)).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Int[int64]("artId", req.Id)).
+			Add(logger.Int[int]("version", req.Version)).
+			Add(logger.Int[int64]("userId", claims.UserID))...)
+	return
+}
+
+// @func: Schedule
+// @date: 2024-01-22 16:40:26
+// @brief: 帖子模块-保存帖子并设置定时发表
+// @author: Kewin Li
+// @receiver a
+// @param ctx
+func (a *ArticleHandler) Schedule(ctx *gin.Context) {
+	type ScheduleReq struct {
+		Id       int64    `json:"id"`
+		Title    string   `json:"title"`
+		Content  string   `json:"content"`
+		Category string   `json:"category"`
+		Tags     []string `json:"tags"`
+		Version  int64    `json:"version"`
+		// 发表时间, 格式 2006-01-02 15:04:05
+		PublishTime string `json:"publishTime"`
+	}
+
+	var req ScheduleReq
+	var err error
+	var artId int64
+	var version int64
+	var tags []string
+	var ok bool
+	var publishTime time.Time
+	var claims ijwt.UserClaims
+	logKey := logger.ArticleLogMsgKey[logger.LOG_ART_SCHEDULE]
+	fields := logger.Fields{}
+
+	err = ctx.Bind(&req)
+	if err != nil {
+		fields = fields.Add(logger.String("请求解析错误"))
+		goto ERR
+	}
+
+	claims = ctx.MustGet("user_token").(ijwt.UserClaims)
+
+	publishTime, err = time.ParseInLocation(time.DateTime, req.PublishTime, time.Local)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "发表时间格式错误",
+		})
+		return
+	}
+
+	tags, ok = normalizeTags(req.Tags)
+	req.Category = strings.TrimSpace(req.Category)
+	if !ok || utf8.RuneCountInString(req.Category) > articleCategoryMaxLen {
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "标签或分类不合法",
+		})
+		return
+	}
+
+	artId, version, err = a.svc.Schedule(ctx, domain.Article{
+		Id:       req.Id,
+		Title:    req.Title,
+		Content:  req.Content,
+		Category: req.Category,
+		Tags:     tags,
+		Author: domain.Author{
+			Id: claims.UserID,
+		},
+		Version: req.Version,
+	}, publishTime)
+
+	switch err {
+	case nil:
+		a.l.INFO(logKey,
+			fields.Add(logger.String("定时发表设置成功")).
+				Add(logger.Field{"IP", ctx.ClientIP()}).
+				Add(logger.Int[int64]("artId", artId)).
+				Add(logger.Field{"publishTime", req.PublishTime}).
+				Add(logger.Int[int64]("userId", claims.UserID))...)
+
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "定时发表设置成功",
+			Data: DraftVo{
+				Id:      artId,
+				Version: version,
+			},
+		})
+		return
+	case service.ErrInvalidPublishTime:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "发表时间必须晚于当前时间",
+		})
+		return
+	case service.ErrVersionConflict:
+		a.versionConflict(ctx, req.Id)
+		return
+	case service.ErrInvalidUpdate:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "非法操作",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "定时发表设置失败",
+		})
+	}
+
+ERR:
+	a.l.ERROR(logKey,
+		fields.Add(logger.Error(err)).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Int[int64]("artId", req.Id)).
+			Add(logger.Int[int64]("userId", claims.UserID))...)
+	return
+}
+
+// @func: CancelSchedule
+// @date: 2024-01-22 16:46:50
+// @brief: 帖子模块-取消定时发表, 帖子恢复为草稿
+// @author: Kewin Li
+// @receiver a
+// @param ctx
+func (a *ArticleHandler) CancelSchedule(ctx *gin.Context) {
+	type CancelReq struct {
+		Id int64 `json:"id"`
+	}
+
+	var req CancelReq
+	var err error
+	var claims ijwt.UserClaims
+	logKey := logger.ArticleLogMsgKey[logger.LOG_ART_CANCEL_SCHEDULE]
+	fields := logger.Fields{}
+
+	err = ctx.Bind(&req)
+	if err != nil {
+		fields = fields.Add(logger.String("请求解析错误"))
+		goto ERR
+	}
+
+	claims = ctx.MustGet("user_token").(ijwt.UserClaims)
+
+	err = a.svc.CancelSchedule(ctx, req.Id, claims.UserID)
+
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg:  "取消成功",
+			Data: req.Id,
+		})
+		return
+	case service.ErrScheduleNotCancelable:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "定时发表计划不存在或已执行",
+		})
+		return
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "系统错误",
+		})
+	}
+
+ERR:
+	a.l.ERROR(logKey,
+		fields.Add(logger.Error(err)).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Int[int64]("artId", req.Id)).
+			Add(logger.Int[int64]("userId", claims.UserID))...)
+	return
+}
+
+// @func: GetSchedule
+// @date: 2024-01-22 16:50:12
+// @brief: 帖子模块-查询帖子的定时发表计划及执行结果
+// @author: Kewin Li
+// @receiver a
+// @param ctx
+func (a *ArticleHandler) GetSchedule(ctx *gin.Context) {
+	var err error
+	var artId int64
+	var claims ijwt.UserClaims
+	var schedule domain.ArticleSchedule
+	logKey := logger.ArticleLogMsgKey[logger.LOG_ART_GET_SCHEDULE]
+	fields := logger.Fields{}
+
+	artId, err = strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		fields = fields.Add(logger.String("请求参数解析错误"))
+		goto ERR
+	}
+
+	claims = ctx.MustGet("user_token").(ijwt.UserClaims)
+
+	schedule, err = a.svc.GetSchedule(ctx, artId, claims.UserID)
+
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg:  "查询成功",
+			Data: ConvertScheduleVo(&schedule),
+		})
+		return
+	case service.ErrScheduleNotFound:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "定时发表计划不存在",
+		})
+		return
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "系统错误",
+		})
+	}
+
+ERR:
+	a.l.ERROR(logKey,
+		fields.Add(logger.Error(err)).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Int[int64]("artId", artId)).
+			Add(logger.Int[int64]("userId", claims.UserID))...)
+	return
+}
+
+// @func: GetReview
+// @date: 2024-01-25 16:10:36
+// @brief: 帖子模块-查询帖子最近一次发表的审核结论及原因
+// @author: Kewin Li
+// @receiver a
+// @param ctx
+func (a *ArticleHandler) GetReview(ctx *gin.Context) {
+	var err error
+	var artId int64
+	var claims ijwt.UserClaims
+	var review domain.ArticleReview
+	var vo ReviewVo
+	logKey := logger.ArticleLogMsgKey[logger.LOG_ART_GET_REVIEW]
+	fields := logger.Fields{}
+
+	artId, err = strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		fields = fields.Add(logger.String("请求参数解析错误"))
+		goto ERR
+	}
+
+	claims = ctx.MustGet("user_token").(ijwt.UserClaims)
+
+	review, err = a.svc.GetReview(ctx, artId, claims.UserID)
+
+	switch err {
+	case nil:
+		// 作者查看时不需要返回审核时的内容快照
+		vo = ConvertReviewVo(&review)
+		vo.Content = ""
+		ctx.JSON(http.StatusOK, Result{
+			Msg:  "查询成功",
+			Data: vo,
+		})
+		return
+	case service.ErrReviewNotFound:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "审核记录不存在",
+		})
+		return
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "系统错误",
+		})
+	}
+
+ERR:
+	a.l.ERROR(logKey,
+		fields.Add(logger.Error(err)).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Int[int64]("artId", artId)).
+			Add(logger.Int[int64]("userId", claims.UserID))...)
+	return
+}
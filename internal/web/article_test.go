@@ -45,7 +45,7 @@ func TestArticleHandler_Publish(t *testing.T) {
 					Author: domain.Author{
 						Id: 123,
 					},
-				}).Return(int64(1), nil)
+				}).Return(int64(1), int64(2), nil)
 
 				return svc
 			},
@@ -56,8 +56,11 @@ func TestArticleHandler_Publish(t *testing.T) {
 }`,
 			wantCode: http.StatusOK,
 			wantRes: Result{
-				Msg:  "发表成功",
-				Data: float64(1), // json中数字转为go类型默认是float64
+				Msg: "发表成功",
+				Data: map[string]any{
+					"id":      float64(1), // json中数字转为go类型默认是float64
+					"version": float64(2),
+				},
 			},
 		},
 		{
@@ -71,7 +74,8 @@ func TestArticleHandler_Publish(t *testing.T) {
 					Author: domain.Author{
 						Id: 123,
 					},
-				}).Return(int64(666), nil)
+					Version: 3,
+				}).Return(int64(666), int64(5), nil)
 
 				return svc
 			},
@@ -79,12 +83,16 @@ func TestArticleHandler_Publish(t *testing.T) {
 			reqBody: `{
 "id": 666,
 "title": "发表标题",
-"content": "发表内容"
+"content": "发表内容",
+"version": 3
 }`,
 			wantCode: http.StatusOK,
 			wantRes: Result{
-				Msg:  "发表成功",
-				Data: float64(666), // json中数字转为go类型默认是float64
+				Msg: "发表成功",
+				Data: map[string]any{
+					"id":      float64(666),
+					"version": float64(5),
+				},
 			},
 		},
 		{
@@ -98,7 +106,7 @@ func TestArticleHandler_Publish(t *testing.T) {
 					Author: domain.Author{
 						Id: 123,
 					},
-				}).Return(int64(777), errors.New("未知错误"))
+				}).Return(int64(777), int64(0), errors.New("未知错误"))
 
 				return svc
 			},
@@ -114,6 +122,55 @@ func TestArticleHandler_Publish(t *testing.T) {
 				Data: float64(-1), // json中数字转为go类型默认是float64
 			},
 		},
+		{
+			name: "带标签发表, 标签统一小写后去重去空",
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				svc := svcmocks.NewMockArticleService(ctrl)
+				svc.EXPECT().Publish(gomock.Any(), domain.Article{
+					Title:    "发表标题",
+					Content:  "发表内容",
+					Category: "后端",
+					Tags:     []string{"go", "redis"},
+					Author: domain.Author{
+						Id: 123,
+					},
+				}).Return(int64(1), int64(2), nil)
+
+				return svc
+			},
+
+			reqBody: `{
+"title": "发表标题",
+"content": "发表内容",
+"category": " 后端 ",
+"tags": [" Go", "Redis", "", "go "]
+}`,
+			wantCode: http.StatusOK,
+			wantRes: Result{
+				Msg: "发表成功",
+				Data: map[string]any{
+					"id":      float64(1),
+					"version": float64(2),
+				},
+			},
+		},
+		{
+			name: "标签过多",
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				svc := svcmocks.NewMockArticleService(ctrl)
+				return svc
+			},
+
+			reqBody: `{
+"title": "发表标题",
+"content": "发表内容",
+"tags": ["a", "b", "c", "d", "e", "f"]
+}`,
+			wantCode: http.StatusOK,
+			wantRes: Result{
+				Msg: "标签或分类不合法",
+			},
+		},
 		{
 			name: "Bind错误",
 			mock: func(ctrl *gomock.Controller) service.ArticleService {
@@ -165,6 +222,74 @@ func TestArticleHandler_Publish(t *testing.T) {
 	}
 }
 
+// @func: TestArticleHandler_Edit
+// @date: 2024-01-28 19:30:16
+// @brief: 帖子保存-单元测试-同一页面连续保存, 第二次携带第一次响应中的版本号, 不会与自己的保存冲突
+// @author: Kewin Li
+// @param t
+func TestArticleHandler_Edit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc := svcmocks.NewMockArticleService(ctrl)
+	gomock.InOrder(
+		svc.EXPECT().Save(gomock.Any(), domain.Article{
+			Id:      1,
+			Title:   "第一次保存",
+			Author:  domain.Author{Id: 123},
+			Version: 3,
+		}).Return(int64(1), int64(4), nil),
+		svc.EXPECT().Save(gomock.Any(), domain.Article{
+			Id:      1,
+			Title:   "第二次保存",
+			Author:  domain.Author{Id: 123},
+			Version: 4,
+		}).Return(int64(1), int64(5), nil),
+	)
+
+	hdl := NewArticleHandler(svc, nil, logger.NewNopLogger())
+	server := gin.Default()
+	server.Use(func(ctx *gin.Context) {
+		ctx.Set("user_token", ijwt.UserClaims{
+			UserID: 123,
+		})
+	})
+	hdl.RegisterRoutes(server)
+
+	edit := func(title string, version int64) DraftVo {
+		body, err := json.Marshal(map[string]any{
+			"id":      1,
+			"title":   title,
+			"version": version,
+		})
+		assert.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodPost, "/articles/edit", bytes.NewReader(body))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, req)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		var res struct {
+			Msg  string  `json:"msg"`
+			Data DraftVo `json:"data"`
+		}
+		err = json.NewDecoder(recorder.Body).Decode(&res)
+		assert.NoError(t, err)
+		assert.Equal(t, "保存成功", res.Msg)
+		return res.Data
+	}
+
+	draft := edit("第一次保存", 3)
+	assert.Equal(t, DraftVo{Id: 1, Version: 4}, draft)
+
+	// 第二次保存使用第一次响应中的版本号
+	draft = edit("第二次保存", draft.Version)
+	assert.Equal(t, DraftVo{Id: 1, Version: 5}, draft)
+}
+
 // @func: TestArticleHandler_Withdraw
 // @date: 2023-11-29 01:03:39
 // @brief: 帖子撤回-单元测试
@@ -353,3 +478,112 @@ func isResEqual(t *testing.T, arts []ArticleVo, datas []any) {
 	}
 
 }
+
+// @func: TestArticleHandler_PubListByTag
+// @date: 2024-01-21 16:40:12
+// @brief: 按标签查询已发表帖子-单元测试
+// @author: Kewin Li
+// @param t
+func TestArticleHandler_PubListByTag(t *testing.T) {
+	now := time.Now()
+	testCases := []struct {
+		name string
+
+		mock func(ctrl *gomock.Controller) service.ArticleService
+
+		url string
+
+		wantCode int
+		wantRes  Result
+	}{
+		{
+			name: "查询成功",
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				svc := svcmocks.NewMockArticleService(ctrl)
+				svc.EXPECT().ListPubByTag(gomock.Any(), "Go", 0, 10).
+					Return([]domain.Article{
+						{
+							Id:     1,
+							Tags:   []string{"Go"},
+							Status: domain.ArticleStatusPublished,
+							Ctime:  now,
+							Utime:  now,
+						},
+					}, int64(1), nil)
+				return svc
+			},
+
+			url:      "/articles/pub/tag/Go?offset=0&limit=10",
+			wantCode: http.StatusOK,
+			wantRes: Result{
+				Msg: "查询成功",
+				Data: map[string]any{
+					"tag":   "Go",
+					"total": float64(1),
+					"articles": []any{
+						map[string]any{
+							"id":     float64(1),
+							"status": float64(domain.ArticleStatusPublished),
+							"ctime":  now.Format(time.DateTime),
+							"utime":  now.Format(time.DateTime),
+							"tags":   []any{"Go"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "分页参数错误",
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				return svcmocks.NewMockArticleService(ctrl)
+			},
+
+			url:      "/articles/pub/tag/Go?offset=0&limit=1000",
+			wantCode: http.StatusOK,
+			wantRes: Result{
+				Msg: "参数错误",
+			},
+		},
+		{
+			name: "查询失败",
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				svc := svcmocks.NewMockArticleService(ctrl)
+				svc.EXPECT().ListPubByTag(gomock.Any(), "Go", 0, 10).
+					Return(nil, int64(0), errors.New("模拟查询失败"))
+				return svc
+			},
+
+			url:      "/articles/pub/tag/Go?offset=0&limit=10",
+			wantCode: http.StatusOK,
+			wantRes: Result{
+				Msg: "系统错误",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			svc := tc.mock(ctrl)
+			hdl := NewArticleHandler(svc, nil, logger.NewNopLogger())
+
+			server := gin.Default()
+			hdl.RegisterRoutes(server)
+
+			req, err := http.NewRequest(http.MethodGet, tc.url, nil)
+			assert.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+
+			server.ServeHTTP(recorder, req)
+			assert.Equal(t, tc.wantCode, recorder.Code)
+
+			var res Result
+			err = json.NewDecoder(recorder.Body).Decode(&res)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantRes, res)
+		})
+	}
+}
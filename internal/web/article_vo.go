@@ -4,6 +4,7 @@ package web
 
 import (
 	"kitbook/internal/domain"
+	"kitbook/pkg/markdown"
 	"time"
 )
 
@@ -12,15 +13,25 @@ type ArticleVo struct {
 	Title      string `json:"title,omitempty"`
 	Abstract   string `json:"abstract,omitempty"`
 	Content    string `json:"content,omitempty"`
+	HTML       string `json:"html,omitempty"` // 正文渲染后的HTML, 已经过XSS过滤, 只在读者详情中返回
 	AuthorId   int64  `json:"authorId,omitempty"`
 	AuthorName string `json:"authorName,omitempty"`
 	Status     uint8  `json:"status,omitempty"`
+	Version    int64  `json:"version,omitempty"`
 	Ctime      string `json:"ctime,omitempty"`
 	Utime      string `json:"utime,omitempty"`
 
+	Category string   `json:"category,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+
+	// 字数、预计阅读分钟数, 只在返回完整正文时计算
+	WordCnt        int `json:"wordCnt,omitempty"`
+	ReadingMinutes int `json:"readingMinutes,omitempty"`
+
 	ReadCnt    int64 `json:"readCnt,omitempty"`
 	LikeCnt    int64 `json:"likeCnt,omitempty"`
 	CollectCnt int64 `json:"collectCnt,omitempty"`
+	CommentCnt int64 `json:"commentCnt,omitempty"`
 	Liked      bool  `json:"liked,omitempty"`
 	Collected  bool  `json:"collected,omitempty"`
 }
@@ -42,9 +53,19 @@ func ConvertArticleVo(art *domain.Article, isAbstract bool) ArticleVo {
 		AuthorId:   art.Author.Id,
 		AuthorName: "",
 		Status:     art.Status.ToUint8(),
+		Version:    art.Version,
 		Ctime:      art.Ctime.Format(time.DateTime),
 		Utime:      art.Utime.Format(time.DateTime),
+		Category:   art.Category,
+		Tags:       art.Tags,
+	}
+
+	// 列表中的正文可能已被缓存截断, 不统计字数
+	if !isAbstract {
+		vo.WordCnt = art.WordCount()
+		vo.ReadingMinutes = markdown.ReadingMinutes(vo.WordCnt)
 	}
+
 	return vo
 }
 
@@ -57,3 +78,165 @@ func ConvertArticleVos(arts []domain.Article, isAbstract bool) []ArticleVo {
 	return artsVo
 
 }
+
+// TagArticlesVo
+// @Description: 前端响应-带有某标签的已发表帖子
+type TagArticlesVo struct {
+	Tag      string      `json:"tag"`
+	Total    int64       `json:"total"`
+	Articles []ArticleVo `json:"articles"`
+}
+
+// TagVo
+// @Description: 前端响应-标签及其已发表帖子数
+type TagVo struct {
+	Name string `json:"name"`
+	Cnt  int64  `json:"cnt"`
+}
+
+func ConvertTagVos(tags []domain.Tag) []TagVo {
+	vos := make([]TagVo, 0, len(tags))
+	for _, tag := range tags {
+		vos = append(vos, TagVo{
+			Name: tag.Name,
+			Cnt:  tag.ArticleCnt,
+		})
+	}
+
+	return vos
+}
+
+// RevisionVo
+// @Description: 前端响应-帖子历史版本, 列表中不返回正文
+type RevisionVo struct {
+	Version  int      `json:"version"`
+	Kind     string   `json:"kind"`
+	Title    string   `json:"title"`
+	Abstract string   `json:"abstract,omitempty"`
+	Category string   `json:"category,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Ctime    string   `json:"ctime"`
+}
+
+func ConvertRevisionVos(revs []domain.ArticleRevision) []RevisionVo {
+	vos := make([]RevisionVo, 0, len(revs))
+	for _, rev := range revs {
+		art := domain.Article{Content: rev.Content}
+		vos = append(vos, RevisionVo{
+			Version:  rev.Version,
+			Kind:     rev.Kind.String(),
+			Title:    rev.Title,
+			Abstract: art.CreateAbstract(),
+			Category: rev.Category,
+			Tags:     rev.Tags,
+			Ctime:    rev.Ctime.Format(time.DateTime),
+		})
+	}
+
+	return vos
+}
+
+// ArticleDiffVo
+// @Description: 前端响应-帖子两个版本之间的差异
+type ArticleDiffVo struct {
+	From RevisionVo `json:"from"`
+	To   RevisionVo `json:"to"`
+	// 新增、删除的行数
+	Added   int          `json:"added"`
+	Deleted int          `json:"deleted"`
+	Lines   []DiffLineVo `json:"lines"`
+}
+
+// DiffLineVo
+// @Description: 前端响应-diff中的一行, op为 = + -
+type DiffLineVo struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+func ConvertArticleDiffVo(diff *domain.ArticleDiff) ArticleDiffVo {
+	revs := ConvertRevisionVos([]domain.ArticleRevision{diff.From, diff.To})
+	added, deleted := diff.Stat()
+
+	lines := make([]DiffLineVo, 0, len(diff.Lines))
+	for _, line := range diff.Lines {
+		lines = append(lines, DiffLineVo{
+			Op:   line.Op.String(),
+			Text: line.Text,
+		})
+	}
+
+	return ArticleDiffVo{
+		From:    revs[0],
+		To:      revs[1],
+		Added:   added,
+		Deleted: deleted,
+		Lines:   lines,
+	}
+}
+
+// ScheduleVo
+// @Description: 前端响应-帖子定时发表计划
+type ScheduleVo struct {
+	ArtId       int64  `json:"artId"`
+	PublishTime string `json:"publishTime"`
+	Status      string `json:"status"`
+	Error       string `json:"error,omitempty"`
+	Utime       string `json:"utime"`
+}
+
+func ConvertScheduleVo(s *domain.ArticleSchedule) ScheduleVo {
+	return ScheduleVo{
+		ArtId:       s.ArtId,
+		PublishTime: s.PublishTime.Format(time.DateTime),
+		Status:      s.Status.String(),
+		Error:       s.Error,
+		Utime:       s.Utime.Format(time.DateTime),
+	}
+}
+
+// ReviewVo
+// @Description: 前端响应-帖子审核记录, 作者查看审核结论及原因, 审核员查看待审核内容
+type ReviewVo struct {
+	Id       int64    `json:"id"`
+	ArtId    int64    `json:"artId"`
+	AuthorId int64    `json:"authorId"`
+	Version  int64    `json:"version"`
+	Title    string   `json:"title"`
+	Content  string   `json:"content,omitempty"`
+	Status   string   `json:"status"`
+	Reasons  []string `json:"reasons"`
+	Ctime    string   `json:"ctime"`
+	Utime    string   `json:"utime"`
+}
+
+func ConvertReviewVo(r *domain.ArticleReview) ReviewVo {
+	return ReviewVo{
+		Id:       r.Id,
+		ArtId:    r.ArtId,
+		AuthorId: r.AuthorId,
+		Version:  r.Version,
+		Title:    r.Title,
+		Content:  r.Content,
+		Status:   r.Status.String(),
+		Reasons:  r.Reasons,
+		Ctime:    r.Ctime.Format(time.DateTime),
+		Utime:    r.Utime.Format(time.DateTime),
+	}
+}
+
+func ConvertReviewVos(reviews []domain.ArticleReview) []ReviewVo {
+	res := make([]ReviewVo, 0, len(reviews))
+	for _, r := range reviews {
+		res = append(res, ConvertReviewVo(&r))
+	}
+
+	return res
+}
+
+// DraftVo
+// @Description: 前端响应-草稿保存结果及最新版本号, 下次修改需携带该版本号
+type DraftVo struct {
+	Id      int64 `json:"id"`
+	Version int64 `json:"version"`
+}
@@ -0,0 +1,447 @@
+// Package web
+// @Description: 收藏夹模块
+package web
+
+import (
+	"github.com/gin-gonic/gin"
+	"kitbook/internal/domain"
+	"kitbook/internal/service"
+	ijwt "kitbook/internal/web/jwt"
+	"kitbook/pkg/logger"
+	"net/http"
+	"unicode/utf8"
+)
+
+const (
+	// 收藏夹名称最大长度
+	collectionNameMaxLen = 64
+	// 收藏夹描述最大长度
+	collectionDescMaxLen = 512
+	// 收藏单页最大条数
+	collectionItemMaxLimit = 100
+)
+
+type CollectionHandler struct {
+	svc service.CollectionService
+	biz string
+	l   logger.Logger
+}
+
+func NewCollectionHandler(svc service.CollectionService, l logger.Logger) *CollectionHandler {
+	return &CollectionHandler{
+		svc: svc,
+		biz: "article",
+		l:   l,
+	}
+}
+
+func (c *CollectionHandler) RegisterRoutes(server *gin.Engine) {
+	group := server.Group("/collections")
+	group.POST("/create", c.Create) // 新建收藏夹
+	group.POST("/edit", c.Edit)     // 修改收藏夹
+	group.POST("/delete", c.Delete) // 删除收藏夹及其中的收藏
+	group.POST("/move", c.Move)     // 移动收藏到另一个收藏夹
+
+	// /list?uid=?  用户的收藏夹, uid为空时查询自己
+	group.GET("/list", c.List)
+	// /items?id=?&offset=?&limit=?  收藏夹中的收藏, id为0表示默认收藏夹
+	group.GET("/items", c.Items)
+}
+
+// CollectionReq
+// @Description: 新建/修改收藏夹请求参数
+type CollectionReq struct {
+	Id          int64  `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Private     bool   `json:"private"`
+}
+
+// @func: checkCollectionReq
+// @date: 2024-01-14 15:45:26
+// @brief: 收藏夹模块-校验名称、描述
+// @author: Kewin Li
+// @param req
+// @return bool
+func checkCollectionReq(req *CollectionReq) bool {
+	nameLen := utf8.RuneCountInString(req.Name)
+	return nameLen > 0 && nameLen <= collectionNameMaxLen &&
+		utf8.RuneCountInString(req.Description) <= collectionDescMaxLen
+}
+
+// @func: Create
+// @date: 2024-01-14 15:48:10
+// @brief: 收藏夹模块-新建收藏夹
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+func (c *CollectionHandler) Create(ctx *gin.Context) {
+	var req CollectionReq
+	var err error
+	var id int64
+	var claims ijwt.UserClaims
+	logKey := logger.CollectionLogMsgKey[logger.LOG_COLLECTION_CREATE]
+	fields := logger.Fields{}
+
+	err = ctx.Bind(&req)
+	if err != nil {
+		fields = fields.Add(logger.String("请求参数解析错误"))
+		goto ERR
+	}
+
+	claims = ctx.MustGet("user_token").(ijwt.UserClaims)
+
+	if !checkCollectionReq(&req) {
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "参数错误",
+		})
+		return
+	}
+
+	id, err = c.svc.CreateCollection(ctx, domain.Collection{
+		Uid:         claims.UserID,
+		Name:        req.Name,
+		Description: req.Description,
+		Private:     req.Private,
+	})
+
+	switch err {
+	case nil:
+		c.l.INFO(logKey, fields.Add(logger.String("新建收藏夹成功")).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Int[int64]("collectId", id)).
+			Add(logger.Int[int64]("userId", claims.UserID))...)
+
+		ctx.JSON(http.StatusOK, Result{
+			Msg:  "新建成功",
+			Data: id,
+		})
+		return
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "系统错误",
+		})
+	}
+
+ERR:
+	c.l.ERROR(logKey,
+		fields.Add(logger.Error(err)).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Field{"name", req.Name}).
+			Add(logger.Int[int64]("userId", claims.UserID))...)
+	return
+}
+
+// @func: Edit
+// @date: 2024-01-14 15:55:32
+// @brief: 收藏夹模块-修改收藏夹
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+func (c *CollectionHandler) Edit(ctx *gin.Context) {
+	var req CollectionReq
+	var err error
+	var claims ijwt.UserClaims
+	logKey := logger.CollectionLogMsgKey[logger.LOG_COLLECTION_EDIT]
+	fields := logger.Fields{}
+
+	err = ctx.Bind(&req)
+	if err != nil {
+		fields = fields.Add(logger.String("请求参数解析错误"))
+		goto ERR
+	}
+
+	claims = ctx.MustGet("user_token").(ijwt.UserClaims)
+
+	if req.Id <= 0 || !checkCollectionReq(&req) {
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "参数错误",
+		})
+		return
+	}
+
+	err = c.svc.UpdateCollection(ctx, domain.Collection{
+		Id:          req.Id,
+		Uid:         claims.UserID,
+		Name:        req.Name,
+		Description: req.Description,
+		Private:     req.Private,
+	})
+
+	switch err {
+	case nil:
+		c.l.INFO(logKey, fields.Add(logger.String("修改收藏夹成功")).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Int[int64]("collectId", req.Id)).
+			Add(logger.Int[int64]("userId", claims.UserID))...)
+
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "修改成功",
+		})
+		return
+	case service.ErrInvalidUpdate:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "非法操作",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "系统错误",
+		})
+	}
+
+ERR:
+	c.l.ERROR(logKey,
+		fields.Add(logger.Error(err)).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Int[int64]("collectId", req.Id)).
+			Add(logger.Int[int64]("userId", claims.UserID))...)
+	return
+}
+
+// @func: Delete
+// @date: 2024-01-14 16:01:47
+// @brief: 收藏夹模块-删除收藏夹, 连带取消其中的收藏
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+func (c *CollectionHandler) Delete(ctx *gin.Context) {
+	type DeleteReq struct {
+		Id int64 `json:"id"`
+	}
+
+	var req DeleteReq
+	var err error
+	var claims ijwt.UserClaims
+	logKey := logger.CollectionLogMsgKey[logger.LOG_COLLECTION_DELETE]
+	fields := logger.Fields{}
+
+	err = ctx.Bind(&req)
+	if err != nil {
+		fields = fields.Add(logger.String("请求参数解析错误"))
+		goto ERR
+	}
+
+	claims = ctx.MustGet("user_token").(ijwt.UserClaims)
+
+	err = c.svc.DeleteCollection(ctx, req.Id, claims.UserID)
+
+	switch err {
+	case nil:
+		c.l.INFO(logKey, fields.Add(logger.String("删除收藏夹成功")).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Int[int64]("collectId", req.Id)).
+			Add(logger.Int[int64]("userId", claims.UserID))...)
+
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "删除成功",
+		})
+		return
+	case service.ErrInvalidUpdate:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "非法操作",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "系统错误",
+		})
+	}
+
+ERR:
+	c.l.ERROR(logKey,
+		fields.Add(logger.Error(err)).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Int[int64]("collectId", req.Id)).
+			Add(logger.Int[int64]("userId", claims.UserID))...)
+	return
+}
+
+// @func: Move
+// @date: 2024-01-14 16:07:15
+// @brief: 收藏夹模块-移动收藏到另一个收藏夹
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+func (c *CollectionHandler) Move(ctx *gin.Context) {
+	type MoveReq struct {
+		// 源收藏夹, 0表示默认收藏夹
+		SrcId int64 `json:"srcId"`
+		// 目标收藏夹, 0表示默认收藏夹
+		DstId int64 `json:"dstId"`
+		// 帖子ID
+		Ids []int64 `json:"ids"`
+	}
+
+	var req MoveReq
+	var err error
+	var cnt int64
+	var claims ijwt.UserClaims
+	logKey := logger.CollectionLogMsgKey[logger.LOG_COLLECTION_MOVE]
+	fields := logger.Fields{}
+
+	err = ctx.Bind(&req)
+	if err != nil {
+		fields = fields.Add(logger.String("请求参数解析错误"))
+		goto ERR
+	}
+
+	claims = ctx.MustGet("user_token").(ijwt.UserClaims)
+
+	if req.SrcId < 0 || req.DstId < 0 || len(req.Ids) > collectionItemMaxLimit {
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "参数错误",
+		})
+		return
+	}
+
+	cnt, err = c.svc.MoveItems(ctx, claims.UserID, req.SrcId, req.DstId, c.biz, req.Ids)
+
+	switch err {
+	case nil:
+		c.l.INFO(logKey, fields.Add(logger.String("移动收藏成功")).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Int[int64]("srcId", req.SrcId)).
+			Add(logger.Int[int64]("dstId", req.DstId)).
+			Add(logger.Int[int64]("cnt", cnt)).
+			Add(logger.Int[int64]("userId", claims.UserID))...)
+
+		ctx.JSON(http.StatusOK, Result{
+			Msg:  "移动成功",
+			Data: cnt,
+		})
+		return
+	case service.ErrInvalidUpdate:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "非法操作",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "系统错误",
+		})
+	}
+
+ERR:
+	c.l.ERROR(logKey,
+		fields.Add(logger.Error(err)).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Int[int64]("srcId", req.SrcId)).
+			Add(logger.Int[int64]("dstId", req.DstId)).
+			Add(logger.Int[int64]("userId", claims.UserID))...)
+	return
+}
+
+// @func: List
+// @date: 2024-01-14 16:15:02
+// @brief: 收藏夹模块-查询用户的收藏夹列表
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+func (c *CollectionHandler) List(ctx *gin.Context) {
+	type ListReq struct {
+		Uid int64 `form:"uid"`
+	}
+
+	var req ListReq
+	var err error
+	var claims ijwt.UserClaims
+	var cs []domain.Collection
+	logKey := logger.CollectionLogMsgKey[logger.LOG_COLLECTION_LIST]
+	fields := logger.Fields{}
+
+	err = ctx.BindQuery(&req)
+	if err != nil {
+		fields = fields.Add(logger.String("请求参数解析错误"))
+		goto ERR
+	}
+
+	claims = ctx.MustGet("user_token").(ijwt.UserClaims)
+	if req.Uid <= 0 {
+		req.Uid = claims.UserID
+	}
+
+	cs, err = c.svc.ListCollections(ctx, req.Uid, claims.UserID)
+
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg:  "查询成功",
+			Data: ConvertsCollectionVos(cs),
+		})
+		return
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "系统错误",
+		})
+	}
+
+ERR:
+	c.l.ERROR(logKey,
+		fields.Add(logger.Error(err)).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Int[int64]("uid", req.Uid)).
+			Add(logger.Int[int64]("userId", claims.UserID))...)
+	return
+}
+
+// @func: Items
+// @date: 2024-01-14 16:21:38
+// @brief: 收藏夹模块-分页查询收藏夹中的收藏
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+func (c *CollectionHandler) Items(ctx *gin.Context) {
+	type ItemsReq struct {
+		Id     int64 `form:"id"`
+		Offset int   `form:"offset"`
+		Limit  int   `form:"limit"`
+	}
+
+	var req ItemsReq
+	var err error
+	var claims ijwt.UserClaims
+	var items []domain.CollectionItem
+	logKey := logger.CollectionLogMsgKey[logger.LOG_COLLECTION_ITEMS]
+	fields := logger.Fields{}
+
+	err = ctx.BindQuery(&req)
+	if err != nil {
+		fields = fields.Add(logger.String("请求参数解析错误"))
+		goto ERR
+	}
+
+	if req.Id < 0 || req.Offset < 0 || req.Limit <= 0 || req.Limit > collectionItemMaxLimit {
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "参数错误",
+		})
+		return
+	}
+
+	claims = ctx.MustGet("user_token").(ijwt.UserClaims)
+
+	items, err = c.svc.ListItems(ctx, req.Id, claims.UserID, req.Offset, req.Limit)
+
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg:  "查询成功",
+			Data: ConvertsCollectionItemVos(items),
+		})
+		return
+	case service.ErrCollectionNotFound:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "收藏夹不存在",
+		})
+		return
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "系统错误",
+		})
+	}
+
+ERR:
+	c.l.ERROR(logKey,
+		fields.Add(logger.Error(err)).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Int[int64]("collectId", req.Id)).
+			Add(logger.Int[int64]("userId", claims.UserID))...)
+	return
+}
@@ -0,0 +1,63 @@
+package web
+
+import (
+	"kitbook/internal/domain"
+	"time"
+)
+
+// CollectionVo
+// @Description: 前端响应-收藏夹
+type CollectionVo struct {
+	Id          int64  `json:"id"`
+	Uid         int64  `json:"uid"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Private     bool   `json:"private"`
+	Ctime       string `json:"ctime"`
+	Utime       string `json:"utime"`
+}
+
+// CollectionItemVo
+// @Description: 前端响应-收藏夹中的收藏
+type CollectionItemVo struct {
+	Id        int64  `json:"id"`
+	CollectId int64  `json:"collectId"`
+	BizId     int64  `json:"bizId"`
+	Title     string `json:"title"`
+	Author    string `json:"author"`
+	// 收藏时间
+	Utime string `json:"utime"`
+}
+
+func ConvertsCollectionVos(cs []domain.Collection) []CollectionVo {
+	vos := make([]CollectionVo, 0, len(cs))
+	for _, c := range cs {
+		vos = append(vos, CollectionVo{
+			Id:          c.Id,
+			Uid:         c.Uid,
+			Name:        c.Name,
+			Description: c.Description,
+			Private:     c.Private,
+			Ctime:       c.Ctime.Format(time.DateTime),
+			Utime:       c.Utime.Format(time.DateTime),
+		})
+	}
+
+	return vos
+}
+
+func ConvertsCollectionItemVos(items []domain.CollectionItem) []CollectionItemVo {
+	vos := make([]CollectionItemVo, 0, len(items))
+	for _, item := range items {
+		vos = append(vos, CollectionItemVo{
+			Id:        item.Id,
+			CollectId: item.CollectId,
+			BizId:     item.BizId,
+			Title:     item.Article.Title,
+			Author:    item.Article.Author.Name,
+			Utime:     item.Utime.Format(time.DateTime),
+		})
+	}
+
+	return vos
+}
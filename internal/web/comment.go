@@ -0,0 +1,302 @@
+// Package web
+// @Description: 评论模块
+package web
+
+import (
+	"github.com/gin-gonic/gin"
+	"kitbook/internal/domain"
+	"kitbook/internal/service"
+	ijwt "kitbook/internal/web/jwt"
+	"kitbook/pkg/logger"
+	"net/http"
+)
+
+// 评论单页最大条数
+const commentMaxLimit = 100
+
+type CommentHandler struct {
+	svc service.CommentService
+	l   logger.Logger
+}
+
+func NewCommentHandler(svc service.CommentService, l logger.Logger) *CommentHandler {
+	return &CommentHandler{
+		svc: svc,
+		l:   l,
+	}
+}
+
+func (c *CommentHandler) RegisterRoutes(server *gin.Engine) {
+	group := server.Group("/comments")
+	group.POST("/create", c.Create) // 发表评论/回复评论
+	group.POST("/delete", c.Delete) // 删除自己的评论
+
+	// /list?biz=?&bizId=?&cursor=?&limit=?  根评论, 带前N条回复
+	group.GET("/list", c.List)
+	// /replies?rootId=?&cursor=?&limit=?  根评论下的更多回复
+	group.GET("/replies", c.Replies)
+}
+
+// @func: Create
+// @date: 2024-01-08 22:30:16
+// @brief: 评论模块-发表评论, parentId不为0时表示回复评论
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+func (c *CommentHandler) Create(ctx *gin.Context) {
+	type CreateReq struct {
+		Biz     string `json:"biz"`
+		BizId   int64  `json:"bizId"`
+		Content string `json:"content"`
+		// 回复的评论ID
+		ParentId int64 `json:"parentId"`
+	}
+
+	var req CreateReq
+	var err error
+	var id int64
+	var claims ijwt.UserClaims
+	var cmt domain.Comment
+	logKey := logger.CommentLogMsgKey[logger.LOG_COMMENT_CREATE]
+	fields := logger.Fields{}
+
+	err = ctx.Bind(&req)
+	if err != nil {
+		fields = fields.Add(logger.String("请求参数解析错误"))
+		goto ERR
+	}
+
+	claims = ctx.MustGet("user_token").(ijwt.UserClaims)
+
+	if req.Biz == "" || req.BizId <= 0 || req.Content == "" {
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "参数错误",
+		})
+		return
+	}
+
+	cmt = domain.Comment{
+		Commenter: domain.User{
+			Id: claims.UserID,
+		},
+		Biz:     req.Biz,
+		BizId:   req.BizId,
+		Content: req.Content,
+	}
+	if req.ParentId > 0 {
+		cmt.ParentComment = &domain.Comment{
+			Id: req.ParentId,
+		}
+	}
+
+	id, err = c.svc.CreateComment(ctx, cmt)
+
+	switch err {
+	case nil:
+		c.l.INFO(logKey, fields.Add(logger.String("评论成功")).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Int[int64]("commentId", id)).
+			Add(logger.Int[int64]("userId", claims.UserID))...)
+
+		ctx.JSON(http.StatusOK, Result{
+			Msg:  "评论成功",
+			Data: id,
+		})
+		return
+	case service.ErrInvalidComment:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "回复的评论不存在",
+		})
+	case service.ErrCommentTargetNotFound:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "帖子不存在",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "系统错误",
+		})
+	}
+
+ERR:
+	c.l.ERROR(logKey,
+		fields.Add(logger.Error(err)).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Field{"biz", req.Biz}).
+			Add(logger.Int[int64]("bizId", req.BizId)).
+			Add(logger.Int[int64]("parentId", req.ParentId)).
+			Add(logger.Int[int64]("userId", claims.UserID))...)
+	return
+}
+
+// @func: Delete
+// @date: 2024-01-08 22:41:55
+// @brief: 评论模块-删除评论, 连带删除其下的回复
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+func (c *CommentHandler) Delete(ctx *gin.Context) {
+	type DeleteReq struct {
+		Id int64 `json:"id"`
+	}
+
+	var req DeleteReq
+	var err error
+	var claims ijwt.UserClaims
+	logKey := logger.CommentLogMsgKey[logger.LOG_COMMENT_DELETE]
+	fields := logger.Fields{}
+
+	err = ctx.Bind(&req)
+	if err != nil {
+		fields = fields.Add(logger.String("请求参数解析错误"))
+		goto ERR
+	}
+
+	claims = ctx.MustGet("user_token").(ijwt.UserClaims)
+
+	err = c.svc.DeleteComment(ctx, req.Id, claims.UserID)
+
+	switch err {
+	case nil:
+		c.l.INFO(logKey, fields.Add(logger.String("删除评论成功")).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Int[int64]("commentId", req.Id)).
+			Add(logger.Int[int64]("userId", claims.UserID))...)
+
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "删除成功",
+		})
+		return
+	case service.ErrInvalidUpdate:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "非法操作",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "系统错误",
+		})
+	}
+
+ERR:
+	c.l.ERROR(logKey,
+		fields.Add(logger.Error(err)).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Int[int64]("commentId", req.Id)).
+			Add(logger.Int[int64]("userId", claims.UserID))...)
+	return
+}
+
+// @func: List
+// @date: 2024-01-08 22:50:32
+// @brief: 评论模块-游标分页查询根评论
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+func (c *CommentHandler) List(ctx *gin.Context) {
+	type ListReq struct {
+		Biz   string `form:"biz"`
+		BizId int64  `form:"bizId"`
+		// 上一页最后一条根评论ID
+		Cursor int64 `form:"cursor"`
+		Limit  int   `form:"limit"`
+	}
+
+	var req ListReq
+	var err error
+	var cmts []domain.Comment
+	logKey := logger.CommentLogMsgKey[logger.LOG_COMMENT_LIST]
+	fields := logger.Fields{}
+
+	err = ctx.BindQuery(&req)
+	if err != nil {
+		fields = fields.Add(logger.String("请求参数解析错误"))
+		goto ERR
+	}
+
+	if req.Limit <= 0 || req.Limit > commentMaxLimit {
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "参数错误",
+		})
+		return
+	}
+
+	cmts, err = c.svc.GetCommentList(ctx, req.Biz, req.BizId, req.Cursor, req.Limit)
+
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg:  "查询成功",
+			Data: ConvertsCommentVos(cmts),
+		})
+		return
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "系统错误",
+		})
+	}
+
+ERR:
+	c.l.ERROR(logKey,
+		fields.Add(logger.Error(err)).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Field{"biz", req.Biz}).
+			Add(logger.Int[int64]("bizId", req.BizId)).
+			Add(logger.Int[int64]("cursor", req.Cursor))...)
+	return
+}
+
+// @func: Replies
+// @date: 2024-01-08 22:58:10
+// @brief: 评论模块-游标分页查询更多回复
+// @author: Kewin Li
+// @receiver c
+// @param ctx
+func (c *CommentHandler) Replies(ctx *gin.Context) {
+	type RepliesReq struct {
+		RootId int64 `form:"rootId"`
+		// 上一页最后一条回复ID
+		Cursor int64 `form:"cursor"`
+		Limit  int   `form:"limit"`
+	}
+
+	var req RepliesReq
+	var err error
+	var cmts []domain.Comment
+	logKey := logger.CommentLogMsgKey[logger.LOG_COMMENT_REPLIES]
+	fields := logger.Fields{}
+
+	err = ctx.BindQuery(&req)
+	if err != nil {
+		fields = fields.Add(logger.String("请求参数解析错误"))
+		goto ERR
+	}
+
+	if req.Limit <= 0 || req.Limit > commentMaxLimit {
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "参数错误",
+		})
+		return
+	}
+
+	cmts, err = c.svc.GetMoreReplies(ctx, req.RootId, req.Cursor, req.Limit)
+
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg:  "查询成功",
+			Data: ConvertsCommentVos(cmts),
+		})
+		return
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "系统错误",
+		})
+	}
+
+ERR:
+	c.l.ERROR(logKey,
+		fields.Add(logger.Error(err)).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Int[int64]("rootId", req.RootId)).
+			Add(logger.Int[int64]("cursor", req.Cursor))...)
+	return
+}
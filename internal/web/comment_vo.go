@@ -0,0 +1,58 @@
+package web
+
+import (
+	"kitbook/internal/domain"
+	"time"
+)
+
+// CommentVo
+// @Description: 前端响应-评论
+type CommentVo struct {
+	Id      int64  `json:"id"`
+	Uid     int64  `json:"uid"`
+	Biz     string `json:"biz"`
+	BizId   int64  `json:"bizId"`
+	Content string `json:"content"`
+	// 根评论ID, 0表示自身就是根评论
+	RootId int64 `json:"rootId"`
+	// 回复的评论ID
+	ParentId int64  `json:"parentId"`
+	Ctime    string `json:"ctime"`
+
+	// 根评论预加载的回复
+	Replies []CommentVo `json:"replies,omitempty"`
+}
+
+func ConvertsCommentVo(c *domain.Comment) CommentVo {
+	vo := CommentVo{
+		Id:      c.Id,
+		Uid:     c.Commenter.Id,
+		Biz:     c.Biz,
+		BizId:   c.BizId,
+		Content: c.Content,
+		Ctime:   c.Ctime.Format(time.DateTime),
+	}
+
+	if c.RootComment != nil {
+		vo.RootId = c.RootComment.Id
+	}
+
+	if c.ParentComment != nil {
+		vo.ParentId = c.ParentComment.Id
+	}
+
+	if len(c.Children) > 0 {
+		vo.Replies = ConvertsCommentVos(c.Children)
+	}
+
+	return vo
+}
+
+func ConvertsCommentVos(cs []domain.Comment) []CommentVo {
+	vos := make([]CommentVo, 0, len(cs))
+	for _, c := range cs {
+		vos = append(vos, ConvertsCommentVo(&c))
+	}
+
+	return vos
+}
@@ -0,0 +1,103 @@
+// Package web
+// @Description: feed流模块
+package web
+
+import (
+	"github.com/gin-gonic/gin"
+	"kitbook/internal/domain"
+	"kitbook/internal/service"
+	ijwt "kitbook/internal/web/jwt"
+	"kitbook/pkg/logger"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// feed流单页最大条数
+const feedMaxLimit = 100
+
+type FeedHandler struct {
+	svc service.FeedService
+	l   logger.Logger
+}
+
+func NewFeedHandler(svc service.FeedService, l logger.Logger) *FeedHandler {
+	return &FeedHandler{
+		svc: svc,
+		l:   l,
+	}
+}
+
+func (f *FeedHandler) RegisterRoutes(server *gin.Engine) {
+	// /feed?cursor=?&limit=?  关注作者发表的帖子, 按发表时间倒序分页
+	server.GET("/feed", f.List)
+}
+
+// @func: List
+// @date: 2024-01-13 17:05:39
+// @brief: feed流-分页查询
+// @author: Kewin Li
+// @receiver f
+// @param ctx
+func (f *FeedHandler) List(ctx *gin.Context) {
+	var err error
+	var claims ijwt.UserClaims
+	var events []domain.FeedEvent
+	var cursor int64
+	var limit int
+	logKey := logger.FeedLogMsgKey[logger.LOG_FEED_LIST]
+	fields := logger.Fields{}
+
+	cursorStr := ctx.DefaultQuery("cursor", "0")
+	limitStr := ctx.DefaultQuery("limit", "10")
+
+	claims = ctx.MustGet("user_token").(ijwt.UserClaims)
+
+	cursor, err = strconv.ParseInt(cursorStr, 10, 64)
+	if err != nil {
+		fields = fields.Add(logger.String("请求参数解析错误")).
+			Add(logger.Field{"cursor", cursorStr})
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "系统错误",
+		})
+		goto ERR
+	}
+
+	limit, err = strconv.Atoi(limitStr)
+	if err != nil || limit <= 0 || limit > feedMaxLimit {
+		fields = fields.Add(logger.String("请求参数非法")).
+			Add(logger.Field{"limit", limitStr})
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "参数错误",
+		})
+		goto ERR
+	}
+
+	// 首页查询
+	if cursor <= 0 {
+		cursor = time.Now().UnixMilli()
+	}
+
+	events, err = f.svc.GetFeed(ctx, claims.UserID, time.UnixMilli(cursor), limit)
+
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg:  "查询成功",
+			Data: ConvertsFeedVos(events),
+		})
+		return
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "系统错误",
+		})
+	}
+
+ERR:
+	f.l.ERROR(logKey,
+		fields.Add(logger.Error(err)).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Int[int64]("cursor", cursor)).
+			Add(logger.Int[int64]("userId", claims.UserID))...)
+	return
+}
@@ -0,0 +1,31 @@
+package web
+
+import (
+	"kitbook/internal/domain"
+	"time"
+)
+
+// FeedVo
+// @Description: 前端响应-feed流
+type FeedVo struct {
+	ArtId    int64 `json:"artId"`
+	AuthorId int64 `json:"authorId"`
+	// 帖子发表时间
+	Ctime string `json:"ctime"`
+	// 下一页查询的时间游标
+	Cursor int64 `json:"cursor"`
+}
+
+func ConvertsFeedVos(events []domain.FeedEvent) []FeedVo {
+	vos := make([]FeedVo, 0, len(events))
+	for _, event := range events {
+		vos = append(vos, FeedVo{
+			ArtId:    event.ArtId,
+			AuthorId: event.AuthorId,
+			Ctime:    event.Ctime.Format(time.DateTime),
+			Cursor:   event.Ctime.UnixMilli(),
+		})
+	}
+
+	return vos
+}
@@ -0,0 +1,307 @@
+// Package web
+// @Description: 关注模块
+package web
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"kitbook/internal/domain"
+	"kitbook/internal/service"
+	ijwt "kitbook/internal/web/jwt"
+	"kitbook/pkg/logger"
+	"net/http"
+)
+
+// 关注/粉丝列表单页最大条数
+const followMaxLimit = 100
+
+type FollowHandler struct {
+	svc service.FollowService
+	l   logger.Logger
+}
+
+func NewFollowHandler(svc service.FollowService, l logger.Logger) *FollowHandler {
+	return &FollowHandler{
+		svc: svc,
+		l:   l,
+	}
+}
+
+func (f *FollowHandler) RegisterRoutes(server *gin.Engine) {
+	group := server.Group("/follow")
+	group.POST("/follow", f.Follow)       // 关注
+	group.POST("/cancel", f.CancelFollow) // 取消关注
+
+	// /followers?uid=?&cursor=?&limit=?  粉丝列表, uid为空时查询自己
+	group.GET("/followers", f.Followers)
+	// /followees?uid=?&cursor=?&limit=?  关注列表, uid为空时查询自己
+	group.GET("/followees", f.Followees)
+	// /statics?uid=?  粉丝数、关注数
+	group.GET("/statics", f.Statics)
+}
+
+// @func: Follow
+// @date: 2024-01-12 22:20:15
+// @brief: 关注模块-关注
+// @author: Kewin Li
+// @receiver f
+// @param ctx
+func (f *FollowHandler) Follow(ctx *gin.Context) {
+	type FollowReq struct {
+		Followee int64 `json:"followee"`
+	}
+
+	var req FollowReq
+	var err error
+	var claims ijwt.UserClaims
+	logKey := logger.FollowLogMsgKey[logger.LOG_FOLLOW_FOLLOW]
+	fields := logger.Fields{}
+
+	err = ctx.Bind(&req)
+	if err != nil {
+		fields = fields.Add(logger.String("请求参数解析错误"))
+		goto ERR
+	}
+
+	claims = ctx.MustGet("user_token").(ijwt.UserClaims)
+
+	if req.Followee <= 0 {
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "参数错误",
+		})
+		return
+	}
+
+	err = f.svc.Follow(ctx, claims.UserID, req.Followee)
+
+	switch err {
+	case nil:
+		f.l.INFO(logKey, fields.Add(logger.String("关注成功")).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Int[int64]("follower", claims.UserID)).
+			Add(logger.Int[int64]("followee", req.Followee))...)
+
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "关注成功",
+		})
+		return
+	case service.ErrFollowSelf:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "不能关注自己",
+		})
+	case service.ErrRepeatFollow:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "已经关注过了",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "系统错误",
+		})
+	}
+
+ERR:
+	f.l.ERROR(logKey,
+		fields.Add(logger.Error(err)).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Int[int64]("follower", claims.UserID)).
+			Add(logger.Int[int64]("followee", req.Followee))...)
+	return
+}
+
+// @func: CancelFollow
+// @date: 2024-01-12 22:26:41
+// @brief: 关注模块-取消关注
+// @author: Kewin Li
+// @receiver f
+// @param ctx
+func (f *FollowHandler) CancelFollow(ctx *gin.Context) {
+	type CancelReq struct {
+		Followee int64 `json:"followee"`
+	}
+
+	var req CancelReq
+	var err error
+	var claims ijwt.UserClaims
+	logKey := logger.FollowLogMsgKey[logger.LOG_FOLLOW_CANCEL]
+	fields := logger.Fields{}
+
+	err = ctx.Bind(&req)
+	if err != nil {
+		fields = fields.Add(logger.String("请求参数解析错误"))
+		goto ERR
+	}
+
+	claims = ctx.MustGet("user_token").(ijwt.UserClaims)
+
+	err = f.svc.CancelFollow(ctx, claims.UserID, req.Followee)
+
+	switch err {
+	case nil:
+		f.l.INFO(logKey, fields.Add(logger.String("取消关注成功")).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Int[int64]("follower", claims.UserID)).
+			Add(logger.Int[int64]("followee", req.Followee))...)
+
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "取消关注成功",
+		})
+		return
+	case service.ErrRepeatFollow:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "未关注该用户",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "系统错误",
+		})
+	}
+
+ERR:
+	f.l.ERROR(logKey,
+		fields.Add(logger.Error(err)).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Int[int64]("follower", claims.UserID)).
+			Add(logger.Int[int64]("followee", req.Followee))...)
+	return
+}
+
+// FollowListReq
+// @Description: 关注/粉丝列表请求参数
+type FollowListReq struct {
+	Uid int64 `form:"uid"`
+	// 上一页最后一条关注关系ID
+	Cursor int64 `form:"cursor"`
+	Limit  int   `form:"limit"`
+}
+
+// @func: Followers
+// @date: 2024-01-12 22:33:08
+// @brief: 关注模块-游标分页查询粉丝列表
+// @author: Kewin Li
+// @receiver f
+// @param ctx
+func (f *FollowHandler) Followers(ctx *gin.Context) {
+	f.list(ctx, logger.LOG_FOLLOW_FOLLOWERS, f.svc.GetFollowers)
+}
+
+// @func: Followees
+// @date: 2024-01-12 22:33:52
+// @brief: 关注模块-游标分页查询关注列表
+// @author: Kewin Li
+// @receiver f
+// @param ctx
+func (f *FollowHandler) Followees(ctx *gin.Context) {
+	f.list(ctx, logger.LOG_FOLLOW_FOLLOWEES, f.svc.GetFollowees)
+}
+
+// @func: list
+// @date: 2024-01-12 22:36:27
+// @brief: 关注模块-关注/粉丝列表的通用处理
+// @author: Kewin Li
+// @receiver f
+// @param ctx
+// @param key
+// @param query
+func (f *FollowHandler) list(ctx *gin.Context, key int,
+	query func(ctx context.Context, uid int64, maxId int64, limit int) ([]domain.FollowRelation, error)) {
+
+	var req FollowListReq
+	var err error
+	var claims ijwt.UserClaims
+	var relations []domain.FollowRelation
+	logKey := logger.FollowLogMsgKey[key]
+	fields := logger.Fields{}
+
+	err = ctx.BindQuery(&req)
+	if err != nil {
+		fields = fields.Add(logger.String("请求参数解析错误"))
+		goto ERR
+	}
+
+	if req.Limit <= 0 || req.Limit > followMaxLimit {
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "参数错误",
+		})
+		return
+	}
+
+	claims = ctx.MustGet("user_token").(ijwt.UserClaims)
+	if req.Uid <= 0 {
+		req.Uid = claims.UserID
+	}
+
+	relations, err = query(ctx, req.Uid, req.Cursor, req.Limit)
+
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg:  "查询成功",
+			Data: ConvertsFollowVos(relations),
+		})
+		return
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "系统错误",
+		})
+	}
+
+ERR:
+	f.l.ERROR(logKey,
+		fields.Add(logger.Error(err)).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Int[int64]("uid", req.Uid)).
+			Add(logger.Int[int64]("cursor", req.Cursor))...)
+	return
+}
+
+// @func: Statics
+// @date: 2024-01-12 22:42:19
+// @brief: 关注模块-查询粉丝数、关注数
+// @author: Kewin Li
+// @receiver f
+// @param ctx
+func (f *FollowHandler) Statics(ctx *gin.Context) {
+	type StaticsReq struct {
+		Uid int64 `form:"uid"`
+	}
+
+	var req StaticsReq
+	var err error
+	var claims ijwt.UserClaims
+	var statics domain.FollowStatics
+	logKey := logger.FollowLogMsgKey[logger.LOG_FOLLOW_STATICS]
+	fields := logger.Fields{}
+
+	err = ctx.BindQuery(&req)
+	if err != nil {
+		fields = fields.Add(logger.String("请求参数解析错误"))
+		goto ERR
+	}
+
+	claims = ctx.MustGet("user_token").(ijwt.UserClaims)
+	if req.Uid <= 0 {
+		req.Uid = claims.UserID
+	}
+
+	statics, err = f.svc.GetStatics(ctx, req.Uid)
+
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg:  "查询成功",
+			Data: ConvertsFollowStaticsVo(&statics),
+		})
+		return
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "系统错误",
+		})
+	}
+
+ERR:
+	f.l.ERROR(logKey,
+		fields.Add(logger.Error(err)).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Int[int64]("uid", req.Uid))...)
+	return
+}
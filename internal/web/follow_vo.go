@@ -0,0 +1,46 @@
+package web
+
+import (
+	"kitbook/internal/domain"
+	"time"
+)
+
+// FollowVo
+// @Description: 前端响应-关注关系
+type FollowVo struct {
+	// 关注关系ID, 作为下一页的游标
+	Id       int64  `json:"id"`
+	Follower int64  `json:"follower"`
+	Followee int64  `json:"followee"`
+	Ctime    string `json:"ctime"`
+}
+
+// FollowStaticsVo
+// @Description: 前端响应-粉丝数、关注数
+type FollowStaticsVo struct {
+	Uid       int64 `json:"uid"`
+	Followers int64 `json:"followers"`
+	Followees int64 `json:"followees"`
+}
+
+func ConvertsFollowVos(relations []domain.FollowRelation) []FollowVo {
+	vos := make([]FollowVo, 0, len(relations))
+	for _, relation := range relations {
+		vos = append(vos, FollowVo{
+			Id:       relation.Id,
+			Follower: relation.Follower,
+			Followee: relation.Followee,
+			Ctime:    relation.Ctime.Format(time.DateTime),
+		})
+	}
+
+	return vos
+}
+
+func ConvertsFollowStaticsVo(statics *domain.FollowStatics) FollowStaticsVo {
+	return FollowStaticsVo{
+		Uid:       statics.Uid,
+		Followers: statics.Followers,
+		Followees: statics.Followees,
+	}
+}
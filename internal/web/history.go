@@ -0,0 +1,183 @@
+// Package web
+// @Description: 用户模块-浏览记录
+package web
+
+import (
+	"github.com/gin-gonic/gin"
+	"kitbook/internal/domain"
+	"kitbook/internal/service"
+	ijwt "kitbook/internal/web/jwt"
+	"kitbook/pkg/logger"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// 浏览记录单页最大条数
+const historyMaxLimit = 100
+
+type HistoryHandler struct {
+	svc service.HistoryService
+	l   logger.Logger
+}
+
+func NewHistoryHandler(svc service.HistoryService, l logger.Logger) *HistoryHandler {
+	return &HistoryHandler{
+		svc: svc,
+		l:   l,
+	}
+}
+
+func (h *HistoryHandler) RegisterRoutes(server *gin.Engine) {
+	group := server.Group("/users/history")
+	// /users/history?biz=?&cursor=?&cursorId=?&limit=?  按浏览时间倒序分页
+	group.GET("", h.List)
+	// 清空浏览记录
+	group.POST("/clear", h.Clear)
+}
+
+// @func: List
+// @date: 2024-01-06 16:35:10
+// @brief: 浏览记录-分页查询
+// @author: Kewin Li
+// @receiver h
+// @param ctx
+func (h *HistoryHandler) List(ctx *gin.Context) {
+	var err error
+	var claims ijwt.UserClaims
+	var records []domain.HistoryRecord
+	var cursor int64
+	var cursorId int64
+	var limit int
+	logKey := logger.UserLogMsgKey[logger.LOG_USER_HISTORY]
+	fields := logger.Fields{}
+
+	biz := ctx.Query("biz")
+	cursorStr := ctx.DefaultQuery("cursor", "0")
+	cursorIdStr := ctx.DefaultQuery("cursorId", "0")
+	limitStr := ctx.DefaultQuery("limit", "10")
+
+	claims = ctx.MustGet("user_token").(ijwt.UserClaims)
+
+	cursor, err = strconv.ParseInt(cursorStr, 10, 64)
+	if err != nil {
+		fields = fields.Add(logger.String("请求参数解析错误")).
+			Add(logger.Field{"cursor", cursorStr})
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "系统错误",
+		})
+		goto ERR
+	}
+
+	cursorId, err = strconv.ParseInt(cursorIdStr, 10, 64)
+	if err != nil {
+		fields = fields.Add(logger.String("请求参数解析错误")).
+			Add(logger.Field{"cursorId", cursorIdStr})
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "系统错误",
+		})
+		goto ERR
+	}
+
+	limit, err = strconv.Atoi(limitStr)
+	if err != nil || limit <= 0 || limit > historyMaxLimit {
+		fields = fields.Add(logger.String("请求参数非法")).
+			Add(logger.Field{"limit", limitStr})
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "参数错误",
+		})
+		goto ERR
+	}
+
+	// 首页查询
+	if cursor <= 0 {
+		cursor = time.Now().UnixMilli()
+	}
+	// 未带ID游标时包含游标时间点上的全部记录
+	if cursorId <= 0 {
+		cursorId = math.MaxInt64
+	}
+
+	records, err = h.svc.GetHistory(ctx, claims.UserID, biz, time.UnixMilli(cursor), cursorId, limit)
+
+	switch err {
+	case nil:
+		h.l.INFO(logKey, fields.Add(logger.String("浏览记录查询成功")).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Field{"biz", biz}).
+			Add(logger.Int[int64]("userId", claims.UserID))...)
+
+		ctx.JSON(http.StatusOK, Result{
+			Msg:  "查询成功",
+			Data: ConvertsHistoryVos(records),
+		})
+		return
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "系统错误",
+		})
+	}
+
+ERR:
+	h.l.ERROR(logKey,
+		fields.Add(logger.Error(err)).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Field{"biz", biz}).
+			Add(logger.Int[int64]("userId", claims.UserID))...)
+	return
+}
+
+// @func: Clear
+// @date: 2024-01-06 16:48:52
+// @brief: 浏览记录-清空
+// @author: Kewin Li
+// @receiver h
+// @param ctx
+func (h *HistoryHandler) Clear(ctx *gin.Context) {
+	type ClearReq struct {
+		// 为空时清空全部业务的浏览记录
+		Biz string `json:"biz"`
+	}
+
+	var req ClearReq
+	var err error
+	var claims ijwt.UserClaims
+	logKey := logger.UserLogMsgKey[logger.LOG_USER_HISTORY_CLEAR]
+	fields := logger.Fields{}
+
+	err = ctx.Bind(&req)
+	if err != nil {
+		fields = fields.Add(logger.String("请求参数解析错误"))
+		goto ERR
+	}
+
+	claims = ctx.MustGet("user_token").(ijwt.UserClaims)
+
+	err = h.svc.Clear(ctx, claims.UserID, req.Biz)
+
+	switch err {
+	case nil:
+		h.l.INFO(logKey, fields.Add(logger.String("浏览记录清空成功")).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Field{"biz", req.Biz}).
+			Add(logger.Int[int64]("userId", claims.UserID))...)
+
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "清空成功",
+		})
+		return
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "系统错误",
+		})
+	}
+
+ERR:
+	h.l.ERROR(logKey,
+		fields.Add(logger.Error(err)).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Field{"biz", req.Biz}).
+			Add(logger.Int[int64]("userId", claims.UserID))...)
+	return
+}
@@ -0,0 +1,136 @@
+// Package web
+// @Description: web层-浏览记录-单元测试
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"kitbook/internal/domain"
+	"kitbook/internal/service"
+	svcmocks "kitbook/internal/service/mocks"
+	ijwt "kitbook/internal/web/jwt"
+	"kitbook/pkg/logger"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// @func: TestHistoryHandler_List
+// @date: 2024-01-06 17:02:41
+// @brief: 浏览记录分页查询-单元测试
+// @author: Kewin Li
+// @param t
+func TestHistoryHandler_List(t *testing.T) {
+	utime := time.UnixMilli(1704531600000)
+
+	testCases := []struct {
+		name string
+
+		mock func(ctrl *gomock.Controller) service.HistoryService
+
+		url     string
+		wantRes Result
+	}{
+		{
+			name: "按游标查询帖子浏览记录成功",
+			mock: func(ctrl *gomock.Controller) service.HistoryService {
+				svc := svcmocks.NewMockHistoryService(ctrl)
+				svc.EXPECT().GetHistory(gomock.Any(), int64(123), "article", time.UnixMilli(1704531700000), int64(7), 2).
+					Return([]domain.HistoryRecord{
+						{
+							Id:     5,
+							BizId:  1,
+							Biz:    "article",
+							UserId: 123,
+							Utime:  utime,
+						},
+					}, nil)
+				return svc
+			},
+			url: "/users/history?biz=article&cursor=1704531700000&cursorId=7&limit=2",
+			wantRes: Result{
+				Msg: "查询成功",
+				Data: []any{
+					map[string]any{
+						"bizId":    float64(1),
+						"biz":      "article",
+						"utime":    utime.Format(time.DateTime),
+						"cursor":   float64(utime.UnixMilli()),
+						"cursorId": float64(5),
+					},
+				},
+			},
+		},
+		{
+			name: "未带ID游标, 包含游标时间点上的全部记录",
+			mock: func(ctrl *gomock.Controller) service.HistoryService {
+				svc := svcmocks.NewMockHistoryService(ctrl)
+				svc.EXPECT().GetHistory(gomock.Any(), int64(123), "article", time.UnixMilli(1704531700000), int64(math.MaxInt64), 2).
+					Return([]domain.HistoryRecord{}, nil)
+				return svc
+			},
+			url: "/users/history?biz=article&cursor=1704531700000&limit=2",
+			wantRes: Result{
+				Msg:  "查询成功",
+				Data: []any{},
+			},
+		},
+		{
+			name: "分页数量非法",
+			mock: func(ctrl *gomock.Controller) service.HistoryService {
+				return svcmocks.NewMockHistoryService(ctrl)
+			},
+			url: "/users/history?limit=1000",
+			wantRes: Result{
+				Msg: "参数错误",
+			},
+		},
+		{
+			name: "查询失败",
+			mock: func(ctrl *gomock.Controller) service.HistoryService {
+				svc := svcmocks.NewMockHistoryService(ctrl)
+				svc.EXPECT().GetHistory(gomock.Any(), int64(123), "", gomock.Any(), int64(math.MaxInt64), 10).
+					Return(nil, errors.New("数据库错误"))
+				return svc
+			},
+			url: "/users/history",
+			wantRes: Result{
+				Msg: "系统错误",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			hdl := NewHistoryHandler(tc.mock(ctrl), logger.NewNopLogger())
+
+			server := gin.Default()
+			server.Use(func(ctx *gin.Context) {
+				ctx.Set("user_token", ijwt.UserClaims{
+					UserID: 123,
+				})
+			})
+			hdl.RegisterRoutes(server)
+
+			req, err := http.NewRequest(http.MethodGet, tc.url, nil)
+			assert.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, req)
+			assert.Equal(t, http.StatusOK, recorder.Code)
+
+			var res Result
+			err = json.NewDecoder(recorder.Body).Decode(&res)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantRes, res)
+		})
+	}
+}
@@ -0,0 +1,790 @@
+// Package web
+// @Description: 任务调度管理模块
+package web
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"kitbook/internal/domain"
+	"kitbook/internal/job"
+	"kitbook/internal/service"
+	ijwt "kitbook/internal/web/jwt"
+	"kitbook/pkg/logger"
+	"net/http"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// 任务名称最大长度
+	jobNameMaxLen = 128
+	// 任务列表单页最大条数
+	jobListMaxLimit = 100
+	// 最大重试次数上限
+	jobMaxRetries = 10
+	// 最大分片数上限
+	jobMaxShards = 64
+	// 上游任务数上限
+	jobMaxUpstreams = 32
+	// 补跑次数上限
+	jobMaxMisfireCap = 100
+	// 亲和标签数上限及标签键值最大长度
+	jobMaxAffinity    = 8
+	jobAffinityMaxLen = 32
+)
+
+// 执行模式, 为空时默认单结点
+var jobModes = map[string]domain.JobMode{
+	"":          domain.JobModeSingle,
+	"single":    domain.JobModeSingle,
+	"broadcast": domain.JobModeBroadcast,
+	"sharding":  domain.JobModeSharding,
+}
+
+// 错过调度的处理策略, 为空时默认只执行一次
+var jobMisfirePolicies = map[string]domain.JobMisfirePolicy{
+	"":          domain.MisfireFireOnce,
+	"fire_once": domain.MisfireFireOnce,
+	"skip":      domain.MisfireSkip,
+	"fire_all":  domain.MisfireFireAll,
+}
+
+type JobHandler struct {
+	svc service.JobService
+	// 允许管理任务的用户
+	admins map[int64]struct{}
+	l      logger.Logger
+}
+
+func NewJobHandler(svc service.JobService, admins []int64, l logger.Logger) *JobHandler {
+	set := make(map[int64]struct{}, len(admins))
+	for _, uid := range admins {
+		set[uid] = struct{}{}
+	}
+
+	return &JobHandler{
+		svc:    svc,
+		admins: set,
+		l:      l,
+	}
+}
+
+func (j *JobHandler) RegisterRoutes(server *gin.Engine) {
+	group := server.Group("/jobs", j.CheckAdmin)
+	group.POST("/create", j.Create)   // 新建任务
+	group.POST("/update", j.Update)   // 修改cron表达式、执行器
+	group.POST("/delete", j.Delete)   // 删除任务
+	group.POST("/pause", j.Pause)     // 暂停调度
+	group.POST("/resume", j.Resume)   // 恢复调度
+	group.POST("/trigger", j.Trigger) // 立即触发一次
+
+	// /list?offset=?&limit=?
+	group.GET("/list", j.List)
+	// /executions?id=?&offset=?&limit=?  任务执行记录
+	group.GET("/executions", j.Executions)
+	// /dag?id=?  任务所在的依赖图及运行状态
+	group.GET("/dag", j.DAG)
+	// 在线调度结点的负载
+	group.GET("/nodes", j.Nodes)
+
+	// 远程任务执行完毕后回调, 使用执行时下发的token鉴权, 不需要登录
+	server.POST("/jobs/callback", j.Callback)
+}
+
+// @func: CheckAdmin
+// @date: 2024-01-16 16:02:11
+// @brief: 任务调度管理模块-仅管理员可操作
+// @author: Kewin Li
+// @receiver j
+// @param ctx
+func (j *JobHandler) CheckAdmin(ctx *gin.Context) {
+	val, _ := ctx.Get("user_token")
+	claims, ok := val.(ijwt.UserClaims)
+	if ok {
+		if _, ok = j.admins[claims.UserID]; ok {
+			return
+		}
+	}
+
+	ctx.AbortWithStatus(http.StatusForbidden)
+}
+
+// JobReq
+// @Description: 新建/修改任务请求参数
+type JobReq struct {
+	Id         int64  `json:"id"`
+	Name       string `json:"name"`
+	Expression string `json:"expression"`
+	// cron表达式所在时区, 如Asia/Shanghai
+	TimeZone string `json:"timeZone"`
+	Executor string `json:"executor"`
+	// 执行器配置, JSON格式
+	Cfg string `json:"cfg"`
+	// 重试策略, 重试间隔单位为秒
+	MaxRetries    int   `json:"maxRetries"`
+	RetryInterval int64 `json:"retryInterval"`
+	// 执行模式: single、broadcast、sharding, 分片模式需指定分片总数
+	Mode       string `json:"mode"`
+	ShardTotal int    `json:"shardTotal"`
+	// 上游任务ID, 所有上游在本周期内执行成功后才会调度
+	Upstreams []int64 `json:"upstreams"`
+	// 错过调度的处理策略: fire_once、skip、fire_all, fire_all需指定补跑次数上限
+	MisfirePolicy string `json:"misfirePolicy"`
+	MisfireCap    int    `json:"misfireCap"`
+	// 亲和标签, 只有包含全部标签的结点才会执行
+	Affinity map[string]string `json:"affinity"`
+}
+
+// @func: checkExecutorCfg
+// @date: 2024-01-17 15:05:30
+// @brief: 任务调度管理模块-校验执行器配置
+// @author: Kewin Li
+// @param req
+// @return bool
+func checkExecutorCfg(req *JobReq) bool {
+	switch req.Executor {
+	case "":
+		return false
+	case "http":
+		_, err := job.ParseHttpJobCfg(req.Cfg)
+		return err == nil
+	default:
+		return true
+	}
+}
+
+// @func: checkRetryPolicy
+// @date: 2024-01-17 11:02:40
+// @brief: 任务调度管理模块-校验重试策略
+// @author: Kewin Li
+// @param req
+// @return bool
+func checkRetryPolicy(req *JobReq) bool {
+	if req.MaxRetries < 0 || req.MaxRetries > jobMaxRetries || req.RetryInterval < 0 {
+		return false
+	}
+
+	// 需要重试时必须指定重试间隔
+	return req.MaxRetries == 0 || req.RetryInterval > 0
+}
+
+// @func: checkJobMode
+// @date: 2024-01-18 16:45:22
+// @brief: 任务调度管理模块-校验执行模式, 广播、分片任务不支持失败重试, 广播任务不支持依赖
+// @author: Kewin Li
+// @param req
+// @return bool
+func checkJobMode(req *JobReq) bool {
+	mode, ok := jobModes[req.Mode]
+	if !ok {
+		return false
+	}
+
+	switch mode {
+	case domain.JobModeSharding:
+		return req.ShardTotal > 0 && req.ShardTotal <= jobMaxShards && req.MaxRetries == 0
+	case domain.JobModeBroadcast:
+		// 广播任务由各结点按本地时钟执行, 不支持依赖
+		return req.ShardTotal == 0 && req.MaxRetries == 0 && len(req.Upstreams) == 0
+	default:
+		return req.ShardTotal == 0
+	}
+}
+
+// @func: checkMisfirePolicy
+// @date: 2024-01-19 15:05:48
+// @brief: 任务调度管理模块-校验错过策略, 仅单结点任务支持自定义
+// @author: Kewin Li
+// @param req
+// @return bool
+func checkMisfirePolicy(req *JobReq) bool {
+	policy, ok := jobMisfirePolicies[req.MisfirePolicy]
+	if !ok {
+		return false
+	}
+
+	if policy != domain.MisfireFireOnce && jobModes[req.Mode] != domain.JobModeSingle {
+		return false
+	}
+
+	if policy == domain.MisfireFireAll {
+		return req.MisfireCap > 0 && req.MisfireCap <= jobMaxMisfireCap
+	}
+
+	return req.MisfireCap == 0
+}
+
+// @func: checkAffinity
+// @date: 2024-01-20 11:20:18
+// @brief: 任务调度管理模块-校验亲和标签
+// @author: Kewin Li
+// @param req
+// @return bool
+func checkAffinity(req *JobReq) bool {
+	if len(req.Affinity) > jobMaxAffinity {
+		return false
+	}
+
+	for key, val := range req.Affinity {
+		if key == "" || utf8.RuneCountInString(key) > jobAffinityMaxLen ||
+			utf8.RuneCountInString(val) > jobAffinityMaxLen {
+			return false
+		}
+	}
+
+	return true
+}
+
+// @func: checkUpstreams
+// @date: 2024-01-19 11:20:36
+// @brief: 任务调度管理模块-校验上游任务ID并去重
+// @author: Kewin Li
+// @param req
+// @return bool
+func checkUpstreams(req *JobReq) bool {
+	if len(req.Upstreams) > jobMaxUpstreams {
+		return false
+	}
+
+	set := make(map[int64]struct{}, len(req.Upstreams))
+	ups := make([]int64, 0, len(req.Upstreams))
+	for _, up := range req.Upstreams {
+		if up <= 0 {
+			return false
+		}
+		if _, ok := set[up]; ok {
+			continue
+		}
+		set[up] = struct{}{}
+		ups = append(ups, up)
+	}
+
+	req.Upstreams = ups
+	return true
+}
+
+// @func: Create
+// @date: 2024-01-16 16:05:40
+// @brief: 任务调度管理模块-新建任务
+// @author: Kewin Li
+// @receiver j
+// @param ctx
+func (j *JobHandler) Create(ctx *gin.Context) {
+	var req JobReq
+	var err error
+	var id int64
+	logKey := logger.JobLogMsgKey[logger.LOG_JOB_CREATE]
+	fields := logger.Fields{}
+
+	err = ctx.Bind(&req)
+	if err != nil {
+		fields = fields.Add(logger.String("请求参数解析错误"))
+		goto ERR
+	}
+
+	if nameLen := utf8.RuneCountInString(req.Name); nameLen <= 0 || nameLen > jobNameMaxLen ||
+		!checkExecutorCfg(&req) || !checkRetryPolicy(&req) || !checkJobMode(&req) || !checkMisfirePolicy(&req) || !checkUpstreams(&req) || !checkAffinity(&req) {
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "参数错误",
+		})
+		return
+	}
+
+	id, err = j.svc.Create(ctx, domain.Job{
+		Name:          req.Name,
+		Expression:    req.Expression,
+		TimeZone:      req.TimeZone,
+		ExecutorName:  req.Executor,
+		Cfg:           req.Cfg,
+		MaxRetries:    req.MaxRetries,
+		RetryInterval: time.Duration(req.RetryInterval) * time.Second,
+		Mode:          jobModes[req.Mode],
+		ShardTotal:    req.ShardTotal,
+		Upstreams:     req.Upstreams,
+		MisfirePolicy: jobMisfirePolicies[req.MisfirePolicy],
+		MisfireCap:    req.MisfireCap,
+		Affinity:      req.Affinity,
+	})
+
+	switch err {
+	case nil:
+		j.l.INFO(logKey, fields.Add(logger.String("新建任务成功")).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Int[int64]("jobId", id)).
+			Add(logger.Field{"name", req.Name})...)
+
+		ctx.JSON(http.StatusOK, Result{
+			Msg:  "新建成功",
+			Data: id,
+		})
+		return
+	case service.ErrInvalidJobExpression:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "cron表达式或时区不合法",
+		})
+		return
+	case service.ErrInvalidJobUpstream:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "上游任务不存在",
+		})
+		return
+	case service.ErrJobDependencyCycle:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "任务依赖存在环",
+		})
+		return
+	case service.ErrDuplicateJob:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "任务名称已存在",
+		})
+		return
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "系统错误",
+		})
+	}
+
+ERR:
+	j.l.ERROR(logKey,
+		fields.Add(logger.Error(err)).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Field{"name", req.Name})...)
+	return
+}
+
+// @func: Update
+// @date: 2024-01-16 16:12:05
+// @brief: 任务调度管理模块-修改cron表达式、执行器、重试策略、执行模式、错过策略、上游依赖、亲和标签
+// @author: Kewin Li
+// @receiver j
+// @param ctx
+func (j *JobHandler) Update(ctx *gin.Context) {
+	var req JobReq
+	var err error
+	logKey := logger.JobLogMsgKey[logger.LOG_JOB_UPDATE]
+	fields := logger.Fields{}
+
+	err = ctx.Bind(&req)
+	if err != nil {
+		fields = fields.Add(logger.String("请求参数解析错误"))
+		goto ERR
+	}
+
+	if req.Id <= 0 || !checkExecutorCfg(&req) || !checkRetryPolicy(&req) || !checkJobMode(&req) || !checkMisfirePolicy(&req) || !checkUpstreams(&req) || !checkAffinity(&req) {
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "参数错误",
+		})
+		return
+	}
+
+	err = j.svc.Update(ctx, domain.Job{
+		Id:            req.Id,
+		Expression:    req.Expression,
+		TimeZone:      req.TimeZone,
+		ExecutorName:  req.Executor,
+		Cfg:           req.Cfg,
+		MaxRetries:    req.MaxRetries,
+		RetryInterval: time.Duration(req.RetryInterval) * time.Second,
+		Mode:          jobModes[req.Mode],
+		ShardTotal:    req.ShardTotal,
+		Upstreams:     req.Upstreams,
+		MisfirePolicy: jobMisfirePolicies[req.MisfirePolicy],
+		MisfireCap:    req.MisfireCap,
+		Affinity:      req.Affinity,
+	})
+
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "修改成功",
+		})
+		return
+	case service.ErrInvalidJobExpression:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "cron表达式或时区不合法",
+		})
+		return
+	case service.ErrInvalidJobUpstream:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "上游任务不存在",
+		})
+		return
+	case service.ErrJobDependencyCycle:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "任务依赖存在环",
+		})
+		return
+	case service.ErrJobNotFound:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "任务不存在",
+		})
+		return
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "系统错误",
+		})
+	}
+
+ERR:
+	j.l.ERROR(logKey,
+		fields.Add(logger.Error(err)).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Int[int64]("jobId", req.Id))...)
+	return
+}
+
+// @func: Delete
+// @date: 2024-01-16 16:15:32
+// @brief: 任务调度管理模块-删除任务
+// @author: Kewin Li
+// @receiver j
+// @param ctx
+func (j *JobHandler) Delete(ctx *gin.Context) {
+	j.operate(ctx, logger.JobLogMsgKey[logger.LOG_JOB_DELETE], "删除成功", j.svc.Delete)
+}
+
+// @func: Pause
+// @date: 2024-01-16 16:16:04
+// @brief: 任务调度管理模块-暂停调度
+// @author: Kewin Li
+// @receiver j
+// @param ctx
+func (j *JobHandler) Pause(ctx *gin.Context) {
+	j.operate(ctx, logger.JobLogMsgKey[logger.LOG_JOB_PAUSE], "暂停成功", j.svc.Pause)
+}
+
+// @func: Resume
+// @date: 2024-01-16 16:16:37
+// @brief: 任务调度管理模块-恢复调度
+// @author: Kewin Li
+// @receiver j
+// @param ctx
+func (j *JobHandler) Resume(ctx *gin.Context) {
+	j.operate(ctx, logger.JobLogMsgKey[logger.LOG_JOB_RESUME], "恢复成功", j.svc.Resume)
+}
+
+// @func: Trigger
+// @date: 2024-01-16 16:17:10
+// @brief: 任务调度管理模块-立即触发一次调度
+// @author: Kewin Li
+// @receiver j
+// @param ctx
+func (j *JobHandler) Trigger(ctx *gin.Context) {
+	j.operate(ctx, logger.JobLogMsgKey[logger.LOG_JOB_TRIGGER], "触发成功", j.svc.Trigger)
+}
+
+// @func: operate
+// @date: 2024-01-16 16:18:45
+// @brief: 任务调度管理模块-按任务ID操作的公共流程
+// @author: Kewin Li
+// @receiver j
+// @param ctx
+// @param logKey
+// @param okMsg
+// @param fn
+func (j *JobHandler) operate(ctx *gin.Context, logKey string, okMsg string,
+	fn func(ctx context.Context, jobId int64) error) {
+	type Req struct {
+		Id int64 `json:"id"`
+	}
+	var req Req
+	var err error
+	fields := logger.Fields{}
+
+	err = ctx.Bind(&req)
+	if err != nil {
+		fields = fields.Add(logger.String("请求参数解析错误"))
+		goto ERR
+	}
+
+	err = fn(ctx, req.Id)
+
+	switch err {
+	case nil:
+		j.l.INFO(logKey, fields.Add(logger.String(okMsg)).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Int[int64]("jobId", req.Id))...)
+
+		ctx.JSON(http.StatusOK, Result{
+			Msg: okMsg,
+		})
+		return
+	case service.ErrJobNotFound:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "任务不存在",
+		})
+		return
+	case service.ErrJobStatusMismatch:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "任务当前状态不允许该操作",
+		})
+		return
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "系统错误",
+		})
+	}
+
+ERR:
+	j.l.ERROR(logKey,
+		fields.Add(logger.Error(err)).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Int[int64]("jobId", req.Id))...)
+	return
+}
+
+// @func: List
+// @date: 2024-01-16 16:25:18
+// @brief: 任务调度管理模块-任务列表, 包含下一次调度时间和最近一次执行结果
+// @author: Kewin Li
+// @receiver j
+// @param ctx
+func (j *JobHandler) List(ctx *gin.Context) {
+	type Req struct {
+		Offset int `form:"offset"`
+		Limit  int `form:"limit"`
+	}
+	var req Req
+	var err error
+	var jobs []domain.Job
+	logKey := logger.JobLogMsgKey[logger.LOG_JOB_LIST]
+	fields := logger.Fields{}
+
+	err = ctx.BindQuery(&req)
+	if err != nil {
+		fields = fields.Add(logger.String("请求参数解析错误"))
+		goto ERR
+	}
+
+	if req.Offset < 0 || req.Limit <= 0 || req.Limit > jobListMaxLimit {
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "参数错误",
+		})
+		return
+	}
+
+	jobs, err = j.svc.List(ctx, req.Offset, req.Limit)
+
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg:  "查询成功",
+			Data: ConvertJobVos(jobs),
+		})
+		return
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "系统错误",
+		})
+	}
+
+ERR:
+	j.l.ERROR(logKey,
+		fields.Add(logger.Error(err)).
+			Add(logger.Field{"IP", ctx.ClientIP()})...)
+	return
+}
+
+// @func: Executions
+// @date: 2024-01-17 11:10:35
+// @brief: 任务调度管理模块-任务执行记录
+// @author: Kewin Li
+// @receiver j
+// @param ctx
+func (j *JobHandler) Executions(ctx *gin.Context) {
+	type Req struct {
+		Id     int64 `form:"id"`
+		Offset int   `form:"offset"`
+		Limit  int   `form:"limit"`
+	}
+	var req Req
+	var err error
+	var execs []domain.JobExecution
+	logKey := logger.JobLogMsgKey[logger.LOG_JOB_EXECUTIONS]
+	fields := logger.Fields{}
+
+	err = ctx.BindQuery(&req)
+	if err != nil {
+		fields = fields.Add(logger.String("请求参数解析错误"))
+		goto ERR
+	}
+
+	if req.Id <= 0 || req.Offset < 0 || req.Limit <= 0 || req.Limit > jobListMaxLimit {
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "参数错误",
+		})
+		return
+	}
+
+	execs, err = j.svc.ListExecutions(ctx, req.Id, req.Offset, req.Limit)
+
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg:  "查询成功",
+			Data: ConvertJobExecutionVos(execs),
+		})
+		return
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "系统错误",
+		})
+	}
+
+ERR:
+	j.l.ERROR(logKey,
+		fields.Add(logger.Error(err)).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Int[int64]("jobId", req.Id))...)
+	return
+}
+
+// @func: DAG
+// @date: 2024-01-19 11:30:52
+// @brief: 任务调度管理模块-任务依赖图, 包含各任务的运行状态及依赖是否满足
+// @author: Kewin Li
+// @receiver j
+// @param ctx
+func (j *JobHandler) DAG(ctx *gin.Context) {
+	type Req struct {
+		Id int64 `form:"id"`
+	}
+	var req Req
+	var err error
+	var jobs []domain.Job
+	logKey := logger.JobLogMsgKey[logger.LOG_JOB_DAG]
+	fields := logger.Fields{}
+
+	err = ctx.BindQuery(&req)
+	if err != nil {
+		fields = fields.Add(logger.String("请求参数解析错误"))
+		goto ERR
+	}
+
+	if req.Id <= 0 {
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "参数错误",
+		})
+		return
+	}
+
+	jobs, err = j.svc.DAG(ctx, req.Id)
+
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg:  "查询成功",
+			Data: ConvertJobDAGVo(jobs),
+		})
+		return
+	case service.ErrJobNotFound:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "任务不存在",
+		})
+		return
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "系统错误",
+		})
+	}
+
+ERR:
+	j.l.ERROR(logKey,
+		fields.Add(logger.Error(err)).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Int[int64]("jobId", req.Id))...)
+	return
+}
+
+// @func: Nodes
+// @date: 2024-01-20 11:25:40
+// @brief: 任务调度管理模块-在线调度结点的负载, 负载高的在前
+// @author: Kewin Li
+// @receiver j
+// @param ctx
+func (j *JobHandler) Nodes(ctx *gin.Context) {
+	var err error
+	var nodes []domain.JobNode
+	logKey := logger.JobLogMsgKey[logger.LOG_JOB_NODES]
+	fields := logger.Fields{}
+
+	nodes, err = j.svc.ListNodes(ctx)
+
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg:  "查询成功",
+			Data: ConvertJobNodeVos(nodes),
+		})
+		return
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "系统错误",
+		})
+	}
+
+	j.l.ERROR(logKey,
+		fields.Add(logger.Error(err)).
+			Add(logger.Field{"IP", ctx.ClientIP()})...)
+	return
+}
+
+// @func: Callback
+// @date: 2024-01-17 15:12:26
+// @brief: 任务调度管理模块-远程任务上报执行结果
+// @author: Kewin Li
+// @receiver j
+// @param ctx
+func (j *JobHandler) Callback(ctx *gin.Context) {
+	type Req struct {
+		Token   string `json:"token"`
+		Success bool   `json:"success"`
+		Error   string `json:"error"`
+	}
+	var req Req
+	var err error
+	logKey := logger.JobLogMsgKey[logger.LOG_JOB_CALLBACK]
+	fields := logger.Fields{}
+
+	err = ctx.Bind(&req)
+	if err != nil {
+		fields = fields.Add(logger.String("请求参数解析错误"))
+		goto ERR
+	}
+
+	if req.Token == "" {
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "参数错误",
+		})
+		return
+	}
+
+	err = j.svc.Callback(ctx, domain.JobCallback{
+		Token:   req.Token,
+		Success: req.Success,
+		Error:   req.Error,
+	})
+
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "上报成功",
+		})
+		return
+	case service.ErrInvalidJobCallback:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "回调token无效或已过期",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "系统错误",
+		})
+	}
+
+ERR:
+	j.l.ERROR(logKey,
+		fields.Add(logger.Error(err)).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Field{"token", req.Token})...)
+	return
+}
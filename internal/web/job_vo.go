@@ -0,0 +1,223 @@
+package web
+
+import (
+	"kitbook/internal/domain"
+	"sort"
+	"time"
+)
+
+// JobVo
+// @Description: 前端响应-任务
+type JobVo struct {
+	Id         int64             `json:"id"`
+	Name       string            `json:"name"`
+	Expression string            `json:"expression"`
+	TimeZone   string            `json:"timeZone,omitempty"`
+	Executor   string            `json:"executor"`
+	Cfg        string            `json:"cfg,omitempty"`
+	Status     string            `json:"status"`
+	NextTime   string            `json:"nextTime"`
+	Mode       string            `json:"mode"`
+	ShardTotal int               `json:"shardTotal,omitempty"`
+	Upstreams  []int64           `json:"upstreams,omitempty"`
+	Affinity   map[string]string `json:"affinity,omitempty"`
+
+	// 重试策略, 重试间隔单位为秒
+	MaxRetries    int   `json:"maxRetries"`
+	RetryInterval int64 `json:"retryInterval"`
+	RetryCnt      int   `json:"retryCnt"`
+
+	// 错过调度的处理策略及累计错过次数
+	MisfirePolicy string `json:"misfirePolicy"`
+	MisfireCap    int    `json:"misfireCap,omitempty"`
+	CatchUp       int    `json:"catchUp"`
+	MisfireCnt    int64  `json:"misfireCnt"`
+
+	// 最近一次执行情况, 从未执行时为空
+	LastExecTime string `json:"lastExecTime,omitempty"`
+	LastResult   string `json:"lastResult"`
+	LastError    string `json:"lastError,omitempty"`
+}
+
+func ConvertJobVo(job *domain.Job) JobVo {
+	vo := JobVo{
+		Id:         job.Id,
+		Name:       job.Name,
+		Expression: job.Expression,
+		TimeZone:   job.TimeZone,
+		Executor:   job.ExecutorName,
+		Cfg:        job.Cfg,
+		Status:     job.Status.String(),
+		NextTime:   job.NextExecTime.Format(time.DateTime),
+		Mode:       job.Mode.String(),
+		ShardTotal: job.ShardTotal,
+		Upstreams:  job.Upstreams,
+		Affinity:   job.Affinity,
+
+		MaxRetries:    job.MaxRetries,
+		RetryInterval: int64(job.RetryInterval / time.Second),
+		RetryCnt:      job.RetryCnt,
+
+		MisfirePolicy: job.MisfirePolicy.String(),
+		MisfireCap:    job.MisfireCap,
+		CatchUp:       job.CatchUp,
+		MisfireCnt:    job.MisfireCnt,
+
+		LastResult: job.LastResult.String(),
+		LastError:  job.LastError,
+	}
+
+	if job.LastResult != domain.JobResultNone {
+		vo.LastExecTime = job.LastExecTime.Format(time.DateTime)
+	}
+
+	return vo
+}
+
+func ConvertJobVos(jobs []domain.Job) []JobVo {
+	vos := make([]JobVo, len(jobs))
+	for i, job := range jobs {
+		vos[i] = ConvertJobVo(&job)
+	}
+
+	return vos
+}
+
+// JobDAGVo
+// @Description: 前端响应-任务依赖图
+type JobDAGVo struct {
+	Nodes []JobDAGNodeVo `json:"nodes"`
+	Edges []JobDAGEdgeVo `json:"edges"`
+}
+
+// JobDAGNodeVo
+// @Description: 前端响应-依赖图中的任务及其运行状态
+type JobDAGNodeVo struct {
+	Id         int64  `json:"id"`
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	NextTime   string `json:"nextTime"`
+	LastResult string `json:"lastResult"`
+	// 从未成功时为空
+	LastSuccessTime string `json:"lastSuccessTime,omitempty"`
+	// 上游均已在本周期内执行成功
+	Ready bool `json:"ready"`
+}
+
+// JobDAGEdgeVo
+// @Description: 前端响应-依赖关系, 上游指向下游
+type JobDAGEdgeVo struct {
+	From int64 `json:"from"`
+	To   int64 `json:"to"`
+	// 上游在本周期内是否已执行成功
+	Satisfied bool `json:"satisfied"`
+}
+
+func ConvertJobDAGVo(jobs []domain.Job) JobDAGVo {
+	index := make(map[int64]domain.Job, len(jobs))
+	for _, job := range jobs {
+		index[job.Id] = job
+	}
+
+	vo := JobDAGVo{
+		Nodes: make([]JobDAGNodeVo, 0, len(jobs)),
+		Edges: []JobDAGEdgeVo{},
+	}
+	for _, job := range jobs {
+		node := JobDAGNodeVo{
+			Id:         job.Id,
+			Name:       job.Name,
+			Status:     job.Status.String(),
+			NextTime:   job.NextExecTime.Format(time.DateTime),
+			LastResult: job.LastResult.String(),
+			Ready:      true,
+		}
+		if job.LastSuccessTime.UnixMilli() > 0 {
+			node.LastSuccessTime = job.LastSuccessTime.Format(time.DateTime)
+		}
+
+		for _, up := range job.Upstreams {
+			upJob, ok := index[up]
+			satisfied := ok && job.UpstreamSatisfied(upJob)
+			node.Ready = node.Ready && satisfied
+			vo.Edges = append(vo.Edges, JobDAGEdgeVo{
+				From:      up,
+				To:        job.Id,
+				Satisfied: satisfied,
+			})
+		}
+
+		vo.Nodes = append(vo.Nodes, node)
+	}
+
+	return vo
+}
+
+// JobExecutionVo
+// @Description: 前端响应-任务执行记录
+type JobExecutionVo struct {
+	Id   int64  `json:"id"`
+	Node string `json:"node"`
+	// 分片序号, 仅分片任务有效
+	ShardIndex int    `json:"shardIndex"`
+	Attempt    int    `json:"attempt"`
+	Result     string `json:"result"`
+	Error      string `json:"error,omitempty"`
+	StartTime  string `json:"startTime"`
+	EndTime    string `json:"endTime"`
+	// 执行耗时 毫秒
+	Duration int64 `json:"duration"`
+}
+
+func ConvertJobExecutionVos(execs []domain.JobExecution) []JobExecutionVo {
+	vos := make([]JobExecutionVo, len(execs))
+	for i, exec := range execs {
+		vos[i] = JobExecutionVo{
+			Id:         exec.Id,
+			Node:       exec.Node,
+			ShardIndex: exec.ShardIndex,
+			Attempt:    exec.Attempt,
+			Result:     exec.Result.String(),
+			Error:      exec.Error,
+			StartTime:  exec.StartTime.Format(time.DateTime),
+			EndTime:    exec.EndTime.Format(time.DateTime),
+			Duration:   exec.EndTime.Sub(exec.StartTime).Milliseconds(),
+		}
+	}
+
+	return vos
+}
+
+// JobNodeVo
+// @Description: 前端响应-调度结点负载
+type JobNodeVo struct {
+	Name     string            `json:"name"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Running  int64             `json:"running"`
+	Capacity int64             `json:"capacity"`
+	// CPU使用率及综合负载 0~1
+	CPU   float64 `json:"cpu"`
+	Load  float64 `json:"load"`
+	Utime string  `json:"utime"`
+}
+
+func ConvertJobNodeVos(nodes []domain.JobNode) []JobNodeVo {
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Load() > nodes[j].Load()
+	})
+
+	vos := make([]JobNodeVo, len(nodes))
+	for i, node := range nodes {
+		vos[i] = JobNodeVo{
+			Name:     node.Name,
+			Labels:   node.Labels,
+			Running:  node.Running,
+			Capacity: node.Capacity,
+			CPU:      node.CPU,
+			Load:     node.Load(),
+			Utime:    node.Utime.Format(time.DateTime),
+		}
+	}
+
+	return vos
+}
@@ -2,7 +2,10 @@
 // @Description: 登录校验通用组件
 package middlewares
 
-import "github.com/gin-gonic/gin"
+import (
+	"github.com/gin-gonic/gin"
+	"strings"
+)
 
 // 所有注册、登录的URL
 var signupOrLoginPaths = []string{
@@ -12,6 +15,13 @@ var signupOrLoginPaths = []string{
 	"/users/login_sms/code/send",
 	"/oauth2/wechat/authurl",
 	"/oauth2.wechat/callback",
+	"/jobs/callback", // 远程任务回调, 由token鉴权
+}
+
+// 无需登录的URL前缀
+var publicPathPrefixes = []string{
+	"/files/",   // 帖子中引用的文件, 读者无需登录
+	"/storage/", // 本地存储的预签名地址, 由签名鉴权
 }
 
 // @func: checkIsSignupOrLogin
@@ -29,5 +39,11 @@ func CheckIsSignupOrLogin(ctx *gin.Context) bool {
 		}
 	}
 
+	for _, prefix := range publicPathPrefixes {
+		if strings.HasPrefix(requestPath, prefix) {
+			return true
+		}
+	}
+
 	return false
 }
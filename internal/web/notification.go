@@ -0,0 +1,107 @@
+// Package web
+// @Description: 用户模块-站内通知
+package web
+
+import (
+	"github.com/gin-gonic/gin"
+	"kitbook/internal/domain"
+	"kitbook/internal/service"
+	ijwt "kitbook/internal/web/jwt"
+	"kitbook/pkg/logger"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// 站内通知单页最大条数
+const notificationMaxLimit = 100
+
+type NotificationHandler struct {
+	svc service.NotificationService
+	l   logger.Logger
+}
+
+func NewNotificationHandler(svc service.NotificationService, l logger.Logger) *NotificationHandler {
+	return &NotificationHandler{
+		svc: svc,
+		l:   l,
+	}
+}
+
+func (n *NotificationHandler) RegisterRoutes(server *gin.Engine) {
+	group := server.Group("/users/notifications")
+	// /users/notifications?cursor=?&limit=?  按通知时间倒序分页
+	group.GET("", n.List)
+}
+
+// @func: List
+// @date: 2024-01-28 11:02:17
+// @brief: 站内通知-分页查询
+// @author: Kewin Li
+// @receiver n
+// @param ctx
+func (n *NotificationHandler) List(ctx *gin.Context) {
+	var err error
+	var claims ijwt.UserClaims
+	var ns []domain.Notification
+	var cursor int64
+	var limit int
+	logKey := logger.UserLogMsgKey[logger.LOG_USER_NOTIFICATION]
+	fields := logger.Fields{}
+
+	cursorStr := ctx.DefaultQuery("cursor", "0")
+	limitStr := ctx.DefaultQuery("limit", "10")
+
+	claims = ctx.MustGet("user_token").(ijwt.UserClaims)
+
+	cursor, err = strconv.ParseInt(cursorStr, 10, 64)
+	if err != nil {
+		fields = fields.Add(logger.String("请求参数解析错误")).
+			Add(logger.Field{"cursor", cursorStr})
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "系统错误",
+		})
+		goto ERR
+	}
+
+	limit, err = strconv.Atoi(limitStr)
+	if err != nil || limit <= 0 || limit > notificationMaxLimit {
+		fields = fields.Add(logger.String("请求参数非法")).
+			Add(logger.Field{"limit", limitStr})
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "参数错误",
+		})
+		goto ERR
+	}
+
+	// 首页查询
+	if cursor <= 0 {
+		cursor = time.Now().UnixMilli()
+	}
+
+	ns, err = n.svc.List(ctx, claims.UserID, time.UnixMilli(cursor), limit)
+
+	switch err {
+	case nil:
+		n.l.INFO(logKey, fields.Add(logger.String("站内通知查询成功")).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Int[int64]("userId", claims.UserID))...)
+
+		ctx.JSON(http.StatusOK, Result{
+			Msg:  "查询成功",
+			Data: ConvertsNotificationVos(ns),
+		})
+		return
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "系统错误",
+		})
+	}
+
+ERR:
+	n.l.ERROR(logKey,
+		fields.Add(logger.Error(err)).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Int[int64]("userId", claims.UserID))...)
+	return
+}
@@ -0,0 +1,115 @@
+// Package web
+// @Description: 热榜模块
+package web
+
+import (
+	"github.com/gin-gonic/gin"
+	"kitbook/internal/domain"
+	"kitbook/internal/service"
+	"kitbook/pkg/logger"
+	"net/http"
+)
+
+type RankingHandler struct {
+	svc     service.RankingService
+	intrSvc service.InteractiveService
+	biz     string
+	l       logger.Logger
+}
+
+func NewRankingHandler(svc service.RankingService, intrSvc service.InteractiveService, l logger.Logger) *RankingHandler {
+	return &RankingHandler{
+		svc:     svc,
+		intrSvc: intrSvc,
+		biz:     "article",
+		l:       l,
+	}
+}
+
+func (r *RankingHandler) RegisterRoutes(server *gin.Engine) {
+	// /ranking/hot  按榜单名称查询, 榜单由配置决定
+	server.GET("/ranking/:name", r.TopN)
+}
+
+// @func: TopN
+// @date: 2024-01-15 21:20:13
+// @brief: 热榜模块-查询指定榜单, 返回帖子摘要和互动数
+// @author: Kewin Li
+// @receiver r
+// @param ctx
+func (r *RankingHandler) TopN(ctx *gin.Context) {
+	var err error
+	var arts []domain.Article
+	logKey := logger.RankingLogMsgKey[logger.LOG_RANKING_TOPN]
+	fields := logger.Fields{}
+
+	name := ctx.Param("name")
+
+	arts, err = r.svc.GetTopN(ctx, name)
+
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg:  "查询成功",
+			Data: r.withInteractive(ctx, name, ConvertArticleVos(arts, true)),
+		})
+		return
+	case service.ErrRankingNotFound:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "榜单不存在",
+		})
+		return
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "系统错误",
+		})
+	}
+
+	r.l.ERROR(logKey,
+		fields.Add(logger.Error(err)).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Field{"name", name})...)
+	return
+}
+
+// @func: withInteractive
+// @date: 2024-01-16 11:02:37
+// @brief: 热榜模块-填充帖子互动数, 查询失败时降级只返回帖子
+// @author: Kewin Li
+// @receiver r
+// @param ctx
+// @param name
+// @param vos
+// @return []ArticleVo
+func (r *RankingHandler) withInteractive(ctx *gin.Context, name string, vos []ArticleVo) []ArticleVo {
+	if len(vos) == 0 {
+		return vos
+	}
+
+	ids := make([]int64, 0, len(vos))
+	for _, vo := range vos {
+		ids = append(ids, vo.Id)
+	}
+
+	intrs, err := r.intrSvc.GetByIds(ctx, r.biz, ids)
+	if err != nil {
+		r.l.WARN("热榜查询互动数失败",
+			logger.Error(err),
+			logger.Field{"name", name})
+		return vos
+	}
+
+	for i := range vos {
+		intr, ok := intrs[vos[i].Id]
+		if !ok {
+			continue
+		}
+
+		vos[i].ReadCnt = intr.ReadCnt
+		vos[i].LikeCnt = intr.LikeCnt
+		vos[i].CollectCnt = intr.CollectCnt
+		vos[i].CommentCnt = intr.CommentCnt
+	}
+
+	return vos
+}
@@ -0,0 +1,281 @@
+// Package web
+// @Description: 内容审核模块
+package web
+
+import (
+	"github.com/gin-gonic/gin"
+	"kitbook/internal/domain"
+	"kitbook/internal/service"
+	ijwt "kitbook/internal/web/jwt"
+	"kitbook/pkg/logger"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// 待审核列表单页最大条数
+	reviewListMaxLimit = 100
+	// 驳回原因最多条数及单条最大长度
+	reviewReasonMaxCnt = 10
+	reviewReasonMaxLen = 200
+)
+
+type ReviewHandler struct {
+	svc service.ArticleService
+	// 允许审核帖子的用户
+	reviewers map[int64]struct{}
+	l         logger.Logger
+}
+
+func NewReviewHandler(svc service.ArticleService, reviewers []int64, l logger.Logger) *ReviewHandler {
+	set := make(map[int64]struct{}, len(reviewers))
+	for _, uid := range reviewers {
+		set[uid] = struct{}{}
+	}
+
+	return &ReviewHandler{
+		svc:       svc,
+		reviewers: set,
+		l:         l,
+	}
+}
+
+// @func: RegisterRoutes
+// @date: 2024-01-25 16:20:08
+// @brief: 内容审核模块-路由注册, 仅审核员可访问
+// @author: Kewin Li
+// @receiver r
+// @param server
+func (r *ReviewHandler) RegisterRoutes(server *gin.Engine) {
+	group := server.Group("/reviews", r.CheckReviewer)
+	// /pending?offset=?&limit=?  待人工审核队列, 先提交的在前
+	group.GET("/pending", r.Pending)
+	group.POST("/approve", r.Approve) // 审核通过并发表
+	group.POST("/reject", r.Reject)   // 驳回, 原因展示给作者
+}
+
+// @func: CheckReviewer
+// @date: 2024-01-25 16:21:30
+// @brief: 内容审核模块-仅审核员可操作
+// @author: Kewin Li
+// @receiver r
+// @param ctx
+func (r *ReviewHandler) CheckReviewer(ctx *gin.Context) {
+	val, _ := ctx.Get("user_token")
+	claims, ok := val.(ijwt.UserClaims)
+	if ok {
+		if _, ok = r.reviewers[claims.UserID]; ok {
+			return
+		}
+	}
+
+	ctx.AbortWithStatus(http.StatusForbidden)
+}
+
+// @func: Pending
+// @date: 2024-01-25 16:25:46
+// @brief: 内容审核模块-待人工审核列表, 包含提交审核时的内容快照
+// @author: Kewin Li
+// @receiver r
+// @param ctx
+func (r *ReviewHandler) Pending(ctx *gin.Context) {
+	type Req struct {
+		Offset int `form:"offset"`
+		Limit  int `form:"limit"`
+	}
+	var req Req
+	var err error
+	var reviews []domain.ArticleReview
+	logKey := logger.ReviewLogMsgKey[logger.LOG_REVIEW_PENDING]
+	fields := logger.Fields{}
+
+	err = ctx.BindQuery(&req)
+	if err != nil {
+		fields = fields.Add(logger.String("请求参数解析错误"))
+		goto ERR
+	}
+
+	if req.Offset < 0 || req.Limit <= 0 || req.Limit > reviewListMaxLimit {
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "参数错误",
+		})
+		return
+	}
+
+	reviews, err = r.svc.ListPendingReviews(ctx, req.Offset, req.Limit)
+
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg:  "查询成功",
+			Data: ConvertReviewVos(reviews),
+		})
+		return
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "系统错误",
+		})
+	}
+
+ERR:
+	r.l.ERROR(logKey,
+		fields.Add(logger.Error(err)).
+			Add(logger.Field{"IP", ctx.ClientIP()})...)
+	return
+}
+
+// @func: Approve
+// @date: 2024-01-25 16:30:12
+// @brief: 内容审核模块-审核通过并发表
+// @author: Kewin Li
+// @receiver r
+// @param ctx
+func (r *ReviewHandler) Approve(ctx *gin.Context) {
+	type Req struct {
+		Id int64 `json:"id"`
+	}
+	var req Req
+	var err error
+	var claims ijwt.UserClaims
+	logKey := logger.ReviewLogMsgKey[logger.LOG_REVIEW_APPROVE]
+	fields := logger.Fields{}
+
+	err = ctx.Bind(&req)
+	if err != nil {
+		fields = fields.Add(logger.String("请求解析失败"))
+		goto ERR
+	}
+
+	claims = ctx.MustGet("user_token").(ijwt.UserClaims)
+
+	err = r.svc.ApproveReview(ctx, req.Id, claims.UserID)
+
+	if r.handleErr(ctx, err) {
+		return
+	}
+	if err == nil {
+		r.l.INFO(logKey,
+			fields.Add(logger.String("审核通过")).
+				Add(logger.Int[int64]("reviewId", req.Id)).
+				Add(logger.Int[int64]("reviewerId", claims.UserID))...)
+
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "审核通过, 已发表",
+		})
+		return
+	}
+
+ERR:
+	r.l.ERROR(logKey,
+		fields.Add(logger.Error(err)).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Int[int64]("reviewId", req.Id)).
+			Add(logger.Int[int64]("reviewerId", claims.UserID))...)
+	return
+}
+
+// @func: Reject
+// @date: 2024-01-25 16:35:40
+// @brief: 内容审核模块-驳回, 必须给出原因
+// @author: Kewin Li
+// @receiver r
+// @param ctx
+func (r *ReviewHandler) Reject(ctx *gin.Context) {
+	type Req struct {
+		Id      int64    `json:"id"`
+		Reasons []string `json:"reasons"`
+	}
+	var req Req
+	var err error
+	var claims ijwt.UserClaims
+	var reasons []string
+	logKey := logger.ReviewLogMsgKey[logger.LOG_REVIEW_REJECT]
+	fields := logger.Fields{}
+
+	err = ctx.Bind(&req)
+	if err != nil {
+		fields = fields.Add(logger.String("请求解析失败"))
+		goto ERR
+	}
+
+	claims = ctx.MustGet("user_token").(ijwt.UserClaims)
+
+	reasons = make([]string, 0, len(req.Reasons))
+	for _, reason := range req.Reasons {
+		if reason = strings.TrimSpace(reason); reason != "" {
+			reasons = append(reasons, reason)
+		}
+	}
+	if len(reasons) == 0 || len(reasons) > reviewReasonMaxCnt {
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "请填写驳回原因",
+		})
+		return
+	}
+	for _, reason := range reasons {
+		if utf8.RuneCountInString(reason) > reviewReasonMaxLen {
+			ctx.JSON(http.StatusOK, Result{
+				Msg: "驳回原因过长",
+			})
+			return
+		}
+	}
+
+	err = r.svc.RejectReview(ctx, req.Id, claims.UserID, reasons)
+
+	if r.handleErr(ctx, err) {
+		return
+	}
+	if err == nil {
+		r.l.INFO(logKey,
+			fields.Add(logger.String("审核驳回")).
+				Add(logger.Int[int64]("reviewId", req.Id)).
+				Add(logger.Int[int64]("reviewerId", claims.UserID))...)
+
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "已驳回",
+		})
+		return
+	}
+
+ERR:
+	r.l.ERROR(logKey,
+		fields.Add(logger.Error(err)).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Int[int64]("reviewId", req.Id)).
+			Add(logger.Int[int64]("reviewerId", claims.UserID))...)
+	return
+}
+
+// @func: handleErr
+// @date: 2024-01-25 16:40:18
+// @brief: 内容审核模块-业务错误响应
+// @author: Kewin Li
+// @receiver r
+// @param ctx
+// @param err
+// @return bool 已响应时返回true; 系统错误同样已响应, 但返回false交由调用方记录日志
+func (r *ReviewHandler) handleErr(ctx *gin.Context, err error) bool {
+	msg := ""
+	switch err {
+	case nil:
+		return false
+	case service.ErrReviewNotFound:
+		msg = "审核记录不存在"
+	case service.ErrReviewResolved:
+		msg = "该审核已被处理"
+	case service.ErrReviewStale:
+		msg = "作者已修改帖子, 本次审核失效"
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "系统错误",
+		})
+		return false
+	}
+
+	ctx.JSON(http.StatusOK, Result{
+		Msg: msg,
+	})
+	return true
+}
@@ -0,0 +1,95 @@
+// Package web
+// @Description: 搜索模块
+package web
+
+import (
+	"github.com/gin-gonic/gin"
+	"kitbook/internal/domain"
+	"kitbook/internal/service"
+	"kitbook/pkg/logger"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// 搜索关键词最大长度
+	searchQueryMaxLen = 64
+	// 搜索单页最大条数
+	searchMaxLimit = 50
+)
+
+type SearchHandler struct {
+	svc service.SearchService
+	l   logger.Logger
+}
+
+func NewSearchHandler(svc service.SearchService, l logger.Logger) *SearchHandler {
+	return &SearchHandler{
+		svc: svc,
+		l:   l,
+	}
+}
+
+func (s *SearchHandler) RegisterRoutes(server *gin.Engine) {
+	group := server.Group("/search")
+	// /search/articles?q=xxx&offset=0&limit=10
+	group.GET("/articles", s.Articles)
+}
+
+// @func: Articles
+// @date: 2024-01-23 17:05:12
+// @brief: 搜索模块-按关键词搜索已发表帖子, 标题、正文摘要中的关键词高亮
+// @author: Kewin Li
+// @receiver s
+// @param ctx
+func (s *SearchHandler) Articles(ctx *gin.Context) {
+	type SearchReq struct {
+		Query  string `form:"q"`
+		Offset int    `form:"offset"`
+		Limit  int    `form:"limit"`
+	}
+
+	var req SearchReq
+	var err error
+	var res domain.SearchArticleResult
+	logKey := logger.SearchLogMsgKey[logger.LOG_SEARCH_ARTICLES]
+	fields := logger.Fields{}
+
+	err = ctx.BindQuery(&req)
+	if err != nil {
+		fields = fields.Add(logger.String("请求参数解析错误"))
+		goto ERR
+	}
+
+	req.Query = strings.TrimSpace(req.Query)
+	if req.Query == "" || utf8.RuneCountInString(req.Query) > searchQueryMaxLen ||
+		req.Offset < 0 || req.Limit <= 0 || req.Limit > searchMaxLimit {
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "参数错误",
+		})
+		return
+	}
+
+	res, err = s.svc.SearchArticles(ctx, req.Query, req.Offset, req.Limit)
+
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg:  "查询成功",
+			Data: ConvertSearchArticlesVo(&res),
+		})
+		return
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "系统错误",
+		})
+	}
+
+ERR:
+	s.l.ERROR(logKey,
+		fields.Add(logger.Error(err)).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Field{"q", req.Query})...)
+	return
+}
@@ -0,0 +1,45 @@
+package web
+
+import (
+	"kitbook/internal/domain"
+	"time"
+)
+
+// SearchArticleVo
+// @Description: 前端响应-搜索命中的帖子, 标题、摘要中的关键词已用<em>包裹, 其余内容已转义
+type SearchArticleVo struct {
+	Id         int64   `json:"id"`
+	Title      string  `json:"title"`
+	Snippet    string  `json:"snippet"`
+	AuthorId   int64   `json:"authorId"`
+	AuthorName string  `json:"authorName"`
+	Utime      string  `json:"utime"`
+	Score      float64 `json:"score"`
+}
+
+// SearchArticlesVo
+// @Description: 前端响应-帖子搜索结果
+type SearchArticlesVo struct {
+	Total    int               `json:"total"`
+	Articles []SearchArticleVo `json:"articles"`
+}
+
+func ConvertSearchArticlesVo(res *domain.SearchArticleResult) SearchArticlesVo {
+	vos := make([]SearchArticleVo, 0, len(res.Hits))
+	for _, hit := range res.Hits {
+		vos = append(vos, SearchArticleVo{
+			Id:         hit.Article.Id,
+			Title:      hit.Title,
+			Snippet:    hit.Snippet,
+			AuthorId:   hit.Article.AuthorId,
+			AuthorName: hit.Article.AuthorName,
+			Utime:      hit.Article.Utime.Format(time.DateTime),
+			Score:      hit.Score,
+		})
+	}
+
+	return SearchArticlesVo{
+		Total:    res.Total,
+		Articles: vos,
+	}
+}
@@ -0,0 +1,309 @@
+// Package web
+// @Description: 上传模块
+package web
+
+import (
+	"github.com/gin-gonic/gin"
+	"kitbook/internal/domain"
+	"kitbook/internal/service"
+	ijwt "kitbook/internal/web/jwt"
+	"kitbook/pkg/logger"
+	"kitbook/pkg/storage"
+	"net/http"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// 中转上传请求体上限, 精确的大小限制由服务层按文件类型校验
+	uploadMaxBodySize = 64 << 20
+	// 文件名最大长度
+	uploadFilenameMaxLen = 255
+)
+
+// 内容SHA256, 小写十六进制
+var uploadHashRegexp = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+type UploadHandler struct {
+	svc   service.UploadService
+	store storage.Storage
+	l     logger.Logger
+}
+
+func NewUploadHandler(svc service.UploadService, store storage.Storage, l logger.Logger) *UploadHandler {
+	return &UploadHandler{
+		svc:   svc,
+		store: store,
+		l:     l,
+	}
+}
+
+func (u *UploadHandler) RegisterRoutes(server *gin.Engine) {
+	group := server.Group("/uploads")
+	// 服务端中转上传, multipart表单字段 file
+	group.POST("", u.Upload)
+	// 签发客户端直传地址
+	group.POST("/presign", u.Presign)
+	// 客户端直传完成后确认
+	group.POST("/confirm", u.Confirm)
+
+	// 帖子中引用的文件地址, 跳转到有时效的下载地址
+	server.GET("/files/:hash", u.Download)
+
+	// 本地存储时由本服务处理预签名地址
+	if local, ok := u.store.(*storage.LocalStorage); ok {
+		server.Any("/storage/*key", func(ctx *gin.Context) {
+			local.ServePresigned(ctx.Writer, ctx.Request, strings.TrimPrefix(ctx.Param("key"), "/"))
+		})
+	}
+}
+
+// @func: Upload
+// @date: 2024-01-24 16:10:12
+// @brief: 上传模块-服务端中转上传图片、附件
+// @author: Kewin Li
+// @receiver u
+// @param ctx
+func (u *UploadHandler) Upload(ctx *gin.Context) {
+	var err error
+	var res domain.Upload
+	logKey := logger.UploadLogMsgKey[logger.LOG_UPLOAD_FILE]
+	fields := logger.Fields{}
+	claims := ctx.MustGet("user_token").(ijwt.UserClaims)
+
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, uploadMaxBodySize)
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "文件缺失或超过大小限制",
+		})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		fields = fields.Add(logger.String("上传文件读取错误"))
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "系统错误",
+		})
+		goto ERR
+	}
+	defer file.Close()
+
+	res, err = u.svc.Upload(ctx, claims.UserID, normalizeFilename(header.Filename), file, header.Size)
+	if u.handleErr(ctx, err) {
+		return
+	}
+	if err == nil {
+		ctx.JSON(http.StatusOK, Result{
+			Msg:  "上传成功",
+			Data: ConvertUploadVo(&res),
+		})
+		return
+	}
+
+ERR:
+	u.l.ERROR(logKey,
+		fields.Add(logger.Error(err)).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Int[int64]("userId", claims.UserID))...)
+	return
+}
+
+// @func: Presign
+// @date: 2024-01-24 16:14:36
+// @brief: 上传模块-签发客户端直传地址, 内容已存在时直接返回就绪的文件
+// @author: Kewin Li
+// @receiver u
+// @param ctx
+func (u *UploadHandler) Presign(ctx *gin.Context) {
+	type PresignReq struct {
+		Hash        string `json:"hash"`
+		Size        int64  `json:"size"`
+		ContentType string `json:"contentType"`
+		Filename    string `json:"filename"`
+	}
+
+	var req PresignReq
+	var err error
+	var res domain.UploadPresign
+	logKey := logger.UploadLogMsgKey[logger.LOG_UPLOAD_PRESIGN]
+	fields := logger.Fields{}
+	claims := ctx.MustGet("user_token").(ijwt.UserClaims)
+
+	err = ctx.Bind(&req)
+	if err != nil {
+		fields = fields.Add(logger.String("请求参数解析错误"))
+		goto ERR
+	}
+
+	req.Hash = strings.ToLower(req.Hash)
+	if !uploadHashRegexp.MatchString(req.Hash) {
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "参数错误",
+		})
+		return
+	}
+
+	res, err = u.svc.Presign(ctx, domain.Upload{
+		UserId:      claims.UserID,
+		Hash:        req.Hash,
+		Size:        req.Size,
+		ContentType: req.ContentType,
+		Filename:    normalizeFilename(req.Filename),
+	})
+	if u.handleErr(ctx, err) {
+		return
+	}
+	if err == nil {
+		ctx.JSON(http.StatusOK, Result{
+			Msg:  "签发成功",
+			Data: ConvertPresignVo(&res),
+		})
+		return
+	}
+
+ERR:
+	u.l.ERROR(logKey,
+		fields.Add(logger.Error(err)).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Int[int64]("userId", claims.UserID))...)
+	return
+}
+
+// @func: Confirm
+// @date: 2024-01-24 16:18:52
+// @brief: 上传模块-客户端直传完成后确认
+// @author: Kewin Li
+// @receiver u
+// @param ctx
+func (u *UploadHandler) Confirm(ctx *gin.Context) {
+	type ConfirmReq struct {
+		Id int64 `json:"id"`
+	}
+
+	var req ConfirmReq
+	var err error
+	var res domain.Upload
+	logKey := logger.UploadLogMsgKey[logger.LOG_UPLOAD_CONFIRM]
+	fields := logger.Fields{}
+	claims := ctx.MustGet("user_token").(ijwt.UserClaims)
+
+	err = ctx.Bind(&req)
+	if err != nil {
+		fields = fields.Add(logger.String("请求参数解析错误"))
+		goto ERR
+	}
+
+	res, err = u.svc.Confirm(ctx, req.Id, claims.UserID)
+	if u.handleErr(ctx, err) {
+		return
+	}
+	if err == nil {
+		ctx.JSON(http.StatusOK, Result{
+			Msg:  "上传成功",
+			Data: ConvertUploadVo(&res),
+		})
+		return
+	}
+
+ERR:
+	u.l.ERROR(logKey,
+		fields.Add(logger.Error(err)).
+			Add(logger.Field{"IP", ctx.ClientIP()}).
+			Add(logger.Int[int64]("userId", claims.UserID)).
+			Add(logger.Int[int64]("id", req.Id))...)
+	return
+}
+
+// @func: Download
+// @date: 2024-01-24 16:22:10
+// @brief: 上传模块-跳转到有时效的下载地址, 帖子中保存的是不会过期的 /files/:hash
+// @author: Kewin Li
+// @receiver u
+// @param ctx
+func (u *UploadHandler) Download(ctx *gin.Context) {
+	hash := ctx.Param("hash")
+	if !uploadHashRegexp.MatchString(hash) {
+		ctx.Status(http.StatusNotFound)
+		return
+	}
+
+	url, err := u.svc.DownloadURL(ctx, hash)
+	switch err {
+	case nil:
+		// 下载地址会过期, 不允许缓存跳转结果
+		ctx.Header("Cache-Control", "no-store")
+		ctx.Redirect(http.StatusFound, url)
+	case service.ErrUploadNotFound:
+		ctx.Status(http.StatusNotFound)
+	default:
+		ctx.Status(http.StatusInternalServerError)
+		u.l.ERROR(logger.UploadLogMsgKey[logger.LOG_UPLOAD_DOWNLOAD],
+			logger.Error(err),
+			logger.Field{"IP", ctx.ClientIP()},
+			logger.Field{"hash", hash})
+	}
+}
+
+// @func: handleErr
+// @date: 2024-01-24 16:25:32
+// @brief: 上传模块-业务错误响应
+// @author: Kewin Li
+// @receiver u
+// @param ctx
+// @param err
+// @return bool 已响应时返回true; 系统错误同样已响应, 但返回false交由调用方记录日志
+func (u *UploadHandler) handleErr(ctx *gin.Context, err error) bool {
+	msg := ""
+	switch err {
+	case nil:
+		return false
+	case service.ErrUploadTooLarge:
+		msg = "文件超过大小限制"
+	case service.ErrUploadTypeNotAllowed:
+		msg = "不支持的文件类型"
+	case service.ErrUploadQuotaExceeded:
+		msg = "上传空间不足"
+	case service.ErrUploadMismatch:
+		msg = "文件内容与声明不一致"
+	case service.ErrUploadNotUploaded:
+		msg = "文件尚未上传"
+	case service.ErrUploadNotFound:
+		msg = "上传记录不存在"
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "系统错误",
+		})
+		return false
+	}
+
+	ctx.JSON(http.StatusOK, Result{
+		Msg: msg,
+	})
+	return true
+}
+
+// @func: normalizeFilename
+// @date: 2024-01-24 16:27:48
+// @brief: 上传模块-只保留文件名本身, 去掉路径和控制字符, 超长截断
+// @author: Kewin Li
+// @param name
+// @return string
+func normalizeFilename(name string) string {
+	if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(name))
+
+	if utf8.RuneCountInString(name) > uploadFilenameMaxLen {
+		name = string([]rune(name)[:uploadFilenameMaxLen])
+	}
+	return name
+}
@@ -0,0 +1,56 @@
+package web
+
+import (
+	"kitbook/internal/domain"
+	"time"
+)
+
+// UploadVo
+// @Description: 前端响应-上传文件, url 为帖子中引用的地址
+type UploadVo struct {
+	Id          int64  `json:"id"`
+	Hash        string `json:"hash"`
+	Size        int64  `json:"size"`
+	ContentType string `json:"contentType"`
+	Filename    string `json:"filename"`
+	Kind        string `json:"kind"`
+	Status      string `json:"status"`
+	URL         string `json:"url"`
+}
+
+// PresignVo
+// @Description: 前端响应-直传地址; 文件已就绪时不需要上传, uploadUrl 为空
+type PresignVo struct {
+	Upload    UploadVo          `json:"upload"`
+	Method    string            `json:"method,omitempty"`
+	UploadURL string            `json:"uploadUrl,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	ExpireAt  string            `json:"expireAt,omitempty"`
+}
+
+func ConvertUploadVo(u *domain.Upload) UploadVo {
+	return UploadVo{
+		Id:          u.Id,
+		Hash:        u.Hash,
+		Size:        u.Size,
+		ContentType: u.ContentType,
+		Filename:    u.Filename,
+		Kind:        u.Kind.String(),
+		Status:      u.Status.String(),
+		URL:         u.URL(),
+	}
+}
+
+func ConvertPresignVo(p *domain.UploadPresign) PresignVo {
+	vo := PresignVo{
+		Upload:    ConvertUploadVo(&p.Upload),
+		Method:    p.Method,
+		UploadURL: p.URL,
+		Headers:   p.Headers,
+	}
+	if !p.ExpireAt.IsZero() {
+		vo.ExpireAt = p.ExpireAt.Format(time.DateTime)
+	}
+
+	return vo
+}
@@ -11,6 +11,7 @@ import (
 	ijwt "kitbook/internal/web/jwt"
 	"kitbook/pkg/logger"
 	"net/http"
+	"strconv"
 	"time"
 )
 
@@ -32,6 +33,7 @@ type UserHandler struct {
 	svc            service.UserService
 	code           service.CodeService
 	jwtHdl         ijwt.JWTHandler
+	followSvc      service.FollowService
 	l              logger.Logger
 }
 
@@ -43,6 +45,7 @@ type UserHandler struct {
 func NewUserHandler(svc service.UserService,
 	code service.CodeService,
 	jwtHdl ijwt.JWTHandler,
+	followSvc service.FollowService,
 	l logger.Logger) *UserHandler {
 	return &UserHandler{
 		emailRegExp:    regexp.MustCompile(emailRegexPattern, regexp.None),
@@ -51,6 +54,7 @@ func NewUserHandler(svc service.UserService,
 		svc:            svc,
 		code:           code,
 		jwtHdl:         jwtHdl,
+		followSvc:      followSvc,
 		l:              l,
 	}
 }
@@ -518,15 +522,18 @@ ERR:
 
 // @func: Profile
 // @date: 2023-10-14 17:50:47
-// @brief: 用户模块-查看个人信息
+// @brief: 用户模块-查看个人信息, 携带uid时查看他人信息及互相关注状态
 // @author: Kewin Li
 // @receiver h
 // @param ctx
 func (h *UserHandler) Profile(ctx *gin.Context) {
 
 	var userID int64
+	var targetID int64
 	var err error
 	var user domain.User
+	var state domain.FollowState
+	var vo ProfileVo
 	var logKey = logger.UserLogMsgKey[logger.LOG_USER_PROFILE]
 	fields := logger.Fields{}
 
@@ -538,17 +545,42 @@ func (h *UserHandler) Profile(ctx *gin.Context) {
 		goto ERR
 	}
 
-	user, err = h.svc.Profile(ctx, userID)
+	// 2. 查看的用户ID, 默认查看自己
+	targetID = userID
+	if uid := ctx.Query("uid"); uid != "" {
+		targetID, err = strconv.ParseInt(uid, 10, 64)
+		if err != nil {
+			fields = fields.Add(logger.String("请求参数解析错误"))
+			goto ERR
+		}
+	}
+
+	user, err = h.svc.Profile(ctx, targetID)
 
 	switch err {
 	case nil:
 		// 转化为返回给前端响应的用户个人信息
-		ctx.JSON(http.StatusOK, ConvertsProfileVo(&user))
+		vo = ConvertsProfileVo(&user)
+
+		// 查看他人信息, 隐藏联系方式并附带关注状态
+		if targetID != userID {
+			state, err = h.followSvc.GetFollowState(ctx, userID, targetID)
+			if err != nil {
+				fields = fields.Add(logger.String("查询关注状态失败"))
+				goto ERR
+			}
+			vo.Email, vo.Phone = "", ""
+			vo.Followed = state.Followed
+			vo.Mutual = state.Mutual()
+		}
+
+		ctx.JSON(http.StatusOK, vo)
 
 		h.l.INFO(logKey,
 			fields.Add(logger.String("查看个人信息")).
 				Add(logger.Field{"IP", ctx.ClientIP()}).
-				Add(logger.Field{"userID", userID})...)
+				Add(logger.Field{"userID", userID}).
+				Add(logger.Field{"targetID", targetID})...)
 		return
 	case service.ErrInvalidUserAccess:
 		err = errors.New("用户不存在")
@@ -562,7 +594,8 @@ ERR:
 		fields.Add(logger.Error(err)).
 			Add(logger.Field{"IP", ctx.ClientIP()}).
 			Add(logger.Field{"email", user.Email}).
-			Add(logger.Field{"userID", userID})...)
+			Add(logger.Field{"userID", userID}).
+			Add(logger.Field{"targetID", targetID})...)
 
 	ctx.String(http.StatusOK, "系统错误")
 	return
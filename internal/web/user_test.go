@@ -259,7 +259,7 @@ func (u *UserHandlerSuite) TestSignUp() {
 
 			// 构造handler
 			userSvc := tc.mock(ctrl)
-			h := NewUserHandler(userSvc, nil, nil, logger.NewNopLogger())
+			h := NewUserHandler(userSvc, nil, nil, nil, logger.NewNopLogger())
 
 			// 准备服务器, 注册路由
 			server := gin.Default()
@@ -375,7 +375,7 @@ func (u *UserHandlerSuite) TestProfile() {
 			defer ctrl.Finish()
 
 			svc := tc.mock(ctrl)
-			h := NewUserHandler(svc, nil, nil, logger.NewNopLogger())
+			h := NewUserHandler(svc, nil, nil, nil, logger.NewNopLogger())
 
 			// 创建服务器
 			server := gin.Default()
@@ -534,7 +534,7 @@ func (u *UserHandlerSuite) TestEdit() {
 			defer ctrl.Finish()
 
 			svc := tc.mock(ctrl)
-			h := NewUserHandler(svc, nil, nil, logger.NewNopLogger())
+			h := NewUserHandler(svc, nil, nil, nil, logger.NewNopLogger())
 
 			server := gin.Default()
 			server.Use(func(ctx *gin.Context) {
@@ -759,7 +759,7 @@ func (u *UserHandlerSuite) TestLogin() {
 			defer ctrl.Finish()
 
 			userSvc := tc.mock(ctrl)
-			h := NewUserHandler(userSvc, nil, nil, logger.NewNopLogger())
+			h := NewUserHandler(userSvc, nil, nil, nil, logger.NewNopLogger())
 			server := gin.Default()
 			h.RegisterRoutes(server)
 
@@ -975,7 +975,7 @@ func (u *UserHandlerSuite) TestLoginWithJWT() {
 			defer ctrl.Finish()
 
 			userSvc, ijwtHdl := tc.mock(ctrl)
-			h := NewUserHandler(userSvc, nil, ijwtHdl, logger.NewNopLogger())
+			h := NewUserHandler(userSvc, nil, ijwtHdl, nil, logger.NewNopLogger())
 			server := gin.Default()
 			h.RegisterRoutes(server)
 
@@ -1154,7 +1154,7 @@ func (u *UserHandlerSuite) TestSendLoginSMSCode() {
 			defer ctrl.Finish()
 
 			userSvc, codeSvc := tc.mock(ctrl)
-			h := NewUserHandler(userSvc, codeSvc, nil, logger.NewNopLogger())
+			h := NewUserHandler(userSvc, codeSvc, nil, nil, logger.NewNopLogger())
 			server := gin.Default()
 			h.RegisterRoutes(server)
 
@@ -1435,7 +1435,7 @@ func (u *UserHandlerSuite) TestLoginSMS() {
 			defer ctrl.Finish()
 
 			userSvc, codeSvc, ijwt := tc.mock(ctrl)
-			h := NewUserHandler(userSvc, codeSvc, ijwt, logger.NewNopLogger())
+			h := NewUserHandler(userSvc, codeSvc, ijwt, nil, logger.NewNopLogger())
 			server := gin.Default()
 			h.RegisterRoutes(server)
 
@@ -1506,7 +1506,7 @@ func (u *UserHandlerSuite) TestRefreshToken() {
 			defer ctrl.Finish()
 
 			ijwt := tc.mock(ctrl)
-			h := NewUserHandler(nil, nil, ijwt, logger.NewNopLogger())
+			h := NewUserHandler(nil, nil, ijwt, nil, logger.NewNopLogger())
 			server := gin.Default()
 			h.RegisterRoutes(server)
 
@@ -1554,7 +1554,7 @@ func (u *UserHandlerSuite) TestRefreshToken() {
 //			ctrl := gomock.NewController(t)
 //			defer ctrl.Finish()
 //
-//			h := NewUserHandler(nil, nil, nil, logger.NewNopLogger())
+//			h := NewUserHandler(nil, nil, nil, nil, logger.NewNopLogger())
 //			server := gin.Default()
 //			h.RegisterRoutes(server)
 //
@@ -1629,7 +1629,7 @@ func (u *UserHandlerSuite) TestLogoutWithJWT() {
 			defer ctrl.Finish()
 
 			ijwt := tc.mock(ctrl)
-			h := NewUserHandler(nil, nil, ijwt, logger.NewNopLogger())
+			h := NewUserHandler(nil, nil, ijwt, nil, logger.NewNopLogger())
 			server := gin.Default()
 			server.Use(func(ctx *gin.Context) {
 				ctx.Set("user_token", jwt.UserClaims{
@@ -1693,7 +1693,7 @@ func TestEmailRegExp(t *testing.T) {
 		},
 	}
 
-	h := NewUserHandler(nil, nil, nil, logger.NewNopLogger())
+	h := NewUserHandler(nil, nil, nil, nil, logger.NewNopLogger())
 
 	for _, val := range testCases {
 		tc := val
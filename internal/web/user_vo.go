@@ -13,6 +13,10 @@ type ProfileVo struct {
 	Phone    string `json:"phone"`
 	Birthday string `json:"birthday"`
 	AboutMe  string `json:"aboutMe"`
+
+	// 查看他人信息时: 是否已关注、是否互相关注
+	Followed bool `json:"followed,omitempty"`
+	Mutual   bool `json:"mutual,omitempty"`
 }
 
 func ConvertsProfileVo(user *domain.User) ProfileVo {
@@ -24,3 +28,59 @@ func ConvertsProfileVo(user *domain.User) ProfileVo {
 		AboutMe:  user.AboutMe,
 	}
 }
+
+// HistoryVo
+// @Description: 前端响应-浏览记录
+type HistoryVo struct {
+	BizId int64  `json:"bizId"`
+	Biz   string `json:"biz"`
+	// 最近一次浏览时间
+	Utime string `json:"utime"`
+	// 下一页查询的时间游标
+	Cursor int64 `json:"cursor"`
+	// 下一页查询的ID游标, 与时间游标一起使用
+	CursorId int64 `json:"cursorId"`
+}
+
+func ConvertsHistoryVos(records []domain.HistoryRecord) []HistoryVo {
+	vos := make([]HistoryVo, 0, len(records))
+	for _, record := range records {
+		vos = append(vos, HistoryVo{
+			BizId:    record.BizId,
+			Biz:      record.Biz,
+			Utime:    record.Utime.Format(time.DateTime),
+			Cursor:   record.Utime.UnixMilli(),
+			CursorId: record.Id,
+		})
+	}
+
+	return vos
+}
+
+// NotificationVo
+// @Description: 前端响应-站内通知
+type NotificationVo struct {
+	Id      int64  `json:"id"`
+	Biz     string `json:"biz"`
+	BizId   int64  `json:"bizId"`
+	Content string `json:"content"`
+	Ctime   string `json:"ctime"`
+	// 下一页查询的时间游标
+	Cursor int64 `json:"cursor"`
+}
+
+func ConvertsNotificationVos(ns []domain.Notification) []NotificationVo {
+	vos := make([]NotificationVo, 0, len(ns))
+	for _, n := range ns {
+		vos = append(vos, NotificationVo{
+			Id:      n.Id,
+			Biz:     n.Biz,
+			BizId:   n.BizId,
+			Content: n.Content,
+			Ctime:   n.Ctime.Format(time.DateTime),
+			Cursor:  n.Ctime.UnixMilli(),
+		})
+	}
+
+	return vos
+}
@@ -0,0 +1,220 @@
+// Package ioc
+// @Description: 帖子存储
+package ioc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/bwmarrin/snowflake"
+	"github.com/spf13/viper"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"gorm.io/gorm"
+	"kitbook/internal/repository/dao"
+	"time"
+)
+
+// 帖子存储后端
+const (
+	articleStorageMySQL   = "mysql"
+	articleStorageMongoDB = "mongodb"
+	articleStorageS3      = "s3"
+)
+
+// 启动时检查存储后端是否可用的超时
+const articleStorageCheckTimeout = 10 * time.Second
+
+// articleStorageConfig
+// @Description: 帖子存储配置, 制作库与线上库分别选择后端
+type articleStorageConfig struct {
+	// 制作库: mysql、mongodb
+	Author string `yaml:"author"`
+	// 线上库: mysql、mongodb、s3(内容存对象存储, 其余字段存MySQL)
+	Reader  string `yaml:"reader"`
+	MongoDB struct {
+		URI      string `yaml:"uri"`
+		Database string `yaml:"database"`
+		// 雪花算法结点ID, 制作库使用mongodb时生成帖子ID, 多实例部署时各不相同
+		NodeId int64 `yaml:"nodeId"`
+	} `yaml:"mongodb"`
+	S3 struct {
+		Endpoint  string `yaml:"endpoint"`
+		Region    string `yaml:"region"`
+		Bucket    string `yaml:"bucket"`
+		PathStyle bool   `yaml:"pathStyle"`
+	} `yaml:"s3"`
+}
+
+// @func: InitArticleDao
+// @date: 2024-01-26 11:30:12
+// @brief: 帖子存储-按配置选择制作库和线上库的后端, 启动时检查所选后端可用, 不可用直接退出
+// @author: Kewin Li
+// @param db
+// @return dao.ArticleDao 两边同为MySQL或MongoDB时使用原有实现, 否则组合两边的实现
+func InitArticleDao(db *gorm.DB) dao.ArticleDao {
+	cfg, err := loadArticleStorageConfig()
+	if err != nil {
+		panic(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), articleStorageCheckTimeout)
+	defer cancel()
+
+	// 检查所选后端可用
+	if cfg.Author == articleStorageMySQL || cfg.Reader == articleStorageMySQL || cfg.Reader == articleStorageS3 {
+		checkArticleMySQL(ctx, db)
+	}
+
+	var mdb *mongo.Database
+	if cfg.Author == articleStorageMongoDB || cfg.Reader == articleStorageMongoDB {
+		mdb = initArticleMongoDB(ctx, cfg.MongoDB.URI, cfg.MongoDB.Database)
+	}
+
+	var oss *s3.S3
+	if cfg.Reader == articleStorageS3 {
+		oss = newS3Client(cfg.S3.Endpoint, cfg.S3.Region, cfg.S3.PathStyle)
+		_, err = oss.HeadBucketWithContext(ctx, &s3.HeadBucketInput{
+			Bucket: aws.String(cfg.S3.Bucket),
+		})
+		if err != nil {
+			panic("帖子存储对象存储不可用: " + err.Error())
+		}
+	}
+
+	var node *snowflake.Node
+	if cfg.Author == articleStorageMongoDB {
+		node, err = snowflake.NewNode(cfg.MongoDB.NodeId)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	return newArticleDao(cfg, db, mdb, node, oss)
+}
+
+// @func: loadArticleStorageConfig
+// @date: 2024-01-26 11:32:05
+// @brief: 帖子存储-读取并校验配置, 未知后端在连接任何存储前报错
+// @author: Kewin Li
+// @return articleStorageConfig
+// @return error
+func loadArticleStorageConfig() (articleStorageConfig, error) {
+	cfg := articleStorageConfig{
+		Author: articleStorageMySQL,
+		Reader: articleStorageMySQL,
+	}
+	cfg.MongoDB.URI = "mongodb://localhost:27017"
+	cfg.MongoDB.Database = "kitbook"
+	cfg.MongoDB.NodeId = 1
+	err := viper.UnmarshalKey("article.storage", &cfg)
+	if err != nil {
+		return articleStorageConfig{}, err
+	}
+
+	switch cfg.Author {
+	case articleStorageMySQL, articleStorageMongoDB:
+	default:
+		return articleStorageConfig{}, fmt.Errorf("未知的帖子制作库后端: %q", cfg.Author)
+	}
+
+	switch cfg.Reader {
+	case articleStorageMySQL, articleStorageMongoDB:
+	case articleStorageS3:
+		if cfg.S3.Bucket == "" {
+			return articleStorageConfig{}, errors.New("帖子对象存储bucket未配置")
+		}
+	default:
+		return articleStorageConfig{}, fmt.Errorf("未知的帖子线上库后端: %q", cfg.Reader)
+	}
+
+	return cfg, nil
+}
+
+// @func: newArticleDao
+// @date: 2024-01-26 11:33:50
+// @brief: 帖子存储-按已校验的配置组装实现, 不做任何IO
+// @author: Kewin Li
+// @param cfg
+// @param db
+// @param mdb 制作库或线上库使用mongodb时非空
+// @param node 制作库使用mongodb时非空
+// @param oss 线上库使用s3时非空
+// @return dao.ArticleDao
+func newArticleDao(cfg articleStorageConfig, db *gorm.DB, mdb *mongo.Database,
+	node *snowflake.Node, oss *s3.S3) dao.ArticleDao {
+	switch {
+	case cfg.Author == articleStorageMySQL && cfg.Reader == articleStorageMySQL:
+		// 同库, 制作库和线上库在一个事务中同步
+		return dao.NewGormArticleDao(db)
+	case cfg.Author == articleStorageMongoDB && cfg.Reader == articleStorageMongoDB:
+		return dao.NewMongoDBArticleDAO(mdb, node)
+	}
+
+	var author dao.ArticleAuthorDao
+	switch cfg.Author {
+	case articleStorageMongoDB:
+		author = dao.NewMongoDBArticleAuthorDao(mdb, node)
+	default:
+		author = dao.NewGormArticleAuthorDao(db)
+	}
+
+	var reader dao.ArticleReaderDao
+	switch cfg.Reader {
+	case articleStorageMongoDB:
+		reader = dao.NewMongoDBArticleReaderDao(mdb)
+	case articleStorageS3:
+		reader = dao.NewS3ArticleReaderDao(db, oss, cfg.S3.Bucket)
+	default:
+		reader = dao.NewGormArticleReaderDao(db)
+	}
+
+	return dao.NewSplitArticleDao(author, reader)
+}
+
+// @func: initArticleMongoDB
+// @date: 2024-01-26 11:35:40
+// @brief: 帖子存储-连接MongoDB并建立索引
+// @author: Kewin Li
+// @param ctx
+// @param uri
+// @param database
+// @return *mongo.Database
+func initArticleMongoDB(ctx context.Context, uri string, database string) *mongo.Database {
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		panic(err)
+	}
+
+	err = client.Ping(ctx, readpref.Primary())
+	if err != nil {
+		panic("帖子存储MongoDB不可用: " + err.Error())
+	}
+
+	mdb := client.Database(database)
+	err = dao.InitCollection(mdb)
+	if err != nil {
+		panic(err)
+	}
+
+	return mdb
+}
+
+// @func: checkArticleMySQL
+// @date: 2024-01-26 11:37:08
+// @brief: 帖子存储-检查MySQL可用
+// @author: Kewin Li
+// @param ctx
+// @param db
+func checkArticleMySQL(ctx context.Context, db *gorm.DB) {
+	sqlDB, err := db.DB()
+	if err == nil {
+		err = sqlDB.PingContext(ctx)
+	}
+	if err != nil {
+		panic("帖子存储MySQL不可用: " + err.Error())
+	}
+}
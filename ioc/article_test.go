@@ -0,0 +1,215 @@
+// Package ioc
+// @Description: 单元测试-帖子存储
+package ioc
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/bwmarrin/snowflake"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"kitbook/internal/repository/dao"
+	"strings"
+	"testing"
+)
+
+// @func: TestLoadArticleStorageConfig
+// @date: 2024-01-28 17:10:32
+// @brief: 单元测试-帖子存储配置读取与校验, 未知后端直接报错
+// @author: Kewin Li
+// @param t
+func TestLoadArticleStorageConfig(t *testing.T) {
+	testCases := []struct {
+		name string
+
+		config string
+
+		wantCfg    func() articleStorageConfig
+		wantErrMsg string
+	}{
+		{
+			name:   "未配置, 默认两边都是mysql",
+			config: "",
+			wantCfg: func() articleStorageConfig {
+				return defaultArticleStorageConfig(articleStorageMySQL, articleStorageMySQL)
+			},
+		},
+		{
+			name: "制作库mongodb, 线上库s3",
+			config: `
+article:
+  storage:
+    author: mongodb
+    reader: s3
+    mongodb:
+      uri: mongodb://mongo:27017
+      nodeId: 3
+    s3:
+      endpoint: http://minio:9000
+      bucket: articles
+      pathStyle: true
+`,
+			wantCfg: func() articleStorageConfig {
+				cfg := defaultArticleStorageConfig(articleStorageMongoDB, articleStorageS3)
+				cfg.MongoDB.URI = "mongodb://mongo:27017"
+				cfg.MongoDB.NodeId = 3
+				cfg.S3.Endpoint = "http://minio:9000"
+				cfg.S3.Bucket = "articles"
+				cfg.S3.PathStyle = true
+				return cfg
+			},
+		},
+		{
+			name: "只配置线上库, 制作库保持默认",
+			config: `
+article:
+  storage:
+    reader: mongodb
+`,
+			wantCfg: func() articleStorageConfig {
+				return defaultArticleStorageConfig(articleStorageMySQL, articleStorageMongoDB)
+			},
+		},
+		{
+			name: "未知的制作库后端",
+			config: `
+article:
+  storage:
+    author: s3
+`,
+			wantErrMsg: "未知的帖子制作库后端",
+		},
+		{
+			name: "未知的线上库后端",
+			config: `
+article:
+  storage:
+    reader: postgres
+`,
+			wantErrMsg: "未知的帖子线上库后端",
+		},
+		{
+			name: "线上库s3未配置bucket",
+			config: `
+article:
+  storage:
+    reader: s3
+`,
+			wantErrMsg: "帖子对象存储bucket未配置",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			viper.Reset()
+			defer viper.Reset()
+			viper.SetConfigType("yaml")
+			require.NoError(t, viper.ReadConfig(strings.NewReader(tc.config)))
+
+			cfg, err := loadArticleStorageConfig()
+			if tc.wantErrMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErrMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantCfg(), cfg)
+		})
+	}
+}
+
+// @func: TestNewArticleDao
+// @date: 2024-01-28 17:15:48
+// @brief: 单元测试-按配置选择帖子存储实现
+// @author: Kewin Li
+// @param t
+func TestNewArticleDao(t *testing.T) {
+	sqlDB, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      sqlDB,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		SkipDefaultTransaction: true,
+		DisableAutomaticPing:   true,
+	})
+	require.NoError(t, err)
+
+	// 不发起连接, 只用于构造实现
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://localhost:27017"))
+	require.NoError(t, err)
+	defer client.Disconnect(context.Background())
+	mdb := client.Database("kitbook")
+
+	node, err := snowflake.NewNode(1)
+	require.NoError(t, err)
+
+	oss := newS3Client("http://localhost:9000", "us-east-1", true)
+
+	testCases := []struct {
+		name string
+
+		author string
+		reader string
+
+		wantDao dao.ArticleDao
+	}{
+		{
+			name:    "两边都是mysql, 同库事务实现",
+			author:  articleStorageMySQL,
+			reader:  articleStorageMySQL,
+			wantDao: &dao.GormArticleDao{},
+		},
+		{
+			name:    "两边都是mongodb",
+			author:  articleStorageMongoDB,
+			reader:  articleStorageMongoDB,
+			wantDao: &dao.MongoDBArticleDAO{},
+		},
+		{
+			name:    "制作库mysql, 线上库s3",
+			author:  articleStorageMySQL,
+			reader:  articleStorageS3,
+			wantDao: &dao.SplitArticleDao{},
+		},
+		{
+			name:    "制作库mongodb, 线上库mysql",
+			author:  articleStorageMongoDB,
+			reader:  articleStorageMySQL,
+			wantDao: &dao.SplitArticleDao{},
+		},
+		{
+			name:    "制作库mysql, 线上库mongodb",
+			author:  articleStorageMySQL,
+			reader:  articleStorageMongoDB,
+			wantDao: &dao.SplitArticleDao{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := defaultArticleStorageConfig(tc.author, tc.reader)
+			cfg.S3.Bucket = "articles"
+
+			artDao := newArticleDao(cfg, db, mdb, node, oss)
+			assert.IsType(t, tc.wantDao, artDao)
+		})
+	}
+}
+
+func defaultArticleStorageConfig(author string, reader string) articleStorageConfig {
+	cfg := articleStorageConfig{
+		Author: author,
+		Reader: reader,
+	}
+	cfg.MongoDB.URI = "mongodb://localhost:27017"
+	cfg.MongoDB.Database = "kitbook"
+	cfg.MongoDB.NodeId = 1
+	return cfg
+}
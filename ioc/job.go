@@ -1,12 +1,17 @@
 package ioc
 
 import (
+	"context"
 	rlock "github.com/gotomicro/redis-lock"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/robfig/cron/v3"
+	"github.com/spf13/viper"
+	"kitbook/internal/domain"
 	"kitbook/internal/job"
 	"kitbook/internal/service"
+	"kitbook/internal/web"
 	"kitbook/pkg/logger"
+	"net/http"
 	"time"
 )
 
@@ -14,7 +19,33 @@ func InitRankingJob(svc service.RankingService, client *rlock.Client, l logger.L
 	return job.NewRankingJob(svc, time.Second*30, client, l)
 }
 
-func InitJobs(l logger.Logger, ranking_job *job.RankingJob) *cron.Cron {
+func InitRankingLocalCacheJob(svc service.RankingService) *job.RankingLocalCacheJob {
+	return job.NewRankingLocalCacheJob(svc, time.Second*3)
+}
+
+// @func: InitStuckJobReaper
+// @date: 2024-01-18 10:48:30
+// @brief: MySQL任务调度-回收续约超时的任务, 租约默认3min(续约间隔1min)
+// @author: Kewin Li
+// @param svc
+// @param l
+// @return *job.StuckJobReaper
+func InitStuckJobReaper(svc service.JobService, l logger.Logger) *job.StuckJobReaper {
+	lease := viper.GetDuration("job.lease")
+	if lease <= 0 {
+		lease = 3 * time.Minute
+	}
+
+	return job.NewStuckJobReaper(svc, lease, prometheus.CounterOpts{
+		Namespace: "kewin",
+		Subsystem: "kitbook",
+		Name:      "job_reclaimed_total",
+		Help:      "回收续约超时的任务数",
+	}, l)
+}
+
+func InitJobs(l logger.Logger, ranking_job *job.RankingJob, local_cache_job *job.RankingLocalCacheJob,
+	reaper *job.StuckJobReaper, search_job *job.SearchIndexRebuildJob) *cron.Cron {
 
 	builder := job.NewCronJobBuilder(l, prometheus.SummaryOpts{
 		Namespace: "kewin",
@@ -36,5 +67,108 @@ func InitJobs(l logger.Logger, ranking_job *job.RankingJob) *cron.Cron {
 		panic(err)
 	}
 
+	// 本地缓存过期时间1min, 每30s从redis刷新一次
+	_, err = expr.AddJob("@every 30s", builder.Build(local_cache_job))
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = expr.AddJob("@every 1m", builder.Build(reaper))
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = expr.AddJob(searchRebuildSpec(), builder.Build(search_job))
+	if err != nil {
+		panic(err)
+	}
+
 	return expr
 }
+
+// @func: InitJobHandler
+// @date: 2024-01-16 16:40:12
+// @brief: 任务调度管理-从配置读取管理员
+// @author: Kewin Li
+// @param svc
+// @param l
+// @return *web.JobHandler
+func InitJobHandler(svc service.JobService, l logger.Logger) *web.JobHandler {
+	admins := make([]int64, 0)
+	for _, uid := range viper.GetIntSlice("job.admins") {
+		admins = append(admins, int64(uid))
+	}
+
+	return web.NewJobHandler(svc, admins, l)
+}
+
+// 本地任务名称
+const (
+	// 定时发表帖子
+	articleSchedulePublishJob = "article_schedule_publish"
+	// 清理未被引用的上传文件
+	uploadOrphanCleanJob = "upload_orphan_clean"
+)
+
+// @func: InitScheduler
+// @date: 2024-01-17 15:25:40
+// @brief: MySQL任务调度-读取结点标签并注册执行器
+// @author: Kewin Li
+// @param svc
+// @param artSvc
+// @param uploadSvc
+// @param l
+// @return *job.Scheduler
+func InitScheduler(svc service.JobService, artSvc service.ArticleService,
+	uploadSvc service.UploadService, l logger.Logger) *job.Scheduler {
+	scheduler := job.NewScheduler(svc, viper.GetStringMapString("job.node.labels"), l)
+
+	// 本地方法在此注册
+	scheduler.RegisterExecutor(job.NewLocalFuncExecutor(map[string]func(ctx context.Context, job domain.Job) error{
+		articleSchedulePublishJob: func(ctx context.Context, job domain.Job) error {
+			_, err := artSvc.PublishDue(ctx, time.Now())
+			return err
+		},
+		uploadOrphanCleanJob: func(ctx context.Context, job domain.Job) error {
+			_, err := uploadSvc.CleanOrphans(ctx, time.Now())
+			return err
+		},
+	}))
+	scheduler.RegisterExecutor(job.NewHttpExecutor(&http.Client{}, svc, viper.GetString("job.callbackUrl"), l))
+
+	initLocalJob(svc, articleSchedulePublishJob, "article.schedule.expression", "*/10 * * * * *", l)
+	initLocalJob(svc, uploadOrphanCleanJob, "upload.orphan.expression", "0 30 3 * * *", l)
+
+	return scheduler
+}
+
+// @func: initLocalJob
+// @date: 2024-01-22 16:30:15
+// @brief: MySQL任务调度-确保本地任务存在, 任务名称唯一, 多个结点同时启动也只会创建一个
+// @author: Kewin Li
+// @param svc
+// @param name
+// @param exprKey cron表达式的配置项
+// @param defaultExpr 未配置时的cron表达式, 支持秒级
+// @param l
+func initLocalJob(svc service.JobService, name string, exprKey string, defaultExpr string, l logger.Logger) {
+	expr := viper.GetString(exprKey)
+	if expr == "" {
+		expr = defaultExpr
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	_, err := svc.Create(ctx, domain.Job{
+		Name:         name,
+		Expression:   expr,
+		ExecutorName: "local",
+		Mode:         domain.JobModeSingle,
+		// 错过的调度只需补一次, 每次都会处理所有到期的数据
+		MisfirePolicy: domain.MisfireFireOnce,
+	})
+	if err != nil && err != service.ErrDuplicateJob {
+		l.ERROR("本地任务创建失败", logger.Error(err), logger.Field{Key: "name", Val: name})
+	}
+}
@@ -5,6 +5,10 @@ import (
 	"github.com/spf13/viper"
 	"kitbook/internal/events"
 	"kitbook/internal/events/article"
+	"kitbook/internal/events/feed"
+	"kitbook/internal/events/notification"
+	"kitbook/internal/events/ranking"
+	"kitbook/internal/events/search"
 )
 
 func InitSaramaClient() sarama.Client {
@@ -40,8 +44,13 @@ func InitSyncProducer(client sarama.Client) sarama.SyncProducer {
 }
 
 // 注意： wire没有办法找到所有同类实现
-func InitConsumers(c *article.InteractiveReadEventConsumer) []events.Consumer {
-
-	return []events.Consumer{c}
+func InitConsumers(c *article.InteractiveReadEventConsumer,
+	historyConsumer *article.HistoryRecordConsumer,
+	feedConsumer *feed.ArticlePublishEventConsumer,
+	rankingConsumer *ranking.InteractiveEventConsumer,
+	searchConsumer *search.ArticleEventConsumer,
+	scheduleFailedConsumer *notification.ScheduleFailedEventConsumer) []events.Consumer {
+
+	return []events.Consumer{c, historyConsumer, feedConsumer, rankingConsumer, searchConsumer, scheduleFailedConsumer}
 
 }
@@ -0,0 +1,99 @@
+// Package ioc
+// @Description: 内容审核
+package ioc
+
+import (
+	"github.com/spf13/viper"
+	"kitbook/internal/service"
+	"kitbook/internal/service/moderation"
+	"kitbook/internal/web"
+	"kitbook/pkg/logger"
+	"kitbook/pkg/sensitive"
+	"net/http"
+	"os"
+	"time"
+)
+
+// @func: InitModerator
+// @date: 2024-01-25 16:50:20
+// @brief: 内容审核-按配置组装审核链: 敏感词 -> 链接 -> 外部审核服务(配置了地址才启用, token从环境变量读取)
+// @author: Kewin Li
+// @param l
+// @return moderation.Moderator
+func InitModerator(l logger.Logger) moderation.Moderator {
+	// 配置管理
+	type Config struct {
+		Words struct {
+			// 词表文件, 每行一个词
+			Banned  string `yaml:"banned"`
+			Suspect string `yaml:"suspect"`
+		} `yaml:"words"`
+		Links struct {
+			Blocked     []string `yaml:"blocked"`
+			Trusted     []string `yaml:"trusted"`
+			MaxExternal int      `yaml:"maxExternal"`
+		} `yaml:"links"`
+		Remote struct {
+			URL     string        `yaml:"url"`
+			Timeout time.Duration `yaml:"timeout"`
+		} `yaml:"remote"`
+	}
+
+	var cfg Config
+	err := viper.UnmarshalKey("moderation", &cfg)
+	if err != nil {
+		panic(err)
+	}
+
+	moderators := []moderation.Moderator{
+		moderation.NewWordModerator(loadMatcher(cfg.Words.Banned), loadMatcher(cfg.Words.Suspect)),
+		moderation.NewLinkModerator(cfg.Links.Blocked, cfg.Links.Trusted, cfg.Links.MaxExternal),
+	}
+	if cfg.Remote.URL != "" {
+		moderators = append(moderators, moderation.NewRemoteModerator(http.DefaultClient,
+			cfg.Remote.URL, os.Getenv("MODERATION_REMOTE_TOKEN"), cfg.Remote.Timeout))
+	}
+
+	return moderation.NewChain(l, moderators...)
+}
+
+// @func: InitReviewHandler
+// @date: 2024-01-25 16:55:48
+// @brief: 内容审核-从配置读取审核员
+// @author: Kewin Li
+// @param svc
+// @param l
+// @return *web.ReviewHandler
+func InitReviewHandler(svc service.ArticleService, l logger.Logger) *web.ReviewHandler {
+	reviewers := make([]int64, 0)
+	for _, uid := range viper.GetIntSlice("moderation.reviewers") {
+		reviewers = append(reviewers, int64(uid))
+	}
+
+	return web.NewReviewHandler(svc, reviewers, l)
+}
+
+// @func: loadMatcher
+// @date: 2024-01-25 16:58:10
+// @brief: 内容审核-读取词表构建匹配器, 未配置时为空词表
+// @author: Kewin Li
+// @param path
+// @return *sensitive.Matcher
+func loadMatcher(path string) *sensitive.Matcher {
+	if path == "" {
+		return sensitive.NewMatcher(nil)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+
+	words, err := sensitive.LoadWords(f)
+	if err != nil {
+		panic(err)
+	}
+
+	return sensitive.NewMatcher(words)
+}
@@ -0,0 +1,90 @@
+package ioc
+
+import (
+	"github.com/spf13/viper"
+	"kitbook/internal/repository"
+	"kitbook/internal/repository/cache"
+	"kitbook/internal/service"
+	"time"
+)
+
+// @func: InitLeaderboards
+// @date: 2024-01-15 21:05:40
+// @brief: 热榜服务-从配置加载榜单, 未配置时使用默认榜单
+// @author: Kewin Li
+// @return []service.Leaderboard
+func InitLeaderboards() []service.Leaderboard {
+	// 配置管理
+	type Config struct {
+		Name   string        `yaml:"name"`
+		Window time.Duration `yaml:"window"`
+		Size   int           `yaml:"size"`
+		// 分数 = (阅读数*readWeight + 点赞数*likeWeight + 收藏数*collectWeight - 1) / (秒数+1)^gravity
+		ReadWeight    float64 `yaml:"readWeight"`
+		LikeWeight    float64 `yaml:"likeWeight"`
+		CollectWeight float64 `yaml:"collectWeight"`
+		Gravity       float64 `yaml:"gravity"`
+	}
+
+	var cfgs []Config
+	err := viper.UnmarshalKey("ranking.boards", &cfgs)
+	if err != nil {
+		panic(err)
+	}
+
+	if len(cfgs) == 0 {
+		return service.DefaultLeaderboards()
+	}
+
+	boards := make([]service.Leaderboard, 0, len(cfgs))
+	for _, cfg := range cfgs {
+		if cfg.Name == "" || cfg.Window <= 0 || cfg.Size <= 0 {
+			panic("榜单配置错误: " + cfg.Name)
+		}
+
+		boards = append(boards, service.Leaderboard{
+			Name:      cfg.Name,
+			Window:    cfg.Window,
+			N:         cfg.Size,
+			ScoreFunc: service.GravityScoreFunc(cfg.ReadWeight, cfg.LikeWeight, cfg.CollectWeight, cfg.Gravity),
+		})
+	}
+
+	return boards
+}
+
+// @func: InitLocalRankingCache
+// @date: 2024-01-16 10:40:26
+// @brief: 热榜服务-本地缓存, 过期时间比redis缓存短, 由定时任务刷新
+// @author: Kewin Li
+// @return *cache.LocalRankingCache
+func InitLocalRankingCache() *cache.LocalRankingCache {
+	return cache.NewLocalRankingCache(time.Minute)
+}
+
+// @func: InitRankingService
+// @date: 2024-01-21 12:20:45
+// @brief: 热榜服务-按配置选择计算模式, batch: 定时全量计算(默认), incr: 互动事件实时增量更新
+// @author: Kewin Li
+// @param intrSvc
+// @param artSvc
+// @param artRepo
+// @param repo
+// @param scoreRepo
+// @param boards
+// @return service.RankingService
+func InitRankingService(intrSvc service.InteractiveService,
+	artSvc service.ArticleService,
+	artRepo repository.ArticleRepository,
+	repo repository.RankingRepository,
+	scoreRepo repository.RankingScoreRepository,
+	boards []service.Leaderboard) service.RankingService {
+	switch viper.GetString("ranking.mode") {
+	case "", "batch":
+		return service.NewBatchRankingService(intrSvc, artSvc, repo, boards)
+	case "incr":
+		return service.NewIncrRankingService(intrSvc, artRepo, scoreRepo, boards)
+	default:
+		panic("热榜计算模式配置错误: " + viper.GetString("ranking.mode"))
+	}
+}
@@ -0,0 +1,28 @@
+// Package ioc
+// @Description: 搜索服务
+package ioc
+
+import (
+	"github.com/spf13/viper"
+	"kitbook/internal/job"
+	"kitbook/internal/service"
+	"time"
+)
+
+func InitSearchIndexRebuildJob(svc service.SearchService) *job.SearchIndexRebuildJob {
+	return job.NewSearchIndexRebuildJob(svc, 10*time.Minute)
+}
+
+// @func: searchRebuildSpec
+// @date: 2024-01-23 16:55:40
+// @brief: 搜索索引定时重建间隔, 默认1h
+// @author: Kewin Li
+// @return string
+func searchRebuildSpec() string {
+	interval := viper.GetDuration("search.rebuild.interval")
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	return "@every " + interval.String()
+}
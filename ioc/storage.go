@@ -0,0 +1,144 @@
+// Package ioc
+// @Description: 对象存储、上传服务
+package ioc
+
+import (
+	"crypto/rand"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/spf13/viper"
+	"kitbook/internal/service"
+	"kitbook/pkg/logger"
+	"kitbook/pkg/storage"
+	"os"
+)
+
+// @func: InitStorage
+// @date: 2024-01-24 16:35:20
+// @brief: 对象存储-按配置选择后端, local: 本地文件系统(开发测试); s3: S3兼容存储, 密钥从环境变量读取
+// @author: Kewin Li
+// @param l
+// @return storage.Storage
+func InitStorage(l logger.Logger) storage.Storage {
+	// 配置管理
+	type Config struct {
+		Backend string `yaml:"backend"`
+		Local   struct {
+			Dir     string `yaml:"dir"`
+			BaseURL string `yaml:"baseURL"`
+		} `yaml:"local"`
+		S3 struct {
+			Endpoint string `yaml:"endpoint"`
+			Region   string `yaml:"region"`
+			Bucket   string `yaml:"bucket"`
+			// MinIO等自建存储通常需要路径风格
+			PathStyle bool `yaml:"pathStyle"`
+		} `yaml:"s3"`
+	}
+
+	cfg := Config{
+		Backend: "local",
+	}
+	err := viper.UnmarshalKey("storage", &cfg)
+	if err != nil {
+		panic(err)
+	}
+
+	switch cfg.Backend {
+	case "local":
+		if cfg.Local.Dir == "" {
+			cfg.Local.Dir = "./data/storage"
+		}
+		if cfg.Local.BaseURL == "" {
+			cfg.Local.BaseURL = "http://localhost:8080/storage"
+		}
+		return storage.NewLocalStorage(cfg.Local.Dir, cfg.Local.BaseURL, localStorageSecret(l))
+
+	case "s3":
+		if cfg.S3.Bucket == "" {
+			panic("对象存储bucket未配置")
+		}
+		return storage.NewS3Storage(newS3Client(cfg.S3.Endpoint, cfg.S3.Region, cfg.S3.PathStyle), cfg.S3.Bucket)
+
+	default:
+		panic("未知的存储后端: " + cfg.Backend)
+	}
+}
+
+// @func: localStorageSecret
+// @date: 2024-01-28 15:05:12
+// @brief: 对象存储-本地存储的签名密钥从环境变量读取, 未配置时(开发环境)生成临时密钥, 重启后已签发的链接失效
+// @author: Kewin Li
+// @param l
+// @return []byte
+func localStorageSecret(l logger.Logger) []byte {
+	if secret := os.Getenv("STORAGE_LOCAL_SECRET"); secret != "" {
+		return []byte(secret)
+	}
+
+	secret := make([]byte, 32)
+	_, err := rand.Read(secret)
+	if err != nil {
+		panic(err)
+	}
+	l.WARN("本地存储签名密钥未配置, 使用临时密钥, 重启后已签发的上传、下载链接失效",
+		logger.Field{"env", "STORAGE_LOCAL_SECRET"})
+
+	return secret
+}
+
+// @func: InitUploadLimits
+// @date: 2024-01-24 16:38:46
+// @brief: 上传服务-从配置加载限制, 未配置的项使用默认值
+// @author: Kewin Li
+// @return service.UploadLimits
+func InitUploadLimits() service.UploadLimits {
+	limits := service.DefaultUploadLimits()
+
+	if v := viper.GetInt64("upload.maxImageSize"); v > 0 {
+		limits.MaxImageSize = v
+	}
+	if v := viper.GetInt64("upload.maxAttachmentSize"); v > 0 {
+		limits.MaxAttachmentSize = v
+	}
+	if v := viper.GetInt64("upload.userQuota"); v > 0 {
+		limits.UserQuota = v
+	}
+	if v := viper.GetDuration("upload.presignExpire"); v > 0 {
+		limits.PresignExpire = v
+	}
+	if v := viper.GetDuration("upload.downloadExpire"); v > 0 {
+		limits.DownloadExpire = v
+	}
+	if v := viper.GetDuration("upload.orphan.grace"); v > 0 {
+		limits.OrphanGrace = v
+	}
+
+	return limits
+}
+
+// @func: newS3Client
+// @date: 2024-01-26 11:20:36
+// @brief: 对象存储-创建S3客户端, 密钥从环境变量读取
+// @author: Kewin Li
+// @param endpoint
+// @param region
+// @param pathStyle MinIO等自建存储通常需要路径风格
+// @return *s3.S3
+func newS3Client(endpoint string, region string, pathStyle bool) *s3.S3 {
+	sess, err := session.NewSession(&aws.Config{
+		Credentials: credentials.NewStaticCredentials(
+			os.Getenv("STORAGE_S3_ACCESS_KEY_ID"),
+			os.Getenv("STORAGE_S3_SECRET_ACCESS_KEY"), ""),
+		Region:           aws.String(region),
+		Endpoint:         aws.String(endpoint),
+		S3ForcePathStyle: aws.Bool(pathStyle),
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	return s3.New(sess)
+}
@@ -20,13 +20,35 @@ import (
 func InitWebServer(middlewares []gin.HandlerFunc,
 	userHdl *web.UserHandler,
 	wechatHdl *web.OAuth2WechatHandler,
-	articleHdl *web.ArticleHandler) *gin.Engine {
+	articleHdl *web.ArticleHandler,
+	historyHdl *web.HistoryHandler,
+	commentHdl *web.CommentHandler,
+	followHdl *web.FollowHandler,
+	feedHdl *web.FeedHandler,
+	collectionHdl *web.CollectionHandler,
+	rankingHdl *web.RankingHandler,
+	jobHdl *web.JobHandler,
+	searchHdl *web.SearchHandler,
+	uploadHdl *web.UploadHandler,
+	reviewHdl *web.ReviewHandler,
+	notificationHdl *web.NotificationHandler) *gin.Engine {
 
 	server := gin.Default()
 	server.Use(middlewares...)
 	userHdl.RegisterRoutes(server)
 	wechatHdl.RegisterRoutes(server)
 	articleHdl.RegisterRoutes(server)
+	historyHdl.RegisterRoutes(server)
+	commentHdl.RegisterRoutes(server)
+	followHdl.RegisterRoutes(server)
+	feedHdl.RegisterRoutes(server)
+	collectionHdl.RegisterRoutes(server)
+	rankingHdl.RegisterRoutes(server)
+	jobHdl.RegisterRoutes(server)
+	searchHdl.RegisterRoutes(server)
+	uploadHdl.RegisterRoutes(server)
+	reviewHdl.RegisterRoutes(server)
+	notificationHdl.RegisterRoutes(server)
 	return server
 }
 
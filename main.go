@@ -31,6 +31,11 @@ func main() {
 		<-app.cron.Stop().Done()
 	}()
 
+	// 开始MySQL任务调度
+	schedulerCtx, schedulerCancel := context.WithCancel(context.Background())
+	defer schedulerCancel()
+	go app.scheduler.Schedule(schedulerCtx)
+
 	server := app.server
 	for _, c := range app.consumers {
 		err := c.Start()
@@ -6,30 +6,57 @@ mockgen -source=D:./internal/service/user.go -package=svcmocks -destination=./in
 mockgen -source=D:./internal/service/code.go -package=svcmocks -destination=./internal/service/mocks/code.mock.go
 mockgen -source=D:./internal/service/article.go -package=svcmocks -destination=./internal/service/mocks/article.mock.go
 mockgen -source=D:./internal/service/interactive.go -package=svcmocks -destination=./internal/service/mocks/interactive.mock.go
+mockgen -source=D:./internal/service/history.go -package=svcmocks -destination=./internal/service/mocks/history.mock.go
+mockgen -source=D:./internal/service/comment.go -package=svcmocks -destination=./internal/service/mocks/comment.mock.go
+mockgen -source=D:./internal/service/follow.go -package=svcmocks -destination=./internal/service/mocks/follow.mock.go
+mockgen -source=D:./internal/service/feed.go -package=svcmocks -destination=./internal/service/mocks/feed.mock.go
+mockgen -source=D:./internal/service/collection.go -package=svcmocks -destination=./internal/service/mocks/collection.mock.go
+mockgen -source=D:./internal/service/job.go -package=svcmocks -destination=./internal/service/mocks/job.mock.go
+mockgen -source=D:./internal/service/search.go -package=svcmocks -destination=./internal/service/mocks/search.mock.go
+mockgen -source=D:./internal/service/upload.go -package=svcmocks -destination=./internal/service/mocks/upload.mock.go
+mockgen -source=D:./internal/service/notification.go -package=svcmocks -destination=./internal/service/mocks/notification.mock.go
 
 
 mockgen -source=D:./internal/service/sms/types.go -package=smsmocks -destination=./internal/service/sms/mocks/sms.mock.go
+mockgen -source=D:./internal/service/moderation/types.go -package=moderationmocks -destination=./internal/service/moderation/mocks/moderation.mock.go
 
 mockgen -source=D:./internal/repository/user.go -package=repomocks -destination=./internal/repository/mocks/user.mock.go
 mockgen -source=D:./internal/repository/code.go -package=repomocks -destination=./internal/repository/mocks/code.mock.go
 mockgen -source=D:./internal/repository/article.go -package=repomocks -destination=./internal/repository/mocks/article.mock.go
+mockgen -source=D:./internal/repository/article_revision.go -package=repomocks -destination=./internal/repository/mocks/article_revision.mock.go
+mockgen -source=D:./internal/repository/article_schedule.go -package=repomocks -destination=./internal/repository/mocks/article_schedule.mock.go
+mockgen -source=D:./internal/repository/article_review.go -package=repomocks -destination=./internal/repository/mocks/article_review.mock.go
+mockgen -source=D:./internal/repository/article_search.go -package=repomocks -destination=./internal/repository/mocks/article_search.mock.go
+mockgen -source=D:./internal/repository/upload.go -package=repomocks -destination=./internal/repository/mocks/upload.mock.go
+mockgen -source=D:./internal/repository/notification.go -package=repomocks -destination=./internal/repository/mocks/notification.mock.go
 mockgen -source=D:./internal/repository/article_author.go -package=repomocks -destination=./internal/repository/mocks/article_author.mock.go
 mockgen -source=D:./internal/repository/article_reader.go -package=repomocks -destination=./internal/repository/mocks/article_reader.mock.go
+mockgen -source=D:./internal/repository/comment.go -package=repomocks -destination=./internal/repository/mocks/comment.mock.go
+mockgen -source=D:./internal/repository/follow.go -package=repomocks -destination=./internal/repository/mocks/follow.mock.go
+mockgen -source=D:./internal/repository/feed.go -package=repomocks -destination=./internal/repository/mocks/feed.mock.go
+mockgen -source=D:./internal/repository/collection.go -package=repomocks -destination=./internal/repository/mocks/collection.mock.go
+mockgen -source=D:./internal/repository/job.go -package=repomocks -destination=./internal/repository/mocks/job.mock.go
+mockgen -source=D:./internal/repository/ranking.go -package=repomocks -destination=./internal/repository/mocks/ranking.mock.go
 
 mockgen -source=D:./internal/repository/dao/user.go -package=daomocks -destination=./internal/repository/dao/mocks/user.mock.go
 mockgen -source=D:./internal/repository/dao/article.go -package=daomocks -destination=./internal/repository/dao/mocks/article.mock.go
+mockgen -source=D:./internal/repository/dao/article_tag.go -package=daomocks -destination=./internal/repository/dao/mocks/article_tag.mock.go
+mockgen -source=D:./internal/repository/dao/upload_ref.go -package=daomocks -destination=./internal/repository/dao/mocks/upload_ref.mock.go
 mockgen -source=D:./internal/repository/dao/article_author.go -package=daomocks -destination=./internal/repository/dao/mocks/article_author.mock.go
 mockgen -source=D:./internal/repository/dao/article_reader.go -package=daomocks -destination=./internal/repository/dao/mocks/article_reader.mock.go
 
 
 mockgen -source=D:./internal/repository/cache/user.go -package=cachemocks -destination=./internal/repository/cache/mocks/user.mock.go
 mockgen -source=D:./internal/repository/cache/code.go -package=cachemocks -destination=./internal/repository/cache/mocks/code.mock.go
+mockgen -source=D:./internal/repository/cache/ranking.go -package=cachemocks -destination=./internal/repository/cache/mocks/ranking.mock.go
+mockgen -source=D:./internal/repository/cache/job.go -package=cachemocks -destination=./internal/repository/cache/mocks/job.mock.go
 
 mockgen -package=redismocks -destination=./internal/repository/cache/redismocks/cmd.mock.go github.com/redis/go-redis/v9 Cmdable
 
 mockgen -source=D:./pkg/limiter/types.go -package=limitermocks -destination=./pkg/limiter/mocks/limiter.mock.go
 
 mockgen -source=D:./internal/web/jwt/types.go -package=jwtmocks -destination=./internal/web/jwt/mocks/jwt.mock.go
+mockgen -source=D:./internal/events/article/producer.go -package=evtmocks -destination=./internal/events/article/mocks/producer.mock.go
 
 go mod tidy
 
@@ -0,0 +1,129 @@
+package fulltext
+
+import (
+	"html"
+	"strings"
+	"unicode/utf8"
+)
+
+// 高亮标签
+const (
+	HighlightPre  = "<em>"
+	HighlightPost = "</em>"
+)
+
+// 截取摘要时, 第一个命中词之前保留的字数占摘要长度的比例
+const snippetLeadRatio = 4
+
+// @func: Highlight
+// @date: 2024-01-23 14:45:20
+// @brief: 用高亮标签包裹文本中的查询词, 其余内容做HTML转义
+// @author: Kewin Li
+// @param text
+// @param query
+// @param maxRunes 大于0且文本更长时, 截取第一个命中词附近的摘要
+// @return string
+func Highlight(text string, query string, maxRunes int) string {
+	spans := matchSpans(text, query)
+
+	start, end := 0, len(text)
+	if maxRunes > 0 && utf8.RuneCountInString(text) > maxRunes {
+		start, end = snippetRange(text, spans, maxRunes)
+	}
+
+	var sb strings.Builder
+	if start > 0 {
+		sb.WriteString("...")
+	}
+
+	pos := start
+	for _, sp := range spans {
+		if sp[1] <= start || sp[0] >= end {
+			continue
+		}
+		from, to := max(sp[0], start), min(sp[1], end)
+		sb.WriteString(html.EscapeString(text[pos:from]))
+		sb.WriteString(HighlightPre)
+		sb.WriteString(html.EscapeString(text[from:to]))
+		sb.WriteString(HighlightPost)
+		pos = to
+	}
+	sb.WriteString(html.EscapeString(text[pos:end]))
+
+	if end < len(text) {
+		sb.WriteString("...")
+	}
+
+	return sb.String()
+}
+
+// @func: matchSpans
+// @date: 2024-01-23 14:48:36
+// @brief: 查询词在文本中出现的位置, 重叠、相邻的合并, 按位置有序
+// @author: Kewin Li
+// @param text
+// @param query
+// @return [][2]int 字节偏移区间[start, end)
+func matchSpans(text string, query string) [][2]int {
+	terms := queryTerms(query)
+	if len(terms) == 0 {
+		return nil
+	}
+
+	set := make(map[string]struct{}, len(terms))
+	for _, term := range terms {
+		set[term] = struct{}{}
+	}
+
+	var spans [][2]int
+	// 分词结果按起始位置有序
+	for _, t := range Tokenize(text) {
+		if _, ok := set[t.Term]; !ok {
+			continue
+		}
+
+		last := len(spans) - 1
+		if last >= 0 && t.Start <= spans[last][1] {
+			spans[last][1] = max(spans[last][1], t.End)
+			continue
+		}
+		spans = append(spans, [2]int{t.Start, t.End})
+	}
+
+	return spans
+}
+
+// @func: snippetRange
+// @date: 2024-01-23 14:52:10
+// @brief: 摘要区间, 以第一个命中词为中心偏前的位置截取maxRunes个字
+// @author: Kewin Li
+// @param text
+// @param spans
+// @param maxRunes
+// @return int 起始字节偏移
+// @return int 结束字节偏移
+func snippetRange(text string, spans [][2]int, maxRunes int) (int, int) {
+	start := 0
+	if len(spans) > 0 {
+		start = spans[0][0]
+		// 向前保留一部分上下文
+		for lead := maxRunes / snippetLeadRatio; lead > 0 && start > 0; lead-- {
+			_, size := utf8.DecodeLastRuneInString(text[:start])
+			start -= size
+		}
+	}
+
+	end := start
+	for cnt := 0; cnt < maxRunes && end < len(text); cnt++ {
+		_, size := utf8.DecodeRuneInString(text[end:])
+		end += size
+	}
+
+	// 命中词靠近结尾时, 向前补足长度
+	for cnt := utf8.RuneCountInString(text[start:end]); cnt < maxRunes && start > 0; cnt++ {
+		_, size := utf8.DecodeLastRuneInString(text[:start])
+		start -= size
+	}
+
+	return start, end
+}
@@ -0,0 +1,256 @@
+package fulltext
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// BM25参数
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Field
+// @Description: 建索引的字段及其权重
+type Field struct {
+	Name  string
+	Boost float64
+}
+
+// Hit
+// @Description: 命中的文档及相关度得分
+type Hit struct {
+	Id    int64
+	Score float64
+}
+
+// Index
+// @Description: 内存倒排索引, 按BM25计算各字段得分后加权求和, 并发安全
+type Index struct {
+	mu     sync.RWMutex
+	fields []Field
+	// 字段名 -> 字段下标
+	fieldIdx map[string]int
+
+	// 词 -> 文档ID -> 各字段词频
+	postings map[string]map[int64][]int
+	docs     map[int64]document
+	// 各字段词数之和, 用于计算平均长度
+	totalLens []int
+}
+
+// document
+// @Description: 已建索引的文档, 记录包含的词用于删除
+type document struct {
+	// 各字段词数
+	lens  []int
+	terms []string
+}
+
+func NewIndex(fields ...Field) *Index {
+	fieldIdx := make(map[string]int, len(fields))
+	for i, f := range fields {
+		fieldIdx[f.Name] = i
+	}
+
+	return &Index{
+		fields:    fields,
+		fieldIdx:  fieldIdx,
+		postings:  make(map[string]map[int64][]int),
+		docs:      make(map[int64]document),
+		totalLens: make([]int, len(fields)),
+	}
+}
+
+// @func: Put
+// @date: 2024-01-23 14:20:36
+// @brief: 新增或覆盖文档, 未声明的字段忽略
+// @author: Kewin Li
+// @receiver idx
+// @param id
+// @param values 字段名 -> 字段内容
+func (idx *Index) Put(id int64, values map[string]string) {
+	lens := make([]int, len(idx.fields))
+	tfs := make(map[string][]int)
+	for name, text := range values {
+		fi, ok := idx.fieldIdx[name]
+		if !ok {
+			continue
+		}
+
+		for _, t := range Tokenize(text) {
+			tf, ok := tfs[t.Term]
+			if !ok {
+				tf = make([]int, len(idx.fields))
+				tfs[t.Term] = tf
+			}
+			tf[fi]++
+			lens[fi]++
+		}
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.delete(id)
+	terms := make([]string, 0, len(tfs))
+	for term, tf := range tfs {
+		docs, ok := idx.postings[term]
+		if !ok {
+			docs = make(map[int64][]int)
+			idx.postings[term] = docs
+		}
+		docs[id] = tf
+		terms = append(terms, term)
+	}
+	idx.docs[id] = document{lens: lens, terms: terms}
+	for i, l := range lens {
+		idx.totalLens[i] += l
+	}
+}
+
+// @func: Delete
+// @date: 2024-01-23 14:22:48
+// @brief: 删除文档, 不存在时忽略
+// @author: Kewin Li
+// @receiver idx
+// @param id
+func (idx *Index) Delete(id int64) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.delete(id)
+}
+
+// @func: delete
+// @date: 2024-01-23 14:24:10
+// @brief: 删除文档, 调用方持有写锁
+// @author: Kewin Li
+// @receiver idx
+// @param id
+func (idx *Index) delete(id int64) {
+	doc, ok := idx.docs[id]
+	if !ok {
+		return
+	}
+
+	for _, term := range doc.terms {
+		docs := idx.postings[term]
+		delete(docs, id)
+		if len(docs) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+
+	for i, l := range doc.lens {
+		idx.totalLens[i] -= l
+	}
+	delete(idx.docs, id)
+}
+
+// @func: Len
+// @date: 2024-01-23 14:25:32
+// @brief: 文档数
+// @author: Kewin Li
+// @receiver idx
+// @return int
+func (idx *Index) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	return len(idx.docs)
+}
+
+// @func: Search
+// @date: 2024-01-23 14:30:15
+// @brief: 检索包含全部查询词的文档, 按得分从高到低分页
+// @author: Kewin Li
+// @receiver idx
+// @param query
+// @param offset
+// @param limit
+// @return []Hit
+// @return int 命中总数
+func (idx *Index) Search(query string, offset int, limit int) ([]Hit, int) {
+	terms := queryTerms(query)
+	if len(terms) == 0 {
+		return nil, 0
+	}
+
+	idx.mu.RLock()
+	n := len(idx.docs)
+	avgLens := make([]float64, len(idx.fields))
+	for i, l := range idx.totalLens {
+		if n > 0 {
+			avgLens[i] = float64(l) / float64(n)
+		}
+	}
+
+	scores := make(map[int64]float64)
+	for i, term := range terms {
+		docs := idx.postings[term]
+		if len(docs) == 0 {
+			// 要求包含全部查询词
+			idx.mu.RUnlock()
+			return nil, 0
+		}
+
+		df := float64(len(docs))
+		idf := math.Log(1 + (float64(n)-df+0.5)/(df+0.5))
+		next := make(map[int64]float64, len(docs))
+		for id, tf := range docs {
+			prev, ok := scores[id]
+			if i > 0 && !ok {
+				continue
+			}
+			next[id] = prev + idf*idx.fieldsScore(tf, idx.docs[id].lens, avgLens)
+		}
+		scores = next
+	}
+	idx.mu.RUnlock()
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, Hit{Id: id, Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		// 得分相同时新文档在前
+		return hits[i].Id > hits[j].Id
+	})
+
+	total := len(hits)
+	if offset >= total {
+		return []Hit{}, total
+	}
+
+	return hits[offset:min(offset+limit, total)], total
+}
+
+// @func: fieldsScore
+// @date: 2024-01-23 14:33:40
+// @brief: 一个词在文档各字段的BM25词频得分加权求和
+// @author: Kewin Li
+// @receiver idx
+// @param tf
+// @param lens
+// @param avgLens
+// @return float64
+func (idx *Index) fieldsScore(tf []int, lens []int, avgLens []float64) float64 {
+	var score float64
+	for i, f := range idx.fields {
+		if tf[i] == 0 || avgLens[i] == 0 {
+			continue
+		}
+
+		freq := float64(tf[i])
+		norm := 1 - bm25B + bm25B*float64(lens[i])/avgLens[i]
+		score += f.Boost * freq * (bm25K1 + 1) / (freq + bm25K1*norm)
+	}
+
+	return score
+}
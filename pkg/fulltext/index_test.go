@@ -0,0 +1,80 @@
+package fulltext
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// @func: TestTokenize
+// @date: 2024-01-23 15:02:18
+// @brief: 单元测试-中英文混合分词
+// @author: Kewin Li
+// @param t
+func TestTokenize(t *testing.T) {
+	terms := func(tokens []Token) []string {
+		res := make([]string, 0, len(tokens))
+		for _, tk := range tokens {
+			res = append(res, tk.Term)
+		}
+		return res
+	}
+
+	assert.Equal(t, []string{"go", "并", "并发", "发", "发编", "编", "编程", "程", "v2"},
+		terms(Tokenize("Go并发编程 v2")))
+	assert.Equal(t, []string{"go", "并发", "发编", "编程", "v2"},
+		terms(TokenizeQuery("Go并发编程 v2")))
+	// 单个汉字查询保留单字
+	assert.Equal(t, []string{"锁"}, terms(TokenizeQuery("锁")))
+}
+
+// @func: TestIndex_Search
+// @date: 2024-01-23 15:05:40
+// @brief: 单元测试-检索排序、分页、覆盖与删除
+// @author: Kewin Li
+// @param t
+func TestIndex_Search(t *testing.T) {
+	idx := NewIndex(Field{Name: "title", Boost: 3}, Field{Name: "content", Boost: 1})
+	idx.Put(1, map[string]string{"title": "Redis入门", "content": "分布式锁可以基于Redis实现"})
+	idx.Put(2, map[string]string{"title": "分布式锁详解", "content": "介绍分布式锁的几种实现"})
+	idx.Put(3, map[string]string{"title": "Go语言", "content": "并发编程"})
+
+	// 标题命中的排在前面
+	hits, total := idx.Search("分布式锁", 0, 10)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, int64(2), hits[0].Id)
+	assert.Equal(t, int64(1), hits[1].Id)
+
+	// 要求包含全部查询词
+	_, total = idx.Search("Redis 并发", 0, 10)
+	assert.Equal(t, 0, total)
+
+	hits, total = idx.Search("分布式锁", 1, 10)
+	assert.Equal(t, 2, total)
+	assert.Len(t, hits, 1)
+
+	// 覆盖后旧内容不再命中
+	idx.Put(2, map[string]string{"title": "消息队列", "content": "Kafka"})
+	hits, total = idx.Search("分布式锁", 0, 10)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, int64(1), hits[0].Id)
+
+	idx.Delete(1)
+	_, total = idx.Search("redis", 0, 10)
+	assert.Equal(t, 0, total)
+	assert.Equal(t, 2, idx.Len())
+}
+
+// @func: TestHighlight
+// @date: 2024-01-23 15:08:52
+// @brief: 单元测试-高亮与摘要截取
+// @author: Kewin Li
+// @param t
+func TestHighlight(t *testing.T) {
+	assert.Equal(t, "<em>Redis</em>实现<em>分布式锁</em>",
+		Highlight("Redis实现分布式锁", "redis 分布式锁", 0))
+	// 其余内容转义
+	assert.Equal(t, "&lt;b&gt;<em>Go</em>",
+		Highlight("<b>Go", "go", 0))
+	assert.Equal(t, "...一二<em>分布</em>三四五六...",
+		Highlight("甲乙丙丁戊己一二分布三四五六七八九十", "分布", 8))
+}
@@ -0,0 +1,127 @@
+// Package fulltext
+// @Description: 可嵌入的全文检索: 中英文分词、内存倒排索引(BM25排序)、关键词高亮
+package fulltext
+
+import (
+	"strings"
+	"unicode"
+)
+
+// 单词最大长度(字节), 超过的视为无意义内容不建索引
+const maxWordLen = 64
+
+// Token
+// @Description: 分词结果, Start、End为词在原文中的字节偏移
+type Token struct {
+	Term  string
+	Start int
+	End   int
+}
+
+// @func: Tokenize
+// @date: 2024-01-23 14:05:12
+// @brief: 索引分词-英文、数字按单词切分并转小写, 连续汉字同时切出单字和相邻二元组
+// @author: Kewin Li
+// @param text
+// @return []Token
+func Tokenize(text string) []Token {
+	return tokenize(text, true)
+}
+
+// @func: TokenizeQuery
+// @date: 2024-01-23 14:06:40
+// @brief: 查询分词-连续汉字只切相邻二元组(单个汉字除外), 避免单字匹配带来大量噪音
+// @author: Kewin Li
+// @param text
+// @return []Token
+func TokenizeQuery(text string) []Token {
+	return tokenize(text, false)
+}
+
+// @func: tokenize
+// @date: 2024-01-23 14:08:26
+// @brief: 分词实现
+// @author: Kewin Li
+// @param text
+// @param unigram 连续汉字是否切出单字
+// @return []Token
+func tokenize(text string, unigram bool) []Token {
+	var res []Token
+
+	// 当前连续汉字中每个字的起始偏移
+	var han []int
+	flushHan := func(end int) {
+		n := len(han)
+		for i := 0; i < n; i++ {
+			next := end
+			if i+1 < n {
+				next = han[i+1]
+			}
+			if unigram || n == 1 {
+				res = append(res, Token{Term: text[han[i]:next], Start: han[i], End: next})
+			}
+
+			if i+1 < n {
+				after := end
+				if i+2 < n {
+					after = han[i+2]
+				}
+				res = append(res, Token{Term: text[han[i]:after], Start: han[i], End: after})
+			}
+		}
+		han = han[:0]
+	}
+
+	// 当前单词的起始偏移, -1表示不在单词中
+	wordStart := -1
+	flushWord := func(end int) {
+		if wordStart < 0 {
+			return
+		}
+		if end-wordStart <= maxWordLen {
+			res = append(res, Token{Term: strings.ToLower(text[wordStart:end]), Start: wordStart, End: end})
+		}
+		wordStart = -1
+	}
+
+	for i, r := range text {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flushWord(i)
+			han = append(han, i)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushHan(i)
+			if wordStart < 0 {
+				wordStart = i
+			}
+		default:
+			flushWord(i)
+			flushHan(i)
+		}
+	}
+	flushWord(len(text))
+	flushHan(len(text))
+
+	return res
+}
+
+// @func: queryTerms
+// @date: 2024-01-23 14:12:50
+// @brief: 查询去重后的词
+// @author: Kewin Li
+// @param query
+// @return []string
+func queryTerms(query string) []string {
+	tokens := TokenizeQuery(query)
+	seen := make(map[string]struct{}, len(tokens))
+	res := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		if _, ok := seen[t.Term]; ok {
+			continue
+		}
+		seen[t.Term] = struct{}{}
+		res = append(res, t.Term)
+	}
+
+	return res
+}
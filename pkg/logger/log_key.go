@@ -10,6 +10,9 @@ const (
 	LOG_USER_REFRESHTOKEN
 	LOG_USER_SENDCODE
 	LOG_USER_LOGOUT
+	LOG_USER_HISTORY
+	LOG_USER_HISTORY_CLEAR
+	LOG_USER_NOTIFICATION
 )
 
 // 微信模块
@@ -28,17 +31,102 @@ const (
 	LOG_ART_PUBDETAIL
 	LOG_ART_LIKE
 	LOG_ART_COLLECT
+	LOG_ART_PUBTAG
+	LOG_ART_TAGS
+	LOG_ART_REVISIONS
+	LOG_ART_DIFF
+	LOG_ART_ROLLBACK
+	LOG_ART_SCHEDULE
+	LOG_ART_CANCEL_SCHEDULE
+	LOG_ART_GET_SCHEDULE
+	LOG_ART_AUTOSAVE
+	LOG_ART_GET_REVIEW
+)
+
+// 评论模块
+const (
+	LOG_COMMENT_CREATE = iota
+	LOG_COMMENT_DELETE
+	LOG_COMMENT_LIST
+	LOG_COMMENT_REPLIES
+)
+
+// 关注模块
+const (
+	LOG_FOLLOW_FOLLOW = iota
+	LOG_FOLLOW_CANCEL
+	LOG_FOLLOW_FOLLOWERS
+	LOG_FOLLOW_FOLLOWEES
+	LOG_FOLLOW_STATICS
+)
+
+// feed流模块
+const (
+	LOG_FEED_LIST = iota
+)
+
+// 收藏夹模块
+const (
+	LOG_COLLECTION_CREATE = iota
+	LOG_COLLECTION_EDIT
+	LOG_COLLECTION_DELETE
+	LOG_COLLECTION_LIST
+	LOG_COLLECTION_ITEMS
+	LOG_COLLECTION_MOVE
+)
+
+// 热榜模块
+const (
+	LOG_RANKING_TOPN = iota
+)
+
+// 任务调度模块
+const (
+	LOG_JOB_CREATE = iota
+	LOG_JOB_UPDATE
+	LOG_JOB_DELETE
+	LOG_JOB_PAUSE
+	LOG_JOB_RESUME
+	LOG_JOB_TRIGGER
+	LOG_JOB_LIST
+	LOG_JOB_EXECUTIONS
+	LOG_JOB_CALLBACK
+	LOG_JOB_DAG
+	LOG_JOB_NODES
+)
+
+// 搜索模块
+const (
+	LOG_SEARCH_ARTICLES = iota
+)
+
+// 上传模块
+const (
+	LOG_UPLOAD_FILE = iota
+	LOG_UPLOAD_PRESIGN
+	LOG_UPLOAD_CONFIRM
+	LOG_UPLOAD_DOWNLOAD
+)
+
+// 内容审核模块
+const (
+	LOG_REVIEW_PENDING = iota
+	LOG_REVIEW_APPROVE
+	LOG_REVIEW_REJECT
 )
 
 // 用户模块报错key
 var UserLogMsgKey = map[int]string{
-	LOG_USER_SIGNUP:       "user_signup_log",
-	LOG_USER_LOGIN:        "user_login_log",
-	LOG_USER_LOGINSMS:     "user_loginsms_log",
-	LOG_USER_EDIT:         "user_edit_log",
-	LOG_USER_PROFILE:      "user_profile_log",
-	LOG_USER_REFRESHTOKEN: "user_refresh_log",
-	LOG_USER_LOGOUT:       "user_logout_log",
+	LOG_USER_SIGNUP:        "user_signup_log",
+	LOG_USER_LOGIN:         "user_login_log",
+	LOG_USER_LOGINSMS:      "user_loginsms_log",
+	LOG_USER_EDIT:          "user_edit_log",
+	LOG_USER_PROFILE:       "user_profile_log",
+	LOG_USER_REFRESHTOKEN:  "user_refresh_log",
+	LOG_USER_LOGOUT:        "user_logout_log",
+	LOG_USER_HISTORY:       "user_history_log",
+	LOG_USER_HISTORY_CLEAR: "user_history_clear_log",
+	LOG_USER_NOTIFICATION:  "user_notification_log",
 }
 
 // 微信模块报错key
@@ -49,12 +137,94 @@ var WechatLogMsgKey = map[int]string{
 
 // 帖子模块报错
 var ArticleLogMsgKey = map[int]string{
-	LOG_ART_EDIT:      "art_edit_log",
-	LOG_ART_PUBLISH:   "art_publish_log",
-	LOG_ART_WITHDRAW:  "art_withdraw_log",
-	LOG_ART_DETAIL:    "art_detail_log",
-	LOG_ART_LIST:      "art_list_log",
-	LOG_ART_PUBDETAIL: "art_pub_detail_log",
-	LOG_ART_LIKE:      "art_like_log",
-	LOG_ART_COLLECT:   "art_collect_log",
+	LOG_ART_EDIT:            "art_edit_log",
+	LOG_ART_PUBLISH:         "art_publish_log",
+	LOG_ART_WITHDRAW:        "art_withdraw_log",
+	LOG_ART_DETAIL:          "art_detail_log",
+	LOG_ART_LIST:            "art_list_log",
+	LOG_ART_PUBDETAIL:       "art_pub_detail_log",
+	LOG_ART_LIKE:            "art_like_log",
+	LOG_ART_COLLECT:         "art_collect_log",
+	LOG_ART_PUBTAG:          "art_pub_tag_log",
+	LOG_ART_TAGS:            "art_tags_log",
+	LOG_ART_REVISIONS:       "art_revisions_log",
+	LOG_ART_DIFF:            "art_diff_log",
+	LOG_ART_ROLLBACK:        "art_rollback_log",
+	LOG_ART_SCHEDULE:        "art_schedule_log",
+	LOG_ART_CANCEL_SCHEDULE: "art_cancel_schedule_log",
+	LOG_ART_GET_SCHEDULE:    "art_get_schedule_log",
+	LOG_ART_AUTOSAVE:        "art_autosave_log",
+	LOG_ART_GET_REVIEW:      "art_get_review_log",
+}
+
+// 评论模块报错key
+var CommentLogMsgKey = map[int]string{
+	LOG_COMMENT_CREATE:  "comment_create_log",
+	LOG_COMMENT_DELETE:  "comment_delete_log",
+	LOG_COMMENT_LIST:    "comment_list_log",
+	LOG_COMMENT_REPLIES: "comment_replies_log",
+}
+
+// 关注模块报错key
+var FollowLogMsgKey = map[int]string{
+	LOG_FOLLOW_FOLLOW:    "follow_follow_log",
+	LOG_FOLLOW_CANCEL:    "follow_cancel_log",
+	LOG_FOLLOW_FOLLOWERS: "follow_followers_log",
+	LOG_FOLLOW_FOLLOWEES: "follow_followees_log",
+	LOG_FOLLOW_STATICS:   "follow_statics_log",
+}
+
+// feed流模块报错key
+var FeedLogMsgKey = map[int]string{
+	LOG_FEED_LIST: "feed_list_log",
+}
+
+// 收藏夹模块报错key
+var CollectionLogMsgKey = map[int]string{
+	LOG_COLLECTION_CREATE: "collection_create_log",
+	LOG_COLLECTION_EDIT:   "collection_edit_log",
+	LOG_COLLECTION_DELETE: "collection_delete_log",
+	LOG_COLLECTION_LIST:   "collection_list_log",
+	LOG_COLLECTION_ITEMS:  "collection_items_log",
+	LOG_COLLECTION_MOVE:   "collection_move_log",
+}
+
+// 热榜模块报错key
+var RankingLogMsgKey = map[int]string{
+	LOG_RANKING_TOPN: "ranking_topn_log",
+}
+
+// 任务调度模块报错key
+var JobLogMsgKey = map[int]string{
+	LOG_JOB_CREATE:     "job_create_log",
+	LOG_JOB_UPDATE:     "job_update_log",
+	LOG_JOB_DELETE:     "job_delete_log",
+	LOG_JOB_PAUSE:      "job_pause_log",
+	LOG_JOB_RESUME:     "job_resume_log",
+	LOG_JOB_TRIGGER:    "job_trigger_log",
+	LOG_JOB_LIST:       "job_list_log",
+	LOG_JOB_EXECUTIONS: "job_executions_log",
+	LOG_JOB_CALLBACK:   "job_callback_log",
+	LOG_JOB_DAG:        "job_dag_log",
+	LOG_JOB_NODES:      "job_nodes_log",
+}
+
+// 搜索模块报错key
+var SearchLogMsgKey = map[int]string{
+	LOG_SEARCH_ARTICLES: "search_articles_log",
+}
+
+// 上传模块报错key
+var UploadLogMsgKey = map[int]string{
+	LOG_UPLOAD_FILE:     "upload_file_log",
+	LOG_UPLOAD_PRESIGN:  "upload_presign_log",
+	LOG_UPLOAD_CONFIRM:  "upload_confirm_log",
+	LOG_UPLOAD_DOWNLOAD: "upload_download_log",
+}
+
+// 内容审核模块报错key
+var ReviewLogMsgKey = map[int]string{
+	LOG_REVIEW_PENDING: "review_pending_log",
+	LOG_REVIEW_APPROVE: "review_approve_log",
+	LOG_REVIEW_REJECT:  "review_reject_log",
 }
@@ -0,0 +1,335 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	autolinkRegexp  = regexp.MustCompile(`^<((?:https?|mailto):[^\s<>]+)>`)
+	inlineTagRegexp = regexp.MustCompile(`^</?[a-zA-Z][a-zA-Z0-9-]*(?:\s+[a-zA-Z_:][-a-zA-Z0-9_:.]*(?:\s*=\s*(?:"[^"]*"|'[^']*'|[^\s"'=<>` + "`" + `]+))?)*\s*/?>`)
+	entityRegexp    = regexp.MustCompile(`^&(?:[a-zA-Z][a-zA-Z0-9]{1,31}|#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6});`)
+)
+
+// 可以用反斜杠转义的字符
+const escapable = "\\`*_{}[]()#+-.!|~<>\"'&"
+
+// 链接文字、地址的最大长度, 避免未闭合的括号导致反复扫描全文
+const maxLinkLen = 1024
+
+// @func: renderInline
+// @date: 2024-01-24 10:30:16
+// @brief: 行内语法解析: 转义、行内代码、图片、链接、自动链接、行内HTML、加粗、斜体、删除线、换行
+// @author: Kewin Li
+// @param text
+// @return string
+func renderInline(text string) string {
+	var sb strings.Builder
+	// 已确认找不到结束标记的强调标记, 后面同样的标记无需再找
+	unclosed := make(map[string]bool)
+
+	for i := 0; i < len(text); {
+		c := text[i]
+
+		switch c {
+		case '\\':
+			if i+1 < len(text) && strings.IndexByte(escapable, text[i+1]) >= 0 {
+				sb.WriteString(html.EscapeString(text[i+1 : i+2]))
+				i += 2
+				continue
+			}
+			// 行尾反斜杠为硬换行
+			if i+1 < len(text) && text[i+1] == '\n' {
+				sb.WriteString("<br>\n")
+				i += 2
+				continue
+			}
+
+		case '`':
+			if n, ok := renderCodeSpan(&sb, text[i:]); ok {
+				i += n
+				continue
+			}
+			// 没有配对的反引号串原样输出
+			n := runLen(text[i:], '`')
+			sb.WriteString(text[i : i+n])
+			i += n
+			continue
+
+		case '!':
+			if i+1 < len(text) && text[i+1] == '[' {
+				if label, dest, title, n, ok := parseLink(text[i+1:]); ok {
+					sb.WriteString(`<img src="` + html.EscapeString(dest) + `" alt="` + html.EscapeString(plainLabel(label)) + `"`)
+					if title != "" {
+						sb.WriteString(` title="` + html.EscapeString(title) + `"`)
+					}
+					sb.WriteString(">")
+					i += 1 + n
+					continue
+				}
+			}
+
+		case '[':
+			if label, dest, title, n, ok := parseLink(text[i:]); ok {
+				sb.WriteString(`<a href="` + html.EscapeString(dest) + `"`)
+				if title != "" {
+					sb.WriteString(` title="` + html.EscapeString(title) + `"`)
+				}
+				sb.WriteString(">" + renderInline(label) + "</a>")
+				i += n
+				continue
+			}
+
+		case '<':
+			if m := autolinkRegexp.FindStringSubmatch(text[i:]); m != nil {
+				sb.WriteString(`<a href="` + html.EscapeString(m[1]) + `">` + html.EscapeString(m[1]) + "</a>")
+				i += len(m[0])
+				continue
+			}
+			// 行内HTML原样输出, 交给 Sanitize 过滤
+			if m := inlineTagRegexp.FindString(text[i:]); m != "" {
+				sb.WriteString(m)
+				i += len(m)
+				continue
+			}
+
+		case '&':
+			if m := entityRegexp.FindString(text[i:]); m != "" {
+				sb.WriteString(m)
+				i += len(m)
+				continue
+			}
+
+		case '*', '_', '~':
+			if n, ok := renderEmphasis(&sb, text, i, unclosed); ok {
+				i += n
+				continue
+			}
+			n := runLen(text[i:], c)
+			sb.WriteString(text[i : i+n])
+			i += n
+			continue
+
+		case '\n':
+			// 行尾两个以上空格为硬换行
+			if strings.HasSuffix(sb.String(), "  ") {
+				trimmed := strings.TrimRight(sb.String(), " ")
+				sb.Reset()
+				sb.WriteString(trimmed + "<br>\n")
+			} else {
+				sb.WriteByte('\n')
+			}
+			i++
+			continue
+		}
+
+		_, size := utf8.DecodeRuneInString(text[i:])
+		sb.WriteString(html.EscapeString(text[i : i+size]))
+		i += size
+	}
+
+	return sb.String()
+}
+
+// @func: renderCodeSpan
+// @date: 2024-01-24 10:32:40
+// @brief: 行内代码, 开始、结束的反引号数量相同, 内容不解析
+// @author: Kewin Li
+// @param sb
+// @param text 以反引号开头
+// @return int 消耗的字节数
+// @return bool
+func renderCodeSpan(sb *strings.Builder, text string) (int, bool) {
+	n := runLen(text, '`')
+	for j := n; j < len(text); {
+		k := strings.IndexByte(text[j:], '`')
+		if k < 0 {
+			return 0, false
+		}
+		j += k
+		m := runLen(text[j:], '`')
+		if m == n {
+			code := strings.ReplaceAll(text[n:j], "\n", " ")
+			if len(code) > 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.TrimSpace(code) != "" {
+				code = code[1 : len(code)-1]
+			}
+			sb.WriteString("<code>" + html.EscapeString(code) + "</code>")
+			return j + m, true
+		}
+		j += m
+	}
+
+	return 0, false
+}
+
+// @func: renderEmphasis
+// @date: 2024-01-24 10:34:06
+// @brief: 加粗(**、__)、斜体(*、_)、删除线(~~), 开始标记后和结束标记前不能是空白, _ 不能在单词中间
+// @author: Kewin Li
+// @param sb
+// @param text
+// @param i 标记开始位置
+// @param unclosed 找不到结束标记的强调标记
+// @return int 消耗的字节数
+// @return bool
+func renderEmphasis(sb *strings.Builder, text string, i int, unclosed map[string]bool) (int, bool) {
+	c := text[i]
+	n := runLen(text[i:], c)
+
+	var delim, tag string
+	switch {
+	case c == '~' && n == 2:
+		delim, tag = "~~", "del"
+	case c == '~':
+		return 0, false
+	case n >= 2:
+		delim, tag = string([]byte{c, c}), "strong"
+	default:
+		delim, tag = string(c), "em"
+	}
+
+	// _ 在单词中间不作为标记, 如 snake_case
+	if c == '_' && i > 0 && isWordByte(text, i-1) {
+		return 0, false
+	}
+
+	start := i + len(delim)
+	if start >= len(text) || isSpaceAt(text, start) || unclosed[delim] {
+		return 0, false
+	}
+
+	for j := start + 1; j <= len(text)-len(delim); j++ {
+		if text[j] == '`' {
+			// 跳过行内代码, 其中的标记不参与配对
+			if n, ok := renderCodeSpan(&strings.Builder{}, text[j:]); ok {
+				j += n - 1
+			}
+			continue
+		}
+		if !strings.HasPrefix(text[j:], delim) || isSpaceAt(text, j-1) {
+			continue
+		}
+		// 单个标记不能和连续标记配对
+		if len(delim) == 1 && (runLen(text[j:], c) != 1 || text[j-1] == c) {
+			continue
+		}
+		end := j + len(delim)
+		if c == '_' && end < len(text) && isWordByte(text, end) {
+			continue
+		}
+		// ***x*** 这类连续标记, 取最后两个作为加粗的结束
+		if len(delim) == 2 && end < len(text) && text[end] == c {
+			continue
+		}
+
+		sb.WriteString("<" + tag + ">" + renderInline(text[start:j]) + "</" + tag + ">")
+		return end - i, true
+	}
+
+	// 结束标记是否成立只和其所在位置有关, 从更后面开始也不会找到
+	unclosed[delim] = true
+	return 0, false
+}
+
+// @func: parseLink
+// @date: 2024-01-24 10:36:22
+// @brief: 解析 [文字](地址 "标题")
+// @author: Kewin Li
+// @param text 以 [ 开头
+// @return label
+// @return dest
+// @return title
+// @return n 消耗的字节数
+// @return ok
+func parseLink(text string) (label string, dest string, title string, n int, ok bool) {
+	depth := 0
+	close := -1
+	for j := 0; j < len(text) && j <= maxLinkLen && close < 0; j++ {
+		switch text[j] {
+		case '\\':
+			j++
+		case '`':
+			if m, found := renderCodeSpan(&strings.Builder{}, text[j:]); found {
+				j += m - 1
+			}
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				close = j
+			}
+		}
+	}
+	if close < 0 || close+1 >= len(text) || text[close+1] != '(' {
+		return "", "", "", 0, false
+	}
+
+	// 地址中可以有成对的括号
+	end := -1
+	for j, parens := close+2, 0; j < len(text) && j <= close+2+maxLinkLen && end < 0; j++ {
+		switch text[j] {
+		case '\\':
+			j++
+		case '(':
+			parens++
+		case ')':
+			if parens == 0 {
+				end = j - close - 2
+			}
+			parens--
+		case '\n':
+			if j+1 < len(text) && text[j+1] == '\n' {
+				return "", "", "", 0, false
+			}
+		}
+	}
+	if end < 0 {
+		return "", "", "", 0, false
+	}
+	inner := strings.TrimSpace(text[close+2 : close+2+end])
+
+	dest = inner
+	if k := strings.IndexAny(inner, " \t\n"); k >= 0 {
+		dest = inner[:k]
+		rest := strings.TrimSpace(inner[k:])
+		if len(rest) < 2 || !(rest[0] == '"' && rest[len(rest)-1] == '"' || rest[0] == '\'' && rest[len(rest)-1] == '\'') {
+			return "", "", "", 0, false
+		}
+		title = rest[1 : len(rest)-1]
+	}
+	dest = strings.TrimSuffix(strings.TrimPrefix(dest, "<"), ">")
+
+	return text[1:close], dest, title, close + 3 + end, true
+}
+
+// @func: plainLabel
+// @date: 2024-01-24 10:37:50
+// @brief: 图片的 alt 只保留文字
+// @author: Kewin Li
+// @param label
+// @return string
+func plainLabel(label string) string {
+	return Text(renderInline(label))
+}
+
+func runLen(text string, c byte) int {
+	n := 0
+	for n < len(text) && text[n] == c {
+		n++
+	}
+	return n
+}
+
+func isSpaceAt(text string, i int) bool {
+	r, _ := utf8.DecodeRuneInString(text[i:])
+	return unicode.IsSpace(r)
+}
+
+func isWordByte(text string, i int) bool {
+	c := text[i]
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
@@ -0,0 +1,413 @@
+// Package markdown
+// @Description: 服务端 Markdown 渲染: 常用 Markdown 语法转 HTML、白名单 XSS 过滤、纯文本提取及字数统计
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	headingRegexp   = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	hrRegexp        = regexp.MustCompile(`^ {0,3}((?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	fenceRegexp     = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})[ \t]*([^`\\s]*)")
+	bulletRegexp    = regexp.MustCompile(`^( {0,3})([-*+])[ \t]+`)
+	orderedRegexp   = regexp.MustCompile(`^( {0,3})([0-9]{1,9})[.)][ \t]+`)
+	quoteRegexp     = regexp.MustCompile(`^ {0,3}> ?`)
+	htmlBlockRegexp = regexp.MustCompile(`^ {0,3}</?(?i:address|article|aside|blockquote|details|dialog|div|dl|fieldset|figure|footer|form|h[1-6]|header|hr|iframe|li|main|nav|noscript|ol|p|pre|script|section|style|summary|table|tbody|td|textarea|tfoot|th|thead|tr|ul)(?:[\s/>]|$)`)
+	tableSepRegexp  = regexp.MustCompile(`^ {0,3}\|?[ \t]*:?-+:?[ \t]*(?:\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+)
+
+// 引用、列表的最大嵌套深度, 防止恶意输入导致栈过深
+const maxNesting = 16
+
+// @func: Render
+// @date: 2024-01-24 10:05:12
+// @brief: Markdown转HTML, 输出已经过白名单XSS过滤, 可直接返回给前端
+// @author: Kewin Li
+// @param src
+// @return string
+func Render(src string) string {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	src = strings.ReplaceAll(src, "\r", "\n")
+
+	var sb strings.Builder
+	renderBlocks(&sb, strings.Split(src, "\n"), 0)
+
+	return Sanitize(sb.String())
+}
+
+// @func: renderBlocks
+// @date: 2024-01-24 10:08:36
+// @brief: 块级语法解析: 标题、分割线、代码块、引用、列表、表格、HTML块、段落
+// @author: Kewin Li
+// @param sb
+// @param lines
+// @param level 嵌套深度
+func renderBlocks(sb *strings.Builder, lines []string, level int) {
+	for i := 0; i < len(lines); {
+		line := lines[i]
+
+		switch {
+		case isBlank(line):
+			i++
+
+		case fenceRegexp.MatchString(line):
+			i = renderFence(sb, lines, i)
+
+		case headingRegexp.MatchString(line):
+			m := headingRegexp.FindStringSubmatch(line)
+			tag := "h" + strconv.Itoa(len(m[1]))
+			sb.WriteString("<" + tag + ">" + renderInline(m[2]) + "</" + tag + ">\n")
+			i++
+
+		case hrRegexp.MatchString(line):
+			sb.WriteString("<hr>\n")
+			i++
+
+		case quoteRegexp.MatchString(line) && level < maxNesting:
+			var inner []string
+			for ; i < len(lines) && !isBlank(lines[i]); i++ {
+				inner = append(inner, quoteRegexp.ReplaceAllString(lines[i], ""))
+			}
+			sb.WriteString("<blockquote>\n")
+			renderBlocks(sb, inner, level+1)
+			sb.WriteString("</blockquote>\n")
+
+		case isListItem(line) && level < maxNesting:
+			i = renderList(sb, lines, i, level)
+
+		case isTableStart(lines, i):
+			i = renderTable(sb, lines, i)
+
+		case htmlBlockRegexp.MatchString(line):
+			// 原样输出, 交给 Sanitize 过滤
+			for ; i < len(lines) && !isBlank(lines[i]); i++ {
+				sb.WriteString(lines[i] + "\n")
+			}
+
+		default:
+			i = renderParagraph(sb, lines, i)
+		}
+	}
+}
+
+// @func: renderFence
+// @date: 2024-01-24 10:10:20
+// @brief: 围栏代码块, 内容不做任何解析
+// @author: Kewin Li
+// @param sb
+// @param lines
+// @param i
+// @return int 下一个未处理的行
+func renderFence(sb *strings.Builder, lines []string, i int) int {
+	m := fenceRegexp.FindStringSubmatch(lines[i])
+	indent, fence, lang := len(m[1]), m[2], m[3]
+
+	sb.WriteString("<pre><code")
+	if lang != "" {
+		sb.WriteString(` class="language-` + html.EscapeString(lang) + `"`)
+	}
+	sb.WriteString(">")
+
+	for i++; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(trimmed, fence[:1]) && len(trimmed) >= len(fence) &&
+			strings.Trim(trimmed, fence[:1]) == "" {
+			i++
+			break
+		}
+		sb.WriteString(html.EscapeString(trimIndent(lines[i], indent)) + "\n")
+	}
+
+	sb.WriteString("</code></pre>\n")
+	return i
+}
+
+// @func: renderList
+// @date: 2024-01-24 10:12:48
+// @brief: 有序、无序列表, 缩进的行属于上一个列表项, 支持嵌套
+// @author: Kewin Li
+// @param sb
+// @param lines
+// @param i
+// @param level
+// @return int 下一个未处理的行
+func renderList(sb *strings.Builder, lines []string, i int, level int) int {
+	ordered := orderedRegexp.MatchString(lines[i])
+	tag := "ul"
+	if ordered {
+		tag = "ol"
+		start := orderedRegexp.FindStringSubmatch(lines[i])[2]
+		if n, _ := strconv.Atoi(start); n != 1 {
+			sb.WriteString(`<ol start="` + strconv.Itoa(n) + `">` + "\n")
+		} else {
+			sb.WriteString("<ol>\n")
+		}
+	} else {
+		sb.WriteString("<ul>\n")
+	}
+
+	for i < len(lines) {
+		marker := listMarker(lines[i], ordered)
+		if marker == "" {
+			break
+		}
+
+		// 列表项内容: 首行去掉标记, 后续缩进行去掉缩进
+		item := []string{lines[i][len(marker):]}
+		width := len(marker)
+		for i++; i < len(lines); i++ {
+			line := lines[i]
+			if isBlank(line) {
+				// 空行后仍有缩进内容则属于本项
+				if i+1 < len(lines) && leadingSpaces(lines[i+1]) >= width {
+					item = append(item, "")
+					continue
+				}
+				break
+			}
+			if leadingSpaces(line) >= width {
+				item = append(item, trimIndent(line, width))
+				continue
+			}
+			// 懒惰续行: 未缩进的普通文本仍属于本项
+			if isListItem(line) || !isParagraphText(line) {
+				break
+			}
+			item = append(item, line)
+		}
+
+		sb.WriteString("<li>")
+		renderListItem(sb, item, level)
+		sb.WriteString("</li>\n")
+
+		// 跳过列表项之间的空行
+		for i < len(lines) && isBlank(lines[i]) && i+1 < len(lines) && listMarker(lines[i+1], ordered) != "" {
+			i++
+		}
+	}
+
+	sb.WriteString("</" + tag + ">\n")
+	return i
+}
+
+// @func: renderListItem
+// @date: 2024-01-24 10:14:30
+// @brief: 列表项只有一段文本时不包裹<p>, 否则按块解析
+// @author: Kewin Li
+// @param sb
+// @param item
+// @param level
+func renderListItem(sb *strings.Builder, item []string, level int) {
+	end := 0
+	for end < len(item) && !isBlank(item[end]) && (end == 0 || isParagraphText(item[end])) {
+		end++
+	}
+	sb.WriteString(renderInline(strings.Join(item[:end], "\n")))
+
+	if end < len(item) {
+		sb.WriteString("\n")
+		renderBlocks(sb, item[end:], level+1)
+	}
+}
+
+// @func: renderTable
+// @date: 2024-01-24 10:16:02
+// @brief: GFM表格, 第二行为对齐方式
+// @author: Kewin Li
+// @param sb
+// @param lines
+// @param i
+// @return int 下一个未处理的行
+func renderTable(sb *strings.Builder, lines []string, i int) int {
+	header := splitRow(lines[i])
+	seps := splitRow(lines[i+1])
+	aligns := make([]string, len(header))
+	for j := range aligns {
+		if j >= len(seps) {
+			break
+		}
+		left, right := strings.HasPrefix(seps[j], ":"), strings.HasSuffix(seps[j], ":")
+		switch {
+		case left && right:
+			aligns[j] = "center"
+		case right:
+			aligns[j] = "right"
+		case left:
+			aligns[j] = "left"
+		}
+	}
+
+	writeRow := func(cells []string, tag string) {
+		sb.WriteString("<tr>")
+		for j := range header {
+			cell := ""
+			if j < len(cells) {
+				cell = cells[j]
+			}
+			sb.WriteString("<" + tag)
+			if aligns[j] != "" {
+				sb.WriteString(` align="` + aligns[j] + `"`)
+			}
+			sb.WriteString(">" + renderInline(cell) + "</" + tag + ">")
+		}
+		sb.WriteString("</tr>\n")
+	}
+
+	sb.WriteString("<table>\n<thead>\n")
+	writeRow(header, "th")
+	sb.WriteString("</thead>\n<tbody>\n")
+	for i += 2; i < len(lines) && !isBlank(lines[i]) && strings.Contains(lines[i], "|"); i++ {
+		writeRow(splitRow(lines[i]), "td")
+	}
+	sb.WriteString("</tbody>\n</table>\n")
+
+	return i
+}
+
+// @func: renderParagraph
+// @date: 2024-01-24 10:17:34
+// @brief: 段落, 遇到空行或其他块级语法结束
+// @author: Kewin Li
+// @param sb
+// @param lines
+// @param i
+// @return int 下一个未处理的行
+func renderParagraph(sb *strings.Builder, lines []string, i int) int {
+	start := i
+	for i++; i < len(lines) && isParagraphText(lines[i]); i++ {
+		// 段落下一行是表格分隔行时, 当前行是表头
+		if isTableStart(lines, i) {
+			break
+		}
+	}
+
+	text := strings.TrimSpace(strings.Join(lines[start:i], "\n"))
+	sb.WriteString("<p>" + renderInline(text) + "</p>\n")
+	return i
+}
+
+// @func: isParagraphText
+// @date: 2024-01-24 10:18:20
+// @brief: 是否为普通文本行, 可以接在段落后面
+// @author: Kewin Li
+// @param line
+// @return bool
+func isParagraphText(line string) bool {
+	return !isBlank(line) &&
+		!fenceRegexp.MatchString(line) &&
+		!headingRegexp.MatchString(line) &&
+		!hrRegexp.MatchString(line) &&
+		!quoteRegexp.MatchString(line) &&
+		!isListItem(line) &&
+		!htmlBlockRegexp.MatchString(line)
+}
+
+// @func: isTableStart
+// @date: 2024-01-24 10:18:42
+// @brief: 是否为表格开始: 表头行之后紧跟列数相同的分隔行
+// @author: Kewin Li
+// @param lines
+// @param i
+// @return bool
+func isTableStart(lines []string, i int) bool {
+	if i+1 >= len(lines) || !strings.Contains(lines[i], "|") || !strings.Contains(lines[i+1], "|") {
+		return false
+	}
+	if !tableSepRegexp.MatchString(lines[i+1]) {
+		return false
+	}
+	return len(splitRow(lines[i])) == len(splitRow(lines[i+1]))
+}
+
+func isListItem(line string) bool {
+	return bulletRegexp.MatchString(line) || orderedRegexp.MatchString(line)
+}
+
+// @func: listMarker
+// @date: 2024-01-24 10:19:06
+// @brief: 返回列表项标记(含前后空白), 与当前列表类型不一致时返回空
+// @author: Kewin Li
+// @param line
+// @param ordered
+// @return string
+func listMarker(line string, ordered bool) string {
+	if hrRegexp.MatchString(line) {
+		return ""
+	}
+	if ordered {
+		return orderedRegexp.FindString(line)
+	}
+	return bulletRegexp.FindString(line)
+}
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+func leadingSpaces(line string) int {
+	n := 0
+	for _, c := range line {
+		switch c {
+		case ' ':
+			n++
+		case '\t':
+			n += 4
+		default:
+			return n
+		}
+	}
+	return n
+}
+
+// @func: trimIndent
+// @date: 2024-01-24 10:19:40
+// @brief: 去掉行首最多n个空格的缩进
+// @author: Kewin Li
+// @param line
+// @param n
+// @return string
+func trimIndent(line string, n int) string {
+	i := 0
+	for i < len(line) && i < n && line[i] == ' ' {
+		i++
+	}
+	if i < n && i < len(line) && line[i] == '\t' {
+		i++
+	}
+	return line[i:]
+}
+
+// @func: splitRow
+// @date: 2024-01-24 10:19:58
+// @brief: 拆分表格行, 支持 \| 转义
+// @author: Kewin Li
+// @param line
+// @return []string
+func splitRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+
+	return append(cells, strings.TrimSpace(cell.String()))
+}
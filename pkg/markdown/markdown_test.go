@@ -0,0 +1,193 @@
+package markdown
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// @func: TestRender
+// @date: 2024-01-24 11:05:20
+// @brief: 单元测试-常用Markdown语法渲染
+// @author: Kewin Li
+// @param t
+func TestRender(t *testing.T) {
+	testCases := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "标题与段落",
+			src:  "# 标题\n\n正文**加粗**和*斜体*, snake_case",
+			want: "<h1>标题</h1>\n<p>正文<strong>加粗</strong>和<em>斜体</em>, snake_case</p>\n",
+		},
+		{
+			name: "行内代码不解析",
+			src:  "调用 `a *b* <c>`",
+			want: "<p>调用 <code>a *b* &lt;c&gt;</code></p>\n",
+		},
+		{
+			name: "围栏代码块",
+			src:  "```go\nif a < b {}\n```",
+			want: "<pre><code class=\"language-go\">if a &lt; b {}\n</code></pre>\n",
+		},
+		{
+			name: "嵌套列表",
+			src:  "- a\n- b\n  - c\n\n3. x",
+			want: "<ul>\n<li>a</li>\n<li>b\n<ul>\n<li>c</li>\n</ul>\n</li>\n</ul>\n<ol start=\"3\">\n<li>x</li>\n</ol>\n",
+		},
+		{
+			name: "引用",
+			src:  "> 引用\n> ~~删除~~",
+			want: "<blockquote>\n<p>引用\n<del>删除</del></p>\n</blockquote>\n",
+		},
+		{
+			name: "链接和图片",
+			src:  "[官网](https://a.com/x_(1) \"标题\") ![图](/a.png)",
+			want: "<p><a href=\"https://a.com/x_(1)\" title=\"标题\" rel=\"nofollow noopener noreferrer\">官网</a> <img src=\"/a.png\" alt=\"图\"></p>\n",
+		},
+		{
+			name: "表格",
+			src:  "| a | b |\n|:--|--:|\n| 1 | 2 |",
+			want: "<table>\n<thead>\n<tr><th align=\"left\">a</th><th align=\"right\">b</th></tr>\n</thead>\n<tbody>\n<tr><td align=\"left\">1</td><td align=\"right\">2</td></tr>\n</tbody>\n</table>\n",
+		},
+		{
+			name: "硬换行",
+			src:  "第一行  \n第二行",
+			want: "<p>第一行<br>\n第二行</p>\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Render(tc.src))
+		})
+	}
+}
+
+// @func: TestSanitize
+// @date: 2024-01-24 11:08:42
+// @brief: 单元测试-XSS过滤
+// @author: Kewin Li
+// @param t
+func TestSanitize(t *testing.T) {
+	testCases := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "脚本连同内容丢弃",
+			src:  "<script>alert(1)</script>ok<style>p{}</style>",
+			want: "ok\n",
+		},
+		{
+			name: "事件属性去掉",
+			src:  "<img src=\"/a.png\" onerror=\"alert(1)\">",
+			want: "<p><img src=\"/a.png\"></p>\n",
+		},
+		{
+			name: "javascript协议去掉",
+			src:  "[x](javascript:alert(1))",
+			want: "<p><a>x</a></p>\n",
+		},
+		{
+			name: "混淆的javascript协议去掉",
+			src:  "<a href=\"jav&#x09;ascript:alert(1)\">x</a> <a href=\" JAVASCRIPT:alert(1)\">y</a>",
+			want: "<p><a>x</a> <a>y</a></p>\n",
+		},
+		{
+			name: "白名单外的标签去掉, 保留文字",
+			src:  "<div onclick=\"x\">hi <em>there</em></div>",
+			want: "hi <em>there</em>\n",
+		},
+		{
+			name: "未闭合的标签补全",
+			src:  "<details><summary>更多",
+			want: "<details><summary>更多\n</summary></details>",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Render(tc.src))
+		})
+	}
+}
+
+// @func: TestWordCount
+// @date: 2024-01-24 11:10:06
+// @brief: 单元测试-纯文本提取与字数统计
+// @author: Kewin Li
+// @param t
+func TestWordCount(t *testing.T) {
+	text := Text(Render("# Go并发\n\n- 使用 **goroutine** 和 `channel`"))
+	assert.Equal(t, "Go并发 使用 goroutine 和 channel", text)
+	assert.Equal(t, 8, WordCount(text))
+
+	assert.Equal(t, 0, ReadingMinutes(0))
+	assert.Equal(t, 1, ReadingMinutes(1))
+	assert.Equal(t, 2, ReadingMinutes(wordsPerMinute+1))
+}
+
+// @func: TestTextPrefix
+// @date: 2024-01-28 14:30:18
+// @brief: 单元测试-只渲染前缀提取摘要, 结果与全文渲染后截取一致
+// @author: Kewin Li
+// @param t
+func TestTextPrefix(t *testing.T) {
+	var sb strings.Builder
+	for i := 0; i < 200; i++ {
+		sb.WriteString("## 第" + strconv.Itoa(i) + "节\n\n")
+		sb.WriteString("使用 **goroutine** 和 [channel](https://go.dev/ref/spec#Channel_types) 编写并发程序\n")
+		sb.WriteString("第二行\n\n")
+		sb.WriteString("| 名称 | 说明 |\n| --- | --- |\n| `go` | 启动协程 |\n\n")
+		sb.WriteString("```go\nch := make(chan int)\n\ngo func() { ch <- 1 }()\n```\n\n")
+	}
+	long := sb.String()
+
+	testCases := []struct {
+		name string
+		src  string
+		n    int
+	}{
+		{
+			name: "长文只渲染开头",
+			src:  long,
+			n:    256,
+		},
+		{
+			name: "前缀在代码块中的空行截断",
+			src:  long,
+			n:    60,
+		},
+		{
+			name: "Windows换行",
+			src:  strings.ReplaceAll(long, "\n", "\r\n"),
+			n:    256,
+		},
+		{
+			name: "短文全文渲染",
+			src:  "# Go并发\n\n- 使用 **goroutine** 和 `channel`",
+			n:    256,
+		},
+		{
+			name: "没有空行的长段落",
+			src:  strings.Repeat("并发编程", 2000),
+			n:    256,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			want := []rune(Text(Render(tc.src)))
+			if len(want) > tc.n {
+				want = want[:tc.n]
+			}
+
+			assert.Equal(t, string(want), TextPrefix(tc.src, tc.n))
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package markdown
+
+import (
+	"golang.org/x/net/html"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// 白名单: 允许的标签及其允许的属性
+var allowedTags = map[string]map[string]bool{
+	"p": {}, "br": {}, "hr": {},
+	"h1": {}, "h2": {}, "h3": {}, "h4": {}, "h5": {}, "h6": {},
+	"strong": {}, "b": {}, "em": {}, "i": {}, "del": {}, "s": {},
+	"sub": {}, "sup": {}, "mark": {}, "kbd": {},
+	"code":       {"class": true},
+	"pre":        {},
+	"blockquote": {},
+	"ul":         {},
+	"ol":         {"start": true},
+	"li":         {},
+	"a":          {"href": true, "title": true},
+	"img":        {"src": true, "alt": true, "title": true},
+	"table":      {}, "thead": {}, "tbody": {}, "tr": {},
+	"th":      {"align": true},
+	"td":      {"align": true},
+	"details": {}, "summary": {},
+}
+
+// 连同内容一起丢弃的标签
+var droppedTags = map[string]bool{
+	"script": true, "style": true, "iframe": true, "object": true, "embed": true,
+	"noscript": true, "textarea": true, "title": true, "template": true,
+	"svg": true, "math": true, "frame": true, "frameset": true,
+}
+
+// 无结束标签的元素
+var voidTags = map[string]bool{
+	"br": true, "hr": true, "img": true,
+}
+
+// 链接允许的协议, 空协议为相对地址
+var allowedSchemes = map[string]map[string]bool{
+	"href": {"": true, "http": true, "https": true, "mailto": true},
+	"src":  {"": true, "http": true, "https": true},
+}
+
+var (
+	codeClassRegexp = regexp.MustCompile(`^language-[a-zA-Z0-9_+#-]{1,32}$`)
+	alignRegexp     = regexp.MustCompile(`^(left|right|center)$`)
+	numberRegexp    = regexp.MustCompile(`^[0-9]{1,9}$`)
+)
+
+// @func: Sanitize
+// @date: 2024-01-24 10:20:36
+// @brief: XSS过滤-只保留白名单内的标签、属性和链接协议, 其余标签去掉但保留文本, 脚本类标签连同内容丢弃
+// @author: Kewin Li
+// @param src
+// @return string
+func Sanitize(src string) string {
+	var sb strings.Builder
+	z := html.NewTokenizer(strings.NewReader(src))
+	// 已输出、尚未闭合的标签
+	var open []string
+	// 正在丢弃内容的标签及其嵌套深度
+	var dropping string
+	depth := 0
+
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		tok := z.Token()
+
+		if dropping != "" {
+			switch {
+			case tt == html.StartTagToken && tok.Data == dropping:
+				depth++
+			case tt == html.EndTagToken && tok.Data == dropping:
+				depth--
+				if depth == 0 {
+					dropping = ""
+				}
+			}
+			continue
+		}
+
+		switch tt {
+		case html.TextToken:
+			sb.WriteString(html.EscapeString(tok.Data))
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if droppedTags[tok.Data] {
+				if tt == html.StartTagToken && !voidTags[tok.Data] {
+					dropping, depth = tok.Data, 1
+				}
+				continue
+			}
+			attrs, ok := allowedTags[tok.Data]
+			if !ok {
+				continue
+			}
+			writeStartTag(&sb, tok.Data, attrs, tok.Attr)
+			if !voidTags[tok.Data] {
+				open = append(open, tok.Data)
+			}
+
+		case html.EndTagToken:
+			// 只闭合已打开的标签, 中间未闭合的一并闭合
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] != tok.Data {
+					continue
+				}
+				for j := len(open) - 1; j >= i; j-- {
+					sb.WriteString("</" + open[j] + ">")
+				}
+				open = open[:i]
+				break
+			}
+		}
+		// 注释、DOCTYPE 直接丢弃
+	}
+
+	for i := len(open) - 1; i >= 0; i-- {
+		sb.WriteString("</" + open[i] + ">")
+	}
+
+	return sb.String()
+}
+
+// @func: writeStartTag
+// @date: 2024-01-24 10:24:18
+// @brief: XSS过滤-输出开始标签, 过滤属性
+// @author: Kewin Li
+// @param sb
+// @param tag
+// @param allowed
+// @param attrs
+func writeStartTag(sb *strings.Builder, tag string, allowed map[string]bool, attrs []html.Attribute) {
+	sb.WriteString("<" + tag)
+
+	seen := make(map[string]bool, len(attrs))
+	for _, attr := range attrs {
+		if attr.Namespace != "" || !allowed[attr.Key] || seen[attr.Key] {
+			continue
+		}
+		val, ok := sanitizeAttr(attr.Key, attr.Val)
+		if !ok {
+			continue
+		}
+		seen[attr.Key] = true
+		sb.WriteString(" " + attr.Key + `="` + html.EscapeString(val) + `"`)
+	}
+
+	// 外链不传递权重、不泄露来源
+	if tag == "a" && seen["href"] {
+		sb.WriteString(` rel="nofollow noopener noreferrer"`)
+	}
+	sb.WriteString(">")
+}
+
+// @func: sanitizeAttr
+// @date: 2024-01-24 10:26:40
+// @brief: XSS过滤-校验属性值
+// @author: Kewin Li
+// @param key
+// @param val
+// @return string
+// @return bool
+func sanitizeAttr(key string, val string) (string, bool) {
+	switch key {
+	case "href", "src":
+		return sanitizeURL(val, allowedSchemes[key])
+	case "class":
+		return val, codeClassRegexp.MatchString(val)
+	case "align":
+		return val, alignRegexp.MatchString(val)
+	case "start":
+		return val, numberRegexp.MatchString(val)
+	default:
+		return val, true
+	}
+}
+
+// @func: sanitizeURL
+// @date: 2024-01-24 10:28:52
+// @brief: XSS过滤-只允许白名单协议, 拦截 javascript: 等协议及其混淆写法
+// @author: Kewin Li
+// @param raw
+// @param schemes
+// @return string
+// @return bool
+func sanitizeURL(raw string, schemes map[string]bool) (string, bool) {
+	// 浏览器解析时会去掉首尾的控制字符、空白及任意位置的制表、换行, 这里先按同样规则处理再判断
+	cleaned := strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' {
+			return -1
+		}
+		return r
+	}, raw)
+	cleaned = strings.TrimFunc(cleaned, func(r rune) bool {
+		return r <= ' ' || r == 0x7f
+	})
+	if cleaned == "" {
+		return "", false
+	}
+
+	u, err := url.Parse(cleaned)
+	if err != nil {
+		return "", false
+	}
+	if !schemes[strings.ToLower(u.Scheme)] {
+		return "", false
+	}
+	// 相对地址中出现冒号可能被当作协议
+	if u.Scheme == "" && strings.Contains(strings.SplitN(cleaned, "/", 2)[0], ":") {
+		return "", false
+	}
+
+	return cleaned, true
+}
@@ -0,0 +1,144 @@
+package markdown
+
+import (
+	"golang.org/x/net/html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 阅读速度, 每分钟字数(汉字按字、英文按单词计)
+const wordsPerMinute = 300
+
+// 提取前缀纯文本时, 每个字预估的源码字节数, 不够时前缀长度翻倍
+const prefixBytesPerRune = 16
+
+// 换行的块级标签, 提取纯文本时替换为空白, 避免前后文字粘连
+var blockTags = map[string]bool{
+	"p": true, "br": true, "hr": true, "li": true, "pre": true, "blockquote": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"tr": true, "td": true, "th": true, "table": true, "ul": true, "ol": true,
+	"details": true, "summary": true,
+}
+
+// @func: Text
+// @date: 2024-01-24 10:45:30
+// @brief: 从HTML中提取纯文本, 连续空白合并为一个空格, 用于摘要和字数统计
+// @author: Kewin Li
+// @param src Render 的输出
+// @return string
+func Text(src string) string {
+	var sb strings.Builder
+	z := html.NewTokenizer(strings.NewReader(src))
+
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+
+		switch tt {
+		case html.TextToken:
+			sb.Write(z.Text())
+		case html.StartTagToken, html.EndTagToken, html.SelfClosingTagToken:
+			name, _ := z.TagName()
+			if blockTags[string(name)] {
+				sb.WriteByte(' ')
+			}
+		}
+	}
+
+	return strings.Join(strings.Fields(sb.String()), " ")
+}
+
+// @func: TextPrefix
+// @date: 2024-01-28 14:10:25
+// @brief: 只渲染正文开头的若干段落, 提取前n个字的纯文本, 用于列表摘要, 避免长文每次全文渲染
+// @author: Kewin Li
+// @param src Markdown源码
+// @param n 最多返回的字数
+// @return string
+func TextPrefix(src string, n int) string {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	size := n * prefixBytesPerRune
+	for size < len(src) {
+		// 只在空行处截断, 截断位置前的块级结构与全文渲染一致
+		cut := strings.LastIndex(src[:size], "\n\n")
+		if cut <= 0 {
+			next := strings.Index(src[size:], "\n\n")
+			if next < 0 {
+				break
+			}
+			cut = size + next
+		}
+
+		text := Text(Render(src[:cut]))
+		if utf8.RuneCountInString(text) > n {
+			return truncateRunes(text, n)
+		}
+		size = max(size, cut) * 2
+	}
+
+	return truncateRunes(Text(Render(src)), n)
+}
+
+// @func: truncateRunes
+// @date: 2024-01-28 14:12:40
+// @brief: 按字截断, 不截断多字节字符
+// @author: Kewin Li
+// @param text
+// @param n
+// @return string
+func truncateRunes(text string, n int) string {
+	cnt := 0
+	for i := range text {
+		if cnt == n {
+			return text[:i]
+		}
+		cnt++
+	}
+
+	return text
+}
+
+// @func: WordCount
+// @date: 2024-01-24 10:47:12
+// @brief: 字数统计, 汉字每个字算一个, 英文、数字每个单词算一个, 标点不计
+// @author: Kewin Li
+// @param text 纯文本
+// @return int
+func WordCount(text string) int {
+	cnt := 0
+	inWord := false
+
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			cnt++
+			inWord = false
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if !inWord {
+				cnt++
+			}
+			inWord = true
+		default:
+			inWord = false
+		}
+	}
+
+	return cnt
+}
+
+// @func: ReadingMinutes
+// @date: 2024-01-24 10:48:36
+// @brief: 预计阅读时间(分钟), 向上取整, 有内容时至少1分钟
+// @author: Kewin Li
+// @param words 字数
+// @return int
+func ReadingMinutes(words int) int {
+	if words <= 0 {
+		return 0
+	}
+
+	return (words + wordsPerMinute - 1) / wordsPerMinute
+}
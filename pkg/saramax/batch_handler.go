@@ -42,7 +42,7 @@ func (b *BatchHandler[T]) ConsumeClaim(session sarama.ConsumerGroupSession, clai
 		ts := make([]T, 0, batchSize)
 		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 		timeoutFlag := false
-		for i := 0; i < batchSize && timeoutFlag; {
+		for i := 0; i < batchSize && !timeoutFlag; {
 			select {
 			case <-ctx.Done():
 				//TODO: 会话超时, 日志埋点
@@ -75,6 +75,11 @@ func (b *BatchHandler[T]) ConsumeClaim(session sarama.ConsumerGroupSession, clai
 
 		cancel()
 
+		// 超时时间内没有收到任何消息
+		if len(batch) == 0 {
+			continue
+		}
+
 		err := b.fn(batch, ts)
 		if err != nil {
 			b.l.ERROR("消息业务处理出错",
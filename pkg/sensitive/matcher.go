@@ -0,0 +1,361 @@
+// Package sensitive
+// @Description: 敏感词匹配: Aho-Corasick自动机, 一次扫描找出文本中的所有词, 忽略大小写、全半角及夹杂的空白和符号;
+// 拉丁字母词只匹配完整单词, 且只忽略单词内夹杂的符号, 避免 "this exam" 命中 "sex"
+package sensitive
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// 单个词最大长度(字符), 超过的视为配置错误
+const maxWordLen = 64
+
+// Matcher
+// @Description: 构建后只读, 可并发使用
+type Matcher struct {
+	nodes []node
+	words []string
+	// 与words一一对应: 词长(字符), 是否为拉丁字母词
+	lens  []int
+	latin []bool
+}
+
+// 归一化字符在原文中的位置, 用于校验拉丁字母词的边界
+type position struct {
+	// 原文中的下标(字符)
+	idx int
+	// 此前跳过的空白数
+	spaces int
+}
+
+type node struct {
+	next map[rune]int32
+	fail int32
+	// 以该结点结尾的词下标, -1表示没有
+	word int32
+	// 沿fail链最近的有词结点, -1表示没有
+	dict int32
+}
+
+// @func: NewMatcher
+// @date: 2024-01-25 10:05:12
+// @brief: 敏感词-构建自动机, 词会先归一化, 归一化后为空或重复的词被忽略
+// @author: Kewin Li
+// @param words
+// @return *Matcher
+func NewMatcher(words []string) *Matcher {
+	m := &Matcher{
+		nodes: []node{newNode()},
+	}
+
+	for _, w := range words {
+		m.insert(w)
+	}
+	m.build()
+
+	return m
+}
+
+// @func: LoadWords
+// @date: 2024-01-25 10:08:36
+// @brief: 敏感词-读取词表, 每行一个词, 忽略空行和#开头的注释
+// @author: Kewin Li
+// @param r
+// @return []string
+// @return error
+func LoadWords(r io.Reader) ([]string, error) {
+	words := make([]string, 0)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+
+	return words, scanner.Err()
+}
+
+// @func: Len
+// @date: 2024-01-25 10:10:20
+// @brief: 敏感词-有效词数
+// @author: Kewin Li
+// @receiver m
+// @return int
+func (m *Matcher) Len() int {
+	return len(m.words)
+}
+
+// @func: FindAll
+// @date: 2024-01-25 10:12:48
+// @brief: 敏感词-找出文本中出现的所有词, 按首次出现的顺序去重返回, 返回的是归一化后的词
+// @author: Kewin Li
+// @receiver m
+// @param text
+// @return []string
+func (m *Matcher) FindAll(text string) []string {
+	if len(m.words) == 0 {
+		return nil
+	}
+
+	var res []string
+	seen := make(map[int32]struct{})
+	m.scan(text, func(w int32) bool {
+		if _, ok := seen[w]; !ok {
+			seen[w] = struct{}{}
+			res = append(res, m.words[w])
+		}
+		return true
+	})
+
+	return res
+}
+
+// @func: Contains
+// @date: 2024-01-25 10:15:30
+// @brief: 敏感词-文本中是否出现任一词
+// @author: Kewin Li
+// @receiver m
+// @param text
+// @return bool
+func (m *Matcher) Contains(text string) bool {
+	if len(m.words) == 0 {
+		return false
+	}
+
+	found := false
+	m.scan(text, func(w int32) bool {
+		found = true
+		return false
+	})
+
+	return found
+}
+
+// @func: scan
+// @date: 2024-01-29 10:10:26
+// @brief: 敏感词-扫描文本, 每命中一个词回调一次, 回调返回false时停止; 拉丁字母词不满足单词边界的不回调
+// @author: Kewin Li
+// @receiver m
+// @param text
+// @param visit
+func (m *Matcher) scan(text string, visit func(w int32) bool) {
+	runes := []rune(text)
+	// 最近maxWordLen个归一化字符的位置, 足够覆盖最长的词
+	var window [maxWordLen]position
+	cur := int32(0)
+	n, spaces := 0, 0
+
+	for i, r := range runes {
+		r, ok := normalize(r)
+		if !ok {
+			if unicode.IsSpace(runes[i]) {
+				spaces++
+			}
+			continue
+		}
+		window[n%maxWordLen] = position{idx: i, spaces: spaces}
+		n++
+
+		cur = m.step(cur, r)
+		for out := cur; out >= 0; out = m.nodes[out].dict {
+			w := m.nodes[out].word
+			if w < 0 {
+				continue
+			}
+			if m.latin[w] && !isWholeWord(runes, window[(n-m.lens[w])%maxWordLen], window[(n-1)%maxWordLen]) {
+				continue
+			}
+			if !visit(w) {
+				return
+			}
+		}
+	}
+}
+
+// @func: insert
+// @date: 2024-01-25 10:18:06
+// @brief: 敏感词-将归一化后的词加入字典树
+// @author: Kewin Li
+// @receiver m
+// @param word
+func (m *Matcher) insert(word string) {
+	var sb strings.Builder
+	cur := int32(0)
+	n := 0
+	for _, r := range word {
+		r, ok := normalize(r)
+		if !ok {
+			continue
+		}
+		if n++; n > maxWordLen {
+			return
+		}
+		sb.WriteRune(r)
+
+		nxt, ok := m.nodes[cur].next[r]
+		if !ok {
+			nxt = int32(len(m.nodes))
+			m.nodes = append(m.nodes, newNode())
+			m.nodes[cur].next[r] = nxt
+		}
+		cur = nxt
+	}
+
+	if cur == 0 || m.nodes[cur].word >= 0 {
+		return
+	}
+	m.nodes[cur].word = int32(len(m.words))
+	m.words = append(m.words, sb.String())
+	m.lens = append(m.lens, n)
+	m.latin = append(m.latin, isLatinWord(sb.String()))
+}
+
+// @func: build
+// @date: 2024-01-25 10:20:40
+// @brief: 敏感词-按层序计算fail指针和输出链
+// @author: Kewin Li
+// @receiver m
+func (m *Matcher) build() {
+	queue := make([]int32, 0, len(m.nodes))
+	for _, child := range m.nodes[0].next {
+		m.nodes[child].fail = 0
+		queue = append(queue, child)
+	}
+
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+
+		for r, child := range m.nodes[cur].next {
+			fail := m.nodes[cur].fail
+			for fail > 0 {
+				if _, ok := m.nodes[fail].next[r]; ok {
+					break
+				}
+				fail = m.nodes[fail].fail
+			}
+			if nxt, ok := m.nodes[fail].next[r]; ok && nxt != child {
+				fail = nxt
+			} else {
+				fail = 0
+			}
+
+			m.nodes[child].fail = fail
+			if m.nodes[fail].word >= 0 {
+				m.nodes[child].dict = fail
+			} else {
+				m.nodes[child].dict = m.nodes[fail].dict
+			}
+			queue = append(queue, child)
+		}
+	}
+}
+
+// @func: step
+// @date: 2024-01-25 10:23:16
+// @brief: 敏感词-自动机状态转移
+// @author: Kewin Li
+// @receiver m
+// @param cur
+// @param r
+// @return int32
+func (m *Matcher) step(cur int32, r rune) int32 {
+	for {
+		if nxt, ok := m.nodes[cur].next[r]; ok {
+			return nxt
+		}
+		if cur == 0 {
+			return 0
+		}
+		cur = m.nodes[cur].fail
+	}
+}
+
+func newNode() node {
+	return node{
+		next: make(map[rune]int32),
+		word: -1,
+		dict: -1,
+	}
+}
+
+// @func: normalize
+// @date: 2024-01-25 10:25:52
+// @brief: 敏感词-字符归一化: 全角转半角、转小写; 空白、标点、符号返回false, 匹配时跳过, 防止用"赌 博"、"赌*博"绕过
+// @author: Kewin Li
+// @param r
+// @return rune
+// @return bool
+func normalize(r rune) (rune, bool) {
+	switch {
+	case r == '　':
+		return 0, false
+	case r >= '！' && r <= '～':
+		r -= 0xfee0
+	}
+
+	if unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r) ||
+		unicode.IsControl(r) || unicode.Is(unicode.Cf, r) {
+		return 0, false
+	}
+
+	return unicode.ToLower(r), true
+}
+
+// @func: isLatinWord
+// @date: 2024-01-29 10:14:52
+// @brief: 敏感词-归一化后的词是否全部由拉丁字母、数字组成
+// @author: Kewin Li
+// @param word
+// @return bool
+func isLatinWord(word string) bool {
+	for _, r := range word {
+		if !isLatinRune(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// @func: isLatinRune
+// @date: 2024-01-29 10:15:40
+// @brief: 敏感词-归一化后的字符是否为拉丁字母或数字
+// @author: Kewin Li
+// @param r
+// @return bool
+func isLatinRune(r rune) bool {
+	return unicode.Is(unicode.Latin, r) || unicode.IsDigit(r)
+}
+
+// @func: isWholeWord
+// @date: 2024-01-29 10:17:08
+// @brief: 敏感词-拉丁字母词的命中是否为完整单词: 中间没有空白(只忽略单词内的符号), 前后紧邻的不是拉丁字母或数字
+// @author: Kewin Li
+// @param runes 原文
+// @param start 命中的第一个字符
+// @param end 命中的最后一个字符
+// @return bool
+func isWholeWord(runes []rune, start position, end position) bool {
+	if start.spaces != end.spaces {
+		return false
+	}
+
+	if start.idx > 0 {
+		if r, ok := normalize(runes[start.idx-1]); ok && isLatinRune(r) {
+			return false
+		}
+	}
+	if end.idx+1 < len(runes) {
+		if r, ok := normalize(runes[end.idx+1]); ok && isLatinRune(r) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,100 @@
+package sensitive
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+// @func: TestMatcher_FindAll
+// @date: 2024-01-25 10:40:12
+// @brief: 单元测试-敏感词匹配
+// @author: Kewin Li
+// @param t
+func TestMatcher_FindAll(t *testing.T) {
+	m := NewMatcher([]string{"赌博", "网络赌博", "博彩", "he", "she", "hers", "Casino", "sex", "  ", "赌博"})
+	assert.Equal(t, 8, m.Len())
+
+	testCases := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "没有命中",
+			text: "今天天气不错",
+		},
+		{
+			name: "重叠与后缀命中, 按首次出现顺序去重",
+			text: "网络赌博彩票, 拒绝赌博",
+			want: []string{"网络赌博", "赌博", "博彩"},
+		},
+		{
+			name: "长词包含短词",
+			text: "拒绝网络赌博, 远离赌博",
+			want: []string{"网络赌博", "赌博"},
+		},
+		{
+			name: "忽略大小写、全角和夹杂的符号空白",
+			text: "ＣＡＳＩＮＯ 和 赌 * 博",
+			want: []string{"casino", "赌博"},
+		},
+		{
+			name: "失配后沿fail链继续",
+			text: "网络赌赌博",
+			want: []string{"赌博"},
+		},
+		{
+			name: "拉丁字母词只匹配完整单词",
+			text: "she said hers",
+			want: []string{"she", "hers"},
+		},
+		{
+			name: "拉丁字母词忽略单词内夹杂的符号",
+			text: "S.E.X 和 bad-s*e*x!",
+			want: []string{"sex"},
+		},
+		{
+			name: "拉丁字母词与中文相邻",
+			text: "发布sex内容",
+			want: []string{"sex"},
+		},
+		{
+			name: "拉丁字母词不跨单词匹配",
+			text: "this exam",
+		},
+		{
+			name: "拉丁字母词不跨空白匹配",
+			text: "s e x",
+		},
+		{
+			name: "拉丁字母词不匹配单词的一部分",
+			text: "ushers in Essex, sextant, casinos",
+		},
+		{
+			name: "数字与拉丁字母词相连",
+			text: "sex2 2sex",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, m.FindAll(tc.text))
+			assert.Equal(t, len(tc.want) > 0, m.Contains(tc.text))
+		})
+	}
+}
+
+// @func: TestLoadWords
+// @date: 2024-01-25 10:45:36
+// @brief: 单元测试-读取词表
+// @author: Kewin Li
+// @param t
+func TestLoadWords(t *testing.T) {
+	words, err := LoadWords(strings.NewReader("# 注释\n赌博\n\n  casino  \n"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"赌博", "casino"}, words)
+
+	assert.False(t, NewMatcher(nil).Contains("赌博"))
+}
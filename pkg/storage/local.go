@@ -0,0 +1,348 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidKey       = errors.New("非法的对象key")
+	ErrInvalidSignature = errors.New("签名无效或已过期")
+	ErrContentMismatch  = errors.New("上传内容与签名不一致")
+)
+
+// LocalStorage
+// @Description: 本地文件系统存储, 用于开发和测试; 预签名地址由本服务的路由处理, 以HMAC签名鉴权
+type LocalStorage struct {
+	dir string
+	// 预签名地址前缀, 如 http://localhost:8080/storage
+	baseURL string
+	secret  []byte
+}
+
+func NewLocalStorage(dir string, baseURL string, secret []byte) *LocalStorage {
+	return &LocalStorage{
+		dir:     dir,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		secret:  secret,
+	}
+}
+
+// @func: Put
+// @date: 2024-01-24 14:20:16
+// @brief: 本地存储-写入对象, 先写临时文件再重命名, 读到的对象总是完整的
+// @author: Kewin Li
+// @receiver l
+// @param ctx
+// @param key
+// @param body
+// @param size
+// @param contentType
+// @return error
+func (l *LocalStorage) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	return l.put(key, body, size, nil)
+}
+
+// @func: Get
+// @date: 2024-01-24 14:21:30
+// @brief: 本地存储-读取对象, 调用方负责关闭
+// @author: Kewin Li
+// @receiver l
+// @param ctx
+// @param key
+// @return io.ReadCloser
+// @return error
+func (l *LocalStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	p, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrObjectNotFound
+	}
+
+	return f, err
+}
+
+// @func: Stat
+// @date: 2024-01-24 14:22:36
+// @brief: 本地存储-查询对象元数据
+// @author: Kewin Li
+// @receiver l
+// @param ctx
+// @param key
+// @return Object
+// @return error
+func (l *LocalStorage) Stat(ctx context.Context, key string) (Object, error) {
+	p, err := l.path(key)
+	if err != nil {
+		return Object{}, err
+	}
+
+	info, err := os.Stat(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return Object{}, ErrObjectNotFound
+	}
+	if err != nil {
+		return Object{}, err
+	}
+
+	return Object{
+		Key:  key,
+		Size: info.Size(),
+	}, nil
+}
+
+// @func: Delete
+// @date: 2024-01-24 14:23:40
+// @brief: 本地存储-删除对象, 对象不存在不报错
+// @author: Kewin Li
+// @receiver l
+// @param ctx
+// @param key
+// @return error
+func (l *LocalStorage) Delete(ctx context.Context, key string) error {
+	p, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+// @func: PresignPut
+// @date: 2024-01-24 14:25:12
+// @brief: 本地存储-预签名上传地址
+// @author: Kewin Li
+// @receiver l
+// @param ctx
+// @param key
+// @param size
+// @param contentType
+// @param sum
+// @param expire
+// @return PresignedRequest
+// @return error
+func (l *LocalStorage) PresignPut(ctx context.Context, key string, size int64, contentType string, sum []byte, expire time.Duration) (PresignedRequest, error) {
+	if _, err := l.path(key); err != nil {
+		return PresignedRequest{}, err
+	}
+
+	expireAt := time.Now().Add(expire)
+	query := url.Values{}
+	query.Set("exp", strconv.FormatInt(expireAt.Unix(), 10))
+	query.Set("size", strconv.FormatInt(size, 10))
+	query.Set("sha256", hex.EncodeToString(sum))
+	query.Set("sig", l.sign(http.MethodPut, key, query, contentType))
+
+	return PresignedRequest{
+		Method: http.MethodPut,
+		URL:    l.baseURL + "/" + key + "?" + query.Encode(),
+		Headers: map[string]string{
+			"Content-Type": contentType,
+		},
+		ExpireAt: expireAt,
+	}, nil
+}
+
+// @func: PresignGet
+// @date: 2024-01-24 14:26:40
+// @brief: 本地存储-预签名下载地址
+// @author: Kewin Li
+// @receiver l
+// @param ctx
+// @param key
+// @param expire
+// @return string
+// @return error
+func (l *LocalStorage) PresignGet(ctx context.Context, key string, expire time.Duration) (string, error) {
+	if _, err := l.path(key); err != nil {
+		return "", err
+	}
+
+	query := url.Values{}
+	query.Set("exp", strconv.FormatInt(time.Now().Add(expire).Unix(), 10))
+	query.Set("sig", l.sign(http.MethodGet, key, query, ""))
+
+	return l.baseURL + "/" + key + "?" + query.Encode(), nil
+}
+
+// @func: ServePresigned
+// @date: 2024-01-24 14:28:52
+// @brief: 本地存储-处理预签名地址的上传、下载请求
+// @author: Kewin Li
+// @receiver l
+// @param w
+// @param r
+// @param key
+func (l *LocalStorage) ServePresigned(w http.ResponseWriter, r *http.Request, key string) {
+	query := r.URL.Query()
+	contentType := ""
+	if r.Method == http.MethodPut {
+		contentType = r.Header.Get("Content-Type")
+	}
+
+	if !l.verify(r.Method, key, query, contentType) {
+		http.Error(w, ErrInvalidSignature.Error(), http.StatusForbidden)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		p, err := l.path(key)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		// 对象按内容哈希命名, 内容不会变化
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		http.ServeFile(w, r, p)
+
+	case http.MethodPut:
+		size, _ := strconv.ParseInt(query.Get("size"), 10, 64)
+		sum, _ := hex.DecodeString(query.Get("sha256"))
+		err := l.put(key, r.Body, size, sum)
+		switch {
+		case err == nil:
+			w.WriteHeader(http.StatusOK)
+		case errors.Is(err, ErrContentMismatch):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, "系统错误", http.StatusInternalServerError)
+		}
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// @func: put
+// @date: 2024-01-24 14:31:20
+// @brief: 本地存储-写入临时文件并校验大小、SHA256, 校验通过后重命名为目标文件
+// @author: Kewin Li
+// @receiver l
+// @param key
+// @param body
+// @param size
+// @param sum 为空时不校验
+// @return error
+func (l *LocalStorage) put(key string, body io.Reader, size int64, sum []byte) error {
+	p, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(p), 0o755)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	h := sha256.New()
+	// 多读1字节用于判断是否超出声明的大小
+	n, err := io.Copy(io.MultiWriter(tmp, h), io.LimitReader(body, size+1))
+	closeErr := tmp.Close()
+	if err != nil {
+		return err
+	}
+	if closeErr != nil {
+		return closeErr
+	}
+
+	if n != size || (len(sum) > 0 && !bytes.Equal(h.Sum(nil), sum)) {
+		return ErrContentMismatch
+	}
+
+	return os.Rename(tmp.Name(), p)
+}
+
+// @func: path
+// @date: 2024-01-24 14:33:06
+// @brief: 本地存储-对象key转文件路径, 拒绝跳出存储目录的key
+// @author: Kewin Li
+// @receiver l
+// @param key
+// @return string
+// @return error
+func (l *LocalStorage) path(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") ||
+		path.Clean(key) != key || key == ".." || strings.HasPrefix(key, "../") {
+		return "", ErrInvalidKey
+	}
+
+	return filepath.Join(l.dir, filepath.FromSlash(key)), nil
+}
+
+// @func: sign
+// @date: 2024-01-24 14:34:20
+// @brief: 本地存储-HMAC-SHA256签名, 覆盖请求方法、key、过期时间、大小、校验和、类型
+// @author: Kewin Li
+// @receiver l
+// @param method
+// @param key
+// @param query
+// @param contentType
+// @return string
+func (l *LocalStorage) sign(method string, key string, query url.Values, contentType string) string {
+	mac := hmac.New(sha256.New, l.secret)
+	mac.Write([]byte(strings.Join([]string{
+		method,
+		key,
+		query.Get("exp"),
+		query.Get("size"),
+		query.Get("sha256"),
+		contentType,
+	}, "\n")))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// @func: verify
+// @date: 2024-01-24 14:35:36
+// @brief: 本地存储-校验签名和过期时间
+// @author: Kewin Li
+// @receiver l
+// @param method
+// @param key
+// @param query
+// @param contentType
+// @return bool
+func (l *LocalStorage) verify(method string, key string, query url.Values, contentType string) bool {
+	exp, err := strconv.ParseInt(query.Get("exp"), 10, 64)
+	if err != nil || time.Now().Unix() > exp {
+		return false
+	}
+
+	sig, err := hex.DecodeString(query.Get("sig"))
+	if err != nil {
+		return false
+	}
+	want, _ := hex.DecodeString(l.sign(method, key, query, contentType))
+
+	return hmac.Equal(sig, want)
+}
@@ -0,0 +1,142 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// @func: TestLocalStorage_Presigned
+// @date: 2024-01-24 17:00:20
+// @brief: 单元测试-本地存储预签名地址上传、下载
+// @author: Kewin Li
+// @param t
+func TestLocalStorage_Presigned(t *testing.T) {
+	var store *LocalStorage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		store.ServePresigned(w, r, strings.TrimPrefix(r.URL.Path, "/storage/"))
+	}))
+	defer server.Close()
+	store = NewLocalStorage(t.TempDir(), server.URL+"/storage", []byte("secret"))
+
+	data := []byte("hello kitbook")
+	sum := sha256.Sum256(data)
+	key := "files/ab/abc"
+
+	testCases := []struct {
+		name string
+		// 基于签发结果构造请求
+		req func(t *testing.T, presign PresignedRequest) *http.Request
+
+		wantCode int
+	}{
+		{
+			name: "篡改类型, 签名无效",
+			req: func(t *testing.T, presign PresignedRequest) *http.Request {
+				req, err := http.NewRequest(presign.Method, presign.URL, bytes.NewReader(data))
+				require.NoError(t, err)
+				req.Header.Set("Content-Type", "text/html")
+				return req
+			},
+
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name: "篡改大小, 签名无效",
+			req: func(t *testing.T, presign PresignedRequest) *http.Request {
+				req, err := http.NewRequest(presign.Method,
+					strings.Replace(presign.URL, "size=13", "size=14", 1), bytes.NewReader(data))
+				require.NoError(t, err)
+				req.Header.Set("Content-Type", presign.Headers["Content-Type"])
+				return req
+			},
+
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name: "内容与校验和不一致",
+			req: func(t *testing.T, presign PresignedRequest) *http.Request {
+				req, err := http.NewRequest(presign.Method, presign.URL, strings.NewReader("hello kitboox"))
+				require.NoError(t, err)
+				req.Header.Set("Content-Type", presign.Headers["Content-Type"])
+				return req
+			},
+
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "上传成功",
+			req: func(t *testing.T, presign PresignedRequest) *http.Request {
+				req, err := http.NewRequest(presign.Method, presign.URL, bytes.NewReader(data))
+				require.NoError(t, err)
+				req.Header.Set("Content-Type", presign.Headers["Content-Type"])
+				return req
+			},
+
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			presign, err := store.PresignPut(context.Background(), key, int64(len(data)), "text/plain", sum[:], time.Minute)
+			require.NoError(t, err)
+
+			resp, err := http.DefaultClient.Do(tc.req(t, presign))
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, tc.wantCode, resp.StatusCode)
+		})
+	}
+
+	// 上传成功后可通过预签名地址下载
+	url, err := store.PresignGet(context.Background(), key, time.Minute)
+	require.NoError(t, err)
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, data, body)
+
+	// 下载签名不能用于上传
+	req, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+	require.NoError(t, err)
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	// 过期的地址
+	url, err = store.PresignGet(context.Background(), key, -time.Minute)
+	require.NoError(t, err)
+	resp, err = http.Get(url)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+// @func: TestLocalStorage_InvalidKey
+// @date: 2024-01-24 17:05:36
+// @brief: 单元测试-拒绝跳出存储目录的key
+// @author: Kewin Li
+// @param t
+func TestLocalStorage_InvalidKey(t *testing.T) {
+	store := NewLocalStorage(t.TempDir(), "http://localhost/storage", []byte("secret"))
+
+	for _, key := range []string{"", "/etc/passwd", "../a", "a/../../b", "a\\b", "a//b"} {
+		_, err := store.Stat(context.Background(), key)
+		assert.Equal(t, ErrInvalidKey, err, key)
+	}
+
+	_, err := store.Stat(context.Background(), "files/ab/abc")
+	assert.Equal(t, ErrObjectNotFound, err)
+}
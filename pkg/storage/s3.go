@@ -0,0 +1,199 @@
+package storage
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"io"
+	"net/http"
+	"time"
+)
+
+// S3Storage
+// @Description: S3兼容对象存储(AWS S3、腾讯云COS、MinIO等)
+type S3Storage struct {
+	client *s3.S3
+	bucket string
+}
+
+func NewS3Storage(client *s3.S3, bucket string) Storage {
+	return &S3Storage{
+		client: client,
+		bucket: bucket,
+	}
+}
+
+// @func: Put
+// @date: 2024-01-24 14:05:20
+// @brief: S3存储-上传对象
+// @author: Kewin Li
+// @receiver s
+// @param ctx
+// @param key
+// @param body
+// @param size
+// @param contentType
+// @return error
+func (s *S3Storage) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	// SDK需要可重放的请求体用于签名和重试
+	rs, ok := body.(io.ReadSeeker)
+	if !ok {
+		return errors.New("S3上传需要可Seek的请求体")
+	}
+
+	_, err := s.client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(s.bucket),
+		Key:           aws.String(key),
+		Body:          rs,
+		ContentLength: aws.Int64(size),
+		ContentType:   aws.String(contentType),
+	})
+
+	return err
+}
+
+// @func: Get
+// @date: 2024-01-24 14:06:42
+// @brief: S3存储-读取对象, 调用方负责关闭
+// @author: Kewin Li
+// @receiver s
+// @param ctx
+// @param key
+// @return io.ReadCloser
+// @return error
+func (s *S3Storage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	out, err := s.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, s.convertErr(err)
+	}
+
+	return out.Body, nil
+}
+
+// @func: Stat
+// @date: 2024-01-24 14:08:16
+// @brief: S3存储-查询对象元数据
+// @author: Kewin Li
+// @receiver s
+// @param ctx
+// @param key
+// @return Object
+// @return error
+func (s *S3Storage) Stat(ctx context.Context, key string) (Object, error) {
+	out, err := s.client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return Object{}, s.convertErr(err)
+	}
+
+	return Object{
+		Key:  key,
+		Size: aws.Int64Value(out.ContentLength),
+	}, nil
+}
+
+// @func: Delete
+// @date: 2024-01-24 14:09:30
+// @brief: S3存储-删除对象, 对象不存在不报错
+// @author: Kewin Li
+// @receiver s
+// @param ctx
+// @param key
+// @return error
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	_, err := s.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+
+	return err
+}
+
+// @func: PresignPut
+// @date: 2024-01-24 14:11:08
+// @brief: S3存储-预签名上传地址, 大小、类型、SHA256校验和都参与签名
+// @author: Kewin Li
+// @receiver s
+// @param ctx
+// @param key
+// @param size
+// @param contentType
+// @param sum
+// @param expire
+// @return PresignedRequest
+// @return error
+func (s *S3Storage) PresignPut(ctx context.Context, key string, size int64, contentType string, sum []byte, expire time.Duration) (PresignedRequest, error) {
+	req, _ := s.client.PutObjectRequest(&s3.PutObjectInput{
+		Bucket:         aws.String(s.bucket),
+		Key:            aws.String(key),
+		ContentLength:  aws.Int64(size),
+		ContentType:    aws.String(contentType),
+		ChecksumSHA256: aws.String(base64.StdEncoding.EncodeToString(sum)),
+	})
+	req.SetContext(ctx)
+
+	url, header, err := req.PresignRequest(expire)
+	if err != nil {
+		return PresignedRequest{}, err
+	}
+
+	headers := make(map[string]string, len(header))
+	for k := range header {
+		// Host 由客户端根据URL自动设置
+		if k == "Host" {
+			continue
+		}
+		headers[k] = header.Get(k)
+	}
+
+	return PresignedRequest{
+		Method:   http.MethodPut,
+		URL:      url,
+		Headers:  headers,
+		ExpireAt: time.Now().Add(expire),
+	}, nil
+}
+
+// @func: PresignGet
+// @date: 2024-01-24 14:12:40
+// @brief: S3存储-预签名下载地址
+// @author: Kewin Li
+// @receiver s
+// @param ctx
+// @param key
+// @param expire
+// @return string
+// @return error
+func (s *S3Storage) PresignGet(ctx context.Context, key string, expire time.Duration) (string, error) {
+	req, _ := s.client.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	req.SetContext(ctx)
+
+	return req.Presign(expire)
+}
+
+// @func: convertErr
+// @date: 2024-01-24 14:13:52
+// @brief: S3存储-对象不存在转为ErrObjectNotFound
+// @author: Kewin Li
+// @receiver s
+// @param err
+// @return error
+func (s *S3Storage) convertErr(err error) error {
+	var reqErr awserr.RequestFailure
+	if errors.As(err, &reqErr) && reqErr.StatusCode() == http.StatusNotFound {
+		return ErrObjectNotFound
+	}
+
+	return err
+}
@@ -0,0 +1,38 @@
+// Package storage
+// @Description: 对象存储: S3兼容存储、本地文件系统两种实现, 支持预签名上传、下载地址
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+var ErrObjectNotFound = errors.New("对象不存在")
+
+// Object
+// @Description: 对象元数据
+type Object struct {
+	Key  string
+	Size int64
+}
+
+// PresignedRequest
+// @Description: 预签名请求, 客户端在过期前按 Method 请求 URL, 并携带 Headers 中的全部请求头
+type PresignedRequest struct {
+	Method   string
+	URL      string
+	Headers  map[string]string
+	ExpireAt time.Time
+}
+
+type Storage interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Stat(ctx context.Context, key string) (Object, error)
+	Delete(ctx context.Context, key string) error
+	// PresignPut 签名中包含大小、类型和SHA256, 上传内容不一致时存储端拒绝
+	PresignPut(ctx context.Context, key string, size int64, contentType string, sum []byte, expire time.Duration) (PresignedRequest, error)
+	PresignGet(ctx context.Context, key string, expire time.Duration) (string, error)
+}
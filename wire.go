@@ -5,6 +5,10 @@ package main
 import (
 	"github.com/google/wire"
 	"kitbook/internal/events/article"
+	"kitbook/internal/events/feed"
+	"kitbook/internal/events/notification"
+	"kitbook/internal/events/ranking"
+	"kitbook/internal/events/search"
 	"kitbook/internal/repository"
 	"kitbook/internal/repository/cache"
 	"kitbook/internal/repository/dao"
@@ -23,8 +27,68 @@ var interactiveSvcSet = wire.NewSet(
 
 var rankingSvcSet = wire.NewSet(
 	cache.NewRedisRankingCache,
+	ioc.InitLocalRankingCache,
 	repository.NewCacheRankingRepository,
-	service.NewBatchRankingService,
+	cache.NewRedisRankingScoreCache,
+	repository.NewCacheRankingScoreRepository,
+	ioc.InitRankingService,
+)
+
+var historySvcSet = wire.NewSet(
+	dao.NewGormHistoryDao,
+	repository.NewNormalHistoryRepository,
+	service.NewNormalHistoryService,
+)
+
+var commentSvcSet = wire.NewSet(
+	dao.NewGormCommentDao,
+	repository.NewCacheCommentRepository,
+	service.NewNormalCommentService,
+)
+
+var followSvcSet = wire.NewSet(
+	dao.NewGormFollowDao,
+	cache.NewRedisFollowCache,
+	repository.NewCacheFollowRepository,
+	service.NewNormalFollowService,
+)
+
+var feedSvcSet = wire.NewSet(
+	dao.NewGormFeedDao,
+	repository.NewNormalFeedRepository,
+	service.NewPushPullFeedService,
+)
+
+var collectionSvcSet = wire.NewSet(
+	dao.NewGormCollectionDao,
+	repository.NewNormalCollectionRepository,
+	service.NewNormalCollectionService,
+)
+
+var jobSvcSet = wire.NewSet(
+	dao.NewGormJobDao,
+	cache.NewRedisJobCallbackCache,
+	cache.NewRedisJobNodeCache,
+	repository.NewPreemptJobRepository,
+	service.NewCronJobService,
+)
+
+var searchSvcSet = wire.NewSet(
+	dao.NewMemoryArticleSearchDao,
+	repository.NewNormalArticleSearchRepository,
+	service.NewNormalSearchService,
+)
+
+var uploadSvcSet = wire.NewSet(
+	dao.NewGormUploadDao,
+	repository.NewNormalUploadRepository,
+	service.NewNormalUploadService,
+)
+
+var notificationSvcSet = wire.NewSet(
+	dao.NewGormNotificationDao,
+	repository.NewNormalNotificationRepository,
+	service.NewNormalNotificationService,
 )
 
 func InitApp() *App {
@@ -38,18 +102,45 @@ func InitApp() *App {
 		ioc.InitSyncProducer,
 		ioc.InitJobs,
 		ioc.InitRankingJob,
+		ioc.InitRankingLocalCacheJob,
+		ioc.InitScheduler,
+		ioc.InitStuckJobReaper,
+		ioc.InitSearchIndexRebuildJob,
+		ioc.InitLeaderboards,
 		ioc.InitRlockClient,
+		ioc.InitStorage,
+		ioc.InitUploadLimits,
+		ioc.InitModerator,
 		//ioc.InitFreeCache,
 
 		interactiveSvcSet,
 		rankingSvcSet,
+		historySvcSet,
+		commentSvcSet,
+		followSvcSet,
+		feedSvcSet,
+		collectionSvcSet,
+		jobSvcSet,
+		searchSvcSet,
+		uploadSvcSet,
+		notificationSvcSet,
 
 		article.NewSaramaSyncProducer,
 		article.NewInteractiveReadEventConsumer,
+		article.NewHistoryRecordConsumer,
+		feed.NewArticlePublishEventConsumer,
+		ranking.NewInteractiveEventConsumer,
+		search.NewArticleEventConsumer,
+		notification.NewScheduleFailedEventConsumer,
 		ioc.InitConsumers,
 
 		dao.NewGormUserDao,
-		dao.NewGormArticleDao,
+		ioc.InitArticleDao,
+		dao.NewGormArticleTagDao,
+		dao.NewGormUploadRefDao,
+		dao.NewGormArticleRevisionDao,
+		dao.NewGormArticleScheduleDao,
+		dao.NewGormArticleReviewDao,
 		cache.NewRedisUserCache,
 		cache.NewRedisCodeCache,
 		cache.NewRedisArticleCache,
@@ -58,6 +149,9 @@ func InitApp() *App {
 		repository.NewCacheUserRepository,
 		repository.NewcodeRepository,
 		repository.NewCacheArticleRepository,
+		repository.NewNormalArticleRevisionRepository,
+		repository.NewNormalArticleScheduleRepository,
+		repository.NewNormalArticleReviewRepository,
 
 		//  TODO: 如何使用多个不同的限流器
 		ioc.InitLimiter,
@@ -72,6 +166,17 @@ func InitApp() *App {
 		web.NewUserHandler,
 		web.NewArticleHandler,
 		web.NewOAuth2WechatHandler,
+		web.NewHistoryHandler,
+		web.NewCommentHandler,
+		web.NewFollowHandler,
+		web.NewFeedHandler,
+		web.NewCollectionHandler,
+		web.NewRankingHandler,
+		web.NewSearchHandler,
+		web.NewUploadHandler,
+		web.NewNotificationHandler,
+		ioc.InitJobHandler,
+		ioc.InitReviewHandler,
 		ioc.InitWebServer,
 
 		wire.Struct(new(App), "*"),
@@ -9,6 +9,10 @@ package main
 import (
 	"github.com/google/wire"
 	"kitbook/internal/events/article"
+	"kitbook/internal/events/feed"
+	"kitbook/internal/events/notification"
+	"kitbook/internal/events/ranking"
+	"kitbook/internal/events/search"
 	"kitbook/internal/repository"
 	"kitbook/internal/repository/cache"
 	"kitbook/internal/repository/dao"
@@ -35,32 +39,100 @@ func InitApp() *App {
 	codeRepository := repository.NewcodeRepository(codeCache)
 	smsService := ioc.InitSmsService(limiter)
 	codeService := service.NewPhoneCodeService(codeRepository, smsService)
-	userHandler := web.NewUserHandler(userService, codeService, jwtHandler, logger)
+	followDao := dao.NewGormFollowDao(db)
+	followCache := cache.NewRedisFollowCache(cmdable)
+	followRepository := repository.NewCacheFollowRepository(followDao, followCache, logger)
+	followService := service.NewNormalFollowService(followRepository)
+	userHandler := web.NewUserHandler(userService, codeService, jwtHandler, followService, logger)
 	wechatService := ioc.InitWechatService()
 	oAuth2WechatHandler := web.NewOAuth2WechatHandler(wechatService, userService, jwtHandler, logger)
-	articleDao := dao.NewGormArticleDao(db)
+	articleDao := ioc.InitArticleDao(db)
 	articleCache := cache.NewRedisArticleCache(cmdable)
-	articleRepository := repository.NewCacheArticleRepository(articleDao, articleCache, userRepository)
+	articleTagDao := dao.NewGormArticleTagDao(db)
+	uploadRefDao := dao.NewGormUploadRefDao(db)
+	articleRevisionDao := dao.NewGormArticleRevisionDao(db)
+	articleRepository := repository.NewCacheArticleRepository(articleDao, articleTagDao, articleRevisionDao, uploadRefDao, articleCache, userRepository)
 	client := ioc.InitSaramaClient()
 	syncProducer := ioc.InitSyncProducer(client)
 	producer := article.NewSaramaSyncProducer(syncProducer)
-	articleService := service.NewNormalArticleService(articleRepository, producer, logger)
+	articleRevisionRepository := repository.NewNormalArticleRevisionRepository(articleRevisionDao)
+	articleScheduleDao := dao.NewGormArticleScheduleDao(db)
+	articleScheduleRepository := repository.NewNormalArticleScheduleRepository(articleScheduleDao)
+	articleReviewDao := dao.NewGormArticleReviewDao(db)
+	articleReviewRepository := repository.NewNormalArticleReviewRepository(articleReviewDao)
+	moderator := ioc.InitModerator(logger)
+	articleService := service.NewNormalArticleService(articleRepository, articleRevisionRepository, articleScheduleRepository, articleReviewRepository, moderator, producer, logger)
 	interactiveDao := dao.NewGORMInteractiveDao(db)
 	interactiveCache := cache.NewRedisInteractiveCache(cmdable)
 	interactiveRepository := repository.NewArticleInteractiveRepository(interactiveDao, interactiveCache, logger)
-	interactiveService := service.NewArticleInteractiveService(interactiveRepository, logger)
+	interactiveService := service.NewArticleInteractiveService(interactiveRepository, producer, logger)
 	articleHandler := web.NewArticleHandler(articleService, interactiveService, logger)
-	engine := ioc.InitWebServer(v, userHandler, oAuth2WechatHandler, articleHandler)
+	historyDao := dao.NewGormHistoryDao(db)
+	historyRepository := repository.NewNormalHistoryRepository(historyDao)
+	historyService := service.NewNormalHistoryService(historyRepository)
+	historyHandler := web.NewHistoryHandler(historyService, logger)
+	commentDao := dao.NewGormCommentDao(db)
+	commentRepository := repository.NewCacheCommentRepository(commentDao, interactiveCache, logger)
+	commentService := service.NewNormalCommentService(commentRepository, articleRepository)
+	commentHandler := web.NewCommentHandler(commentService, logger)
+	followHandler := web.NewFollowHandler(followService, logger)
+	feedDao := dao.NewGormFeedDao(db)
+	feedRepository := repository.NewNormalFeedRepository(feedDao)
+	feedService := service.NewPushPullFeedService(feedRepository, followService)
+	feedHandler := web.NewFeedHandler(feedService, logger)
+	collectionDao := dao.NewGormCollectionDao(db)
+	collectionRepository := repository.NewNormalCollectionRepository(collectionDao, interactiveCache, articleRepository, logger)
+	collectionService := service.NewNormalCollectionService(collectionRepository)
+	collectionHandler := web.NewCollectionHandler(collectionService, logger)
+	rankingCache := cache.NewRedisRankingCache(cmdable)
+	localRankingCache := ioc.InitLocalRankingCache()
+	rankingRepository := repository.NewCacheRankingRepository(rankingCache, localRankingCache, logger)
+	rankingScoreCache := cache.NewRedisRankingScoreCache(cmdable)
+	rankingScoreRepository := repository.NewCacheRankingScoreRepository(rankingScoreCache)
+	v2 := ioc.InitLeaderboards()
+	rankingService := ioc.InitRankingService(interactiveService, articleService, articleRepository, rankingRepository, rankingScoreRepository, v2)
+	rankingHandler := web.NewRankingHandler(rankingService, interactiveService, logger)
+	jobDao := dao.NewGormJobDao(db)
+	jobCallbackCache := cache.NewRedisJobCallbackCache(cmdable)
+	jobNodeCache := cache.NewRedisJobNodeCache(cmdable)
+	jobRepository := repository.NewPreemptJobRepository(jobDao, jobCallbackCache, jobNodeCache)
+	jobService := service.NewCronJobService(jobRepository, logger)
+	jobHandler := ioc.InitJobHandler(jobService, logger)
+	articleSearchDao := dao.NewMemoryArticleSearchDao()
+	articleSearchRepository := repository.NewNormalArticleSearchRepository(articleSearchDao)
+	searchService := service.NewNormalSearchService(articleSearchRepository, articleRepository, userRepository, logger)
+	searchHandler := web.NewSearchHandler(searchService, logger)
+	uploadDao := dao.NewGormUploadDao(db)
+	uploadRepository := repository.NewNormalUploadRepository(uploadDao)
+	storageStorage := ioc.InitStorage(logger)
+	uploadLimits := ioc.InitUploadLimits()
+	uploadService := service.NewNormalUploadService(uploadRepository, storageStorage, uploadLimits, logger)
+	uploadHandler := web.NewUploadHandler(uploadService, storageStorage, logger)
+	reviewHandler := ioc.InitReviewHandler(articleService, logger)
+	notificationDao := dao.NewGormNotificationDao(db)
+	notificationRepository := repository.NewNormalNotificationRepository(notificationDao)
+	notificationService := service.NewNormalNotificationService(notificationRepository)
+	notificationHandler := web.NewNotificationHandler(notificationService, logger)
+	engine := ioc.InitWebServer(v, userHandler, oAuth2WechatHandler, articleHandler, historyHandler, commentHandler, followHandler, feedHandler, collectionHandler, rankingHandler, jobHandler, searchHandler, uploadHandler, reviewHandler, notificationHandler)
 	interactiveReadEventConsumer := article.NewInteractiveReadEventConsumer(interactiveRepository, client, logger)
-	v2 := ioc.InitConsumers(interactiveReadEventConsumer)
-	rankingService := service.NewBatchRankingService(interactiveService, articleService)
+	historyRecordConsumer := article.NewHistoryRecordConsumer(historyRepository, client, logger)
+	articlePublishEventConsumer := feed.NewArticlePublishEventConsumer(feedService, client, logger)
+	interactiveEventConsumer := ranking.NewInteractiveEventConsumer(rankingService, client, logger)
+	articleEventConsumer := search.NewArticleEventConsumer(searchService, client, logger)
+	scheduleFailedEventConsumer := notification.NewScheduleFailedEventConsumer(notificationService, client, logger)
+	v3 := ioc.InitConsumers(interactiveReadEventConsumer, historyRecordConsumer, articlePublishEventConsumer, interactiveEventConsumer, articleEventConsumer, scheduleFailedEventConsumer)
 	rlockClient := ioc.InitRlockClient(cmdable)
 	rankingJob := ioc.InitRankingJob(rankingService, rlockClient, logger)
-	cron := ioc.InitJobs(logger, rankingJob)
+	rankingLocalCacheJob := ioc.InitRankingLocalCacheJob(rankingService)
+	stuckJobReaper := ioc.InitStuckJobReaper(jobService, logger)
+	searchIndexRebuildJob := ioc.InitSearchIndexRebuildJob(searchService)
+	cron := ioc.InitJobs(logger, rankingJob, rankingLocalCacheJob, stuckJobReaper, searchIndexRebuildJob)
+	scheduler := ioc.InitScheduler(jobService, articleService, uploadService, logger)
 	app := &App{
 		server:    engine,
-		consumers: v2,
+		consumers: v3,
 		cron:      cron,
+		scheduler: scheduler,
 	}
 	return app
 }
@@ -69,4 +141,20 @@ func InitApp() *App {
 
 var interactiveSvcSet = wire.NewSet(dao.NewGORMInteractiveDao, cache.NewRedisInteractiveCache, repository.NewArticleInteractiveRepository, service.NewArticleInteractiveService)
 
-var rankingSvcSet = wire.NewSet(cache.NewRedisRankingCache, repository.NewCacheRankingRepository, service.NewBatchRankingService)
+var rankingSvcSet = wire.NewSet(cache.NewRedisRankingCache, ioc.InitLocalRankingCache, repository.NewCacheRankingRepository, cache.NewRedisRankingScoreCache, repository.NewCacheRankingScoreRepository, ioc.InitRankingService)
+
+var historySvcSet = wire.NewSet(dao.NewGormHistoryDao, repository.NewNormalHistoryRepository, service.NewNormalHistoryService)
+
+var commentSvcSet = wire.NewSet(dao.NewGormCommentDao, repository.NewCacheCommentRepository, service.NewNormalCommentService)
+
+var followSvcSet = wire.NewSet(dao.NewGormFollowDao, cache.NewRedisFollowCache, repository.NewCacheFollowRepository, service.NewNormalFollowService)
+
+var feedSvcSet = wire.NewSet(dao.NewGormFeedDao, repository.NewNormalFeedRepository, service.NewPushPullFeedService)
+
+var collectionSvcSet = wire.NewSet(dao.NewGormCollectionDao, repository.NewNormalCollectionRepository, service.NewNormalCollectionService)
+
+var jobSvcSet = wire.NewSet(dao.NewGormJobDao, cache.NewRedisJobCallbackCache, cache.NewRedisJobNodeCache, repository.NewPreemptJobRepository, service.NewCronJobService)
+
+var searchSvcSet = wire.NewSet(dao.NewMemoryArticleSearchDao, repository.NewNormalArticleSearchRepository, service.NewNormalSearchService)
+
+var uploadSvcSet = wire.NewSet(dao.NewGormUploadDao, repository.NewNormalUploadRepository, service.NewNormalUploadService)